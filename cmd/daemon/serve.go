@@ -115,6 +115,10 @@ func servePublic(r driver.Registry, cmd *cobra.Command, eg *errgroup.Group, slOp
 	})
 
 	n.UseFunc(x.CleanPath) // Prevent double slashes from breaking CSRF.
+	n.UseFunc(x.ReadOnlyPublicMiddleware(r))
+	n.UseFunc(x.FlowInitRateLimitMiddleware(r, r.SelfServiceErrorManager().Forward))
+	n.UseFunc(x.PublicRequestTimeoutMiddleware(r))
+	n.UseFunc(x.PublicJSONFieldCaseMiddleware(r))
 	r.WithCSRFHandler(csrf)
 	n.UseHandler(http.MaxBytesHandler(r.CSRFHandler(), 5*1024*1024 /* 5 MB */))
 
@@ -191,6 +195,8 @@ func serveAdmin(r driver.Registry, cmd *cobra.Command, eg *errgroup.Group, slOpt
 	n.UseFunc(semconv.Middleware)
 	n.Use(adminLogger)
 	n.UseFunc(x.RedirectAdminMiddleware)
+	n.UseFunc(x.ReadOnlyAdminMiddleware(r))
+	n.UseFunc(x.AdminRequestTimeoutMiddleware(r))
 	n.Use(x.HTTPLoaderContextMiddleware(r))
 	n.Use(sqa(ctx, cmd, r))
 	n.Use(r.PrometheusManager())
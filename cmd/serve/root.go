@@ -4,8 +4,11 @@
 package serve
 
 import (
+	"context"
+
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/x/configx"
+	"github.com/ory/x/logrusx"
 	"github.com/ory/x/servicelocatorx"
 
 	"github.com/spf13/cobra"
@@ -28,22 +31,8 @@ func NewServeCmd(slOpts []servicelocatorx.Option, dOpts []driver.RegistryOption)
 				return err
 			}
 
-			if d.Config().IsInsecureDevMode(ctx) {
-				d.Logger().Warn(`
-
-YOU ARE RUNNING Ory KRATOS IN DEV MODE.
-SECURITY IS DISABLED.
-DON'T DO THIS IN PRODUCTION!
-
-`)
-			}
-
-			configVersion := d.Config().ConfigVersion(ctx)
-			if configVersion == config.UnknownVersion {
-				d.Logger().Warn("The config has no version specified. Add the version to improve your development experience.")
-			} else if config.Version != "" &&
-				configVersion != config.Version {
-				d.Logger().Warnf("Config version is '%s' but kratos runs on version '%s'", configVersion, config.Version)
+			if err := checkStartupConfig(ctx, d.Config(), d.Logger()); err != nil {
+				return err
 			}
 
 			return daemon.ServeAll(d, sl, nil)(cmd, args)
@@ -54,9 +43,34 @@ DON'T DO THIS IN PRODUCTION!
 	serveCmd.PersistentFlags().Bool("sqa-opt-out", false, "Disable anonymized telemetry reports - for more information please visit https://www.ory.sh/docs/ecosystem/sqa")
 	serveCmd.PersistentFlags().Bool("dev", false, "Disables critical security features to make development easier")
 	serveCmd.PersistentFlags().Bool("watch-courier", false, "Run the message courier as a background task, to simplify single-instance setup")
+	serveCmd.PersistentFlags().Bool("suppress-dev-warning", false, "Do not log the warning printed when running in dev mode")
+	serveCmd.PersistentFlags().Bool("strict-version", false, "Fail to start if the config version is missing or does not match the running version")
 	return serveCmd
 }
 
+// checkStartupConfig logs warnings about the loaded configuration. It returns an error instead
+// if the config version is missing or mismatched and strict version checking is enabled.
+func checkStartupConfig(ctx context.Context, c *config.Config, l *logrusx.Logger) error {
+	if c.IsInsecureDevMode(ctx) && !c.SuppressDevWarning(ctx) {
+		l.Warn(`
+
+YOU ARE RUNNING Ory KRATOS IN DEV MODE.
+SECURITY IS DISABLED.
+DON'T DO THIS IN PRODUCTION!
+
+`)
+	}
+
+	if err := c.CheckVersion(ctx); err != nil {
+		if c.StrictVersion(ctx) {
+			return err
+		}
+		l.Warn(err.Error())
+	}
+
+	return nil
+}
+
 func RegisterCommandRecursive(parent *cobra.Command, slOpts []servicelocatorx.Option, dOpts []driver.RegistryOption) {
 	parent.AddCommand(NewServeCmd(slOpts, dOpts))
 }
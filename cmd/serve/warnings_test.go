@@ -0,0 +1,112 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package serve
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/x/configx"
+	"github.com/ory/x/contextx"
+	"github.com/ory/x/logrusx"
+)
+
+func TestCheckStartupConfig(t *testing.T) {
+	ctx := context.Background()
+
+	hasDevWarning := func(hook *test.Hook) bool {
+		for _, e := range hook.AllEntries() {
+			if strings.Contains(e.Message, "DEV MODE") {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, tc := range []struct {
+		name   string
+		values map[string]interface{}
+		expect bool
+	}{
+		{
+			name:   "dev mode logs warning",
+			values: map[string]interface{}{"dev": true},
+			expect: true,
+		},
+		{
+			name:   "warning suppressed by config",
+			values: map[string]interface{}{"dev": true, config.ViperKeyLogSuppressDevWarning: true},
+		},
+		{
+			name:   "warning suppressed by flag",
+			values: map[string]interface{}{"dev": true, config.ViperKeySuppressDevWarningFlag: true},
+		},
+		{
+			name:   "no warning without dev mode",
+			values: map[string]interface{}{"dev": false},
+		},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			l := logrusx.New("", "")
+			hook := new(test.Hook)
+			l.Logger.Hooks.Add(hook)
+
+			c := config.MustNew(t, l, os.Stderr, &contextx.Default{}, configx.WithValues(tc.values), configx.SkipValidation())
+			require.NoError(t, checkStartupConfig(ctx, c, l))
+
+			assert.Equal(t, tc.expect, hasDevWarning(hook))
+			// the config version warning is never suppressed
+			assert.Contains(t, hook.LastEntry().Message, "version")
+		})
+	}
+
+	t.Run("case=strict version", func(t *testing.T) {
+		version := config.Version
+		config.Version = "v1.1.0"
+		t.Cleanup(func() {
+			config.Version = version
+		})
+
+		for _, tc := range []struct {
+			name   string
+			values map[string]interface{}
+		}{
+			{name: "mismatch", values: map[string]interface{}{config.ViperKeyVersion: "v1.0.0"}},
+			{name: "missing", values: map[string]interface{}{}},
+		} {
+			t.Run("case="+tc.name, func(t *testing.T) {
+				l := logrusx.New("", "")
+				hook := new(test.Hook)
+				l.Logger.Hooks.Add(hook)
+
+				c := config.MustNew(t, l, os.Stderr, &contextx.Default{}, configx.WithValues(tc.values), configx.SkipValidation())
+				require.NoError(t, checkStartupConfig(ctx, c, l))
+				assert.Contains(t, hook.LastEntry().Message, "version")
+
+				tc.values[config.ViperKeyStrictVersion] = true
+				c = config.MustNew(t, l, os.Stderr, &contextx.Default{}, configx.WithValues(tc.values), configx.SkipValidation())
+				err := checkStartupConfig(ctx, c, l)
+				var versionErr *config.VersionError
+				require.ErrorAs(t, err, &versionErr)
+				assert.Equal(t, "v1.1.0", versionErr.RuntimeVersion)
+			})
+		}
+
+		t.Run("case=matching version passes", func(t *testing.T) {
+			l := logrusx.New("", "")
+			c := config.MustNew(t, l, os.Stderr, &contextx.Default{}, configx.WithValues(map[string]interface{}{
+				config.ViperKeyVersion:       "v1.1.0",
+				config.ViperKeyStrictVersion: true,
+			}), configx.SkipValidation())
+			require.NoError(t, checkStartupConfig(ctx, c, l))
+		})
+	})
+}
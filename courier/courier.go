@@ -8,11 +8,14 @@ import (
 	"time"
 
 	"github.com/ory/x/jsonnetsecure"
+	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
 
 	"github.com/cenkalti/backoff"
 	"github.com/gofrs/uuid"
 	"github.com/pkg/errors"
 
+	"github.com/ory/herodot"
 	"github.com/ory/kratos/courier/template"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/x"
@@ -30,8 +33,9 @@ type (
 
 	Courier interface {
 		Work(ctx context.Context) error
-		QueueEmail(ctx context.Context, t EmailTemplate) (uuid.UUID, error)
-		QueueSMS(ctx context.Context, t SMSTemplate) (uuid.UUID, error)
+		QueueEmail(ctx context.Context, t EmailTemplate, opts ...QueueOption) (uuid.UUID, error)
+		QueueSMS(ctx context.Context, t SMSTemplate, opts ...QueueOption) (uuid.UUID, error)
+		QueueWithFallback(ctx context.Context, ts []Template, opts ...QueueOption) (uuid.UUID, error)
 		DispatchQueue(ctx context.Context) error
 		DispatchMessage(ctx context.Context, msg Message) error
 		UseBackoff(b backoff.BackOff)
@@ -42,6 +46,9 @@ type (
 		Courier(ctx context.Context) (Courier, error)
 	}
 
+	// QueueOption configures a message before it is queued.
+	QueueOption func(m *Message)
+
 	ConfigProvider interface {
 		CourierConfig() config.CourierConfigs
 	}
@@ -111,6 +118,92 @@ func (c *courier) UseBackoff(b backoff.BackOff) {
 	c.backoff = b
 }
 
+// WithSendAt schedules the message to be dispatched no earlier than the given
+// time. A zero time or a time in the past dispatches the message immediately.
+func WithSendAt(sendAt time.Time) QueueOption {
+	return func(m *Message) {
+		if sendAt.IsZero() {
+			return
+		}
+		m.SendAfter = sqlxx.NullTime(sendAt.UTC())
+	}
+}
+
+// QueueWithFallback queues a message for the first template. If the courier fails to deliver
+// it, the message is abandoned and a message for the next template is queued instead, and so
+// on. Templates which can not be rendered are skipped.
+func (c *courier) QueueWithFallback(ctx context.Context, ts []Template, opts ...QueueOption) (uuid.UUID, error) {
+	var (
+		messages  []*Message
+		renderErr error
+	)
+	for _, t := range ts {
+		m, err := newMessage(ctx, t)
+		if err != nil {
+			c.deps.Logger().
+				WithError(err).
+				WithField("message_template_type", t.TemplateType()).
+				Warn("Unable to render courier message, falling back to the next template.")
+			renderErr = err
+			continue
+		}
+		messages = append(messages, m)
+	}
+
+	if len(messages) == 0 {
+		if renderErr != nil {
+			return uuid.Nil, renderErr
+		}
+		return uuid.Nil, errors.WithStack(herodot.ErrInternalServerError.WithReason("No courier message templates were given."))
+	}
+
+	first := messages[0]
+	for _, m := range messages[1:] {
+		first.FallbackMessages = append(first.FallbackMessages, m.toFallback())
+	}
+
+	return c.addMessage(ctx, first, opts...)
+}
+
+func newMessage(ctx context.Context, t Template) (*Message, error) {
+	switch t := t.(type) {
+	case EmailTemplate:
+		return newEmailMessage(ctx, t)
+	case SMSTemplate:
+		return newSMSMessage(ctx, t)
+	default:
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Expected email or sms template but got %T", t))
+	}
+}
+
+// addMessage persists the message to the queue. If `courier.deduplicate_window` is set and a
+// message with the same recipient, template type, body, and schedule was queued or sent within
+// the window, the existing message's ID is returned instead and nothing is queued.
+func (c *courier) addMessage(ctx context.Context, m *Message, opts ...QueueOption) (uuid.UUID, error) {
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if window := c.deps.CourierConfig().CourierDeduplicateWindow(ctx); window > 0 {
+		dup, err := c.deps.CourierPersister().FindDuplicateMessage(ctx, m, time.Now().UTC().Add(-window))
+		if err == nil {
+			c.deps.Logger().
+				WithField("message_id", dup.ID).
+				WithField("message_template_type", m.TemplateType).
+				Debug("Not queueing courier message because an identical message was queued recently.")
+			return dup.ID, nil
+		} else if !errors.Is(err, sqlcon.ErrNoRows) {
+			return uuid.Nil, err
+		}
+	}
+
+	if err := c.deps.CourierPersister().AddMessage(ctx, m); err != nil {
+		return uuid.Nil, err
+	}
+
+	return m.ID, nil
+}
+
 func (c *courier) watchMessages(ctx context.Context, errChan chan error) {
 	wait := c.deps.CourierConfig().CourierWorkerPullWait(ctx)
 	c.backoff.Reset()
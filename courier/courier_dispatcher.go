@@ -5,6 +5,7 @@ package courier
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -79,7 +80,24 @@ func (c *courier) DispatchQueue(ctx context.Context) error {
 			// Skip the message
 			logger.
 				Warnf(`Message was abandoned because it did not deliver after %d attempts`, msg.SendCount)
+
+			if err := c.queueFallback(ctx, msg); err != nil {
+				logger.
+					WithError(err).
+					Error(`Unable to queue the fallback of the abandoned message.`)
+				return err
+			}
 		} else if err := c.DispatchMessage(ctx, msg); err != nil {
+			var retryAfter *retryAfterError
+			deferred := errors.As(err, &retryAfter)
+			if deferred {
+				if err := c.deps.CourierPersister().SetMessageSendAfter(ctx, msg.ID, time.Now().Add(retryAfter.retryAfter)); err != nil {
+					logger.
+						WithError(err).
+						Error(`Unable to defer the next delivery attempt of the message.`)
+				}
+			}
+
 			if err := c.deps.CourierPersister().RecordDispatch(ctx, msg.ID, CourierMessageDispatchStatusFailed, err); err != nil {
 				logger.
 					WithError(err).
@@ -89,7 +107,22 @@ func (c *courier) DispatchQueue(ctx context.Context) error {
 				}
 			}
 
-			for _, replace := range messages[k:] {
+			requeue := messages[k:]
+			if !deferred && len(msg.FallbackMessages) > 0 {
+				// Instead of retrying the message, the next fallback message is attempted.
+				if err := c.fallBack(ctx, msg); err != nil {
+					logger.
+						WithError(err).
+						Error(`Unable to fall back to the next message.`)
+					if c.failOnDispatchError {
+						return err
+					}
+				} else {
+					requeue = messages[k+1:]
+				}
+			}
+
+			for _, replace := range requeue {
 				if err := c.deps.CourierPersister().SetMessageStatus(ctx, replace.ID, MessageStatusQueued); err != nil {
 					logger.
 						WithError(err).
@@ -113,3 +146,32 @@ func (c *courier) DispatchQueue(ctx context.Context) error {
 
 	return nil
 }
+
+// fallBack abandons a message which could not be delivered and queues its next
+// fallback message instead.
+func (c *courier) fallBack(ctx context.Context, msg Message) error {
+	if err := c.queueFallback(ctx, msg); err != nil {
+		return err
+	}
+
+	return c.deps.CourierPersister().SetMessageStatus(ctx, msg.ID, MessageStatusAbandoned)
+}
+
+// queueFallback queues the next fallback message of a message, if it has one.
+func (c *courier) queueFallback(ctx context.Context, msg Message) error {
+	next := msg.nextFallback()
+	if next == nil {
+		return nil
+	}
+
+	if err := c.deps.CourierPersister().AddMessage(ctx, next); err != nil {
+		return err
+	}
+
+	c.deps.Logger().
+		WithField("message_id", msg.ID).
+		WithField("fallback_message_id", next.ID).
+		WithField("fallback_message_type", next.Type).
+		Info("Courier message could not be delivered, queued the next fallback message.")
+	return nil
+}
@@ -5,18 +5,26 @@ package courier_test
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
 
 	"github.com/ory/kratos/courier"
 	"github.com/ory/kratos/courier/template"
 	templates "github.com/ory/kratos/courier/template/email"
+	smsTemplates "github.com/ory/kratos/courier/template/sms"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/internal"
 	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/x"
 )
 
 func queueNewMessage(t *testing.T, ctx context.Context, c courier.Courier, d template.Dependencies) uuid.UUID {
@@ -35,7 +43,7 @@ func TestDispatchMessageWithInvalidSMTP(t *testing.T) {
 
 	conf, reg := internal.NewRegistryDefaultWithDSN(t, "")
 	conf.MustSet(ctx, config.ViperKeyCourierMessageRetries, 5)
-	conf.MustSet(ctx, config.ViperKeyCourierSMTPURL, "http://foo.url")
+	conf.MustSet(ctx, config.ViperKeyCourierSMTPURL, "smtp://foo.url")
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -64,7 +72,7 @@ func TestDispatchMessage(t *testing.T) {
 
 	conf, reg := internal.NewRegistryDefaultWithDSN(t, "")
 	conf.MustSet(ctx, config.ViperKeyCourierMessageRetries, 5)
-	conf.MustSet(ctx, config.ViperKeyCourierSMTPURL, "http://foo.url")
+	conf.MustSet(ctx, config.ViperKeyCourierSMTPURL, "smtp://foo.url")
 
 	ctx, cancel := context.WithCancel(ctx)
 	t.Cleanup(cancel)
@@ -127,3 +135,280 @@ func TestDispatchQueue(t *testing.T) {
 	require.Contains(t, gjson.GetBytes(message.Dispatches[0].Error, "reason").String(), "failed to send email via smtp")
 	require.Contains(t, gjson.GetBytes(message.Dispatches[1].Error, "reason").String(), "failed to send email via smtp")
 }
+
+func TestDispatchScheduledMessage(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "http")
+	conf.MustSet(ctx, config.ViperKeyCourierHTTPRequestConfig, fmt.Sprintf(`{"url": "%s", "method": "POST"}`, srv.URL))
+
+	c, err := reg.Courier(ctx)
+	require.NoError(t, err)
+
+	queue := func(t *testing.T, opts ...courier.QueueOption) uuid.UUID {
+		id, err := c.QueueEmail(ctx, templates.NewTestStub(reg, &templates.TestStubModel{
+			To:      testhelpers.RandomEmail(),
+			Subject: "test-subject",
+			Body:    "test-body",
+		}), opts...)
+		require.NoError(t, err)
+		return id
+	}
+
+	t.Run("case=future message is not sent before its time", func(t *testing.T) {
+		calls = 0
+		id := queue(t, courier.WithSendAt(time.Now().Add(time.Hour)))
+
+		require.NoError(t, c.DispatchQueue(ctx))
+		assert.Equal(t, 0, calls)
+
+		message, err := reg.CourierPersister().FetchMessage(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, courier.MessageStatusQueued, message.Status)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), time.Time(message.SendAfter), 10*time.Second)
+
+		require.NoError(t, reg.CourierPersister().SetMessageSendAfter(ctx, id, time.Now().Add(-time.Second)))
+		require.NoError(t, c.DispatchQueue(ctx))
+		assert.Equal(t, 1, calls)
+
+		message, err = reg.CourierPersister().FetchMessage(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, courier.MessageStatusSent, message.Status)
+	})
+
+	t.Run("case=past message is sent immediately", func(t *testing.T) {
+		calls = 0
+		id := queue(t, courier.WithSendAt(time.Now().Add(-time.Hour)))
+
+		require.NoError(t, c.DispatchQueue(ctx))
+		assert.Equal(t, 1, calls)
+
+		message, err := reg.CourierPersister().FetchMessage(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, courier.MessageStatusSent, message.Status)
+	})
+}
+
+func TestDispatchQueueWithFallback(t *testing.T) {
+	ctx := context.Background()
+
+	var delivered []string
+	newServer := func(status int) string {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if status < 300 {
+				delivered = append(delivered, gjson.GetBytes(x.MustReadAll(r.Body), "recipient").String())
+			}
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+	requestConfig := func(url string) string {
+		return fmt.Sprintf(`{"url": "%s", "method": "POST", "body": "base64://%s"}`,
+			url, base64.StdEncoding.EncodeToString([]byte(`function(ctx) { recipient: ctx.recipient }`)))
+	}
+
+	// The email channel rejects all messages, the SMS channel delivers them.
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeyCourierMessageRetries, 5)
+	conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "http")
+	conf.MustSet(ctx, config.ViperKeyCourierHTTPRequestConfig, requestConfig(newServer(http.StatusBadRequest)))
+	conf.MustSet(ctx, config.ViperKeyCourierChannels, fmt.Sprintf(`[{"id": "sms", "type": "http", "request_config": %s}]`, requestConfig(newServer(http.StatusOK))))
+
+	c, err := reg.Courier(ctx)
+	require.NoError(t, err)
+
+	newEmail := func(to string) courier.Template {
+		return templates.NewTestStub(reg, &templates.TestStubModel{To: to, Subject: "test-subject", Body: "test-body"})
+	}
+	newSMS := func(to string) courier.Template {
+		return smsTemplates.NewTestStub(reg, &smsTemplates.TestStubModel{To: to, Body: "test-body"})
+	}
+	findMessage := func(t *testing.T, recipient string) *courier.Message {
+		messages, _, _, err := reg.CourierPersister().ListMessages(ctx, courier.ListCourierMessagesParameters{Recipient: recipient}, nil)
+		require.NoError(t, err)
+		require.Len(t, messages, 1)
+		return &messages[0]
+	}
+
+	t.Run("case=failed message falls back to the next message", func(t *testing.T) {
+		delivered = nil
+		email, phone := testhelpers.RandomEmail(), "+12065550101"
+		id, err := c.QueueWithFallback(ctx, []courier.Template{newEmail(email), newSMS(phone)})
+		require.NoError(t, err)
+
+		require.NoError(t, c.DispatchQueue(ctx))
+		assert.Empty(t, delivered)
+
+		message, err := reg.CourierPersister().FetchMessage(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, courier.MessageStatusAbandoned, message.Status, "the failed message is not retried")
+		require.Len(t, message.Dispatches, 1)
+		assert.Equal(t, courier.CourierMessageDispatchStatusFailed, message.Dispatches[0].Status)
+
+		fallback := findMessage(t, phone)
+		assert.Equal(t, courier.MessageStatusQueued, fallback.Status)
+		assert.Equal(t, courier.MessageTypeSMS, fallback.Type)
+		assert.Empty(t, fallback.FallbackMessages)
+
+		require.NoError(t, c.DispatchQueue(ctx))
+		assert.Equal(t, []string{phone}, delivered)
+		assert.Equal(t, courier.MessageStatusSent, findMessage(t, phone).Status)
+	})
+
+	t.Run("case=delivered message stops the chain", func(t *testing.T) {
+		delivered = nil
+		phone, email := "+12065550102", testhelpers.RandomEmail()
+		_, err := c.QueueWithFallback(ctx, []courier.Template{newSMS(phone), newEmail(email)})
+		require.NoError(t, err)
+
+		require.NoError(t, c.DispatchQueue(ctx))
+		require.NoError(t, c.DispatchQueue(ctx))
+		assert.Equal(t, []string{phone}, delivered)
+		assert.Equal(t, courier.MessageStatusSent, findMessage(t, phone).Status)
+
+		messages, _, _, err := reg.CourierPersister().ListMessages(ctx, courier.ListCourierMessagesParameters{Recipient: email}, nil)
+		require.NoError(t, err)
+		assert.Empty(t, messages)
+	})
+
+	t.Run("case=abandoned message falls back to the next message", func(t *testing.T) {
+		delivered = nil
+		email, phone := testhelpers.RandomEmail(), "+12065550103"
+		id, err := c.QueueWithFallback(ctx, []courier.Template{newEmail(email), newSMS(phone)})
+		require.NoError(t, err)
+
+		// The message was attempted too often, for example by a worker which crashed.
+		for range 6 {
+			require.NoError(t, reg.CourierPersister().IncrementMessageSendCount(ctx, id))
+		}
+
+		require.NoError(t, c.DispatchQueue(ctx))
+		assert.Equal(t, courier.MessageStatusAbandoned, findMessage(t, email).Status)
+		assert.Equal(t, courier.MessageStatusQueued, findMessage(t, phone).Status)
+	})
+
+	t.Run("case=templates which can not be rendered are skipped", func(t *testing.T) {
+		phone := "+12065550104"
+		_, err := c.QueueWithFallback(ctx, []courier.Template{newEmail("not-an-email"), newSMS(phone)})
+		require.NoError(t, err)
+		assert.Empty(t, findMessage(t, phone).FallbackMessages)
+
+		_, err = c.QueueWithFallback(ctx, []courier.Template{newEmail("not-an-email")})
+		require.Error(t, err)
+	})
+}
+
+func TestQueueDeduplication(t *testing.T) {
+	ctx := context.Background()
+
+	conf, reg := internal.NewRegistryDefaultWithDSN(t, "")
+
+	c, err := reg.Courier(ctx)
+	require.NoError(t, err)
+
+	countMessages := func(t *testing.T) int {
+		count, err := reg.Persister().GetConnection(ctx).Count(new(courier.Message))
+		require.NoError(t, err)
+		return count
+	}
+
+	t.Run("case=identical messages are queued twice without a window", func(t *testing.T) {
+		before := countMessages(t)
+		first := queueNewMessage(t, ctx, c, reg)
+		second := queueNewMessage(t, ctx, c, reg)
+		require.NotEqual(t, first, second)
+		require.Equal(t, before+2, countMessages(t))
+	})
+
+	t.Run("case=identical messages are queued once within the window", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyCourierDeduplicateWindow, "1m")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyCourierDeduplicateWindow, nil)
+		})
+
+		queue := func(to, body string, opts ...courier.QueueOption) uuid.UUID {
+			id, err := c.QueueEmail(ctx, templates.NewTestStub(reg, &templates.TestStubModel{
+				To:      to,
+				Subject: "test-subject-1",
+				Body:    body,
+			}), opts...)
+			require.NoError(t, err)
+			return id
+		}
+
+		before := countMessages(t)
+		first := queue("test-recipient-dedup@example.org", "test-body-1")
+		second := queue("test-recipient-dedup@example.org", "test-body-1")
+		require.Equal(t, first, second)
+		require.Equal(t, before+1, countMessages(t))
+
+		third := queue("test-recipient-dedup@example.org", "test-body-2")
+		require.NotEqual(t, first, third)
+		require.Equal(t, before+2, countMessages(t))
+
+		fourth := queue("test-recipient-other@example.org", "test-body-1")
+		require.NotEqual(t, first, fourth)
+		require.Equal(t, before+3, countMessages(t))
+	})
+
+	t.Run("case=a resent recovery code is queued within the window", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyCourierDeduplicateWindow, "1m")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyCourierDeduplicateWindow, nil)
+		})
+
+		queue := func(code string) uuid.UUID {
+			id, err := c.QueueEmail(ctx, templates.NewRecoveryCodeValid(reg, &templates.RecoveryCodeValidModel{
+				To:           "test-recipient-recovery@example.org",
+				RecoveryCode: code,
+			}))
+			require.NoError(t, err)
+			return id
+		}
+
+		before := countMessages(t)
+		first := queue("123456")
+		require.Equal(t, first, queue("123456"))
+		require.Equal(t, before+1, countMessages(t))
+
+		require.NotEqual(t, first, queue("654321"), "a new code must be delivered")
+		require.Equal(t, before+2, countMessages(t))
+	})
+
+	t.Run("case=scheduled messages are not deduplicated against immediate ones", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyCourierDeduplicateWindow, "1m")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyCourierDeduplicateWindow, nil)
+		})
+
+		queue := func(opts ...courier.QueueOption) uuid.UUID {
+			id, err := c.QueueEmail(ctx, templates.NewTestStub(reg, &templates.TestStubModel{
+				To:      "test-recipient-scheduled@example.org",
+				Subject: "test-subject-1",
+				Body:    "test-body-1",
+			}), opts...)
+			require.NoError(t, err)
+			return id
+		}
+
+		sendAt := time.Now().Add(time.Hour).Truncate(time.Second)
+		before := countMessages(t)
+		immediate := queue()
+		scheduled := queue(courier.WithSendAt(sendAt))
+		require.NotEqual(t, immediate, scheduled)
+		require.Equal(t, before+2, countMessages(t))
+
+		assert.Equal(t, scheduled, queue(courier.WithSendAt(sendAt)), "the same schedule is deduplicated")
+		assert.Equal(t, immediate, queue(), "an immediate message is not swallowed by a scheduled one")
+		assert.Equal(t, before+2, countMessages(t))
+	})
+}
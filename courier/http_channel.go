@@ -7,7 +7,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/hashicorp/go-retryablehttp"
 	"github.com/tidwall/gjson"
 
 	"github.com/pkg/errors"
@@ -36,6 +40,62 @@ type (
 
 var _ Channel = new(httpChannel)
 
+// retryAfterError is returned if the upstream server asked us to retry the
+// delivery later using the Retry-After header.
+type retryAfterError struct {
+	error
+	retryAfter time.Duration
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.error
+}
+
+// maxRetryAfter caps how long the upstream server can postpone the delivery of
+// a message, so that a misbehaving server can not park messages indefinitely.
+const maxRetryAfter = time.Hour
+
+// parseRetryAfter parses the Retry-After header, which is either a number of
+// seconds or an HTTP date. The result is capped at maxRetryAfter.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > int64(maxRetryAfter/time.Second) {
+			return maxRetryAfter, true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if d := at.Sub(now); d > maxRetryAfter {
+		return maxRetryAfter, true
+	} else if d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// checkRetry hands 429 and 503 responses carrying a Retry-After header back to
+// the courier, which schedules the next attempt instead of blocking the worker.
+func checkRetry(ctx context.Context, res *http.Response, err error) (bool, error) {
+	if err == nil && res != nil &&
+		(res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable) &&
+		res.Header.Get("Retry-After") != "" {
+		return false, nil
+	}
+	return retryablehttp.DefaultRetryPolicy(ctx, res, err)
+}
+
 func newHttpChannel(id string, requestConfig json.RawMessage, d channelDependencies) *httpChannel {
 	return &httpChannel{
 		id:            id,
@@ -86,10 +146,14 @@ func (c *httpChannel) Dispatch(ctx context.Context, msg Message) (err error) {
 	}
 	req = req.WithContext(ctx)
 
-	res, err := c.d.HTTPClient(ctx).Do(req)
+	client := c.d.HTTPClient(ctx)
+	client.CheckRetry = checkRetry
+
+	res, err := client.Do(req)
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	defer res.Body.Close()
 
 	logger := c.d.Logger().
 		WithField("http_server", gjson.GetBytes(c.requestConfig, "url").String()).
@@ -111,6 +175,13 @@ func (c *httpChannel) Dispatch(ctx context.Context, msg Message) (err error) {
 	logger.
 		WithError(err).
 		Error("sending mail via HTTP failed.")
+
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable {
+		if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			return errors.WithStack(&retryAfterError{error: err, retryAfter: retryAfter})
+		}
+	}
+
 	return errors.WithStack(err)
 }
 
@@ -19,6 +19,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ory/kratos/courier"
 	"github.com/ory/kratos/courier/template/email"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/internal"
@@ -89,7 +90,7 @@ func TestQueueHTTPEmail(t *testing.T) {
 	conf, reg := internal.NewFastRegistryWithMocks(t)
 	conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "http")
 	conf.MustSet(ctx, config.ViperKeyCourierHTTPRequestConfig, requestConfig)
-	conf.MustSet(ctx, config.ViperKeyCourierSMTPURL, "http://foo.url")
+	conf.MustSet(ctx, config.ViperKeyCourierSMTPURL, "smtp://foo.url")
 	reg.Logger().Level = logrus.TraceLevel
 
 	courier, err := reg.Courier(ctx)
@@ -123,3 +124,92 @@ func TestQueueHTTPEmail(t *testing.T) {
 		assert.Equal(t, x.Must(expected.EmailSubject(ctx)), message.Subject)
 	}
 }
+
+func TestHTTPRetryAfter(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "http")
+	conf.MustSet(ctx, config.ViperKeyCourierHTTPRequestConfig, fmt.Sprintf(`{"url": "%s", "method": "POST"}`, srv.URL))
+	conf.MustSet(ctx, config.ViperKeyCourierMessageRetries, 5)
+
+	c, err := reg.Courier(ctx)
+	require.NoError(t, err)
+
+	id, err := c.QueueEmail(ctx, email.NewTestStub(reg, &email.TestStubModel{
+		To:      "test-retry-after@test.com",
+		Subject: "test-subject",
+		Body:    "test-body",
+	}))
+	require.NoError(t, err)
+
+	require.NoError(t, c.DispatchQueue(ctx))
+	require.Equal(t, 1, calls)
+
+	message, err := reg.CourierPersister().FetchMessage(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, courier.MessageStatusQueued, message.Status)
+	require.False(t, time.Time(message.SendAfter).IsZero())
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), time.Time(message.SendAfter), 10*time.Second)
+
+	// The provider asked us to wait, so the message is not retried yet.
+	require.NoError(t, c.DispatchQueue(ctx))
+	assert.Equal(t, 1, calls)
+
+	require.NoError(t, reg.CourierPersister().SetMessageSendAfter(ctx, id, time.Now().Add(-time.Second)))
+	require.NoError(t, c.DispatchQueue(ctx))
+	assert.Equal(t, 2, calls)
+
+	message, err = reg.CourierPersister().FetchMessage(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, courier.MessageStatusSent, message.Status)
+}
+
+func TestHTTPRetryAfterIsCapped(t *testing.T) {
+	ctx := context.Background()
+
+	for _, retryAfter := range []string{
+		"99999999999999999",
+		time.Now().Add(365 * 24 * time.Hour).UTC().Format(http.TimeFormat),
+	} {
+		t.Run("retry_after="+retryAfter, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", retryAfter)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			t.Cleanup(srv.Close)
+
+			conf, reg := internal.NewFastRegistryWithMocks(t)
+			conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "http")
+			conf.MustSet(ctx, config.ViperKeyCourierHTTPRequestConfig, fmt.Sprintf(`{"url": "%s", "method": "POST"}`, srv.URL))
+
+			c, err := reg.Courier(ctx)
+			require.NoError(t, err)
+
+			id, err := c.QueueEmail(ctx, email.NewTestStub(reg, &email.TestStubModel{
+				To:      "test-retry-after-capped@test.com",
+				Subject: "test-subject",
+				Body:    "test-body",
+			}))
+			require.NoError(t, err)
+			require.NoError(t, c.DispatchQueue(ctx))
+
+			message, err := reg.CourierPersister().FetchMessage(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, courier.MessageStatusQueued, message.Status)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), time.Time(message.SendAfter), 10*time.Second)
+		})
+	}
+}
@@ -5,6 +5,7 @@ package courier
 
 import (
 	"context"
+	"database/sql/driver"
 	"encoding/json"
 	"time"
 
@@ -194,6 +195,15 @@ type Message struct {
 	// required: true
 	SendCount int `json:"send_count" db:"send_count"`
 
+	// SendAfter is set if the message was scheduled for later, or if the
+	// upstream provider asked to retry the delivery at a later point in time.
+	// The message is not dispatched before then.
+	SendAfter sqlxx.NullTime `json:"send_at" faker:"-" db:"send_after"`
+
+	// FallbackMessages are attempted in order if this message can not be
+	// delivered. Only the first one is queued, carrying the remaining ones.
+	FallbackMessages FallbackMessages `json:"-" faker:"-" db:"fallback_messages"`
+
 	// Dispatches store information about the attempts of delivering a message
 	// May contain an error if any happened, or just the `success` state.
 	Dispatches []MessageDispatch `json:"dispatches,omitempty" has_many:"courier_message_dispatches" order_by:"created_at desc" faker:"-"`
@@ -206,6 +216,65 @@ type Message struct {
 	UpdatedAt time.Time `json:"updated_at" faker:"-" db:"updated_at"`
 }
 
+// FallbackMessage is a rendered message which is queued if the message it
+// belongs to can not be delivered.
+type FallbackMessage struct {
+	Type         MessageType           `json:"type"`
+	Channel      sqlxx.NullString      `json:"channel"`
+	Recipient    string                `json:"recipient"`
+	Subject      string                `json:"subject"`
+	Body         string                `json:"body"`
+	TemplateType template.TemplateType `json:"template_type"`
+	TemplateData []byte                `json:"template_data"`
+}
+
+// FallbackMessages is stored as JSON.
+type FallbackMessages []FallbackMessage
+
+func (f *FallbackMessages) Scan(value interface{}) error {
+	return sqlxx.JSONScan(f, value)
+}
+
+func (f FallbackMessages) Value() (driver.Value, error) {
+	if len(f) == 0 {
+		return nil, nil
+	}
+	return sqlxx.JSONValue(f)
+}
+
+func (m *Message) toFallback() FallbackMessage {
+	return FallbackMessage{
+		Type:         m.Type,
+		Channel:      m.Channel,
+		Recipient:    m.Recipient,
+		Subject:      m.Subject,
+		Body:         m.Body,
+		TemplateType: m.TemplateType,
+		TemplateData: m.TemplateData,
+	}
+}
+
+// nextFallback returns the first fallback message as a queued message which
+// carries the remaining fallback messages, or nil if there is none.
+func (m *Message) nextFallback() *Message {
+	if len(m.FallbackMessages) == 0 {
+		return nil
+	}
+
+	f := m.FallbackMessages[0]
+	return &Message{
+		Status:           MessageStatusQueued,
+		Type:             f.Type,
+		Channel:          f.Channel,
+		Recipient:        f.Recipient,
+		Subject:          f.Subject,
+		Body:             f.Body,
+		TemplateType:     f.TemplateType,
+		TemplateData:     f.TemplateData,
+		FallbackMessages: m.FallbackMessages[1:],
+	}
+}
+
 func (m Message) PageToken() keysetpagination.PageToken {
 	return keysetpagination.MapPageToken{
 		"id":         m.ID.String(),
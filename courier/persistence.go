@@ -5,6 +5,7 @@ package courier
 
 import (
 	"context"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/pkg/errors"
@@ -24,8 +25,19 @@ type (
 
 		LatestQueuedMessage(ctx context.Context) (*Message, error)
 
+		// FindDuplicateMessage returns a queued, processing, or sent message which was created
+		// after `since` and has the same recipient, template type, and body as the given message.
+		// A scheduled message only matches messages scheduled for the same time, and an
+		// unscheduled message only matches messages which are already due.
+		// Returns sqlcon.ErrNoRows if no such message exists.
+		FindDuplicateMessage(ctx context.Context, m *Message, since time.Time) (*Message, error)
+
 		IncrementMessageSendCount(context.Context, uuid.UUID) error
 
+		// SetMessageSendAfter defers the next delivery attempt of a message
+		// until the given time.
+		SetMessageSendAfter(context.Context, uuid.UUID, time.Time) error
+
 		// ListMessages lists all messages in the store given the page, itemsPerPage, status and recipient.
 		// Returns list of messages, total count of messages satisfied by given filter, and error if any
 		ListMessages(context.Context, ListCourierMessagesParameters, []keysetpagination.Option) ([]Message, int64, *keysetpagination.Paginator, error)
@@ -10,23 +10,33 @@ import (
 	"github.com/gofrs/uuid"
 )
 
-func (c *courier) QueueSMS(ctx context.Context, t SMSTemplate) (uuid.UUID, error) {
-	recipient, err := t.PhoneNumber()
+func (c *courier) QueueSMS(ctx context.Context, t SMSTemplate, opts ...QueueOption) (uuid.UUID, error) {
+	message, err := newSMSMessage(ctx, t)
 	if err != nil {
 		return uuid.Nil, err
 	}
 
+	return c.addMessage(ctx, message, opts...)
+}
+
+// newSMSMessage renders the template into a queued SMS message.
+func newSMSMessage(ctx context.Context, t SMSTemplate) (*Message, error) {
+	recipient, err := t.PhoneNumber()
+	if err != nil {
+		return nil, err
+	}
+
 	templateData, err := json.Marshal(t)
 	if err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
 
 	body, err := t.SMSBody(ctx)
 	if err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
 
-	message := &Message{
+	return &Message{
 		Status:       MessageStatusQueued,
 		Type:         MessageTypeSMS,
 		Channel:      "sms",
@@ -34,10 +44,5 @@ func (c *courier) QueueSMS(ctx context.Context, t SMSTemplate) (uuid.UUID, error
 		TemplateType: t.TemplateType(),
 		TemplateData: templateData,
 		Body:         body,
-	}
-	if err := c.deps.CourierPersister().AddMessage(ctx, message); err != nil {
-		return uuid.Nil, err
-	}
-
-	return message.ID, nil
+	}, nil
 }
@@ -85,7 +85,7 @@ func TestQueueSMS(t *testing.T) {
 			"request_config": %s
 		}
 	]`, requestConfig))
-	conf.MustSet(ctx, config.ViperKeyCourierSMTPURL, "http://foo.url")
+	conf.MustSet(ctx, config.ViperKeyCourierSMTPURL, "smtp://foo.url")
 	reg.Logger().Level = logrus.TraceLevel
 
 	c, err := reg.Courier(ctx)
@@ -131,7 +131,7 @@ func TestDisallowedInternalNetwork(t *testing.T) {
 			}
 		}
 	]`)
-	conf.MustSet(ctx, config.ViperKeyCourierSMTPURL, "http://foo.url")
+	conf.MustSet(ctx, config.ViperKeyCourierSMTPURL, "smtp://foo.url")
 	conf.MustSet(ctx, config.ViperKeyClientHTTPNoPrivateIPRanges, true)
 
 	c, err := reg.Courier(ctx)
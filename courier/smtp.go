@@ -96,31 +96,41 @@ func NewSMTPClient(deps Dependencies, cfg *config.SMTPConfig) (*SMTPClient, erro
 	}, nil
 }
 
-func (c *courier) QueueEmail(ctx context.Context, t EmailTemplate) (uuid.UUID, error) {
-	recipient, err := t.EmailRecipient()
+func (c *courier) QueueEmail(ctx context.Context, t EmailTemplate, opts ...QueueOption) (uuid.UUID, error) {
+	message, err := newEmailMessage(ctx, t)
 	if err != nil {
 		return uuid.Nil, err
 	}
+
+	return c.addMessage(ctx, message, opts...)
+}
+
+// newEmailMessage renders the template into a queued email message.
+func newEmailMessage(ctx context.Context, t EmailTemplate) (*Message, error) {
+	recipient, err := t.EmailRecipient()
+	if err != nil {
+		return nil, err
+	}
 	if _, err := mail.ParseAddress(recipient); err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
 
 	subject, err := t.EmailSubject(ctx)
 	if err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
 
 	bodyPlaintext, err := t.EmailBodyPlaintext(ctx)
 	if err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
 
 	templateData, err := json.Marshal(t)
 	if err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
 
-	message := &Message{
+	return &Message{
 		Status:       MessageStatusQueued,
 		Type:         MessageTypeEmail,
 		Channel:      "email",
@@ -129,11 +139,5 @@ func (c *courier) QueueEmail(ctx context.Context, t EmailTemplate) (uuid.UUID, e
 		Subject:      subject,
 		TemplateType: t.TemplateType(),
 		TemplateData: templateData,
-	}
-
-	if err := c.deps.CourierPersister().AddMessage(ctx, message); err != nil {
-		return uuid.Nil, err
-	}
-
-	return message.ID, nil
+	}, nil
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/ory/kratos/courier/template"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/mail/v3"
+	"github.com/ory/x/otelx"
 )
 
 type (
@@ -47,7 +48,10 @@ func (c *SMTPChannel) ID() string {
 	return "email"
 }
 
-func (c *SMTPChannel) Dispatch(ctx context.Context, msg Message) error {
+func (c *SMTPChannel) Dispatch(ctx context.Context, msg Message) (err error) {
+	ctx, span := c.d.Tracer(ctx).Tracer().Start(ctx, "courier.SMTPChannel.Dispatch")
+	defer otelx.End(span, &err)
+
 	if c.smtpClient.Host == "" {
 		return errors.WithStack(herodot.ErrInternalServerError.WithErrorf("Courier tried to deliver an email but %s is not set!", config.ViperKeyCourierSMTPURL))
 	}
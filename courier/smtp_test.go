@@ -43,6 +43,12 @@ func TestNewSMTPClientPreventLeak(t *testing.T) {
 
 	invalidURL := "sm<>t>p://f%oo::bar:baz@my-server:1234:122/"
 	conf.MustSet(ctx, config.ViperKeyCourierSMTPURL, invalidURL)
+	_, err := conf.CourierChannels(ctx)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), invalidURL)
+
+	invalidURL = "smtp://f%oo::bar:baz@my-server:1234:122/"
+	conf.MustSet(ctx, config.ViperKeyCourierSMTPURL, invalidURL)
 	channels, err := conf.CourierChannels(ctx)
 	require.NoError(t, err)
 	require.Len(t, channels, 1)
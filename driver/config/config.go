@@ -6,15 +6,22 @@ package config
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -23,6 +30,7 @@ import (
 	"github.com/ory/x/crdbx"
 	"github.com/ory/x/pointerx"
 
+	"github.com/dgraph-io/ristretto"
 	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/gofrs/uuid"
@@ -30,7 +38,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/cors"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/net/publicsuffix"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/ory/herodot"
 	"github.com/ory/jsonschema/v3"
@@ -54,6 +66,8 @@ const (
 	DefaultPasswordHashingAlgorithm                          = "argon2"
 	DefaultCipherAlgorithm                                   = "noop"
 	UnknownVersion                                           = "unknown version"
+	LoginFlowStyleOneStep                                    = "one_step"
+	LoginFlowStylePasswordlessOrPassword                     = "passwordless_or_password"
 	ViperKeyDSN                                              = "dsn"
 	ViperKeyCourierSMTPURL                                   = "courier.smtp.connection_uri"
 	ViperKeyCourierSMTPClientCertPath                        = "courier.smtp.client_cert_path"
@@ -69,8 +83,11 @@ const (
 	ViperKeyCourierTemplatesVerificationCodeValidEmail       = "courier.templates.verification_code.valid.email"
 	ViperKeyCourierTemplatesVerificationCodeValidSMS         = "courier.templates.verification_code.valid.sms"
 	ViperKeyCourierTemplatesLoginCodeValidSMS                = "courier.templates.login_code.valid.sms"
+	ViperKeyCourierTemplatesRecoveryCodeValidSMS             = "courier.templates.recovery_code.valid.sms"
 	ViperKeyCourierDeliveryStrategy                          = "courier.delivery_strategy"
 	ViperKeyCourierHTTPRequestConfig                         = "courier.http.request_config"
+	ViperKeyCourierSMSStrategy                               = "courier.sms.strategy"
+	ViperKeyCourierSMSRequestConfig                          = "courier.sms.request_config"
 	ViperKeyCourierTemplatesLoginCodeValidEmail              = "courier.templates.login_code.valid.email"
 	ViperKeyCourierTemplatesRegistrationCodeValidEmail       = "courier.templates.registration_code.valid.email"
 	ViperKeyCourierSMTP                                      = "courier.smtp"
@@ -79,6 +96,7 @@ const (
 	ViperKeyCourierSMTPHeaders                               = "courier.smtp.headers"
 	ViperKeyCourierSMTPLocalName                             = "courier.smtp.local_name"
 	ViperKeyCourierMessageRetries                            = "courier.message_retries"
+	ViperKeyCourierDeduplicateWindow                         = "courier.deduplicate_window"
 	ViperKeyCourierWorkerPullCount                           = "courier.worker.pull_count"
 	ViperKeyCourierWorkerPullWait                            = "courier.worker.pull_wait"
 	ViperKeyCourierChannels                                  = "courier.channels"
@@ -96,6 +114,10 @@ const (
 	ViperKeyPublicTLSKeyBase64                               = "serve.public.tls.key.base64"
 	ViperKeyPublicTLSCertPath                                = "serve.public.tls.cert.path"
 	ViperKeyPublicTLSKeyPath                                 = "serve.public.tls.key.path"
+	ViperKeyPublicRequestTimeout                             = "serve.public.request_timeout"
+	ViperKeyPublicServerTiming                               = "serve.public.server_timing"
+	ViperKeyPublicJSONFieldCase                              = "serve.public.json_field_case"
+	ViperKeyPublicTrustedProxies                             = "serve.public.trusted_proxies"
 	ViperKeyDisableAdminHealthRequestLog                     = "serve.admin.request_log.disable_for_health"
 	ViperKeyAdminBaseURL                                     = "serve.admin.base_url"
 	ViperKeyAdminPort                                        = "serve.admin.port"
@@ -107,12 +129,16 @@ const (
 	ViperKeyAdminTLSKeyBase64                                = "serve.admin.tls.key.base64"
 	ViperKeyAdminTLSCertPath                                 = "serve.admin.tls.cert.path"
 	ViperKeyAdminTLSKeyPath                                  = "serve.admin.tls.key.path"
+	ViperKeyAdminRequestTimeout                              = "serve.admin.request_timeout"
 	ViperKeySessionLifespan                                  = "session.lifespan"
 	ViperKeySessionSameSite                                  = "session.cookie.same_site"
 	ViperKeySessionDomain                                    = "session.cookie.domain"
 	ViperKeySessionName                                      = "session.cookie.name"
 	ViperKeySessionPath                                      = "session.cookie.path"
 	ViperKeySessionPersistentCookie                          = "session.cookie.persistent"
+	ViperKeySessionCookieMaxAge                              = "session.cookie.max_age"
+	ViperKeySessionUseHostPrefix                             = "session.cookie.use_host_prefix"
+	ViperKeySessionIssuancePolicyURL                         = "session.issuance_policy.url"
 	ViperKeySessionTokenizerTemplates                        = "session.whoami.tokenizer.templates"
 	ViperKeySessionWhoAmIAAL                                 = "session.whoami.required_aal"
 	ViperKeySessionWhoAmICaching                             = "feature_flags.cacheable_sessions"
@@ -120,12 +146,19 @@ const (
 	ViperKeySessionWhoAmICachingMaxAge                       = "feature_flags.cacheable_sessions_max_age"
 	ViperKeyUseContinueWithTransitions                       = "feature_flags.use_continue_with_transitions"
 	ViperKeySessionRefreshMinTimeLeft                        = "session.earliest_possible_extend"
+	ViperKeySessionResignOnRead                              = "session.resign_on_read"
 	ViperKeyCookieSameSite                                   = "cookies.same_site"
 	ViperKeyCookieDomain                                     = "cookies.domain"
 	ViperKeyCookiePath                                       = "cookies.path"
 	ViperKeySelfServiceStrategyConfig                        = "selfservice.methods"
 	ViperKeySelfServiceBrowserDefaultReturnTo                = "selfservice." + DefaultBrowserReturnURL
+	ViperKeySelfServiceIdentifierInputNormalization          = "selfservice.identifier_input_normalization"
+	ViperKeySelfServiceIdentifierStorage                     = "selfservice.identifier_storage"
 	ViperKeyURLsAllowedReturnToDomains                       = "selfservice.allowed_return_urls"
+	ViperKeyURLsRequireHTTPSReturnTo                         = "selfservice.require_https_return_to"
+	ViperKeySelfServiceFlowsExpiredAsStatusForBrowser        = "selfservice.flows.expired_as_status_for_browser"
+	ViperKeySelfServiceFlowsCSRFTokenInHeader                = "selfservice.flows.csrf_token_in_header"
+	ViperKeySelfServiceFlowsInitRateLimitMaxPerMinute        = "selfservice.flows.init_rate_limit.max_per_minute"
 	ViperKeySelfServiceRegistrationEnabled                   = "selfservice.flows.registration.enabled"
 	ViperKeySelfServiceRegistrationLoginHints                = "selfservice.flows.registration.login_hints"
 	ViperKeySelfServiceRegistrationEnableLegacyOneStep       = "selfservice.flows.registration.enable_legacy_one_step"
@@ -133,8 +166,17 @@ const (
 	ViperKeySelfServiceRegistrationRequestLifespan           = "selfservice.flows.registration.lifespan"
 	ViperKeySelfServiceRegistrationAfter                     = "selfservice.flows.registration.after"
 	ViperKeySelfServiceRegistrationBeforeHooks               = "selfservice.flows.registration.before.hooks"
+	ViperKeySelfServiceRegistrationDefaultIdentityState      = "selfservice.flows.registration.default_identity_state"
+	ViperKeySelfServiceRegistrationIncludeAddresses          = "selfservice.flows.registration.include_addresses"
+	ViperKeySelfServiceRegistrationRequireConsent            = "selfservice.flows.registration.require_consent"
 	ViperKeySelfServiceLoginUI                               = "selfservice.flows.login.ui_url"
 	ViperKeySelfServiceLoginRequestLifespan                  = "selfservice.flows.login.lifespan"
+	ViperKeySelfServiceLoginFlowReuseWithin                  = "selfservice.flows.login.reuse_within"
+	ViperKeySelfServiceLoginHideUnavailableMFAMethods        = "selfservice.flows.login.hide_unavailable_mfa_methods"
+	ViperKeySelfServiceLoginEchoIdentifierOnError            = "selfservice.flows.login.echo_identifier_on_error"
+	ViperKeySelfServiceLoginMaxSubmissions                   = "selfservice.flows.login.max_submissions"
+	ViperKeySelfServiceLoginFlowStyle                        = "selfservice.flows.login.style"
+	ViperKeySelfServiceAPIDebugErrors                        = "selfservice.flows.api.debug_errors"
 	ViperKeySelfServiceLoginAfter                            = "selfservice.flows.login.after"
 	ViperKeySelfServiceLoginBeforeHooks                      = "selfservice.flows.login.before.hooks"
 	ViperKeySelfServiceErrorUI                               = "selfservice.flows.error.ui_url"
@@ -145,6 +187,8 @@ const (
 	ViperKeySelfServiceSettingsRequestLifespan               = "selfservice.flows.settings.lifespan"
 	ViperKeySelfServiceSettingsPrivilegedAuthenticationAfter = "selfservice.flows.settings.privileged_session_max_age"
 	ViperKeySelfServiceSettingsRequiredAAL                   = "selfservice.flows.settings.required_aal"
+	ViperKeySelfServiceSettingsOptimisticConcurrency         = "selfservice.flows.settings.optimistic_concurrency"
+	ViperKeySelfServiceSettingsReturnChangedTraits           = "selfservice.flows.settings.return_changed_traits"
 	ViperKeySelfServiceRecoveryAfter                         = "selfservice.flows.recovery.after"
 	ViperKeySelfServiceRecoveryBeforeHooks                   = "selfservice.flows.recovery.before.hooks"
 	ViperKeySelfServiceRecoveryEnabled                       = "selfservice.flows.recovery.enabled"
@@ -153,6 +197,9 @@ const (
 	ViperKeySelfServiceRecoveryRequestLifespan               = "selfservice.flows.recovery.lifespan"
 	ViperKeySelfServiceRecoveryBrowserDefaultReturnTo        = "selfservice.flows.recovery.after." + DefaultBrowserReturnURL
 	ViperKeySelfServiceRecoveryNotifyUnknownRecipients       = "selfservice.flows.recovery.notify_unknown_recipients"
+	ViperKeySelfServiceRecoveryChannelOrder                  = "selfservice.flows.recovery.channel_order"
+	ViperKeySelfServiceRecoveryMaxSubmitAttempts             = "selfservice.flows.recovery.max_submit_attempts"
+	ViperKeySelfServiceRecoveryUseOpaqueTokens               = "selfservice.flows.recovery.use_opaque_tokens"
 	ViperKeySelfServiceVerificationEnabled                   = "selfservice.flows.verification.enabled"
 	ViperKeySelfServiceVerificationUI                        = "selfservice.flows.verification.ui_url"
 	ViperKeySelfServiceVerificationRequestLifespan           = "selfservice.flows.verification.lifespan"
@@ -161,8 +208,13 @@ const (
 	ViperKeySelfServiceVerificationBeforeHooks               = "selfservice.flows.verification.before.hooks"
 	ViperKeySelfServiceVerificationUse                       = "selfservice.flows.verification.use"
 	ViperKeySelfServiceVerificationNotifyUnknownRecipients   = "selfservice.flows.verification.notify_unknown_recipients"
+	ViperKeySelfServiceVerificationMaxSubmitAttempts         = "selfservice.flows.verification.max_submit_attempts"
+	ViperKeySelfServiceVerificationRevalidateAfter           = "selfservice.flows.verification.revalidate_after"
+	ViperKeySelfServiceVerificationUseOpaqueTokens           = "selfservice.flows.verification.use_opaque_tokens"
 	ViperKeyDefaultIdentitySchemaID                          = "identity.default_schema_id"
 	ViperKeyIdentitySchemas                                  = "identity.schemas"
+	ViperKeyIdentityMaxCredentialConfigSize                  = "identity.max_credential_config_size"
+	ViperKeyIdentityTraitsPreserveNumberPrecision            = "identity.traits.preserve_number_precision"
 	ViperKeyHasherAlgorithm                                  = "hashers.algorithm"
 	ViperKeyHasherArgon2ConfigMemory                         = "hashers.argon2.memory"
 	ViperKeyHasherArgon2ConfigIterations                     = "hashers.argon2.iterations"
@@ -172,10 +224,13 @@ const (
 	ViperKeyHasherArgon2ConfigExpectedDuration               = "hashers.argon2.expected_duration"
 	ViperKeyHasherArgon2ConfigExpectedDeviation              = "hashers.argon2.expected_deviation"
 	ViperKeyHasherArgon2ConfigDedicatedMemory                = "hashers.argon2.dedicated_memory"
+	ViperKeyHasherArgon2ConfigAutotune                       = "hashers.argon2.autotune"
 	ViperKeyHasherBcryptCost                                 = "hashers.bcrypt.cost"
 	ViperKeyCipherAlgorithm                                  = "ciphers.algorithm"
 	ViperKeyDatabaseCleanupSleepTables                       = "database.cleanup.sleep.tables"
 	ViperKeyDatabaseCleanupBatchSize                         = "database.cleanup.batch_size"
+	ViperKeyDatabaseCleanupSessionsBatchSize                 = "database.cleanup.sessions.batch_size"
+	ViperKeyDatabaseCleanupSessionsSleep                     = "database.cleanup.sessions.sleep"
 	ViperKeyLinkLifespan                                     = "selfservice.methods.link.config.lifespan"
 	ViperKeyLinkBaseURL                                      = "selfservice.methods.link.config.base_url"
 	ViperKeyCodeLifespan                                     = "selfservice.methods.code.config.lifespan"
@@ -186,16 +241,31 @@ const (
 	ViperKeyPasswordIdentifierSimilarityCheckEnabled         = "selfservice.methods.password.config.identifier_similarity_check_enabled"
 	ViperKeyIgnoreNetworkErrors                              = "selfservice.methods.password.config.ignore_network_errors"
 	ViperKeyTOTPIssuer                                       = "selfservice.methods.totp.config.issuer"
+	ViperKeyTOTPEmitQRDataURI                                = "selfservice.methods.totp.config.emit_qr_data_uri"
 	ViperKeyOIDCBaseRedirectURL                              = "selfservice.methods.oidc.config.base_redirect_uri"
+	ViperKeyOIDCMaxProviders                                 = "selfservice.methods.oidc.config.max_providers"
 	ViperKeyWebAuthnRPDisplayName                            = "selfservice.methods.webauthn.config.rp.display_name"
 	ViperKeyWebAuthnRPID                                     = "selfservice.methods.webauthn.config.rp.id"
 	ViperKeyWebAuthnRPOrigin                                 = "selfservice.methods.webauthn.config.rp.origin"
 	ViperKeyWebAuthnRPOrigins                                = "selfservice.methods.webauthn.config.rp.origins"
 	ViperKeyWebAuthnPasswordless                             = "selfservice.methods.webauthn.config.passwordless"
+	ViperKeyWebAuthnSignCountPolicy                          = "selfservice.methods.webauthn.config.sign_count_policy"
+	ViperKeyWebAuthnLegacyAppID                              = "selfservice.methods.webauthn.config.legacy_appid"
+	ViperKeyWebAuthnChallengeLifespan                        = "selfservice.methods.webauthn.config.challenge_lifespan"
+	ViperKeyWebAuthnTenants                                  = "selfservice.methods.webauthn.config.tenants"
+	ViperKeyWebAuthnUseExternalScript                        = "selfservice.methods.webauthn.config.use_external_script"
+	ViperKeyWebAuthnAuthenticatorAttachment                  = "selfservice.methods.webauthn.config.authenticator_attachment"
+	ViperKeyWebAuthnUserVerification                         = "selfservice.methods.webauthn.config.user_verification"
+	ViperKeyWebAuthnMaxAllowCredentials                      = "selfservice.methods.webauthn.config.max_allow_credentials"
+	ViperKeyWebAuthnUserNameTrait                            = "selfservice.methods.webauthn.config.user_name_trait"
+	ViperKeyWebAuthnUserDisplayNameTrait                     = "selfservice.methods.webauthn.config.user_display_name_trait"
+	ViperKeyWebAuthnVerifyAttestation                        = "selfservice.methods.webauthn.config.verify_attestation"
 	ViperKeyPasskeyEnabled                                   = "selfservice.methods.passkey.enabled"
 	ViperKeyPasskeyRPDisplayName                             = "selfservice.methods.passkey.config.rp.display_name"
 	ViperKeyPasskeyRPID                                      = "selfservice.methods.passkey.config.rp.id"
 	ViperKeyPasskeyRPOrigins                                 = "selfservice.methods.passkey.config.rp.origins"
+	ViperKeyPasskeyTenants                                   = "selfservice.methods.passkey.config.tenants"
+	ViperKeyPasskeyChallengeLifespan                         = "selfservice.methods.passkey.config.challenge_lifespan"
 	ViperKeyOAuth2ProviderURL                                = "oauth2_provider.url"
 	ViperKeyOAuth2ProviderHeader                             = "oauth2_provider.headers"
 	ViperKeyOAuth2ProviderOverrideReturnTo                   = "oauth2_provider.override_return_to"
@@ -203,6 +273,11 @@ const (
 	ViperKeyClientHTTPPrivateIPExceptionURLs                 = "clients.http.private_ip_exception_urls"
 	ViperKeyPreviewDefaultReadConsistencyLevel               = "preview.default_read_consistency_level"
 	ViperKeyVersion                                          = "version"
+	ViperKeyLogSuppressDevWarning                            = "log.suppress_dev_warning"
+	ViperKeySuppressDevWarningFlag                           = "suppress-dev-warning"
+	ViperKeyStrictVersion                                    = "strict_version"
+	ViperKeyStrictVersionFlag                                = "strict-version"
+	ViperKeyMaintenanceReadOnly                              = "maintenance.read_only"
 )
 
 const (
@@ -220,6 +295,10 @@ const (
 // DefaultSessionCookieName returns the default cookie name for the kratos session.
 const DefaultSessionCookieName = "ory_kratos_session"
 
+// SessionCookieHostPrefix is the cookie name prefix which makes browsers only
+// accept the cookie if it is secure, has the path "/" and no domain.
+const SessionCookieHostPrefix = "__Host-"
+
 type (
 	Argon2 struct {
 		Memory            bytesize.ByteSize `json:"memory"`
@@ -237,6 +316,20 @@ type (
 	SelfServiceHook struct {
 		Name   string          `json:"hook"`
 		Config json.RawMessage `json:"config"`
+
+		// Priority controls the execution order of before hooks. Hooks with a
+		// higher priority run first, hooks with equal priority run in the
+		// order they are configured in.
+		Priority int `json:"priority,omitempty"`
+
+		// AllowedHeaders is set for web hooks which configure `allowed_headers`.
+		// Only these request headers are then passed to the web hook.
+		AllowedHeaders []string `json:"-"`
+	}
+	SelfServiceUIMessage struct {
+		ID   int    `json:"id,omitempty"`
+		Text string `json:"text"`
+		Type string `json:"type"`
 	}
 	SelfServiceStrategy struct {
 		Enabled bool            `json:"enabled"`
@@ -250,6 +343,21 @@ type (
 	Schema struct {
 		ID  string `json:"id" koanf:"id"`
 		URL string `json:"url" koanf:"url"`
+		// Extends is the ID of another identity schema whose traits are
+		// inherited by this schema.
+		Extends string `json:"extends,omitempty" koanf:"extends"`
+		// SelfServiceRegistrationAllowed controls whether the schema can be used
+		// for self-service registration. Defaults to true if unset.
+		SelfServiceRegistrationAllowed *bool `json:"selfservice_registration_allowed,omitempty" koanf:"selfservice_registration_allowed"`
+	}
+	WebAuthnAttestationVerification struct {
+		Enabled     bool   `json:"enabled"`
+		MetadataURL string `json:"metadata_url"`
+	}
+	RegistrationConsent struct {
+		Enabled bool   `json:"enabled"`
+		Label   string `json:"label"`
+		Version string `json:"version"`
 	}
 	PasswordPolicy struct {
 		HaveIBeenPwnedHost               string `json:"haveibeenpwned_host"`
@@ -259,7 +367,13 @@ type (
 		MinPasswordLength                uint   `json:"min_password_length"`
 		IdentifierSimilarityCheckEnabled bool   `json:"identifier_similarity_check_enabled"`
 	}
-	Schemas                  []Schema
+	Schemas        []Schema
+	WebAuthnTenant struct {
+		Host        string   `json:"host" koanf:"host"`
+		ID          string   `json:"id" koanf:"id"`
+		DisplayName string   `json:"display_name" koanf:"display_name"`
+		Origins     []string `json:"origins" koanf:"origins"`
+	}
 	CourierEmailBodyTemplate struct {
 		PlainText string `json:"plaintext"`
 		HTML      string `json:"html"`
@@ -291,11 +405,25 @@ type (
 		LocalName      string            `json:"local_name" koanf:"local_name"`
 	}
 	Config struct {
-		l                  *logrusx.Logger
-		p                  *configx.Provider
-		c                  contextx.Contextualizer
-		identityMetaSchema *jsonschema.Schema
-		stdOutOrErr        io.Writer
+		l                    *logrusx.Logger
+		p                    *configx.Provider
+		c                    contextx.Contextualizer
+		identityMetaSchema   *jsonschema.Schema
+		identitySchemaReload *identitySchemaReload
+		extendedSchemas      *extendedSchemas
+		stdOutOrErr          io.Writer
+	}
+	identitySchemaReload struct {
+		sync.Mutex
+		loaded    Schemas
+		callbacks []func(ctx context.Context, schemas []Schema)
+	}
+	// extendedSchemas caches the resolved identity schemas so that extended
+	// schemas are only fetched when the identity schema configuration changes.
+	extendedSchemas struct {
+		sync.Mutex
+		key      string
+		resolved Schemas
 	}
 	Provider interface {
 		Config() *Config
@@ -314,7 +442,9 @@ type (
 		CourierTemplatesRegistrationCodeValid(ctx context.Context) *CourierEmailTemplate
 		CourierSMSTemplatesVerificationCodeValid(ctx context.Context) *CourierSMSTemplate
 		CourierSMSTemplatesLoginCodeValid(ctx context.Context) *CourierSMSTemplate
+		CourierSMSTemplatesRecoveryCodeValid(ctx context.Context) *CourierSMSTemplate
 		CourierMessageRetries(ctx context.Context) int
+		CourierDeduplicateWindow(ctx context.Context) time.Duration
 		CourierWorkerPullCount(ctx context.Context) int
 		CourierWorkerPullWait(ctx context.Context) time.Duration
 		CourierChannels(context.Context) ([]*CourierChannel, error)
@@ -357,6 +487,19 @@ func HookStrategyKey(key, strategy string) string {
 	}
 }
 
+// RegistrationAllowed returns whether the schema can be used for self-service
+// registration.
+func (s Schema) RegistrationAllowed() bool {
+	return s.SelfServiceRegistrationAllowed == nil || *s.SelfServiceRegistrationAllowed
+}
+
+// equal reports whether both schemas are configured the same way. Optional
+// settings are compared by their effective value, not by their address.
+func (s Schema) equal(o Schema) bool {
+	return s.ID == o.ID && s.URL == o.URL && s.Extends == o.Extends &&
+		s.RegistrationAllowed() == o.RegistrationAllowed()
+}
+
 func (s Schemas) FindSchemaByID(id string) (*Schema, error) {
 	for _, sc := range s {
 		if sc.ID == id {
@@ -378,7 +521,7 @@ func New(ctx context.Context, l *logrusx.Logger, stdOutOrErr io.Writer, ctxer co
 
 	opts = append([]configx.OptionModifier{
 		configx.WithStderrValidationReporter(),
-		configx.OmitKeysFromTracing("dsn", "courier.smtp.connection_uri", "secrets.default", "secrets.cookie", "secrets.cipher", "client_secret"),
+		configx.OmitKeysFromTracing(secretKeys...),
 		configx.WithImmutables("serve", "profiling", "log"),
 		configx.WithExceptImmutables("serve.public.cors.allowed_origins"),
 		configx.WithLogrusWatcher(l),
@@ -391,7 +534,9 @@ func New(ctx context.Context, l *logrusx.Logger, stdOutOrErr io.Writer, ctxer co
 			if err := c.validateIdentitySchemas(ctx); err != nil {
 				l.WithError(err).
 					Errorf("The changed identity schema configuration is invalid and could not be loaded. Rolling back to the last working configuration revision. Please address the validation errors before restarting the process.")
+				return
 			}
+			c.notifyIdentitySchemaReload(ctx)
 		}),
 	}, opts...)
 
@@ -408,6 +553,27 @@ func New(ctx context.Context, l *logrusx.Logger, stdOutOrErr io.Writer, ctxer co
 		if err := c.validateIdentitySchemas(ctx); err != nil {
 			return nil, err
 		}
+		if err := c.validateOIDCProviders(ctx); err != nil {
+			return nil, err
+		}
+		if err := c.validateSessionCookie(ctx); err != nil {
+			return nil, err
+		}
+		if err := c.validateIPRanges(ctx); err != nil {
+			return nil, err
+		}
+		if err := c.validateCourierChannels(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	c.identitySchemaReload.Lock()
+	c.identitySchemaReload.loaded, _ = c.IdentityTraitsSchemas(ctx)
+	c.identitySchemaReload.Unlock()
+
+	if c.HasherArgon2Autotune(ctx) {
+		// Calibrate at startup instead of during the first request.
+		_ = c.HasherArgon2(ctx)
 	}
 
 	return c, nil
@@ -415,7 +581,35 @@ func New(ctx context.Context, l *logrusx.Logger, stdOutOrErr io.Writer, ctxer co
 
 func NewCustom(l *logrusx.Logger, p *configx.Provider, stdOutOrErr io.Writer, ctxt contextx.Contextualizer) *Config {
 	l.UseConfig(p)
-	return &Config{l: l, p: p, c: ctxt, stdOutOrErr: stdOutOrErr}
+	return &Config{l: l, p: p, c: ctxt, stdOutOrErr: stdOutOrErr, identitySchemaReload: new(identitySchemaReload), extendedSchemas: new(extendedSchemas)}
+}
+
+// OnIdentitySchemaReload registers a callback which is called with the new identity schemas
+// whenever a changed and valid `identity.schemas` configuration was loaded by the file watcher.
+func (p *Config) OnIdentitySchemaReload(cb func(ctx context.Context, schemas []Schema)) {
+	p.identitySchemaReload.Lock()
+	defer p.identitySchemaReload.Unlock()
+	p.identitySchemaReload.callbacks = append(p.identitySchemaReload.callbacks, cb)
+}
+
+func (p *Config) notifyIdentitySchemaReload(ctx context.Context) {
+	ss, err := p.IdentityTraitsSchemas(ctx)
+	if err != nil {
+		return
+	}
+
+	p.identitySchemaReload.Lock()
+	if slices.EqualFunc(p.identitySchemaReload.loaded, ss, Schema.equal) {
+		p.identitySchemaReload.Unlock()
+		return
+	}
+	p.identitySchemaReload.loaded = ss
+	callbacks := slices.Clone(p.identitySchemaReload.callbacks)
+	p.identitySchemaReload.Unlock()
+
+	for _, cb := range callbacks {
+		cb(ctx, ss)
+	}
 }
 
 func (p *Config) getIdentitySchemaValidator(ctx context.Context) (*jsonschema.Schema, error) {
@@ -442,7 +636,8 @@ func SetValidateIdentitySchemaResilientClientOptions(ctx context.Context, option
 	return context.WithValue(ctx, validateIdentitySchemasClientKey, options)
 }
 
-func (p *Config) validateIdentitySchemas(ctx context.Context) error {
+// withIdentitySchemaClient sets the HTTP client used to load identity schemas from remote URLs.
+func (p *Config) withIdentitySchemaClient(ctx context.Context) context.Context {
 	opts := []httpx.ResilientOptions{
 		httpx.ResilientClientWithLogger(p.l),
 		httpx.ResilientClientWithMaxRetry(2),
@@ -461,7 +656,11 @@ func (p *Config) validateIdentitySchemas(ctx context.Context) error {
 		opts = append(opts, httpx.ResilientClientDisallowInternalIPs())
 	}
 
-	ctx = context.WithValue(ctx, httploader.ContextKey, httpx.NewResilientClient(opts...))
+	return context.WithValue(ctx, httploader.ContextKey, httpx.NewResilientClient(opts...))
+}
+
+func (p *Config) validateIdentitySchemas(ctx context.Context) error {
+	ctx = p.withIdentitySchemaClient(ctx)
 
 	j, err := p.getIdentitySchemaValidator(ctx)
 	if err != nil {
@@ -493,6 +692,92 @@ func (p *Config) validateIdentitySchemas(ctx context.Context) error {
 	return nil
 }
 
+func (p *Config) validateOIDCProviders(ctx context.Context) error {
+	var oidc struct {
+		Providers []json.RawMessage `json:"providers"`
+	}
+	if err := json.Unmarshal(p.SelfServiceStrategy(ctx, "oidc").Config, &oidc); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if max := p.OIDCMaxProviders(ctx); len(oidc.Providers) > max {
+		return errors.Errorf("the OpenID Connect configuration contains %d providers, but at most %d are allowed by %s", len(oidc.Providers), max, ViperKeyOIDCMaxProviders)
+	}
+	return nil
+}
+
+func (p *Config) validateSessionCookie(ctx context.Context) error {
+	if !p.SessionUseHostPrefix(ctx) {
+		return nil
+	}
+
+	if domain := p.sessionDomain(ctx); domain != "" {
+		return errors.Errorf("%s requires the session cookie to have no domain, but the domain %q is configured", ViperKeySessionUseHostPrefix, domain)
+	}
+	if path := p.sessionPath(ctx); path != "" && path != "/" {
+		return errors.Errorf("%s requires the session cookie path to be \"/\", but the path %q is configured", ViperKeySessionUseHostPrefix, path)
+	}
+	return nil
+}
+
+func (p *Config) validateIPRanges(ctx context.Context) error {
+	if _, err := ParseCIDRs(p.GetProvider(ctx).Strings(ViperKeyPublicTrustedProxies)); err != nil {
+		return errors.Wrapf(err, "unable to parse %s", ViperKeyPublicTrustedProxies)
+	}
+
+	for _, h := range p.SelfServiceFlowRegistrationBeforeHooks(ctx) {
+		if h.Name != "ip_deny" {
+			continue
+		}
+		var conf struct {
+			Ranges []string `json:"ranges"`
+		}
+		if err := json.Unmarshal(h.Config, &conf); err != nil {
+			return errors.WithStack(err)
+		}
+		if _, err := ParseCIDRs(conf.Ranges); err != nil {
+			return errors.Wrap(err, "unable to parse the ranges of the ip_deny hook")
+		}
+	}
+	return nil
+}
+
+// validateCourierChannels rejects courier channels which can not be built, e.g. because the
+// SMTP connection URI does not use a supported scheme.
+func (p *Config) validateCourierChannels(ctx context.Context) error {
+	_, err := p.CourierChannels(ctx)
+	return err
+}
+
+// parsedCIDRs caches parsed CIDR ranges. The cost of an entry is its number of ranges, so
+// that reloading the configuration many times can not grow the cache indefinitely.
+var parsedCIDRs, _ = ristretto.NewCache(&ristretto.Config{
+	MaxCost:     100_000,
+	NumCounters: 10_000,
+	BufferItems: 64,
+})
+
+// ParseCIDRs parses a list of CIDR ranges. Results are cached because the ranges are
+// evaluated on every request but only change when the configuration is reloaded.
+func ParseCIDRs(ranges []string) ([]*net.IPNet, error) {
+	key := strings.Join(ranges, ",")
+	if nets, ok := parsedCIDRs.Get(key); ok {
+		return nets.([]*net.IPNet), nil
+	}
+
+	nets := make([]*net.IPNet, 0, len(ranges))
+	for _, raw := range ranges {
+		_, ipNet, err := net.ParseCIDR(raw)
+		if err != nil {
+			return nil, errors.Errorf("invalid CIDR range %q: %s", raw, err)
+		}
+		nets = append(nets, ipNet)
+	}
+
+	parsedCIDRs.Set(key, nets, int64(max(len(nets), 1)))
+	return nets, nil
+}
+
 func (p *Config) formatJsonErrors(schema []byte, err error) {
 	_, _ = fmt.Fprintln(p.stdOutOrErr, "")
 	jsonschemax.FormatValidationErrorForCLI(p.stdOutOrErr, schema, err)
@@ -513,7 +798,7 @@ func (p *Config) cors(ctx context.Context, prefix string) (cors.Options, bool) {
 	return p.GetProvider(ctx).CORS(prefix, cors.Options{
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
 		AllowedHeaders:   []string{"Authorization", "Content-Type", "Cookie"},
-		ExposedHeaders:   []string{"Content-Type", "Set-Cookie"},
+		ExposedHeaders:   []string{"Content-Type", "Set-Cookie", "X-CSRF-Token"},
 		AllowCredentials: true,
 	})
 }
@@ -531,22 +816,156 @@ func (p *Config) MustSet(ctx context.Context, key string, value interface{}) {
 }
 
 func (p *Config) SessionName(ctx context.Context) string {
-	return stringsx.Coalesce(p.GetProvider(ctx).String(ViperKeySessionName), DefaultSessionCookieName)
+	name := stringsx.Coalesce(p.GetProvider(ctx).String(ViperKeySessionName), DefaultSessionCookieName)
+	if p.SessionUseHostPrefix(ctx) && !strings.HasPrefix(name, SessionCookieHostPrefix) {
+		return SessionCookieHostPrefix + name
+	}
+	return name
+}
+
+func (p *Config) SessionUseHostPrefix(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeySessionUseHostPrefix, false)
+}
+
+// SessionIssuancePolicyURL returns the URL of the policy service which is asked
+// before a session is issued, or nil if no policy service is configured.
+func (p *Config) SessionIssuancePolicyURL(ctx context.Context) *url.URL {
+	return p.GetProvider(ctx).URIF(ViperKeySessionIssuancePolicyURL, nil)
 }
 
 func (p *Config) HasherArgon2(ctx context.Context) *Argon2 {
+	pp := p.GetProvider(ctx)
+
 	// warn about usage of default values and point to the docs
 	// warning will require https://github.com/ory/viper/issues/19
-	return &Argon2{
-		Memory:            p.GetProvider(ctx).ByteSizeF(ViperKeyHasherArgon2ConfigMemory, Argon2DefaultMemory),
-		Iterations:        uint32(p.GetProvider(ctx).IntF(ViperKeyHasherArgon2ConfigIterations, int(Argon2DefaultIterations))),
-		Parallelism:       uint8(p.GetProvider(ctx).IntF(ViperKeyHasherArgon2ConfigParallelism, int(Argon2DefaultParallelism))),
-		SaltLength:        uint32(p.GetProvider(ctx).IntF(ViperKeyHasherArgon2ConfigSaltLength, int(Argon2DefaultSaltLength))),
-		KeyLength:         uint32(p.GetProvider(ctx).IntF(ViperKeyHasherArgon2ConfigKeyLength, int(Argon2DefaultKeyLength))),
-		ExpectedDuration:  p.GetProvider(ctx).DurationF(ViperKeyHasherArgon2ConfigExpectedDuration, Argon2DefaultDuration),
-		ExpectedDeviation: p.GetProvider(ctx).DurationF(ViperKeyHasherArgon2ConfigExpectedDeviation, Argon2DefaultDeviation),
-		DedicatedMemory:   p.GetProvider(ctx).ByteSizeF(ViperKeyHasherArgon2ConfigDedicatedMemory, Argon2DefaultDedicatedMemory),
+	c := &Argon2{
+		Memory:            pp.ByteSizeF(ViperKeyHasherArgon2ConfigMemory, Argon2DefaultMemory),
+		Iterations:        uint32(pp.IntF(ViperKeyHasherArgon2ConfigIterations, int(Argon2DefaultIterations))),
+		Parallelism:       uint8(pp.IntF(ViperKeyHasherArgon2ConfigParallelism, int(Argon2DefaultParallelism))),
+		SaltLength:        uint32(pp.IntF(ViperKeyHasherArgon2ConfigSaltLength, int(Argon2DefaultSaltLength))),
+		KeyLength:         uint32(pp.IntF(ViperKeyHasherArgon2ConfigKeyLength, int(Argon2DefaultKeyLength))),
+		ExpectedDuration:  pp.DurationF(ViperKeyHasherArgon2ConfigExpectedDuration, Argon2DefaultDuration),
+		ExpectedDeviation: pp.DurationF(ViperKeyHasherArgon2ConfigExpectedDeviation, Argon2DefaultDeviation),
+		DedicatedMemory:   pp.ByteSizeF(ViperKeyHasherArgon2ConfigDedicatedMemory, Argon2DefaultDedicatedMemory),
+	}
+
+	if !p.HasherArgon2Autotune(ctx) {
+		return c
+	}
+
+	// The dedicated memory is a hard ceiling, and only iterations which are not configured are calibrated.
+	if c.Memory > c.DedicatedMemory {
+		c.Memory = c.DedicatedMemory
+	}
+	if !pp.Exists(ViperKeyHasherArgon2ConfigIterations) {
+		c.Iterations = p.autotunedArgon2Iterations(c)
+	}
+	return c
+}
+
+func (p *Config) HasherArgon2Autotune(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeyHasherArgon2ConfigAutotune, false)
+}
+
+const (
+	// argon2AutotuneMaxProbes limits how many hashes are computed while calibrating Argon2.
+	argon2AutotuneMaxProbes = 16
+
+	// argon2AutotuneMaxDuration limits how long calibrating Argon2 may delay the startup.
+	argon2AutotuneMaxDuration = 5 * time.Second
+)
+
+type argon2Calibration struct {
+	memory              bytesize.ByteSize
+	parallelism         uint8
+	keyLength           uint32
+	duration, deviation time.Duration
+}
+
+// argon2Calibrations caches the calibrated iterations per set of parameters,
+// so that each set is only calibrated once per process. Concurrent calibrations
+// of the same set are collapsed, while different sets are calibrated independently.
+var argon2Calibrations struct {
+	iterations sync.Map
+	group      singleflight.Group
+}
+
+// autotunedArgon2Iterations returns the number of iterations for which hashing a
+// password with the given parameters takes ExpectedDuration ± ExpectedDeviation
+// on this machine. The parameters are calibrated on first use.
+func (p *Config) autotunedArgon2Iterations(c *Argon2) uint32 {
+	key := argon2Calibration{
+		memory:      c.Memory,
+		parallelism: c.Parallelism,
+		keyLength:   c.KeyLength,
+		duration:    c.ExpectedDuration,
+		deviation:   c.ExpectedDeviation,
 	}
+
+	if iterations, ok := argon2Calibrations.iterations.Load(key); ok {
+		return iterations.(uint32)
+	}
+
+	iterations, _, _ := argon2Calibrations.group.Do(fmt.Sprintf("%+v", key), func() (interface{}, error) {
+		if iterations, ok := argon2Calibrations.iterations.Load(key); ok {
+			return iterations, nil
+		}
+		iterations := p.calibrateArgon2Iterations(c)
+		argon2Calibrations.iterations.Store(key, iterations)
+		return iterations, nil
+	})
+	return iterations.(uint32)
+}
+
+// calibrateArgon2Iterations probes how many iterations hashing a password with the given
+// parameters takes ExpectedDuration ± ExpectedDeviation.
+func (p *Config) calibrateArgon2Iterations(c *Argon2) uint32 {
+	start := time.Now()
+	iterations := uint32(1)
+	salt := make([]byte, c.SaltLength)
+	probe := func() time.Duration {
+		start := time.Now()
+		_ = argon2.IDKey([]byte("autotune"), salt, iterations, uint32(c.Memory/bytesize.KB), c.Parallelism, c.KeyLength)
+		return time.Since(start)
+	}
+
+	took := probe()
+	if took > 0 && took < c.ExpectedDuration {
+		// Hashing time grows linearly with the iterations, so start with an estimate.
+		iterations = uint32(c.ExpectedDuration / took)
+		took = probe()
+	}
+
+	for i := 0; i < argon2AutotuneMaxProbes; i++ {
+		// Another probe takes about as long as the last one.
+		if time.Since(start)+took > argon2AutotuneMaxDuration {
+			p.l.Warnf("Stopped calibrating the Argon2 hasher parameters after %s.", time.Since(start).Round(time.Millisecond))
+			break
+		}
+
+		tooFast := took < c.ExpectedDuration-c.ExpectedDeviation
+		tooSlow := took > c.ExpectedDuration+c.ExpectedDeviation && iterations > 1
+		if !tooFast && !tooSlow {
+			break
+		}
+
+		// Re-estimate from the last probe, but always move by at least one iteration.
+		next := uint32(uint64(iterations) * uint64(c.ExpectedDuration) / uint64(max(took, 1)))
+		if tooFast {
+			iterations = max(next, iterations+1)
+		} else {
+			iterations = max(min(next, iterations-1), 1)
+		}
+		took = probe()
+	}
+
+	p.l.
+		WithField("memory", c.Memory.String()).
+		WithField("iterations", iterations).
+		WithField("parallelism", c.Parallelism).
+		WithField("duration", took.String()).
+		Infof("Calibrated the Argon2 hasher parameters.")
+	return iterations
 }
 
 func (p *Config) HasherBcrypt(ctx context.Context) *Bcrypt {
@@ -592,20 +1011,314 @@ func (p *Config) DefaultIdentityTraitsSchemaID(ctx context.Context) string {
 	return p.GetProvider(ctx).String(ViperKeyDefaultIdentitySchemaID)
 }
 
+// IdentityMaxCredentialConfigSize returns the maximum size in bytes of a single credential's configuration.
+// Zero means unlimited.
+func (p *Config) IdentityMaxCredentialConfigSize(ctx context.Context) int {
+	return p.GetProvider(ctx).IntF(ViperKeyIdentityMaxCredentialConfigSize, 0)
+}
+
+// IdentityTraitsPreserveNumberPrecision returns whether numbers in traits are kept verbatim
+// instead of being converted to float64 when Kratos rewrites traits.
+func (p *Config) IdentityTraitsPreserveNumberPrecision(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeyIdentityTraitsPreserveNumberPrecision, false)
+}
+
 func (p *Config) TOTPIssuer(ctx context.Context) string {
 	return p.GetProvider(ctx).StringF(ViperKeyTOTPIssuer, p.SelfPublicURL(ctx).Hostname())
 }
 
+func (p *Config) TOTPEmitQRDataURI(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeyTOTPEmitQRDataURI, false)
+}
+
 func (p *Config) OIDCRedirectURIBase(ctx context.Context) *url.URL {
 	return p.GetProvider(ctx).URIF(ViperKeyOIDCBaseRedirectURL, p.SelfPublicURL(ctx))
 }
 
+func (p *Config) OIDCMaxProviders(ctx context.Context) int {
+	return p.GetProvider(ctx).IntF(ViperKeyOIDCMaxProviders, 250)
+}
+
 func (p *Config) IdentityTraitsSchemas(ctx context.Context) (ss Schemas, err error) {
 	if err = p.GetProvider(ctx).Koanf.Unmarshal(ViperKeyIdentitySchemas, &ss); err != nil {
 		return ss, nil
 	}
 
-	return ss, nil
+	if !slices.ContainsFunc(ss, func(s Schema) bool { return s.Extends != "" }) {
+		return ss, nil
+	}
+
+	key, err := json.Marshal(ss)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	p.extendedSchemas.Lock()
+	defer p.extendedSchemas.Unlock()
+	if p.extendedSchemas.key == string(key) {
+		return slices.Clone(p.extendedSchemas.resolved), nil
+	}
+
+	// Outside of HTTP requests no client has been set by the middleware.
+	if ctx.Value(httploader.ContextKey) == nil {
+		ctx = p.withIdentitySchemaClient(ctx)
+	}
+
+	resolved, err := ss.resolveExtends(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.extendedSchemas.key, p.extendedSchemas.resolved = string(key), resolved
+	return slices.Clone(resolved), nil
+}
+
+// resolveExtends replaces the URL of every schema which extends another schema
+// with a base64 encoded schema containing the traits of the whole chain.
+func (s Schemas) resolveExtends(ctx context.Context) (Schemas, error) {
+	resolved := make(Schemas, len(s))
+	for k, sc := range s {
+		if sc.Extends == "" {
+			resolved[k] = sc
+			continue
+		}
+
+		merged, err := s.loadExtended(ctx, sc, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+
+		sc.URL = "base64://" + base64.StdEncoding.EncodeToString(merged)
+		resolved[k] = sc
+	}
+	return resolved, nil
+}
+
+func (s Schemas) loadExtended(ctx context.Context, sc Schema, seen map[string]bool) ([]byte, error) {
+	if seen[sc.ID] {
+		return nil, errors.Errorf("identity schema %q is part of a cycle of extended identity schemas", sc.ID)
+	}
+	seen[sc.ID] = true
+
+	resource, err := jsonschema.LoadURL(ctx, sc.URL)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer resource.Close()
+
+	own, err := io.ReadAll(io.LimitReader(resource, 1024*1024))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// The merged schema is served from a base64 URL, which is why relative
+	// references have to be resolved against the location of their schema.
+	location := sc.URL
+	if id := gjson.GetBytes(own, "$id"); id.Type == gjson.String {
+		location = id.String()
+	}
+	if baseURL, err := url.Parse(location); err == nil && baseURL.Scheme != "base64" {
+		own, err = rewriteSchemaRefs(own, func(ref string) string {
+			if strings.HasPrefix(ref, "#") {
+				return ref
+			}
+			refURL, err := url.Parse(ref)
+			if err != nil {
+				return ref
+			}
+			return baseURL.ResolveReference(refURL).String()
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if sc.Extends == "" {
+		return own, nil
+	}
+
+	base, err := s.FindSchemaByID(sc.Extends)
+	if err != nil {
+		return nil, errors.Errorf("identity schema %q extends identity schema %q which does not exist", sc.ID, sc.Extends)
+	}
+
+	inherited, err := s.loadExtended(ctx, *base, seen)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeIdentitySchemaTraits(sc.ID, base.ID, inherited, own)
+}
+
+// mergeIdentitySchemaTraits adds the traits of the base schema to the schema
+// extending it. Inherited traits are placed before the schema's own traits so
+// that forms render them first. The definitions of the base schema are carried
+// over so that references to them keep working.
+func mergeIdentitySchemaTraits(id, baseID string, base, own []byte) ([]byte, error) {
+	base, own, err := mergeIdentitySchemaDefinitions(baseID, base, own)
+	if err != nil {
+		return nil, err
+	}
+
+	baseTraits := gjson.GetBytes(base, "properties.traits")
+	if !baseTraits.Exists() {
+		return own, nil
+	}
+
+	ownTraits := gjson.GetBytes(own, "properties.traits")
+	if !ownTraits.Exists() {
+		merged, err := sjson.SetRawBytes(own, "properties.traits", []byte(baseTraits.Raw))
+		return merged, errors.WithStack(err)
+	}
+
+	var (
+		properties []string
+		conflict   string
+		defined    = ownTraits.Get("properties").Map()
+	)
+	baseTraits.Get("properties").ForEach(func(key, value gjson.Result) bool {
+		if _, ok := defined[key.String()]; ok {
+			conflict = key.String()
+			return false
+		}
+		properties = append(properties, key.Raw+":"+value.Raw)
+		return true
+	})
+	if conflict != "" {
+		return nil, errors.Errorf("identity schema %q defines trait %q which is already defined by the identity schema %q it extends", id, conflict, baseID)
+	}
+	ownTraits.Get("properties").ForEach(func(key, value gjson.Result) bool {
+		properties = append(properties, key.Raw+":"+value.Raw)
+		return true
+	})
+
+	merged, err := sjson.SetRawBytes(own, "properties.traits.properties", []byte("{"+strings.Join(properties, ",")+"}"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var required []string
+	for _, r := range append(baseTraits.Get("required").Array(), ownTraits.Get("required").Array()...) {
+		if !slices.Contains(required, r.String()) {
+			required = append(required, r.String())
+		}
+	}
+	if len(required) > 0 {
+		if merged, err = sjson.SetBytes(merged, "properties.traits.required", required); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return merged, nil
+}
+
+// mergeIdentitySchemaDefinitions copies the definitions of the base schema to
+// the schema extending it. A base definition whose name is already used for a
+// different definition is renamed, and the references of the base schema are
+// rewritten to the new name.
+func mergeIdentitySchemaDefinitions(baseID string, base, own []byte) ([]byte, []byte, error) {
+	for _, keyword := range []string{"definitions", "$defs"} {
+		path := escapeSchemaPath(keyword)
+		if !gjson.GetBytes(base, path).IsObject() {
+			continue
+		}
+
+		defined := gjson.GetBytes(own, path).Map()
+		targets, renamed := map[string]string{}, map[string]string{}
+		gjson.GetBytes(base, path).ForEach(func(key, definition gjson.Result) bool {
+			name := key.String()
+			target := name
+			for existing, ok := defined[target]; ok; existing, ok = defined[target] {
+				if existing.Raw == definition.Raw {
+					return true
+				}
+				target = baseID + "_" + target
+			}
+			defined[target] = definition
+			targets[name] = target
+			if target != name {
+				renamed["#/"+keyword+"/"+jsonPointerEscape(name)] = "#/" + keyword + "/" + jsonPointerEscape(target)
+			}
+			return true
+		})
+
+		var err error
+		if len(renamed) > 0 {
+			base, err = rewriteSchemaRefs(base, func(ref string) string {
+				for from, to := range renamed {
+					if ref == from || strings.HasPrefix(ref, from+"/") {
+						return to + strings.TrimPrefix(ref, from)
+					}
+				}
+				return ref
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		var copyErr error
+		gjson.GetBytes(base, path).ForEach(func(key, definition gjson.Result) bool {
+			target, ok := targets[key.String()]
+			if !ok {
+				return true
+			}
+			own, copyErr = sjson.SetRawBytes(own, path+"."+escapeSchemaPath(target), []byte(definition.Raw))
+			return copyErr == nil
+		})
+		if copyErr != nil {
+			return nil, nil, errors.WithStack(copyErr)
+		}
+	}
+	return base, own, nil
+}
+
+// rewriteSchemaRefs replaces the value of every "$ref" keyword in the schema
+// with the result of rewrite.
+func rewriteSchemaRefs(raw []byte, rewrite func(ref string) string) ([]byte, error) {
+	refs := map[string]string{}
+	var walk func(path string, value gjson.Result)
+	walk = func(path string, value gjson.Result) {
+		value.ForEach(func(key, child gjson.Result) bool {
+			p := escapeSchemaPath(key.String())
+			if path != "" {
+				p = path + "." + p
+			}
+			if value.IsObject() && key.String() == "$ref" && child.Type == gjson.String {
+				if ref := rewrite(child.String()); ref != child.String() {
+					refs[p] = ref
+				}
+			} else if child.IsObject() || child.IsArray() {
+				walk(p, child)
+			}
+			return true
+		})
+	}
+	walk("", gjson.ParseBytes(raw))
+
+	for path, ref := range refs {
+		var err error
+		if raw, err = sjson.SetBytes(raw, path, ref); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return raw, nil
+}
+
+// escapeSchemaPath escapes a key for use in a gjson or sjson path.
+func escapeSchemaPath(key string) string {
+	var b strings.Builder
+	for _, c := range key {
+		if strings.ContainsRune(`.*?|#@\:!=<>%`, c) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// jsonPointerEscape escapes a key for use in a JSON pointer.
+func jsonPointerEscape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
 }
 
 func (p *Config) AdminListenOn(ctx context.Context) string {
@@ -643,13 +1356,72 @@ func (p *Config) DSN(ctx context.Context) string {
 	}
 
 	if len(dsn) > 0 {
-		return dsn
+		return p.expandDSN(dsn)
 	}
 
 	p.l.Fatal("dsn must be set")
 	return ""
 }
 
+// dsnEnvReference matches `${VAR}` and `$VAR` references as well as escaped `$$` dollar signs in a DSN.
+var dsnEnvReference = regexp.MustCompile(`\$(?:\$|\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// expandDSN replaces `${VAR}` and `$VAR` references in the DSN with the values of the respective environment
+// variables. All referenced variables must be set. A literal dollar sign followed by a name, for example in a
+// password, must be escaped as `$$`. Values substituted into the user info of the DSN are URL-escaped, so that
+// credentials may contain characters such as `@`, `:`, or `/`.
+func (p *Config) expandDSN(dsn string) string {
+	userInfoEnd := -1
+	if scheme := strings.Index(dsn, "://"); scheme >= 0 {
+		authority := dsn[scheme+3:]
+		if slash := strings.Index(authority, "/"); slash >= 0 {
+			authority = authority[:slash]
+		}
+		if at := strings.LastIndex(authority, "@"); at >= 0 {
+			userInfoEnd = scheme + 3 + at
+		}
+	}
+
+	var (
+		missing  []string
+		expanded strings.Builder
+		last     int
+	)
+	for _, m := range dsnEnvReference.FindAllStringSubmatchIndex(dsn, -1) {
+		expanded.WriteString(dsn[last:m[0]])
+		last = m[1]
+
+		var key string
+		switch {
+		case m[2] >= 0:
+			key = dsn[m[2]:m[3]]
+		case m[4] >= 0:
+			key = dsn[m[4]:m[5]]
+		default:
+			expanded.WriteString("$")
+			continue
+		}
+
+		v, ok := os.LookupEnv(key)
+		switch {
+		case !ok:
+			missing = append(missing, key)
+		case m[0] < userInfoEnd:
+			expanded.WriteString(strings.ReplaceAll(url.QueryEscape(v), "+", "%20"))
+		default:
+			expanded.WriteString(v)
+		}
+	}
+	expanded.WriteString(dsn[last:])
+
+	if len(missing) > 0 {
+		p.l.WithField("missing_variables", missing).Fatalf("dsn references environment variables which are not set: %s", strings.Join(missing, ", "))
+		return ""
+	}
+
+	return expanded.String()
+}
+
 func (p *Config) DisableAPIFlowEnforcement(ctx context.Context) bool {
 	if p.IsInsecureDevMode(ctx) && os.Getenv("DEV_DISABLE_API_FLOW_ENFORCEMENT") == "true" {
 		p.l.Warn("Because \"DEV_DISABLE_API_FLOW_ENFORCEMENT=true\" and the \"--dev\" flag are set, self-service API flows will no longer check if the interaction is actually a browser flow. This is very dangerous as it allows bypassing of anti-CSRF measures, leaving the deployment highly vulnerable. This option should only be used for automated testing and never come close to real user data anywhere.")
@@ -666,6 +1438,10 @@ func (p *Config) ClientHTTPPrivateIPExceptionURLs(ctx context.Context) []string
 	return p.GetProvider(ctx).Strings(ViperKeyClientHTTPPrivateIPExceptionURLs)
 }
 
+func (p *Config) MaintenanceReadOnly(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeyMaintenanceReadOnly, false)
+}
+
 func (p *Config) SelfServiceFlowRegistrationEnabled(ctx context.Context) bool {
 	return p.GetProvider(ctx).Bool(ViperKeySelfServiceRegistrationEnabled)
 }
@@ -678,6 +1454,28 @@ func (p *Config) SelfServiceFlowRegistrationTwoSteps(ctx context.Context) bool {
 	return !p.GetProvider(ctx).BoolF(ViperKeySelfServiceRegistrationEnableLegacyOneStep, false)
 }
 
+// SelfServiceFlowRegistrationDefaultIdentityState returns the state (`active` or `inactive`)
+// assigned to identities created through self-service registration.
+func (p *Config) SelfServiceFlowRegistrationDefaultIdentityState(ctx context.Context) string {
+	return p.GetProvider(ctx).StringF(ViperKeySelfServiceRegistrationDefaultIdentityState, "active")
+}
+
+// SelfServiceFlowRegistrationIncludeAddresses returns whether the registration success response
+// includes the verifiable and recovery addresses of the created identity.
+func (p *Config) SelfServiceFlowRegistrationIncludeAddresses(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeySelfServiceRegistrationIncludeAddresses, false)
+}
+
+// SelfServiceFlowRegistrationRequireConsent returns the consent identities must give
+// during self-service registration.
+func (p *Config) SelfServiceFlowRegistrationRequireConsent(ctx context.Context) *RegistrationConsent {
+	return &RegistrationConsent{
+		Enabled: p.GetProvider(ctx).BoolF(ViperKeySelfServiceRegistrationRequireConsent+".enabled", false),
+		Label:   p.GetProvider(ctx).StringF(ViperKeySelfServiceRegistrationRequireConsent+".label", "I agree to the terms of service and privacy policy"),
+		Version: p.GetProvider(ctx).String(ViperKeySelfServiceRegistrationRequireConsent + ".version"),
+	}
+}
+
 func (p *Config) SelfServiceFlowVerificationEnabled(ctx context.Context) bool {
 	return p.GetProvider(ctx).Bool(ViperKeySelfServiceVerificationEnabled)
 }
@@ -691,15 +1489,15 @@ func (p *Config) SelfServiceFlowRecoveryUse(ctx context.Context) string {
 }
 
 func (p *Config) SelfServiceFlowLoginBeforeHooks(ctx context.Context) []SelfServiceHook {
-	return p.selfServiceHooks(ctx, ViperKeySelfServiceLoginBeforeHooks)
+	return p.selfServiceBeforeHooks(ctx, ViperKeySelfServiceLoginBeforeHooks)
 }
 
 func (p *Config) SelfServiceFlowRecoveryBeforeHooks(ctx context.Context) []SelfServiceHook {
-	return p.selfServiceHooks(ctx, ViperKeySelfServiceRecoveryBeforeHooks)
+	return p.selfServiceBeforeHooks(ctx, ViperKeySelfServiceRecoveryBeforeHooks)
 }
 
 func (p *Config) SelfServiceFlowVerificationBeforeHooks(ctx context.Context) []SelfServiceHook {
-	return p.selfServiceHooks(ctx, ViperKeySelfServiceVerificationBeforeHooks)
+	return p.selfServiceBeforeHooks(ctx, ViperKeySelfServiceVerificationBeforeHooks)
 }
 
 func (p *Config) SelfServiceFlowVerificationUse(ctx context.Context) string {
@@ -711,18 +1509,28 @@ func (p *Config) SelfServiceFlowVerificationNotifyUnknownRecipients(ctx context.
 }
 
 func (p *Config) SelfServiceFlowSettingsBeforeHooks(ctx context.Context) []SelfServiceHook {
-	return p.selfServiceHooks(ctx, ViperKeySelfServiceSettingsBeforeHooks)
+	return p.selfServiceBeforeHooks(ctx, ViperKeySelfServiceSettingsBeforeHooks)
 }
 
 func (p *Config) SelfServiceFlowRegistrationBeforeHooks(ctx context.Context) []SelfServiceHook {
-	hooks := p.selfServiceHooks(ctx, ViperKeySelfServiceRegistrationBeforeHooks)
+	hooks := p.selfServiceBeforeHooks(ctx, ViperKeySelfServiceRegistrationBeforeHooks)
 	if p.SelfServiceFlowRegistrationTwoSteps(ctx) {
-		hooks = append(hooks, SelfServiceHook{"two_step_registration", json.RawMessage("{}")})
+		hooks = append(hooks, SelfServiceHook{Name: "two_step_registration", Config: json.RawMessage("{}")})
 	}
 
 	return hooks
 }
 
+// selfServiceBeforeHooks returns the before hooks configured at key, sorted by
+// their priority. Hooks with equal priority keep their configuration order.
+func (p *Config) selfServiceBeforeHooks(ctx context.Context, key string) []SelfServiceHook {
+	hooks := p.selfServiceHooks(ctx, key)
+	slices.SortStableFunc(hooks, func(a, b SelfServiceHook) int {
+		return b.Priority - a.Priority
+	})
+	return hooks
+}
+
 func (p *Config) selfServiceHooks(ctx context.Context, key string) []SelfServiceHook {
 	pp := p.GetProvider(ctx)
 	val := pp.Get(key)
@@ -744,11 +1552,29 @@ func (p *Config) selfServiceHooks(ctx context.Context, key string) []SelfService
 		if len(hooks[k].Config) == 0 {
 			hooks[k].Config = json.RawMessage("{}")
 		}
+		if hooks[k].Name == "web_hook" {
+			hooks[k].AllowedHeaders = WebHookAllowedHeaders(hooks[k].Config)
+		}
 	}
 
 	return hooks
 }
 
+// WebHookAllowedHeaders returns the canonicalized `allowed_headers` of a web hook configuration,
+// or nil if the web hook does not restrict the request headers it receives.
+func WebHookAllowedHeaders(config json.RawMessage) []string {
+	raw := gjson.GetBytes(config, "allowed_headers")
+	if !raw.Exists() {
+		return nil
+	}
+
+	allowed := make([]string, 0, len(raw.Array()))
+	for _, h := range raw.Array() {
+		allowed = append(allowed, http.CanonicalHeaderKey(h.String()))
+	}
+	return allowed
+}
+
 func (p *Config) SelfServiceFlowLoginAfterHooks(ctx context.Context, strategy string) []SelfServiceHook {
 	return p.selfServiceHooks(ctx, HookStrategyKey(ViperKeySelfServiceLoginAfter, strategy))
 }
@@ -847,22 +1673,37 @@ func (p *Config) SecretsSession(ctx context.Context) [][]byte {
 	return result
 }
 
-func (p *Config) SecretsCipher(ctx context.Context) [][32]byte {
+// CipherSecret is a cipher secret together with a stable identifier, which allows finding
+// the key a ciphertext was encrypted with after the cipher secrets were rotated.
+type CipherSecret struct {
+	ID  string
+	Key [32]byte
+}
+
+// SecretsCipherWithIDs returns all cipher secrets which are exactly 32 bytes long. The ID of a
+// secret is the hex encoded prefix of its SHA-256 hash.
+func (p *Config) SecretsCipherWithIDs(ctx context.Context) []CipherSecret {
 	secrets := p.GetProvider(ctx).Strings(ViperKeySecretsCipher)
-	var cleanSecrets []string
-	for k := range secrets {
-		if len(secrets[k]) == 32 {
-			cleanSecrets = append(cleanSecrets, secrets[k])
+	result := make([]CipherSecret, 0, len(secrets))
+	for _, secret := range secrets {
+		if len(secret) != 32 {
+			continue
 		}
+
+		var c CipherSecret
+		copy(c.Key[:], secret)
+		sum := sha256.Sum256(c.Key[:])
+		c.ID = hex.EncodeToString(sum[:4])
+		result = append(result, c)
 	}
-	if len(cleanSecrets) == 0 {
-		return [][32]byte{}
-	}
-	result := make([][32]byte, len(cleanSecrets))
-	for n, s := range secrets {
-		for k, v := range []byte(s) {
-			result[n][k] = v
-		}
+	return result
+}
+
+func (p *Config) SecretsCipher(ctx context.Context) [][32]byte {
+	secrets := p.SecretsCipherWithIDs(ctx)
+	result := make([][32]byte, len(secrets))
+	for k, s := range secrets {
+		result[k] = s.Key
 	}
 	return result
 }
@@ -915,6 +1756,33 @@ func (p *Config) DisablePublicHealthRequestLog(ctx context.Context) bool {
 	return p.GetProvider(ctx).Bool(ViperKeyDisablePublicHealthRequestLog)
 }
 
+// PublicRequestTimeout returns the maximum duration of a request to the public endpoint. Zero means no timeout.
+func (p *Config) PublicRequestTimeout(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeyPublicRequestTimeout, 0)
+}
+
+// PublicServerTiming returns true if flow creation responses should include a Server-Timing header.
+func (p *Config) PublicServerTiming(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeyPublicServerTiming, false)
+}
+
+const (
+	JSONFieldCaseSnake = "snake"
+	JSONFieldCaseCamel = "camel"
+)
+
+// PublicJSONFieldCase returns the case of the keys in JSON responses of the public endpoint.
+func (p *Config) PublicJSONFieldCase(ctx context.Context) string {
+	return p.GetProvider(ctx).StringF(ViperKeyPublicJSONFieldCase, JSONFieldCaseSnake)
+}
+
+// PublicTrustedProxies returns the CIDR ranges of reverse proxies whose X-Forwarded-For header
+// is trusted when determining the client IP. Invalid ranges are rejected when the configuration is loaded.
+func (p *Config) PublicTrustedProxies(ctx context.Context) []*net.IPNet {
+	nets, _ := ParseCIDRs(p.GetProvider(ctx).Strings(ViperKeyPublicTrustedProxies))
+	return nets
+}
+
 func (p *Config) SelfPublicURL(ctx context.Context) *url.URL {
 	return p.baseURL(ctx, ViperKeyPublicBaseURL, ViperKeyPublicHost, ViperKeyPublicPort, 4433)
 }
@@ -923,6 +1791,11 @@ func (p *Config) DisableAdminHealthRequestLog(ctx context.Context) bool {
 	return p.GetProvider(ctx).Bool(ViperKeyDisableAdminHealthRequestLog)
 }
 
+// AdminRequestTimeout returns the maximum duration of a request to the admin endpoint. Zero means no timeout.
+func (p *Config) AdminRequestTimeout(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeyAdminRequestTimeout, 0)
+}
+
 func (p *Config) SelfAdminURL(ctx context.Context) *url.URL {
 	return p.baseURL(ctx, ViperKeyAdminBaseURL, ViperKeyAdminHost, ViperKeyAdminPort, 4434)
 }
@@ -966,6 +1839,25 @@ func (p *Config) SelfServiceFlowLoginUI(ctx context.Context) *url.URL {
 	return p.ParseAbsoluteOrRelativeURIOrFail(ctx, ViperKeySelfServiceLoginUI)
 }
 
+// SelfServiceFlowExpiredAsStatusForBrowser returns true if browser flows which
+// expired should be answered with 410 Gone instead of a redirect to the UI.
+func (p *Config) SelfServiceFlowExpiredAsStatusForBrowser(ctx context.Context) bool {
+	return p.GetProvider(ctx).Bool(ViperKeySelfServiceFlowsExpiredAsStatusForBrowser)
+}
+
+// SelfServiceFlowCSRFTokenInHeader returns true if the CSRF token of browser
+// flows should also be returned in the X-CSRF-Token header on flow init.
+func (p *Config) SelfServiceFlowCSRFTokenInHeader(ctx context.Context) bool {
+	return p.GetProvider(ctx).Bool(ViperKeySelfServiceFlowsCSRFTokenInHeader)
+}
+
+// SelfServiceFlowInitRateLimitMaxPerMinute returns how many login, registration,
+// recovery, and verification flows a single IP may initialize per minute. Zero
+// means unlimited.
+func (p *Config) SelfServiceFlowInitRateLimitMaxPerMinute(ctx context.Context) int {
+	return p.GetProvider(ctx).IntF(ViperKeySelfServiceFlowsInitRateLimitMaxPerMinute, 0)
+}
+
 func (p *Config) SelfServiceFlowSettingsUI(ctx context.Context) *url.URL {
 	return p.ParseAbsoluteOrRelativeURIOrFail(ctx, ViperKeySelfServiceSettingsURL)
 }
@@ -991,6 +1883,35 @@ func (p *Config) SessionPersistentCookie(ctx context.Context) bool {
 	return p.GetProvider(ctx).Bool(ViperKeySessionPersistentCookie)
 }
 
+// SessionCookieMaxAge returns the Max-Age of persistent session cookies. If zero,
+// the Max-Age is derived from the session's expiry.
+func (p *Config) SessionCookieMaxAge(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeySessionCookieMaxAge, 0)
+}
+
+const (
+	IdentifierInputNormalizationNone    = "none"
+	IdentifierInputNormalizationTrim    = "trim"
+	IdentifierInputNormalizationTrimNFC = "trim_nfc"
+)
+
+// SelfServiceIdentifierInputNormalization returns how submitted identifiers are normalized
+// before they are looked up.
+func (p *Config) SelfServiceIdentifierInputNormalization(ctx context.Context) string {
+	return p.GetProvider(ctx).StringF(ViperKeySelfServiceIdentifierInputNormalization, IdentifierInputNormalizationTrim)
+}
+
+const (
+	IdentifierStoragePlaintext = "plaintext"
+	IdentifierStorageHashed    = "hashed"
+)
+
+// SelfServiceIdentifierStorage returns how credential identifiers are stored in the
+// database.
+func (p *Config) SelfServiceIdentifierStorage(ctx context.Context) string {
+	return p.GetProvider(ctx).StringF(ViperKeySelfServiceIdentifierStorage, IdentifierStoragePlaintext)
+}
+
 func (p *Config) SelfServiceBrowserAllowedReturnToDomains(ctx context.Context) (us []url.URL) {
 	src := p.GetProvider(ctx).Strings(ViperKeyURLsAllowedReturnToDomains)
 	for k, u := range src {
@@ -1022,10 +1943,76 @@ func (p *Config) SelfServiceBrowserAllowedReturnToDomains(ctx context.Context) (
 	return us
 }
 
+func (p *Config) SelfServiceBrowserRequireHTTPSReturnTo(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeyURLsRequireHTTPSReturnTo, false)
+}
+
 func (p *Config) SelfServiceFlowLoginRequestLifespan(ctx context.Context) time.Duration {
 	return p.GetProvider(ctx).DurationF(ViperKeySelfServiceLoginRequestLifespan, time.Hour)
 }
 
+func (p *Config) SelfServiceFlowLoginReuseWithin(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeySelfServiceLoginFlowReuseWithin, 0)
+}
+
+func (p *Config) SelfServiceFlowLoginHideUnavailableMFAMethods(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeySelfServiceLoginHideUnavailableMFAMethods, false)
+}
+
+// SelfServiceFlowLoginEchoIdentifierOnError returns whether the identifier is filled in again when the login
+// form is re-rendered after an error.
+func (p *Config) SelfServiceFlowLoginEchoIdentifierOnError(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeySelfServiceLoginEchoIdentifierOnError, true)
+}
+
+// SelfServiceFlowLoginMaxSubmissions returns how often a single login flow may be submitted using the
+// given strategy. The strategy specific `selfservice.flows.login.<strategy>.max_submissions` takes
+// precedence over the global value. Zero means unlimited.
+func (p *Config) SelfServiceFlowLoginMaxSubmissions(ctx context.Context, strategy string) int {
+	pp := p.GetProvider(ctx)
+	if strategy != HookGlobal {
+		if key := fmt.Sprintf("selfservice.flows.login.%s.max_submissions", strategy); pp.Exists(key) {
+			return pp.Int(key)
+		}
+	}
+	return pp.IntF(ViperKeySelfServiceLoginMaxSubmissions, 0)
+}
+
+// SelfServiceFlowUIMessages returns the messages configured in `selfservice.flows.<flow>.ui_messages`
+// which are shown when a flow is created.
+func (p *Config) SelfServiceFlowUIMessages(ctx context.Context, flow string) []SelfServiceUIMessage {
+	key := fmt.Sprintf("selfservice.flows.%s.ui_messages", flow)
+	if !p.GetProvider(ctx).Exists(key) {
+		return nil
+	}
+
+	config, err := json.Marshal(p.GetProvider(ctx).Get(key))
+	if err != nil {
+		p.l.WithError(err).Errorf("Unable to decode values from %s.", key)
+		return nil
+	}
+
+	var messages []SelfServiceUIMessage
+	if err := json.Unmarshal(config, &messages); err != nil {
+		p.l.WithError(err).Errorf("Unable to encode values from %s.", key)
+		return nil
+	}
+	return messages
+}
+
+// SelfServiceLoginFlowStyle returns how the first factor login form is rendered. With
+// LoginFlowStylePasswordlessOrPassword, the password field and the passkey conditional UI share the
+// identifier field if both strategies are enabled.
+func (p *Config) SelfServiceLoginFlowStyle(ctx context.Context) string {
+	return p.GetProvider(ctx).StringF(ViperKeySelfServiceLoginFlowStyle, LoginFlowStyleOneStep)
+}
+
+// SelfServiceFlowAPIDebugErrors returns true if error responses of API flows include the reason and debug
+// details of internal server errors. This must not be enabled in production.
+func (p *Config) SelfServiceFlowAPIDebugErrors(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeySelfServiceAPIDebugErrors, false)
+}
+
 func (p *Config) SelfServiceFlowSettingsFlowLifespan(ctx context.Context) time.Duration {
 	return p.GetProvider(ctx).DurationF(ViperKeySelfServiceSettingsRequestLifespan, time.Hour)
 }
@@ -1056,6 +2043,24 @@ func (p *Config) CourierEmailRequestConfig(ctx context.Context) json.RawMessage
 	return config
 }
 
+func (p *Config) CourierSMSStrategy(ctx context.Context) string {
+	return p.GetProvider(ctx).StringF(ViperKeyCourierSMSStrategy, "http")
+}
+
+func (p *Config) CourierSMSRequestConfig(ctx context.Context) json.RawMessage {
+	if p.CourierSMSStrategy(ctx) != "http" || !p.GetProvider(ctx).Exists(ViperKeyCourierSMSRequestConfig) {
+		return nil
+	}
+
+	config, err := json.Marshal(p.GetProvider(ctx).Get(ViperKeyCourierSMSRequestConfig))
+	if err != nil {
+		p.l.WithError(err).Warn("Unable to marshal SMS request configuration.")
+		return nil
+	}
+
+	return config
+}
+
 func (p *Config) CourierTemplatesRoot(ctx context.Context) string {
 	return p.GetProvider(ctx).StringF(ViperKeyCourierTemplatesPath, "courier/builtin/templates")
 }
@@ -1150,6 +2155,10 @@ func (p *Config) CourierSMSTemplatesLoginCodeValid(ctx context.Context) *Courier
 	return p.CourierSMSTemplatesHelper(ctx, ViperKeyCourierTemplatesLoginCodeValidSMS)
 }
 
+func (p *Config) CourierSMSTemplatesRecoveryCodeValid(ctx context.Context) *CourierSMSTemplate {
+	return p.CourierSMSTemplatesHelper(ctx, ViperKeyCourierTemplatesRecoveryCodeValidSMS)
+}
+
 func (p *Config) CourierTemplatesLoginCodeValid(ctx context.Context) *CourierEmailTemplate {
 	return p.CourierEmailTemplatesHelper(ctx, ViperKeyCourierTemplatesLoginCodeValidEmail)
 }
@@ -1162,6 +2171,10 @@ func (p *Config) CourierMessageRetries(ctx context.Context) int {
 	return p.GetProvider(ctx).IntF(ViperKeyCourierMessageRetries, 5)
 }
 
+func (p *Config) CourierDeduplicateWindow(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeyCourierDeduplicateWindow, 0)
+}
+
 func (p *Config) CourierWorkerPullCount(ctx context.Context) int {
 	return p.GetProvider(ctx).Int(ViperKeyCourierWorkerPullCount)
 }
@@ -1207,9 +2220,33 @@ func (p *Config) CourierChannels(ctx context.Context) (ccs []*CourierChannel, _
 		}
 	}
 	ccs = append(ccs, &channel)
+
+	for _, c := range ccs {
+		if c.Type != "smtp" || c.SMTPConfig == nil || c.SMTPConfig.ConnectionURI == "" {
+			continue
+		}
+		if err := validateSMTPScheme(c.SMTPConfig.ConnectionURI); err != nil {
+			return nil, errors.WithMessagef(err, "courier channel %q has an invalid SMTP connection URI", c.ID)
+		}
+	}
 	return ccs, nil
 }
 
+// validateSMTPScheme only checks the scheme of the connection URI, because passwords with
+// special characters are often not URL encoded and would fail a full URL parse.
+func validateSMTPScheme(uri string) error {
+	scheme, _, ok := strings.Cut(uri, "://")
+	if !ok {
+		return errors.Errorf("the URI must start with smtp:// or smtps://")
+	}
+	// The SMTP client only supports these schemes, see courier.NewSMTPClient.
+	switch strings.ToLower(scheme) {
+	case "smtp", "smtps":
+		return nil
+	}
+	return errors.Errorf("the URI scheme %q is not supported, use smtp or smtps", scheme)
+}
+
 func splitUrlAndFragment(s string) (string, string) {
 	i := strings.IndexByte(s, '#')
 	if i < 0 {
@@ -1269,6 +2306,12 @@ func (p *Config) IsInsecureDevMode(ctx context.Context) bool {
 	return p.GetProvider(ctx).Bool("dev")
 }
 
+// SuppressDevWarning returns true if the warning logged on start up when running in dev mode
+// should be skipped. It can be set using either the config file or the `--suppress-dev-warning` flag.
+func (p *Config) SuppressDevWarning(ctx context.Context) bool {
+	return p.GetProvider(ctx).Bool(ViperKeyLogSuppressDevWarning) || p.GetProvider(ctx).Bool(ViperKeySuppressDevWarningFlag)
+}
+
 func (p *Config) IsBackgroundCourierEnabled(ctx context.Context) bool {
 	return p.GetProvider(ctx).Bool("watch-courier")
 }
@@ -1289,6 +2332,16 @@ func (p *Config) SelfServiceFlowVerificationRequestLifespan(ctx context.Context)
 	return p.GetProvider(ctx).DurationF(ViperKeySelfServiceVerificationRequestLifespan, time.Hour)
 }
 
+func (p *Config) SelfServiceFlowVerificationMaxSubmitAttempts(ctx context.Context) int {
+	return p.GetProvider(ctx).IntF(ViperKeySelfServiceVerificationMaxSubmitAttempts, 5)
+}
+
+// SelfServiceFlowVerificationRevalidateAfter returns how long a verified address stays verified
+// before it needs to be verified again. Zero disables revalidation.
+func (p *Config) SelfServiceFlowVerificationRevalidateAfter(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeySelfServiceVerificationRevalidateAfter, 0)
+}
+
 func (p *Config) SelfServiceFlowVerificationReturnTo(ctx context.Context, defaultReturnTo *url.URL) *url.URL {
 	return p.GetProvider(ctx).RequestURIF(ViperKeySelfServiceVerificationBrowserDefaultReturnTo, defaultReturnTo)
 }
@@ -1305,10 +2358,29 @@ func (p *Config) SelfServiceFlowRecoveryRequestLifespan(ctx context.Context) tim
 	return p.GetProvider(ctx).DurationF(ViperKeySelfServiceRecoveryRequestLifespan, time.Hour)
 }
 
+func (p *Config) SelfServiceFlowRecoveryMaxSubmitAttempts(ctx context.Context) int {
+	return p.GetProvider(ctx).IntF(ViperKeySelfServiceRecoveryMaxSubmitAttempts, 5)
+}
+
+func (p *Config) SelfServiceFlowRecoveryUseOpaqueTokens(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeySelfServiceRecoveryUseOpaqueTokens, false)
+}
+
+func (p *Config) SelfServiceFlowVerificationUseOpaqueTokens(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeySelfServiceVerificationUseOpaqueTokens, false)
+}
+
 func (p *Config) SelfServiceFlowRecoveryNotifyUnknownRecipients(ctx context.Context) bool {
 	return p.GetProvider(ctx).BoolF(ViperKeySelfServiceRecoveryNotifyUnknownRecipients, false)
 }
 
+// SelfServiceFlowRecoveryChannelOrder returns the channels a recovery code is
+// sent through, in the order they are attempted. The courier attempts the next
+// channel if it fails to deliver the message of the previous one.
+func (p *Config) SelfServiceFlowRecoveryChannelOrder(ctx context.Context) []string {
+	return p.GetProvider(ctx).StringsF(ViperKeySelfServiceRecoveryChannelOrder, []string{"email"})
+}
+
 func (p *Config) SelfServiceLinkMethodLifespan(ctx context.Context) time.Duration {
 	return p.GetProvider(ctx).DurationF(ViperKeyLinkLifespan, time.Hour)
 }
@@ -1329,6 +2401,19 @@ func (p *Config) DatabaseCleanupBatchSize(ctx context.Context) int {
 	return p.GetProvider(ctx).Int(ViperKeyDatabaseCleanupBatchSize)
 }
 
+// DatabaseCleanupSessionsBatchSize returns the number of sessions to remove in
+// one cleanup iteration, or the given batch size of the other tables if it is
+// not configured.
+func (p *Config) DatabaseCleanupSessionsBatchSize(ctx context.Context, fallback int) int {
+	return p.GetProvider(ctx).IntF(ViperKeyDatabaseCleanupSessionsBatchSize, fallback)
+}
+
+// DatabaseCleanupSessionsSleep returns the delay after cleaning up sessions, or
+// the given delay between the other tables if it is not configured.
+func (p *Config) DatabaseCleanupSessionsSleep(ctx context.Context, fallback time.Duration) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeyDatabaseCleanupSessionsSleep, fallback)
+}
+
 func (p *Config) SelfServiceFlowRecoveryAfterHooks(ctx context.Context, strategy string) []SelfServiceHook {
 	return p.selfServiceHooks(ctx, HookStrategyKey(ViperKeySelfServiceRecoveryAfter, strategy))
 }
@@ -1354,6 +2439,13 @@ func (p *Config) SessionSameSiteMode(ctx context.Context) http.SameSite {
 }
 
 func (p *Config) SessionDomain(ctx context.Context) string {
+	if p.SessionUseHostPrefix(ctx) {
+		return ""
+	}
+	return p.sessionDomain(ctx)
+}
+
+func (p *Config) sessionDomain(ctx context.Context) string {
 	if !p.GetProvider(ctx).Exists(ViperKeySessionDomain) {
 		return p.CookieDomain(ctx)
 	}
@@ -1388,10 +2480,24 @@ func (p *Config) SessionRefreshMinTimeLeft(ctx context.Context) time.Duration {
 	return p.GetProvider(ctx).DurationF(ViperKeySessionRefreshMinTimeLeft, p.SessionLifespan(ctx))
 }
 
+// SessionResignOnRead returns true if session cookies signed with an older secret should be
+// re-issued with the current secret.
+func (p *Config) SessionResignOnRead(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeySessionResignOnRead, false)
+}
+
 func (p *Config) SelfServiceSettingsRequiredAAL(ctx context.Context) string {
 	return p.GetProvider(ctx).String(ViperKeySelfServiceSettingsRequiredAAL)
 }
 
+func (p *Config) SelfServiceSettingsOptimisticConcurrency(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeySelfServiceSettingsOptimisticConcurrency, false)
+}
+
+func (p *Config) SelfServiceSettingsReturnChangedTraits(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeySelfServiceSettingsReturnChangedTraits, false)
+}
+
 func (p *Config) CookieSameSiteMode(ctx context.Context) http.SameSite {
 	switch p.GetProvider(ctx).StringF(ViperKeyCookieSameSite, "Lax") {
 	case "Lax":
@@ -1405,6 +2511,13 @@ func (p *Config) CookieSameSiteMode(ctx context.Context) http.SameSite {
 }
 
 func (p *Config) SessionPath(ctx context.Context) string {
+	if p.SessionUseHostPrefix(ctx) {
+		return "/"
+	}
+	return p.sessionPath(ctx)
+}
+
+func (p *Config) sessionPath(ctx context.Context) string {
 	if !p.GetProvider(ctx).Exists(ViperKeySessionPath) {
 		return p.CookiePath(ctx)
 	}
@@ -1445,6 +2558,37 @@ func (p *Config) ConfigVersion(ctx context.Context) string {
 	return p.GetProvider(ctx).StringF(ViperKeyVersion, UnknownVersion)
 }
 
+// VersionError is returned by CheckVersion if the config version is missing or
+// does not match the version Ory Kratos is running on.
+type VersionError struct {
+	ConfigVersion  string
+	RuntimeVersion string
+}
+
+func (e *VersionError) Error() string {
+	if e.ConfigVersion == UnknownVersion {
+		return "The config has no version specified. Add the version to improve your development experience."
+	}
+	return fmt.Sprintf("Config version is '%s' but kratos runs on version '%s'", e.ConfigVersion, e.RuntimeVersion)
+}
+
+// CheckVersion returns a *VersionError if the config has no version or if the
+// version does not match the version Ory Kratos is running on.
+func (p *Config) CheckVersion(ctx context.Context) error {
+	configVersion := p.ConfigVersion(ctx)
+	if configVersion == UnknownVersion ||
+		(Version != "" && configVersion != Version) {
+		return errors.WithStack(&VersionError{ConfigVersion: configVersion, RuntimeVersion: Version})
+	}
+	return nil
+}
+
+// StrictVersion returns true if a config version mismatch should fail the boot
+// process instead of logging a warning.
+func (p *Config) StrictVersion(ctx context.Context) bool {
+	return p.GetProvider(ctx).Bool(ViperKeyStrictVersion) || p.GetProvider(ctx).Bool(ViperKeyStrictVersionFlag)
+}
+
 func (p *Config) PasswordPolicyConfig(ctx context.Context) *PasswordPolicy {
 	return &PasswordPolicy{
 		HaveIBeenPwnedHost:               p.GetProvider(ctx).StringF(ViperKeyPasswordHaveIBeenPwnedHost, "api.pwnedpasswords.com"),
@@ -1460,28 +2604,156 @@ func (p *Config) WebAuthnForPasswordless(ctx context.Context) bool {
 	return p.GetProvider(ctx).BoolF(ViperKeyWebAuthnPasswordless, false)
 }
 
+const (
+	WebAuthnSignCountPolicyIgnore = "ignore"
+	WebAuthnSignCountPolicyWarn   = "warn"
+	WebAuthnSignCountPolicyReject = "reject"
+)
+
+func (p *Config) WebAuthnSignCountPolicy(ctx context.Context) string {
+	return p.GetProvider(ctx).StringF(ViperKeyWebAuthnSignCountPolicy, WebAuthnSignCountPolicyIgnore)
+}
+
+// WebAuthnLegacyAppID returns the FIDO U2F AppID which is sent as the `appid` extension during
+// WebAuthn logins, or nil if it is not configured.
+func (p *Config) WebAuthnLegacyAppID(ctx context.Context) *url.URL {
+	return p.GetProvider(ctx).URIF(ViperKeyWebAuthnLegacyAppID, nil)
+}
+
+// WebAuthnChallengeLifespan returns how long a WebAuthn login challenge may be
+// answered after it was issued. Defaults to the login flow lifespan.
+func (p *Config) WebAuthnChallengeLifespan(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeyWebAuthnChallengeLifespan, p.SelfServiceFlowLoginRequestLifespan(ctx))
+}
+
+// WebAuthnUseExternalScript returns true if WebAuthn and passkey nodes should reference the
+// functions of webauthn.js instead of carrying inline scripts.
+func (p *Config) WebAuthnUseExternalScript(ctx context.Context) bool {
+	return p.GetProvider(ctx).BoolF(ViperKeyWebAuthnUseExternalScript, false)
+}
+
+// WebAuthnMaxAllowCredentials returns the maximum number of credentials sent as
+// `allowCredentials` when initiating a WebAuthn login. Zero means unlimited.
+func (p *Config) WebAuthnMaxAllowCredentials(ctx context.Context) int {
+	return p.GetProvider(ctx).IntF(ViperKeyWebAuthnMaxAllowCredentials, 0)
+}
+
+// WebAuthnUserNameTrait returns the path of the identity trait used as the
+// name of the WebAuthn user entity.
+func (p *Config) WebAuthnUserNameTrait(ctx context.Context) string {
+	return p.GetProvider(ctx).String(ViperKeyWebAuthnUserNameTrait)
+}
+
+// WebAuthnUserDisplayNameTrait returns the path of the identity trait used as
+// the display name of the WebAuthn user entity.
+func (p *Config) WebAuthnUserDisplayNameTrait(ctx context.Context) string {
+	return p.GetProvider(ctx).String(ViperKeyWebAuthnUserDisplayNameTrait)
+}
+
+type webAuthnContextKey int
+
+const webAuthnRequestHostKey webAuthnContextKey = 1
+
+// WithWebAuthnRequestHost stores the host a request was made to in the context. WebAuthnConfig and PasskeyConfig
+// use it to pick the relying party of the matching tenant. The host must be resolved with x.RequestHost so that
+// clients can not pick a tenant through forwarded headers.
+func WithWebAuthnRequestHost(ctx context.Context, host string) context.Context {
+	return context.WithValue(ctx, webAuthnRequestHostKey, host)
+}
+
+// webAuthnTenant returns the tenant configured at the given key for the request host stored in the context, if any.
+func (p *Config) webAuthnTenant(ctx context.Context, key string) (*WebAuthnTenant, bool) {
+	host, _ := ctx.Value(webAuthnRequestHostKey).(string)
+	if host == "" {
+		return nil, false
+	}
+
+	var tenants []WebAuthnTenant
+	if err := p.GetProvider(ctx).Koanf.Unmarshal(key, &tenants); err != nil {
+		p.l.WithError(err).Warnf("Unable to decode WebAuthn tenants from key %s.", key)
+		return nil, false
+	}
+
+	hostname, _, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+	}
+
+	for k := range tenants {
+		if strings.EqualFold(tenants[k].Host, host) || strings.EqualFold(tenants[k].Host, hostname) {
+			return &tenants[k], true
+		}
+	}
+	return nil, false
+}
+
 func (p *Config) WebAuthnConfig(ctx context.Context) *webauthn.Config {
 	scheme := p.SelfPublicURL(ctx).Scheme
 	id := p.GetProvider(ctx).String(ViperKeyWebAuthnRPID)
 	origin := p.GetProvider(ctx).String(ViperKeyWebAuthnRPOrigin)
 	origins := p.GetProvider(ctx).StringsF(ViperKeyWebAuthnRPOrigins, []string{stringsx.Coalesce(origin, scheme+"://"+id)})
+	// The display name is shown in the browser prompt. Fall back to the RP ID, because an empty display name
+	// is rejected by the WebAuthn library.
+	displayName := p.GetProvider(ctx).StringF(ViperKeyWebAuthnRPDisplayName, id)
+	if tenant, ok := p.webAuthnTenant(ctx, ViperKeyWebAuthnTenants); ok {
+		id = tenant.ID
+		origins = tenant.Origins
+		if len(origins) == 0 {
+			origins = []string{scheme + "://" + id}
+		}
+		displayName = stringsx.Coalesce(tenant.DisplayName, id)
+	}
+
+	// Attestation statements can only be verified if the authenticator conveys them.
+	var attestation protocol.ConveyancePreference
+	if p.WebAuthnVerifyAttestation(ctx).Enabled {
+		attestation = protocol.PreferDirectAttestation
+	}
+
 	return &webauthn.Config{
-		RPDisplayName: p.GetProvider(ctx).String(ViperKeyWebAuthnRPDisplayName),
-		RPID:          id,
-		RPOrigins:     origins,
+		RPDisplayName:         displayName,
+		RPID:                  id,
+		RPOrigins:             origins,
+		AttestationPreference: attestation,
 		AuthenticatorSelection: protocol.AuthenticatorSelection{
-			UserVerification: protocol.VerificationDiscouraged,
+			AuthenticatorAttachment: protocol.AuthenticatorAttachment(p.GetProvider(ctx).String(ViperKeyWebAuthnAuthenticatorAttachment)),
+			UserVerification:        protocol.UserVerificationRequirement(p.GetProvider(ctx).StringF(ViperKeyWebAuthnUserVerification, string(protocol.VerificationDiscouraged))),
 		},
 		EncodeUserIDAsString: false,
 	}
 }
 
+// WebAuthnVerifyAttestation returns how attestation statements of new WebAuthn
+// credentials are verified.
+func (p *Config) WebAuthnVerifyAttestation(ctx context.Context) *WebAuthnAttestationVerification {
+	return &WebAuthnAttestationVerification{
+		Enabled:     p.GetProvider(ctx).BoolF(ViperKeyWebAuthnVerifyAttestation+".enabled", false),
+		MetadataURL: p.GetProvider(ctx).String(ViperKeyWebAuthnVerifyAttestation + ".metadata_url"),
+	}
+}
+
+// PasskeyChallengeLifespan returns how long a passkey login challenge may be
+// answered after it was issued. Defaults to the login flow lifespan.
+func (p *Config) PasskeyChallengeLifespan(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeyPasskeyChallengeLifespan, p.SelfServiceFlowLoginRequestLifespan(ctx))
+}
+
 func (p *Config) PasskeyConfig(ctx context.Context) *webauthn.Config {
 	scheme := p.SelfPublicURL(ctx).Scheme
 	id := p.GetProvider(ctx).String(ViperKeyPasskeyRPID)
 	origins := p.GetProvider(ctx).StringsF(ViperKeyPasskeyRPOrigins, []string{scheme + "://" + id})
+	displayName := p.GetProvider(ctx).String(ViperKeyPasskeyRPDisplayName)
+	if tenant, ok := p.webAuthnTenant(ctx, ViperKeyPasskeyTenants); ok {
+		id = tenant.ID
+		origins = tenant.Origins
+		if len(origins) == 0 {
+			origins = []string{scheme + "://" + id}
+		}
+		displayName = stringsx.Coalesce(tenant.DisplayName, id)
+	}
+
 	return &webauthn.Config{
-		RPDisplayName: p.GetProvider(ctx).String(ViperKeyPasskeyRPDisplayName),
+		RPDisplayName: displayName,
 		RPID:          id,
 		RPOrigins:     origins,
 		AuthenticatorSelection: protocol.AuthenticatorSelection{
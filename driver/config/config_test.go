@@ -11,10 +11,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -26,6 +30,8 @@ import (
 	"github.com/ory/x/snapshotx"
 
 	"github.com/ghodss/yaml"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/inhies/go-bytesize"
 	"github.com/spf13/cobra"
 
 	"github.com/ory/kratos/internal/testhelpers"
@@ -40,10 +46,13 @@ import (
 	_ "github.com/ory/jsonschema/v3/fileloader"
 
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/embedx"
+	"github.com/ory/kratos/schema"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
 func TestViperProvider(t *testing.T) {
@@ -219,13 +228,14 @@ func TestViperProvider(t *testing.T) {
 				enabled bool
 			}{
 				{id: "password", enabled: true, config: `{"haveibeenpwned_host":"api.pwnedpasswords.com","haveibeenpwned_enabled":true,"ignore_network_errors":true,"max_breaches":0,"min_password_length":8,"identifier_similarity_check_enabled":true}`},
-				{id: "oidc", enabled: true, config: `{"providers":[{"client_id":"a","client_secret":"b","id":"github","provider":"github","mapper_url":"http://test.kratos.ory.sh/default-identity.schema.json"}]}`},
-				{id: "totp", enabled: true, config: `{"issuer":"issuer.ory.sh"}`},
+				{id: "oidc", enabled: true, config: `{"max_providers":250,"on_existing_account":"link_after_verification","providers":[{"client_id":"a","client_secret":"b","id":"github","provider":"github","mapper_url":"http://test.kratos.ory.sh/default-identity.schema.json"}]}`},
+				{id: "totp", enabled: true, config: `{"issuer":"issuer.ory.sh","emit_qr_data_uri":false}`},
 			} {
 				strategy := p.SelfServiceStrategy(ctx, tc.id)
 				assert.Equal(t, tc.enabled, strategy.Enabled)
 				assert.JSONEq(t, tc.config, string(strategy.Config))
 			}
+			assert.False(t, p.TOTPEmitQRDataURI(ctx))
 		})
 
 		t.Run("method=registration", func(t *testing.T) {
@@ -332,6 +342,27 @@ func TestViperProvider(t *testing.T) {
 					assert.Equal(t, tc.hooks, hooks)
 				})
 			}
+
+			t.Run("hook=after/allowed_headers", func(t *testing.T) {
+				p := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation())
+				p.MustSet(ctx, config.ViperKeySelfServiceLoginAfter+".hooks", []map[string]interface{}{
+					{"hook": "web_hook", "config": map[string]interface{}{"url": "https://test.kratos.ory.sh/hook", "method": "POST", "allowed_headers": []string{"user-agent", "Accept-Language"}}},
+					{"hook": "web_hook", "config": map[string]interface{}{"url": "https://test.kratos.ory.sh/hook", "method": "POST"}},
+				})
+				hooks := p.SelfServiceFlowLoginAfterHooks(ctx, config.HookGlobal)
+				require.Len(t, hooks, 2)
+				assert.Equal(t, []string{"User-Agent", "Accept-Language"}, hooks[0].AllowedHeaders)
+				assert.Nil(t, hooks[1].AllowedHeaders)
+			})
+
+			t.Run("hook=after/allowed_headers/invalid", func(t *testing.T) {
+				_, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+					configx.WithConfigFiles("stub/.kratos.yaml"),
+					configx.WithValue(config.ViperKeySelfServiceLoginAfter+".hooks", []map[string]interface{}{
+						{"hook": "web_hook", "config": map[string]interface{}{"url": "https://test.kratos.ory.sh/hook", "method": "POST", "allowed_headers": []string{"User Agent"}}},
+					}))
+				assert.Error(t, err)
+			})
 		})
 
 		t.Run("method=settings", func(t *testing.T) {
@@ -399,11 +430,88 @@ func TestViperProvider(t *testing.T) {
 
 			p.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+".oidc", strategyConfigJSON)
 			strategy := p.SelfServiceStrategy(ctx, "oidc")
-			assert.JSONEq(t, providerConfigJSON, string(strategy.Config))
+			assert.JSONEq(t, `{"max_providers":250,"on_existing_account":"link_after_verification",`+providerConfigJSON[1:], string(strategy.Config))
 		})
 	})
 }
 
+func TestArgon2Autotune(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("case=disabled", func(t *testing.T) {
+		p := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation(),
+			configx.WithValues(map[string]interface{}{
+				config.ViperKeyHasherArgon2ConfigMemory:     "1MB",
+				config.ViperKeyHasherArgon2ConfigIterations: 7,
+			}))
+		assert.False(t, p.HasherArgon2Autotune(ctx))
+		assert.EqualValues(t, 7, p.HasherArgon2(ctx).Iterations)
+	})
+
+	autotuned := map[string]interface{}{
+		config.ViperKeyHasherArgon2ConfigAutotune:          true,
+		config.ViperKeyHasherArgon2ConfigDedicatedMemory:   "1MB",
+		config.ViperKeyHasherArgon2ConfigParallelism:       1,
+		config.ViperKeyHasherArgon2ConfigExpectedDuration:  "10ms",
+		config.ViperKeyHasherArgon2ConfigExpectedDeviation: "5ms",
+	}
+
+	t.Run("case=enabled", func(t *testing.T) {
+		p := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation(),
+			configx.WithValues(autotuned))
+
+		c := p.HasherArgon2(ctx)
+		assert.EqualValues(t, bytesize.MB, c.Memory, "the default memory is capped at the dedicated memory")
+		assert.GreaterOrEqual(t, c.Iterations, uint32(1))
+		assert.Equal(t, c, p.HasherArgon2(ctx), "the calibrated parameters are stable")
+
+		other := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation(),
+			configx.WithValues(autotuned))
+		assert.Equal(t, c, other.HasherArgon2(ctx), "the parameters are calibrated once per process")
+	})
+
+	t.Run("case=explicit iterations take precedence", func(t *testing.T) {
+		p := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation(),
+			configx.WithValues(autotuned),
+			configx.WithValues(map[string]interface{}{
+				config.ViperKeyHasherArgon2ConfigMemory:     "4MB",
+				config.ViperKeyHasherArgon2ConfigIterations: 7,
+			}))
+
+		c := p.HasherArgon2(ctx)
+		assert.EqualValues(t, bytesize.MB, c.Memory, "explicit memory is capped at the dedicated memory as well")
+		assert.EqualValues(t, 7, c.Iterations)
+
+		p.MustSet(ctx, config.ViperKeyHasherArgon2ConfigIterations, 9)
+		assert.EqualValues(t, 9, p.HasherArgon2(ctx).Iterations, "changes to the configuration are picked up")
+	})
+
+	t.Run("case=explicit default iterations take precedence", func(t *testing.T) {
+		p := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation(),
+			configx.WithValues(autotuned),
+			configx.WithValues(map[string]interface{}{
+				config.ViperKeyHasherArgon2ConfigIterations:       config.Argon2DefaultIterations,
+				config.ViperKeyHasherArgon2ConfigExpectedDuration: "1s",
+			}))
+
+		assert.EqualValues(t, config.Argon2DefaultIterations, p.HasherArgon2(ctx).Iterations)
+	})
+
+	t.Run("case=calibration is capped", func(t *testing.T) {
+		start := time.Now()
+		p := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation(),
+			configx.WithValues(autotuned),
+			configx.WithValues(map[string]interface{}{
+				config.ViperKeyHasherArgon2ConfigExpectedDuration:  "2s",
+				config.ViperKeyHasherArgon2ConfigExpectedDeviation: "1ns",
+			}))
+
+		assert.GreaterOrEqual(t, p.HasherArgon2(ctx).Iterations, uint32(1))
+		assert.Less(t, time.Since(start), 10*time.Second)
+	})
+}
+
 func TestBcrypt(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -473,6 +581,25 @@ func TestViperProvider_Secrets(t *testing.T) {
 	err := p.Set(ctx, config.ViperKeySecretsCipher, []string{"short-secret-key"})
 	require.NoError(t, err)
 	assert.Equal(t, [][32]byte{}, p.SecretsCipher(ctx))
+	assert.Empty(t, p.SecretsCipherWithIDs(ctx))
+
+	t.Run("case=cipher secrets have stable ids", func(t *testing.T) {
+		p := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation())
+		first, second := "secret-thirty-two-character-long", "another-thirty-two-char-long-key"
+		p.MustSet(ctx, config.ViperKeySecretsCipher, []string{"short-secret-key", first, second})
+
+		secrets := p.SecretsCipherWithIDs(ctx)
+		require.Len(t, secrets, 2)
+		assert.Equal(t, [32]byte([]byte(first)), secrets[0].Key)
+		assert.Equal(t, [32]byte([]byte(second)), secrets[1].Key)
+		assert.Len(t, secrets[0].ID, 8)
+		assert.NotEqual(t, secrets[0].ID, secrets[1].ID)
+		assert.Equal(t, secrets, p.SecretsCipherWithIDs(ctx))
+		assert.Equal(t, [][32]byte{secrets[0].Key, secrets[1].Key}, p.SecretsCipher(ctx))
+
+		p.MustSet(ctx, config.ViperKeySecretsCipher, []string{second})
+		assert.Equal(t, secrets[1:], p.SecretsCipherWithIDs(ctx), "the id does not depend on the position")
+	})
 }
 
 func TestViperProvider_Defaults(t *testing.T) {
@@ -633,6 +760,122 @@ func TestSession(t *testing.T) {
 	assert.Equal(t, true, p.SessionWhoAmICaching(ctx))
 }
 
+func TestSessionUseHostPrefix(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	newConfig := func(values map[string]interface{}) (*config.Config, error) {
+		return config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.yaml"),
+			configx.WithValues(values))
+	}
+
+	t.Run("case=enforces the required attributes", func(t *testing.T) {
+		p, err := newConfig(map[string]interface{}{config.ViperKeySessionUseHostPrefix: true})
+		require.NoError(t, err)
+
+		assert.True(t, p.SessionUseHostPrefix(ctx))
+		assert.Equal(t, "__Host-ory_kratos_session", p.SessionName(ctx))
+		assert.Equal(t, "/", p.SessionPath(ctx))
+		assert.Equal(t, "", p.SessionDomain(ctx))
+	})
+
+	t.Run("case=does not prefix twice", func(t *testing.T) {
+		p, err := newConfig(map[string]interface{}{
+			config.ViperKeySessionUseHostPrefix: true,
+			config.ViperKeySessionName:          "__Host-session",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "__Host-session", p.SessionName(ctx))
+	})
+
+	for _, tc := range []struct {
+		name   string
+		values map[string]interface{}
+		err    string
+	}{
+		{name: "session domain", values: map[string]interface{}{config.ViperKeySessionDomain: "ory.sh"}, err: `no domain, but the domain "ory.sh" is configured`},
+		{name: "cookie domain", values: map[string]interface{}{config.ViperKeyCookieDomain: "ory.sh"}, err: `no domain, but the domain "ory.sh" is configured`},
+		{name: "session path", values: map[string]interface{}{config.ViperKeySessionPath: "/kratos"}, err: `path to be "/", but the path "/kratos" is configured`},
+	} {
+		t.Run("case=fails with conflicting "+tc.name, func(t *testing.T) {
+			tc.values[config.ViperKeySessionUseHostPrefix] = true
+			_, err := newConfig(tc.values)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestAllKeys(t *testing.T) {
+	t.Parallel()
+	keys := config.AllKeys()
+
+	find := func(t *testing.T, key string) config.KeyInfo {
+		for _, k := range keys {
+			if k.Key == key {
+				return k
+			}
+		}
+		require.Failf(t, "key not found", "%s", key)
+		return config.KeyInfo{}
+	}
+
+	assert.Equal(t, config.KeyInfo{Key: config.ViperKeySessionLifespan, Type: "string", Default: "24h"}, find(t, config.ViperKeySessionLifespan))
+	assert.Equal(t, config.KeyInfo{Key: config.ViperKeyPublicPort, Type: "integer", Default: float64(4433)}, find(t, config.ViperKeyPublicPort))
+	assert.Equal(t, config.KeyInfo{Key: config.ViperKeySessionPersistentCookie, Type: "boolean", Default: true}, find(t, config.ViperKeySessionPersistentCookie))
+	assert.Equal(t, config.KeyInfo{Key: config.ViperKeyDSN, Type: "string", Secret: true}, find(t, config.ViperKeyDSN))
+	assert.True(t, find(t, config.ViperKeySecretsCookie).Secret)
+	assert.Equal(t, "array", find(t, config.ViperKeySecretsCookie).Type)
+
+	assert.True(t, sort.SliceIsSorted(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key }))
+	assert.True(t, find(t, "courier.http.request_config.auth.config.password").Secret)
+	assert.True(t, find(t, "courier.sms.request_config.auth.config.value").Secret)
+
+	t.Run("case=all credential leaves are secret", func(t *testing.T) {
+		schema := gjson.Parse(embedx.ConfigSchema)
+
+		var leaves []string
+		var walk func(node gjson.Result, path []string, refs []string)
+		walk = func(node gjson.Result, path []string, refs []string) {
+			if ref := node.Get("$ref").String(); strings.HasPrefix(ref, "#/") {
+				if slices.Contains(refs, ref) {
+					return
+				}
+				walk(schema.Get(strings.ReplaceAll(strings.TrimPrefix(ref, "#/"), "/", ".")), path, append(slices.Clone(refs), ref))
+				return
+			}
+			node.Get("properties").ForEach(func(name, property gjson.Result) bool {
+				p := append(slices.Clone(path), name.String())
+				switch name.String() {
+				case "password", "value", "secret", "client_secret":
+					if property.Get("type").String() == "string" {
+						leaves = append(leaves, strings.Join(p, "."))
+					}
+				}
+				walk(property, p, refs)
+				return true
+			})
+			for _, k := range []string{"items", "allOf", "anyOf", "oneOf", "then", "else"} {
+				if n := node.Get(k); n.IsArray() {
+					n.ForEach(func(_, branch gjson.Result) bool {
+						walk(branch, path, refs)
+						return true
+					})
+				} else if n.IsObject() {
+					walk(n, path, refs)
+				}
+			}
+		}
+		walk(schema, nil, nil)
+
+		require.NotEmpty(t, leaves)
+		for _, leaf := range leaves {
+			assert.Truef(t, config.IsSecretKey(leaf), "%s must be a secret key", leaf)
+		}
+	})
+}
+
 func TestCookies(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -713,6 +956,76 @@ func TestViperProvider_DSN(t *testing.T) {
 	})
 }
 
+func TestViperProvider_DSNExpansion(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("KRATOS_TEST_DSN_USER", "kratos")
+	t.Setenv("KRATOS_TEST_DSN_PASS", "secret")
+
+	t.Run("case=expands environment variables", func(t *testing.T) {
+		p := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation())
+		p.MustSet(ctx, config.ViperKeyDSN, "postgres://${KRATOS_TEST_DSN_USER}:${KRATOS_TEST_DSN_PASS}@db/kratos")
+
+		assert.Equal(t, "postgres://kratos:secret@db/kratos", p.DSN(ctx))
+	})
+
+	t.Run("case=expands bare environment variables", func(t *testing.T) {
+		p := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation())
+		p.MustSet(ctx, config.ViperKeyDSN, "postgres://$KRATOS_TEST_DSN_USER:$KRATOS_TEST_DSN_PASS@db/kratos")
+
+		assert.Equal(t, "postgres://kratos:secret@db/kratos", p.DSN(ctx))
+	})
+
+	t.Run("case=escapes values in the user info", func(t *testing.T) {
+		t.Setenv("KRATOS_TEST_DSN_SPECIAL_PASS", "p@ss:w/rd?# +")
+		t.Setenv("KRATOS_TEST_DSN_DIR", "/var/lib/kratos")
+
+		p := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation())
+		p.MustSet(ctx, config.ViperKeyDSN, "postgres://${KRATOS_TEST_DSN_USER}:${KRATOS_TEST_DSN_SPECIAL_PASS}@db/kratos")
+
+		dsn := p.DSN(ctx)
+		assert.Equal(t, "postgres://kratos:p%40ss%3Aw%2Frd%3F%23%20%2B@db/kratos", dsn)
+		u, err := url.Parse(dsn)
+		require.NoError(t, err)
+		pass, _ := u.User.Password()
+		assert.Equal(t, "p@ss:w/rd?# +", pass)
+		assert.Equal(t, "db", u.Host)
+
+		p.MustSet(ctx, config.ViperKeyDSN, "sqlite://${KRATOS_TEST_DSN_DIR}/db.sqlite?_fk=true")
+		assert.Equal(t, "sqlite:///var/lib/kratos/db.sqlite?_fk=true", p.DSN(ctx))
+	})
+
+	t.Run("case=unescapes dollar signs", func(t *testing.T) {
+		p := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation())
+		for dsn, expected := range map[string]string{
+			"postgres://kratos:pa$$HOME@db/kratos":                   "postgres://kratos:pa$HOME@db/kratos",
+			"postgres://kratos:pa$${KRATOS_TEST_DSN_PASS}@db/kratos": "postgres://kratos:pa${KRATOS_TEST_DSN_PASS}@db/kratos",
+			"postgres://kratos:pa$$$KRATOS_TEST_DSN_PASS@db/kratos":  "postgres://kratos:pa$secret@db/kratos",
+			"postgres://kratos:pa${@db/kratos":                       "postgres://kratos:pa${@db/kratos",
+			"postgres://kratos:pa$1@db/kratos":                       "postgres://kratos:pa$1@db/kratos",
+		} {
+			p.MustSet(ctx, config.ViperKeyDSN, dsn)
+			assert.Equal(t, expected, p.DSN(ctx), dsn)
+		}
+	})
+
+	t.Run("case=unresolved environment variable", func(t *testing.T) {
+		for _, dsn := range []string{
+			"postgres://${KRATOS_TEST_DSN_UNSET}@db/kratos",
+			"postgres://kratos:$KRATOS_TEST_DSN_UNSET@db/kratos",
+		} {
+			var exitCode int
+			l := logrusx.New("", "", logrusx.WithExitFunc(func(i int) {
+				exitCode = i
+			}))
+			p := config.MustNew(t, l, os.Stderr, &contextx.Default{}, configx.SkipValidation())
+			p.MustSet(ctx, config.ViperKeyDSN, dsn)
+
+			assert.Equal(t, "", p.DSN(ctx), dsn)
+			assert.NotEqual(t, 0, exitCode, dsn)
+		}
+	})
+}
+
 func TestViperProvider_ParseURIOrFail(t *testing.T) {
 	t.Parallel()
 
@@ -922,7 +1235,7 @@ func TestIdentitySchemaValidation(t *testing.T) {
 	})
 
 	type identity struct {
-		Schemas []map[string]string `json:"schemas"`
+		Schemas []map[string]any `json:"schemas"`
 	}
 
 	type configFile struct {
@@ -948,7 +1261,7 @@ func TestIdentitySchemaValidation(t *testing.T) {
 			},
 			DSN: "memory",
 			Identity: &identity{
-				Schemas: []map[string]string{{"id": "default", "url": "base64://" + base64.StdEncoding.EncodeToString(identityTest)}},
+				Schemas: []map[string]any{{"id": "default", "url": "base64://" + base64.StdEncoding.EncodeToString(identityTest)}},
 			},
 		}
 	}
@@ -965,7 +1278,7 @@ func TestIdentitySchemaValidation(t *testing.T) {
 		assert.NoError(t, tmpFile.Sync())
 	}
 
-	testWatch := func(t *testing.T, ctx context.Context, cmd *cobra.Command, identity *configFile) (*config.Config, *test.Hook, func([]map[string]string)) {
+	testWatch := func(t *testing.T, ctx context.Context, cmd *cobra.Command, identity *configFile) (*config.Config, *test.Hook, func([]map[string]any)) {
 		tdir := t.TempDir()
 		assert.NoError(t,
 			os.MkdirAll(tdir,
@@ -986,7 +1299,7 @@ func TestIdentitySchemaValidation(t *testing.T) {
 		// clean the hooks since it will throw an event on first boot
 		hook.Reset()
 
-		return conf, hook, func(schemas []map[string]string) {
+		return conf, hook, func(schemas []map[string]any) {
 			identity.Identity.Schemas = schemas
 			marshalAndWrite(t, ctx, tmpConfig, identity)
 		}
@@ -1070,6 +1383,44 @@ func TestIdentitySchemaValidation(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("case=reload callbacks are called for valid schema changes", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+		t.Cleanup(cancel)
+
+		identity := setup(t, files[0])
+		other := setup(t, files[1])
+		// Optional settings must be compared by value, otherwise every reload looks like a change.
+		other.Identity.Schemas[0]["selfservice_registration_allowed"] = false
+
+		conf, _, writeSchema := testWatch(t, ctx, &cobra.Command{}, identity)
+
+		reloaded := make(chan []config.Schema, 10)
+		conf.OnIdentitySchemaReload(func(_ context.Context, schemas []config.Schema) {
+			reloaded <- schemas
+		})
+
+		invalidIdentity := setup(t, "stub/.identity.invalid.json")
+		writeSchema(invalidIdentity.Identity.Schemas)
+		writeSchema(other.Identity.Schemas)
+
+		select {
+		case <-ctx.Done():
+			t.Fatal("the test could not complete as the context timed out before the file watcher updated")
+		case schemas := <-reloaded:
+			require.Len(t, schemas, 1)
+			assert.Equal(t, other.Identity.Schemas[0]["url"], schemas[0].URL)
+			assert.False(t, schemas[0].RegistrationAllowed())
+		}
+
+		// Writing the same schemas again must not call the callbacks.
+		writeSchema(other.Identity.Schemas)
+		select {
+		case schemas := <-reloaded:
+			t.Fatalf("expected no reload for unchanged schemas but got: %+v", schemas)
+		case <-time.After(time.Second):
+		}
+	})
 }
 
 func TestPasswordless(t *testing.T) {
@@ -1103,6 +1454,127 @@ func TestPasswordlessCode(t *testing.T) {
 	assert.True(t, conf.SelfServiceCodeStrategy(ctx).PasswordlessEnabled)
 }
 
+func TestSelfServiceBeforeHooksPriority(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	hooks := []map[string]interface{}{
+		{"hook": "web_hook", "config": map[string]interface{}{"url": "https://example.com/first", "method": "POST"}},
+		{"hook": "web_hook", "config": map[string]interface{}{"url": "https://example.com/second", "method": "POST"}},
+		{"hook": "web_hook", "config": map[string]interface{}{"url": "https://example.com/captcha", "method": "POST"}},
+	}
+
+	urls := func(hooks []config.SelfServiceHook) (urls []string) {
+		for _, h := range hooks {
+			urls = append(urls, gjson.GetBytes(h.Config, "url").String())
+		}
+		return urls
+	}
+
+	t.Run("case=keeps configuration order without priorities", func(t *testing.T) {
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.yaml"),
+			configx.WithValue(config.ViperKeySelfServiceLoginBeforeHooks, hooks))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"https://example.com/first", "https://example.com/second", "https://example.com/captcha"}, urls(conf.SelfServiceFlowLoginBeforeHooks(ctx)))
+	})
+
+	t.Run("case=priority overrides configuration order", func(t *testing.T) {
+		prioritized := append([]map[string]interface{}{}, hooks...)
+		prioritized[2] = map[string]interface{}{"hook": "web_hook", "priority": 100, "config": map[string]interface{}{"url": "https://example.com/captcha", "method": "POST"}}
+
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.yaml"),
+			configx.WithValue(config.ViperKeySelfServiceLoginBeforeHooks, prioritized),
+			configx.WithValue(config.ViperKeySelfServiceRegistrationBeforeHooks, prioritized),
+			configx.WithValue(config.ViperKeySelfServiceRegistrationEnableLegacyOneStep, true))
+		require.NoError(t, err)
+
+		expected := []string{"https://example.com/captcha", "https://example.com/first", "https://example.com/second"}
+		assert.Equal(t, expected, urls(conf.SelfServiceFlowLoginBeforeHooks(ctx)))
+		assert.Equal(t, expected, urls(conf.SelfServiceFlowRegistrationBeforeHooks(ctx)))
+	})
+
+	t.Run("case=priority is rejected for after hooks", func(t *testing.T) {
+		_, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.yaml"),
+			configx.WithValue(config.HookStrategyKey(config.ViperKeySelfServiceLoginAfter, config.HookGlobal), []map[string]interface{}{
+				{"hook": "web_hook", "priority": 100, "config": map[string]interface{}{"url": "https://example.com/after", "method": "POST"}},
+			}))
+		require.Error(t, err)
+	})
+}
+
+func TestSelfServiceFlowLoginMaxSubmissions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("case=unlimited by default", func(t *testing.T) {
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation())
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, conf.SelfServiceFlowLoginMaxSubmissions(ctx, config.HookGlobal))
+		assert.Equal(t, 0, conf.SelfServiceFlowLoginMaxSubmissions(ctx, "password"))
+	})
+
+	t.Run("case=strategy falls back to the global value", func(t *testing.T) {
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.SkipValidation(),
+			configx.WithValue(config.ViperKeySelfServiceLoginMaxSubmissions, 10),
+			configx.WithValue("selfservice.flows.login.password.max_submissions", 3))
+		require.NoError(t, err)
+
+		assert.Equal(t, 10, conf.SelfServiceFlowLoginMaxSubmissions(ctx, config.HookGlobal))
+		assert.Equal(t, 3, conf.SelfServiceFlowLoginMaxSubmissions(ctx, "password"))
+		assert.Equal(t, 10, conf.SelfServiceFlowLoginMaxSubmissions(ctx, "code"))
+	})
+}
+
+func TestSelfServiceLoginFlowStyle(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("case=one step by default", func(t *testing.T) {
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation())
+		require.NoError(t, err)
+		assert.Equal(t, config.LoginFlowStyleOneStep, conf.SelfServiceLoginFlowStyle(ctx))
+	})
+
+	t.Run("case=loads passwordless_or_password from a config file", func(t *testing.T) {
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.login-style.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, config.LoginFlowStylePasswordlessOrPassword, conf.SelfServiceLoginFlowStyle(ctx))
+	})
+
+	t.Run("case=rejects unknown styles", func(t *testing.T) {
+		_, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.login-style.yaml"),
+			configx.WithValue(config.ViperKeySelfServiceLoginFlowStyle, "unified"))
+		require.Error(t, err)
+	})
+}
+
+func TestTOMLConfigFiles(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	fromYAML := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+		configx.WithConfigFiles("stub/.kratos.yaml"))
+	fromTOML := config.MustNew(t, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+		configx.WithConfigFiles("stub/.kratos.toml"))
+
+	assert.Equal(t, fromYAML.DSN(ctx), fromTOML.DSN(ctx))
+	assert.Equal(t, fromYAML.SelfServiceFlowLoginUI(ctx), fromTOML.SelfServiceFlowLoginUI(ctx))
+	assert.Equal(t, fromYAML.SelfServiceFlowLoginAfterHooks(ctx, "password"), fromTOML.SelfServiceFlowLoginAfterHooks(ctx, "password"))
+	for _, strategy := range []string{"password", "oidc", "totp"} {
+		t.Run("strategy="+strategy, func(t *testing.T) {
+			assert.Equal(t, fromYAML.SelfServiceStrategy(ctx, strategy), fromTOML.SelfServiceStrategy(ctx, strategy))
+		})
+	}
+}
+
 func TestChangeMinPasswordLength(t *testing.T) {
 	t.Parallel()
 	t.Run("case=must fail on minimum password length below enforced minimum", func(t *testing.T) {
@@ -1126,6 +1598,279 @@ func TestChangeMinPasswordLength(t *testing.T) {
 	})
 }
 
+func TestOIDCMaxProviders(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("case=must fail if more providers than allowed are configured", func(t *testing.T) {
+		_, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.yaml"),
+			configx.WithValue(config.ViperKeyOIDCMaxProviders, 0))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "contains 1 providers, but at most 0 are allowed")
+	})
+
+	t.Run("case=must not fail if providers are within the limit", func(t *testing.T) {
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.yaml"),
+			configx.WithValue(config.ViperKeyOIDCMaxProviders, 1))
+		require.NoError(t, err)
+		assert.Equal(t, 1, conf.OIDCMaxProviders(ctx))
+	})
+
+	t.Run("case=uses a generous default", func(t *testing.T) {
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation())
+		require.NoError(t, err)
+		assert.Equal(t, 250, conf.OIDCMaxProviders(ctx))
+	})
+}
+
+func TestIPRanges(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("case=must fail on invalid trusted proxies", func(t *testing.T) {
+		_, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.yaml"),
+			configx.WithValue(config.ViperKeyPublicTrustedProxies, []string{"10.0.0.0/99"}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), config.ViperKeyPublicTrustedProxies)
+	})
+
+	t.Run("case=must fail on invalid ip_deny ranges", func(t *testing.T) {
+		_, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.yaml"),
+			configx.WithValue(config.ViperKeySelfServiceRegistrationBeforeHooks, []map[string]interface{}{
+				{"hook": "ip_deny", "config": map[string]interface{}{"ranges": []string{"192.0.2.0/24", "192.0.2.0/99"}}},
+			}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ip_deny")
+	})
+
+	t.Run("case=parses trusted proxies", func(t *testing.T) {
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.yaml"),
+			configx.WithValue(config.ViperKeyPublicTrustedProxies, []string{"10.0.0.0/8", "fd00::/8"}))
+		require.NoError(t, err)
+		proxies := conf.PublicTrustedProxies(ctx)
+		require.Len(t, proxies, 2)
+		assert.Equal(t, "10.0.0.0/8", proxies[0].String())
+		assert.Equal(t, "fd00::/8", proxies[1].String())
+	})
+}
+
+func TestIdentitySchemaExtends(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	toURL := func(schema string) string {
+		return "base64://" + base64.StdEncoding.EncodeToString([]byte(schema))
+	}
+
+	base := toURL(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "traits": {
+      "type": "object",
+      "properties": {
+        "email": {"type": "string", "format": "email"}
+      },
+      "required": ["email"]
+    }
+  }
+}`)
+	employee := toURL(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "traits": {
+      "type": "object",
+      "properties": {
+        "department": {"type": "string"}
+      },
+      "required": ["department"],
+      "additionalProperties": false
+    }
+  }
+}`)
+	conflicting := toURL(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "traits": {
+      "type": "object",
+      "properties": {
+        "email": {"type": "integer"}
+      }
+    }
+  }
+}`)
+
+	newConfig := func(schemas ...config.Schema) (*config.Config, error) {
+		return config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithValues(map[string]interface{}{
+				config.ViperKeyDSN:             config.DefaultSQLiteMemoryDSN,
+				config.ViperKeyIdentitySchemas: schemas,
+			}), configx.SkipValidation())
+	}
+
+	t.Run("case=extending schema validates against merged traits", func(t *testing.T) {
+		conf, err := newConfig(
+			config.Schema{ID: "default", URL: base},
+			config.Schema{ID: "employee", URL: employee, Extends: "default"})
+		require.NoError(t, err)
+
+		ss, err := conf.IdentityTraitsSchemas(ctx)
+		require.NoError(t, err)
+		s, err := ss.FindSchemaByID("employee")
+		require.NoError(t, err)
+		assert.Equal(t, "default", s.Extends)
+
+		v := schema.NewValidator()
+		require.NoError(t, v.Validate(ctx, s.URL, json.RawMessage(`{"traits":{"email":"foo@ory.sh","department":"engineering"}}`)))
+		require.Error(t, v.Validate(ctx, s.URL, json.RawMessage(`{"traits":{"department":"engineering"}}`)), "the inherited trait is required")
+		require.Error(t, v.Validate(ctx, s.URL, json.RawMessage(`{"traits":{"email":"not-an-email","department":"engineering"}}`)), "the inherited trait is validated")
+		require.Error(t, v.Validate(ctx, s.URL, json.RawMessage(`{"traits":{"email":"foo@ory.sh"}}`)), "the own trait is required")
+
+		keys, err := schema.GetKeysInOrder(ctx, s.URL)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"traits.email", "traits.department"}, keys, "inherited traits come first")
+	})
+
+	t.Run("case=carries over the definitions of the base schema", func(t *testing.T) {
+		withDefinitions := toURL(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "definitions": {
+    "email": {"type": "string", "format": "email"},
+    "name": {"type": "string", "minLength": 2}
+  },
+  "properties": {
+    "traits": {
+      "type": "object",
+      "properties": {
+        "email": {"$ref": "#/definitions/email"},
+        "nickname": {"$ref": "#/definitions/name"}
+      },
+      "required": ["email"]
+    }
+  }
+}`)
+		extending := toURL(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "definitions": {
+    "name": {"type": "string", "enum": ["engineering", "sales"]}
+  },
+  "properties": {
+    "traits": {
+      "type": "object",
+      "properties": {
+        "department": {"$ref": "#/definitions/name"}
+      }
+    }
+  }
+}`)
+
+		conf, err := newConfig(
+			config.Schema{ID: "default", URL: withDefinitions},
+			config.Schema{ID: "employee", URL: extending, Extends: "default"})
+		require.NoError(t, err)
+
+		ss, err := conf.IdentityTraitsSchemas(ctx)
+		require.NoError(t, err)
+		s, err := ss.FindSchemaByID("employee")
+		require.NoError(t, err)
+
+		v := schema.NewValidator()
+		require.NoError(t, v.Validate(ctx, s.URL, json.RawMessage(`{"traits":{"email":"foo@ory.sh","nickname":"foo","department":"sales"}}`)))
+		require.Error(t, v.Validate(ctx, s.URL, json.RawMessage(`{"traits":{"email":"not-an-email"}}`)), "the inherited definition is used")
+		require.Error(t, v.Validate(ctx, s.URL, json.RawMessage(`{"traits":{"email":"foo@ory.sh","nickname":"f"}}`)), "the renamed inherited definition is used")
+		require.Error(t, v.Validate(ctx, s.URL, json.RawMessage(`{"traits":{"email":"foo@ory.sh","department":"marketing"}}`)), "the own definition is used")
+		require.NoError(t, v.Validate(ctx, s.URL, json.RawMessage(`{"traits":{"email":"foo@ory.sh","nickname":"marketing"}}`)), "the own definition does not replace the inherited one")
+	})
+
+	t.Run("case=resolves relative references of the base schema", func(t *testing.T) {
+		conf, err := newConfig(
+			config.Schema{ID: "default", URL: "file://./stub/.identity.extends.json"},
+			config.Schema{ID: "employee", URL: employee, Extends: "default"})
+		require.NoError(t, err)
+
+		ss, err := conf.IdentityTraitsSchemas(ctx)
+		require.NoError(t, err)
+		s, err := ss.FindSchemaByID("employee")
+		require.NoError(t, err)
+
+		v := schema.NewValidator()
+		require.NoError(t, v.Validate(ctx, s.URL, json.RawMessage(`{"traits":{"email":"foo@ory.sh","department":"engineering"}}`)))
+		require.Error(t, v.Validate(ctx, s.URL, json.RawMessage(`{"traits":{"email":"not-an-email","department":"engineering"}}`)))
+	})
+
+	t.Run("case=fails on conflicting traits", func(t *testing.T) {
+		conf, err := newConfig(
+			config.Schema{ID: "default", URL: base},
+			config.Schema{ID: "conflicting", URL: conflicting, Extends: "default"})
+		require.NoError(t, err)
+
+		_, err = conf.IdentityTraitsSchemas(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `identity schema "conflicting" defines trait "email" which is already defined by the identity schema "default" it extends`)
+	})
+
+	t.Run("case=fails on unknown base schema", func(t *testing.T) {
+		conf, err := newConfig(config.Schema{ID: "employee", URL: employee, Extends: "unknown"})
+		require.NoError(t, err)
+
+		_, err = conf.IdentityTraitsSchemas(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `identity schema "employee" extends identity schema "unknown" which does not exist`)
+	})
+
+	t.Run("case=fails on cycles", func(t *testing.T) {
+		conf, err := newConfig(
+			config.Schema{ID: "a", URL: employee, Extends: "b"},
+			config.Schema{ID: "b", URL: base, Extends: "a"})
+		require.NoError(t, err)
+
+		_, err = conf.IdentityTraitsSchemas(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle")
+	})
+
+	t.Run("case=extended schemas are only fetched once", func(t *testing.T) {
+		var fetched int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&fetched, 1)
+			raw, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(base, "base64://"))
+			_, _ = w.Write(raw)
+		}))
+		t.Cleanup(ts.Close)
+
+		conf, err := newConfig(
+			config.Schema{ID: "default", URL: ts.URL},
+			config.Schema{ID: "employee", URL: employee, Extends: "default"})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			ss, err := conf.IdentityTraitsSchemas(ctx)
+			require.NoError(t, err)
+			require.Len(t, ss, 2)
+		}
+		assert.EqualValues(t, 1, atomic.LoadInt32(&fetched))
+
+		conf.MustSet(ctx, config.ViperKeyIdentitySchemas, []config.Schema{
+			{ID: "default", URL: ts.URL},
+			{ID: "manager", URL: employee, Extends: "default"},
+		})
+		ss, err := conf.IdentityTraitsSchemas(ctx)
+		require.NoError(t, err)
+		_, err = ss.FindSchemaByID("manager")
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, atomic.LoadInt32(&fetched), "changed schemas are resolved again")
+	})
+}
+
 func TestCourierEmailHTTP(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -1144,6 +1889,25 @@ func TestCourierEmailHTTP(t *testing.T) {
 	})
 }
 
+func TestCourierSMS(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("case=configs set", func(t *testing.T) {
+		conf, _ := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.courier.sms.yaml"), configx.SkipValidation())
+		assert.Equal(t, "http", conf.CourierSMSStrategy(ctx))
+		snapshotx.SnapshotT(t, conf.CourierSMSRequestConfig(ctx))
+	})
+
+	t.Run("case=defaults", func(t *testing.T) {
+		conf, _ := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation())
+
+		assert.Equal(t, "http", conf.CourierSMSStrategy(ctx))
+		snapshotx.SnapshotT(t, conf.CourierSMSRequestConfig(ctx))
+	})
+}
+
 func TestCourierChannels(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -1188,6 +1952,46 @@ func TestCourierChannels(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("invalid smtp url schemes", func(t *testing.T) {
+		for _, tc := range []string{
+			"smpt://a:b@email-smtp.eu-west-3.amazonaws.com:587/",
+			"http://a:b@email-smtp.eu-west-3.amazonaws.com:587/",
+			"smtp+starttls://a:b@email-smtp.eu-west-3.amazonaws.com:587/",
+			"email-smtp.eu-west-3.amazonaws.com:587",
+		} {
+			t.Run("case="+tc, func(t *testing.T) {
+				_, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+					configx.WithConfigFiles("stub/.kratos.yaml"),
+					configx.WithValue(config.ViperKeyCourierSMTPURL, tc))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "#/courier/smtp/connection_uri")
+
+				conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+					configx.WithValue(config.ViperKeyCourierSMTPURL, tc), configx.SkipValidation())
+				require.NoError(t, err)
+				_, err = conf.CourierChannels(ctx)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), `courier channel "email"`)
+			})
+		}
+	})
+
+	t.Run("valid smtp url schemes", func(t *testing.T) {
+		for _, tc := range []string{
+			"smtp://a:b@email-smtp.eu-west-3.amazonaws.com:587/",
+			"smtps://a:b@email-smtp.eu-west-3.amazonaws.com:465/",
+		} {
+			t.Run("case="+tc, func(t *testing.T) {
+				conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+					configx.WithConfigFiles("stub/.kratos.yaml"),
+					configx.WithValue(config.ViperKeyCourierSMTPURL, tc))
+				require.NoError(t, err)
+				_, err = conf.CourierChannels(ctx)
+				require.NoError(t, err)
+			})
+		}
+	})
 }
 
 func TestCourierMessageTTL(t *testing.T) {
@@ -1206,6 +2010,19 @@ func TestCourierMessageTTL(t *testing.T) {
 	})
 }
 
+func TestCORS(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+		configx.WithValues(map[string]any{"serve.public.cors.enabled": true}), configx.SkipValidation())
+	require.NoError(t, err)
+
+	opts, enabled := conf.CORS(ctx, "public")
+	assert.True(t, enabled)
+	assert.Contains(t, opts.ExposedHeaders, "X-CSRF-Token")
+}
+
 func TestOAuth2Provider(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -1254,6 +2071,88 @@ func TestWebauthn(t *testing.T) {
 		}, webAuthnConfig.RPOrigins)
 	})
 
+	t.Run("case=display name", func(t *testing.T) {
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.webauthn.origin.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, "Webauthn", conf.WebAuthnConfig(ctx).RPDisplayName)
+
+		conf, err = config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithValue(config.ViperKeyWebAuthnRPID, "example.com"),
+			configx.SkipValidation())
+		require.NoError(t, err)
+		assert.Equal(t, "example.com", conf.WebAuthnConfig(ctx).RPDisplayName, "falls back to the RP ID")
+	})
+
+	t.Run("case=authenticator selection", func(t *testing.T) {
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.webauthn.origin.yaml"))
+		require.NoError(t, err)
+		selection := conf.WebAuthnConfig(ctx).AuthenticatorSelection
+		assert.EqualValues(t, "", selection.AuthenticatorAttachment)
+		assert.Equal(t, protocol.VerificationDiscouraged, selection.UserVerification)
+
+		conf, err = config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.webauthn.origin.yaml"),
+			configx.WithValues(map[string]any{
+				config.ViperKeyWebAuthnAuthenticatorAttachment: "cross-platform",
+				config.ViperKeyWebAuthnUserVerification:        "required",
+			}))
+		require.NoError(t, err)
+		selection = conf.WebAuthnConfig(ctx).AuthenticatorSelection
+		assert.Equal(t, protocol.CrossPlatform, selection.AuthenticatorAttachment)
+		assert.Equal(t, protocol.VerificationRequired, selection.UserVerification)
+	})
+
+	t.Run("case=tenants", func(t *testing.T) {
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.webauthn.origin.yaml"),
+			configx.WithValue(config.ViperKeyWebAuthnTenants, []map[string]any{
+				{"host": "auth.tenant-a.com", "id": "tenant-a.com", "display_name": "Tenant A", "origins": []string{"https://auth.tenant-a.com"}},
+				{"host": "auth.tenant-b.com:4433", "id": "tenant-b.com"},
+			}))
+		require.NoError(t, err)
+
+		a := conf.WebAuthnConfig(config.WithWebAuthnRequestHost(ctx, "auth.tenant-a.com"))
+		assert.Equal(t, "tenant-a.com", a.RPID)
+		assert.Equal(t, "Tenant A", a.RPDisplayName)
+		assert.EqualValues(t, []string{"https://auth.tenant-a.com"}, a.RPOrigins)
+
+		b := conf.WebAuthnConfig(config.WithWebAuthnRequestHost(ctx, "auth.tenant-b.com:4433"))
+		assert.Equal(t, "tenant-b.com", b.RPID)
+		assert.Equal(t, "tenant-b.com", b.RPDisplayName)
+		assert.EqualValues(t, []string{"http://tenant-b.com"}, b.RPOrigins)
+
+		for _, c := range []context.Context{ctx, config.WithWebAuthnRequestHost(ctx, "unknown.com")} {
+			fallback := conf.WebAuthnConfig(c)
+			assert.Equal(t, "https://example.com/webauthn", fallback.RPID)
+			assert.EqualValues(t, []string{"https://origin-a.example.com"}, fallback.RPOrigins)
+		}
+	})
+
+	t.Run("case=passkey tenants", func(t *testing.T) {
+		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.yaml"),
+			configx.WithValue(config.ViperKeyPasskeyRPID, "example.com"),
+			configx.WithValue(config.ViperKeyPasskeyRPDisplayName, "Example"),
+			configx.WithValue(config.ViperKeyPasskeyTenants, []map[string]any{
+				{"host": "auth.tenant-a.com", "id": "tenant-a.com", "origins": []string{"https://auth.tenant-a.com"}},
+			}))
+		require.NoError(t, err)
+
+		a := conf.PasskeyConfig(config.WithWebAuthnRequestHost(ctx, "auth.tenant-a.com"))
+		assert.Equal(t, "tenant-a.com", a.RPID)
+		assert.Equal(t, "tenant-a.com", a.RPDisplayName)
+		assert.EqualValues(t, []string{"https://auth.tenant-a.com"}, a.RPOrigins)
+
+		fallback := conf.PasskeyConfig(config.WithWebAuthnRequestHost(ctx, "unknown.com"))
+		assert.Equal(t, "example.com", fallback.RPID)
+		assert.Equal(t, "Example", fallback.RPDisplayName)
+
+		assert.Equal(t, "example.com", conf.WebAuthnConfig(config.WithWebAuthnRequestHost(ctx, "auth.tenant-a.com")).RPID,
+			"passkey tenants do not apply to the webauthn strategy")
+	})
+
 	t.Run("case=id as origin", func(t *testing.T) {
 		conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
 			configx.WithConfigFiles("stub/.kratos.yaml"))
@@ -0,0 +1,133 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/embedx"
+)
+
+// secretKeys are configuration keys, or the trailing elements of configuration
+// keys, whose values must not be exposed. The `auth.config` keys are the
+// credentials of HTTP request configs such as web hooks and courier channels.
+var secretKeys = []string{
+	"dsn",
+	"courier.smtp.connection_uri",
+	"secrets.default",
+	"secrets.cookie",
+	"secrets.cipher",
+	"client_secret",
+	"auth.config.password",
+	"auth.config.value",
+	"tls.key.base64",
+	"oauth2_provider.headers",
+}
+
+// IsSecretKey returns true if the value of the dot-separated configuration key
+// must not be exposed. Keys within arrays, such as web hook configurations,
+// omit the array index, e.g. "selfservice.flows.login.after.hooks.config.auth.config.password".
+func IsSecretKey(key string) bool {
+	for _, s := range secretKeys {
+		if key == s || strings.HasSuffix(key, "."+s) {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyInfo describes a configuration key.
+type KeyInfo struct {
+	// Key is the dot-separated path of the key, for example "session.lifespan".
+	Key string `json:"key"`
+
+	// Type is the JSON Schema type of the key. Keys which accept several types
+	// list them separated by a comma.
+	Type string `json:"type"`
+
+	// Default is the default value of the key, or nil if it has none.
+	Default any `json:"default,omitempty"`
+
+	// Secret is true if the value of the key must not be exposed.
+	Secret bool `json:"secret"`
+}
+
+// AllKeys lists all configuration keys defined by the configuration schema,
+// sorted by key. Objects with fixed properties are expanded into their
+// properties while arrays and free-form objects are listed as a single key.
+func AllKeys() []KeyInfo {
+	schema := gjson.Parse(embedx.ConfigSchema)
+
+	keys := map[string]KeyInfo{}
+	collectKeys(schema, schema, nil, nil, keys)
+
+	result := make([]KeyInfo, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, k)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+func collectKeys(root, node gjson.Result, path []string, refs []string, keys map[string]KeyInfo) {
+	if ref := node.Get("$ref").String(); strings.HasPrefix(ref, "#/") {
+		// Guard against recursive definitions.
+		if slices.Contains(refs, ref) {
+			return
+		}
+		target := root.Get(strings.ReplaceAll(strings.TrimPrefix(ref, "#/"), "/", "."))
+		collectKeys(root, target, path, append(slices.Clone(refs), ref), keys)
+		return
+	}
+
+	var expanded bool
+	node.Get("properties").ForEach(func(name, property gjson.Result) bool {
+		expanded = true
+		collectKeys(root, property, append(slices.Clone(path), name.String()), refs, keys)
+		return true
+	})
+	for _, combinator := range []string{"allOf", "anyOf", "oneOf"} {
+		node.Get(combinator).ForEach(func(_, branch gjson.Result) bool {
+			if branch.Get("properties").Exists() || branch.Get("$ref").Exists() {
+				expanded = true
+				collectKeys(root, branch, path, refs, keys)
+			}
+			return true
+		})
+	}
+
+	if expanded || len(path) == 0 {
+		return
+	}
+
+	key := strings.Join(path, ".")
+	if _, ok := keys[key]; ok {
+		return
+	}
+
+	var types []string
+	if t := node.Get("type"); t.IsArray() {
+		for _, tt := range t.Array() {
+			types = append(types, tt.String())
+		}
+	} else if t.Exists() {
+		types = append(types, t.String())
+	}
+
+	info := KeyInfo{
+		Key:    key,
+		Type:   strings.Join(types, ","),
+		Secret: IsSecretKey(key),
+	}
+	if d := node.Get("default"); d.Exists() {
+		info.Default = d.Value()
+	}
+	keys[key] = info
+}
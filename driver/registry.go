@@ -114,6 +114,7 @@ type Registry interface {
 	session.ManagementProvider
 	session.PersistenceProvider
 	session.TokenizerProvider
+	session.IssuancePolicyProvider
 
 	settings.HandlerProvider
 	settings.ErrorHandlerProvider
@@ -104,9 +104,10 @@ type RegistryDefault struct {
 
 	schemaHandler *schema.Handler
 
-	sessionHandler   *session.Handler
-	sessionManager   session.Manager
-	sessionTokenizer *session.Tokenizer
+	sessionHandler        *session.Handler
+	sessionManager        session.Manager
+	sessionTokenizer      *session.Tokenizer
+	sessionIssuancePolicy session.IssuancePolicy
 
 	passwordHasher    hash.Hasher
 	passwordValidator password.Validator
@@ -523,7 +524,7 @@ func (m *RegistryDefault) CookieManager(ctx context.Context) sessions.StoreExact
 	}
 
 	cs := sessions.NewCookieStore(keys...)
-	cs.Options.Secure = !m.Config().IsInsecureDevMode(ctx)
+	cs.Options.Secure = !m.Config().IsInsecureDevMode(ctx) || m.Config().SessionUseHostPrefix(ctx)
 	cs.Options.HttpOnly = true
 
 	if domain := m.Config().SessionDomain(ctx); domain != "" {
@@ -573,6 +574,13 @@ func (m *RegistryDefault) SessionManager() session.Manager {
 	return m.sessionManager
 }
 
+func (m *RegistryDefault) SessionIssuancePolicy() session.IssuancePolicy {
+	if m.sessionIssuancePolicy == nil {
+		m.sessionIssuancePolicy = session.NewHTTPIssuancePolicy(m)
+	}
+	return m.sessionIssuancePolicy
+}
+
 func (m *RegistryDefault) Hydra() hydra.Hydra {
 	if m.hydra == nil {
 		m.hydra = hydra.NewDefaultHydra(m)
@@ -71,7 +71,7 @@ func (m *RegistryDefault) getHooks(credentialsType string, configs []config.Self
 		case hook.KeySessionDestroyer:
 			i = append(i, m.HookSessionDestroyer())
 		case hook.KeyWebHook:
-			i = append(i, hook.NewWebHook(m, h.Config))
+			i = append(i, hook.NewWebHook(m, h.Config).WithAllowedHeaders(h.AllowedHeaders))
 		case hook.KeyAddressVerifier:
 			i = append(i, m.HookAddressVerifier())
 		case hook.KeyVerificationUI:
@@ -80,6 +80,8 @@ func (m *RegistryDefault) getHooks(credentialsType string, configs []config.Self
 			i = append(i, m.HookTwoStepRegistration())
 		case hook.KeyVerifier:
 			i = append(i, m.HookVerifier())
+		case hook.KeyIPDeny:
+			i = append(i, hook.NewIPDeny(m, h.Config))
 		default:
 			var found bool
 			for name, m := range m.injectedSelfserviceHooks {
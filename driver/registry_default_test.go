@@ -770,6 +770,42 @@ func TestDriverDefault_Strategies(t *testing.T) {
 		}
 	})
 
+	t.Run("case=login methods by aal", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := confighelpers.WithConfigValues(ctx, map[string]any{
+			config.ViperKeySelfServiceStrategyConfig + ".password.enabled": false,
+			config.ViperKeySelfServiceStrategyConfig + ".code.enabled":     false,
+			config.ViperKeySelfServiceStrategyConfig + ".totp.enabled":     true,
+			config.ViperKeySelfServiceStrategyConfig + ".webauthn.enabled": true,
+			config.ViperKeyWebAuthnPasswordless:                            true,
+		})
+		s := reg.LoginStrategies(ctx)
+		assert.Equal(t, []identity.CredentialsType{identity.CredentialsTypeWebAuthn}, s.EnabledFor(ctx, identity.AuthenticatorAssuranceLevel1))
+		assert.Equal(t, []identity.CredentialsType{identity.CredentialsTypeTOTP}, s.EnabledFor(ctx, identity.AuthenticatorAssuranceLevel2))
+
+		ctx = confighelpers.WithConfigValue(ctx, config.ViperKeySelfServiceStrategyConfig+".password.enabled", true)
+		assert.Equal(t, []identity.CredentialsType{identity.CredentialsTypePassword, identity.CredentialsTypeWebAuthn}, reg.LoginStrategies(ctx).EnabledFor(ctx, identity.AuthenticatorAssuranceLevel1))
+
+		t.Run("method=code", func(t *testing.T) {
+			ctx := confighelpers.WithConfigValues(ctx, map[string]any{
+				config.ViperKeySelfServiceStrategyConfig + ".code.enabled":              true,
+				config.ViperKeySelfServiceStrategyConfig + ".code.passwordless_enabled": true,
+			})
+			s := reg.LoginStrategies(ctx)
+			assert.Contains(t, s.EnabledFor(ctx, identity.AuthenticatorAssuranceLevel1), identity.CredentialsTypeCodeAuth)
+			assert.NotContains(t, s.EnabledFor(ctx, identity.AuthenticatorAssuranceLevel2), identity.CredentialsTypeCodeAuth)
+
+			ctx = confighelpers.WithConfigValues(ctx, map[string]any{
+				config.ViperKeySelfServiceStrategyConfig + ".code.passwordless_enabled": false,
+				config.ViperKeySelfServiceStrategyConfig + ".code.mfa_enabled":          true,
+			})
+			s = reg.LoginStrategies(ctx)
+			assert.NotContains(t, s.EnabledFor(ctx, identity.AuthenticatorAssuranceLevel1), identity.CredentialsTypeCodeAuth)
+			assert.Contains(t, s.EnabledFor(ctx, identity.AuthenticatorAssuranceLevel2), identity.CredentialsTypeCodeAuth)
+		})
+	})
+
 	t.Run("case=recovery", func(t *testing.T) {
 		t.Parallel()
 		for k, tc := range []struct {
@@ -0,0 +1,75 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package identity
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/sqlxx"
+)
+
+// exportConfig returns the parts of the credential's configuration which may be
+// disclosed to the identity, for example in a data export. Secrets such as
+// password hashes, TOTP secrets, lookup codes, and tokens are removed. Unknown
+// credential types have no exportable configuration.
+func (c Credentials) exportConfig() (sqlxx.JSONRawMessage, error) {
+	if len(c.Config) == 0 {
+		return nil, nil
+	}
+
+	var exported any
+	switch c.Type {
+	case CredentialsTypeWebAuthn, CredentialsTypePasskey:
+		// Credential IDs, public keys, and authenticator data are not secret.
+		return c.Config, nil
+	case CredentialsTypeCodeAuth:
+		// The configuration only holds the address type and when the code was used.
+		return c.Config, nil
+	case CredentialsTypeOIDC:
+		var config CredentialsOIDC
+		if err := json.Unmarshal(c.Config, &config); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		type provider struct {
+			Subject      string `json:"subject"`
+			Provider     string `json:"provider"`
+			Organization string `json:"organization,omitempty"`
+		}
+		providers := make([]provider, len(config.Providers))
+		for k, p := range config.Providers {
+			providers[k] = provider{Subject: p.Subject, Provider: p.Provider, Organization: p.Organization}
+		}
+		exported = struct {
+			Providers []provider `json:"providers"`
+		}{Providers: providers}
+	case CredentialsTypeLookup:
+		var config CredentialsLookupConfig
+		if err := json.Unmarshal(c.Config, &config); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		type code struct {
+			UsedAt sqlxx.NullTime `json:"used_at,omitempty"`
+		}
+		codes := make([]code, len(config.RecoveryCodes))
+		for k, rc := range config.RecoveryCodes {
+			codes[k] = code{UsedAt: rc.UsedAt}
+		}
+		exported = struct {
+			RecoveryCodes []code `json:"recovery_codes"`
+		}{RecoveryCodes: codes}
+	default:
+		// Password and TOTP configurations consist of secrets only.
+		return nil, nil
+	}
+
+	config, err := json.Marshal(exported)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return config, nil
+}
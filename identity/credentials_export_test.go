@@ -0,0 +1,64 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package identity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/x/sqlxx"
+)
+
+func TestCredentialsExportConfig(t *testing.T) {
+	for _, tc := range []struct {
+		ct       CredentialsType
+		config   string
+		expected string
+	}{
+		{
+			ct:     CredentialsTypePassword,
+			config: `{"hashed_password":"$2a$08$.cOYmAd.vCpDOoiVJrO5B.hjTLKQQ6cAK40u8uB.FnZDyPvVvQ9Q."}`,
+		},
+		{
+			ct:     CredentialsTypeTOTP,
+			config: `{"totp_url":"otpauth://totp/issuer:foo?secret=JBSWY3DPEHPK3PXP"}`,
+		},
+		{
+			ct:       CredentialsTypeOIDC,
+			config:   `{"providers":[{"subject":"foo","provider":"github","initial_id_token":"id","initial_access_token":"access","initial_refresh_token":"refresh","organization":"org"}]}`,
+			expected: `{"providers":[{"subject":"foo","provider":"github","organization":"org"}]}`,
+		},
+		{
+			ct:       CredentialsTypeLookup,
+			config:   `{"recovery_codes":[{"code":"secret","used_at":null},{"code":"used","used_at":"2024-01-01T00:00:00Z"}]}`,
+			expected: `{"recovery_codes":[{"used_at":null},{"used_at":"2024-01-01T00:00:00Z"}]}`,
+		},
+		{
+			ct:       CredentialsTypeWebAuthn,
+			config:   `{"credentials":[{"id":"Zm9v","public_key":"YmFy","display_name":"key"}],"user_handle":"YmF6"}`,
+			expected: `{"credentials":[{"id":"Zm9v","public_key":"YmFy","display_name":"key"}],"user_handle":"YmF6"}`,
+		},
+		{
+			ct:       CredentialsTypeCodeAuth,
+			config:   `{"address_type":"email","used_at":null}`,
+			expected: `{"address_type":"email","used_at":null}`,
+		},
+		{
+			ct:     "unknown",
+			config: `{"secret":"foo"}`,
+		},
+	} {
+		t.Run("type="+tc.ct.String(), func(t *testing.T) {
+			actual, err := Credentials{Type: tc.ct, Config: sqlxx.JSONRawMessage(tc.config)}.exportConfig()
+			require.NoError(t, err)
+			if tc.expected == "" {
+				assert.Empty(t, actual)
+				return
+			}
+			assert.JSONEq(t, tc.expected, string(actual))
+		})
+	}
+}
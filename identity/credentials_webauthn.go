@@ -4,8 +4,11 @@
 package identity
 
 import (
+	"bytes"
+	"sort"
 	"time"
 
+	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/go-webauthn/webauthn/webauthn"
 
 	"github.com/ory/kratos/x/webauthnx/aaguid"
@@ -27,6 +30,9 @@ func CredentialFromWebAuthn(credential *webauthn.Credential, isPasswordless bool
 		IsPasswordless:  isPasswordless,
 		AttestationType: credential.AttestationType,
 		AddedAt:         time.Now().UTC().Round(time.Second),
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		Transports:      credential.Transport,
 		Authenticator: AuthenticatorWebAuthn{
 			AAGUID:       credential.Authenticator.AAGUID,
 			SignCount:    credential.Authenticator.SignCount,
@@ -72,11 +78,44 @@ func (c CredentialsWebAuthn) ToWebAuthnFiltered(aal AuthenticatorAssuranceLevel)
 	return result
 }
 
+// SortedByLastUse returns a copy of the credentials ordered by when they were
+// last used, most recent first. Credentials which were never used are ordered
+// by when they were added.
+func (c CredentialsWebAuthn) SortedByLastUse() CredentialsWebAuthn {
+	result := make(CredentialsWebAuthn, len(c))
+	copy(result, c)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].lastUsedAt().After(result[j].lastUsedAt())
+	})
+	return result
+}
+
+// MarkUsed records that the credential with the given ID was used at the given time.
+func (c CredentialsWebAuthn) MarkUsed(id []byte, at time.Time) {
+	for k := range c {
+		if bytes.Equal(c[k].ID, id) {
+			c[k].LastUsedAt = &at
+		}
+	}
+}
+
+func (c *CredentialWebAuthn) lastUsedAt() time.Time {
+	if c.LastUsedAt != nil {
+		return *c.LastUsedAt
+	}
+	return c.AddedAt
+}
+
 func (c *CredentialWebAuthn) ToWebAuthn() *webauthn.Credential {
 	return &webauthn.Credential{
 		ID:              c.ID,
 		PublicKey:       c.PublicKey,
 		AttestationType: c.AttestationType,
+		Transport:       c.Transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: c.BackupEligible,
+			BackupState:    c.BackupState,
+		},
 		Authenticator: webauthn.Authenticator{
 			AAGUID:       c.Authenticator.AAGUID,
 			SignCount:    c.Authenticator.SignCount,
@@ -93,6 +132,22 @@ type CredentialWebAuthn struct {
 	DisplayName     string                `json:"display_name"`
 	AddedAt         time.Time             `json:"added_at"`
 	IsPasswordless  bool                  `json:"is_passwordless"`
+
+	// BackupEligible is true if the authenticator reported that the credential
+	// can be backed up, for example to a cloud keychain.
+	BackupEligible bool `json:"backup_eligible"`
+
+	// BackupState is true if the authenticator reported that the credential
+	// is currently backed up.
+	BackupState bool `json:"backup_state"`
+
+	// Transports lists how the client communicated with the authenticator
+	// (e.g. usb, nfc, ble, internal) as reported during registration.
+	Transports []protocol.AuthenticatorTransport `json:"transports,omitempty"`
+
+	// LastUsedAt is the time of the last login with this credential. It is
+	// only recorded if `max_allow_credentials` is configured.
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
 }
 
 type AuthenticatorWebAuthn struct {
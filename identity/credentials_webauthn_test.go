@@ -4,10 +4,14 @@
 package identity
 
 import (
+	_ "embed"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
@@ -18,6 +22,11 @@ func TestCredentialConversion(t *testing.T) {
 		ID:              []byte("abcdef"),
 		PublicKey:       []byte("foobar"),
 		AttestationType: "test",
+		Transport:       []protocol.AuthenticatorTransport{protocol.USB, protocol.NFC},
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: true,
+			BackupState:    true,
+		},
 		Authenticator: webauthn.Authenticator{
 			AAGUID:       []byte("baz"),
 			SignCount:    1,
@@ -62,3 +71,47 @@ func TestPasswordlessOnly(t *testing.T) {
 	require.Len(t, actual, 2)
 	assert.Equal(t, []webauthn.Credential{*c.ToWebAuthn(), *e.ToWebAuthn()}, actual)
 }
+
+func TestSortedByLastUse(t *testing.T) {
+	now := time.Now().UTC().Round(time.Second)
+	a := CredentialWebAuthn{ID: []byte("a"), AddedAt: now.Add(-time.Hour)}
+	b := CredentialWebAuthn{ID: []byte("b"), AddedAt: now.Add(-3 * time.Hour)}
+	c := CredentialWebAuthn{ID: []byte("c"), AddedAt: now.Add(-2 * time.Hour)}
+	credentials := CredentialsWebAuthn{a, b, c}
+
+	credentials.MarkUsed([]byte("b"), now)
+	actual := credentials.SortedByLastUse()
+	require.Len(t, actual, 3)
+	assert.Equal(t, []string{"b", "a", "c"}, []string{string(actual[0].ID), string(actual[1].ID), string(actual[2].ID)})
+	assert.Equal(t, "a", string(credentials[0].ID), "does not modify the order of the credentials")
+	require.NotNil(t, credentials[1].LastUsedAt)
+	assert.Equal(t, now, *credentials[1].LastUsedAt)
+}
+
+//go:embed stub/webauthn/backup.json
+var webAuthnBackup []byte
+
+func TestCredentialBackupFlags(t *testing.T) {
+	var cc CredentialsWebAuthnConfig
+	require.NoError(t, json.Unmarshal(webAuthnBackup, &cc))
+	require.Len(t, cc.Credentials, 2)
+
+	assert.True(t, cc.Credentials[0].BackupEligible)
+	assert.True(t, cc.Credentials[0].BackupState)
+	assert.False(t, cc.Credentials[1].BackupEligible)
+	assert.False(t, cc.Credentials[1].BackupState)
+
+	actual := cc.Credentials.ToWebAuthn()
+	assert.True(t, actual[0].Flags.BackupEligible)
+	assert.True(t, actual[0].Flags.BackupState)
+	assert.False(t, actual[1].Flags.BackupEligible)
+	assert.False(t, actual[1].Flags.BackupState)
+
+	encoded, err := json.Marshal(cc)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(webAuthnBackup), string(encoded))
+
+	fromWebAuthn := CredentialFromWebAuthn(&actual[0], true)
+	assert.True(t, fromWebAuthn.BackupEligible)
+	assert.True(t, fromWebAuthn.BackupState)
+}
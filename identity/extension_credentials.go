@@ -44,6 +44,20 @@ func (r *SchemaExtensionCredentials) setIdentifier(ct CredentialsType, value int
 	r.i.SetCredentials(ct, *cred)
 }
 
+// isCredentialsIdentifier returns true if the extension configuration marks the value as
+// an identifier of the given credentials type.
+func isCredentialsIdentifier(e *schema.ExtensionConfig, ct CredentialsType) bool {
+	switch ct {
+	case CredentialsTypePassword:
+		return e.Credentials.Password.Identifier
+	case CredentialsTypeWebAuthn:
+		return e.Credentials.WebAuthn.Identifier
+	case CredentialsTypeCodeAuth:
+		return e.Credentials.Code.Identifier
+	}
+	return false
+}
+
 func (r *SchemaExtensionCredentials) Run(ctx jsonschema.ValidationContext, s schema.ExtensionConfig, value interface{}) error {
 	r.l.Lock()
 	defer r.l.Unlock()
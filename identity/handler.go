@@ -4,10 +4,12 @@
 package identity
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,9 +27,12 @@ import (
 
 	"github.com/ory/herodot"
 
+	"github.com/gofrs/uuid"
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
 
+	"github.com/ory/jsonschema/v3"
+
 	"github.com/ory/x/decoderx"
 	"github.com/ory/x/jsonx"
 	"github.com/ory/x/openapix"
@@ -35,14 +40,18 @@ import (
 	"github.com/ory/x/urlx"
 
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/schema"
 )
 
 const (
 	RouteCollection     = "/identities"
 	RouteItem           = RouteCollection + "/:id"
 	RouteCredentialItem = RouteItem + "/credentials/:type"
+	RouteIdentifiers    = RouteCredentialItem + "/identifiers"
+	RouteValidate       = RouteCollection + "/validate"
 
-	BatchPatchIdentitiesLimit = 2000
+	BatchPatchIdentitiesLimit    = 2000
+	BatchValidateIdentitiesLimit = 500
 )
 
 type (
@@ -50,11 +59,13 @@ type (
 		PoolProvider
 		PrivilegedPoolProvider
 		ManagementProvider
+		ValidationProvider
 		x.WriterProvider
 		config.Provider
 		x.CSRFProvider
 		cipher.Provider
 		hash.HashProvider
+		schema.IdentitySchemaProvider
 	}
 	HandlerProvider interface {
 		IdentityHandler() *Handler
@@ -80,8 +91,10 @@ func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	h.r.CSRFHandler().IgnoreGlobs(
 		RouteCollection, RouteCollection+"/*",
 		RouteCollection+"/*/credentials/*",
+		RouteCollection+"/*/credentials/*/identifiers",
 		x.AdminPrefix+RouteCollection, x.AdminPrefix+RouteCollection+"/*",
 		x.AdminPrefix+RouteCollection+"/*/credentials/*",
+		x.AdminPrefix+RouteCollection+"/*/credentials/*/identifiers",
 	)
 
 	public.GET(RouteCollection, x.RedirectToAdminRoute(h.r))
@@ -91,6 +104,8 @@ func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	public.PUT(RouteItem, x.RedirectToAdminRoute(h.r))
 	public.PATCH(RouteItem, x.RedirectToAdminRoute(h.r))
 	public.DELETE(RouteCredentialItem, x.RedirectToAdminRoute(h.r))
+	public.PATCH(RouteIdentifiers, x.RedirectToAdminRoute(h.r))
+	public.POST(RouteValidate, x.RedirectToAdminRoute(h.r))
 
 	public.GET(x.AdminPrefix+RouteCollection, x.RedirectToAdminRoute(h.r))
 	public.GET(x.AdminPrefix+RouteItem, x.RedirectToAdminRoute(h.r))
@@ -99,6 +114,8 @@ func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	public.PUT(x.AdminPrefix+RouteItem, x.RedirectToAdminRoute(h.r))
 	public.PATCH(x.AdminPrefix+RouteItem, x.RedirectToAdminRoute(h.r))
 	public.DELETE(x.AdminPrefix+RouteCredentialItem, x.RedirectToAdminRoute(h.r))
+	public.PATCH(x.AdminPrefix+RouteIdentifiers, x.RedirectToAdminRoute(h.r))
+	public.POST(x.AdminPrefix+RouteValidate, x.RedirectToAdminRoute(h.r))
 }
 
 func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
@@ -112,6 +129,8 @@ func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
 	admin.PUT(RouteItem, h.update)
 
 	admin.DELETE(RouteCredentialItem, h.deleteIdentityCredentials)
+	admin.PATCH(RouteIdentifiers, h.updateIdentityCredentialIdentifiers)
+	admin.POST(RouteValidate, h.validateIdentities)
 }
 
 // Paginated Identity List Response
@@ -171,6 +190,24 @@ type listIdentitiesParameters struct {
 	// in: query
 	DeclassifyCredentials []string `json:"include_credential"`
 
+	// Filter by Credential Type
+	//
+	// Only return identities which have credentials of this type, for example `totp`.
+	// Use together with `has=false` to only return identities without credentials of this type.
+	//
+	// required: false
+	// in: query
+	CredentialsType string `json:"credential_type"`
+
+	// Credential Type Presence
+	//
+	// If set to `false`, only identities without credentials of the type given in `credential_type` are returned.
+	//
+	// required: false
+	// default: true
+	// in: query
+	HasCredentialsType *bool `json:"has"`
+
 	crdbx.ConsistencyRequestParameters
 }
 
@@ -219,6 +256,23 @@ func (h *Handler) list(w http.ResponseWriter, r *http.Request, _ httprouter.Para
 		h.r.Writer().WriteError(w, r, herodot.ErrBadRequest.WithReason("Cannot pass both credentials_identifier and preview_credentials_identifier_similar."))
 		return
 	}
+	if raw := r.URL.Query().Get("credential_type"); raw != "" {
+		ct, ok := ParseCredentialsType(raw)
+		if !ok {
+			h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Invalid value `%s` for parameter `credential_type`.", raw)))
+			return
+		}
+		params.CredentialsType = ct
+
+		if has := r.URL.Query().Get("has"); has != "" {
+			hasType, err := strconv.ParseBool(has)
+			if err != nil {
+				h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Invalid value `%s` for parameter `has`.", has)))
+				return
+			}
+			params.WithoutCredentialsType = !hasType
+		}
+	}
 	if params.CredentialsIdentifier != "" || params.CredentialsIdentifierSimilar != "" || len(params.DeclassifyCredentials) > 0 {
 		params.Expand = ExpandEverything
 	}
@@ -236,7 +290,7 @@ func (h *Handler) list(w http.ResponseWriter, r *http.Request, _ httprouter.Para
 
 	if params.PagePagination != nil {
 		total := int64(len(is))
-		if params.CredentialsIdentifier == "" {
+		if params.CredentialsIdentifier == "" && params.CredentialsType == "" {
 			total, err = h.r.IdentityPool().CountIdentities(r.Context())
 			if err != nil {
 				h.r.Writer().WriteError(w, r, err)
@@ -371,7 +425,8 @@ type CreateIdentityBody struct {
 
 	// VerifiableAddresses contains all the addresses that can be verified by the user.
 	//
-	// Use this structure to import verified addresses for an identity. Please keep in mind
+	// Use this structure to import verified addresses for an identity. Addresses with `verified: true`
+	// or `status: completed` are stored as verified and do not need to be verified again. Please keep in mind
 	// that the address needs to be represented in the Identity Schema or this field will be overwritten
 	// on the next identity update.
 	VerifiableAddresses []VerifiableAddress `json:"verifiable_addresses"`
@@ -533,9 +588,14 @@ func (h *Handler) identityFromCreateIdentityBody(ctx context.Context, cr *Create
 		MetadataAdmin:       []byte(cr.MetadataAdmin),
 		MetadataPublic:      []byte(cr.MetadataPublic),
 	}
-	// Lowercase all emails, because the schema extension will otherwise not find them.
+	// Lowercase all emails and default the channel, because the schema extension will otherwise not find them.
 	for k := range i.VerifiableAddresses {
 		i.VerifiableAddresses[k].Value = strings.ToLower(i.VerifiableAddresses[k].Value)
+		i.VerifiableAddresses[k].Via = x.Coalesce(i.VerifiableAddresses[k].Via, AddressTypeEmail)
+		// Imported addresses with a completed verification are verified, even if `verified` was omitted.
+		if i.VerifiableAddresses[k].Status == VerifiableAddressStatusCompleted {
+			i.VerifiableAddresses[k].Verified = true
+		}
 	}
 	for k := range i.RecoveryAddresses {
 		i.RecoveryAddresses[k].Value = strings.ToLower(i.RecoveryAddresses[k].Value)
@@ -627,6 +687,191 @@ func (h *Handler) batchPatchIdentities(w http.ResponseWriter, r *http.Request, _
 	h.r.Writer().Write(w, r, &res)
 }
 
+// Validate Identities Parameters
+//
+// swagger:parameters validateIdentities
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type validateIdentities struct {
+	// in: body
+	// required: true
+	Body ValidateIdentitiesBody
+}
+
+// Validate Identities Body
+//
+// swagger:model validateIdentitiesBody
+type ValidateIdentitiesBody struct {
+	// IDs lists the identities to validate.
+	//
+	// required: true
+	IDs []uuid.UUID `json:"ids"`
+}
+
+// Validate Identities Response
+//
+// swagger:model validateIdentitiesResponse
+type ValidateIdentitiesResponse struct {
+	// Identities contains the validation result of each requested identity, in
+	// the order of the request.
+	//
+	// required: true
+	Identities []IdentityValidationResult `json:"identities"`
+}
+
+// Identity Validation Result
+//
+// swagger:model identityValidationResult
+type IdentityValidationResult struct {
+	// IdentityID is the ID of the validated identity.
+	//
+	// required: true
+	IdentityID uuid.UUID `json:"identity_id"`
+
+	// SchemaID is the ID of the identity schema the identity was validated against.
+	//
+	// required: true
+	SchemaID string `json:"schema_id"`
+
+	// Valid is true if the identity's traits conform to its current schema.
+	//
+	// required: true
+	Valid bool `json:"valid"`
+
+	// Violations lists the schema violations of the identity's traits.
+	Violations []IdentityValidationViolation `json:"violations,omitempty"`
+}
+
+// Identity Validation Violation
+//
+// swagger:model identityValidationViolation
+type IdentityValidationViolation struct {
+	// InstancePtr is the JSON pointer to the offending value, for example `#/traits/email`.
+	//
+	// required: true
+	InstancePtr string `json:"instance_ptr"`
+
+	// Message is a human-readable description of the violation.
+	//
+	// required: true
+	Message string `json:"message"`
+}
+
+// swagger:route POST /admin/identities/validate identity validateIdentities
+//
+// # Validate Identities Against Their Current Schema
+//
+// Validates the traits of one or more
+// [identities](https://www.ory.sh/docs/kratos/concepts/identity-user-model)
+// against their current identity schema and reports all violations. This is
+// useful to plan migrations after an identity schema changed, for example when
+// a trait became required. The identities are not modified.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  200: validateIdentitiesResponse
+//	  400: errorGeneric
+//	  404: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) validateIdentities(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var body ValidateIdentitiesBody
+	if err := jsonx.NewStrictDecoder(r.Body).Decode(&body); err != nil {
+		h.r.Writer().WriteErrorCode(w, r, http.StatusBadRequest, errors.WithStack(err))
+		return
+	}
+
+	if len(body.IDs) == 0 {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReason("At least one identity ID must be provided.")))
+		return
+	} else if len(body.IDs) > BatchValidateIdentitiesLimit {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf(
+			"The maximum number of identities that can be validated at once is %d.",
+			BatchValidateIdentitiesLimit)))
+		return
+	}
+
+	res := ValidateIdentitiesResponse{Identities: make([]IdentityValidationResult, len(body.IDs))}
+	for k, id := range body.IDs {
+		i, err := h.r.IdentityPool().GetIdentity(r.Context(), id, ExpandDefault)
+		if err != nil {
+			h.r.Writer().WriteError(w, r, err)
+			return
+		}
+
+		// The identity is only validated, never persisted, so changes made by the
+		// schema extensions are discarded.
+		violations, err := validationViolations(h.r.IdentityValidator().Validate(r.Context(), i))
+		if err != nil {
+			h.r.Writer().WriteError(w, r, err)
+			return
+		}
+
+		res.Identities[k] = IdentityValidationResult{
+			IdentityID: i.ID,
+			SchemaID:   i.SchemaID,
+			Valid:      len(violations) == 0,
+			Violations: violations,
+		}
+	}
+
+	h.r.Writer().Write(w, r, &res)
+}
+
+// validationViolations converts schema validation errors into violations. Any
+// other error is returned as is.
+func validationViolations(err error) ([]IdentityValidationViolation, error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	var (
+		listErr   *schema.ValidationListError
+		schemaErr *schema.ValidationError
+		jsonErr   *jsonschema.ValidationError
+	)
+	var causes []*jsonschema.ValidationError
+	switch {
+	case errors.As(err, &listErr):
+		for _, e := range listErr.Validations {
+			causes = append(causes, e.ValidationError)
+		}
+	case errors.As(err, &schemaErr):
+		causes = append(causes, schemaErr.ValidationError)
+	case errors.As(err, &jsonErr):
+		causes = append(causes, jsonErr)
+	default:
+		return nil, err
+	}
+
+	var violations []IdentityValidationViolation
+	var collect func(e *jsonschema.ValidationError)
+	collect = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			violations = append(violations, IdentityValidationViolation{InstancePtr: e.InstancePtr, Message: e.Message})
+			return
+		}
+		for _, cause := range e.Causes {
+			collect(cause)
+		}
+	}
+	for _, e := range causes {
+		collect(e)
+	}
+
+	return violations, nil
+}
+
 // Update Identity Parameters
 //
 // swagger:parameters updateIdentity
@@ -1001,3 +1246,239 @@ func (h *Handler) deleteIdentityCredentials(w http.ResponseWriter, r *http.Reque
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Update Identity Credential Identifiers Parameters
+//
+// swagger:parameters updateIdentityCredentialIdentifiers
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type updateIdentityCredentialIdentifiers struct {
+	// ID is the identity's ID.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+
+	// Type is the type of credentials whose identifiers are updated.
+	//
+	// required: true
+	// in: path
+	Type CredentialsType `json:"type"`
+
+	// in: body
+	// required: true
+	Body UpdateIdentityCredentialIdentifiersBody
+}
+
+// Update Identity Credential Identifiers Body
+//
+// swagger:model updateIdentityCredentialIdentifiersBody
+type UpdateIdentityCredentialIdentifiersBody struct {
+	// Identifiers lists the identifiers to replace.
+	//
+	// required: true
+	Identifiers []IdentifierReplacement `json:"identifiers"`
+}
+
+// Identifier Replacement
+//
+// swagger:model identifierReplacement
+type IdentifierReplacement struct {
+	// From is the identifier to replace.
+	//
+	// required: true
+	From string `json:"from"`
+
+	// To is the new identifier.
+	//
+	// required: true
+	To string `json:"to"`
+}
+
+// swagger:route PATCH /admin/identities/{id}/credentials/{type}/identifiers identity updateIdentityCredentialIdentifiers
+//
+// # Update the Identifiers of an Identity's Credential
+//
+// Replaces identifiers of an [identity](https://www.ory.sh/docs/kratos/concepts/identity-user-model) credential, for
+// example when the domain of a company email address changes. Traits which the identity schema marks as identifiers
+// of this credential type and which hold the old identifier are updated in the same transaction. The identity's
+// sessions are kept. Verifiable addresses which are rotated keep their verification state.
+//
+// This endpoint returns 409 if a new identifier is already used by another identity.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  200: identity
+//	  400: errorGeneric
+//	  404: errorGeneric
+//	  409: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) updateIdentityCredentialIdentifiers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var body UpdateIdentityCredentialIdentifiersBody
+	if err := h.dx.Decode(r, &body, decoderx.HTTPJSONDecoder()); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if len(body.Identifiers) == 0 {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReason("At least one identifier replacement must be provided.")))
+		return
+	}
+
+	identity, err := h.r.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), x.ParseUUID(ps.ByName("id")))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	ct := CredentialsType(ps.ByName("type"))
+	cred, ok := identity.GetCredentials(ct)
+	if !ok {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrNotFound.WithReasonf("This identity has no %s credentials.", ct)))
+		return
+	}
+
+	paths, err := h.credentialIdentifierTraits(r.Context(), identity, ct)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	for _, replacement := range body.Identifiers {
+		from, to := strings.TrimSpace(replacement.From), strings.TrimSpace(replacement.To)
+		if from == "" || to == "" {
+			h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReason("Identifier replacements must set both `from` and `to`.")))
+			return
+		}
+
+		idx := -1
+		for k, identifier := range cred.Identifiers {
+			if strings.EqualFold(identifier, from) {
+				idx = k
+				break
+			}
+		}
+		if idx < 0 {
+			h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("The %s credentials of this identity have no identifier %q.", ct, from)))
+			return
+		}
+
+		existing, _, err := h.r.PrivilegedIdentityPool().FindByCredentialsIdentifier(r.Context(), ct, to)
+		if err == nil && existing.ID != identity.ID {
+			h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrConflict.WithReasonf("The identifier %q is already in use by another identity.", to)))
+			return
+		} else if err != nil && !errors.Is(err, sqlcon.ErrNoRows) {
+			h.r.Writer().WriteError(w, r, err)
+			return
+		}
+
+		cred.Identifiers[idx] = to
+		if identity.Traits, err = replaceTraitValue(identity.Traits, paths, from, to); err != nil {
+			h.r.Writer().WriteError(w, r, err)
+			return
+		}
+		identity.VerifiableAddresses = rotateVerifiableAddresses(identity.VerifiableAddresses, from, to)
+	}
+	identity.SetCredentials(ct, *cred)
+
+	// Sessions are not touched by the update and therefore stay valid.
+	if err := h.r.IdentityManager().Update(
+		r.Context(),
+		identity,
+		ManagerAllowWriteProtectedTraits,
+	); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.Writer().Write(w, r, WithCredentialsMetadataAndAdminMetadataInJSON(*identity))
+}
+
+// credentialIdentifierTraits returns the paths of the traits which the identity
+// schema marks as identifiers of the given credentials type. Array items are
+// denoted by `#`.
+func (h *Handler) credentialIdentifierTraits(ctx context.Context, i *Identity, ct CredentialsType) ([]string, error) {
+	return h.r.IdentityValidator().traitPaths(ctx, i.SchemaID, func(e *schema.ExtensionConfig) bool {
+		return isCredentialsIdentifier(e, ct)
+	})
+}
+
+// rotateVerifiableAddresses adds a copy of each verifiable address holding from
+// with the value to. The rotated address thereby keeps its verification state
+// when the identity is validated. Copies which no trait produces, and addresses
+// whose trait no longer holds from, are dropped during validation.
+func rotateVerifiableAddresses(addresses []VerifiableAddress, from, to string) []VerifiableAddress {
+	rotated := addresses
+	for _, address := range addresses {
+		if !strings.EqualFold(address.Value, from) {
+			continue
+		}
+
+		address.ID = uuid.Nil
+		address.Value = strings.ToLower(to)
+		rotated = append(rotated, address)
+	}
+	return rotated
+}
+
+// replaceTraitValue replaces the string values at the given trait paths which
+// match from (ignoring case) with to. Other traits are left untouched, even if
+// they hold the same value.
+func replaceTraitValue(traits Traits, paths []string, from, to string) (Traits, error) {
+	if len(traits) == 0 || len(paths) == 0 {
+		return traits, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(traits))
+	dec.UseNumber()
+
+	var decoded interface{}
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to decode identity traits: %s", err))
+	}
+
+	var replace func(v interface{}, path []string) interface{}
+	replace = func(v interface{}, path []string) interface{} {
+		if len(path) == 0 {
+			if vv, ok := v.(string); ok && strings.EqualFold(strings.TrimSpace(vv), from) {
+				return to
+			}
+			return v
+		}
+
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			if child, ok := vv[path[0]]; ok {
+				vv[path[0]] = replace(child, path[1:])
+			}
+		case []interface{}:
+			if path[0] == "#" {
+				for k := range vv {
+					vv[k] = replace(vv[k], path[1:])
+				}
+			}
+		}
+		return v
+	}
+
+	for _, path := range paths {
+		decoded = replace(decoded, strings.Split(path, "."))
+	}
+
+	out, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return out, nil
+}
@@ -177,6 +177,19 @@ func TestHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("case=should not lose precision of large integer traits", func(t *testing.T) {
+		// Traits are stored and returned as raw JSON, so this holds regardless of
+		// identity.traits.preserve_number_precision, which only applies where Kratos
+		// rewrites traits (see TestManager and TestMerge).
+		var i identity.CreateIdentityBody
+		i.Traits = []byte(`{"bar":"baz","external_id":9007199254740993}`)
+		res := send(t, adminTS, "POST", "/identities", http.StatusCreated, &i)
+		assert.Equal(t, "9007199254740993", res.Get("traits.external_id").Raw, "%s", res.Raw)
+
+		res = get(t, adminTS, "/identities/"+res.Get("id").String(), http.StatusOK)
+		assert.Equal(t, "9007199254740993", res.Get("traits.external_id").Raw, "%s", res.Raw)
+	})
+
 	t.Run("case=should create an identity with metadata", func(t *testing.T) {
 		for name, ts := range map[string]*httptest.Server{"public": publicTS, "admin": adminTS} {
 			t.Run("endpoint="+name, func(t *testing.T) {
@@ -331,6 +344,41 @@ func TestHandler(t *testing.T) {
 
 			snapshotx.SnapshotT(t, identity.WithCredentialsAndAdminMetadataInJSON(*actual), snapshotx.ExceptNestedKeys(ignoreDefault...), snapshotx.ExceptNestedKeys("verified_at"))
 		})
+
+		t.Run("with pre-verified addresses", func(t *testing.T) {
+			for _, tc := range []struct {
+				name    string
+				address identity.VerifiableAddress
+			}{
+				{name: "verified and completed", address: identity.VerifiableAddress{Verified: true, Status: identity.VerifiableAddressStatusCompleted}},
+				{name: "only verified", address: identity.VerifiableAddress{Verified: true}},
+				{name: "only completed", address: identity.VerifiableAddress{Status: identity.VerifiableAddressStatusCompleted}},
+			} {
+				t.Run("case="+tc.name, func(t *testing.T) {
+					email := "pre-verified-" + x.NewUUID().String() + "@ory.sh"
+					address := tc.address
+					address.Value = email
+					res := send(t, adminTS, "POST", "/identities", http.StatusCreated, identity.CreateIdentityBody{
+						SchemaID:            "customer",
+						Traits:              []byte(`{"email": "` + email + `"}`),
+						VerifiableAddresses: []identity.VerifiableAddress{address},
+					})
+					actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, uuid.FromStringOrNil(res.Get("id").String()))
+					require.NoError(t, err)
+
+					require.Len(t, actual.VerifiableAddresses, 1)
+					assert.Equal(t, email, actual.VerifiableAddresses[0].Value)
+					assert.Equal(t, identity.VerifiableAddressTypeEmail, actual.VerifiableAddresses[0].Via)
+					assert.True(t, actual.VerifiableAddresses[0].Verified)
+					assert.Equal(t, identity.VerifiableAddressStatusCompleted, actual.VerifiableAddresses[0].Status)
+					require.NotNil(t, actual.VerifiableAddresses[0].VerifiedAt)
+
+					va, err := reg.PrivilegedIdentityPool().FindVerifiableAddressByValue(ctx, identity.VerifiableAddressTypeEmail, email)
+					require.NoError(t, err)
+					assert.True(t, va.Verified)
+				})
+			}
+		})
 	})
 
 	t.Run("case=unable to set ID itself", func(t *testing.T) {
@@ -1171,6 +1219,146 @@ func TestHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("case=should rotate credential identifiers", func(t *testing.T) {
+		createIdentity := func(t *testing.T, email, password string) *identity.Identity {
+			t.Helper()
+			p, err := reg.Hasher(ctx).Generate(context.Background(), []byte(password))
+			require.NoError(t, err)
+			// The non-identifier trait holds the same value and must not be rotated.
+			i := &identity.Identity{Traits: identity.Traits(`{"email":"` + email + `","bar":"` + email + `"}`)}
+			i.SetCredentials(identity.CredentialsTypePassword, identity.Credentials{
+				Type:        identity.CredentialsTypePassword,
+				Identifiers: []string{email},
+				Config:      sqlxx.JSONRawMessage(`{"hashed_password":"` + string(p) + `"}`),
+			})
+			require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(context.Background(), i))
+			return i
+		}
+
+		id := x.NewUUID().String()
+		oldEmail, newEmail, password := id+"@acme.com", id+"@acme.io", "ljanf123akf"
+		i := createIdentity(t, oldEmail, password)
+		other := createIdentity(t, x.NewUUID().String()+"@acme.io", password)
+
+		href := "/identities/" + i.ID.String() + "/credentials/password/identifiers"
+		rotate := func(from, to string) identity.UpdateIdentityCredentialIdentifiersBody {
+			return identity.UpdateIdentityCredentialIdentifiersBody{Identifiers: []identity.IdentifierReplacement{{From: from, To: to}}}
+		}
+
+		t.Run("case=rejects a collision", func(t *testing.T) {
+			otherEmail := gjson.GetBytes(other.Traits, "email").String()
+			send(t, adminTS, "PATCH", href, http.StatusConflict, rotate(oldEmail, otherEmail))
+
+			actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+			require.NoError(t, err)
+			assert.Equal(t, oldEmail, gjson.GetBytes(actual.Traits, "email").String())
+		})
+
+		t.Run("case=rejects unknown identifiers", func(t *testing.T) {
+			send(t, adminTS, "PATCH", href, http.StatusBadRequest, rotate("unknown@acme.com", newEmail))
+		})
+
+		t.Run("case=updates identifiers and traits", func(t *testing.T) {
+			res := send(t, adminTS, "PATCH", href, http.StatusOK, rotate(oldEmail, newEmail))
+			assert.Equal(t, newEmail, res.Get("traits.email").String(), "%s", res.Raw)
+			assert.Equal(t, oldEmail, res.Get("traits.bar").String(), "%s", res.Raw)
+			assert.Equal(t, newEmail, res.Get("credentials.password.identifiers.0").String(), "%s", res.Raw)
+
+			_, _, err := reg.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, identity.CredentialsTypePassword, oldEmail)
+			require.Error(t, err)
+
+			values := func(v url.Values) {
+				v.Set("identifier", newEmail)
+				v.Set("password", password)
+			}
+			loginResponse := testhelpers.SubmitLoginForm(t, true, publicTS.Client(), publicTS, values, false, true, 200, "")
+			assert.Equal(t, i.ID.String(), gjson.Get(loginResponse, "session.identity.id").String(), "%s", loginResponse)
+		})
+
+		t.Run("case=keeps the verification state of rotated addresses", func(t *testing.T) {
+			id := x.NewUUID().String()
+			verified, pending, rotated := id+"-verified@acme.com", id+"-pending@acme.com", id+"-verified@acme.io"
+
+			i := &identity.Identity{SchemaID: "multiple_emails", Traits: identity.Traits(`{"emails":["` + verified + `","` + pending + `"]}`)}
+			i.SetCredentials(identity.CredentialsTypePassword, identity.Credentials{
+				Type:        identity.CredentialsTypePassword,
+				Identifiers: []string{verified, pending},
+				Config:      sqlxx.JSONRawMessage(`{}`),
+			})
+			i.VerifiableAddresses = []identity.VerifiableAddress{
+				{Value: verified, Via: identity.AddressTypeEmail, Verified: true, Status: identity.VerifiableAddressStatusCompleted},
+				{Value: pending, Via: identity.AddressTypeEmail, Status: identity.VerifiableAddressStatusPending},
+			}
+			require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+
+			res := send(t, adminTS, "PATCH", "/identities/"+i.ID.String()+"/credentials/password/identifiers", http.StatusOK, rotate(verified, rotated))
+			require.EqualValues(t, 2, res.Get("verifiable_addresses.#").Int(), "%s", res.Raw)
+			assert.True(t, res.Get(`verifiable_addresses.#(value=="`+rotated+`").verified`).Bool(), "%s", res.Raw)
+			assert.Equal(t, "completed", res.Get(`verifiable_addresses.#(value=="`+rotated+`").status`).String(), "%s", res.Raw)
+			assert.False(t, res.Get(`verifiable_addresses.#(value=="`+pending+`").verified`).Bool(), "%s", res.Raw)
+			assert.False(t, res.Get(`verifiable_addresses.#(value=="`+verified+`")`).Exists(), "%s", res.Raw)
+
+			actual, err := reg.PrivilegedIdentityPool().GetIdentity(ctx, i.ID, identity.ExpandDefault)
+			require.NoError(t, err)
+			require.Len(t, actual.VerifiableAddresses, 2)
+			for _, address := range actual.VerifiableAddresses {
+				assert.Equal(t, address.Value == rotated, address.Verified, address.Value)
+			}
+		})
+	})
+
+	t.Run("case=should validate identities against their current schema", func(t *testing.T) {
+		compliant := &identity.Identity{SchemaID: "employee", Traits: identity.Traits(`{"email":"` + x.NewUUID().String() + `@ory.sh","department":"engineering"}`)}
+		outdated := &identity.Identity{SchemaID: "employee", Traits: identity.Traits(`{"email":"` + x.NewUUID().String() + `@ory.sh"}`)}
+		for _, i := range []*identity.Identity{compliant, outdated} {
+			require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+		}
+
+		// Make the department trait required for all employees.
+		testhelpers.SetIdentitySchemas(t, conf, map[string]string{
+			"default":         "file://./stub/identity.schema.json",
+			"customer":        "file://./stub/handler/customer.schema.json",
+			"multiple_emails": "file://./stub/handler/multiple_emails.schema.json",
+			"employee":        "file://./stub/handler/employee_department_required.schema.json",
+		})
+		t.Cleanup(func() {
+			testhelpers.SetIdentitySchemas(t, conf, map[string]string{
+				"default":         "file://./stub/identity.schema.json",
+				"customer":        "file://./stub/handler/customer.schema.json",
+				"multiple_emails": "file://./stub/handler/multiple_emails.schema.json",
+				"employee":        "file://./stub/handler/employee.schema.json",
+			})
+		})
+
+		t.Run("case=reports violations", func(t *testing.T) {
+			res := send(t, adminTS, "POST", "/identities/validate", http.StatusOK, &identity.ValidateIdentitiesBody{IDs: []uuid.UUID{outdated.ID, compliant.ID}})
+
+			assert.Equal(t, outdated.ID.String(), res.Get("identities.0.identity_id").String(), "%s", res.Raw)
+			assert.Equal(t, "employee", res.Get("identities.0.schema_id").String(), "%s", res.Raw)
+			assert.False(t, res.Get("identities.0.valid").Bool(), "%s", res.Raw)
+			assert.Equal(t, "#/traits", res.Get("identities.0.violations.0.instance_ptr").String(), "%s", res.Raw)
+			assert.Contains(t, res.Get("identities.0.violations.0.message").String(), "department", "%s", res.Raw)
+
+			assert.Equal(t, compliant.ID.String(), res.Get("identities.1.identity_id").String(), "%s", res.Raw)
+			assert.True(t, res.Get("identities.1.valid").Bool(), "%s", res.Raw)
+			assert.False(t, res.Get("identities.1.violations").Exists(), "%s", res.Raw)
+		})
+
+		t.Run("case=does not modify the identity", func(t *testing.T) {
+			actual, err := reg.IdentityPool().GetIdentity(ctx, outdated.ID, identity.ExpandNothing)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(outdated.Traits), string(actual.Traits))
+		})
+
+		t.Run("case=fails for unknown identities", func(t *testing.T) {
+			send(t, adminTS, "POST", "/identities/validate", http.StatusNotFound, &identity.ValidateIdentitiesBody{IDs: []uuid.UUID{x.NewUUID()}})
+		})
+
+		t.Run("case=requires at least one id", func(t *testing.T) {
+			send(t, adminTS, "POST", "/identities/validate", http.StatusBadRequest, &identity.ValidateIdentitiesBody{})
+		})
+	})
+
 	t.Run("case=PATCH should update metadata_admin correctly", func(t *testing.T) {
 		uuid := x.NewUUID().String()
 		i := &identity.Identity{Traits: identity.Traits(fmt.Sprintf(`{"subject":"%s"}`, uuid))}
@@ -1454,6 +1642,39 @@ func TestHandler(t *testing.T) {
 		})
 	})
 
+	t.Run("case=should list identities filtered by credential type", func(t *testing.T) {
+		withTOTP := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+		withTOTP.SetCredentials(identity.CredentialsTypeTOTP, identity.Credentials{
+			Type:        identity.CredentialsTypeTOTP,
+			Identifiers: []string{x.NewUUID().String()},
+			Config:      sqlxx.JSONRawMessage(`{"totp_url":"otpauth://totp/test"}`),
+		})
+		withoutTOTP := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, withTOTP))
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, withoutTOTP))
+
+		ids := "&ids=" + withTOTP.ID.String() + "&ids=" + withoutTOTP.ID.String()
+		for _, tc := range []struct {
+			query    string
+			expected string
+		}{
+			{query: "credential_type=totp", expected: withTOTP.ID.String()},
+			{query: "credential_type=totp&has=true", expected: withTOTP.ID.String()},
+			{query: "credential_type=totp&has=false", expected: withoutTOTP.ID.String()},
+		} {
+			t.Run("query="+tc.query, func(t *testing.T) {
+				res := get(t, adminTS, "/identities?"+tc.query+ids, http.StatusOK)
+				require.Len(t, res.Array(), 1, "%s", res.Raw)
+				assert.Equal(t, tc.expected, res.Get("0.id").String(), "%s", res.Raw)
+			})
+		}
+
+		t.Run("case=invalid parameters", func(t *testing.T) {
+			get(t, adminTS, "/identities?credential_type=XYZ", http.StatusBadRequest)
+			get(t, adminTS, "/identities?credential_type=totp&has=maybe", http.StatusBadRequest)
+		})
+	})
+
 	t.Run("case=should not be able to list all identities with credentials due to wrong credentials type", func(t *testing.T) {
 		for name, ts := range map[string]*httptest.Server{"admin": adminTS} {
 			t.Run("endpoint="+name, func(t *testing.T) {
@@ -4,6 +4,7 @@
 package identity
 
 import (
+	"bytes"
 	"context"
 	"database/sql/driver"
 	"encoding/json"
@@ -177,6 +178,16 @@ func (t *Traits) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Decode decodes the traits into v. If preserveNumberPrecision is set, numbers are decoded
+// as json.Number instead of float64 so that large integers survive re-encoding unchanged.
+func (t Traits) Decode(v any, preserveNumberPrecision bool) error {
+	dec := json.NewDecoder(bytes.NewReader(t))
+	if preserveNumberPrecision {
+		dec.UseNumber()
+	}
+	return errors.WithStack(dec.Decode(v))
+}
+
 func (i Identity) TableName(context.Context) string {
 	return "identities"
 }
@@ -392,6 +403,25 @@ func (i WithCredentialsMetadataAndAdminMetadataInJSON) MarshalJSON() ([]byte, er
 	return json.Marshal(localIdentity(i))
 }
 
+// WithExportedCredentialsAndAdminMetadataInJSON includes the credentials, but only
+// the parts of their configuration which contain no secrets, see Credentials.exportConfig.
+type WithExportedCredentialsAndAdminMetadataInJSON Identity
+
+func (i WithExportedCredentialsAndAdminMetadataInJSON) MarshalJSON() ([]byte, error) {
+	type localIdentity Identity
+	credentials := make(map[CredentialsType]Credentials, len(i.Credentials))
+	for k, v := range i.Credentials {
+		config, err := v.exportConfig()
+		if err != nil {
+			return nil, err
+		}
+		v.Config = config
+		credentials[k] = v
+	}
+	i.Credentials = credentials
+	return json.Marshal(localIdentity(i))
+}
+
 func (i *Identity) Validate() error {
 	expected := i.NID
 	if expected == uuid.Nil {
@@ -13,6 +13,7 @@ import (
 
 const (
 	RecoveryAddressTypeEmail RecoveryAddressType = AddressTypeEmail
+	RecoveryAddressTypeSMS   RecoveryAddressType = ChannelTypeSMS
 )
 
 type (
@@ -78,3 +79,14 @@ func NewRecoveryEmailAddress(
 		IdentityID: identity,
 	}
 }
+
+func NewRecoverySMSAddress(
+	value string,
+	identity uuid.UUID,
+) *RecoveryAddress {
+	return &RecoveryAddress{
+		Value:      value,
+		Via:        RecoveryAddressTypeSMS,
+		IdentityID: identity,
+	}
+}
@@ -116,6 +116,15 @@ func (a VerifiableAddress) ValidateNID() error {
 	return nil
 }
 
+// NeedsRevalidation returns true if the address was verified longer ago than
+// revalidateAfter. A zero revalidateAfter disables revalidation.
+func (a VerifiableAddress) NeedsRevalidation(revalidateAfter time.Duration) bool {
+	if revalidateAfter <= 0 || !a.Verified || a.VerifiedAt == nil {
+		return false
+	}
+	return time.Since(time.Time(*a.VerifiedAt)) > revalidateAfter
+}
+
 // Hash returns a unique string representation for the recovery address.
 func (a VerifiableAddress) Hash() string {
 	return fmt.Sprintf("%v|%v|%v|%v|%v|%v|%v", a.Value, a.Verified, a.Via, a.Status, a.VerifiedAt, a.IdentityID, a.NID)
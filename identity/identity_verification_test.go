@@ -103,3 +103,14 @@ func TestVerifiableAddress_Hash(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifiableAddress_NeedsRevalidation(t *testing.T) {
+	twoDaysAgo := sqlxx.NullTime(time.Now().Add(-48 * time.Hour))
+	verified := VerifiableAddress{Verified: true, Status: VerifiableAddressStatusCompleted, VerifiedAt: &twoDaysAgo}
+
+	assert.False(t, verified.NeedsRevalidation(0), "revalidation is disabled")
+	assert.False(t, verified.NeedsRevalidation(72*time.Hour), "verified within the window")
+	assert.True(t, verified.NeedsRevalidation(24*time.Hour), "verified beyond the window")
+	assert.False(t, VerifiableAddress{Verified: false}.NeedsRevalidation(24*time.Hour), "never verified")
+	assert.False(t, VerifiableAddress{Verified: true}.NeedsRevalidation(24*time.Hour), "verification time unknown")
+}
@@ -9,6 +9,8 @@ import (
 	"reflect"
 	"slices"
 	"sort"
+	"strings"
+	"time"
 
 	"go.opentelemetry.io/otel/trace"
 
@@ -37,6 +39,12 @@ import (
 var ErrProtectedFieldModified = herodot.ErrForbidden.
 	WithReasonf(`A field was modified that updates one or more credentials-related settings. This action was blocked because an unprivileged method was used to execute the update. This is either a configuration issue or a bug and should be reported to the system administrator.`)
 
+// ErrModifiedSince is returned if an identity update requires the identity to be unmodified since a
+// point in time, but the identity was modified after it.
+var ErrModifiedSince = herodot.ErrConflict.
+	WithError("identity was modified").
+	WithReasonf(`The identity was modified after the point in time the update was based on.`)
+
 type (
 	managerDependencies interface {
 		config.Provider
@@ -56,8 +64,10 @@ type (
 	}
 
 	ManagerOptions struct {
-		ExposeValidationErrors    bool
-		AllowWriteProtectedTraits bool
+		ExposeValidationErrors     bool
+		AllowWriteProtectedTraits  bool
+		DropConflictingIdentifiers bool
+		UnmodifiedSince            *time.Time
 	}
 
 	ManagerOption func(*ManagerOptions)
@@ -75,6 +85,21 @@ func ManagerAllowWriteProtectedTraits(options *ManagerOptions) {
 	options.AllowWriteProtectedTraits = true
 }
 
+// ManagerDropConflictingIdentifiers removes credential identifiers and addresses which are
+// already in use by another identity before creating the identity, instead of failing
+// with a duplicate credentials error.
+func ManagerDropConflictingIdentifiers(options *ManagerOptions) {
+	options.DropConflictingIdentifiers = true
+}
+
+// ManagerRequireUnmodifiedSince makes the update fail with ErrModifiedSince if the identity was
+// modified after the given time.
+func ManagerRequireUnmodifiedSince(since time.Time) ManagerOption {
+	return func(options *ManagerOptions) {
+		options.UnmodifiedSince = &since
+	}
+}
+
 func newManagerOptions(opts []ManagerOption) *ManagerOptions {
 	var o ManagerOptions
 	for _, f := range opts {
@@ -96,6 +121,26 @@ func (m *Manager) Create(ctx context.Context, i *Identity, opts ...ManagerOption
 		return err
 	}
 
+	if o.DropConflictingIdentifiers {
+		original := deepcopy.Copy(i).(*Identity)
+		if dropped, err := m.dropConflictingIdentifiers(ctx, i); err != nil {
+			return err
+		} else if dropped {
+			// The identifiers and addresses are derived from the traits again, so that
+			// they stay consistent with what is persisted.
+			if err := m.r.IdentityValidator().Validate(ctx, i); err != nil {
+				if _, ok := errorsx.Cause(err).(*jsonschema.ValidationError); !ok {
+					return err
+				}
+
+				// The identity schema requires one of the conflicting values, so there
+				// is no separate identity without them.
+				*i = *original
+				return m.findExistingAuthMethod(ctx, errors.WithStack(sqlcon.ErrUniqueViolation), i)
+			}
+		}
+	}
+
 	if err := i.SetAvailableAAL(ctx, m); err != nil {
 		return err
 	}
@@ -167,6 +212,137 @@ func (m *Manager) ConflictingIdentity(ctx context.Context, i *Identity) (found *
 	return nil, "", sqlcon.ErrNoRows
 }
 
+// dropConflictingIdentifiers removes all credential identifiers, verifiable addresses, and recovery
+// addresses from the identity which are already used by another identity. Credentials which are left
+// without identifiers and without configuration are removed entirely. The conflicting values are also
+// removed from the traits the identity schema derives them from, as they would otherwise conflict again
+// on the next update of the identity. Other traits are left untouched, even if they hold the same value.
+// Returns true if anything was removed.
+func (m *Manager) dropConflictingIdentifiers(ctx context.Context, i *Identity) (bool, error) {
+	type conflict struct {
+		match  func(e *schema.ExtensionConfig) bool
+		values map[string]struct{}
+	}
+	var conflicts []conflict
+	addConflict := func(match func(e *schema.ExtensionConfig) bool, values map[string]struct{}) {
+		if len(values) > 0 {
+			conflicts = append(conflicts, conflict{match: match, values: values})
+		}
+	}
+
+	for ct, cred := range i.Credentials {
+		conflicting := make(map[string]struct{})
+		identifiers := make([]string, 0, len(cred.Identifiers))
+		for _, id := range cred.Identifiers {
+			if _, _, err := m.r.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, ct, id); err == nil {
+				conflicting[strings.ToLower(strings.TrimSpace(id))] = struct{}{}
+				continue
+			} else if !errors.Is(err, sqlcon.ErrNoRows) {
+				return false, err
+			}
+			identifiers = append(identifiers, id)
+		}
+		addConflict(func(e *schema.ExtensionConfig) bool { return isCredentialsIdentifier(e, ct) }, conflicting)
+
+		if len(identifiers) == 0 && len(cred.Config) == 0 {
+			i.DeleteCredentialsType(ct)
+			continue
+		}
+
+		cred.Identifiers = identifiers
+		i.SetCredentials(ct, cred)
+	}
+
+	conflicting := make(map[string]struct{})
+	verifiable := make([]VerifiableAddress, 0, len(i.VerifiableAddresses))
+	for _, va := range i.VerifiableAddresses {
+		if _, err := m.r.PrivilegedIdentityPool().FindVerifiableAddressByValue(ctx, va.Via, va.Value); err == nil {
+			conflicting[strings.ToLower(strings.TrimSpace(va.Value))] = struct{}{}
+			continue
+		} else if !errors.Is(err, sqlcon.ErrNoRows) {
+			return false, err
+		}
+		verifiable = append(verifiable, va)
+	}
+	i.VerifiableAddresses = verifiable
+	addConflict(func(e *schema.ExtensionConfig) bool { return e.Verification.Via != "" }, conflicting)
+
+	conflicting = make(map[string]struct{})
+	recovery := make([]RecoveryAddress, 0, len(i.RecoveryAddresses))
+	for _, ra := range i.RecoveryAddresses {
+		if _, err := m.r.PrivilegedIdentityPool().FindRecoveryAddressByValue(ctx, ra.Via, ra.Value); err == nil {
+			conflicting[strings.ToLower(strings.TrimSpace(ra.Value))] = struct{}{}
+			continue
+		} else if !errors.Is(err, sqlcon.ErrNoRows) {
+			return false, err
+		}
+		recovery = append(recovery, ra)
+	}
+	i.RecoveryAddresses = recovery
+	addConflict(func(e *schema.ExtensionConfig) bool { return e.Recovery.Via != "" }, conflicting)
+
+	if len(conflicts) == 0 {
+		return false, nil
+	}
+
+	var traits any
+	if err := i.Traits.Decode(&traits, m.r.Config().IdentityTraitsPreserveNumberPrecision(ctx)); err != nil {
+		return false, err
+	}
+	for _, c := range conflicts {
+		paths, err := m.r.IdentityValidator().traitPaths(ctx, i.SchemaID, c.match)
+		if err != nil {
+			return false, err
+		}
+		for _, path := range paths {
+			traits, _ = dropTraitValues(traits, strings.Split(path, "."), c.values)
+		}
+	}
+	raw, err := json.Marshal(traits)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	i.Traits = raw
+
+	return true, nil
+}
+
+// dropTraitValues removes the strings at the given path of the decoded traits which are contained in
+// values, compared case-insensitively. Array items are denoted by `#`. Returns false if the given value
+// itself is such a string.
+func dropTraitValues(traits any, path []string, values map[string]struct{}) (any, bool) {
+	if len(path) == 0 {
+		if t, ok := traits.(string); ok {
+			_, drop := values[strings.ToLower(strings.TrimSpace(t))]
+			return t, !drop
+		}
+		return traits, true
+	}
+
+	switch t := traits.(type) {
+	case map[string]any:
+		if v, ok := t[path[0]]; ok {
+			if v, keep := dropTraitValues(v, path[1:], values); keep {
+				t[path[0]] = v
+			} else {
+				delete(t, path[0])
+			}
+		}
+	case []any:
+		if path[0] != "#" {
+			return t, true
+		}
+		kept := make([]any, 0, len(t))
+		for _, v := range t {
+			if v, keep := dropTraitValues(v, path[1:], values); keep {
+				kept = append(kept, v)
+			}
+		}
+		return kept, true
+	}
+	return traits, true
+}
+
 func (m *Manager) findExistingAuthMethod(ctx context.Context, e error, i *Identity) (err error) {
 	if !m.r.Config().SelfServiceFlowRegistrationLoginHints(ctx) {
 		return &ErrDuplicateCredentials{error: e}
@@ -394,6 +570,9 @@ func (m *Manager) Update(ctx context.Context, updated *Identity, opts ...Manager
 		return err
 	}
 
+	if o.UnmodifiedSince != nil {
+		return m.r.PrivilegedIdentityPool().UpdateIdentityIfUnmodifiedSince(ctx, updated, *o.UnmodifiedSince)
+	}
 	return m.r.PrivilegedIdentityPool().UpdateIdentity(ctx, updated)
 }
 
@@ -469,6 +648,14 @@ func (m *Manager) ValidateIdentity(ctx context.Context, i *Identity, o *ManagerO
 		return err
 	}
 
+	if maxSize := m.r.Config().IdentityMaxCredentialConfigSize(ctx); maxSize > 0 {
+		for ct, c := range i.Credentials {
+			if len(c.Config) > maxSize {
+				return errors.WithStack(herodot.ErrBadRequest.WithReasonf("The configuration of the %s credentials is %d bytes large and exceeds the maximum size of %d bytes.", ct, len(c.Config), maxSize))
+			}
+		}
+	}
+
 	return nil
 }
 
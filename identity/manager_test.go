@@ -5,9 +5,11 @@ package identity_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ory/herodot"
 	"github.com/ory/x/configx"
 	"github.com/ory/x/pointerx"
 	"github.com/ory/x/sqlcon"
@@ -23,6 +25,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ory/kratos/driver/config"
+	confighelpers "github.com/ory/kratos/driver/config/testhelpers"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/internal"
 	"github.com/ory/kratos/x"
@@ -351,6 +354,73 @@ func TestManager(t *testing.T) {
 				runAddress(t, "email_recovery")
 			})
 		})
+
+		t.Run("case=should drop conflicting identifiers with option", func(t *testing.T) {
+			email := x.NewUUID().String() + "@ory.sh"
+			require.NoError(t, reg.IdentityManager().Create(ctx, &identity.Identity{SchemaID: config.DefaultIdentityTraitsSchemaID, Traits: newTraits(email, "first")}))
+
+			separate := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+			separate.Traits = newTraits(email, "second")
+			require.NoError(t, reg.IdentityManager().Create(ctx, separate, identity.ManagerDropConflictingIdentifiers))
+			assert.JSONEq(t, `{"unprotected":"second"}`, string(separate.Traits))
+			assert.Empty(t, separate.VerifiableAddresses)
+			assert.Empty(t, separate.RecoveryAddresses)
+			assert.Empty(t, separate.Credentials[identity.CredentialsTypePassword].Identifiers)
+
+			// The identity can be updated without running into the conflict again.
+			separate.Traits = identity.Traits(`{"unprotected":"updated"}`)
+			require.NoError(t, reg.IdentityManager().Update(ctx, separate))
+
+			fromStore, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, separate.ID)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"unprotected":"updated"}`, string(fromStore.Traits))
+		})
+
+		t.Run("case=should keep other traits with the same value as a conflicting identifier", func(t *testing.T) {
+			email := x.NewUUID().String() + "@ory.sh"
+			require.NoError(t, reg.IdentityManager().Create(ctx, &identity.Identity{SchemaID: config.DefaultIdentityTraitsSchemaID, Traits: newTraits(email, "first")}))
+
+			separate := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+			separate.Traits = newTraits(email, strings.ToUpper(email))
+			require.NoError(t, reg.IdentityManager().Create(ctx, separate, identity.ManagerDropConflictingIdentifiers))
+			assert.JSONEq(t, `{"unprotected":"`+strings.ToUpper(email)+`"}`, string(separate.Traits))
+			assert.Empty(t, separate.VerifiableAddresses)
+			assert.Empty(t, separate.RecoveryAddresses)
+		})
+
+		t.Run("case=should keep number precision when dropping conflicting identifiers if configured", func(t *testing.T) {
+			for _, tc := range []struct {
+				preserve bool
+				expected string
+			}{
+				{preserve: true, expected: `{"unprotected":"second","external_id":9007199254740993}`},
+				{preserve: false, expected: `{"unprotected":"second","external_id":9007199254740992}`},
+			} {
+				t.Run(fmt.Sprintf("preserve=%t", tc.preserve), func(t *testing.T) {
+					ctx := confighelpers.WithConfigValue(ctx, config.ViperKeyIdentityTraitsPreserveNumberPrecision, tc.preserve)
+					email := x.NewUUID().String() + "@ory.sh"
+					require.NoError(t, reg.IdentityManager().Create(ctx, &identity.Identity{SchemaID: config.DefaultIdentityTraitsSchemaID, Traits: newTraits(email, "first")}))
+
+					separate := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+					separate.Traits = identity.Traits(fmt.Sprintf(`{"email":"%[1]s","email_verify":"%[1]s","email_recovery":"%[1]s","email_creds":"%[1]s","unprotected":"second","external_id":9007199254740993}`, email))
+					require.NoError(t, reg.IdentityManager().Create(ctx, separate, identity.ManagerDropConflictingIdentifiers))
+					assert.JSONEq(t, tc.expected, string(separate.Traits))
+				})
+			}
+		})
+
+		t.Run("case=should not drop conflicting identifiers required by the schema", func(t *testing.T) {
+			email := x.NewUUID().String() + "@ory.sh"
+			require.NoError(t, reg.IdentityManager().Create(ctx, &identity.Identity{SchemaID: extensionSchemaID, Traits: identity.Traits(`{"email":"` + email + `"}`)}))
+
+			separate := identity.NewIdentity(extensionSchemaID)
+			separate.Traits = identity.Traits(`{"email":"` + email + `"}`)
+			err := reg.IdentityManager().Create(ctx, separate, identity.ManagerDropConflictingIdentifiers)
+
+			var verr = new(identity.ErrDuplicateCredentials)
+			require.ErrorAs(t, err, &verr)
+			assert.JSONEq(t, `{"email":"`+email+`"}`, string(separate.Traits))
+		})
 	})
 
 	t.Run("method=Update", func(t *testing.T) {
@@ -422,6 +492,37 @@ func TestManager(t *testing.T) {
 			assert.EqualValues(t, identity.NoAuthenticatorAssuranceLevel, original.AvailableAAL.String)
 		})
 
+		t.Run("case=should reject credentials exceeding the maximum config size", func(t *testing.T) {
+			ctx := confighelpers.WithConfigValue(ctx, config.ViperKeyIdentityMaxCredentialConfigSize, 128)
+
+			email := uuid.Must(uuid.NewV4()).String() + "@ory.sh"
+			original := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+			original.Traits = newTraits(email, "")
+			require.NoError(t, reg.IdentityManager().Create(ctx, original))
+
+			original.Credentials = map[identity.CredentialsType]identity.Credentials{
+				identity.CredentialsTypeWebAuthn: {
+					Type:        identity.CredentialsTypeWebAuthn,
+					Identifiers: []string{x.NewUUID().String()},
+					Config:      sqlxx.JSONRawMessage(`{"credentials":[{"id":"` + strings.Repeat("a", 128) + `"}]}`),
+				},
+			}
+			err := reg.IdentityManager().Update(ctx, original, identity.ManagerAllowWriteProtectedTraits)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, herodot.ErrBadRequest)
+
+			fromStore, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, original.ID)
+			require.NoError(t, err)
+			assert.NotContains(t, fromStore.Credentials, identity.CredentialsTypeWebAuthn)
+
+			original.Credentials[identity.CredentialsTypeWebAuthn] = identity.Credentials{
+				Type:        identity.CredentialsTypeWebAuthn,
+				Identifiers: []string{x.NewUUID().String()},
+				Config:      sqlxx.JSONRawMessage(`{"credentials":[]}`),
+			}
+			require.NoError(t, reg.IdentityManager().Update(ctx, original, identity.ManagerAllowWriteProtectedTraits))
+		})
+
 		t.Run("case=should not update protected traits without option", func(t *testing.T) {
 			original := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
 			original.Traits = newTraits("email-update-1@ory.sh", "")
@@ -618,6 +719,36 @@ func TestManager(t *testing.T) {
 		})
 	})
 
+	t.Run("case=identifier_storage=hashed", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceIdentifierStorage, config.IdentifierStorageHashed)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceIdentifierStorage, config.IdentifierStoragePlaintext)
+		})
+
+		email := x.NewUUID().String() + "@ory.sh"
+		original := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+		original.Traits = newTraits(email, "initial")
+		require.NoError(t, reg.IdentityManager().Create(ctx, original))
+
+		t.Run("case=should update unprotected traits", func(t *testing.T) {
+			require.NoError(t, reg.IdentityManager().UpdateTraits(ctx, original.ID, newTraits(email, "updated")))
+
+			fromStore, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, original.ID)
+			require.NoError(t, err)
+			checkExtensionFields(fromStore, email)(t)
+		})
+
+		t.Run("case=should hint at the duplicate identifier in plaintext", func(t *testing.T) {
+			duplicate := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+			duplicate.Traits = newTraits(email, "")
+			err := reg.IdentityManager().Create(ctx, duplicate)
+
+			var verr = new(identity.ErrDuplicateCredentials)
+			require.ErrorAs(t, err, &verr)
+			assert.Equal(t, email, verr.IdentifierHint())
+		})
+	})
+
 	t.Run("method=ConflictingIdentity", func(t *testing.T) {
 		ctx := ctx
 
@@ -5,6 +5,7 @@ package identity
 
 import (
 	"context"
+	"time"
 
 	"github.com/ory/x/crdbx"
 
@@ -22,7 +23,11 @@ type (
 		CredentialsIdentifier        string
 		CredentialsIdentifierSimilar string
 		DeclassifyCredentials        []CredentialsType
-		KeySetPagination             []keysetpagination.Option
+		// CredentialsType filters identities by whether they have credentials of the given type. If
+		// WithoutCredentialsType is true, only identities without credentials of that type are returned.
+		CredentialsType        CredentialsType
+		WithoutCredentialsType bool
+		KeySetPagination       []keysetpagination.Option
 		// DEPRECATED
 		PagePagination   *x.Page
 		ConsistencyLevel crdbx.ConsistencyLevel
@@ -78,6 +83,10 @@ type (
 		// UpdateIdentity updates an identity including its confidential / privileged / protected data.
 		UpdateIdentity(context.Context, *Identity) error
 
+		// UpdateIdentityIfUnmodifiedSince updates an identity like UpdateIdentity, but fails with ErrModifiedSince
+		// if the identity was modified after the given time. The check and the update are atomic.
+		UpdateIdentityIfUnmodifiedSince(ctx context.Context, i *Identity, since time.Time) error
+
 		// GetIdentityConfidential returns the identity including it's raw credentials. This should only be used internally.
 		GetIdentityConfidential(context.Context, uuid.UUID) (*Identity, error)
 
@@ -8,6 +8,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 	"testing"
@@ -23,6 +24,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
 
+	"github.com/ory/herodot"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/internal/testhelpers"
@@ -549,6 +551,37 @@ func TestPool(ctx context.Context, p persistence.Persister, m *identity.Manager,
 			require.Contains(t, err.Error(), "malformed")
 		})
 
+		t.Run("case=update an identity only if it was not modified since", func(t *testing.T) {
+			initial := oidcIdentity("", x.NewUUID().String())
+			require.NoError(t, p.CreateIdentity(ctx, initial))
+			createdIDs = append(createdIDs, initial.ID)
+
+			loaded, err := p.GetIdentityConfidential(ctx, initial.ID)
+			require.NoError(t, err)
+			since := loaded.UpdatedAt
+
+			first := loaded.CopyWithoutCredentials()
+			first.Credentials = loaded.Credentials
+			first.Traits = identity.Traits(`{"update":"first"}`)
+			require.NoError(t, p.UpdateIdentityIfUnmodifiedSince(ctx, first, since))
+
+			actual, err := p.GetIdentityConfidential(ctx, initial.ID)
+			require.NoError(t, err)
+			assert.True(t, actual.UpdatedAt.After(since), "%s should be after %s", actual.UpdatedAt, since)
+
+			// A second update based on the same state conflicts with the first one.
+			second := loaded.CopyWithoutCredentials()
+			second.Credentials = loaded.Credentials
+			second.Traits = identity.Traits(`{"update":"second"}`)
+			require.ErrorIs(t, p.UpdateIdentityIfUnmodifiedSince(ctx, second, since), identity.ErrModifiedSince)
+
+			actual, err = p.GetIdentityConfidential(ctx, initial.ID)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"update":"first"}`, string(actual.Traits))
+
+			require.NoError(t, p.UpdateIdentityIfUnmodifiedSince(ctx, second, actual.UpdatedAt))
+		})
+
 		t.Run("case=should fail to insert identity because credentials from traits exist", func(t *testing.T) {
 			first := passwordIdentity("", "test-identity@ory.sh")
 			first.Traits = identity.Traits(`{}`)
@@ -1105,6 +1138,54 @@ func TestPool(ctx context.Context, p persistence.Persister, m *identity.Manager,
 				assert.Equal(t, "new-code", actual.Value)
 			})
 
+			t.Run("case=explains address conflicts", func(t *testing.T) {
+				verified := createIdentityWithAddresses(t, randx.MustString(16, randx.AlphaLowerNum)+"@ory.sh")
+				verified.Verified = true
+				verified.Status = identity.VerifiableAddressStatusCompleted
+				require.NoError(t, p.UpdateVerifiableAddress(ctx, &verified))
+
+				other := createIdentityWithAddresses(t, randx.MustString(16, randx.AlphaLowerNum)+"@ory.sh")
+				other.Value = verified.Value
+				other.Verified = true
+				other.Status = identity.VerifiableAddressStatusCompleted
+
+				err := p.UpdateVerifiableAddress(ctx, &other)
+				var he *herodot.DefaultError
+				require.ErrorAs(t, err, &he)
+				assert.Equal(t, http.StatusConflict, he.CodeField)
+				assert.Equal(t, "This address is already used by another account.", he.Reason())
+
+				t.Run("verifying the same identity again is allowed", func(t *testing.T) {
+					require.NoError(t, p.UpdateVerifiableAddress(ctx, &verified))
+				})
+
+				t.Run("unverified addresses are unique as well", func(t *testing.T) {
+					unverified := createIdentityWithAddresses(t, randx.MustString(16, randx.AlphaLowerNum)+"@ory.sh")
+					require.False(t, unverified.Verified)
+
+					duplicate := createIdentityWithAddresses(t, randx.MustString(16, randx.AlphaLowerNum)+"@ory.sh")
+					duplicate.Value = unverified.Value
+
+					err := p.UpdateVerifiableAddress(ctx, &duplicate)
+					require.ErrorIs(t, err, sqlcon.ErrUniqueViolation)
+					require.ErrorAs(t, err, &he)
+					assert.Equal(t, http.StatusConflict, he.CodeField)
+					assert.Equal(t, "This address is already used by another account.", he.Reason())
+				})
+
+				t.Run("updating the identity is rejected as well", func(t *testing.T) {
+					var i identity.Identity
+					require.NoError(t, faker.FakeData(&i))
+					require.NoError(t, p.CreateIdentity(ctx, &i))
+
+					i.VerifiableAddresses = []identity.VerifiableAddress{*identity.NewVerifiableEmailAddress(verified.Value, i.ID)}
+					err := p.UpdateIdentity(ctx, &i)
+					require.ErrorIs(t, err, sqlcon.ErrUniqueViolation)
+					require.ErrorAs(t, err, &he)
+					assert.Equal(t, "This address is already used by another account.", he.Reason())
+				})
+			})
+
 			t.Run("case=create and update and find", func(t *testing.T) {
 				var i identity.Identity
 				require.NoError(t, faker.FakeData(&i))
@@ -5,10 +5,13 @@ package identity
 
 import (
 	"context"
+	"strings"
 
 	"github.com/pkg/errors"
 
 	"github.com/ory/herodot"
+	"github.com/ory/jsonschema/v3"
+	"github.com/ory/x/jsonschemax"
 
 	"github.com/tidwall/sjson"
 
@@ -72,3 +75,42 @@ func (v *Validator) Validate(ctx context.Context, i *Identity) error {
 		)
 	})
 }
+
+// traitPaths returns the paths of the traits, relative to the traits, whose `ory.sh/kratos`
+// extension configuration in the identity schema matches. Array items are denoted by `#`.
+func (v *Validator) traitPaths(ctx context.Context, schemaID string, match func(e *schema.ExtensionConfig) bool) ([]string, error) {
+	ss, err := v.d.IdentityTraitsSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := ss.GetByID(schemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	runner, err := schema.NewExtensionRunner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := jsonschema.NewCompiler()
+	runner.Register(c)
+
+	paths, err := jsonschemax.ListPathsWithArraysIncluded(ctx, s.URL.String(), c)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var result []string
+	for _, p := range paths {
+		e, ok := p.CustomProperties[schema.ExtensionName].(*schema.ExtensionConfig)
+		if !ok || !match(e) {
+			continue
+		}
+		if strings.HasPrefix(p.Name, "traits.") {
+			result = append(result, strings.TrimPrefix(p.Name, "traits."))
+		}
+	}
+
+	return result, nil
+}
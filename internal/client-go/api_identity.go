@@ -152,6 +152,24 @@ type IdentityApi interface {
 	 */
 	DisableSessionExecute(r IdentityApiApiDisableSessionRequest) (*http.Response, error)
 
+	/*
+			 * ExportIdentity Export all Data of an Identity
+			 * This endpoint returns everything Ory Kratos stores about an identity in a single JSON document, for example
+		to answer data subject access requests. The export contains the identity's traits, metadata, verifiable and
+		recovery addresses, the public parts of its credentials, and its active sessions. Credential secrets such as
+		password hashes are excluded.
+			 * @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+			 * @param id ID is the identity's ID.
+			 * @return IdentityApiApiExportIdentityRequest
+	*/
+	ExportIdentity(ctx context.Context, id string) IdentityApiApiExportIdentityRequest
+
+	/*
+	 * ExportIdentityExecute executes the request
+	 * @return IdentityExport
+	 */
+	ExportIdentityExecute(r IdentityApiApiExportIdentityRequest) (*IdentityExport, *http.Response, error)
+
 	/*
 			 * ExtendSession Extend a Session
 			 * Calling this endpoint extends the given session ID. If `session.earliest_possible_extend` is set it
@@ -313,6 +331,45 @@ type IdentityApi interface {
 	 * @return Identity
 	 */
 	UpdateIdentityExecute(r IdentityApiApiUpdateIdentityRequest) (*Identity, *http.Response, error)
+
+	/*
+			 * UpdateIdentityCredentialIdentifiers Update the Identifiers of an Identity's Credential
+			 * Replaces identifiers of an [identity](https://www.ory.sh/docs/kratos/concepts/identity-user-model) credential, for
+		example when the domain of a company email address changes. Traits which the identity schema marks as identifiers
+		of this credential type and which hold the old identifier are updated in the same transaction. The identity's
+		sessions are kept. Verifiable addresses which are rotated keep their verification state.
+
+		This endpoint returns 409 if a new identifier is already used by another identity.
+			 * @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+			 * @param id ID is the identity's ID.
+			 * @param type_ Type is the type of credentials whose identifiers are updated. password CredentialsTypePassword oidc CredentialsTypeOIDC totp CredentialsTypeTOTP lookup_secret CredentialsTypeLookup webauthn CredentialsTypeWebAuthn code CredentialsTypeCodeAuth passkey CredentialsTypePasskey profile CredentialsTypeProfile link_recovery CredentialsTypeRecoveryLink  CredentialsTypeRecoveryLink is a special credential type linked to the link strategy (recovery flow).  It is not used within the credentials object itself. code_recovery CredentialsTypeRecoveryCode
+			 * @return IdentityApiApiUpdateIdentityCredentialIdentifiersRequest
+	*/
+	UpdateIdentityCredentialIdentifiers(ctx context.Context, id string, type_ string) IdentityApiApiUpdateIdentityCredentialIdentifiersRequest
+
+	/*
+	 * UpdateIdentityCredentialIdentifiersExecute executes the request
+	 * @return Identity
+	 */
+	UpdateIdentityCredentialIdentifiersExecute(r IdentityApiApiUpdateIdentityCredentialIdentifiersRequest) (*Identity, *http.Response, error)
+
+	/*
+			 * ValidateIdentities Validate Identities Against Their Current Schema
+			 * Validates the traits of one or more
+		[identities](https://www.ory.sh/docs/kratos/concepts/identity-user-model)
+		against their current identity schema and reports all violations. This is
+		useful to plan migrations after an identity schema changed, for example when
+		a trait became required. The identities are not modified.
+			 * @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+			 * @return IdentityApiApiValidateIdentitiesRequest
+	*/
+	ValidateIdentities(ctx context.Context) IdentityApiApiValidateIdentitiesRequest
+
+	/*
+	 * ValidateIdentitiesExecute executes the request
+	 * @return ValidateIdentitiesResponse
+	 */
+	ValidateIdentitiesExecute(r IdentityApiApiValidateIdentitiesRequest) (*ValidateIdentitiesResponse, *http.Response, error)
 }
 
 // IdentityApiService IdentityApi service
@@ -1486,6 +1543,155 @@ func (a *IdentityApiService) DisableSessionExecute(r IdentityApiApiDisableSessio
 	return localVarHTTPResponse, nil
 }
 
+type IdentityApiApiExportIdentityRequest struct {
+	ctx        context.Context
+	ApiService IdentityApi
+	id         string
+}
+
+func (r IdentityApiApiExportIdentityRequest) Execute() (*IdentityExport, *http.Response, error) {
+	return r.ApiService.ExportIdentityExecute(r)
+}
+
+/*
+  - ExportIdentity Export all Data of an Identity
+  - This endpoint returns everything Ory Kratos stores about an identity in a single JSON document, for example
+
+to answer data subject access requests. The export contains the identity's traits, metadata, verifiable and
+recovery addresses, the public parts of its credentials, and its active sessions. Credential secrets such as
+password hashes are excluded.
+  - @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+  - @param id ID is the identity's ID.
+  - @return IdentityApiApiExportIdentityRequest
+*/
+func (a *IdentityApiService) ExportIdentity(ctx context.Context, id string) IdentityApiApiExportIdentityRequest {
+	return IdentityApiApiExportIdentityRequest{
+		ApiService: a,
+		ctx:        ctx,
+		id:         id,
+	}
+}
+
+/*
+ * Execute executes the request
+ * @return IdentityExport
+ */
+func (a *IdentityApiService) ExportIdentityExecute(r IdentityApiApiExportIdentityRequest) (*IdentityExport, *http.Response, error) {
+	var (
+		localVarHTTPMethod   = http.MethodGet
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+		localVarReturnValue  *IdentityExport
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "IdentityApiService.ExportIdentity")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/admin/identities/{id}/export"
+	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterToString(r.id, "")), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if r.ctx != nil {
+		// API Key Authentication
+		if auth, ok := r.ctx.Value(ContextAPIKeys).(map[string]APIKey); ok {
+			if apiKey, ok := auth["oryAccessToken"]; ok {
+				var key string
+				if apiKey.Prefix != "" {
+					key = apiKey.Prefix + " " + apiKey.Key
+				} else {
+					key = apiKey.Key
+				}
+				localVarHeaderParams["Authorization"] = key
+			}
+		}
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(io.LimitReader(localVarHTTPResponse.Body, 1024*1024))
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v ErrorGeneric
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 404 {
+			var v ErrorGeneric
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		var v ErrorGeneric
+		err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+		if err != nil {
+			newErr.error = err.Error()
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		newErr.model = v
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
 type IdentityApiApiExtendSessionRequest struct {
 	ctx        context.Context
 	ApiService IdentityApi
@@ -2087,6 +2293,8 @@ type IdentityApiApiListIdentitiesRequest struct {
 	credentialsIdentifier               *string
 	previewCredentialsIdentifierSimilar *string
 	includeCredential                   *[]string
+	credentialType                      *string
+	has                                 *bool
 }
 
 func (r IdentityApiApiListIdentitiesRequest) PerPage(perPage int64) IdentityApiApiListIdentitiesRequest {
@@ -2125,6 +2333,14 @@ func (r IdentityApiApiListIdentitiesRequest) IncludeCredential(includeCredential
 	r.includeCredential = &includeCredential
 	return r
 }
+func (r IdentityApiApiListIdentitiesRequest) CredentialType(credentialType string) IdentityApiApiListIdentitiesRequest {
+	r.credentialType = &credentialType
+	return r
+}
+func (r IdentityApiApiListIdentitiesRequest) Has(has bool) IdentityApiApiListIdentitiesRequest {
+	r.has = &has
+	return r
+}
 
 func (r IdentityApiApiListIdentitiesRequest) Execute() ([]Identity, *http.Response, error) {
 	return r.ApiService.ListIdentitiesExecute(r)
@@ -2211,6 +2427,12 @@ func (a *IdentityApiService) ListIdentitiesExecute(r IdentityApiApiListIdentitie
 			localVarQueryParams.Add("include_credential", parameterToString(t, "multi"))
 		}
 	}
+	if r.credentialType != nil {
+		localVarQueryParams.Add("credential_type", parameterToString(*r.credentialType, ""))
+	}
+	if r.has != nil {
+		localVarQueryParams.Add("has", parameterToString(*r.has, ""))
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -3118,3 +3340,338 @@ func (a *IdentityApiService) UpdateIdentityExecute(r IdentityApiApiUpdateIdentit
 
 	return localVarReturnValue, localVarHTTPResponse, nil
 }
+
+type IdentityApiApiUpdateIdentityCredentialIdentifiersRequest struct {
+	ctx                                     context.Context
+	ApiService                              IdentityApi
+	id                                      string
+	type_                                   string
+	updateIdentityCredentialIdentifiersBody *UpdateIdentityCredentialIdentifiersBody
+}
+
+func (r IdentityApiApiUpdateIdentityCredentialIdentifiersRequest) UpdateIdentityCredentialIdentifiersBody(updateIdentityCredentialIdentifiersBody UpdateIdentityCredentialIdentifiersBody) IdentityApiApiUpdateIdentityCredentialIdentifiersRequest {
+	r.updateIdentityCredentialIdentifiersBody = &updateIdentityCredentialIdentifiersBody
+	return r
+}
+
+func (r IdentityApiApiUpdateIdentityCredentialIdentifiersRequest) Execute() (*Identity, *http.Response, error) {
+	return r.ApiService.UpdateIdentityCredentialIdentifiersExecute(r)
+}
+
+/*
+  - UpdateIdentityCredentialIdentifiers Update the Identifiers of an Identity's Credential
+  - Replaces identifiers of an [identity](https://www.ory.sh/docs/kratos/concepts/identity-user-model) credential, for
+
+example when the domain of a company email address changes. Traits which the identity schema marks as identifiers
+of this credential type and which hold the old identifier are updated in the same transaction. The identity's
+sessions are kept. Verifiable addresses which are rotated keep their verification state.
+
+This endpoint returns 409 if a new identifier is already used by another identity.
+  - @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+  - @param id ID is the identity's ID.
+  - @param type_ Type is the type of credentials whose identifiers are updated. password CredentialsTypePassword oidc CredentialsTypeOIDC totp CredentialsTypeTOTP lookup_secret CredentialsTypeLookup webauthn CredentialsTypeWebAuthn code CredentialsTypeCodeAuth passkey CredentialsTypePasskey profile CredentialsTypeProfile link_recovery CredentialsTypeRecoveryLink  CredentialsTypeRecoveryLink is a special credential type linked to the link strategy (recovery flow).  It is not used within the credentials object itself. code_recovery CredentialsTypeRecoveryCode
+  - @return IdentityApiApiUpdateIdentityCredentialIdentifiersRequest
+*/
+func (a *IdentityApiService) UpdateIdentityCredentialIdentifiers(ctx context.Context, id string, type_ string) IdentityApiApiUpdateIdentityCredentialIdentifiersRequest {
+	return IdentityApiApiUpdateIdentityCredentialIdentifiersRequest{
+		ApiService: a,
+		ctx:        ctx,
+		id:         id,
+		type_:      type_,
+	}
+}
+
+/*
+ * Execute executes the request
+ * @return Identity
+ */
+func (a *IdentityApiService) UpdateIdentityCredentialIdentifiersExecute(r IdentityApiApiUpdateIdentityCredentialIdentifiersRequest) (*Identity, *http.Response, error) {
+	var (
+		localVarHTTPMethod   = http.MethodPatch
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+		localVarReturnValue  *Identity
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "IdentityApiService.UpdateIdentityCredentialIdentifiers")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/admin/identities/{id}/credentials/{type}/identifiers"
+	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterToString(r.id, "")), -1)
+	localVarPath = strings.Replace(localVarPath, "{"+"type"+"}", url.PathEscape(parameterToString(r.type_, "")), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+	if r.updateIdentityCredentialIdentifiersBody == nil {
+		return localVarReturnValue, nil, reportError("updateIdentityCredentialIdentifiersBody is required and must be specified")
+	}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{"application/json"}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	// body params
+	localVarPostBody = r.updateIdentityCredentialIdentifiersBody
+	if r.ctx != nil {
+		// API Key Authentication
+		if auth, ok := r.ctx.Value(ContextAPIKeys).(map[string]APIKey); ok {
+			if apiKey, ok := auth["oryAccessToken"]; ok {
+				var key string
+				if apiKey.Prefix != "" {
+					key = apiKey.Prefix + " " + apiKey.Key
+				} else {
+					key = apiKey.Key
+				}
+				localVarHeaderParams["Authorization"] = key
+			}
+		}
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(io.LimitReader(localVarHTTPResponse.Body, 1024*1024))
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v ErrorGeneric
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 404 {
+			var v ErrorGeneric
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 409 {
+			var v ErrorGeneric
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		var v ErrorGeneric
+		err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+		if err != nil {
+			newErr.error = err.Error()
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		newErr.model = v
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
+type IdentityApiApiValidateIdentitiesRequest struct {
+	ctx                    context.Context
+	ApiService             IdentityApi
+	validateIdentitiesBody *ValidateIdentitiesBody
+}
+
+func (r IdentityApiApiValidateIdentitiesRequest) ValidateIdentitiesBody(validateIdentitiesBody ValidateIdentitiesBody) IdentityApiApiValidateIdentitiesRequest {
+	r.validateIdentitiesBody = &validateIdentitiesBody
+	return r
+}
+
+func (r IdentityApiApiValidateIdentitiesRequest) Execute() (*ValidateIdentitiesResponse, *http.Response, error) {
+	return r.ApiService.ValidateIdentitiesExecute(r)
+}
+
+/*
+  - ValidateIdentities Validate Identities Against Their Current Schema
+  - Validates the traits of one or more
+
+[identities](https://www.ory.sh/docs/kratos/concepts/identity-user-model)
+against their current identity schema and reports all violations. This is
+useful to plan migrations after an identity schema changed, for example when
+a trait became required. The identities are not modified.
+  - @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+  - @return IdentityApiApiValidateIdentitiesRequest
+*/
+func (a *IdentityApiService) ValidateIdentities(ctx context.Context) IdentityApiApiValidateIdentitiesRequest {
+	return IdentityApiApiValidateIdentitiesRequest{
+		ApiService: a,
+		ctx:        ctx,
+	}
+}
+
+/*
+ * Execute executes the request
+ * @return ValidateIdentitiesResponse
+ */
+func (a *IdentityApiService) ValidateIdentitiesExecute(r IdentityApiApiValidateIdentitiesRequest) (*ValidateIdentitiesResponse, *http.Response, error) {
+	var (
+		localVarHTTPMethod   = http.MethodPost
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+		localVarReturnValue  *ValidateIdentitiesResponse
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "IdentityApiService.ValidateIdentities")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/admin/identities/validate"
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+	if r.validateIdentitiesBody == nil {
+		return localVarReturnValue, nil, reportError("validateIdentitiesBody is required and must be specified")
+	}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{"application/json"}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	// body params
+	localVarPostBody = r.validateIdentitiesBody
+	if r.ctx != nil {
+		// API Key Authentication
+		if auth, ok := r.ctx.Value(ContextAPIKeys).(map[string]APIKey); ok {
+			if apiKey, ok := auth["oryAccessToken"]; ok {
+				var key string
+				if apiKey.Prefix != "" {
+					key = apiKey.Prefix + " " + apiKey.Key
+				} else {
+					key = apiKey.Key
+				}
+				localVarHeaderParams["Authorization"] = key
+			}
+		}
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(io.LimitReader(localVarHTTPResponse.Body, 1024*1024))
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v ErrorGeneric
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 404 {
+			var v ErrorGeneric
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		var v ErrorGeneric
+		err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+		if err != nil {
+			newErr.error = err.Error()
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		newErr.model = v
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
@@ -0,0 +1,138 @@
+/*
+ * Ory Identities API
+ *
+ * This is the API specification for Ory Identities with features such as registration, login, recovery, account verification, profile settings, password reset, identity management, session management, email and sms delivery, and more.
+ *
+ * API version:
+ * Contact: office@ory.sh
+ */
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package client
+
+import (
+	"encoding/json"
+)
+
+// IdentifierReplacement Identifier Replacement
+type IdentifierReplacement struct {
+	// From is the identifier to replace.
+	From string `json:"from"`
+	// To is the new identifier.
+	To string `json:"to"`
+}
+
+// NewIdentifierReplacement instantiates a new IdentifierReplacement object
+// This constructor will assign default values to properties that have it defined,
+// and makes sure properties required by API are set, but the set of arguments
+// will change when the set of required properties is changed
+func NewIdentifierReplacement(from string, to string) *IdentifierReplacement {
+	this := IdentifierReplacement{}
+	this.From = from
+	this.To = to
+	return &this
+}
+
+// NewIdentifierReplacementWithDefaults instantiates a new IdentifierReplacement object
+// This constructor will only assign default values to properties that have it defined,
+// but it doesn't guarantee that properties required by API are set
+func NewIdentifierReplacementWithDefaults() *IdentifierReplacement {
+	this := IdentifierReplacement{}
+	return &this
+}
+
+// GetFrom returns the From field value
+func (o *IdentifierReplacement) GetFrom() string {
+	if o == nil {
+		var ret string
+		return ret
+	}
+
+	return o.From
+}
+
+// GetFromOk returns a tuple with the From field value
+// and a boolean to check if the value has been set.
+func (o *IdentifierReplacement) GetFromOk() (*string, bool) {
+	if o == nil {
+		return nil, false
+	}
+	return &o.From, true
+}
+
+// SetFrom sets field value
+func (o *IdentifierReplacement) SetFrom(v string) {
+	o.From = v
+}
+
+// GetTo returns the To field value
+func (o *IdentifierReplacement) GetTo() string {
+	if o == nil {
+		var ret string
+		return ret
+	}
+
+	return o.To
+}
+
+// GetToOk returns a tuple with the To field value
+// and a boolean to check if the value has been set.
+func (o *IdentifierReplacement) GetToOk() (*string, bool) {
+	if o == nil {
+		return nil, false
+	}
+	return &o.To, true
+}
+
+// SetTo sets field value
+func (o *IdentifierReplacement) SetTo(v string) {
+	o.To = v
+}
+
+func (o IdentifierReplacement) MarshalJSON() ([]byte, error) {
+	toSerialize := map[string]interface{}{}
+	if true {
+		toSerialize["from"] = o.From
+	}
+	if true {
+		toSerialize["to"] = o.To
+	}
+	return json.Marshal(toSerialize)
+}
+
+type NullableIdentifierReplacement struct {
+	value *IdentifierReplacement
+	isSet bool
+}
+
+func (v NullableIdentifierReplacement) Get() *IdentifierReplacement {
+	return v.value
+}
+
+func (v *NullableIdentifierReplacement) Set(val *IdentifierReplacement) {
+	v.value = val
+	v.isSet = true
+}
+
+func (v NullableIdentifierReplacement) IsSet() bool {
+	return v.isSet
+}
+
+func (v *NullableIdentifierReplacement) Unset() {
+	v.value = nil
+	v.isSet = false
+}
+
+func NewNullableIdentifierReplacement(val *IdentifierReplacement) *NullableIdentifierReplacement {
+	return &NullableIdentifierReplacement{value: val, isSet: true}
+}
+
+func (v NullableIdentifierReplacement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.value)
+}
+
+func (v *NullableIdentifierReplacement) UnmarshalJSON(src []byte) error {
+	v.isSet = true
+	return json.Unmarshal(src, &v.value)
+}
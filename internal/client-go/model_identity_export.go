@@ -0,0 +1,137 @@
+/*
+ * Ory Identities API
+ *
+ * This is the API specification for Ory Identities with features such as registration, login, recovery, account verification, profile settings, password reset, identity management, session management, email and sms delivery, and more.
+ *
+ * API version:
+ * Contact: office@ory.sh
+ */
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package client
+
+import (
+	"encoding/json"
+)
+
+// IdentityExport Contains all data Ory Kratos stores about an identity. Credential secrets such as password hashes are never part of the export.
+type IdentityExport struct {
+	Identity Identity `json:"identity"`
+	// Sessions contains the identity's active sessions.
+	Sessions []Session `json:"sessions"`
+}
+
+// NewIdentityExport instantiates a new IdentityExport object
+// This constructor will assign default values to properties that have it defined,
+// and makes sure properties required by API are set, but the set of arguments
+// will change when the set of required properties is changed
+func NewIdentityExport(identity Identity, sessions []Session) *IdentityExport {
+	this := IdentityExport{}
+	this.Identity = identity
+	this.Sessions = sessions
+	return &this
+}
+
+// NewIdentityExportWithDefaults instantiates a new IdentityExport object
+// This constructor will only assign default values to properties that have it defined,
+// but it doesn't guarantee that properties required by API are set
+func NewIdentityExportWithDefaults() *IdentityExport {
+	this := IdentityExport{}
+	return &this
+}
+
+// GetIdentity returns the Identity field value
+func (o *IdentityExport) GetIdentity() Identity {
+	if o == nil {
+		var ret Identity
+		return ret
+	}
+
+	return o.Identity
+}
+
+// GetIdentityOk returns a tuple with the Identity field value
+// and a boolean to check if the value has been set.
+func (o *IdentityExport) GetIdentityOk() (*Identity, bool) {
+	if o == nil {
+		return nil, false
+	}
+	return &o.Identity, true
+}
+
+// SetIdentity sets field value
+func (o *IdentityExport) SetIdentity(v Identity) {
+	o.Identity = v
+}
+
+// GetSessions returns the Sessions field value
+func (o *IdentityExport) GetSessions() []Session {
+	if o == nil {
+		var ret []Session
+		return ret
+	}
+
+	return o.Sessions
+}
+
+// GetSessionsOk returns a tuple with the Sessions field value
+// and a boolean to check if the value has been set.
+func (o *IdentityExport) GetSessionsOk() ([]Session, bool) {
+	if o == nil {
+		return nil, false
+	}
+	return o.Sessions, true
+}
+
+// SetSessions sets field value
+func (o *IdentityExport) SetSessions(v []Session) {
+	o.Sessions = v
+}
+
+func (o IdentityExport) MarshalJSON() ([]byte, error) {
+	toSerialize := map[string]interface{}{}
+	if true {
+		toSerialize["identity"] = o.Identity
+	}
+	if true {
+		toSerialize["sessions"] = o.Sessions
+	}
+	return json.Marshal(toSerialize)
+}
+
+type NullableIdentityExport struct {
+	value *IdentityExport
+	isSet bool
+}
+
+func (v NullableIdentityExport) Get() *IdentityExport {
+	return v.value
+}
+
+func (v *NullableIdentityExport) Set(val *IdentityExport) {
+	v.value = val
+	v.isSet = true
+}
+
+func (v NullableIdentityExport) IsSet() bool {
+	return v.isSet
+}
+
+func (v *NullableIdentityExport) Unset() {
+	v.value = nil
+	v.isSet = false
+}
+
+func NewNullableIdentityExport(val *IdentityExport) *NullableIdentityExport {
+	return &NullableIdentityExport{value: val, isSet: true}
+}
+
+func (v NullableIdentityExport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.value)
+}
+
+func (v *NullableIdentityExport) UnmarshalJSON(src []byte) error {
+	v.isSet = true
+	return json.Unmarshal(src, &v.value)
+}
@@ -26,6 +26,7 @@ type Message struct {
 	Dispatches []MessageDispatch    `json:"dispatches,omitempty"`
 	Id         string               `json:"id"`
 	Recipient  string               `json:"recipient"`
+	SendAt     *time.Time           `json:"send_at,omitempty"`
 	SendCount  int64                `json:"send_count"`
 	Status     CourierMessageStatus `json:"status"`
 	Subject    string               `json:"subject"`
@@ -223,6 +224,38 @@ func (o *Message) SetRecipient(v string) {
 	o.Recipient = v
 }
 
+// GetSendAt returns the SendAt field value if set, zero value otherwise.
+func (o *Message) GetSendAt() time.Time {
+	if o == nil || o.SendAt == nil {
+		var ret time.Time
+		return ret
+	}
+	return *o.SendAt
+}
+
+// GetSendAtOk returns a tuple with the SendAt field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *Message) GetSendAtOk() (*time.Time, bool) {
+	if o == nil || o.SendAt == nil {
+		return nil, false
+	}
+	return o.SendAt, true
+}
+
+// HasSendAt returns a boolean if a field has been set.
+func (o *Message) HasSendAt() bool {
+	if o != nil && o.SendAt != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetSendAt gets a reference to the given time.Time and assigns it to the SendAt field.
+func (o *Message) SetSendAt(v time.Time) {
+	o.SendAt = &v
+}
+
 // GetSendCount returns the SendCount field value
 func (o *Message) GetSendCount() int64 {
 	if o == nil {
@@ -387,6 +420,9 @@ func (o Message) MarshalJSON() ([]byte, error) {
 	if true {
 		toSerialize["recipient"] = o.Recipient
 	}
+	if o.SendAt != nil {
+		toSerialize["send_at"] = o.SendAt
+	}
 	if true {
 		toSerialize["send_count"] = o.SendCount
 	}
@@ -0,0 +1,138 @@
+/*
+ * Ory Identities API
+ *
+ * This is the API specification for Ory Identities with features such as registration, login, recovery, account verification, profile settings, password reset, identity management, session management, email and sms delivery, and more.
+ *
+ * API version:
+ * Contact: office@ory.sh
+ */
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package client
+
+import (
+	"encoding/json"
+)
+
+// OidcLink A linked OpenID Connect provider
+type OidcLink struct {
+	// The ID of the linked provider.
+	Provider string `json:"provider"`
+	// The subject of the identity at the linked provider.
+	Subject string `json:"subject"`
+}
+
+// NewOidcLink instantiates a new OidcLink object
+// This constructor will assign default values to properties that have it defined,
+// and makes sure properties required by API are set, but the set of arguments
+// will change when the set of required properties is changed
+func NewOidcLink(provider string, subject string) *OidcLink {
+	this := OidcLink{}
+	this.Provider = provider
+	this.Subject = subject
+	return &this
+}
+
+// NewOidcLinkWithDefaults instantiates a new OidcLink object
+// This constructor will only assign default values to properties that have it defined,
+// but it doesn't guarantee that properties required by API are set
+func NewOidcLinkWithDefaults() *OidcLink {
+	this := OidcLink{}
+	return &this
+}
+
+// GetProvider returns the Provider field value
+func (o *OidcLink) GetProvider() string {
+	if o == nil {
+		var ret string
+		return ret
+	}
+
+	return o.Provider
+}
+
+// GetProviderOk returns a tuple with the Provider field value
+// and a boolean to check if the value has been set.
+func (o *OidcLink) GetProviderOk() (*string, bool) {
+	if o == nil {
+		return nil, false
+	}
+	return &o.Provider, true
+}
+
+// SetProvider sets field value
+func (o *OidcLink) SetProvider(v string) {
+	o.Provider = v
+}
+
+// GetSubject returns the Subject field value
+func (o *OidcLink) GetSubject() string {
+	if o == nil {
+		var ret string
+		return ret
+	}
+
+	return o.Subject
+}
+
+// GetSubjectOk returns a tuple with the Subject field value
+// and a boolean to check if the value has been set.
+func (o *OidcLink) GetSubjectOk() (*string, bool) {
+	if o == nil {
+		return nil, false
+	}
+	return &o.Subject, true
+}
+
+// SetSubject sets field value
+func (o *OidcLink) SetSubject(v string) {
+	o.Subject = v
+}
+
+func (o OidcLink) MarshalJSON() ([]byte, error) {
+	toSerialize := map[string]interface{}{}
+	if true {
+		toSerialize["provider"] = o.Provider
+	}
+	if true {
+		toSerialize["subject"] = o.Subject
+	}
+	return json.Marshal(toSerialize)
+}
+
+type NullableOidcLink struct {
+	value *OidcLink
+	isSet bool
+}
+
+func (v NullableOidcLink) Get() *OidcLink {
+	return v.value
+}
+
+func (v *NullableOidcLink) Set(val *OidcLink) {
+	v.value = val
+	v.isSet = true
+}
+
+func (v NullableOidcLink) IsSet() bool {
+	return v.isSet
+}
+
+func (v *NullableOidcLink) Unset() {
+	v.value = nil
+	v.isSet = false
+}
+
+func NewNullableOidcLink(val *OidcLink) *NullableOidcLink {
+	return &NullableOidcLink{value: val, isSet: true}
+}
+
+func (v NullableOidcLink) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.value)
+}
+
+func (v *NullableOidcLink) UnmarshalJSON(src []byte) error {
+	v.isSet = true
+	return json.Unmarshal(src, &v.value)
+}
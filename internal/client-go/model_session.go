@@ -36,6 +36,8 @@ type Session struct {
 	IssuedAt *time.Time `json:"issued_at,omitempty"`
 	// Tokenized is the tokenized (e.g. JWT) version of the session.  It is only set when the `tokenize` query parameter was set to a valid tokenize template during calls to `/session/whoami`.
 	Tokenized *string `json:"tokenized,omitempty"`
+	// VerificationRequired is true if one of the identity's addresses was verified longer ago than `selfservice.flows.verification.revalidate_after` and needs to be verified again.  It is only set during calls to `/sessions/whoami`.
+	VerificationRequired *bool `json:"verification_required,omitempty"`
 }
 
 // NewSession instantiates a new Session object
@@ -368,6 +370,38 @@ func (o *Session) SetTokenized(v string) {
 	o.Tokenized = &v
 }
 
+// GetVerificationRequired returns the VerificationRequired field value if set, zero value otherwise.
+func (o *Session) GetVerificationRequired() bool {
+	if o == nil || o.VerificationRequired == nil {
+		var ret bool
+		return ret
+	}
+	return *o.VerificationRequired
+}
+
+// GetVerificationRequiredOk returns a tuple with the VerificationRequired field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *Session) GetVerificationRequiredOk() (*bool, bool) {
+	if o == nil || o.VerificationRequired == nil {
+		return nil, false
+	}
+	return o.VerificationRequired, true
+}
+
+// HasVerificationRequired returns a boolean if a field has been set.
+func (o *Session) HasVerificationRequired() bool {
+	if o != nil && o.VerificationRequired != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetVerificationRequired gets a reference to the given bool and assigns it to the VerificationRequired field.
+func (o *Session) SetVerificationRequired(v bool) {
+	o.VerificationRequired = &v
+}
+
 func (o Session) MarshalJSON() ([]byte, error) {
 	toSerialize := map[string]interface{}{}
 	if o.Active != nil {
@@ -400,6 +434,9 @@ func (o Session) MarshalJSON() ([]byte, error) {
 	if o.Tokenized != nil {
 		toSerialize["tokenized"] = o.Tokenized
 	}
+	if o.VerificationRequired != nil {
+		toSerialize["verification_required"] = o.VerificationRequired
+	}
 	return json.Marshal(toSerialize)
 }
 
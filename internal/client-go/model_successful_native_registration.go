@@ -23,6 +23,10 @@ type SuccessfulNativeRegistration struct {
 	Session      *Session       `json:"session,omitempty"`
 	// The Session Token  This field is only set when the session hook is configured as a post-registration hook.  A session token is equivalent to a session cookie, but it can be sent in the HTTP Authorization Header:  Authorization: bearer ${session-token}  The session token is only issued for API flows, not for Browser flows!
 	SessionToken *string `json:"session_token,omitempty"`
+	// RecoveryAddresses contains the recovery addresses of the created identity.  This field is only set when `selfservice.flows.registration.include_addresses` is enabled.
+	RecoveryAddresses []RecoveryIdentityAddress `json:"recovery_addresses,omitempty"`
+	// VerifiableAddresses contains the verifiable addresses of the created identity.  This field is only set when `selfservice.flows.registration.include_addresses` is enabled.
+	VerifiableAddresses []VerifiableIdentityAddress `json:"verifiable_addresses,omitempty"`
 }
 
 // NewSuccessfulNativeRegistration instantiates a new SuccessfulNativeRegistration object
@@ -163,6 +167,70 @@ func (o *SuccessfulNativeRegistration) SetSessionToken(v string) {
 	o.SessionToken = &v
 }
 
+// GetRecoveryAddresses returns the RecoveryAddresses field value if set, zero value otherwise.
+func (o *SuccessfulNativeRegistration) GetRecoveryAddresses() []RecoveryIdentityAddress {
+	if o == nil || o.RecoveryAddresses == nil {
+		var ret []RecoveryIdentityAddress
+		return ret
+	}
+	return o.RecoveryAddresses
+}
+
+// GetRecoveryAddressesOk returns a tuple with the RecoveryAddresses field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *SuccessfulNativeRegistration) GetRecoveryAddressesOk() ([]RecoveryIdentityAddress, bool) {
+	if o == nil || o.RecoveryAddresses == nil {
+		return nil, false
+	}
+	return o.RecoveryAddresses, true
+}
+
+// HasRecoveryAddresses returns a boolean if a field has been set.
+func (o *SuccessfulNativeRegistration) HasRecoveryAddresses() bool {
+	if o != nil && o.RecoveryAddresses != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetRecoveryAddresses gets a reference to the given []RecoveryIdentityAddress and assigns it to the RecoveryAddresses field.
+func (o *SuccessfulNativeRegistration) SetRecoveryAddresses(v []RecoveryIdentityAddress) {
+	o.RecoveryAddresses = v
+}
+
+// GetVerifiableAddresses returns the VerifiableAddresses field value if set, zero value otherwise.
+func (o *SuccessfulNativeRegistration) GetVerifiableAddresses() []VerifiableIdentityAddress {
+	if o == nil || o.VerifiableAddresses == nil {
+		var ret []VerifiableIdentityAddress
+		return ret
+	}
+	return o.VerifiableAddresses
+}
+
+// GetVerifiableAddressesOk returns a tuple with the VerifiableAddresses field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *SuccessfulNativeRegistration) GetVerifiableAddressesOk() ([]VerifiableIdentityAddress, bool) {
+	if o == nil || o.VerifiableAddresses == nil {
+		return nil, false
+	}
+	return o.VerifiableAddresses, true
+}
+
+// HasVerifiableAddresses returns a boolean if a field has been set.
+func (o *SuccessfulNativeRegistration) HasVerifiableAddresses() bool {
+	if o != nil && o.VerifiableAddresses != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetVerifiableAddresses gets a reference to the given []VerifiableIdentityAddress and assigns it to the VerifiableAddresses field.
+func (o *SuccessfulNativeRegistration) SetVerifiableAddresses(v []VerifiableIdentityAddress) {
+	o.VerifiableAddresses = v
+}
+
 func (o SuccessfulNativeRegistration) MarshalJSON() ([]byte, error) {
 	toSerialize := map[string]interface{}{}
 	if o.ContinueWith != nil {
@@ -177,6 +245,12 @@ func (o SuccessfulNativeRegistration) MarshalJSON() ([]byte, error) {
 	if o.SessionToken != nil {
 		toSerialize["session_token"] = o.SessionToken
 	}
+	if o.RecoveryAddresses != nil {
+		toSerialize["recovery_addresses"] = o.RecoveryAddresses
+	}
+	if o.VerifiableAddresses != nil {
+		toSerialize["verifiable_addresses"] = o.VerifiableAddresses
+	}
 	return json.Marshal(toSerialize)
 }
 
@@ -18,8 +18,12 @@ import (
 // UiContainer Container represents a HTML Form. The container can work with both HTTP Form and JSON requests
 type UiContainer struct {
 	// Action should be used as the form action URL `<form action=\"{{ .Action }}\" method=\"post\">`.
-	Action   string   `json:"action"`
-	Messages []UiText `json:"messages,omitempty"`
+	Action        string   `json:"action"`
+	ErrorMessages []UiText `json:"error_messages,omitempty"`
+	InfoMessages  []UiText `json:"info_messages,omitempty"`
+	Messages      []UiText `json:"messages,omitempty"`
+	// Meta contains optional paging information for node groups, keyed by the node group.  It allows UIs to lazy-render groups with many nodes.
+	Meta *map[string]UiContainerGroupMeta `json:"meta,omitempty"`
 	// Method is the form method (e.g. POST)
 	Method string   `json:"method"`
 	Nodes  []UiNode `json:"nodes"`
@@ -69,6 +73,70 @@ func (o *UiContainer) SetAction(v string) {
 	o.Action = v
 }
 
+// GetErrorMessages returns the ErrorMessages field value if set, zero value otherwise.
+func (o *UiContainer) GetErrorMessages() []UiText {
+	if o == nil || o.ErrorMessages == nil {
+		var ret []UiText
+		return ret
+	}
+	return o.ErrorMessages
+}
+
+// GetErrorMessagesOk returns a tuple with the ErrorMessages field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *UiContainer) GetErrorMessagesOk() ([]UiText, bool) {
+	if o == nil || o.ErrorMessages == nil {
+		return nil, false
+	}
+	return o.ErrorMessages, true
+}
+
+// HasErrorMessages returns a boolean if a field has been set.
+func (o *UiContainer) HasErrorMessages() bool {
+	if o != nil && o.ErrorMessages != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetErrorMessages gets a reference to the given []UiText and assigns it to the ErrorMessages field.
+func (o *UiContainer) SetErrorMessages(v []UiText) {
+	o.ErrorMessages = v
+}
+
+// GetInfoMessages returns the InfoMessages field value if set, zero value otherwise.
+func (o *UiContainer) GetInfoMessages() []UiText {
+	if o == nil || o.InfoMessages == nil {
+		var ret []UiText
+		return ret
+	}
+	return o.InfoMessages
+}
+
+// GetInfoMessagesOk returns a tuple with the InfoMessages field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *UiContainer) GetInfoMessagesOk() ([]UiText, bool) {
+	if o == nil || o.InfoMessages == nil {
+		return nil, false
+	}
+	return o.InfoMessages, true
+}
+
+// HasInfoMessages returns a boolean if a field has been set.
+func (o *UiContainer) HasInfoMessages() bool {
+	if o != nil && o.InfoMessages != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetInfoMessages gets a reference to the given []UiText and assigns it to the InfoMessages field.
+func (o *UiContainer) SetInfoMessages(v []UiText) {
+	o.InfoMessages = v
+}
+
 // GetMessages returns the Messages field value if set, zero value otherwise.
 func (o *UiContainer) GetMessages() []UiText {
 	if o == nil || o.Messages == nil {
@@ -101,6 +169,38 @@ func (o *UiContainer) SetMessages(v []UiText) {
 	o.Messages = v
 }
 
+// GetMeta returns the Meta field value if set, zero value otherwise.
+func (o *UiContainer) GetMeta() map[string]UiContainerGroupMeta {
+	if o == nil || o.Meta == nil {
+		var ret map[string]UiContainerGroupMeta
+		return ret
+	}
+	return *o.Meta
+}
+
+// GetMetaOk returns a tuple with the Meta field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *UiContainer) GetMetaOk() (*map[string]UiContainerGroupMeta, bool) {
+	if o == nil || o.Meta == nil {
+		return nil, false
+	}
+	return o.Meta, true
+}
+
+// HasMeta returns a boolean if a field has been set.
+func (o *UiContainer) HasMeta() bool {
+	if o != nil && o.Meta != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetMeta gets a reference to the given map[string]UiContainerGroupMeta and assigns it to the Meta field.
+func (o *UiContainer) SetMeta(v map[string]UiContainerGroupMeta) {
+	o.Meta = &v
+}
+
 // GetMethod returns the Method field value
 func (o *UiContainer) GetMethod() string {
 	if o == nil {
@@ -154,9 +254,18 @@ func (o UiContainer) MarshalJSON() ([]byte, error) {
 	if true {
 		toSerialize["action"] = o.Action
 	}
+	if o.ErrorMessages != nil {
+		toSerialize["error_messages"] = o.ErrorMessages
+	}
+	if o.InfoMessages != nil {
+		toSerialize["info_messages"] = o.InfoMessages
+	}
 	if o.Messages != nil {
 		toSerialize["messages"] = o.Messages
 	}
+	if o.Meta != nil {
+		toSerialize["meta"] = o.Meta
+	}
 	if true {
 		toSerialize["method"] = o.Method
 	}
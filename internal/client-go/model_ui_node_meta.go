@@ -17,7 +17,8 @@ import (
 
 // UiNodeMeta This might include a label and other information that can optionally be used to render UIs.
 type UiNodeMeta struct {
-	Label *UiText `json:"label,omitempty"`
+	Label          *UiText               `json:"label,omitempty"`
+	PasswordPolicy *UiNodePasswordPolicy `json:"password_policy,omitempty"`
 }
 
 // NewUiNodeMeta instantiates a new UiNodeMeta object
@@ -69,11 +70,46 @@ func (o *UiNodeMeta) SetLabel(v UiText) {
 	o.Label = &v
 }
 
+// GetPasswordPolicy returns the PasswordPolicy field value if set, zero value otherwise.
+func (o *UiNodeMeta) GetPasswordPolicy() UiNodePasswordPolicy {
+	if o == nil || o.PasswordPolicy == nil {
+		var ret UiNodePasswordPolicy
+		return ret
+	}
+	return *o.PasswordPolicy
+}
+
+// GetPasswordPolicyOk returns a tuple with the PasswordPolicy field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *UiNodeMeta) GetPasswordPolicyOk() (*UiNodePasswordPolicy, bool) {
+	if o == nil || o.PasswordPolicy == nil {
+		return nil, false
+	}
+	return o.PasswordPolicy, true
+}
+
+// HasPasswordPolicy returns a boolean if a field has been set.
+func (o *UiNodeMeta) HasPasswordPolicy() bool {
+	if o != nil && o.PasswordPolicy != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetPasswordPolicy gets a reference to the given UiNodePasswordPolicy and assigns it to the PasswordPolicy field.
+func (o *UiNodeMeta) SetPasswordPolicy(v UiNodePasswordPolicy) {
+	o.PasswordPolicy = &v
+}
+
 func (o UiNodeMeta) MarshalJSON() ([]byte, error) {
 	toSerialize := map[string]interface{}{}
 	if o.Label != nil {
 		toSerialize["label"] = o.Label
 	}
+	if o.PasswordPolicy != nil {
+		toSerialize["password_policy"] = o.PasswordPolicy
+	}
 	return json.Marshal(toSerialize)
 }
 
@@ -37,10 +37,15 @@ type FrontendApi interface {
 		exists already, the browser will be redirected to `urls.default_redirect_url` unless the query parameter
 		`?refresh=true` was set.
 
+		If the URL query parameter `?prompt=none` is set, no login form is rendered. If a session with the
+		requested AAL exists, the browser is redirected to `return_to` or, for AJAX requests, the response
+		body is that session instead of a login flow.
+
 		If this endpoint is called via an AJAX request, the response contains the flow without a redirect. In the
 		case of an error, the `error.id` of the JSON response body can be one of:
 
 		`session_already_available`: The user is already signed in.
+		`login_required`: `prompt=none` was set but no session with the requested AAL exists.
 		`session_aal1_required`: Multi-factor auth (e.g. 2fa) was requested but the user has no session yet.
 		`security_csrf_violation`: Unable to fetch the flow because a CSRF violation occurred.
 		`security_identity_mismatch`: The requested `?return_to` address is not allowed to be used. Adjust this in the configuration!
@@ -210,6 +215,9 @@ type FrontendApi interface {
 
 		To fetch an existing login flow call `/self-service/login/flows?flow=<flow_id>`.
 
+		If the URL query parameter `?prompt=none` is set, no login form is rendered. If a session with the
+		requested AAL exists, the response body is that session instead of a login flow.
+
 		You MUST NOT use this endpoint in client-side (Single Page Apps, ReactJS, AngularJS) nor server-side (Java Server
 		Pages, NodeJS, PHP, Golang, ...) browser applications. Using this endpoint in these applications will make
 		you vulnerable to a variety of CSRF attacks, including CSRF login attacks.
@@ -217,6 +225,7 @@ type FrontendApi interface {
 		In the case of an error, the `error.id` of the JSON response body can be one of:
 
 		`session_already_available`: The user is already signed in.
+		`login_required`: `prompt=none` was set but no session with the requested AAL exists.
 		`session_aal1_required`: Multi-factor auth (e.g. 2fa) was requested but the user has no session yet.
 		`security_csrf_violation`: Unable to fetch the flow because a CSRF violation occurred.
 
@@ -602,6 +611,20 @@ type FrontendApi interface {
 	 */
 	GetWebAuthnJavaScriptExecute(r FrontendApiApiGetWebAuthnJavaScriptRequest) (string, *http.Response, error)
 
+	/*
+	 * ListMyOidcLinks List my linked OpenID Connect providers
+	 * This endpoint returns the OpenID Connect providers linked to the identity of the current session.
+	 * @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	 * @return FrontendApiApiListMyOidcLinksRequest
+	 */
+	ListMyOidcLinks(ctx context.Context) FrontendApiApiListMyOidcLinksRequest
+
+	/*
+	 * ListMyOidcLinksExecute executes the request
+	 * @return []OidcLink
+	 */
+	ListMyOidcLinksExecute(r FrontendApiApiListMyOidcLinksRequest) ([]OidcLink, *http.Response, error)
+
 	/*
 			 * ListMySessions Get My Active Sessions
 			 * This endpoints returns all other active sessions that belong to the logged-in user.
@@ -637,6 +660,29 @@ type FrontendApi interface {
 	 */
 	PerformNativeLogoutExecute(r FrontendApiApiPerformNativeLogoutRequest) (*http.Response, error)
 
+	/*
+			 * RevokeMyOidcLink Revoke one of my linked OpenID Connect providers
+			 * Calling this endpoint unlinks the provider from the identity of the current session. The link is revoked
+		in a new settings flow, which means that the session must satisfy the AAL required by the settings flow,
+		that the settings hooks are executed, and that a session which is no longer privileged has to re-authenticate
+		first. The link can not be revoked if it is the last remaining first factor credential.
+
+		Browsers must send the anti-CSRF token in the `X-CSRF-Token` header. API clients must authenticate with
+		the `X-Session-Token` header and must not send cookies.
+
+		The response is the completed settings flow. Browsers which do not send `Accept: application/json` are
+		redirected like they are when updating a settings flow.
+			 * @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+			 * @param provider The ID of the linked provider.
+			 * @return FrontendApiApiRevokeMyOidcLinkRequest
+	*/
+	RevokeMyOidcLink(ctx context.Context, provider string) FrontendApiApiRevokeMyOidcLinkRequest
+
+	/*
+	 * RevokeMyOidcLinkExecute executes the request
+	 */
+	RevokeMyOidcLinkExecute(r FrontendApiApiRevokeMyOidcLinkRequest) (*SettingsFlow, *http.Response, error)
+
 	/*
 			 * ToSession Check Who the Current HTTP Session Belongs To
 			 * Uses the HTTP Headers in the GET request to determine (e.g. by using checking the cookies) who is authenticated.
@@ -928,6 +974,26 @@ type FrontendApi interface {
 	 * @return VerificationFlow
 	 */
 	UpdateVerificationFlowExecute(r FrontendApiApiUpdateVerificationFlowRequest) (*VerificationFlow, *http.Response, error)
+
+	/*
+			 * ValidateSession Check if the Current HTTP Session is Valid
+			 * A lightweight alternative to `/sessions/whoami` for reverse proxies and API gateways which only need to know
+		whether a request is authenticated. The identity's traits, credentials, and addresses are neither loaded nor
+		returned. The session is checked the same way as in `/sessions/whoami`, including the required Authenticator
+		Assurance Level.
+
+		When the request it successful it adds the user ID to the 'X-Kratos-Authenticated-Identity-Id' header
+		in the response. If session caching is enabled, the `Ory-Session-Cache-For` header is set as well.
+			 * @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+			 * @return FrontendApiApiValidateSessionRequest
+	*/
+	ValidateSession(ctx context.Context) FrontendApiApiValidateSessionRequest
+
+	/*
+	 * ValidateSessionExecute executes the request
+	 * @return SessionValidity
+	 */
+	ValidateSessionExecute(r FrontendApiApiValidateSessionRequest) (*SessionValidity, *http.Response, error)
 }
 
 // FrontendApiService FrontendApi service
@@ -943,6 +1009,7 @@ type FrontendApiApiCreateBrowserLoginFlowRequest struct {
 	loginChallenge *string
 	organization   *string
 	via            *string
+	prompt         *string
 }
 
 func (r FrontendApiApiCreateBrowserLoginFlowRequest) Refresh(refresh bool) FrontendApiApiCreateBrowserLoginFlowRequest {
@@ -973,6 +1040,10 @@ func (r FrontendApiApiCreateBrowserLoginFlowRequest) Via(via string) FrontendApi
 	r.via = &via
 	return r
 }
+func (r FrontendApiApiCreateBrowserLoginFlowRequest) Prompt(prompt string) FrontendApiApiCreateBrowserLoginFlowRequest {
+	r.prompt = &prompt
+	return r
+}
 
 func (r FrontendApiApiCreateBrowserLoginFlowRequest) Execute() (*LoginFlow, *http.Response, error) {
 	return r.ApiService.CreateBrowserLoginFlowExecute(r)
@@ -989,10 +1060,15 @@ If this endpoint is opened as a link in the browser, it will be redirected to
 exists already, the browser will be redirected to `urls.default_redirect_url` unless the query parameter
 `?refresh=true` was set.
 
+If the URL query parameter `?prompt=none` is set, no login form is rendered. If a session with the
+requested AAL exists, the browser is redirected to `return_to` or, for AJAX requests, the response
+body is that session instead of a login flow.
+
 If this endpoint is called via an AJAX request, the response contains the flow without a redirect. In the
 case of an error, the `error.id` of the JSON response body can be one of:
 
 `session_already_available`: The user is already signed in.
+`login_required`: `prompt=none` was set but no session with the requested AAL exists.
 `session_aal1_required`: Multi-factor auth (e.g. 2fa) was requested but the user has no session yet.
 `security_csrf_violation`: Unable to fetch the flow because a CSRF violation occurred.
 `security_identity_mismatch`: The requested `?return_to` address is not allowed to be used. Adjust this in the configuration!
@@ -1057,6 +1133,9 @@ func (a *FrontendApiService) CreateBrowserLoginFlowExecute(r FrontendApiApiCreat
 	if r.via != nil {
 		localVarQueryParams.Add("via", parameterToString(*r.via, ""))
 	}
+	if r.prompt != nil {
+		localVarQueryParams.Add("prompt", parameterToString(*r.prompt, ""))
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -1899,6 +1978,7 @@ type FrontendApiApiCreateNativeLoginFlowRequest struct {
 	returnSessionTokenExchangeCode *bool
 	returnTo                       *string
 	via                            *string
+	prompt                         *string
 }
 
 func (r FrontendApiApiCreateNativeLoginFlowRequest) Refresh(refresh bool) FrontendApiApiCreateNativeLoginFlowRequest {
@@ -1925,6 +2005,10 @@ func (r FrontendApiApiCreateNativeLoginFlowRequest) Via(via string) FrontendApiA
 	r.via = &via
 	return r
 }
+func (r FrontendApiApiCreateNativeLoginFlowRequest) Prompt(prompt string) FrontendApiApiCreateNativeLoginFlowRequest {
+	r.prompt = &prompt
+	return r
+}
 
 func (r FrontendApiApiCreateNativeLoginFlowRequest) Execute() (*LoginFlow, *http.Response, error) {
 	return r.ApiService.CreateNativeLoginFlowExecute(r)
@@ -1939,6 +2023,9 @@ will be returned unless the URL query parameter `?refresh=true` is set.
 
 To fetch an existing login flow call `/self-service/login/flows?flow=<flow_id>`.
 
+If the URL query parameter `?prompt=none` is set, no login form is rendered. If a session with the
+requested AAL exists, the response body is that session instead of a login flow.
+
 You MUST NOT use this endpoint in client-side (Single Page Apps, ReactJS, AngularJS) nor server-side (Java Server
 Pages, NodeJS, PHP, Golang, ...) browser applications. Using this endpoint in these applications will make
 you vulnerable to a variety of CSRF attacks, including CSRF login attacks.
@@ -1946,6 +2033,7 @@ you vulnerable to a variety of CSRF attacks, including CSRF login attacks.
 In the case of an error, the `error.id` of the JSON response body can be one of:
 
 `session_already_available`: The user is already signed in.
+`login_required`: `prompt=none` was set but no session with the requested AAL exists.
 `session_aal1_required`: Multi-factor auth (e.g. 2fa) was requested but the user has no session yet.
 `security_csrf_violation`: Unable to fetch the flow because a CSRF violation occurred.
 
@@ -2002,6 +2090,9 @@ func (a *FrontendApiService) CreateNativeLoginFlowExecute(r FrontendApiApiCreate
 	if r.via != nil {
 		localVarQueryParams.Add("via", parameterToString(*r.via, ""))
 	}
+	if r.prompt != nil {
+		localVarQueryParams.Add("prompt", parameterToString(*r.prompt, ""))
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -3230,6 +3321,7 @@ type FrontendApiApiGetLoginFlowRequest struct {
 	ApiService FrontendApi
 	id         *string
 	cookie     *string
+	format     *string
 }
 
 func (r FrontendApiApiGetLoginFlowRequest) Id(id string) FrontendApiApiGetLoginFlowRequest {
@@ -3240,6 +3332,10 @@ func (r FrontendApiApiGetLoginFlowRequest) Cookie(cookie string) FrontendApiApiG
 	r.cookie = &cookie
 	return r
 }
+func (r FrontendApiApiGetLoginFlowRequest) Format(format string) FrontendApiApiGetLoginFlowRequest {
+	r.format = &format
+	return r
+}
 
 func (r FrontendApiApiGetLoginFlowRequest) Execute() (*LoginFlow, *http.Response, error) {
 	return r.ApiService.GetLoginFlowExecute(r)
@@ -3309,6 +3405,9 @@ func (a *FrontendApiService) GetLoginFlowExecute(r FrontendApiApiGetLoginFlowReq
 	}
 
 	localVarQueryParams.Add("id", parameterToString(*r.id, ""))
+	if r.format != nil {
+		localVarQueryParams.Add("format", parameterToString(*r.format, ""))
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -3408,6 +3507,7 @@ type FrontendApiApiGetRecoveryFlowRequest struct {
 	ApiService FrontendApi
 	id         *string
 	cookie     *string
+	format     *string
 }
 
 func (r FrontendApiApiGetRecoveryFlowRequest) Id(id string) FrontendApiApiGetRecoveryFlowRequest {
@@ -3418,6 +3518,10 @@ func (r FrontendApiApiGetRecoveryFlowRequest) Cookie(cookie string) FrontendApiA
 	r.cookie = &cookie
 	return r
 }
+func (r FrontendApiApiGetRecoveryFlowRequest) Format(format string) FrontendApiApiGetRecoveryFlowRequest {
+	r.format = &format
+	return r
+}
 
 func (r FrontendApiApiGetRecoveryFlowRequest) Execute() (*RecoveryFlow, *http.Response, error) {
 	return r.ApiService.GetRecoveryFlowExecute(r)
@@ -3482,6 +3586,9 @@ func (a *FrontendApiService) GetRecoveryFlowExecute(r FrontendApiApiGetRecoveryF
 	}
 
 	localVarQueryParams.Add("id", parameterToString(*r.id, ""))
+	if r.format != nil {
+		localVarQueryParams.Add("format", parameterToString(*r.format, ""))
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -3571,6 +3678,7 @@ type FrontendApiApiGetRegistrationFlowRequest struct {
 	ApiService FrontendApi
 	id         *string
 	cookie     *string
+	format     *string
 }
 
 func (r FrontendApiApiGetRegistrationFlowRequest) Id(id string) FrontendApiApiGetRegistrationFlowRequest {
@@ -3581,6 +3689,10 @@ func (r FrontendApiApiGetRegistrationFlowRequest) Cookie(cookie string) Frontend
 	r.cookie = &cookie
 	return r
 }
+func (r FrontendApiApiGetRegistrationFlowRequest) Format(format string) FrontendApiApiGetRegistrationFlowRequest {
+	r.format = &format
+	return r
+}
 
 func (r FrontendApiApiGetRegistrationFlowRequest) Execute() (*RegistrationFlow, *http.Response, error) {
 	return r.ApiService.GetRegistrationFlowExecute(r)
@@ -3650,6 +3762,9 @@ func (a *FrontendApiService) GetRegistrationFlowExecute(r FrontendApiApiGetRegis
 	}
 
 	localVarQueryParams.Add("id", parameterToString(*r.id, ""))
+	if r.format != nil {
+		localVarQueryParams.Add("format", parameterToString(*r.format, ""))
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -3750,6 +3865,7 @@ type FrontendApiApiGetSettingsFlowRequest struct {
 	id            *string
 	xSessionToken *string
 	cookie        *string
+	format        *string
 }
 
 func (r FrontendApiApiGetSettingsFlowRequest) Id(id string) FrontendApiApiGetSettingsFlowRequest {
@@ -3764,6 +3880,10 @@ func (r FrontendApiApiGetSettingsFlowRequest) Cookie(cookie string) FrontendApiA
 	r.cookie = &cookie
 	return r
 }
+func (r FrontendApiApiGetSettingsFlowRequest) Format(format string) FrontendApiApiGetSettingsFlowRequest {
+	r.format = &format
+	return r
+}
 
 func (r FrontendApiApiGetSettingsFlowRequest) Execute() (*SettingsFlow, *http.Response, error) {
 	return r.ApiService.GetSettingsFlowExecute(r)
@@ -3830,6 +3950,9 @@ func (a *FrontendApiService) GetSettingsFlowExecute(r FrontendApiApiGetSettingsF
 	}
 
 	localVarQueryParams.Add("id", parameterToString(*r.id, ""))
+	if r.format != nil {
+		localVarQueryParams.Add("format", parameterToString(*r.format, ""))
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -3942,6 +4065,7 @@ type FrontendApiApiGetVerificationFlowRequest struct {
 	ApiService FrontendApi
 	id         *string
 	cookie     *string
+	format     *string
 }
 
 func (r FrontendApiApiGetVerificationFlowRequest) Id(id string) FrontendApiApiGetVerificationFlowRequest {
@@ -3952,6 +4076,10 @@ func (r FrontendApiApiGetVerificationFlowRequest) Cookie(cookie string) Frontend
 	r.cookie = &cookie
 	return r
 }
+func (r FrontendApiApiGetVerificationFlowRequest) Format(format string) FrontendApiApiGetVerificationFlowRequest {
+	r.format = &format
+	return r
+}
 
 func (r FrontendApiApiGetVerificationFlowRequest) Execute() (*VerificationFlow, *http.Response, error) {
 	return r.ApiService.GetVerificationFlowExecute(r)
@@ -4016,6 +4144,9 @@ func (a *FrontendApiService) GetVerificationFlowExecute(r FrontendApiApiGetVerif
 	}
 
 	localVarQueryParams.Add("id", parameterToString(*r.id, ""))
+	if r.format != nil {
+		localVarQueryParams.Add("format", parameterToString(*r.format, ""))
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -4209,6 +4340,141 @@ func (a *FrontendApiService) GetWebAuthnJavaScriptExecute(r FrontendApiApiGetWeb
 	return localVarReturnValue, localVarHTTPResponse, nil
 }
 
+type FrontendApiApiListMyOidcLinksRequest struct {
+	ctx           context.Context
+	ApiService    FrontendApi
+	xSessionToken *string
+	cookie        *string
+}
+
+func (r FrontendApiApiListMyOidcLinksRequest) XSessionToken(xSessionToken string) FrontendApiApiListMyOidcLinksRequest {
+	r.xSessionToken = &xSessionToken
+	return r
+}
+func (r FrontendApiApiListMyOidcLinksRequest) Cookie(cookie string) FrontendApiApiListMyOidcLinksRequest {
+	r.cookie = &cookie
+	return r
+}
+
+func (r FrontendApiApiListMyOidcLinksRequest) Execute() ([]OidcLink, *http.Response, error) {
+	return r.ApiService.ListMyOidcLinksExecute(r)
+}
+
+/*
+ * ListMyOidcLinks List my linked OpenID Connect providers
+ * This endpoint returns the OpenID Connect providers linked to the identity of the current session.
+ * @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ * @return FrontendApiApiListMyOidcLinksRequest
+ */
+func (a *FrontendApiService) ListMyOidcLinks(ctx context.Context) FrontendApiApiListMyOidcLinksRequest {
+	return FrontendApiApiListMyOidcLinksRequest{
+		ApiService: a,
+		ctx:        ctx,
+	}
+}
+
+/*
+ * Execute executes the request
+ * @return []OidcLink
+ */
+func (a *FrontendApiService) ListMyOidcLinksExecute(r FrontendApiApiListMyOidcLinksRequest) ([]OidcLink, *http.Response, error) {
+	var (
+		localVarHTTPMethod   = http.MethodGet
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+		localVarReturnValue  []OidcLink
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "FrontendApiService.ListMyOidcLinks")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/self-service/methods/oidc/links"
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if r.xSessionToken != nil {
+		localVarHeaderParams["X-Session-Token"] = parameterToString(*r.xSessionToken, "")
+	}
+	if r.cookie != nil {
+		localVarHeaderParams["Cookie"] = parameterToString(*r.cookie, "")
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(io.LimitReader(localVarHTTPResponse.Body, 1024*1024))
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 401 {
+			var v ErrorGeneric
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		var v ErrorGeneric
+		err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+		if err != nil {
+			newErr.error = err.Error()
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		newErr.model = v
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
 type FrontendApiApiListMySessionsRequest struct {
 	ctx           context.Context
 	ApiService    FrontendApi
@@ -4514,6 +4780,182 @@ func (a *FrontendApiService) PerformNativeLogoutExecute(r FrontendApiApiPerformN
 	return localVarHTTPResponse, nil
 }
 
+type FrontendApiApiRevokeMyOidcLinkRequest struct {
+	ctx           context.Context
+	ApiService    FrontendApi
+	provider      string
+	xSessionToken *string
+	cookie        *string
+	xCSRFToken    *string
+}
+
+func (r FrontendApiApiRevokeMyOidcLinkRequest) XSessionToken(xSessionToken string) FrontendApiApiRevokeMyOidcLinkRequest {
+	r.xSessionToken = &xSessionToken
+	return r
+}
+func (r FrontendApiApiRevokeMyOidcLinkRequest) Cookie(cookie string) FrontendApiApiRevokeMyOidcLinkRequest {
+	r.cookie = &cookie
+	return r
+}
+func (r FrontendApiApiRevokeMyOidcLinkRequest) XCSRFToken(xCSRFToken string) FrontendApiApiRevokeMyOidcLinkRequest {
+	r.xCSRFToken = &xCSRFToken
+	return r
+}
+
+func (r FrontendApiApiRevokeMyOidcLinkRequest) Execute() (*SettingsFlow, *http.Response, error) {
+	return r.ApiService.RevokeMyOidcLinkExecute(r)
+}
+
+/*
+  - RevokeMyOidcLink Revoke one of my linked OpenID Connect providers
+  - Calling this endpoint unlinks the provider from the identity of the current session. The link is revoked
+
+in a new settings flow, which means that the session must satisfy the AAL required by the settings flow,
+that the settings hooks are executed, and that a session which is no longer privileged has to re-authenticate
+first. The link can not be revoked if it is the last remaining first factor credential.
+
+Browsers must send the anti-CSRF token in the `X-CSRF-Token` header. API clients must authenticate with
+the `X-Session-Token` header and must not send cookies.
+
+The response is the completed settings flow. Browsers which do not send `Accept: application/json` are
+redirected like they are when updating a settings flow.
+  - @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+  - @param provider The ID of the linked provider.
+  - @return FrontendApiApiRevokeMyOidcLinkRequest
+*/
+func (a *FrontendApiService) RevokeMyOidcLink(ctx context.Context, provider string) FrontendApiApiRevokeMyOidcLinkRequest {
+	return FrontendApiApiRevokeMyOidcLinkRequest{
+		ApiService: a,
+		ctx:        ctx,
+		provider:   provider,
+	}
+}
+
+/*
+ * Execute executes the request
+ */
+func (a *FrontendApiService) RevokeMyOidcLinkExecute(r FrontendApiApiRevokeMyOidcLinkRequest) (*SettingsFlow, *http.Response, error) {
+	var (
+		localVarHTTPMethod   = http.MethodDelete
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+		localVarReturnValue  *SettingsFlow
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "FrontendApiService.RevokeMyOidcLink")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/self-service/methods/oidc/links/{provider}"
+	localVarPath = strings.Replace(localVarPath, "{"+"provider"+"}", url.PathEscape(parameterToString(r.provider, "")), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if r.xSessionToken != nil {
+		localVarHeaderParams["X-Session-Token"] = parameterToString(*r.xSessionToken, "")
+	}
+	if r.cookie != nil {
+		localVarHeaderParams["Cookie"] = parameterToString(*r.cookie, "")
+	}
+	if r.xCSRFToken != nil {
+		localVarHeaderParams["X-CSRF-Token"] = parameterToString(*r.xCSRFToken, "")
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(io.LimitReader(localVarHTTPResponse.Body, 1024*1024))
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v SettingsFlow
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 401 {
+			var v ErrorGeneric
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 403 {
+			var v ErrorGeneric
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		var v ErrorGeneric
+		err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+		if err != nil {
+			newErr.error = err.Error()
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		newErr.model = v
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
 type FrontendApiApiToSessionRequest struct {
 	ctx           context.Context
 	ApiService    FrontendApi
@@ -5861,3 +6303,155 @@ func (a *FrontendApiService) UpdateVerificationFlowExecute(r FrontendApiApiUpdat
 
 	return localVarReturnValue, localVarHTTPResponse, nil
 }
+
+type FrontendApiApiValidateSessionRequest struct {
+	ctx           context.Context
+	ApiService    FrontendApi
+	xSessionToken *string
+	cookie        *string
+}
+
+func (r FrontendApiApiValidateSessionRequest) XSessionToken(xSessionToken string) FrontendApiApiValidateSessionRequest {
+	r.xSessionToken = &xSessionToken
+	return r
+}
+func (r FrontendApiApiValidateSessionRequest) Cookie(cookie string) FrontendApiApiValidateSessionRequest {
+	r.cookie = &cookie
+	return r
+}
+
+func (r FrontendApiApiValidateSessionRequest) Execute() (*SessionValidity, *http.Response, error) {
+	return r.ApiService.ValidateSessionExecute(r)
+}
+
+/*
+  - ValidateSession Check if the Current HTTP Session is Valid
+  - A lightweight alternative to `/sessions/whoami` for reverse proxies and API gateways which only need to know
+
+whether a request is authenticated. The identity's traits, credentials, and addresses are neither loaded nor
+returned. The session is checked the same way as in `/sessions/whoami`, including the required Authenticator
+Assurance Level.
+
+When the request it successful it adds the user ID to the 'X-Kratos-Authenticated-Identity-Id' header
+in the response. If session caching is enabled, the `Ory-Session-Cache-For` header is set as well.
+  - @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+  - @return FrontendApiApiValidateSessionRequest
+*/
+func (a *FrontendApiService) ValidateSession(ctx context.Context) FrontendApiApiValidateSessionRequest {
+	return FrontendApiApiValidateSessionRequest{
+		ApiService: a,
+		ctx:        ctx,
+	}
+}
+
+/*
+ * Execute executes the request
+ * @return SessionValidity
+ */
+func (a *FrontendApiService) ValidateSessionExecute(r FrontendApiApiValidateSessionRequest) (*SessionValidity, *http.Response, error) {
+	var (
+		localVarHTTPMethod   = http.MethodGet
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+		localVarReturnValue  *SessionValidity
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "FrontendApiService.ValidateSession")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/sessions/validate"
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if r.xSessionToken != nil {
+		localVarHeaderParams["X-Session-Token"] = parameterToString(*r.xSessionToken, "")
+	}
+	if r.cookie != nil {
+		localVarHeaderParams["Cookie"] = parameterToString(*r.cookie, "")
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(io.LimitReader(localVarHTTPResponse.Body, 1024*1024))
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 401 {
+			var v ErrorGeneric
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 403 {
+			var v ErrorGeneric
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		var v ErrorGeneric
+		err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+		if err != nil {
+			newErr.error = err.Error()
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		newErr.model = v
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
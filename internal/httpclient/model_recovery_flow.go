@@ -18,7 +18,7 @@ import (
 
 // RecoveryFlow This request is used when an identity wants to recover their account.  We recommend reading the [Account Recovery Documentation](../self-service/flows/password-reset-account-recovery)
 type RecoveryFlow struct {
-	// Active, if set, contains the recovery method that is being used. It is initially not set.
+	// Active contains the recovery method that is being used. It is set when the flow is created to the method configured in `selfservice.flows.recovery.use`.
 	Active *string `json:"active,omitempty"`
 	// Contains possible actions that could follow this flow
 	ContinueWith []ContinueWith `json:"continue_with,omitempty"`
@@ -34,6 +34,10 @@ type RecoveryFlow struct {
 	ReturnTo *string `json:"return_to,omitempty"`
 	// State represents the state of this request:  choose_method: ask the user to choose a method (e.g. recover account via email) sent_email: the email has been sent to the user passed_challenge: the request was successful and the recovery challenge was passed.
 	State interface{} `json:"state"`
+	// Step is the one-based step of the flow derived from its state, e.g. 2 for `sent_email`. It can be used to render a progress indicator.
+	Step int64 `json:"step"`
+	// TotalSteps is the number of steps of the flow.
+	TotalSteps int64 `json:"total_steps"`
 	// TransientPayload is used to pass data from the recovery flow to hooks and email templates
 	TransientPayload map[string]interface{} `json:"transient_payload,omitempty"`
 	// The flow type can either be `api` or `browser`.
@@ -45,13 +49,15 @@ type RecoveryFlow struct {
 // This constructor will assign default values to properties that have it defined,
 // and makes sure properties required by API are set, but the set of arguments
 // will change when the set of required properties is changed
-func NewRecoveryFlow(expiresAt time.Time, id string, issuedAt time.Time, requestUrl string, state interface{}, type_ string, ui UiContainer) *RecoveryFlow {
+func NewRecoveryFlow(expiresAt time.Time, id string, issuedAt time.Time, requestUrl string, state interface{}, step int64, totalSteps int64, type_ string, ui UiContainer) *RecoveryFlow {
 	this := RecoveryFlow{}
 	this.ExpiresAt = expiresAt
 	this.Id = id
 	this.IssuedAt = issuedAt
 	this.RequestUrl = requestUrl
 	this.State = state
+	this.Step = step
+	this.TotalSteps = totalSteps
 	this.Type = type_
 	this.Ui = ui
 	return &this
@@ -283,6 +289,54 @@ func (o *RecoveryFlow) SetState(v interface{}) {
 	o.State = v
 }
 
+// GetStep returns the Step field value
+func (o *RecoveryFlow) GetStep() int64 {
+	if o == nil {
+		var ret int64
+		return ret
+	}
+
+	return o.Step
+}
+
+// GetStepOk returns a tuple with the Step field value
+// and a boolean to check if the value has been set.
+func (o *RecoveryFlow) GetStepOk() (*int64, bool) {
+	if o == nil {
+		return nil, false
+	}
+	return &o.Step, true
+}
+
+// SetStep sets field value
+func (o *RecoveryFlow) SetStep(v int64) {
+	o.Step = v
+}
+
+// GetTotalSteps returns the TotalSteps field value
+func (o *RecoveryFlow) GetTotalSteps() int64 {
+	if o == nil {
+		var ret int64
+		return ret
+	}
+
+	return o.TotalSteps
+}
+
+// GetTotalStepsOk returns a tuple with the TotalSteps field value
+// and a boolean to check if the value has been set.
+func (o *RecoveryFlow) GetTotalStepsOk() (*int64, bool) {
+	if o == nil {
+		return nil, false
+	}
+	return &o.TotalSteps, true
+}
+
+// SetTotalSteps sets field value
+func (o *RecoveryFlow) SetTotalSteps(v int64) {
+	o.TotalSteps = v
+}
+
 // GetTransientPayload returns the TransientPayload field value if set, zero value otherwise.
 func (o *RecoveryFlow) GetTransientPayload() map[string]interface{} {
 	if o == nil || o.TransientPayload == nil {
@@ -389,6 +443,12 @@ func (o RecoveryFlow) MarshalJSON() ([]byte, error) {
 	if o.State != nil {
 		toSerialize["state"] = o.State
 	}
+	if true {
+		toSerialize["step"] = o.Step
+	}
+	if true {
+		toSerialize["total_steps"] = o.TotalSteps
+	}
 	if o.TransientPayload != nil {
 		toSerialize["transient_payload"] = o.TransientPayload
 	}
@@ -18,8 +18,10 @@ import (
 
 // SettingsFlow This flow is used when an identity wants to update settings (e.g. profile data, passwords, ...) in a selfservice manner.  We recommend reading the [User Settings Documentation](../self-service/flows/user-settings)
 type SettingsFlow struct {
-	// Active, if set, contains the registration method that is being used. It is initially not set.
+	// Active, if set, contains the settings method that is being used. It is initially not set.
 	Active *string `json:"active,omitempty"`
+	// Contains the JSON pointers (e.g. `/traits/email`) of the traits which were changed by a successful update.  Only set if `selfservice.flows.settings.return_changed_traits` is enabled.
+	ChangedTraits []string `json:"changed_traits,omitempty"`
 	// Contains a list of actions, that could follow this flow  It can, for example, contain a reference to the verification flow, created as part of the user's registration.
 	ContinueWith []ContinueWith `json:"continue_with,omitempty"`
 	// ExpiresAt is the time (UTC) when the flow expires. If the user still wishes to update the setting, a new flow has to be initiated.
@@ -40,6 +42,8 @@ type SettingsFlow struct {
 	// The flow type can either be `api` or `browser`.
 	Type string      `json:"type"`
 	Ui   UiContainer `json:"ui"`
+	// Contains the node groups (e.g. `profile` or `password`) which were successfully updated by the submission.
+	UpdatedGroups []string `json:"updated_groups,omitempty"`
 }
 
 // NewSettingsFlow instantiates a new SettingsFlow object
@@ -99,6 +103,38 @@ func (o *SettingsFlow) SetActive(v string) {
 	o.Active = &v
 }
 
+// GetChangedTraits returns the ChangedTraits field value if set, zero value otherwise.
+func (o *SettingsFlow) GetChangedTraits() []string {
+	if o == nil || o.ChangedTraits == nil {
+		var ret []string
+		return ret
+	}
+	return o.ChangedTraits
+}
+
+// GetChangedTraitsOk returns a tuple with the ChangedTraits field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *SettingsFlow) GetChangedTraitsOk() ([]string, bool) {
+	if o == nil || o.ChangedTraits == nil {
+		return nil, false
+	}
+	return o.ChangedTraits, true
+}
+
+// HasChangedTraits returns a boolean if a field has been set.
+func (o *SettingsFlow) HasChangedTraits() bool {
+	if o != nil && o.ChangedTraits != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetChangedTraits gets a reference to the given []string and assigns it to the ChangedTraits field.
+func (o *SettingsFlow) SetChangedTraits(v []string) {
+	o.ChangedTraits = v
+}
+
 // GetContinueWith returns the ContinueWith field value if set, zero value otherwise.
 func (o *SettingsFlow) GetContinueWith() []ContinueWith {
 	if o == nil || o.ContinueWith == nil {
@@ -389,11 +425,46 @@ func (o *SettingsFlow) SetUi(v UiContainer) {
 	o.Ui = v
 }
 
+// GetUpdatedGroups returns the UpdatedGroups field value if set, zero value otherwise.
+func (o *SettingsFlow) GetUpdatedGroups() []string {
+	if o == nil || o.UpdatedGroups == nil {
+		var ret []string
+		return ret
+	}
+	return o.UpdatedGroups
+}
+
+// GetUpdatedGroupsOk returns a tuple with the UpdatedGroups field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *SettingsFlow) GetUpdatedGroupsOk() ([]string, bool) {
+	if o == nil || o.UpdatedGroups == nil {
+		return nil, false
+	}
+	return o.UpdatedGroups, true
+}
+
+// HasUpdatedGroups returns a boolean if a field has been set.
+func (o *SettingsFlow) HasUpdatedGroups() bool {
+	if o != nil && o.UpdatedGroups != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetUpdatedGroups gets a reference to the given []string and assigns it to the UpdatedGroups field.
+func (o *SettingsFlow) SetUpdatedGroups(v []string) {
+	o.UpdatedGroups = v
+}
+
 func (o SettingsFlow) MarshalJSON() ([]byte, error) {
 	toSerialize := map[string]interface{}{}
 	if o.Active != nil {
 		toSerialize["active"] = o.Active
 	}
+	if o.ChangedTraits != nil {
+		toSerialize["changed_traits"] = o.ChangedTraits
+	}
 	if o.ContinueWith != nil {
 		toSerialize["continue_with"] = o.ContinueWith
 	}
@@ -427,6 +498,9 @@ func (o SettingsFlow) MarshalJSON() ([]byte, error) {
 	if true {
 		toSerialize["ui"] = o.Ui
 	}
+	if o.UpdatedGroups != nil {
+		toSerialize["updated_groups"] = o.UpdatedGroups
+	}
 	return json.Marshal(toSerialize)
 }
 
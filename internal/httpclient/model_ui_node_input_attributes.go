@@ -28,8 +28,12 @@ type UiNodeInputAttributes struct {
 	NodeType string `json:"node_type"`
 	// OnClick may contain javascript which should be executed on click. This is primarily used for WebAuthn.
 	Onclick *string `json:"onclick,omitempty"`
+	// OnClickTrigger may contain the name of a function defined in webauthn.js which should be called on click. It is set instead of OnClick if inline scripts are disabled.
+	OnclickTrigger *string `json:"onclickTrigger,omitempty"`
 	// OnLoad may contain javascript which should be executed on load. This is primarily used for WebAuthn.
 	Onload *string `json:"onload,omitempty"`
+	// OnLoadTrigger may contain the name of a function defined in webauthn.js which should be called on load. It is set instead of OnLoad if inline scripts are disabled.
+	OnloadTrigger *string `json:"onloadTrigger,omitempty"`
 	// The input's pattern.
 	Pattern *string `json:"pattern,omitempty"`
 	// Mark this input field as required.
@@ -229,6 +233,38 @@ func (o *UiNodeInputAttributes) SetOnclick(v string) {
 	o.Onclick = &v
 }
 
+// GetOnclickTrigger returns the OnclickTrigger field value if set, zero value otherwise.
+func (o *UiNodeInputAttributes) GetOnclickTrigger() string {
+	if o == nil || o.OnclickTrigger == nil {
+		var ret string
+		return ret
+	}
+	return *o.OnclickTrigger
+}
+
+// GetOnclickTriggerOk returns a tuple with the OnclickTrigger field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *UiNodeInputAttributes) GetOnclickTriggerOk() (*string, bool) {
+	if o == nil || o.OnclickTrigger == nil {
+		return nil, false
+	}
+	return o.OnclickTrigger, true
+}
+
+// HasOnclickTrigger returns a boolean if a field has been set.
+func (o *UiNodeInputAttributes) HasOnclickTrigger() bool {
+	if o != nil && o.OnclickTrigger != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetOnclickTrigger gets a reference to the given string and assigns it to the OnclickTrigger field.
+func (o *UiNodeInputAttributes) SetOnclickTrigger(v string) {
+	o.OnclickTrigger = &v
+}
+
 // GetOnload returns the Onload field value if set, zero value otherwise.
 func (o *UiNodeInputAttributes) GetOnload() string {
 	if o == nil || o.Onload == nil {
@@ -261,6 +297,38 @@ func (o *UiNodeInputAttributes) SetOnload(v string) {
 	o.Onload = &v
 }
 
+// GetOnloadTrigger returns the OnloadTrigger field value if set, zero value otherwise.
+func (o *UiNodeInputAttributes) GetOnloadTrigger() string {
+	if o == nil || o.OnloadTrigger == nil {
+		var ret string
+		return ret
+	}
+	return *o.OnloadTrigger
+}
+
+// GetOnloadTriggerOk returns a tuple with the OnloadTrigger field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *UiNodeInputAttributes) GetOnloadTriggerOk() (*string, bool) {
+	if o == nil || o.OnloadTrigger == nil {
+		return nil, false
+	}
+	return o.OnloadTrigger, true
+}
+
+// HasOnloadTrigger returns a boolean if a field has been set.
+func (o *UiNodeInputAttributes) HasOnloadTrigger() bool {
+	if o != nil && o.OnloadTrigger != nil {
+		return true
+	}
+
+	return false
+}
+
+// SetOnloadTrigger gets a reference to the given string and assigns it to the OnloadTrigger field.
+func (o *UiNodeInputAttributes) SetOnloadTrigger(v string) {
+	o.OnloadTrigger = &v
+}
+
 // GetPattern returns the Pattern field value if set, zero value otherwise.
 func (o *UiNodeInputAttributes) GetPattern() string {
 	if o == nil || o.Pattern == nil {
@@ -402,9 +470,15 @@ func (o UiNodeInputAttributes) MarshalJSON() ([]byte, error) {
 	if o.Onclick != nil {
 		toSerialize["onclick"] = o.Onclick
 	}
+	if o.OnclickTrigger != nil {
+		toSerialize["onclickTrigger"] = o.OnclickTrigger
+	}
 	if o.Onload != nil {
 		toSerialize["onload"] = o.Onload
 	}
+	if o.OnloadTrigger != nil {
+		toSerialize["onloadTrigger"] = o.OnloadTrigger
+	}
 	if o.Pattern != nil {
 		toSerialize["pattern"] = o.Pattern
 	}
@@ -0,0 +1,108 @@
+/*
+ * Ory Identities API
+ *
+ * This is the API specification for Ory Identities with features such as registration, login, recovery, account verification, profile settings, password reset, identity management, session management, email and sms delivery, and more.
+ *
+ * API version:
+ * Contact: office@ory.sh
+ */
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package client
+
+import (
+	"encoding/json"
+)
+
+// ValidateIdentitiesBody Validate Identities Body
+type ValidateIdentitiesBody struct {
+	// IDs lists the identities to validate.
+	Ids []string `json:"ids"`
+}
+
+// NewValidateIdentitiesBody instantiates a new ValidateIdentitiesBody object
+// This constructor will assign default values to properties that have it defined,
+// and makes sure properties required by API are set, but the set of arguments
+// will change when the set of required properties is changed
+func NewValidateIdentitiesBody(ids []string) *ValidateIdentitiesBody {
+	this := ValidateIdentitiesBody{}
+	this.Ids = ids
+	return &this
+}
+
+// NewValidateIdentitiesBodyWithDefaults instantiates a new ValidateIdentitiesBody object
+// This constructor will only assign default values to properties that have it defined,
+// but it doesn't guarantee that properties required by API are set
+func NewValidateIdentitiesBodyWithDefaults() *ValidateIdentitiesBody {
+	this := ValidateIdentitiesBody{}
+	return &this
+}
+
+// GetIds returns the Ids field value
+func (o *ValidateIdentitiesBody) GetIds() []string {
+	if o == nil {
+		var ret []string
+		return ret
+	}
+
+	return o.Ids
+}
+
+// GetIdsOk returns a tuple with the Ids field value
+// and a boolean to check if the value has been set.
+func (o *ValidateIdentitiesBody) GetIdsOk() ([]string, bool) {
+	if o == nil {
+		return nil, false
+	}
+	return o.Ids, true
+}
+
+// SetIds sets field value
+func (o *ValidateIdentitiesBody) SetIds(v []string) {
+	o.Ids = v
+}
+
+func (o ValidateIdentitiesBody) MarshalJSON() ([]byte, error) {
+	toSerialize := map[string]interface{}{}
+	if true {
+		toSerialize["ids"] = o.Ids
+	}
+	return json.Marshal(toSerialize)
+}
+
+type NullableValidateIdentitiesBody struct {
+	value *ValidateIdentitiesBody
+	isSet bool
+}
+
+func (v NullableValidateIdentitiesBody) Get() *ValidateIdentitiesBody {
+	return v.value
+}
+
+func (v *NullableValidateIdentitiesBody) Set(val *ValidateIdentitiesBody) {
+	v.value = val
+	v.isSet = true
+}
+
+func (v NullableValidateIdentitiesBody) IsSet() bool {
+	return v.isSet
+}
+
+func (v *NullableValidateIdentitiesBody) Unset() {
+	v.value = nil
+	v.isSet = false
+}
+
+func NewNullableValidateIdentitiesBody(val *ValidateIdentitiesBody) *NullableValidateIdentitiesBody {
+	return &NullableValidateIdentitiesBody{value: val, isSet: true}
+}
+
+func (v NullableValidateIdentitiesBody) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.value)
+}
+
+func (v *NullableValidateIdentitiesBody) UnmarshalJSON(src []byte) error {
+	v.isSet = true
+	return json.Unmarshal(src, &v.value)
+}
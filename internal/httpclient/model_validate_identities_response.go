@@ -0,0 +1,108 @@
+/*
+ * Ory Identities API
+ *
+ * This is the API specification for Ory Identities with features such as registration, login, recovery, account verification, profile settings, password reset, identity management, session management, email and sms delivery, and more.
+ *
+ * API version:
+ * Contact: office@ory.sh
+ */
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package client
+
+import (
+	"encoding/json"
+)
+
+// ValidateIdentitiesResponse Validate Identities Response
+type ValidateIdentitiesResponse struct {
+	// Identities contains the validation result of each requested identity, in the order of the request.
+	Identities []IdentityValidationResult `json:"identities"`
+}
+
+// NewValidateIdentitiesResponse instantiates a new ValidateIdentitiesResponse object
+// This constructor will assign default values to properties that have it defined,
+// and makes sure properties required by API are set, but the set of arguments
+// will change when the set of required properties is changed
+func NewValidateIdentitiesResponse(identities []IdentityValidationResult) *ValidateIdentitiesResponse {
+	this := ValidateIdentitiesResponse{}
+	this.Identities = identities
+	return &this
+}
+
+// NewValidateIdentitiesResponseWithDefaults instantiates a new ValidateIdentitiesResponse object
+// This constructor will only assign default values to properties that have it defined,
+// but it doesn't guarantee that properties required by API are set
+func NewValidateIdentitiesResponseWithDefaults() *ValidateIdentitiesResponse {
+	this := ValidateIdentitiesResponse{}
+	return &this
+}
+
+// GetIdentities returns the Identities field value
+func (o *ValidateIdentitiesResponse) GetIdentities() []IdentityValidationResult {
+	if o == nil {
+		var ret []IdentityValidationResult
+		return ret
+	}
+
+	return o.Identities
+}
+
+// GetIdentitiesOk returns a tuple with the Identities field value
+// and a boolean to check if the value has been set.
+func (o *ValidateIdentitiesResponse) GetIdentitiesOk() ([]IdentityValidationResult, bool) {
+	if o == nil {
+		return nil, false
+	}
+	return o.Identities, true
+}
+
+// SetIdentities sets field value
+func (o *ValidateIdentitiesResponse) SetIdentities(v []IdentityValidationResult) {
+	o.Identities = v
+}
+
+func (o ValidateIdentitiesResponse) MarshalJSON() ([]byte, error) {
+	toSerialize := map[string]interface{}{}
+	if true {
+		toSerialize["identities"] = o.Identities
+	}
+	return json.Marshal(toSerialize)
+}
+
+type NullableValidateIdentitiesResponse struct {
+	value *ValidateIdentitiesResponse
+	isSet bool
+}
+
+func (v NullableValidateIdentitiesResponse) Get() *ValidateIdentitiesResponse {
+	return v.value
+}
+
+func (v *NullableValidateIdentitiesResponse) Set(val *ValidateIdentitiesResponse) {
+	v.value = val
+	v.isSet = true
+}
+
+func (v NullableValidateIdentitiesResponse) IsSet() bool {
+	return v.isSet
+}
+
+func (v *NullableValidateIdentitiesResponse) Unset() {
+	v.value = nil
+	v.isSet = false
+}
+
+func NewNullableValidateIdentitiesResponse(val *ValidateIdentitiesResponse) *NullableValidateIdentitiesResponse {
+	return &NullableValidateIdentitiesResponse{value: val, isSet: true}
+}
+
+func (v NullableValidateIdentitiesResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.value)
+}
+
+func (v *NullableValidateIdentitiesResponse) UnmarshalJSON(src []byte) error {
+	v.isSet = true
+	return json.Unmarshal(src, &v.value)
+}
@@ -18,7 +18,7 @@ import (
 
 // VerificationFlow Used to verify an out-of-band communication channel such as an email address or a phone number.  For more information head over to: https://www.ory.sh/docs/kratos/self-service/flows/verify-email-account-activation
 type VerificationFlow struct {
-	// Active, if set, contains the registration method that is being used. It is initially not set.
+	// Active contains the verification method that is being used. It is set when the flow is created to the method configured in `selfservice.flows.verification.use`.
 	Active *string `json:"active,omitempty"`
 	// ExpiresAt is the time (UTC) when the request expires. If the user still wishes to verify the address, a new request has to be initiated.
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
@@ -32,6 +32,10 @@ type VerificationFlow struct {
 	ReturnTo *string `json:"return_to,omitempty"`
 	// State represents the state of this request:  choose_method: ask the user to choose a method (e.g. verify your email) sent_email: the email has been sent to the user passed_challenge: the request was successful and the verification challenge was passed.
 	State interface{} `json:"state"`
+	// Step is the one-based step of the flow derived from its state, e.g. 2 for `sent_email`. It can be used to render a progress indicator.
+	Step int64 `json:"step"`
+	// TotalSteps is the number of steps of the flow.
+	TotalSteps int64 `json:"total_steps"`
 	// TransientPayload is used to pass data from the verification flow to hooks and email templates
 	TransientPayload map[string]interface{} `json:"transient_payload,omitempty"`
 	// The flow type can either be `api` or `browser`.
@@ -43,10 +47,12 @@ type VerificationFlow struct {
 // This constructor will assign default values to properties that have it defined,
 // and makes sure properties required by API are set, but the set of arguments
 // will change when the set of required properties is changed
-func NewVerificationFlow(id string, state interface{}, type_ string, ui UiContainer) *VerificationFlow {
+func NewVerificationFlow(id string, state interface{}, step int64, totalSteps int64, type_ string, ui UiContainer) *VerificationFlow {
 	this := VerificationFlow{}
 	this.Id = id
 	this.State = state
+	this.Step = step
+	this.TotalSteps = totalSteps
 	this.Type = type_
 	this.Ui = ui
 	return &this
@@ -270,6 +276,54 @@ func (o *VerificationFlow) SetState(v interface{}) {
 	o.State = v
 }
 
+// GetStep returns the Step field value
+func (o *VerificationFlow) GetStep() int64 {
+	if o == nil {
+		var ret int64
+		return ret
+	}
+
+	return o.Step
+}
+
+// GetStepOk returns a tuple with the Step field value
+// and a boolean to check if the value has been set.
+func (o *VerificationFlow) GetStepOk() (*int64, bool) {
+	if o == nil {
+		return nil, false
+	}
+	return &o.Step, true
+}
+
+// SetStep sets field value
+func (o *VerificationFlow) SetStep(v int64) {
+	o.Step = v
+}
+
+// GetTotalSteps returns the TotalSteps field value
+func (o *VerificationFlow) GetTotalSteps() int64 {
+	if o == nil {
+		var ret int64
+		return ret
+	}
+
+	return o.TotalSteps
+}
+
+// GetTotalStepsOk returns a tuple with the TotalSteps field value
+// and a boolean to check if the value has been set.
+func (o *VerificationFlow) GetTotalStepsOk() (*int64, bool) {
+	if o == nil {
+		return nil, false
+	}
+	return &o.TotalSteps, true
+}
+
+// SetTotalSteps sets field value
+func (o *VerificationFlow) SetTotalSteps(v int64) {
+	o.TotalSteps = v
+}
+
 // GetTransientPayload returns the TransientPayload field value if set, zero value otherwise.
 func (o *VerificationFlow) GetTransientPayload() map[string]interface{} {
 	if o == nil || o.TransientPayload == nil {
@@ -373,6 +427,12 @@ func (o VerificationFlow) MarshalJSON() ([]byte, error) {
 	if o.State != nil {
 		toSerialize["state"] = o.State
 	}
+	if true {
+		toSerialize["step"] = o.Step
+	}
+	if true {
+		toSerialize["total_steps"] = o.TotalSteps
+	}
 	if o.TransientPayload != nil {
 		toSerialize["transient_payload"] = o.TransientPayload
 	}
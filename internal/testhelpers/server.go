@@ -32,9 +32,13 @@ func NewKratosServerWithCSRFAndRouters(t *testing.T, reg driver.Registry) (publi
 	reg.WithCSRFHandler(csrfHandler)
 	ran := negroni.New()
 	ran.UseFunc(x.RedirectAdminMiddleware)
+	ran.UseFunc(x.ReadOnlyAdminMiddleware(reg))
+	ran.UseFunc(x.AdminRequestTimeoutMiddleware(reg))
 	ran.UseHandler(ra)
 	rpn := negroni.New()
 	rpn.UseFunc(x.HTTPLoaderContextMiddleware(reg))
+	rpn.UseFunc(x.ReadOnlyPublicMiddleware(reg))
+	rpn.UseFunc(x.PublicRequestTimeoutMiddleware(reg))
 	rpn.UseHandler(rp)
 	public = httptest.NewServer(x.NewTestCSRFHandler(rpn, reg))
 	admin = httptest.NewServer(ran)
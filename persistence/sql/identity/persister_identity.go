@@ -5,8 +5,11 @@ package identity
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha512"
 	"database/sql"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"sort"
 	"strings"
@@ -18,9 +21,11 @@ import (
 	"github.com/gobuffalo/pop/v6"
 	"github.com/gofrs/uuid"
 	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/text/unicode/norm"
 
 	"github.com/ory/herodot"
 	"github.com/ory/jsonschema/v3"
@@ -142,6 +147,200 @@ func NormalizeIdentifier(ct identity.CredentialsType, match string) string {
 	return match
 }
 
+// normalizeIdentifier normalizes an identifier according to
+// `selfservice.identifier_input_normalization`. It is applied both when
+// identifiers are written and when they are looked up, so that the two always
+// agree.
+func (p *IdentityPersister) normalizeIdentifier(ctx context.Context, ct identity.CredentialsType, match string) string {
+	if !isTraitIdentifier(ct) {
+		return NormalizeIdentifier(ct, match)
+	}
+	return normalizeIdentifierInput(p.r.Config().SelfServiceIdentifierInputNormalization(ctx), match)
+}
+
+// normalizeAddress normalizes the value of a verifiable or recovery address
+// like the identifier it is usually derived from.
+func (p *IdentityPersister) normalizeAddress(ctx context.Context, value string) string {
+	return normalizeIdentifierInput(p.r.Config().SelfServiceIdentifierInputNormalization(ctx), value)
+}
+
+func normalizeIdentifierInput(mode, match string) string {
+	switch mode {
+	case config.IdentifierInputNormalizationNone:
+		return strings.ToLower(match)
+	case config.IdentifierInputNormalizationTrimNFC:
+		return norm.NFC.String(stringToLowerTrim(match))
+	default:
+		return stringToLowerTrim(match)
+	}
+}
+
+// isTraitIdentifier returns true for credentials types whose identifiers are
+// taken from the identity traits, such as email addresses or usernames. Only
+// these are normalized as configured and hashed with
+// `selfservice.identifier_storage: hashed`.
+func isTraitIdentifier(ct identity.CredentialsType) bool {
+	switch ct {
+	case identity.CredentialsTypePassword, identity.CredentialsTypeCodeAuth, identity.CredentialsTypeWebAuthn:
+		return true
+	}
+	return false
+}
+
+// hashedIdentifierPrefix marks identifiers which were stored using
+// `selfservice.identifier_storage: hashed`.
+const hashedIdentifierPrefix = "hmac:"
+
+func hashIdentifier(secret []byte, identifier string) string {
+	h := hmac.New(sha512.New512_256, secret)
+	_, _ = h.Write([]byte(identifier))
+	return hashedIdentifierPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+func (p *IdentityPersister) hashesIdentifiers(ctx context.Context, ct identity.CredentialsType) bool {
+	return isTraitIdentifier(ct) && p.r.Config().SelfServiceIdentifierStorage(ctx) == config.IdentifierStorageHashed
+}
+
+// storedIdentifier returns the representation of a normalized identifier as it
+// is written to the database. With `selfservice.identifier_storage` set to
+// `hashed`, this is a keyed hash of the identifier using the current default
+// secret.
+func (p *IdentityPersister) storedIdentifier(ctx context.Context, ct identity.CredentialsType, identifier string, traits identity.Traits) string {
+	if !p.hashesIdentifiers(ctx, ct) {
+		return identifier
+	}
+
+	if strings.HasPrefix(identifier, hashedIdentifierPrefix) && !p.isTraitValue(ctx, ct, identifier, traits) {
+		// A stored hash which did not resolve to a trait value on read is
+		// written back unchanged instead of being hashed twice.
+		return identifier
+	}
+
+	return hashIdentifier(p.r.Config().SecretsDefault(ctx)[0], identifier)
+}
+
+// lookupIdentifiers returns every representation under which a normalized
+// identifier may be stored, starting with the one used for writing. Hashed
+// identifiers keep the hash of the secret which was current when they were
+// written, so each configured default secret is tried after a rotation.
+// Identifiers written before hashed storage was enabled are still stored in
+// plaintext and are matched as well.
+func (p *IdentityPersister) lookupIdentifiers(ctx context.Context, ct identity.CredentialsType, identifier string) []string {
+	if !p.hashesIdentifiers(ctx, ct) {
+		return []string{identifier}
+	}
+
+	secrets := p.r.Config().SecretsDefault(ctx)
+	lookup := make([]string, 0, len(secrets)+1)
+	for _, secret := range secrets {
+		lookup = append(lookup, hashIdentifier(secret, identifier))
+	}
+
+	// Input which looks like a stored hash is never matched as is. Otherwise,
+	// the hash itself could be used to sign in.
+	if !strings.HasPrefix(identifier, hashedIdentifierPrefix) {
+		lookup = append(lookup, identifier)
+	}
+	return lookup
+}
+
+// checkRotatedIdentifier returns a unique violation if the identifier is
+// already stored under the hash of a previous default secret or in plaintext.
+// The unique index only covers the hash of the current secret.
+func (p *IdentityPersister) checkRotatedIdentifier(ctx context.Context, conn *pop.Connection, ct *identity.CredentialsTypeTable, identifier string) error {
+	rotated := p.lookupIdentifiers(ctx, ct.Name, identifier)[1:]
+	if len(rotated) == 0 {
+		return nil
+	}
+
+	exists, err := conn.Where("identity_credential_type_id = ? AND nid = ?", ct.ID, p.NetworkID(ctx)).
+		Where("identifier IN (?)", rotated).
+		Exists(new(identity.CredentialIdentifier))
+	if err != nil {
+		return sqlcon.HandleError(err)
+	} else if exists {
+		return errors.WithStack(sqlcon.ErrUniqueViolation)
+	}
+	return nil
+}
+
+// traitValues returns all string values of the traits. Identifiers of the
+// credentials types covered by isTraitIdentifier are always one of them.
+func traitValues(traits identity.Traits) (values []string) {
+	var walk func(value gjson.Result)
+	walk = func(value gjson.Result) {
+		switch {
+		case value.IsObject(), value.IsArray():
+			value.ForEach(func(_, value gjson.Result) bool {
+				walk(value)
+				return true
+			})
+		case value.Type == gjson.String:
+			values = append(values, value.String())
+		}
+	}
+	walk(gjson.ParseBytes(traits))
+	return values
+}
+
+func (p *IdentityPersister) isTraitValue(ctx context.Context, ct identity.CredentialsType, identifier string, traits identity.Traits) bool {
+	for _, value := range traitValues(traits) {
+		if p.normalizeIdentifier(ctx, ct, value) == identifier {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveStoredIdentifiers replaces hashed credential identifiers with the
+// trait values they were computed from. Everything outside the persister
+// compares and displays identifiers the same way regardless of
+// `selfservice.identifier_storage`.
+func (p *IdentityPersister) resolveStoredIdentifiers(ctx context.Context, i *identity.Identity) {
+	var hashes map[string]string
+	for ct, c := range i.Credentials {
+		resolved := false
+		for k, identifier := range c.Identifiers {
+			if !strings.HasPrefix(identifier, hashedIdentifierPrefix) {
+				continue
+			}
+
+			if hashes == nil {
+				hashes = p.traitHashes(ctx, i.Traits)
+			}
+			if value, ok := hashes[identifier]; ok {
+				c.Identifiers[k] = value
+				resolved = true
+			}
+		}
+		if resolved {
+			sort.Strings(c.Identifiers)
+			i.Credentials[ct] = c
+		}
+	}
+}
+
+// traitHashes maps the hashes of all trait values to the normalized values.
+// The normalization and the default secret may have changed since the
+// identifier was written, which is why every combination is computed.
+func (p *IdentityPersister) traitHashes(ctx context.Context, traits identity.Traits) map[string]string {
+	secrets := p.r.Config().SecretsDefault(ctx)
+	hashes := make(map[string]string)
+	for _, value := range traitValues(traits) {
+		for _, mode := range []string{
+			config.IdentifierInputNormalizationNone,
+			config.IdentifierInputNormalizationTrim,
+			config.IdentifierInputNormalizationTrimNFC,
+		} {
+			normalized := normalizeIdentifierInput(mode, value)
+			for _, secret := range secrets {
+				hashes[hashIdentifier(secret, normalized)] = normalized
+			}
+		}
+	}
+	return hashes
+}
+
 func (p *IdentityPersister) FindIdentityByCredentialIdentifier(ctx context.Context, identifier string, caseSensitive bool) (_ *identity.Identity, err error) {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.FindIdentityByCredentialIdentifier",
 		trace.WithAttributes(
@@ -153,7 +352,7 @@ func (p *IdentityPersister) FindIdentityByCredentialIdentifier(ctx context.Conte
 	}
 
 	if !caseSensitive {
-		identifier = NormalizeIdentifier(identity.CredentialsTypePassword, identifier)
+		identifier = p.normalizeIdentifier(ctx, identity.CredentialsTypePassword, identifier)
 	}
 
 	nid := p.NetworkID(ctx)
@@ -162,11 +361,11 @@ SELECT ic.identity_id
 FROM identity_credentials ic
 INNER JOIN identity_credential_identifiers ici
 	ON ic.id = ici.identity_credential_id
-WHERE ici.identifier = ?
+WHERE ici.identifier IN (?)
 AND ic.nid = ?
 AND ici.nid = ?
 LIMIT 1`,
-		identifier,
+		p.lookupIdentifiers(ctx, identity.CredentialsTypePassword, identifier),
 		nid,
 		nid,
 	).First(&find); err != nil {
@@ -199,8 +398,8 @@ func (p *IdentityPersister) FindByCredentialsIdentifier(ctx context.Context, ct
 		IdentityID uuid.UUID `db:"identity_id"`
 	}
 
-	// Force case-insensitivity and trimming for identifiers
-	match = NormalizeIdentifier(ct, match)
+	// Force case-insensitivity and normalize the identifier as configured
+	match = p.normalizeIdentifier(ctx, ct, match)
 
 	if err := p.GetConnection(ctx).RawQuery(`
 		SELECT
@@ -210,12 +409,12 @@ func (p *IdentityPersister) FindByCredentialsIdentifier(ctx context.Context, ct
 					ON ic.identity_credential_type_id = ict.id
 				INNER JOIN identity_credential_identifiers ici
 					ON ic.id = ici.identity_credential_id AND ici.identity_credential_type_id = ict.id
-		WHERE ici.identifier = ?
+		WHERE ici.identifier IN (?)
 		AND ic.nid = ?
 		AND ici.nid = ?
 		AND ict.name = ?
 		LIMIT 1`, // pop doesn't understand how to add a limit clause to this query
-		match,
+		p.lookupIdentifiers(ctx, ct, match),
 		nid,
 		nid,
 		ct,
@@ -353,10 +552,15 @@ func (p *IdentityPersister) createIdentityCredentials(ctx context.Context, conn
 		return err
 	}
 
+	traits := make(map[uuid.UUID]identity.Traits, len(identities))
+	for _, ident := range identities {
+		traits[ident.ID] = ident.Traits
+	}
+
 	for _, cred := range credentials {
 		for _, identifier := range cred.Identifiers {
-			// Force case-insensitivity and trimming for identifiers
-			identifier = NormalizeIdentifier(cred.Type, identifier)
+			// Force case-insensitivity and normalize the identifier as configured
+			identifier = p.normalizeIdentifier(ctx, cred.Type, identifier)
 
 			if identifier == "" {
 				return errors.WithStack(herodot.ErrInternalServerError.WithReasonf(
@@ -368,8 +572,12 @@ func (p *IdentityPersister) createIdentityCredentials(ctx context.Context, conn
 				return err
 			}
 
+			if err := p.checkRotatedIdentifier(ctx, conn, ct, identifier); err != nil {
+				return err
+			}
+
 			identifiers = append(identifiers, &identity.CredentialIdentifier{
-				Identifier:                identifier,
+				Identifier:                p.storedIdentifier(ctx, cred.Type, identifier, traits[cred.IdentityID]),
 				IdentityCredentialsID:     cred.ID,
 				IdentityCredentialsTypeID: ct.ID,
 				NID:                       p.NetworkID(ctx),
@@ -463,7 +671,7 @@ func (p *IdentityPersister) normalizeVerifiableAddresses(ctx context.Context, id
 
 		v.IdentityID = id.ID
 		v.NID = p.NetworkID(ctx)
-		v.Value = stringToLowerTrim(v.Value)
+		v.Value = p.normalizeAddress(ctx, v.Value)
 		v.Via = x.Coalesce(v.Via, identity.AddressTypeEmail)
 		if len(v.Status) == 0 {
 			if v.Verified {
@@ -489,7 +697,7 @@ func (p *IdentityPersister) normalizeRecoveryAddresses(ctx context.Context, id *
 	for k := range id.RecoveryAddresses {
 		id.RecoveryAddresses[k].IdentityID = id.ID
 		id.RecoveryAddresses[k].NID = p.NetworkID(ctx)
-		id.RecoveryAddresses[k].Value = stringToLowerTrim(id.RecoveryAddresses[k].Value)
+		id.RecoveryAddresses[k].Value = p.normalizeAddress(ctx, id.RecoveryAddresses[k].Value)
 		id.RecoveryAddresses[k].Via = x.Coalesce(id.RecoveryAddresses[k].Via, identity.AddressTypeEmail)
 	}
 }
@@ -659,6 +867,8 @@ func (p *IdentityPersister) HydrateIdentityAssociations(ctx context.Context, i *
 		return err
 	}
 
+	p.resolveStoredIdentifiers(ctx, i)
+
 	if err := i.Validate(); err != nil {
 		return err
 	}
@@ -834,6 +1044,15 @@ func (p *IdentityPersister) ListIdentities(ctx context.Context, params identity.
 		if len(identifier) > 0 {
 			// When filtering by credentials identifier, we most likely are looking for a username or email. It is therefore
 			// important to normalize the identifier before querying the database.
+			normalizedIdentifier := p.normalizeIdentifier(ctx, identity.CredentialsTypePassword, identifier)
+			identifierClause, normalizedMatch, oidcMatch := "LIKE ?", any(normalizedIdentifier), any(identifier)
+			if identifierOperator == "=" {
+				// Hashed identifiers may have been written using any of the configured secrets.
+				identifierClause = "IN (?)"
+				normalizedMatch, oidcMatch = p.lookupIdentifiers(ctx, identity.CredentialsTypePassword, normalizedIdentifier), []string{identifier}
+			} else if p.r.Config().SelfServiceIdentifierStorage(ctx) == config.IdentifierStorageHashed {
+				return errors.WithStack(herodot.ErrBadRequest.WithReason("Similarity search on credential identifiers is not supported when identifiers are stored hashed."))
+			}
 
 			joins = `
 			INNER JOIN identity_credentials ic ON ic.identity_id = identities.id
@@ -841,13 +1060,27 @@ func (p *IdentityPersister) ListIdentities(ctx context.Context, params identity.
 			INNER JOIN identity_credential_identifiers ici ON ici.identity_credential_id = ic.id`
 			wheres += fmt.Sprintf(`
 			AND ic.nid = ? AND ici.nid = ?
-			AND ((ict.name IN (?, ?, ?) AND ici.identifier %s ?)
-              OR (ict.name IN (?) AND ici.identifier %s ?))
-			`, identifierOperator, identifierOperator)
+			AND ((ict.name IN (?, ?, ?) AND ici.identifier %s)
+              OR (ict.name IN (?) AND ici.identifier %s))
+			`, identifierClause, identifierClause)
 			args = append(args,
 				nid, nid,
-				identity.CredentialsTypeWebAuthn, identity.CredentialsTypePassword, identity.CredentialsTypeCodeAuth, NormalizeIdentifier(identity.CredentialsTypePassword, identifier),
-				identity.CredentialsTypeOIDC, identifier)
+				identity.CredentialsTypeWebAuthn, identity.CredentialsTypePassword, identity.CredentialsTypeCodeAuth, normalizedMatch,
+				identity.CredentialsTypeOIDC, oidcMatch)
+		}
+
+		if params.CredentialsType != "" {
+			exists := "EXISTS"
+			if params.WithoutCredentialsType {
+				exists = "NOT EXISTS"
+			}
+			wheres += fmt.Sprintf(`
+			AND %s (
+				SELECT 1 FROM identity_credentials ctc
+				INNER JOIN identity_credential_types ctct ON ctct.id = ctc.identity_credential_type_id
+				WHERE ctc.identity_id = identities.id AND ctc.nid = ? AND ctct.name = ?
+			)`, exists)
+			args = append(args, nid, params.CredentialsType)
 		}
 
 		if params.IdsFilter != nil && len(params.IdsFilter) != 0 {
@@ -935,6 +1168,8 @@ func (p *IdentityPersister) ListIdentities(ctx context.Context, params identity.
 			schemaCache[i.SchemaID] = i.SchemaURL
 		}
 
+		p.resolveStoredIdentifiers(ctx, i)
+
 		if err := i.Validate(); err != nil {
 			return nil, nil, err
 		}
@@ -956,12 +1191,44 @@ func (p *IdentityPersister) UpdateIdentity(ctx context.Context, i *identity.Iden
 			attribute.Stringer("network.id", p.NetworkID(ctx))))
 	defer otelx.End(span, &err)
 
+	return p.updateIdentity(ctx, i, nil)
+}
+
+func (p *IdentityPersister) UpdateIdentityIfUnmodifiedSince(ctx context.Context, i *identity.Identity, since time.Time) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.UpdateIdentityIfUnmodifiedSince",
+		trace.WithAttributes(
+			attribute.Stringer("identity.id", i.ID),
+			attribute.Stringer("network.id", p.NetworkID(ctx))))
+	defer otelx.End(span, &err)
+
+	return p.updateIdentity(ctx, i, &since)
+}
+
+func (p *IdentityPersister) updateIdentity(ctx context.Context, i *identity.Identity, unmodifiedSince *time.Time) (err error) {
 	if err := p.validateIdentity(ctx, i); err != nil {
 		return err
 	}
 
 	i.NID = p.NetworkID(ctx)
+	i.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
 	return sqlcon.HandleError(p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+		if unmodifiedSince != nil {
+			// The conditional update locks the row, so concurrent updates with the same
+			// precondition are serialized and all but the first one fail.
+			// #nosec G201 -- TableName is static
+			count, err := tx.RawQuery(
+				fmt.Sprintf(
+					`UPDATE %s SET updated_at = ? WHERE id = ? AND nid = ? AND updated_at <= ?`,
+					new(identity.Identity).TableName(ctx)),
+				i.UpdatedAt, i.ID, i.NID, unmodifiedSince.UTC()).ExecWithCount()
+			if err != nil {
+				return sqlcon.HandleError(err)
+			}
+			if count == 0 {
+				return errors.WithStack(identity.ErrModifiedSince)
+			}
+		}
+
 		// This returns "ErrNoRows" if the identity does not exist
 		if err := update.Generic(WithTransaction(ctx, tx), tx, p.r.Tracer(ctx).Tracer(), i); err != nil {
 			return err
@@ -973,7 +1240,7 @@ func (p *IdentityPersister) UpdateIdentity(ctx context.Context, i *identity.Iden
 		}
 
 		if err := updateAssociation(ctx, p, i, i.VerifiableAddresses); err != nil {
-			return err
+			return p.verifiableAddressConflict(err)
 		}
 
 		// #nosec G201 -- TableName is static
@@ -1048,7 +1315,7 @@ func (p *IdentityPersister) FindVerifiableAddressByValue(ctx context.Context, vi
 	otelx.End(span, &err)
 
 	var address identity.VerifiableAddress
-	if err := p.GetConnection(ctx).Where("nid = ? AND via = ? AND value = ?", p.NetworkID(ctx), via, stringToLowerTrim(value)).First(&address); err != nil {
+	if err := p.GetConnection(ctx).Where("nid = ? AND via = ? AND value = ?", p.NetworkID(ctx), via, p.normalizeAddress(ctx, value)).First(&address); err != nil {
 		return nil, sqlcon.HandleError(err)
 	}
 
@@ -1062,7 +1329,7 @@ func (p *IdentityPersister) FindRecoveryAddressByValue(ctx context.Context, via
 	defer otelx.End(span, &err)
 
 	var address identity.RecoveryAddress
-	if err := p.GetConnection(ctx).Where("nid = ? AND via = ? AND value = ?", p.NetworkID(ctx), via, stringToLowerTrim(value)).First(&address); err != nil {
+	if err := p.GetConnection(ctx).Where("nid = ? AND via = ? AND value = ?", p.NetworkID(ctx), via, p.normalizeAddress(ctx, value)).First(&address); err != nil {
 		return nil, sqlcon.HandleError(err)
 	}
 
@@ -1112,8 +1379,21 @@ func (p *IdentityPersister) UpdateVerifiableAddress(ctx context.Context, address
 	defer otelx.End(span, &err)
 
 	address.NID = p.NetworkID(ctx)
-	address.Value = stringToLowerTrim(address.Value)
-	return update.Generic(ctx, p.GetConnection(ctx), p.r.Tracer(ctx).Tracer(), address)
+	address.Value = p.normalizeAddress(ctx, address.Value)
+	return p.verifiableAddressConflict(update.Generic(ctx, p.GetConnection(ctx), p.r.Tracer(ctx).Tracer(), address))
+}
+
+// verifiableAddressConflict explains violations of the unique (nid, via, value)
+// index on verifiable addresses with a clear conflict error.
+func (p *IdentityPersister) verifiableAddressConflict(err error) error {
+	err = sqlcon.HandleError(err)
+	if !errors.Is(err, sqlcon.ErrUniqueViolation) {
+		return err
+	}
+	// Only the sentinel is wrapped: sqlcon.HandleError would turn a wrapped driver error
+	// back into a plain unique violation once UpdateIdentity's transaction returns.
+	return errors.WithStack(herodot.ErrConflict.WithReason("This address is already used by another account.").
+		WithDebug(err.Error()).WithWrap(sqlcon.ErrUniqueViolation))
 }
 
 func (p *IdentityPersister) validateIdentity(ctx context.Context, i *identity.Identity) (err error) {
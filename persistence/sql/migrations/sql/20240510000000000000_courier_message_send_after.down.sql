@@ -0,0 +1,2 @@
+ALTER TABLE
+  courier_messages DROP column send_after;
@@ -0,0 +1,4 @@
+ALTER TABLE
+  courier_messages
+ADD
+  column send_after TIMESTAMP NULL;
@@ -0,0 +1 @@
+DROP INDEX selfservice_login_flows_nid_issued_at_idx;
@@ -0,0 +1 @@
+CREATE INDEX selfservice_login_flows_nid_issued_at_idx ON selfservice_login_flows (nid, issued_at);
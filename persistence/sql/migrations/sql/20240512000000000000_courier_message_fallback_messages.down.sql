@@ -0,0 +1,2 @@
+ALTER TABLE
+  courier_messages DROP column fallback_messages;
@@ -0,0 +1,4 @@
+ALTER TABLE
+  courier_messages
+ADD
+  column fallback_messages TEXT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE
+  selfservice_login_flows DROP column submissions;
@@ -0,0 +1,4 @@
+ALTER TABLE
+  selfservice_login_flows
+ADD
+  column submissions TEXT NULL;
@@ -191,10 +191,10 @@ func (p *Persister) CleanupDatabase(ctx context.Context, wait time.Duration, old
 	p.r.Logger().Printf("Cleaning up records older than %s\n", currentTime)
 
 	p.r.Logger().Println("Cleaning up expired sessions")
-	if err := p.DeleteExpiredSessions(ctx, currentTime, batchSize); err != nil {
+	if err := p.DeleteExpiredSessions(ctx, currentTime, p.r.Config().DatabaseCleanupSessionsBatchSize(ctx, batchSize)); err != nil {
 		return err
 	}
-	time.Sleep(wait)
+	time.Sleep(p.r.Config().DatabaseCleanupSessionsSleep(ctx, wait))
 
 	p.r.Logger().Println("Cleaning up expired continuity containers")
 	if err := p.DeleteExpiredContinuitySessions(ctx, currentTime, batchSize); err != nil {
@@ -5,12 +5,19 @@ package sql_test
 
 import (
 	"context"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/ory/kratos/driver/config"
+	confighelpers "github.com/ory/kratos/driver/config/testhelpers"
+	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/session"
 )
 
 func TestPersister_Cleanup(t *testing.T) {
@@ -120,6 +127,38 @@ func TestPersister_Session_Cleanup(t *testing.T) {
 	})
 }
 
+func TestPersister_Session_Cleanup_BatchSize(t *testing.T) {
+	t.Parallel()
+
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	p := reg.Persister()
+	ctx := testhelpers.WithDefaultIdentitySchema(context.Background(), "file://./stub/identity.schema.json")
+
+	i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+	require.NoError(t, p.CreateIdentity(ctx, i))
+
+	for k := 0; k < 3; k++ {
+		s, err := session.NewActiveSession(httptest.NewRequest("GET", "/", nil), i, conf, time.Now().UTC(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+		s.ExpiresAt = time.Now().Add(-time.Hour)
+		require.NoError(t, p.UpsertSession(ctx, s))
+	}
+
+	count := func(t *testing.T) int {
+		c, err := p.GetConnection(ctx).Count(new(session.Session))
+		require.NoError(t, err)
+		return c
+	}
+	require.Equal(t, 3, count(t))
+
+	ctx = confighelpers.WithConfigValue(ctx, config.ViperKeyDatabaseCleanupSessionsBatchSize, 2)
+	require.NoError(t, p.CleanupDatabase(ctx, 0, 0, 100))
+	assert.Equal(t, 1, count(t), "only the session specific batch size should have been removed")
+
+	require.NoError(t, p.CleanupDatabase(ctx, 0, 0, 100))
+	assert.Equal(t, 0, count(t))
+}
+
 func TestPersister_Settings_Cleanup(t *testing.T) {
 	t.Parallel()
 
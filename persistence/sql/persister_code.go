@@ -26,7 +26,8 @@ type oneTimeCodeProvider interface {
 }
 
 type codeOptions struct {
-	IdentityID *uuid.UUID
+	IdentityID     *uuid.UUID
+	MaxSubmitCount int
 }
 
 type codeOption func(o *codeOptions)
@@ -37,6 +38,12 @@ func withCheckIdentityID(id uuid.UUID) codeOption {
 	}
 }
 
+func withMaxSubmitCount(n int) codeOption {
+	return func(o *codeOptions) {
+		o.MaxSubmitCount = n
+	}
+}
+
 func useOneTimeCode[P any, U interface {
 	*P
 	oneTimeCodeProvider
@@ -45,7 +52,7 @@ func useOneTimeCode[P any, U interface {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.useOneTimeCode")
 	defer otelx.End(span, &err)
 
-	o := new(codeOptions)
+	o := &codeOptions{MaxSubmitCount: 5}
 	for _, opt := range opts {
 		opt(o)
 	}
@@ -70,10 +77,10 @@ func useOneTimeCode[P any, U interface {
 		}
 
 		// This check prevents parallel brute force attacks by checking the submit count inside this database
-		// transaction. If the flow has been submitted more than 5 times, the transaction is aborted (regardless of
+		// transaction. If the flow has been submitted too often, the transaction is aborted (regardless of
 		// whether the code was correct or not) and we thus give no indication whether the supplied code was correct or
 		// not. For more explanation see [this comment](https://github.com/ory/kratos/pull/2645#discussion_r984732899).
-		if submitCount > 5 {
+		if submitCount > o.MaxSubmitCount {
 			return errors.WithStack(code.ErrCodeSubmittedTooOften)
 		}
 
@@ -7,6 +7,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
 
 	"github.com/gobuffalo/pop/v6"
 	"github.com/gofrs/uuid"
@@ -74,9 +75,10 @@ func (p *Persister) NextMessages(ctx context.Context, limit uint8) (messages []c
 	if err := p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
 		var m []courier.Message
 		if err := tx.
-			Where("nid = ? AND status = ?",
+			Where("nid = ? AND status = ? AND (send_after IS NULL OR send_after <= ?)",
 				p.NetworkID(ctx),
 				courier.MessageStatusQueued,
+				time.Now().UTC(),
 			).
 			Order("created_at ASC").
 			Limit(int(limit)).
@@ -133,6 +135,37 @@ func (p *Persister) LatestQueuedMessage(ctx context.Context) (_ *courier.Message
 	return &m, nil
 }
 
+func (p *Persister) FindDuplicateMessage(ctx context.Context, m *courier.Message, since time.Time) (_ *courier.Message, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.FindDuplicateMessage")
+	defer otelx.End(span, &err)
+
+	// A scheduled message must not be swallowed by an identical message which is sent right away.
+	schedule, scheduledAt := "(send_after IS NULL OR send_after <= ?)", time.Now().UTC()
+	if sendAfter := time.Time(m.SendAfter); !sendAfter.IsZero() {
+		schedule, scheduledAt = "send_after = ?", sendAfter
+	}
+
+	var dup courier.Message
+	if err := p.GetConnection(ctx).
+		Where("nid = ? AND recipient = ? AND template_type = ? AND body = ? AND created_at >= ? AND status IN (?, ?, ?)",
+			p.NetworkID(ctx),
+			m.Recipient,
+			m.TemplateType,
+			m.Body,
+			since,
+			courier.MessageStatusQueued,
+			courier.MessageStatusProcessing,
+			courier.MessageStatusSent,
+		).
+		Where(schedule, scheduledAt).
+		Order("created_at DESC").
+		First(&dup); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	return &dup, nil
+}
+
 func (p *Persister) SetMessageStatus(ctx context.Context, id uuid.UUID, ms courier.MessageStatus) (err error) {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.SetMessageStatus")
 	defer otelx.End(span, &err)
@@ -174,6 +207,27 @@ func (p *Persister) IncrementMessageSendCount(ctx context.Context, id uuid.UUID)
 	return nil
 }
 
+func (p *Persister) SetMessageSendAfter(ctx context.Context, id uuid.UUID, sendAfter time.Time) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.SetMessageSendAfter")
+	defer otelx.End(span, &err)
+
+	count, err := p.GetConnection(ctx).RawQuery(
+		"UPDATE courier_messages SET send_after = ? WHERE id = ? AND nid = ?",
+		sendAfter.UTC(),
+		id,
+		p.NetworkID(ctx),
+	).ExecWithCount()
+	if err != nil {
+		return sqlcon.HandleError(err)
+	}
+
+	if count == 0 {
+		return errors.WithStack(sqlcon.ErrNoRows)
+	}
+
+	return nil
+}
+
 func (p *Persister) FetchMessage(ctx context.Context, msgID uuid.UUID) (_ *courier.Message, err error) {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.FetchMessage")
 	defer otelx.End(span, &err)
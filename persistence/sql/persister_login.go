@@ -5,16 +5,20 @@ package sql
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/gobuffalo/pop/v6"
 	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
 
 	"github.com/ory/x/otelx"
 	"github.com/ory/x/sqlcon"
 
 	"github.com/ory/kratos/persistence/sql/update"
+	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/login"
 )
 
@@ -53,6 +57,23 @@ func (p *Persister) GetLoginFlow(ctx context.Context, id uuid.UUID) (_ *login.Fl
 	return &r, nil
 }
 
+func (p *Persister) ListRecentBrowserLoginFlows(ctx context.Context, requestURL string, issuedAfter time.Time) (_ []login.Flow, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ListRecentBrowserLoginFlows")
+	defer otelx.End(span, &err)
+
+	var fs []login.Flow
+	if err := p.GetConnection(ctx).
+		Where("nid = ? AND type = ? AND state = ? AND request_url = ? AND issued_at > ? AND expires_at > ?",
+			p.NetworkID(ctx), flow.TypeBrowser, flow.StateChooseMethod, requestURL, issuedAfter, time.Now().UTC()).
+		Order("issued_at DESC").
+		Limit(10).
+		All(&fs); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	return fs, nil
+}
+
 func (p *Persister) ForceLoginFlow(ctx context.Context, id uuid.UUID) (err error) {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ForceLoginFlow")
 	defer otelx.End(span, &err)
@@ -86,3 +107,74 @@ func (p *Persister) DeleteExpiredLoginFlows(ctx context.Context, expiresAt time.
 	}
 	return nil
 }
+
+func (p *Persister) ReserveLoginFlowSubmission(ctx context.Context, id uuid.UUID, limits map[string]int) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ReserveLoginFlowSubmission")
+	defer otelx.End(span, &err)
+
+	return p.updateLoginFlowSubmissions(ctx, id, func(submissions map[string]int) error {
+		for strategy, limit := range limits {
+			if submissions[strategy] >= limit {
+				return errors.WithStack(login.ErrFlowLocked)
+			}
+		}
+		for strategy := range limits {
+			submissions[strategy]++
+		}
+		return nil
+	})
+}
+
+func (p *Persister) ReleaseLoginFlowSubmission(ctx context.Context, id uuid.UUID, strategies []string) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ReleaseLoginFlowSubmission")
+	defer otelx.End(span, &err)
+
+	return p.updateLoginFlowSubmissions(ctx, id, func(submissions map[string]int) error {
+		for _, strategy := range strategies {
+			if submissions[strategy] > 0 {
+				submissions[strategy]--
+			}
+		}
+		return nil
+	})
+}
+
+// updateLoginFlowSubmissions applies update to the submission counts of the login flow. The counts
+// are stored outside the flow's internal context so that updates of the flow do not overwrite them.
+func (p *Persister) updateLoginFlowSubmissions(ctx context.Context, id uuid.UUID, update func(submissions map[string]int) error) error {
+	nid := p.NetworkID(ctx)
+	tableName := new(login.Flow).TableName(ctx)
+	return sqlcon.HandleError(p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+		// Writing the row first locks it until the transaction ends on all databases. Concurrent
+		// submissions therefore see the counts of each other.
+		//#nosec G201 -- TableName is static
+		if err := tx.RawQuery(fmt.Sprintf("UPDATE %s SET submissions = submissions WHERE id = ? AND nid = ?", tableName), id, nid).Exec(); err != nil {
+			return err
+		}
+
+		var raw sql.NullString
+		//#nosec G201 -- TableName is static
+		if err := tx.RawQuery(fmt.Sprintf("SELECT submissions FROM %s WHERE id = ? AND nid = ?", tableName), id, nid).First(&raw); err != nil {
+			return err
+		}
+
+		submissions := make(map[string]int)
+		if raw.Valid && raw.String != "" {
+			if err := json.Unmarshal([]byte(raw.String), &submissions); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+
+		if err := update(submissions); err != nil {
+			return err
+		}
+
+		encoded, err := json.Marshal(submissions)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		//#nosec G201 -- TableName is static
+		return tx.RawQuery(fmt.Sprintf("UPDATE %s SET submissions = ? WHERE id = ? AND nid = ?", tableName), string(encoded), id, nid).Exec()
+	}))
+}
@@ -111,6 +111,32 @@ func (p *Persister) UseRecoveryToken(ctx context.Context, fID uuid.UUID, token s
 	return &rt, nil
 }
 
+func (p *Persister) FindRecoveryTokenFlowID(ctx context.Context, token string) (_ uuid.UUID, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.FindRecoveryTokenFlowID")
+	defer otelx.End(span, &err)
+
+	var rt link.RecoveryToken
+	nid := p.NetworkID(ctx)
+	for _, secret := range p.r.Config().SecretsSession(ctx) {
+		if err = p.GetConnection(ctx).Where("token = ? AND nid = ? AND NOT used", hmacValueWithSecret(ctx, token, secret), nid).First(&rt); err != nil {
+			if !errors.Is(sqlcon.HandleError(err), sqlcon.ErrNoRows) {
+				return uuid.Nil, sqlcon.HandleError(err)
+			}
+		} else {
+			break
+		}
+	}
+	if err != nil {
+		return uuid.Nil, sqlcon.HandleError(err)
+	}
+
+	if !rt.FlowID.Valid {
+		return uuid.Nil, errors.WithStack(sqlcon.ErrNoRows)
+	}
+
+	return rt.FlowID.UUID, nil
+}
+
 func (p *Persister) DeleteRecoveryToken(ctx context.Context, token string) (err error) {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteRecoveryToken")
 	defer otelx.End(span, &err)
@@ -53,12 +53,12 @@ func (p *Persister) CreateRecoveryCode(ctx context.Context, params *code.CreateR
 // UseRecoveryCode attempts to "use" the supplied code in the flow
 //
 // If the supplied code matched a code from the flow, no error is returned
-// If an invalid code was submitted with this flow more than 5 times, an error is returned
+// If an invalid code was submitted with this flow more often than allowed by the configuration, an error is returned
 func (p *Persister) UseRecoveryCode(ctx context.Context, flowID uuid.UUID, userProvidedCode string) (_ *code.RecoveryCode, err error) {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.UseRecoveryCode")
 	defer otelx.End(span, &err)
 
-	codeRow, err := useOneTimeCode[code.RecoveryCode, *code.RecoveryCode](ctx, p, flowID, userProvidedCode, new(recovery.Flow).TableName(ctx), "selfservice_recovery_flow_id")
+	codeRow, err := useOneTimeCode[code.RecoveryCode, *code.RecoveryCode](ctx, p, flowID, userProvidedCode, new(recovery.Flow).TableName(ctx), "selfservice_recovery_flow_id", withMaxSubmitCount(p.r.Config().SelfServiceFlowRecoveryMaxSubmitAttempts(ctx)))
 	if err != nil {
 		return nil, err
 	}
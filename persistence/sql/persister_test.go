@@ -24,6 +24,7 @@ import (
 	courier "github.com/ory/kratos/courier/test"
 	"github.com/ory/kratos/driver"
 	"github.com/ory/kratos/driver/config"
+	confighelpers "github.com/ory/kratos/driver/config/testhelpers"
 	ri "github.com/ory/kratos/identity"
 	identity "github.com/ory/kratos/identity/test"
 	"github.com/ory/kratos/internal"
@@ -319,6 +320,34 @@ func TestPersister_Transaction(t *testing.T) {
 	})
 }
 
+func TestPersister_AddressNormalization(t *testing.T) {
+	t.Parallel()
+
+	ctx := testhelpers.WithDefaultIdentitySchema(context.Background(), "file://./stub/identity.schema.json")
+	ctx = confighelpers.WithConfigValue(ctx, config.ViperKeySelfServiceIdentifierInputNormalization, config.IdentifierInputNormalizationTrimNFC)
+	_, reg := internal.NewFastRegistryWithMocks(t)
+	p := reg.Persister()
+
+	id := x.NewUUID().String()
+	composed, decomposed := "jos\u00e9-"+id+"@ory.sh", "jose\u0301-"+id+"@ory.sh"
+
+	i := ri.NewIdentity("")
+	i.Traits = ri.Traits("{}")
+	i.VerifiableAddresses = []ri.VerifiableAddress{*ri.NewVerifiableEmailAddress(decomposed, i.ID)}
+	i.RecoveryAddresses = []ri.RecoveryAddress{*ri.NewRecoveryEmailAddress(decomposed, i.ID)}
+	require.NoError(t, p.CreateIdentity(ctx, i))
+
+	for _, value := range []string{composed, decomposed} {
+		va, err := p.FindVerifiableAddressByValue(ctx, ri.VerifiableAddressTypeEmail, value)
+		require.NoError(t, err)
+		assert.Equal(t, composed, va.Value)
+
+		ra, err := p.FindRecoveryAddressByValue(ctx, ri.RecoveryAddressTypeEmail, value)
+		require.NoError(t, err)
+		assert.Equal(t, composed, ra.Value)
+	}
+}
+
 func Benchmark_BatchCreateIdentities(b *testing.B) {
 	conns := createCleanDatabases(b)
 	ctx := context.Background()
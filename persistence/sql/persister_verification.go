@@ -110,6 +110,28 @@ func (p *Persister) UseVerificationToken(ctx context.Context, fID uuid.UUID, tok
 	return &rt, nil
 }
 
+func (p *Persister) FindVerificationTokenFlowID(ctx context.Context, token string) (_ uuid.UUID, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.FindVerificationTokenFlowID")
+	defer otelx.End(span, &err)
+
+	var rt link.VerificationToken
+	nid := p.NetworkID(ctx)
+	for _, secret := range p.r.Config().SecretsSession(ctx) {
+		if err = p.GetConnection(ctx).Where("token = ? AND nid = ? AND NOT used", hmacValueWithSecret(ctx, token, secret), nid).First(&rt); err != nil {
+			if !errors.Is(sqlcon.HandleError(err), sqlcon.ErrNoRows) {
+				return uuid.Nil, sqlcon.HandleError(err)
+			}
+		} else {
+			break
+		}
+	}
+	if err != nil {
+		return uuid.Nil, sqlcon.HandleError(err)
+	}
+
+	return rt.FlowID, nil
+}
+
 func (p *Persister) DeleteVerificationToken(ctx context.Context, token string) (err error) {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteVerificationToken")
 	defer otelx.End(span, &err)
@@ -330,6 +330,16 @@ func NewTraitsMismatch() error {
 	})
 }
 
+func NewWebAuthnChallengeExpiredError() error {
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     `the webauthn challenge expired`,
+			InstancePtr: "#/",
+		},
+		Messages: new(text.Messages).Add(text.NewErrorValidationWebAuthnChallengeExpired()),
+	})
+}
+
 func NewRegistrationCodeInvalid() error {
 	return errors.WithStack(&ValidationError{
 		ValidationError: &jsonschema.ValidationError{
@@ -218,3 +218,21 @@ func GetFlowExpiredRedirectURL(ctx context.Context, config *config.Config, route
 
 	return redirectURL
 }
+
+type apiFlowErrorWriterDependencies interface {
+	config.Provider
+	x.LoggingProvider
+}
+
+// WriteAPIFlowError writes the error response of an API flow. Unless `selfservice.flows.api.debug_errors`
+// is enabled, internal server errors are reduced to their status code and the debug details of all errors
+// are removed.
+func WriteAPIFlowError(d apiFlowErrorWriterDependencies, w http.ResponseWriter, r *http.Request, code int, err error) {
+	writer := herodot.NewJSONWriter(d.Logger())
+	if d.Config().SelfServiceFlowAPIDebugErrors(r.Context()) {
+		writer.EnableDebug = true
+	} else {
+		writer.ErrorEnhancer = herodot.Scrub5xxJSONErrorEnhancer
+	}
+	writer.WriteErrorCode(w, r, code, err)
+}
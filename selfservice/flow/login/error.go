@@ -34,6 +34,12 @@ var (
 
 	// ErrSessionRequiredForHigherAAL is returned when someone requests AAL2 or AAL3 even though no active session exists yet.
 	ErrSessionRequiredForHigherAAL = herodot.ErrUnauthorized.WithID(text.ErrIDSessionRequiredForHigherAAL).WithError("aal2 and aal3 can only be requested if a session exists already").WithReason("You can not requested a higher AAL (AAL2/AAL3) without an active session.")
+
+	// ErrFlowLocked is returned when a login flow was submitted unsuccessfully more often than `max_submissions` allows.
+	ErrFlowLocked = herodot.ErrForbidden.WithID(text.ErrIDSelfServiceFlowLocked).WithError("login flow is locked").WithReason("This login flow was submitted too often. Please start a new login flow.")
+
+	// ErrLoginRequired is returned when `prompt=none` was requested but no session satisfying the requested AAL exists.
+	ErrLoginRequired = herodot.ErrUnauthorized.WithID(text.ErrIDLoginRequired).WithError("login required").WithReason("No session with the requested AAL exists and `prompt=none` does not allow showing a login form.")
 )
 
 type (
@@ -97,7 +103,7 @@ func (s *ErrorHandler) WriteFlowError(w http.ResponseWriter, r *http.Request, f
 		s.WriteFlowError(w, r, f, group, inner)
 		return
 	} else if expired != nil {
-		if f.Type == flow.TypeAPI || x.IsJSONRequest(r) {
+		if f.Type == flow.TypeAPI || x.IsJSONRequest(r) || s.d.Config().SelfServiceFlowExpiredAsStatusForBrowser(r.Context()) {
 			s.d.Writer().WriteError(w, r, expired)
 		} else {
 			http.Redirect(w, r, expired.GetFlow().AppendTo(s.d.Config().SelfServiceFlowLoginUI(r.Context())).String(), http.StatusSeeOther)
@@ -151,7 +157,7 @@ func (s *ErrorHandler) forward(w http.ResponseWriter, r *http.Request, rr *Flow,
 	}
 
 	if rr.Type == flow.TypeAPI {
-		s.d.Writer().WriteErrorCode(w, r, x.RecoverStatusCode(err, http.StatusBadRequest), err)
+		flow.WriteAPIFlowError(s.d, w, r, x.RecoverStatusCode(err, http.StatusBadRequest), err)
 	} else {
 		s.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
 	}
@@ -194,7 +194,37 @@ func TestHandleError(t *testing.T) {
 
 				body, err := io.ReadAll(res.Body)
 				require.NoError(t, err)
-				assert.JSONEq(t, x.MustEncodeJSON(t, flowError), gjson.GetBytes(body, "error").Raw)
+				if tc.t == flow.TypeAPI {
+					assert.JSONEq(t, `{"code":500,"status":"Internal Server Error","message":""}`, gjson.GetBytes(body, "error").Raw)
+				} else {
+					assert.JSONEq(t, x.MustEncodeJSON(t, flowError), gjson.GetBytes(body, "error").Raw)
+				}
+			})
+
+			t.Run("case=generic error with debug errors", func(t *testing.T) {
+				t.Cleanup(reset)
+				conf.MustSet(ctx, config.ViperKeySelfServiceAPIDebugErrors, true)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeySelfServiceAPIDebugErrors, false)
+				})
+
+				loginFlow = newFlow(t, time.Minute, tc.t)
+				flowError = herodot.ErrInternalServerError.WithReason("system error").WithDebug("database unavailable")
+				ct = node.PasswordGroup
+
+				res, err := ts.Client().Do(testhelpers.NewHTTPGetJSONRequest(t, ts.URL+"/error"))
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "system error", gjson.GetBytes(body, "error.reason").String(), "%s", body)
+				if tc.t == flow.TypeAPI {
+					assert.Equal(t, "database unavailable", gjson.GetBytes(body, "error.debug").String(), "%s", body)
+				} else {
+					assert.False(t, gjson.GetBytes(body, "error.debug").Exists(), "%s", body)
+				}
 			})
 		})
 	}
@@ -223,6 +253,32 @@ func TestHandleError(t *testing.T) {
 			assert.Equal(t, int(text.ErrorValidationLoginFlowExpired), int(lf.UI.Messages[0].ID))
 		})
 
+		t.Run("case=expired error with expired_as_status_for_browser", func(t *testing.T) {
+			t.Cleanup(reset)
+			conf.MustSet(ctx, config.ViperKeySelfServiceFlowsExpiredAsStatusForBrowser, true)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeySelfServiceFlowsExpiredAsStatusForBrowser, false)
+			})
+
+			loginFlow = &login.Flow{Type: flow.TypeBrowser}
+			flowError = flow.NewFlowExpiredError(anHourAgo)
+			ct = node.PasswordGroup
+
+			res, err := http.DefaultClient.Get(ts.URL + "/error")
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusGone, res.StatusCode, "%s", body)
+			assert.Equal(t, ts.URL+"/error", res.Request.URL.String(), "must not redirect to the login UI")
+
+			lf, err := reg.LoginFlowPersister().GetLoginFlow(ctx, uuid.FromStringOrNil(gjson.GetBytes(body, "use_flow_id").String()))
+			require.NoError(t, err, "%s", body)
+			require.Len(t, lf.UI.Messages, 1)
+			assert.Equal(t, int(text.ErrorValidationLoginFlowExpired), int(lf.UI.Messages[0].ID))
+		})
+
 		t.Run("case=validation error", func(t *testing.T) {
 			t.Cleanup(reset)
 
@@ -120,6 +120,8 @@ type Flow struct {
 	// RequestedAAL stores if the flow was requested to update the authenticator assurance level.
 	//
 	// This value can be one of "aal1", "aal2", "aal3".
+	// Clients can use it to detect whether the flow is a second factor step-up
+	// (`aal2`) or a regular first factor login (`aal1`).
 	RequestedAAL identity.AuthenticatorAssuranceLevel `json:"requested_aal" faker:"len=4" db:"requested_aal"`
 
 	// SessionTokenExchangeCode holds the secret code that the client can use to retrieve a session token after the login flow has been completed.
@@ -169,6 +171,7 @@ func NewFlow(conf *config.Config, exp time.Duration, csrf string, r *http.Reques
 		conf.SelfServiceBrowserDefaultReturnTo(r.Context()),
 		x.SecureRedirectUseSourceURL(requestURL),
 		x.SecureRedirectAllowURLs(conf.SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+		x.SecureRedirectRequireHTTPS(conf.SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
 		x.SecureRedirectAllowSelfServiceURLs(conf.SelfPublicURL(r.Context())),
 	)
 	if err != nil {
@@ -261,6 +264,10 @@ func (f *Flow) SetInternalContext(bytes sqlxx.JSONRawMessage) {
 func (f Flow) MarshalJSON() ([]byte, error) {
 	type local Flow
 	f.SetReturnTo()
+	f.IssuedAt = f.IssuedAt.UTC()
+	f.ExpiresAt = f.ExpiresAt.UTC()
+	f.CreatedAt = f.CreatedAt.UTC()
+	f.UpdatedAt = f.UpdatedAt.UTC()
 	return json.Marshal(local(f))
 }
 
@@ -293,6 +300,7 @@ func (f *Flow) SecureRedirectToOpts(ctx context.Context, cfg config.Provider) (o
 		x.SecureRedirectReturnTo(f.ReturnTo),
 		x.SecureRedirectUseSourceURL(f.RequestURL),
 		x.SecureRedirectAllowURLs(cfg.Config().SelfServiceBrowserAllowedReturnToDomains(ctx)),
+		x.SecureRedirectRequireHTTPS(cfg.Config().SelfServiceBrowserRequireHTTPSReturnTo(ctx)),
 		x.SecureRedirectAllowSelfServiceURLs(cfg.Config().SelfPublicURL(ctx)),
 		x.SecureRedirectOverrideDefaultReturnTo(cfg.Config().SelfServiceFlowLoginReturnTo(ctx, f.Active.String())),
 	}
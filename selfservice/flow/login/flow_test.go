@@ -202,6 +202,17 @@ func TestFlowEncodeJSON(t *testing.T) {
 	assert.EqualValues(t, "", gjson.Get(jsonx.TestMarshalJSONString(t, &login.Flow{RequestURL: "https://foo.bar?foo=bar"}), "return_to").String())
 	assert.EqualValues(t, "/bar", gjson.Get(jsonx.TestMarshalJSONString(t, &login.Flow{RequestURL: "https://foo.bar?return_to=/bar"}), "return_to").String())
 	assert.EqualValues(t, "/bar", gjson.Get(jsonx.TestMarshalJSONString(t, login.Flow{RequestURL: "https://foo.bar?return_to=/bar"}), "return_to").String())
+
+	t.Run("case=timestamps are encoded in UTC", func(t *testing.T) {
+		loc := time.FixedZone("UTC+2", 2*60*60)
+		at := time.Date(2024, 1, 1, 12, 0, 0, 0, loc)
+		f := &login.Flow{IssuedAt: at, ExpiresAt: at, CreatedAt: at, UpdatedAt: at}
+
+		encoded := jsonx.TestMarshalJSONString(t, f)
+		for _, key := range []string{"issued_at", "expires_at", "created_at", "updated_at"} {
+			assert.Equal(t, "2024-01-01T10:00:00Z", gjson.Get(encoded, key).String(), key)
+		}
+	})
 }
 
 func TestFlowDontOverrideReturnTo(t *testing.T) {
@@ -4,6 +4,7 @@
 package login
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -12,6 +13,8 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/ory/herodot"
 	hydraclientgo "github.com/ory/hydra-client-go/v2"
@@ -28,6 +31,7 @@ import (
 	"github.com/ory/kratos/x"
 	"github.com/ory/nosurf"
 	"github.com/ory/x/decoderx"
+	"github.com/ory/x/otelx"
 	"github.com/ory/x/sqlxx"
 	"github.com/ory/x/stringsx"
 	"github.com/ory/x/urlx"
@@ -58,6 +62,7 @@ type (
 		ErrorHandlerProvider
 		sessiontokenexchange.PersistenceProvider
 		x.LoggingProvider
+		x.TracingProvider
 	}
 	HandlerProvider interface {
 		LoginHandler() *Handler
@@ -115,12 +120,18 @@ func WithFormErrorMessage(messages []text.Message) FlowOption {
 	}
 }
 
-func (h *Handler) NewLoginFlow(w http.ResponseWriter, r *http.Request, ft flow.Type, opts ...FlowOption) (*Flow, *session.Session, error) {
+func (h *Handler) NewLoginFlow(w http.ResponseWriter, r *http.Request, ft flow.Type, opts ...FlowOption) (_ *Flow, _ *session.Session, err error) {
+	ctx, span := h.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.flow.login.Handler.NewLoginFlow",
+		trace.WithAttributes(attribute.String(flow.AttributeKeyFlowType, string(ft))))
+	r = r.WithContext(ctx)
+	defer otelx.End(span, &err)
+
 	conf := h.d.Config()
 	f, err := NewFlow(conf, conf.SelfServiceFlowLoginRequestLifespan(r.Context()), h.d.GenerateCSRFToken(r), r, ft)
 	if err != nil {
 		return nil, nil, err
 	}
+	span.SetAttributes(flow.SpanAttributes(f)...)
 	for _, o := range opts {
 		o(f)
 	}
@@ -138,8 +149,17 @@ func (h *Handler) NewLoginFlow(w http.ResponseWriter, r *http.Request, ft flow.T
 		return nil, nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to parse AuthenticationMethod Assurance Level (AAL): %s", cs.ToUnknownCaseErr()))
 	}
 
+	promptNone, err := isPromptNone(r)
+	if err != nil {
+		return nil, nil, err
+	} else if promptNone && f.Refresh {
+		return nil, nil, errors.WithStack(herodot.ErrBadRequest.WithReason("The query parameters `prompt=none` and `refresh=true` can not be combined."))
+	}
+
 	// We assume an error means the user has no session
+	stopDB := x.TrackServerTiming(r.Context(), x.ServerTimingDB)
 	sess, err := h.d.SessionManager().FetchFromRequest(r.Context(), r)
+	stopDB()
 	if e := new(session.ErrNoActiveSessionFound); errors.As(err, &e) {
 		// No session exists yet
 		returnSessionTokenExchangeCode, _ := strconv.ParseBool(r.URL.Query().Get("return_session_token_exchange_code"))
@@ -152,6 +172,11 @@ func (h *Handler) NewLoginFlow(w http.ResponseWriter, r *http.Request, ft flow.T
 			f.SessionTokenExchangeCode = e.InitCode
 		}
 
+		// Without a session we would have to show the login form.
+		if promptNone {
+			return nil, nil, errors.WithStack(ErrLoginRequired)
+		}
+
 		// We can not request an AAL > 1 because we must first verify the first factor.
 		if f.RequestedAAL > identity.AuthenticatorAssuranceLevel1 {
 			return nil, nil, errors.WithStack(ErrSessionRequiredForHigherAAL)
@@ -185,10 +210,24 @@ func (h *Handler) NewLoginFlow(w http.ResponseWriter, r *http.Request, ft flow.T
 		}
 
 		// Looks like we are requesting an AAL which is higher than what the session has.
+		if promptNone {
+			return nil, sess, errors.WithStack(ErrLoginRequired)
+		}
 		goto preLoginHook
 	}
 
 preLoginHook:
+	if reused, err := h.recentBrowserFlow(r, f); err != nil {
+		return nil, nil, err
+	} else if reused != nil {
+		// Reused flows must pass the pre-login hooks just like new flows do.
+		if err := h.d.LoginHookExecutor().PreLoginHook(w, r, reused); err != nil {
+			h.d.LoginFlowErrorHandler().WriteFlowError(w, r, reused, node.DefaultGroup, err)
+			return reused, sess, nil
+		}
+		return reused, nil, nil
+	}
+
 	var strategyFilters []StrategyFilter
 	orgID := uuid.NullUUID{
 		Valid: false,
@@ -211,11 +250,13 @@ preLoginHook:
 		strategyFilters = []StrategyFilter{func(s Strategy) bool { return s.ID() == identity.CredentialsTypeOIDC }}
 	}
 
+	stopHydration := x.TrackServerTiming(r.Context(), x.ServerTimingHydration)
 	for _, s := range h.d.LoginStrategies(r.Context(), strategyFilters...) {
 		if err := s.PopulateLoginMethod(r, f.RequestedAAL, f); err != nil {
 			return nil, nil, err
 		}
 	}
+	stopHydration()
 
 	if f.Refresh {
 		f.UI.Messages.Set(text.NewInfoLoginReAuth())
@@ -225,9 +266,13 @@ preLoginHook:
 		f.UI.Messages.Add(text.NewInfoLoginMFA())
 	}
 
+	flow.AddConfiguredUIMessages(r.Context(), h.d.Config(), f)
+
+	stopSchema := x.TrackServerTiming(r.Context(), x.ServerTimingSchema)
 	if err := sortNodes(r.Context(), f.UI.Nodes); err != nil {
 		return nil, nil, err
 	}
+	stopSchema()
 
 	if f.Type == flow.TypeBrowser {
 		f.UI.SetCSRF(h.d.GenerateCSRFToken(r))
@@ -238,13 +283,50 @@ preLoginHook:
 		return f, sess, nil
 	}
 
+	stopDB = x.TrackServerTiming(r.Context(), x.ServerTimingDB)
 	if err := h.d.LoginFlowPersister().CreateLoginFlow(r.Context(), f); err != nil {
 		return nil, nil, err
 	}
+	stopDB()
 
 	return f, nil, nil
 }
 
+// isPromptNone returns true if the `prompt=none` query parameter is set, which
+// asks to never render a login form.
+func isPromptNone(r *http.Request) (bool, error) {
+	switch prompt := r.URL.Query().Get("prompt"); prompt {
+	case "":
+		return false, nil
+	case "none":
+		return true, nil
+	default:
+		return false, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unsupported value %q for the query parameter `prompt`. Only `none` is supported.", prompt))
+	}
+}
+
+// recentBrowserFlow returns a login flow of the same browser and request URL which was
+// created within the configured reuse window, or nil if no such flow exists.
+func (h *Handler) recentBrowserFlow(r *http.Request, f *Flow) (*Flow, error) {
+	window := h.d.Config().SelfServiceFlowLoginReuseWithin(r.Context())
+	if window <= 0 || f.Type != flow.TypeBrowser {
+		return nil, nil
+	}
+
+	recent, err := h.d.LoginFlowPersister().ListRecentBrowserLoginFlows(r.Context(), f.RequestURL, time.Now().UTC().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	for k := range recent {
+		if nosurf.VerifyToken(f.CSRFToken, recent[k].CSRFToken) {
+			return &recent[k], nil
+		}
+	}
+
+	return nil, nil
+}
+
 func (h *Handler) FromOldFlow(w http.ResponseWriter, r *http.Request, of Flow) (*Flow, error) {
 	nf, _, err := h.NewLoginFlow(w, r, of.Type)
 	if err != nil {
@@ -301,6 +383,15 @@ type createNativeLoginFlow struct {
 	//
 	// in: query
 	Via string `json:"via"`
+
+	// Silently Check the Session
+	//
+	// If set to `none`, no login form is rendered. If a session with the requested AAL exists, it is
+	// returned immediately. Otherwise the error `login_required` is returned. Can not be combined with
+	// `refresh=true`.
+	//
+	// in: query
+	Prompt string `json:"prompt"`
 }
 
 // swagger:route GET /self-service/login/api frontend createNativeLoginFlow
@@ -314,6 +405,9 @@ type createNativeLoginFlow struct {
 //
 // To fetch an existing login flow call `/self-service/login/flows?flow=<flow_id>`.
 //
+// If the URL query parameter `?prompt=none` is set, no login form is rendered. If a session with the
+// requested AAL exists, the response body is that session instead of a login flow.
+//
 // You MUST NOT use this endpoint in client-side (Single Page Apps, ReactJS, AngularJS) nor server-side (Java Server
 // Pages, NodeJS, PHP, Golang, ...) browser applications. Using this endpoint in these applications will make
 // you vulnerable to a variety of CSRF attacks, including CSRF login attacks.
@@ -321,6 +415,7 @@ type createNativeLoginFlow struct {
 // In the case of an error, the `error.id` of the JSON response body can be one of:
 //
 // - `session_already_available`: The user is already signed in.
+// - `login_required`: `prompt=none` was set but no session with the requested AAL exists.
 // - `session_aal1_required`: Multi-factor auth (e.g. 2fa) was requested but the user has no session yet.
 // - `security_csrf_violation`: Unable to fetch the flow because a CSRF violation occurred.
 //
@@ -338,8 +433,12 @@ type createNativeLoginFlow struct {
 //	  400: errorGeneric
 //	  default: errorGeneric
 func (h *Handler) createNativeLoginFlow(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	f, _, err := h.NewLoginFlow(w, r, flow.TypeAPI)
-	if err != nil {
+	w, r = x.StartServerTiming(h.d, w, r)
+	f, sess, err := h.NewLoginFlow(w, r, flow.TypeAPI)
+	if promptNone, _ := isPromptNone(r); promptNone && errors.Is(err, ErrAlreadyLoggedIn) {
+		h.d.Writer().Write(w, r, sess)
+		return
+	} else if err != nil {
 		h.d.Writer().WriteError(w, r, err)
 		return
 	}
@@ -408,6 +507,15 @@ type createBrowserLoginFlow struct {
 	//
 	// in: query
 	Via string `json:"via"`
+
+	// Silently Check the Session
+	//
+	// If set to `none`, no login form is rendered. If a session with the requested AAL exists, it is
+	// returned immediately. Otherwise the error `login_required` is returned. Can not be combined with
+	// `refresh=true`.
+	//
+	// in: query
+	Prompt string `json:"prompt"`
 }
 
 // swagger:route GET /self-service/login/browser frontend createBrowserLoginFlow
@@ -422,10 +530,15 @@ type createBrowserLoginFlow struct {
 // exists already, the browser will be redirected to `urls.default_redirect_url` unless the query parameter
 // `?refresh=true` was set.
 //
+// If the URL query parameter `?prompt=none` is set, no login form is rendered. If a session with the
+// requested AAL exists, the browser is redirected to `return_to` or, for AJAX requests, the response
+// body is that session instead of a login flow.
+//
 // If this endpoint is called via an AJAX request, the response contains the flow without a redirect. In the
 // case of an error, the `error.id` of the JSON response body can be one of:
 //
 // - `session_already_available`: The user is already signed in.
+// - `login_required`: `prompt=none` was set but no session with the requested AAL exists.
 // - `session_aal1_required`: Multi-factor auth (e.g. 2fa) was requested but the user has no session yet.
 // - `security_csrf_violation`: Unable to fetch the flow because a CSRF violation occurred.
 // - `security_identity_mismatch`: The requested `?return_to` address is not allowed to be used. Adjust this in the configuration!
@@ -449,6 +562,7 @@ type createBrowserLoginFlow struct {
 //	  400: errorGeneric
 //	  default: errorGeneric
 func (h *Handler) createBrowserLoginFlow(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w, r = x.StartServerTiming(h.d, w, r)
 	var (
 		hydraLoginRequest   *hydraclientgo.OAuth2LoginRequest
 		hydraLoginChallenge sqlxx.NullString
@@ -518,12 +632,18 @@ func (h *Handler) createBrowserLoginFlow(w http.ResponseWriter, r *http.Request,
 		returnTo, redirErr := x.SecureRedirectTo(r, h.d.Config().SelfServiceBrowserDefaultReturnTo(r.Context()),
 			x.SecureRedirectAllowSelfServiceURLs(h.d.Config().SelfPublicURL(r.Context())),
 			x.SecureRedirectAllowURLs(h.d.Config().SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+			x.SecureRedirectRequireHTTPS(h.d.Config().SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
 		)
 		if redirErr != nil {
 			h.d.SelfServiceErrorManager().Forward(r.Context(), w, r, redirErr)
 			return
 		}
 
+		if promptNone, _ := isPromptNone(r); promptNone {
+			x.AcceptToRedirectOrJSON(w, r, h.d.Writer(), sess, returnTo.String())
+			return
+		}
+
 		x.AcceptToRedirectOrJSON(w, r, h.d.Writer(), err, returnTo.String())
 		return
 	} else if err != nil {
@@ -533,6 +653,7 @@ func (h *Handler) createBrowserLoginFlow(w http.ResponseWriter, r *http.Request,
 
 	a.HydraLoginRequest = hydraLoginRequest
 
+	flow.SetCSRFTokenHeader(r.Context(), h.d.Config(), w, a)
 	x.AcceptToRedirectOrJSON(w, r, h.d.Writer(), a, a.AppendTo(h.d.Config().SelfServiceFlowLoginUI(r.Context())).String())
 }
 
@@ -560,6 +681,15 @@ type getLoginFlow struct {
 	// in: header
 	// name: Cookie
 	Cookies string `json:"Cookie"`
+
+	// Response Format
+	//
+	// Set to `minimal` to only return the flow's ID, type, state, and the input nodes
+	// required to submit it, without script, text, image, or anchor nodes and node meta information.
+	// The response body then follows the `minimalFlow` schema.
+	//
+	// in: query
+	Format string `json:"format"`
 }
 
 // swagger:route GET /self-service/login/flows frontend getLoginFlow
@@ -643,6 +773,11 @@ func (h *Handler) getLoginFlow(w http.ResponseWriter, r *http.Request, _ httprou
 		ar.HydraLoginRequest = hlr
 	}
 
+	if flow.IsMinimalFormatRequested(r) {
+		h.d.Writer().Write(w, r, flow.NewMinimal(ar))
+		return
+	}
+
 	h.d.Writer().Write(w, r, ar)
 }
 
@@ -739,6 +874,11 @@ type updateLoginFlowBody struct{}
 //	  422: errorBrowserLocationChangeRequired
 //	  default: errorGeneric
 func (h *Handler) updateLoginFlow(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var err error
+	ctx, span := h.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.flow.login.Handler.updateLoginFlow")
+	r = r.WithContext(ctx)
+	defer otelx.End(span, &err)
+
 	rid, err := flow.GetFlowID(r)
 	if err != nil {
 		h.d.LoginFlowErrorHandler().WriteFlowError(w, r, nil, node.DefaultGroup, err)
@@ -750,6 +890,7 @@ func (h *Handler) updateLoginFlow(w http.ResponseWriter, r *http.Request, _ http
 		h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 		return
 	}
+	span.SetAttributes(flow.SpanAttributes(f)...)
 
 	sess, err := h.d.SessionManager().FetchFromRequest(r.Context(), r)
 	if err == nil {
@@ -765,7 +906,8 @@ func (h *Handler) updateLoginFlow(w http.ResponseWriter, r *http.Request, _ http
 
 		if x.IsJSONRequest(r) || f.Type == flow.TypeAPI {
 			// We are not upgrading AAL, nor are we refreshing. Error!
-			h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, errors.WithStack(ErrAlreadyLoggedIn))
+			err = errors.WithStack(ErrAlreadyLoggedIn)
+			h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 			return
 		}
 
@@ -775,7 +917,8 @@ func (h *Handler) updateLoginFlow(w http.ResponseWriter, r *http.Request, _ http
 		// Only failure scenario here is if we try to upgrade the session to a higher AAL without actually
 		// having a session.
 		if f.RequestedAAL > identity.AuthenticatorAssuranceLevel1 {
-			h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, errors.WithStack(ErrSessionRequiredForHigherAAL))
+			err = errors.WithStack(ErrSessionRequiredForHigherAAL)
+			h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 			return
 		}
 
@@ -786,21 +929,41 @@ func (h *Handler) updateLoginFlow(w http.ResponseWriter, r *http.Request, _ http
 	}
 
 continueLogin:
-	if err := f.Valid(); err != nil {
+	if err = f.Valid(); err != nil {
 		h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 		return
 	}
 
+	// The submission is counted before any strategy sees it, so that concurrent submissions
+	// can not exceed the limit. Counts of strategies which did not fail are released again.
+	limits := h.submissionLimits(r.Context())
+	if len(limits) > 0 {
+		if err = h.d.LoginFlowPersister().ReserveLoginFlowSubmission(r.Context(), f.ID, limits); err != nil {
+			h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
+			return
+		}
+	}
+	var failedStrategy string
+	defer func() {
+		h.releaseSubmission(r.Context(), f, limits, failedStrategy)
+	}()
+
 	var i *identity.Identity
 	var group node.UiNodeGroup
 	for _, ss := range h.d.AllLoginStrategies() {
-		interim, err := ss.Login(w, r, f, sess)
+		var interim *identity.Identity
+		interim, err = ss.Login(w, r, f, sess)
 		group = ss.NodeGroup()
 		if errors.Is(err, flow.ErrStrategyNotResponsible) {
 			continue
-		} else if errors.Is(err, flow.ErrCompletedByStrategy) {
+		}
+
+		span.SetAttributes(flow.StrategySpanAttribute(ss.ID().String()))
+		if errors.Is(err, flow.ErrCompletedByStrategy) {
+			err = nil
 			return
 		} else if err != nil {
+			failedStrategy = ss.ID().String()
 			h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, group, err)
 			return
 		}
@@ -818,11 +981,12 @@ continueLogin:
 	}
 
 	if i == nil {
-		h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, errors.WithStack(schema.NewNoLoginStrategyResponsible()))
+		err = errors.WithStack(schema.NewNoLoginStrategyResponsible())
+		h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 		return
 	}
 
-	if err := h.d.LoginHookExecutor().PostLoginHook(w, r, group, f, i, sess, ""); err != nil {
+	if err = h.d.LoginHookExecutor().PostLoginHook(w, r, group, f, i, sess, ""); err != nil {
 		if errors.Is(err, ErrAddressNotVerified) {
 			h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, errors.WithStack(schema.NewAddressNotVerifiedError()))
 			return
@@ -832,3 +996,30 @@ continueLogin:
 		return
 	}
 }
+
+// submissionLimits returns the `max_submissions` of all login strategies whose submissions are limited.
+func (h *Handler) submissionLimits(ctx context.Context) map[string]int {
+	limits := make(map[string]int)
+	for _, s := range h.d.AllLoginStrategies() {
+		if limit := h.d.Config().SelfServiceFlowLoginMaxSubmissions(ctx, s.ID().String()); limit > 0 {
+			limits[s.ID().String()] = limit
+		}
+	}
+	return limits
+}
+
+// releaseSubmission releases the submission counted for all limited strategies except the one which failed.
+func (h *Handler) releaseSubmission(ctx context.Context, f *Flow, limits map[string]int, failedStrategy string) {
+	strategies := make([]string, 0, len(limits))
+	for strategy := range limits {
+		if strategy != failedStrategy {
+			strategies = append(strategies, strategy)
+		}
+	}
+	if len(strategies) == 0 {
+		return
+	}
+	if err := h.d.LoginFlowPersister().ReleaseLoginFlowSubmission(ctx, f.ID, strategies); err != nil {
+		h.d.Logger().WithError(err).WithField("flow_id", f.ID).Warn("Unable to release the submission of a login flow.")
+	}
+}
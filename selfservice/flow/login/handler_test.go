@@ -546,6 +546,45 @@ func TestFlowLifecycle(t *testing.T) {
 				assert.Empty(t, gjson.GetBytes(body, "session_token_exchange_code").String())
 			})
 
+			t.Run("case=server timing", func(t *testing.T) {
+				t.Run("disabled", func(t *testing.T) {
+					res, _ := initFlow(t, url.Values{}, true)
+					assert.Empty(t, res.Header.Get("Server-Timing"))
+				})
+
+				t.Run("enabled", func(t *testing.T) {
+					conf.MustSet(ctx, config.ViperKeyPublicServerTiming, true)
+					t.Cleanup(func() {
+						conf.MustSet(ctx, config.ViperKeyPublicServerTiming, false)
+					})
+
+					res, body := initFlow(t, url.Values{}, true)
+					require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+					assert.Contains(t, res.Header.Get("Server-Timing"), "db;dur=")
+				})
+			})
+
+			t.Run("case=configured ui messages", func(t *testing.T) {
+				conf.MustSet(ctx, "selfservice.flows.login.ui_messages", []map[string]any{
+					{"text": "Maintenance at 2am"},
+					{"id": 1234567, "text": "Something went wrong", "type": "error"},
+				})
+				t.Cleanup(func() {
+					conf.MustSet(ctx, "selfservice.flows.login.ui_messages", nil)
+				})
+
+				res, body := initFlow(t, url.Values{}, true)
+				require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+
+				assert.EqualValues(t, text.InfoSelfServiceCustomMessage, gjson.GetBytes(body, "ui.messages.0.id").Int(), "%s", body)
+				assert.Equal(t, "Maintenance at 2am", gjson.GetBytes(body, "ui.messages.0.text").String(), "%s", body)
+				assert.Equal(t, "info", gjson.GetBytes(body, "ui.messages.0.type").String(), "%s", body)
+
+				assert.EqualValues(t, 1234567, gjson.GetBytes(body, "ui.messages.1.id").Int(), "%s", body)
+				assert.Equal(t, "Something went wrong", gjson.GetBytes(body, "ui.messages.1.text").String(), "%s", body)
+				assert.Equal(t, "error", gjson.GetBytes(body, "ui.messages.1.type").String(), "%s", body)
+			})
+
 			t.Run("case=returns session exchange code with any truthy value", func(t *testing.T) {
 				conf.MustSet(ctx, config.ViperKeyURLsAllowedReturnToDomains, []string{"https://www.ory.sh", "https://example.com"})
 				parameters := []string{"true", "True", "1"}
@@ -598,6 +637,32 @@ func TestFlowLifecycle(t *testing.T) {
 				res, body := initAuthenticatedFlow(t, url.Values{"aal": {"aal2"}}, true)
 				assert.Contains(t, res.Request.URL.String(), login.RouteInitAPIFlow)
 				assert.Equal(t, gjson.GetBytes(body, "ui.messages.0.text").String(), text.NewInfoLoginMFA().Text)
+				assert.Equal(t, "aal2", gjson.GetBytes(body, "requested_aal").String(), "%s", body)
+			})
+
+			t.Run("case=prompt=none returns the session if one exists", func(t *testing.T) {
+				res, body := initAuthenticatedFlow(t, url.Values{"prompt": {"none"}}, true)
+				assert.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+				assert.True(t, gjson.GetBytes(body, "active").Bool(), "%s", body)
+				assert.NotEmpty(t, gjson.GetBytes(body, "identity.id").String(), "%s", body)
+				assert.False(t, gjson.GetBytes(body, "ui").Exists(), "%s", body)
+			})
+
+			t.Run("case=prompt=none returns login_required without a session", func(t *testing.T) {
+				res, body := initFlow(t, url.Values{"prompt": {"none"}}, true)
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode, "%s", body)
+				assert.Equal(t, text.ErrIDLoginRequired, gjson.GetBytes(body, "error.id").String(), "%s", body)
+			})
+
+			t.Run("case=prompt=none returns login_required if the session has a lower aal", func(t *testing.T) {
+				res, body := initAuthenticatedFlow(t, url.Values{"prompt": {"none"}, "aal": {"aal2"}}, true)
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode, "%s", body)
+				assert.Equal(t, text.ErrIDLoginRequired, gjson.GetBytes(body, "error.id").String(), "%s", body)
+			})
+
+			t.Run("case=prompt=none can not be combined with refresh", func(t *testing.T) {
+				res, body := initAuthenticatedFlow(t, url.Values{"prompt": {"none"}, "refresh": {"true"}}, true)
+				assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
 			})
 
 			t.Run("case=does not set forced flag on unauthenticated request with refresh=true", func(t *testing.T) {
@@ -684,6 +749,54 @@ func TestFlowLifecycle(t *testing.T) {
 				res, body := initAuthenticatedFlow(t, url.Values{"aal": {"aal2"}}, false)
 				assert.Contains(t, res.Request.URL.String(), loginTS.URL)
 				assert.Equal(t, gjson.GetBytes(body, "ui.messages.0.text").String(), text.NewInfoLoginMFA().Text)
+				assert.Equal(t, "aal2", gjson.GetBytes(body, "requested_aal").String(), "%s", body)
+			})
+
+			t.Run("case=reuses recent flow within the configured window", func(t *testing.T) {
+				conf.MustSet(ctx, config.ViperKeySelfServiceLoginFlowReuseWithin, "1m")
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeySelfServiceLoginFlowReuseWithin, "0s")
+				})
+
+				// A unique query makes sure no flow from another test case is reused.
+				query := url.Values{"test": {x.NewUUID().String()}}
+				_, first := initSPAFlow(t, query)
+				_, second := initSPAFlow(t, query)
+				assert.NotEmpty(t, gjson.GetBytes(first, "id").String(), "%s", first)
+				assert.Equal(t, gjson.GetBytes(first, "id").String(), gjson.GetBytes(second, "id").String())
+
+				conf.MustSet(ctx, config.ViperKeySelfServiceLoginFlowReuseWithin, "0s")
+				_, third := initSPAFlow(t, query)
+				assert.NotEqual(t, gjson.GetBytes(first, "id").String(), gjson.GetBytes(third, "id").String())
+			})
+
+			t.Run("case=runs pre-login hooks when reusing a flow", func(t *testing.T) {
+				conf.MustSet(ctx, config.ViperKeySelfServiceLoginFlowReuseWithin, "1m")
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeySelfServiceLoginFlowReuseWithin, "0s")
+					conf.MustSet(ctx, config.ViperKeySelfServiceLoginBeforeHooks, nil)
+				})
+
+				query := url.Values{"test": {x.NewUUID().String()}}
+				_, first := initSPAFlow(t, query)
+				require.NotEmpty(t, gjson.GetBytes(first, "id").String(), "%s", first)
+
+				conf.MustSet(ctx, config.ViperKeySelfServiceLoginBeforeHooks, []config.SelfServiceHook{{Name: "err", Config: []byte(`{"ExecuteLoginPreHook": "err"}`)}})
+				res, second := initSPAFlow(t, query)
+				assert.Equal(t, http.StatusInternalServerError, res.StatusCode, "%s", second)
+				assert.NotEqual(t, gjson.GetBytes(first, "id").String(), gjson.GetBytes(second, "id").String(), "%s", second)
+			})
+
+			t.Run("case=prompt=none redirects to return_to if a session exists", func(t *testing.T) {
+				res, _ := initAuthenticatedFlow(t, url.Values{"prompt": {"none"}}, false)
+				assert.Contains(t, res.Request.URL.String(), "https://www.ory.sh")
+			})
+
+			t.Run("case=prompt=none returns login_required without a session", func(t *testing.T) {
+				res, body := initSPAFlow(t, url.Values{"prompt": {"none"}})
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode, "%s", body)
+				assert.Equal(t, text.ErrIDLoginRequired, gjson.GetBytes(body, "error.id").String(), "%s", body)
+				assert.NotContains(t, res.Request.URL.String(), loginTS.URL)
 			})
 
 			t.Run("case=makes request with JSON", func(t *testing.T) {
@@ -692,6 +805,21 @@ func TestFlowLifecycle(t *testing.T) {
 				assert.NotContains(t, res.Request.URL.String(), loginTS.URL)
 			})
 
+			t.Run("case=returns the csrf token in a header if enabled", func(t *testing.T) {
+				res, _ := initSPAFlow(t, url.Values{})
+				assert.Empty(t, res.Header.Get(flow.HeaderCSRFToken))
+
+				conf.MustSet(ctx, config.ViperKeySelfServiceFlowsCSRFTokenInHeader, true)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeySelfServiceFlowsCSRFTokenInHeader, nil)
+				})
+
+				res, body := initSPAFlow(t, url.Values{})
+				csrfToken := gjson.GetBytes(body, "ui.nodes.#(attributes.name==csrf_token).attributes.value").String()
+				require.NotEmpty(t, csrfToken, "%s", body)
+				assert.Equal(t, csrfToken, res.Header.Get(flow.HeaderCSRFToken))
+			})
+
 			t.Run("case=does not set forced flag on unauthenticated request with refresh=true", func(t *testing.T) {
 				res, body := initFlow(t, url.Values{"refresh": {"true"}}, false)
 				assertion(body, false, false)
@@ -839,6 +967,29 @@ func TestGetFlow(t *testing.T) {
 		assert.Contains(t, gjson.GetBytes(body, "ui.action").String(), public.URL, "%s", body)
 	})
 
+	t.Run("case=fetching minimal format", func(t *testing.T) {
+		client := testhelpers.NewClientWithCookies(t)
+		setupLoginUI(t, client)
+		full := testhelpers.EasyGetBody(t, client, public.URL+login.RouteInitBrowserFlow)
+		id := gjson.GetBytes(full, "id").String()
+		require.True(t, gjson.GetBytes(full, "ui.nodes.#(attributes.name==identifier).meta.label").Exists(), "%s", full)
+
+		body := testhelpers.EasyGetBody(t, client, public.URL+login.RouteGetFlow+"?format=minimal&id="+id)
+		assert.Equal(t, id, gjson.GetBytes(body, "id").String(), "%s", body)
+		assert.Equal(t, "browser", gjson.GetBytes(body, "type").String(), "%s", body)
+		assert.Equal(t, gjson.GetBytes(full, "ui.action").String(), gjson.GetBytes(body, "ui.action").String(), "%s", body)
+		assert.False(t, gjson.GetBytes(body, "request_url").Exists(), "%s", body)
+		assert.NotEmpty(t, gjson.GetBytes(body, "ui.nodes.#(attributes.name==csrf_token).attributes.value").String(), "%s", body)
+
+		nodes := gjson.GetBytes(body, "ui.nodes").Array()
+		require.NotEmpty(t, nodes, "%s", body)
+		assert.Len(t, nodes, len(gjson.GetBytes(full, "ui.nodes.#(type==input)#").Array()), "%s", body)
+		for _, n := range nodes {
+			assert.Equal(t, "input", n.Get("type").String(), "%s", body)
+			assert.JSONEq(t, "{}", n.Get("meta").Raw, "%s", body)
+		}
+	})
+
 	t.Run("case=csrf cookie missing", func(t *testing.T) {
 		client := http.DefaultClient
 		setupLoginUI(t, client)
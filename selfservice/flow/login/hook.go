@@ -48,6 +48,7 @@ type (
 		config.Provider
 		hydra.Provider
 		identity.PrivilegedPoolProvider
+		session.IssuancePolicyProvider
 		session.ManagementProvider
 		session.PersistenceProvider
 		x.CSRFTokenGeneratorProvider
@@ -128,7 +129,8 @@ func (e *HookExecutor) PostLoginHook(
 	provider string,
 ) (err error) {
 	ctx := r.Context()
-	ctx, span := e.d.Tracer(ctx).Tracer().Start(ctx, "HookExecutor.PostLoginHook")
+	ctx, span := e.d.Tracer(ctx).Tracer().Start(ctx, "selfservice.flow.login.HookExecutor.PostLoginHook",
+		trace.WithAttributes(append(flow.SpanAttributes(f), flow.StrategySpanAttribute(string(g)))...))
 	r = r.WithContext(ctx)
 	defer otelx.End(span, &err)
 
@@ -140,6 +142,10 @@ func (e *HookExecutor) PostLoginHook(
 		return err
 	}
 
+	if err := session.CheckIssuancePolicy(r.Context(), e.d.SessionIssuancePolicy(), i, f); err != nil {
+		return err
+	}
+
 	c := e.d.Config()
 	// Verify the redirect URL before we do any other processing.
 	returnTo, err := x.SecureRedirectTo(r,
@@ -147,6 +153,7 @@ func (e *HookExecutor) PostLoginHook(
 		x.SecureRedirectReturnTo(f.ReturnTo),
 		x.SecureRedirectUseSourceURL(f.RequestURL),
 		x.SecureRedirectAllowURLs(c.SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+		x.SecureRedirectRequireHTTPS(c.SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
 		x.SecureRedirectAllowSelfServiceURLs(c.SelfPublicURL(r.Context())),
 		x.SecureRedirectOverrideDefaultReturnTo(c.SelfServiceFlowLoginReturnTo(r.Context(), f.Active.String())),
 	)
@@ -5,6 +5,7 @@ package login_test
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/url"
 	"testing"
@@ -17,6 +18,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/tidwall/gjson"
 
+	"github.com/ory/herodot"
 	"github.com/ory/kratos/hydra"
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/session"
@@ -27,6 +29,7 @@ import (
 	"github.com/ory/kratos/internal/testhelpers"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/x"
 )
 
@@ -111,6 +114,40 @@ func TestLoginExecutor(t *testing.T) {
 					assert.Equal(t, "", body)
 				})
 
+				t.Run("case=consult the session issuance policy", func(t *testing.T) {
+					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
+
+					var allow bool
+					policy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						_, _ = w.Write([]byte(fmt.Sprintf(`{"allow":%t,"reason":"device posture check failed"}`, allow)))
+					}))
+					t.Cleanup(policy.Close)
+					conf.MustSet(ctx, config.ViperKeySessionIssuancePolicyURL, policy.URL)
+					t.Cleanup(func() {
+						conf.MustSet(ctx, config.ViperKeySessionIssuancePolicyURL, nil)
+					})
+
+					allow = true
+					res, _ := makeRequestPost(t, newServer(t, flow.TypeBrowser, nil), false, url.Values{})
+					assert.EqualValues(t, http.StatusOK, res.StatusCode)
+					assert.EqualValues(t, "https://www.ory.sh/", res.Request.URL.String())
+
+					allow = false
+					r := httptest.NewRequest("GET", "/login/post", nil)
+					f, err := login.NewFlow(conf, time.Minute, "", r, flow.TypeBrowser)
+					require.NoError(t, err)
+					f.Active = strategy
+					sess := session.NewInactiveSession()
+					sess.CompletedLoginFor(identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+
+					err = reg.LoginHookExecutor().PostLoginHook(httptest.NewRecorder(), r, strategy.ToUiNodeGroup(), f, testhelpers.SelfServiceHookCreateFakeIdentity(t, reg), sess, "")
+					var he *herodot.DefaultError
+					require.ErrorAs(t, err, &he)
+					assert.Equal(t, http.StatusForbidden, he.CodeField)
+					assert.Equal(t, text.ErrIDSessionIssuanceDenied, he.IDField)
+					assert.Equal(t, "device posture check failed", he.Reason())
+				})
+
 				t.Run("case=use return_to value", func(t *testing.T) {
 					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
 					conf.MustSet(ctx, config.ViperKeyURLsAllowedReturnToDomains, []string{"https://www.ory.sh/"})
@@ -323,6 +360,48 @@ func TestLoginExecutor(t *testing.T) {
 					assert.Equal(t, 2, len(ident.Credentials))
 				})
 
+				t.Run("sub-case=links matching identity with hashed identifiers", func(t *testing.T) {
+					conf.MustSet(ctx, config.ViperKeySelfServiceIdentifierStorage, config.IdentifierStorageHashed)
+					t.Cleanup(func() {
+						conf.MustSet(ctx, config.ViperKeySelfServiceIdentifierStorage, config.IdentifierStoragePlaintext)
+					})
+
+					email := testhelpers.RandomEmail()
+					useIdentity := &identity.Identity{
+						Traits: identity.Traits(`{"bar":"` + email + `"}`),
+						Credentials: map[identity.CredentialsType]identity.Credentials{
+							identity.CredentialsTypePassword: {
+								Type:        identity.CredentialsTypePassword,
+								Config:      []byte(`{"hashed_password": "$argon2id$v=19$m=32,t=2,p=4$cm94YnRVOW5jZzFzcVE4bQ$MNzk5BtR2vUhrp6qQEjRNw"}`),
+								Identifiers: []string{email},
+							},
+						},
+					}
+					require.NoError(t, reg.Persister().CreateIdentity(context.Background(), useIdentity))
+
+					credsOIDC, err := identity.NewCredentialsOIDC(
+						&identity.CredentialsOIDCEncryptedTokens{IDToken: "id-token", AccessToken: "access-token", RefreshToken: "refresh-token"},
+						"my-provider",
+						email,
+						"",
+					)
+					require.NoError(t, err)
+
+					res, _ := makeRequestPost(t, newServer(t, flow.TypeBrowser, useIdentity, func(l *login.Flow) {
+						require.NoError(t, flow.SetDuplicateCredentials(l, flow.DuplicateCredentialsData{
+							CredentialsType:     identity.CredentialsTypeOIDC,
+							CredentialsConfig:   credsOIDC.Config,
+							DuplicateIdentifier: email,
+						}))
+					}), false, url.Values{})
+					assert.EqualValues(t, http.StatusOK, res.StatusCode)
+					assert.EqualValues(t, "https://www.ory.sh/", res.Request.URL.String())
+
+					ident, err := reg.Persister().GetIdentity(ctx, useIdentity.ID, identity.ExpandCredentials)
+					require.NoError(t, err)
+					assert.Equal(t, 2, len(ident.Credentials))
+				})
+
 				t.Run("sub-case=errors on non-matching identity", func(t *testing.T) {
 					res, body := makeRequestPost(t, newServer(t, flow.TypeBrowser, useIdentity, func(l *login.Flow) {
 						require.NoError(t, flow.SetDuplicateCredentials(l, flow.DuplicateCredentialsData{
@@ -17,6 +17,12 @@ type (
 		GetLoginFlow(context.Context, uuid.UUID) (*Flow, error)
 		ForceLoginFlow(ctx context.Context, id uuid.UUID) error
 		DeleteExpiredLoginFlows(context.Context, time.Time, int) error
+		ListRecentBrowserLoginFlows(ctx context.Context, requestURL string, issuedAfter time.Time) ([]Flow, error)
+		// ReserveLoginFlowSubmission atomically counts a submission of the flow for each of the given
+		// strategies. It returns ErrFlowLocked and counts nothing if any strategy reached its limit.
+		ReserveLoginFlowSubmission(ctx context.Context, id uuid.UUID, limits map[string]int) error
+		// ReleaseLoginFlowSubmission reverts a submission counted for the given strategies.
+		ReleaseLoginFlowSubmission(ctx context.Context, id uuid.UUID, strategies []string) error
 	}
 	FlowPersistenceProvider interface {
 		LoginFlowPersister() FlowPersister
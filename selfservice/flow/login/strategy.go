@@ -31,6 +31,12 @@ type LinkableStrategy interface {
 	Link(ctx context.Context, i *identity.Identity, credentials sqlxx.JSONRawMessage) error
 }
 
+// AALRestrictedStrategy is implemented by strategies which can be enabled or
+// disabled separately as a first and as a second factor.
+type AALRestrictedStrategy interface {
+	EnabledForAAL(ctx context.Context, aal identity.AuthenticatorAssuranceLevel) bool
+}
+
 func (s Strategies) Strategy(id identity.CredentialsType) (Strategy, error) {
 	ids := make([]identity.CredentialsType, len(s))
 	for k, ss := range s {
@@ -51,6 +57,27 @@ func (s Strategies) MustStrategy(id identity.CredentialsType) Strategy {
 	return strategy
 }
 
+// EnabledFor returns the IDs of the strategies which authenticate at the given
+// assurance level. Called on the result of LoginStrategies, which only contains
+// enabled strategies, it lists the methods a login flow for that level offers.
+func (s Strategies) EnabledFor(ctx context.Context, aal identity.AuthenticatorAssuranceLevel) (ids []identity.CredentialsType) {
+	var amr session.AuthenticationMethods
+	if aal == identity.AuthenticatorAssuranceLevel2 {
+		// Second factors are only offered once a first factor was completed.
+		amr = session.AuthenticationMethods{{Method: identity.CredentialsTypePassword, AAL: identity.AuthenticatorAssuranceLevel1}}
+	}
+
+	for _, ss := range s {
+		if restricted, ok := ss.(AALRestrictedStrategy); ok && !restricted.EnabledForAAL(ctx, aal) {
+			continue
+		}
+		if ss.CompletedAuthenticationMethod(ctx, amr).AAL == aal {
+			ids = append(ids, ss.ID())
+		}
+	}
+	return ids
+}
+
 func (s Strategies) RegisterPublicRoutes(r *x.RouterPublic) {
 	for _, ss := range s {
 		ss.RegisterLoginRoutes(r)
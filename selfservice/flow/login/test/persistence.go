@@ -74,6 +74,31 @@ func TestFlowPersister(ctx context.Context, p persistence.Persister) func(t *tes
 			assertx.EqualAsJSON(t, expected.UI, actual.UI, "expected:\t%s\nactual:\t%s", expected.UI, actual.UI)
 		})
 
+		t.Run("case=should count submissions up to the limit", func(t *testing.T) {
+			f := newFlow(t)
+			require.NoError(t, p.CreateLoginFlow(ctx, f))
+			limits := map[string]int{"password": 2, "totp": 5}
+
+			require.NoError(t, p.ReserveLoginFlowSubmission(ctx, f.ID, limits))
+			require.NoError(t, p.ReserveLoginFlowSubmission(ctx, f.ID, limits))
+			require.ErrorIs(t, p.ReserveLoginFlowSubmission(ctx, f.ID, limits), login.ErrFlowLocked)
+
+			require.NoError(t, p.ReleaseLoginFlowSubmission(ctx, f.ID, []string{"password"}))
+			require.NoError(t, p.ReserveLoginFlowSubmission(ctx, f.ID, limits))
+			require.ErrorIs(t, p.ReserveLoginFlowSubmission(ctx, f.ID, limits), login.ErrFlowLocked)
+
+			t.Run("updating the flow keeps the counts", func(t *testing.T) {
+				require.NoError(t, p.UpdateLoginFlow(ctx, f))
+				require.ErrorIs(t, p.ReserveLoginFlowSubmission(ctx, f.ID, limits), login.ErrFlowLocked)
+			})
+
+			t.Run("counts are kept per flow", func(t *testing.T) {
+				other := newFlow(t)
+				require.NoError(t, p.CreateLoginFlow(ctx, other))
+				require.NoError(t, p.ReserveLoginFlowSubmission(ctx, other.ID, limits))
+			})
+		})
+
 		t.Run("case=should properly set the flow type", func(t *testing.T) {
 			expected := newFlow(t)
 			expected.Refresh = true
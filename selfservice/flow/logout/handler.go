@@ -157,6 +157,7 @@ func (h *Handler) createBrowserLogoutFlow(w http.ResponseWriter, r *http.Request
 			h.d.Config().SelfServiceFlowLogoutRedirectURL(r.Context()),
 			x.SecureRedirectUseSourceURL(requestURL.String()),
 			x.SecureRedirectAllowURLs(conf.SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+			x.SecureRedirectRequireHTTPS(conf.SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
 			x.SecureRedirectAllowSelfServiceURLs(conf.SelfPublicURL(r.Context())),
 		)
 		if err != nil {
@@ -357,6 +358,7 @@ func (h *Handler) completeLogout(w http.ResponseWriter, r *http.Request) {
 	ret, err := x.SecureRedirectTo(r, h.d.Config().SelfServiceFlowLogoutRedirectURL(r.Context()),
 		x.SecureRedirectUseSourceURL(r.RequestURI),
 		x.SecureRedirectAllowURLs(h.d.Config().SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+		x.SecureRedirectRequireHTTPS(h.d.Config().SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
 		x.SecureRedirectAllowSelfServiceURLs(h.d.Config().SelfPublicURL(r.Context())),
 	)
 	if err != nil {
@@ -0,0 +1,78 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package flow
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/ory/kratos/ui/container"
+	"github.com/ory/kratos/ui/node"
+)
+
+// FormatMinimal is the value of the `format` query parameter which requests
+// the minimal flow representation.
+const FormatMinimal = "minimal"
+
+// Minimal is the reduced representation of a flow which is returned when the
+// flow is fetched with `?format=minimal`. It only contains the fields and
+// input nodes required to submit the flow.
+//
+// swagger:model minimalFlow
+type Minimal struct {
+	// ID represents the flow's unique ID.
+	//
+	// required: true
+	ID uuid.UUID `json:"id"`
+
+	// Type represents the flow's type which can be either "api" or "browser".
+	//
+	// required: true
+	Type Type `json:"type"`
+
+	// State represents the state of this flow.
+	//
+	// required: true
+	State State `json:"state"`
+
+	// UI contains the input nodes of the flow without script, text, image,
+	// or anchor nodes, and with empty node meta information.
+	//
+	// required: true
+	UI *container.Container `json:"ui"`
+}
+
+// IsMinimalFormatRequested returns true if the request asks for the minimal
+// flow representation.
+func IsMinimalFormatRequested(r *http.Request) bool {
+	return r.URL.Query().Get("format") == FormatMinimal
+}
+
+// NewMinimal returns the minimal representation of the flow.
+func NewMinimal(f Flow) *Minimal {
+	ui := f.GetUI()
+	nodes := make(node.Nodes, 0, len(ui.Nodes))
+	for _, n := range ui.Nodes {
+		if n.Type != node.Input {
+			continue
+		}
+
+		minimal := *n
+		minimal.Meta = new(node.Meta)
+		nodes = append(nodes, &minimal)
+	}
+
+	return &Minimal{
+		ID:    f.GetID(),
+		Type:  f.GetType(),
+		State: f.GetState(),
+		UI: &container.Container{
+			Action:   ui.Action,
+			Method:   ui.Method,
+			Nodes:    nodes,
+			Messages: ui.Messages,
+		},
+	}
+}
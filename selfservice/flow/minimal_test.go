@@ -0,0 +1,80 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package flow
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/container"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+)
+
+func TestMinimal(t *testing.T) {
+	t.Run("case=detects the format parameter", func(t *testing.T) {
+		for query, expected := range map[string]bool{
+			"":               false,
+			"format=full":    false,
+			"format=minimal": true,
+		} {
+			assert.Equal(t, expected, IsMinimalFormatRequested(&http.Request{URL: &url.URL{RawQuery: query}}), query)
+		}
+	})
+
+	t.Run("case=only keeps input nodes without meta information", func(t *testing.T) {
+		f := &testFlow{
+			ID:    x.NewUUID(),
+			Type:  TypeBrowser,
+			State: StateChooseMethod,
+			UI: &container.Container{
+				Action: "https://www.ory.sh/self-service/login",
+				Method: "POST",
+				Nodes: node.Nodes{
+					node.NewCSRFNode(x.FakeCSRFToken),
+					node.NewInputField("identifier", "", node.DefaultGroup, node.InputAttributeTypeText).
+						WithMetaLabel(text.NewInfoNodeLabelID()),
+					node.NewScriptField("webauthn_script", "https://www.ory.sh/.well-known/ory/webauthn.js", node.WebAuthnGroup, ""),
+					node.NewTextField("totp_secret", text.NewInfoNodeLabelGenerated("secret"), node.TOTPGroup),
+					node.NewAnchorField("recovery", "https://www.ory.sh/recovery", node.DefaultGroup, text.NewInfoNodeLabelContinue()),
+					node.NewImageField("totp_qr", "data:image/png;base64,", node.TOTPGroup),
+					node.NewInputField("method", "password", node.PasswordGroup, node.InputAttributeTypeSubmit).
+						WithMetaLabel(text.NewInfoNodeLabelSubmit()),
+				},
+				Messages: text.Messages{*text.NewInfoLoginMFA()},
+			},
+		}
+
+		raw, err := json.Marshal(NewMinimal(f))
+		require.NoError(t, err)
+		body := string(raw)
+
+		assert.Equal(t, f.ID.String(), gjson.Get(body, "id").String(), "%s", body)
+		assert.Equal(t, "browser", gjson.Get(body, "type").String(), "%s", body)
+		assert.Equal(t, "choose_method", gjson.Get(body, "state").String(), "%s", body)
+		assert.Equal(t, f.UI.Action, gjson.Get(body, "ui.action").String(), "%s", body)
+		assert.Equal(t, f.UI.Method, gjson.Get(body, "ui.method").String(), "%s", body)
+		assert.EqualValues(t, text.InfoSelfServiceLoginMFA, gjson.Get(body, "ui.messages.0.id").Int(), "%s", body)
+		assert.False(t, gjson.Get(body, "request_url").Exists(), "%s", body)
+
+		nodes := gjson.Get(body, "ui.nodes").Array()
+		require.Len(t, nodes, 3, "%s", body)
+		for k, name := range []string{"csrf_token", "identifier", "method"} {
+			assert.Equal(t, "input", nodes[k].Get("type").String(), "%s", body)
+			assert.Equal(t, name, nodes[k].Get("attributes.name").String(), "%s", body)
+			assert.JSONEq(t, "{}", nodes[k].Get("meta").Raw, "%s", body)
+		}
+
+		// The flow itself is left untouched.
+		assert.Len(t, f.UI.Nodes, 7)
+		assert.NotNil(t, f.UI.Nodes.Find("identifier").Meta.Label)
+	})
+}
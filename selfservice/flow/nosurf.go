@@ -4,11 +4,17 @@
 package flow
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/x"
 )
 
+// HeaderCSRFToken is the response header carrying the flow's CSRF token if
+// `selfservice.flows.csrf_token_in_header` is enabled.
+const HeaderCSRFToken = "X-CSRF-Token"
+
 func GetCSRFToken(reg interface {
 	x.CSRFProvider
 	x.CSRFTokenGeneratorProvider
@@ -24,3 +30,20 @@ func GetCSRFToken(reg interface {
 
 	return token
 }
+
+// SetCSRFTokenHeader copies the CSRF token of the flow's UI into the
+// X-CSRF-Token response header, if enabled. The CSRF node is kept as is.
+func SetCSRFTokenHeader(ctx context.Context, conf *config.Config, w http.ResponseWriter, f Flow) {
+	if !conf.SelfServiceFlowCSRFTokenInHeader(ctx) || f.GetUI() == nil {
+		return
+	}
+
+	n := f.GetUI().Nodes.Find(x.CSRFTokenName)
+	if n == nil {
+		return
+	}
+
+	if token, ok := n.GetValue().(string); ok && len(token) > 0 {
+		w.Header().Set(HeaderCSRFToken, token)
+	}
+}
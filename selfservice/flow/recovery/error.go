@@ -102,6 +102,12 @@ func (s *ErrorHandler) WriteFlowError(
 			return
 		}
 
+		if f.Type == flow.TypeBrowser && s.d.Config().SelfServiceFlowExpiredAsStatusForBrowser(r.Context()) {
+			expiredError.FlowID = newFlow.ID
+			s.d.Writer().WriteError(w, r, expiredError)
+			return
+		}
+
 		if s.d.Config().UseContinueWithTransitions(r.Context()) {
 			switch {
 			case newFlow.Type.IsAPI():
@@ -164,7 +170,9 @@ func (s *ErrorHandler) forward(w http.ResponseWriter, r *http.Request, rr *Flow,
 		return
 	}
 
-	if rr.Type == flow.TypeAPI || x.IsJSONRequest(r) {
+	if rr.Type == flow.TypeAPI {
+		flow.WriteAPIFlowError(s.d, w, r, x.RecoverStatusCode(err, http.StatusBadRequest), err)
+	} else if x.IsJSONRequest(r) {
 		s.d.Writer().WriteErrorCode(w, r, x.RecoverStatusCode(err, http.StatusBadRequest), err)
 	} else {
 		s.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
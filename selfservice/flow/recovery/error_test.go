@@ -182,7 +182,37 @@ func TestHandleError(t *testing.T) {
 
 				body, err := io.ReadAll(res.Body)
 				require.NoError(t, err)
-				assert.JSONEq(t, x.MustEncodeJSON(t, flowError), gjson.GetBytes(body, "error").Raw)
+				if tc.t == flow.TypeAPI {
+					assert.JSONEq(t, `{"code":500,"status":"Internal Server Error","message":""}`, gjson.GetBytes(body, "error").Raw)
+				} else {
+					assert.JSONEq(t, x.MustEncodeJSON(t, flowError), gjson.GetBytes(body, "error").Raw)
+				}
+			})
+
+			t.Run("case=generic error with debug errors", func(t *testing.T) {
+				t.Cleanup(reset)
+				conf.MustSet(ctx, config.ViperKeySelfServiceAPIDebugErrors, true)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeySelfServiceAPIDebugErrors, false)
+				})
+
+				recoveryFlow = newFlow(t, time.Minute, tc.t)
+				flowError = herodot.ErrInternalServerError.WithReason("system error").WithDebug("database unavailable")
+				methodName = node.UiNodeGroup(recovery.RecoveryStrategyLink)
+
+				res, err := ts.Client().Do(testhelpers.NewHTTPGetJSONRequest(t, ts.URL+"/error"))
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "system error", gjson.GetBytes(body, "error.reason").String(), "%s", body)
+				if tc.t == flow.TypeAPI {
+					assert.Equal(t, "database unavailable", gjson.GetBytes(body, "error.debug").String(), "%s", body)
+				} else {
+					assert.False(t, gjson.GetBytes(body, "error.debug").Exists(), "%s", body)
+				}
 			})
 
 			t.Run("case=fails if active strategy is disabled", func(t *testing.T) {
@@ -231,6 +261,32 @@ func TestHandleError(t *testing.T) {
 			assert.Equal(t, int(text.ErrorValidationRecoveryFlowExpired), int(lf.UI.Messages[0].ID))
 		})
 
+		t.Run("case=expired error with expired_as_status_for_browser", func(t *testing.T) {
+			t.Cleanup(reset)
+			conf.MustSet(ctx, config.ViperKeySelfServiceFlowsExpiredAsStatusForBrowser, true)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeySelfServiceFlowsExpiredAsStatusForBrowser, false)
+			})
+
+			recoveryFlow = &recovery.Flow{Type: flow.TypeBrowser}
+			flowError = flow.NewFlowExpiredError(anHourAgo)
+			methodName = node.LinkGroup
+
+			res, err := ts.Client().Get(ts.URL + "/error")
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusGone, res.StatusCode, "%s", body)
+			assert.Equal(t, ts.URL+"/error", res.Request.URL.String(), "must not redirect to the recovery UI")
+
+			lf, err := reg.RecoveryFlowPersister().GetRecoveryFlow(ctx, uuid.FromStringOrNil(gjson.GetBytes(body, "use_flow_id").String()))
+			require.NoError(t, err, "%s", body)
+			require.Len(t, lf.UI.Messages, 1)
+			assert.Equal(t, int(text.ErrorValidationRecoveryFlowExpired), int(lf.UI.Messages[0].ID))
+		})
+
 		t.Run("case=validation error", func(t *testing.T) {
 			t.Cleanup(reset)
 
@@ -448,7 +504,37 @@ func TestHandleError_WithContinueWith(t *testing.T) {
 
 				body, err := io.ReadAll(res.Body)
 				require.NoError(t, err)
-				assert.JSONEq(t, x.MustEncodeJSON(t, flowError), gjson.GetBytes(body, "error").Raw)
+				if tc.t == flow.TypeAPI {
+					assert.JSONEq(t, `{"code":500,"status":"Internal Server Error","message":""}`, gjson.GetBytes(body, "error").Raw)
+				} else {
+					assert.JSONEq(t, x.MustEncodeJSON(t, flowError), gjson.GetBytes(body, "error").Raw)
+				}
+			})
+
+			t.Run("case=generic error with debug errors", func(t *testing.T) {
+				t.Cleanup(reset)
+				conf.MustSet(ctx, config.ViperKeySelfServiceAPIDebugErrors, true)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeySelfServiceAPIDebugErrors, false)
+				})
+
+				recoveryFlow = newFlow(t, time.Minute, tc.t)
+				flowError = herodot.ErrInternalServerError.WithReason("system error").WithDebug("database unavailable")
+				methodName = node.UiNodeGroup(recovery.RecoveryStrategyLink)
+
+				res, err := ts.Client().Do(testhelpers.NewHTTPGetJSONRequest(t, ts.URL+"/error"))
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "system error", gjson.GetBytes(body, "error.reason").String(), "%s", body)
+				if tc.t == flow.TypeAPI {
+					assert.Equal(t, "database unavailable", gjson.GetBytes(body, "error.debug").String(), "%s", body)
+				} else {
+					assert.False(t, gjson.GetBytes(body, "error.debug").Exists(), "%s", body)
+				}
 			})
 
 			t.Run("case=fails if active strategy is disabled", func(t *testing.T) {
@@ -497,6 +583,32 @@ func TestHandleError_WithContinueWith(t *testing.T) {
 			assert.Equal(t, int(text.ErrorValidationRecoveryFlowExpired), int(lf.UI.Messages[0].ID))
 		})
 
+		t.Run("case=expired error with expired_as_status_for_browser", func(t *testing.T) {
+			t.Cleanup(reset)
+			conf.MustSet(ctx, config.ViperKeySelfServiceFlowsExpiredAsStatusForBrowser, true)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeySelfServiceFlowsExpiredAsStatusForBrowser, false)
+			})
+
+			recoveryFlow = &recovery.Flow{Type: flow.TypeBrowser}
+			flowError = flow.NewFlowExpiredError(anHourAgo)
+			methodName = node.LinkGroup
+
+			res, err := ts.Client().Get(ts.URL + "/error")
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusGone, res.StatusCode, "%s", body)
+			assert.Equal(t, ts.URL+"/error", res.Request.URL.String(), "must not redirect to the recovery UI")
+
+			lf, err := reg.RecoveryFlowPersister().GetRecoveryFlow(ctx, uuid.FromStringOrNil(gjson.GetBytes(body, "use_flow_id").String()))
+			require.NoError(t, err, "%s", body)
+			require.Len(t, lf.UI.Messages, 1)
+			assert.Equal(t, int(text.ErrorValidationRecoveryFlowExpired), int(lf.UI.Messages[0].ID))
+		})
+
 		t.Run("case=validation error", func(t *testing.T) {
 			t.Cleanup(reset)
 
@@ -64,8 +64,8 @@ type Flow struct {
 	// ReturnTo contains the requested return_to URL.
 	ReturnTo string `json:"return_to,omitempty" db:"-"`
 
-	// Active, if set, contains the recovery method that is being used. It is initially
-	// not set.
+	// Active contains the recovery method that is being used. It is set when the flow
+	// is created to the method configured in `selfservice.flows.recovery.use`.
 	Active sqlxx.NullString `json:"active,omitempty" faker:"-" db:"active_method"`
 
 	// UI contains data which must be shown in the user interface.
@@ -82,6 +82,17 @@ type Flow struct {
 	// required: true
 	State State `json:"state" faker:"-" db:"state"`
 
+	// Step is the one-based step of the flow derived from its state, e.g. 2 for
+	// `sent_email`. It can be used to render a progress indicator.
+	//
+	// required: true
+	Step int `json:"step" faker:"-" db:"-"`
+
+	// TotalSteps is the number of steps of the flow.
+	//
+	// required: true
+	TotalSteps int `json:"total_steps" faker:"-" db:"-"`
+
 	// CSRFToken contains the anti-csrf token associated with this request.
 	CSRFToken string `json:"-" db:"csrf_token"`
 
@@ -122,6 +133,7 @@ func NewFlow(conf *config.Config, exp time.Duration, csrf string, r *http.Reques
 		conf.SelfServiceBrowserDefaultReturnTo(r.Context()),
 		x.SecureRedirectUseSourceURL(requestURL),
 		x.SecureRedirectAllowURLs(conf.SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+		x.SecureRedirectRequireHTTPS(conf.SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
 		x.SecureRedirectAllowSelfServiceURLs(conf.SelfPublicURL(r.Context())),
 	)
 	if err != nil {
@@ -206,6 +218,12 @@ func (f *Flow) SetCSRFToken(token string) {
 func (f Flow) MarshalJSON() ([]byte, error) {
 	type local Flow
 	f.SetReturnTo()
+	f.IssuedAt = f.IssuedAt.UTC()
+	f.ExpiresAt = f.ExpiresAt.UTC()
+	f.CreatedAt = f.CreatedAt.UTC()
+	f.UpdatedAt = f.UpdatedAt.UTC()
+	f.Step = flow.StepOf(f.State)
+	f.TotalSteps = flow.TotalSteps()
 	return json.Marshal(local(f))
 }
 
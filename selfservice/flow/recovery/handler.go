@@ -7,10 +7,14 @@ import (
 	"net/http"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/ory/nosurf"
 
 	"github.com/ory/kratos/schema"
 
+	"github.com/ory/x/otelx"
 	"github.com/ory/x/sqlcon"
 
 	"github.com/ory/kratos/ui/node"
@@ -52,6 +56,7 @@ type (
 		x.CSRFTokenGeneratorProvider
 		x.WriterProvider
 		x.CSRFProvider
+		x.TracingProvider
 		config.Provider
 		ErrorHandlerProvider
 		HookExecutorProvider
@@ -120,6 +125,12 @@ func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
 //	  400: errorGeneric
 //	  default: errorGeneric
 func (h *Handler) createNativeRecoveryFlow(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx, span := h.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.flow.recovery.Handler.createNativeRecoveryFlow",
+		trace.WithAttributes(attribute.String(flow.AttributeKeyFlowType, string(flow.TypeAPI))))
+	r = r.WithContext(ctx)
+	defer span.End()
+
+	w, r = x.StartServerTiming(h.d, w, r)
 	if !h.d.Config().SelfServiceFlowRecoveryEnabled(r.Context()) {
 		h.d.SelfServiceErrorManager().Forward(r.Context(), w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Recovery is not allowed because it was disabled.")))
 		return
@@ -135,16 +146,21 @@ func (h *Handler) createNativeRecoveryFlow(w http.ResponseWriter, r *http.Reques
 		h.d.Writer().WriteError(w, r, err)
 		return
 	}
+	span.SetAttributes(flow.SpanAttributes(f)...)
+
+	flow.AddConfiguredUIMessages(r.Context(), h.d.Config(), f)
 
 	if err := h.d.RecoveryExecutor().PreRecoveryHook(w, r, f); err != nil {
 		h.d.Writer().WriteError(w, r, err)
 		return
 	}
 
+	stopDB := x.TrackServerTiming(r.Context(), x.ServerTimingDB)
 	if err := h.d.RecoveryFlowPersister().CreateRecoveryFlow(r.Context(), f); err != nil {
 		h.d.Writer().WriteError(w, r, err)
 		return
 	}
+	stopDB()
 
 	h.d.Writer().Write(w, r, f)
 }
@@ -185,6 +201,12 @@ type createBrowserRecoveryFlow struct {
 //	  400: errorGeneric
 //	  default: errorGeneric
 func (h *Handler) createBrowserRecoveryFlow(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx, span := h.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.flow.recovery.Handler.createBrowserRecoveryFlow",
+		trace.WithAttributes(attribute.String(flow.AttributeKeyFlowType, string(flow.TypeBrowser))))
+	r = r.WithContext(ctx)
+	defer span.End()
+
+	w, r = x.StartServerTiming(h.d, w, r)
 	if !h.d.Config().SelfServiceFlowRecoveryEnabled(r.Context()) {
 		h.d.SelfServiceErrorManager().Forward(r.Context(), w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Recovery is not allowed because it was disabled.")))
 		return
@@ -200,17 +222,23 @@ func (h *Handler) createBrowserRecoveryFlow(w http.ResponseWriter, r *http.Reque
 		h.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
 		return
 	}
+	span.SetAttributes(flow.SpanAttributes(f)...)
+
+	flow.AddConfiguredUIMessages(r.Context(), h.d.Config(), f)
 
 	if err := h.d.RecoveryExecutor().PreRecoveryHook(w, r, f); err != nil {
 		h.d.Writer().WriteError(w, r, err)
 		return
 	}
 
+	stopDB := x.TrackServerTiming(r.Context(), x.ServerTimingDB)
 	if err := h.d.RecoveryFlowPersister().CreateRecoveryFlow(r.Context(), f); err != nil {
 		h.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
 		return
 	}
+	stopDB()
 
+	flow.SetCSRFTokenHeader(r.Context(), h.d.Config(), w, f)
 	redirTo := f.AppendTo(h.d.Config().SelfServiceFlowRecoveryUI(r.Context())).String()
 	x.AcceptToRedirectOrJSON(w, r, h.d.Writer(), f, redirTo)
 }
@@ -239,6 +267,15 @@ type getRecoveryFlow struct {
 	// in: header
 	// name: Cookie
 	Cookies string `json:"Cookie"`
+
+	// Response Format
+	//
+	// Set to `minimal` to only return the flow's ID, type, state, and the input nodes
+	// required to submit it, without script, text, image, or anchor nodes and node meta information.
+	// The response body then follows the `minimalFlow` schema.
+	//
+	// in: query
+	Format string `json:"format"`
 }
 
 // swagger:route GET /self-service/recovery/flows frontend getRecoveryFlow
@@ -311,6 +348,11 @@ func (h *Handler) getRecoveryFlow(w http.ResponseWriter, r *http.Request, _ http
 		return
 	}
 
+	if flow.IsMinimalFormatRequested(r) {
+		h.d.Writer().Write(w, r, flow.NewMinimal(f))
+		return
+	}
+
 	h.d.Writer().Write(w, r, f)
 }
 
@@ -400,6 +442,11 @@ type updateRecoveryFlowBody struct{}
 //	      422: errorBrowserLocationChangeRequired
 //	      default: errorGeneric
 func (h *Handler) updateRecoveryFlow(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var err error
+	ctx, span := h.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.flow.recovery.Handler.updateRecoveryFlow")
+	r = r.WithContext(ctx)
+	defer otelx.End(span, &err)
+
 	rid, err := flow.GetFlowID(r)
 	if err != nil {
 		h.d.RecoveryFlowErrorHandler().WriteFlowError(w, r, nil, node.DefaultGroup, err)
@@ -408,14 +455,16 @@ func (h *Handler) updateRecoveryFlow(w http.ResponseWriter, r *http.Request, ps
 
 	f, err := h.d.RecoveryFlowPersister().GetRecoveryFlow(r.Context(), rid)
 	if errors.Is(err, sqlcon.ErrNoRows) {
-		h.d.RecoveryFlowErrorHandler().WriteFlowError(w, r, nil, node.DefaultGroup, errors.WithStack(herodot.ErrNotFound.WithReasonf("The recovery request could not be found. Please restart the flow.")))
+		err = errors.WithStack(herodot.ErrNotFound.WithReasonf("The recovery request could not be found. Please restart the flow."))
+		h.d.RecoveryFlowErrorHandler().WriteFlowError(w, r, nil, node.DefaultGroup, err)
 		return
 	} else if err != nil {
 		h.d.RecoveryFlowErrorHandler().WriteFlowError(w, r, nil, node.DefaultGroup, err)
 		return
 	}
+	span.SetAttributes(flow.SpanAttributes(f)...)
 
-	if err := f.Valid(); err != nil {
+	if err = f.Valid(); err != nil {
 		h.d.RecoveryFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 		return
 	}
@@ -423,10 +472,14 @@ func (h *Handler) updateRecoveryFlow(w http.ResponseWriter, r *http.Request, ps
 	var g node.UiNodeGroup
 	var found bool
 	for _, ss := range h.d.AllRecoveryStrategies() {
-		err := ss.Recover(w, r, f)
+		err = ss.Recover(w, r, f)
 		if errors.Is(err, flow.ErrStrategyNotResponsible) {
 			continue
-		} else if errors.Is(err, flow.ErrCompletedByStrategy) {
+		}
+
+		span.SetAttributes(flow.StrategySpanAttribute(ss.RecoveryStrategyID()))
+		if errors.Is(err, flow.ErrCompletedByStrategy) {
+			err = nil
 			return
 		} else if err != nil {
 			h.d.RecoveryFlowErrorHandler().WriteFlowError(w, r, f, ss.NodeGroup(), err)
@@ -439,7 +492,8 @@ func (h *Handler) updateRecoveryFlow(w http.ResponseWriter, r *http.Request, ps
 	}
 
 	if !found {
-		h.d.RecoveryFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, errors.WithStack(schema.NewNoRecoveryStrategyResponsible()))
+		err = errors.WithStack(schema.NewNoRecoveryStrategyResponsible())
+		h.d.RecoveryFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 		return
 	}
 
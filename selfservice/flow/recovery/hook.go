@@ -10,6 +10,8 @@ import (
 
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/ory/x/otelx"
+
 	"github.com/ory/kratos/x/events"
 
 	"github.com/ory/kratos/driver/config"
@@ -62,6 +64,7 @@ type (
 		HooksProvider
 		x.CSRFTokenGeneratorProvider
 		x.LoggingProvider
+		x.TracingProvider
 		x.WriterProvider
 	}
 
@@ -80,7 +83,13 @@ func NewHookExecutor(d executorDependencies) *HookExecutor {
 	}
 }
 
-func (e *HookExecutor) PostRecoveryHook(w http.ResponseWriter, r *http.Request, a *Flow, s *session.Session) error {
+func (e *HookExecutor) PostRecoveryHook(w http.ResponseWriter, r *http.Request, a *Flow, s *session.Session) (err error) {
+	ctx := r.Context()
+	ctx, span := e.d.Tracer(ctx).Tracer().Start(ctx, "selfservice.flow.recovery.HookExecutor.PostRecoveryHook",
+		trace.WithAttributes(append(flow.SpanAttributes(a), flow.StrategySpanAttribute(a.Active.String()))...))
+	r = r.WithContext(ctx)
+	defer otelx.End(span, &err)
+
 	e.d.Logger().
 		WithRequest(r).
 		WithField("identity_id", s.Identity.ID).
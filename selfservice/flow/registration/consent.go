@@ -0,0 +1,127 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package registration
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/x/decoderx"
+)
+
+//go:embed .schema/consent.schema.json
+var consentSchema []byte
+
+var consentDecoder = decoderx.NewHTTP()
+
+const (
+	// consentNodeName is the name of the consent checkbox rendered during registration.
+	consentNodeName = "consent"
+
+	// internalContextConsentPath is the path in the flow's internal context
+	// storing the consented version until the identity is created.
+	internalContextConsentPath = "consent_version"
+)
+
+// addConsentNode adds the consent checkbox to the flow if consent is required.
+func (h *Handler) addConsentNode(ctx context.Context, f *Flow) {
+	c := h.d.Config().SelfServiceFlowRegistrationRequireConsent(ctx)
+	if !c.Enabled {
+		return
+	}
+
+	f.UI.Nodes.Upsert(
+		node.NewInputField(consentNodeName, false, node.DefaultGroup, node.InputAttributeTypeCheckbox, node.WithRequiredInputAttribute).
+			WithMetaLabel(text.NewInfoNodeLabelGenerated(c.Label)),
+	)
+}
+
+// checkConsent rejects the submission if consent is required but was not given. Otherwise,
+// the consented version is stored in the flow's internal context. Consent is only checked
+// once per flow, so later steps of multi-step registrations do not need to repeat it.
+func (h *Handler) checkConsent(r *http.Request, f *Flow) error {
+	c := h.d.Config().SelfServiceFlowRegistrationRequireConsent(r.Context())
+	if !c.Enabled || gjson.GetBytes(f.InternalContext, internalContextConsentPath).Exists() {
+		return nil
+	}
+
+	given, err := consentGiven(r)
+	if err != nil {
+		return err
+	}
+	if !given {
+		return schema.NewRequiredError("#/"+consentNodeName, consentNodeName)
+	}
+
+	f.EnsureInternalContext()
+	f.InternalContext, err = sjson.SetBytes(f.InternalContext, internalContextConsentPath, c.Version)
+	if err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithWrap(err))
+	}
+
+	return h.d.RegistrationFlowPersister().UpdateRegistrationFlow(r.Context(), f)
+}
+
+// consentGiven reads the consent checkbox from the request body. The body is kept
+// so that strategies can decode it.
+func consentGiven(r *http.Request) (bool, error) {
+	var p struct {
+		Consent bool `json:"consent" form:"consent"`
+	}
+
+	compiler, err := decoderx.HTTPRawJSONSchemaCompiler(consentSchema)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	if err := consentDecoder.Decode(r, &p, compiler,
+		decoderx.HTTPKeepRequestBody(true),
+		decoderx.HTTPDecoderAllowedMethods("POST", "PUT", "PATCH"),
+		decoderx.HTTPDecoderSetValidatePayloads(false),
+		decoderx.HTTPDecoderJSONFollowsFormFormat()); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return p.Consent, nil
+}
+
+// recordConsent stores the consented version in the identity's admin metadata. If consent
+// is required but was never given, for example because the identity is registered through
+// an OpenID Connect sign in without submitting the registration form, it fails instead.
+func recordConsent(ctx context.Context, c *config.Config, f *Flow, i *identity.Identity) error {
+	version := gjson.GetBytes(f.InternalContext, internalContextConsentPath)
+	if !version.Exists() {
+		if c.SelfServiceFlowRegistrationRequireConsent(ctx).Enabled {
+			return errors.WithStack(schema.NewRequiredError("#/"+consentNodeName, consentNodeName))
+		}
+		return nil
+	}
+
+	metadata := []byte(i.MetadataAdmin)
+	if !gjson.ParseBytes(metadata).IsObject() {
+		metadata = []byte("{}")
+	}
+
+	metadata, err := sjson.SetBytes(metadata, "consent", map[string]any{
+		"version":  version.String(),
+		"given_at": time.Now().UTC(),
+	})
+	if err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithWrap(err))
+	}
+	i.MetadataAdmin = metadata
+	return nil
+}
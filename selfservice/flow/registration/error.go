@@ -29,6 +29,10 @@ var (
 	ErrHookAbortFlow        = errors.New("aborted registration hook execution")
 	ErrAlreadyLoggedIn      = herodot.ErrBadRequest.WithID(text.ErrIDAlreadyLoggedIn).WithError("you are already logged in").WithReason("A valid session was detected and thus registration is not possible.")
 	ErrRegistrationDisabled = herodot.ErrBadRequest.WithID(text.ErrIDSelfServiceFlowDisabled).WithError("registration flow disabled").WithReason("Registration is not allowed because it was disabled.")
+
+	// ErrRegistrationSchemaNotAllowed is returned when the identity schema used for registration
+	// does not allow self-service registration.
+	ErrRegistrationSchemaNotAllowed = herodot.ErrBadRequest.WithError("identity schema not allowed for registration").WithReason("Registration is not allowed for the requested identity schema.")
 )
 
 type (
@@ -101,7 +105,7 @@ func (s *ErrorHandler) WriteFlowError(
 		s.forward(w, r, f, err)
 		return
 	} else if expired != nil {
-		if f.Type == flow.TypeAPI || x.IsJSONRequest(r) {
+		if f.Type == flow.TypeAPI || x.IsJSONRequest(r) || s.d.Config().SelfServiceFlowExpiredAsStatusForBrowser(r.Context()) {
 			s.d.Writer().WriteError(w, r, expired)
 		} else {
 			http.Redirect(w, r, expired.GetFlow().AppendTo(s.d.Config().SelfServiceFlowRegistrationUI(r.Context())).String(), http.StatusSeeOther)
@@ -159,7 +163,7 @@ func (s *ErrorHandler) forward(w http.ResponseWriter, r *http.Request, rr *Flow,
 	}
 
 	if rr.Type == flow.TypeAPI {
-		s.d.Writer().WriteErrorCode(w, r, x.RecoverStatusCode(err, http.StatusBadRequest), err)
+		flow.WriteAPIFlowError(s.d, w, r, x.RecoverStatusCode(err, http.StatusBadRequest), err)
 	} else {
 		s.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
 	}
@@ -181,7 +181,37 @@ func TestHandleError(t *testing.T) {
 
 				body, err := io.ReadAll(res.Body)
 				require.NoError(t, err)
-				assert.JSONEq(t, x.MustEncodeJSON(t, flowError), gjson.GetBytes(body, "error").Raw)
+				if tc.t == flow.TypeAPI {
+					assert.JSONEq(t, `{"code":500,"status":"Internal Server Error","message":""}`, gjson.GetBytes(body, "error").Raw)
+				} else {
+					assert.JSONEq(t, x.MustEncodeJSON(t, flowError), gjson.GetBytes(body, "error").Raw)
+				}
+			})
+
+			t.Run("case=generic error with debug errors", func(t *testing.T) {
+				t.Cleanup(reset)
+				conf.MustSet(ctx, config.ViperKeySelfServiceAPIDebugErrors, true)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeySelfServiceAPIDebugErrors, false)
+				})
+
+				registrationFlow = newFlow(t, time.Minute, tc.t)
+				flowError = herodot.ErrInternalServerError.WithReason("system error").WithDebug("database unavailable")
+				group = node.PasswordGroup
+
+				res, err := ts.Client().Do(testhelpers.NewHTTPGetJSONRequest(t, ts.URL+"/error"))
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "system error", gjson.GetBytes(body, "error.reason").String(), "%s", body)
+				if tc.t == flow.TypeAPI {
+					assert.Equal(t, "database unavailable", gjson.GetBytes(body, "error.debug").String(), "%s", body)
+				} else {
+					assert.False(t, gjson.GetBytes(body, "error.debug").Exists(), "%s", body)
+				}
 			})
 		})
 	}
@@ -139,6 +139,7 @@ func NewFlow(conf *config.Config, exp time.Duration, csrf string, r *http.Reques
 		conf.SelfServiceBrowserDefaultReturnTo(r.Context()),
 		x.SecureRedirectUseSourceURL(requestURL),
 		x.SecureRedirectAllowURLs(conf.SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+		x.SecureRedirectRequireHTTPS(conf.SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
 		x.SecureRedirectAllowSelfServiceURLs(conf.SelfPublicURL(r.Context())),
 	)
 	if err != nil {
@@ -215,6 +216,10 @@ func (f *Flow) SetInternalContext(bytes sqlxx.JSONRawMessage) {
 func (f Flow) MarshalJSON() ([]byte, error) {
 	type local Flow
 	f.SetReturnTo()
+	f.IssuedAt = f.IssuedAt.UTC()
+	f.ExpiresAt = f.ExpiresAt.UTC()
+	f.CreatedAt = f.CreatedAt.UTC()
+	f.UpdatedAt = f.UpdatedAt.UTC()
 	return json.Marshal(local(f))
 }
 
@@ -255,6 +260,7 @@ func (f *Flow) SecureRedirectToOpts(ctx context.Context, cfg config.Provider) (o
 		x.SecureRedirectReturnTo(f.ReturnTo),
 		x.SecureRedirectUseSourceURL(f.RequestURL),
 		x.SecureRedirectAllowURLs(cfg.Config().SelfServiceBrowserAllowedReturnToDomains(ctx)),
+		x.SecureRedirectRequireHTTPS(cfg.Config().SelfServiceBrowserRequireHTTPSReturnTo(ctx)),
 		x.SecureRedirectAllowSelfServiceURLs(cfg.Config().SelfPublicURL(ctx)),
 		x.SecureRedirectOverrideDefaultReturnTo(cfg.Config().SelfServiceFlowRegistrationReturnTo(ctx, f.Active.String())),
 	}
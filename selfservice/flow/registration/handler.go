@@ -4,6 +4,7 @@
 package registration
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"time"
@@ -11,6 +12,8 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/ory/herodot"
 	hydraclientgo "github.com/ory/hydra-client-go/v2"
@@ -26,6 +29,7 @@ import (
 	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
 	"github.com/ory/nosurf"
+	"github.com/ory/x/otelx"
 	"github.com/ory/x/sqlxx"
 	"github.com/ory/x/urlx"
 )
@@ -55,6 +59,7 @@ type (
 		ErrorHandlerProvider
 		sessiontokenexchange.PersistenceProvider
 		x.LoggingProvider
+		x.TracingProvider
 	}
 	HandlerProvider interface {
 		RegistrationHandler() *Handler
@@ -113,15 +118,25 @@ func WithFlowOAuth2LoginChallenge(loginChallenge string) FlowOption {
 	}
 }
 
-func (h *Handler) NewRegistrationFlow(w http.ResponseWriter, r *http.Request, ft flow.Type, opts ...FlowOption) (*Flow, error) {
+func (h *Handler) NewRegistrationFlow(w http.ResponseWriter, r *http.Request, ft flow.Type, opts ...FlowOption) (_ *Flow, err error) {
+	ctx, span := h.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.flow.registration.Handler.NewRegistrationFlow",
+		trace.WithAttributes(attribute.String(flow.AttributeKeyFlowType, string(ft))))
+	r = r.WithContext(ctx)
+	defer otelx.End(span, &err)
+
 	if !h.d.Config().SelfServiceFlowRegistrationEnabled(r.Context()) {
 		return nil, errors.WithStack(ErrRegistrationDisabled)
 	}
 
+	if err := h.checkRegistrationSchemaAllowed(r.Context()); err != nil {
+		return nil, err
+	}
+
 	f, err := NewFlow(h.d.Config(), h.d.Config().SelfServiceFlowRegistrationRequestLifespan(r.Context()), h.d.GenerateCSRFToken(r), r, ft)
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(flow.SpanAttributes(f)...)
 	for _, o := range opts {
 		o(f)
 	}
@@ -144,32 +159,57 @@ func (h *Handler) NewRegistrationFlow(w http.ResponseWriter, r *http.Request, ft
 			strategyFilters = []StrategyFilter{func(s Strategy) bool { return s.ID() == identity.CredentialsTypeOIDC }}
 		}
 	}
+	stopHydration := x.TrackServerTiming(r.Context(), x.ServerTimingHydration)
 	for _, s := range h.d.RegistrationStrategies(r.Context(), strategyFilters...) {
 		if err := s.PopulateRegistrationMethod(r, f); err != nil {
 			return nil, err
 		}
 	}
+	stopHydration()
+
+	h.addConsentNode(r.Context(), f)
+	flow.AddConfiguredUIMessages(r.Context(), h.d.Config(), f)
 
 	ds, err := h.d.Config().DefaultIdentityTraitsSchemaURL(r.Context())
 	if err != nil {
 		return nil, err
 	}
 
+	stopSchema := x.TrackServerTiming(r.Context(), x.ServerTimingSchema)
 	if err := SortNodes(r.Context(), f.UI.Nodes, ds.String()); err != nil {
 		return nil, err
 	}
+	stopSchema()
 
 	if err := h.d.RegistrationExecutor().PreRegistrationHook(w, r, f); err != nil {
 		return nil, err
 	}
 
+	stopDB := x.TrackServerTiming(r.Context(), x.ServerTimingDB)
 	if err := h.d.RegistrationFlowPersister().CreateRegistrationFlow(r.Context(), f); err != nil {
 		return nil, err
 	}
+	stopDB()
 
 	return f, nil
 }
 
+// checkRegistrationSchemaAllowed returns an error if the identity schema used for
+// self-service registration does not allow self-service registration.
+func (h *Handler) checkRegistrationSchemaAllowed(ctx context.Context) error {
+	schemas, err := h.d.Config().IdentityTraitsSchemas(ctx)
+	if err != nil {
+		return err
+	}
+
+	// A missing schema is reported once the identity is validated.
+	s, err := schemas.FindSchemaByID(h.d.Config().DefaultIdentityTraitsSchemaID(ctx))
+	if err == nil && !s.RegistrationAllowed() {
+		return errors.WithStack(ErrRegistrationSchemaNotAllowed)
+	}
+	return nil
+}
+
 func (h *Handler) FromOldFlow(w http.ResponseWriter, r *http.Request, of Flow) (*Flow, error) {
 	nf, err := h.NewRegistrationFlow(w, r, of.Type)
 	if err != nil {
@@ -211,6 +251,7 @@ func (h *Handler) FromOldFlow(w http.ResponseWriter, r *http.Request, of Flow) (
 //	  400: errorGeneric
 //	  default: errorGeneric
 func (h *Handler) createNativeRegistrationFlow(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w, r = x.StartServerTiming(h.d, w, r)
 	a, err := h.NewRegistrationFlow(w, r, flow.TypeAPI)
 	if err != nil {
 		h.d.Writer().WriteError(w, r, err)
@@ -313,6 +354,7 @@ type createBrowserRegistrationFlow struct {
 //	  303: emptyResponse
 //	  default: errorGeneric
 func (h *Handler) createBrowserRegistrationFlow(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w, r = x.StartServerTiming(h.d, w, r)
 	ctx := r.Context()
 
 	a, err := h.NewRegistrationFlow(w, r, flow.TypeBrowser)
@@ -398,6 +440,7 @@ func (h *Handler) createBrowserRegistrationFlow(w http.ResponseWriter, r *http.R
 		returnTo, redirErr := x.SecureRedirectTo(r, h.d.Config().SelfServiceBrowserDefaultReturnTo(ctx),
 			x.SecureRedirectAllowSelfServiceURLs(h.d.Config().SelfPublicURL(ctx)),
 			x.SecureRedirectAllowURLs(h.d.Config().SelfServiceBrowserAllowedReturnToDomains(ctx)),
+			x.SecureRedirectRequireHTTPS(h.d.Config().SelfServiceBrowserRequireHTTPSReturnTo(ctx)),
 		)
 		if redirErr != nil {
 			h.d.SelfServiceErrorManager().Forward(ctx, w, r, redirErr)
@@ -408,6 +451,7 @@ func (h *Handler) createBrowserRegistrationFlow(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	flow.SetCSRFTokenHeader(ctx, h.d.Config(), w, a)
 	redirTo := a.AppendTo(h.d.Config().SelfServiceFlowRegistrationUI(ctx)).String()
 	x.AcceptToRedirectOrJSON(w, r, h.d.Writer(), a, redirTo)
 }
@@ -436,6 +480,15 @@ type getRegistrationFlow struct {
 	// in: header
 	// name: Cookie
 	Cookies string `json:"Cookie"`
+
+	// Response Format
+	//
+	// Set to `minimal` to only return the flow's ID, type, state, and the input nodes
+	// required to submit it, without script, text, image, or anchor nodes and node meta information.
+	// The response body then follows the `minimalFlow` schema.
+	//
+	// in: query
+	Format string `json:"format"`
 }
 
 // swagger:route GET /self-service/registration/flows frontend getRegistrationFlow
@@ -524,6 +577,11 @@ func (h *Handler) getRegistrationFlow(w http.ResponseWriter, r *http.Request, ps
 		ar.HydraLoginRequest = hlr
 	}
 
+	if flow.IsMinimalFormatRequested(r) {
+		h.d.Writer().Write(w, r, flow.NewMinimal(ar))
+		return
+	}
+
 	h.d.Writer().Write(w, r, ar)
 }
 
@@ -615,6 +673,11 @@ type updateRegistrationFlowBody struct{}
 //	  422: errorBrowserLocationChangeRequired
 //	  default: errorGeneric
 func (h *Handler) updateRegistrationFlow(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var err error
+	ctx, span := h.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.flow.registration.Handler.updateRegistrationFlow")
+	r = r.WithContext(ctx)
+	defer otelx.End(span, &err)
+
 	rid, err := flow.GetFlowID(r)
 	if err != nil {
 		h.d.RegistrationFlowErrorHandler().WriteFlowError(w, r, nil, node.DefaultGroup, err)
@@ -626,18 +689,25 @@ func (h *Handler) updateRegistrationFlow(w http.ResponseWriter, r *http.Request,
 		h.d.RegistrationFlowErrorHandler().WriteFlowError(w, r, nil, node.DefaultGroup, err)
 		return
 	}
+	span.SetAttributes(flow.SpanAttributes(f)...)
 
-	if _, err := h.d.SessionManager().FetchFromRequest(r.Context(), r); err == nil {
+	if _, err = h.d.SessionManager().FetchFromRequest(r.Context(), r); err == nil {
 		if f.Type == flow.TypeBrowser {
 			http.Redirect(w, r, h.d.Config().SelfServiceBrowserDefaultReturnTo(r.Context()).String(), http.StatusSeeOther)
 			return
 		}
 
-		h.d.Writer().WriteError(w, r, errors.WithStack(ErrAlreadyLoggedIn))
+		err = errors.WithStack(ErrAlreadyLoggedIn)
+		h.d.Writer().WriteError(w, r, err)
 		return
 	}
 
-	if err := f.Valid(); err != nil {
+	if err = f.Valid(); err != nil {
+		h.d.RegistrationFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
+		return
+	}
+
+	if err = h.checkConsent(r, f); err != nil {
 		h.d.RegistrationFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 		return
 	}
@@ -645,9 +715,14 @@ func (h *Handler) updateRegistrationFlow(w http.ResponseWriter, r *http.Request,
 	i := identity.NewIdentity(h.d.Config().DefaultIdentityTraitsSchemaID(r.Context()))
 	var s Strategy
 	for _, ss := range h.d.AllRegistrationStrategies() {
-		if err := ss.Register(w, r, f, i); errors.Is(err, flow.ErrStrategyNotResponsible) {
+		err = ss.Register(w, r, f, i)
+		if errors.Is(err, flow.ErrStrategyNotResponsible) {
 			continue
-		} else if errors.Is(err, flow.ErrCompletedByStrategy) {
+		}
+
+		span.SetAttributes(flow.StrategySpanAttribute(ss.ID().String()))
+		if errors.Is(err, flow.ErrCompletedByStrategy) {
+			err = nil
 			return
 		} else if err != nil {
 			h.d.RegistrationFlowErrorHandler().WriteFlowError(w, r, f, ss.NodeGroup(), err)
@@ -659,11 +734,12 @@ func (h *Handler) updateRegistrationFlow(w http.ResponseWriter, r *http.Request,
 	}
 
 	if s == nil {
-		h.d.RegistrationFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, errors.WithStack(schema.NewNoRegistrationStrategyResponsible()))
+		err = errors.WithStack(schema.NewNoRegistrationStrategyResponsible())
+		h.d.RegistrationFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 		return
 	}
 
-	if err := h.d.RegistrationExecutor().PostRegistrationHook(w, r, s.ID(), "", f, i); err != nil {
+	if err = h.d.RegistrationExecutor().PostRegistrationHook(w, r, s.ID(), "", f, i); err != nil {
 		h.d.RegistrationFlowErrorHandler().WriteFlowError(w, r, f, s.NodeGroup(), err)
 		return
 	}
@@ -29,6 +29,7 @@ import (
 	"github.com/tidwall/gjson"
 
 	"github.com/ory/x/assertx"
+	"github.com/ory/x/pointerx"
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
@@ -316,6 +317,44 @@ func TestDisabledFlow(t *testing.T) {
 	})
 }
 
+func TestRegistrationSchemaNotAllowed(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+
+	conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationEnabled, true)
+	conf.MustSet(ctx, config.ViperKeyDefaultIdentitySchemaID, "default")
+	conf.MustSet(ctx, config.ViperKeyIdentitySchemas, config.Schemas{
+		{ID: "default", URL: "file://./stub/login.schema.json", SelfServiceRegistrationAllowed: pointerx.Ptr(false)},
+	})
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+string(identity.CredentialsTypePassword),
+		map[string]interface{}{"enabled": true})
+
+	publicTS, adminTS := testhelpers.NewKratosServerWithCSRF(t, reg)
+
+	t.Run("case=init fails", func(t *testing.T) {
+		res, err := publicTS.Client().Get(publicTS.URL + registration.RouteInitAPIFlow)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assertx.EqualAsJSON(t, registration.ErrRegistrationSchemaNotAllowed, json.RawMessage(gjson.GetBytes(body, "error").Raw), "%s", body)
+	})
+
+	t.Run("case=admin can still create identities", func(t *testing.T) {
+		res, err := adminTS.Client().Post(adminTS.URL+"/admin/identities", "application/json",
+			strings.NewReader(`{"schema_id":"default","traits":{"bar":"baz"}}`))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode, "%s", body)
+		assert.Equal(t, "default", gjson.GetBytes(body, "schema_id").String(), "%s", body)
+	})
+}
+
 func TestGetFlow(t *testing.T) {
 	ctx := context.Background()
 	conf, reg := internal.NewFastRegistryWithMocks(t)
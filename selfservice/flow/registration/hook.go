@@ -12,6 +12,7 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/hydra"
@@ -24,6 +25,7 @@ import (
 	"github.com/ory/kratos/x/events"
 	"github.com/ory/x/otelx"
 	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
 )
 
 type (
@@ -101,12 +103,31 @@ func NewHookExecutor(d executorDependencies) *HookExecutor {
 	return &HookExecutor{d: d}
 }
 
-func (e *HookExecutor) PostRegistrationHook(w http.ResponseWriter, r *http.Request, ct identity.CredentialsType, provider string, registrationFlow *Flow, i *identity.Identity) (err error) {
+func (e *HookExecutor) PostRegistrationHook(w http.ResponseWriter, r *http.Request, ct identity.CredentialsType, provider string, registrationFlow *Flow, i *identity.Identity, opts ...identity.ManagerOption) (err error) {
 	ctx := r.Context()
-	ctx, span := e.d.Tracer(ctx).Tracer().Start(ctx, "HookExecutor.PostRegistrationHook")
+	ctx, span := e.d.Tracer(ctx).Tracer().Start(ctx, "selfservice.flow.registration.HookExecutor.PostRegistrationHook",
+		trace.WithAttributes(append(flow.SpanAttributes(registrationFlow), flow.StrategySpanAttribute(ct.String()))...))
 	r = r.WithContext(ctx)
 	defer otelx.End(span, &err)
 
+	// Registrations which do not go through the registration endpoints, for example
+	// sign-ups through an OpenID Connect callback, are rejected here.
+	if e.d.Config().MaintenanceReadOnly(ctx) {
+		return errors.WithStack(x.ErrReadOnly.WithDebug("The configuration key maintenance.read_only is enabled."))
+	}
+
+	// Self-service registrations may start out inactive, for example when new identities
+	// need to be approved by an administrator first.
+	if state := identity.State(e.d.Config().SelfServiceFlowRegistrationDefaultIdentityState(ctx)); state != i.State {
+		stateChangedAt := sqlxx.NullTime(time.Now().UTC())
+		i.State = state
+		i.StateChangedAt = &stateChangedAt
+	}
+
+	if err := recordConsent(ctx, e.d.Config(), registrationFlow, i); err != nil {
+		return err
+	}
+
 	e.d.Logger().
 		WithRequest(r).
 		WithField("identity_id", i.ID).
@@ -154,7 +175,7 @@ func (e *HookExecutor) PostRegistrationHook(w http.ResponseWriter, r *http.Reque
 		return err
 		// We're now creating the identity because any of the hooks could trigger a "redirect" or a "session" which
 		// would imply that the identity has to exist already.
-	} else if err := e.d.IdentityManager().Create(r.Context(), i); err != nil {
+	} else if err := e.d.IdentityManager().Create(r.Context(), i, opts...); err != nil {
 		if errors.Is(err, sqlcon.ErrUniqueViolation) {
 			strategy, err := e.d.AllLoginStrategies().Strategy(ct)
 			if err != nil {
@@ -186,6 +207,7 @@ func (e *HookExecutor) PostRegistrationHook(w http.ResponseWriter, r *http.Reque
 		x.SecureRedirectReturnTo(registrationFlow.ReturnTo),
 		x.SecureRedirectUseSourceURL(registrationFlow.RequestURL),
 		x.SecureRedirectAllowURLs(c.SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+		x.SecureRedirectRequireHTTPS(c.SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
 		x.SecureRedirectAllowSelfServiceURLs(c.SelfPublicURL(r.Context())),
 		x.SecureRedirectOverrideDefaultReturnTo(c.SelfServiceFlowRegistrationReturnTo(r.Context(), ct.String())),
 	)
@@ -209,14 +231,21 @@ func (e *HookExecutor) PostRegistrationHook(w http.ResponseWriter, r *http.Reque
 
 	s.CompletedLoginForWithProvider(ct, identity.AuthenticatorAssuranceLevel1, provider,
 		httprouter.ParamsFromContext(r.Context()).ByName("organization"))
-	if err := s.Activate(r, i, c, time.Now().UTC()); err != nil {
-		return err
-	}
+	if i.IsActive() {
+		if err := s.Activate(r, i, c, time.Now().UTC()); err != nil {
+			return err
+		}
 
-	// We persist the session here so that subsequent hooks (like verification) can use it.
-	s.AuthenticatedAt = time.Now().UTC()
-	if err := e.d.SessionPersister().UpsertSession(r.Context(), s); err != nil {
-		return err
+		// We persist the session here so that subsequent hooks (like verification) can use it.
+		s.AuthenticatedAt = time.Now().UTC()
+		if err := e.d.SessionPersister().UpsertSession(r.Context(), s); err != nil {
+			return err
+		}
+	} else {
+		// Inactive identities can not sign in, so the session stays inactive and is never
+		// persisted. Hooks still get access to the identity through it.
+		s.Identity = i
+		s.IdentityID = i.ID
 	}
 
 	e.d.Logger().
@@ -278,21 +307,27 @@ func (e *HookExecutor) PostRegistrationHook(w http.ResponseWriter, r *http.Reque
 		if registrationFlow.IDToken != "" {
 			// We don't want to redirect with the code, if the flow was submitted with an ID token.
 			// This is the case for Sign in with native Apple SDK or Google SDK.
+		} else if !i.IsActive() {
+			// Inactive identities have no session which could be exchanged for a code.
 		} else if handled, err := e.d.SessionManager().MaybeRedirectAPICodeFlow(w, r, registrationFlow, s.ID, ct.ToUiNodeGroup()); err != nil {
 			return errors.WithStack(err)
 		} else if handled {
 			return nil
 		}
 
-		e.d.Writer().Write(w, r, &APIFlowResponse{
+		e.d.Writer().Write(w, r, (&APIFlowResponse{
 			Identity:     i,
 			ContinueWith: registrationFlow.ContinueWith(),
-		})
+		}).WithAddresses(r.Context(), e.d.Config()))
 		return nil
 	}
 
 	finalReturnTo := returnTo.String()
-	if registrationFlow.OAuth2LoginChallenge != "" {
+	if registrationFlow.OAuth2LoginChallenge != "" && !i.IsActive() {
+		// Inactive identities can not sign in, so the login request is left for
+		// the user to complete once the identity was activated.
+		span.SetAttributes(attribute.String("redirect_reason", "identity inactive"))
+	} else if registrationFlow.OAuth2LoginChallenge != "" {
 		if registrationFlow.ReturnToVerification != "" {
 			// Special case: If Kratos is used as a login UI *and* we want to show the verification UI,
 			// redirect to the verification URL first and then return to Hydra.
@@ -17,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
 
+	"github.com/ory/herodot"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/hydra"
 	"github.com/ory/kratos/identity"
@@ -25,7 +26,10 @@ import (
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/registration"
 	"github.com/ory/kratos/selfservice/hook"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/x"
+	"github.com/ory/x/sqlcon"
 )
 
 func TestRegistrationExecutor(t *testing.T) {
@@ -159,6 +163,38 @@ func TestRegistrationExecutor(t *testing.T) {
 					assert.NotEmpty(t, gjson.Get(body, "identity.id"))
 				})
 
+				t.Run("case=require consent", func(t *testing.T) {
+					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
+					conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationRequireConsent, map[string]interface{}{"enabled": true, "version": "v1"})
+					t.Cleanup(func() {
+						conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationRequireConsent, nil)
+					})
+
+					t.Run("case=fail if consent was not given", func(t *testing.T) {
+						// For example a registration through an OpenID Connect sign in.
+						i := testhelpers.SelfServiceHookFakeIdentity(t)
+						res, body := makeRequestPost(t, newServer(t, i, flow.TypeAPI), true, url.Values{})
+						assert.EqualValues(t, http.StatusInternalServerError, res.StatusCode, "%s", body)
+						assert.Contains(t, body, "consent")
+
+						_, err := reg.IdentityPool().GetIdentity(ctx, i.ID, identity.ExpandNothing)
+						require.Error(t, err)
+					})
+
+					t.Run("case=record the consent given in the flow", func(t *testing.T) {
+						withConsent := func(f *registration.Flow) {
+							f.InternalContext = []byte(`{"consent_version":"v1"}`)
+						}
+						i := testhelpers.SelfServiceHookFakeIdentity(t)
+						res, body := makeRequestPost(t, newServer(t, i, flow.TypeAPI, withConsent), true, url.Values{})
+						require.EqualValues(t, http.StatusOK, res.StatusCode, "%s", body)
+
+						actual, err := reg.PrivilegedIdentityPool().GetIdentity(ctx, i.ID, identity.ExpandNothing)
+						require.NoError(t, err)
+						assert.Equal(t, "v1", gjson.GetBytes(actual.MetadataAdmin, "consent.version").String(), "%s", actual.MetadataAdmin)
+					})
+				})
+
 				t.Run("case=pass without hooks for browser flow with application/json", func(t *testing.T) {
 					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
 
@@ -259,6 +295,84 @@ func TestRegistrationExecutor(t *testing.T) {
 					require.Len(t, cookies, 1)
 					assert.Equal(t, "ory_kratos_session", cookies[0].Name)
 				})
+
+				t.Run("case=registers inactive identities with default_identity_state=inactive", func(t *testing.T) {
+					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
+					previous := conf.GetProvider(ctx).Get(config.ViperKeySelfServiceRegistrationDefaultIdentityState)
+					conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationDefaultIdentityState, "inactive")
+					t.Cleanup(func() {
+						conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationDefaultIdentityState, previous)
+					})
+					viperSetPost(t, conf, strategy, []config.SelfServiceHook{{Name: hook.KeySessionIssuer}})
+
+					i := testhelpers.SelfServiceHookFakeIdentity(t)
+					jar := testhelpers.EasyCookieJar(t, nil)
+					s := newServer(t, i, flow.TypeBrowser)
+					s.Client().Jar = jar
+					res, _ := makeRequestPost(t, s, false, url.Values{})
+					assert.EqualValues(t, http.StatusOK, res.StatusCode)
+					assert.EqualValues(t, "https://www.ory.sh/", res.Request.URL.String())
+
+					u, err := url.Parse(s.URL)
+					require.NoError(t, err)
+					assert.Empty(t, jar.Cookies(u), "inactive identities must not receive a session")
+
+					actual, err := reg.IdentityPool().GetIdentity(ctx, i.ID, identity.ExpandNothing)
+					require.NoError(t, err)
+					assert.Equal(t, identity.StateInactive, actual.State)
+
+					req := testhelpers.NewTestHTTPRequest(t, "GET", s.URL, nil)
+					require.ErrorIs(t, session.NewInactiveSession().Activate(req, actual, conf, time.Now().UTC()), session.ErrIdentityDisabled)
+
+					actual.State = identity.StateActive
+					require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(ctx, actual))
+					require.NoError(t, session.NewInactiveSession().Activate(req, actual, conf, time.Now().UTC()))
+				})
+
+				t.Run("case=does not accept the login challenge for inactive identities", func(t *testing.T) {
+					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
+					previous := conf.GetProvider(ctx).Get(config.ViperKeySelfServiceRegistrationDefaultIdentityState)
+					conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationDefaultIdentityState, "inactive")
+					t.Cleanup(func() {
+						conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationDefaultIdentityState, previous)
+					})
+					viperSetPost(t, conf, strategy, []config.SelfServiceHook{{Name: hook.KeySessionIssuer}})
+
+					withOAuthChallenge := func(f *registration.Flow) {
+						f.OAuth2LoginChallenge = hydra.FakeValidLoginChallenge
+					}
+					res, _ := makeRequestPost(t, newServer(t, nil, flow.TypeBrowser, withOAuthChallenge), false, url.Values{})
+					assert.EqualValues(t, http.StatusOK, res.StatusCode)
+					assert.EqualValues(t, "https://www.ory.sh/", res.Request.URL.String())
+				})
+
+				t.Run("case=does not create the identity if the session issuance policy denies it", func(t *testing.T) {
+					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
+					viperSetPost(t, conf, strategy, []config.SelfServiceHook{{Name: hook.KeySessionIssuer}})
+
+					policy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						_, _ = w.Write([]byte(`{"allow":false,"reason":"device posture check failed"}`))
+					}))
+					t.Cleanup(policy.Close)
+					conf.MustSet(ctx, config.ViperKeySessionIssuancePolicyURL, policy.URL)
+					t.Cleanup(func() {
+						conf.MustSet(ctx, config.ViperKeySessionIssuancePolicyURL, nil)
+					})
+
+					i := testhelpers.SelfServiceHookFakeIdentity(t)
+					r := httptest.NewRequest("GET", "/registration/post", nil)
+					f, err := registration.NewFlow(conf, time.Minute, x.FakeCSRFToken, r, flow.TypeBrowser)
+					require.NoError(t, err)
+
+					err = reg.RegistrationHookExecutor().PostRegistrationHook(httptest.NewRecorder(), r, identity.CredentialsType(strategy), "", f, i)
+					var he *herodot.DefaultError
+					require.ErrorAs(t, err, &he)
+					assert.Equal(t, http.StatusForbidden, he.CodeField)
+					assert.Equal(t, text.ErrIDSessionIssuanceDenied, he.IDField)
+
+					_, err = reg.IdentityPool().GetIdentity(ctx, i.ID, identity.ExpandNothing)
+					require.ErrorIs(t, err, sqlcon.ErrNoRows)
+				})
 			})
 
 			for _, kind := range []flow.Type{flow.TypeBrowser, flow.TypeAPI} {
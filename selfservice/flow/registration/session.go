@@ -4,6 +4,9 @@
 package registration
 
 import (
+	"context"
+
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/session"
@@ -40,6 +43,16 @@ type APIFlowResponse struct {
 	// required: true
 	Identity *identity.Identity `json:"identity"`
 
+	// VerifiableAddresses contains the verifiable addresses of the created identity.
+	//
+	// This field is only set when `selfservice.flows.registration.include_addresses` is enabled.
+	VerifiableAddresses []identity.VerifiableAddress `json:"verifiable_addresses,omitempty"`
+
+	// RecoveryAddresses contains the recovery addresses of the created identity.
+	//
+	// This field is only set when `selfservice.flows.registration.include_addresses` is enabled.
+	RecoveryAddresses []identity.RecoveryAddress `json:"recovery_addresses,omitempty"`
+
 	// Contains a list of actions, that could follow this flow
 	//
 	// It can, for example, this will contain a reference to the verification flow, created as part of the user's
@@ -48,3 +61,15 @@ type APIFlowResponse struct {
 	// required: false
 	ContinueWith []flow.ContinueWith `json:"continue_with"`
 }
+
+// WithAddresses adds the identity's addresses to the response if
+// `selfservice.flows.registration.include_addresses` is enabled.
+func (r *APIFlowResponse) WithAddresses(ctx context.Context, c *config.Config) *APIFlowResponse {
+	if r.Identity == nil || !c.SelfServiceFlowRegistrationIncludeAddresses(ctx) {
+		return r
+	}
+
+	r.VerifiableAddresses = r.Identity.VerifiableAddresses
+	r.RecoveryAddresses = r.Identity.RecoveryAddresses
+	return r
+}
@@ -0,0 +1,63 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package settings
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/kratos/identity"
+)
+
+// changedTraits returns the JSON pointers of all traits which differ between
+// original and updated. Objects are compared key by key, every other value
+// (including arrays) is reported as a whole.
+func changedTraits(original, updated identity.Traits) ([]string, error) {
+	var o, u interface{}
+	if len(original) > 0 {
+		if err := json.Unmarshal(original, &o); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if len(updated) > 0 {
+		if err := json.Unmarshal(updated, &u); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	changed := []string{}
+	diffTraits("/traits", o, u, &changed)
+	return changed, nil
+}
+
+func diffTraits(pointer string, original, updated interface{}, changed *[]string) {
+	om, oIsObject := original.(map[string]interface{})
+	um, uIsObject := updated.(map[string]interface{})
+	if !oIsObject || !uIsObject {
+		if !reflect.DeepEqual(original, updated) {
+			*changed = append(*changed, pointer)
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(om)+len(um))
+	for k := range om {
+		keys = append(keys, k)
+	}
+	for k := range um {
+		if _, ok := om[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	escape := strings.NewReplacer("~", "~0", "/", "~1")
+	for _, k := range keys {
+		diffTraits(pointer+"/"+escape.Replace(k), om[k], um[k], changed)
+	}
+}
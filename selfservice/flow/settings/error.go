@@ -31,7 +31,15 @@ import (
 	"github.com/ory/kratos/x"
 )
 
-var ErrHookAbortFlow = errors.New("aborted settings hook execution")
+var (
+	ErrHookAbortFlow = errors.New("aborted settings hook execution")
+
+	// ErrIdentityModified is returned if optimistic concurrency is enabled and the identity
+	// was changed after the settings flow was loaded.
+	ErrIdentityModified = herodot.ErrConflict.WithID(text.ErrIDSelfServiceSettingsConflict).
+				WithError("identity was modified").
+				WithReason("The identity was modified after this settings flow was loaded. Please reload the flow and try again.")
+)
 
 type (
 	errorHandlerDependencies interface {
@@ -94,9 +102,15 @@ func (s *ErrorHandler) reauthenticate(
 	w http.ResponseWriter,
 	r *http.Request,
 	f *Flow,
+	group node.UiNodeGroup,
 	err *FlowNeedsReAuth,
 ) {
-	returnTo := urlx.CopyWithQuery(urlx.AppendPaths(s.d.Config().SelfPublicURL(r.Context()), r.URL.Path), r.URL.Query())
+	query := r.URL.Query()
+	if f.Type == flow.TypeBrowser && !x.IsJSONRequest(r) && group != "" && group != node.DefaultGroup {
+		// Remember the method the user was using so it can be selected again after re-authenticating.
+		query.Set(settingsMethodQueryParam, string(group))
+	}
+	returnTo := urlx.CopyWithQuery(urlx.AppendPaths(s.d.Config().SelfPublicURL(r.Context()), r.URL.Path), query)
 
 	params := url.Values{}
 	params.Set("refresh", "true")
@@ -132,6 +146,11 @@ func (s *ErrorHandler) PrepareReplacementForExpiredFlow(w http.ResponseWriter, r
 	return e.WithFlow(a), nil
 }
 
+// WriteFlowError handles errors of the settings flow. Session and expiry errors sent to API and SPA
+// clients carry a stable `error.id` (`session_inactive`, `session_aal2_required`,
+// `session_refresh_required`, or `self_service_flow_expired`) which clients can rely on instead of
+// parsing the error reason. Validation errors are returned as messages on the flow's UI instead, and
+// unexpected errors are not guaranteed to carry an ID.
 func (s *ErrorHandler) WriteFlowError(
 	w http.ResponseWriter,
 	r *http.Request,
@@ -185,7 +204,7 @@ func (s *ErrorHandler) WriteFlowError(
 			return
 		}
 
-		if f.Type == flow.TypeAPI || x.IsJSONRequest(r) {
+		if f.Type == flow.TypeAPI || x.IsJSONRequest(r) || s.d.Config().SelfServiceFlowExpiredAsStatusForBrowser(r.Context()) {
 			s.d.Writer().WriteError(w, r, expired)
 		} else {
 			http.Redirect(w, r, expired.GetFlow().AppendTo(s.d.Config().SelfServiceFlowSettingsUI(r.Context())).String(), http.StatusSeeOther)
@@ -203,7 +222,7 @@ func (s *ErrorHandler) WriteFlowError(
 	}
 
 	if e := new(FlowNeedsReAuth); errors.As(err, &e) {
-		s.reauthenticate(w, r, f, e)
+		s.reauthenticate(w, r, f, group, e)
 		return
 	}
 
@@ -259,7 +278,9 @@ func (s *ErrorHandler) forward(w http.ResponseWriter, r *http.Request, rr *Flow,
 		return
 	}
 
-	if rr.Type == flow.TypeAPI || x.IsJSONRequest(r) {
+	if rr.Type == flow.TypeAPI {
+		flow.WriteAPIFlowError(s.d, w, r, x.RecoverStatusCode(err, http.StatusBadRequest), err)
+	} else if x.IsJSONRequest(r) {
 		s.d.Writer().WriteErrorCode(w, r, x.RecoverStatusCode(err, http.StatusBadRequest), err)
 	} else {
 		s.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
@@ -169,6 +170,7 @@ func TestHandleError(t *testing.T) {
 				require.Equal(t, http.StatusGone, res.StatusCode, "%+v\n\t%s", res.Request, body)
 
 				assert.NotEqual(t, "00000000-0000-0000-0000-000000000000", gjson.GetBytes(body, "use_flow_id").String())
+				assert.Equal(t, text.ErrIDSelfServiceFlowExpired, gjson.GetBytes(body, "error.id").String(), "%s", body)
 				assertx.EqualAsJSONExcept(t, flow.NewFlowExpiredError(expiredAnHourAgo), json.RawMessage(body), []string{"since", "redirect_browser_to", "use_flow_id"})
 			})
 
@@ -224,6 +226,7 @@ func TestHandleError(t *testing.T) {
 				body, err := io.ReadAll(res.Body)
 				require.NoError(t, err)
 				assert.Equal(t, session.NewErrNoActiveSessionFound().Reason(), gjson.GetBytes(body, "error.reason").String(), "%s", body)
+				assert.Equal(t, text.ErrNoActiveSession, gjson.GetBytes(body, "error.id").String(), "%s", body)
 			})
 
 			t.Run("case=aal too low", func(t *testing.T) {
@@ -242,6 +245,25 @@ func TestHandleError(t *testing.T) {
 				body, err := io.ReadAll(res.Body)
 				require.NoError(t, err)
 				assertx.EqualAsJSON(t, session.NewErrAALNotSatisfied("a"), json.RawMessage(body))
+				assert.Equal(t, text.ErrIDHigherAALRequired, gjson.GetBytes(body, "error.id").String(), "%s", body)
+			})
+
+			t.Run("case=session old error", func(t *testing.T) {
+				t.Cleanup(reset)
+
+				settingsFlow = newFlow(t, time.Minute, tc.t)
+				flowError = settings.NewFlowNeedsReAuth()
+				flowMethod = settings.StrategyProfile
+
+				res, err := ts.Client().Do(testhelpers.NewHTTPGetJSONRequest(t, ts.URL+"/error"))
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, text.ErrIDNeedsPrivilegedSession, gjson.GetBytes(body, "error.id").String(), "%s", body)
+				assert.NotEmpty(t, gjson.GetBytes(body, "redirect_browser_to").String(), "%s", body)
 			})
 
 			t.Run("case=generic error", func(t *testing.T) {
@@ -258,7 +280,37 @@ func TestHandleError(t *testing.T) {
 
 				body, err := io.ReadAll(res.Body)
 				require.NoError(t, err)
-				assert.JSONEq(t, x.MustEncodeJSON(t, flowError), gjson.GetBytes(body, "error").Raw)
+				if tc.t == flow.TypeAPI {
+					assert.JSONEq(t, `{"code":500,"status":"Internal Server Error","message":""}`, gjson.GetBytes(body, "error").Raw)
+				} else {
+					assert.JSONEq(t, x.MustEncodeJSON(t, flowError), gjson.GetBytes(body, "error").Raw)
+				}
+			})
+
+			t.Run("case=generic error with debug errors", func(t *testing.T) {
+				t.Cleanup(reset)
+				conf.MustSet(ctx, config.ViperKeySelfServiceAPIDebugErrors, true)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeySelfServiceAPIDebugErrors, false)
+				})
+
+				settingsFlow = newFlow(t, time.Minute, tc.t)
+				flowError = herodot.ErrInternalServerError.WithReason("system error").WithDebug("database unavailable")
+				flowMethod = settings.StrategyProfile
+
+				res, err := ts.Client().Do(testhelpers.NewHTTPGetJSONRequest(t, ts.URL+"/error"))
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "system error", gjson.GetBytes(body, "error.reason").String(), "%s", body)
+				if tc.t == flow.TypeAPI {
+					assert.Equal(t, "database unavailable", gjson.GetBytes(body, "error.debug").String(), "%s", body)
+				} else {
+					assert.False(t, gjson.GetBytes(body, "error.debug").Exists(), "%s", body)
+				}
 			})
 		})
 	}
@@ -358,6 +410,7 @@ func TestHandleError(t *testing.T) {
 			require.NotEmpty(t, gjson.GetBytes(body, "error.reason").String(), "%s", body)
 			// We end up at the error endpoint with an aal2 error message because ts.client has no session.
 			assert.Equal(t, session.NewErrAALNotSatisfied("").Reason(), gjson.GetBytes(body, "error.reason").String(), "%s", body)
+			assert.Equal(t, text.ErrIDHigherAALRequired, gjson.GetBytes(body, "error.id").String(), "%s", body)
 		})
 
 		t.Run("case=session old error", func(t *testing.T) {
@@ -372,6 +425,12 @@ func TestHandleError(t *testing.T) {
 			require.NoError(t, err)
 			defer res.Body.Close()
 			require.Contains(t, res.Request.URL.String(), conf.GetProvider(ctx).String(config.ViperKeySelfServiceLoginUI))
+
+			lf, err := reg.LoginFlowPersister().GetLoginFlow(ctx, uuid.FromStringOrNil(res.Request.URL.Query().Get("flow")))
+			require.NoError(t, err)
+			returnTo, err := url.Parse(lf.ReturnTo)
+			require.NoError(t, err)
+			assert.Equal(t, settings.StrategyProfile, returnTo.Query().Get("settings_method"), "%s", lf.ReturnTo)
 		})
 
 		t.Run("case=validation error", func(t *testing.T) {
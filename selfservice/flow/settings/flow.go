@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"slices"
 	"time"
 
 	"github.com/gobuffalo/pop/v6"
@@ -18,6 +19,7 @@ import (
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/ui/container"
+	"github.com/ory/kratos/ui/node"
 	"github.com/ory/x/urlx"
 
 	"github.com/gofrs/uuid"
@@ -75,7 +77,7 @@ type Flow struct {
 	// ReturnTo contains the requested return_to URL.
 	ReturnTo string `json:"return_to,omitempty" db:"-"`
 
-	// Active, if set, contains the registration method that is being used. It is initially
+	// Active, if set, contains the settings method that is being used. It is initially
 	// not set.
 	Active sqlxx.NullString `json:"active,omitempty" db:"active_method"`
 
@@ -120,6 +122,20 @@ type Flow struct {
 	// required: false
 	ContinueWithItems []flow.ContinueWith `json:"continue_with,omitempty" db:"-" faker:"-" `
 
+	// Contains the JSON pointers (e.g. `/traits/email`) of the traits which were changed
+	// by a successful update.
+	//
+	// Only set if `selfservice.flows.settings.return_changed_traits` is enabled.
+	//
+	// required: false
+	ChangedTraits []string `json:"changed_traits,omitempty" db:"-" faker:"-"`
+
+	// Contains the node groups (e.g. `profile` or `password`) which were successfully
+	// updated by the submission.
+	//
+	// required: false
+	UpdatedGroupItems []node.UiNodeGroup `json:"updated_groups,omitempty" db:"-" faker:"-"`
+
 	// TransientPayload is used to pass data from the settings flow to hooks and email templates
 	//
 	// required: false
@@ -146,6 +162,7 @@ func NewFlow(conf *config.Config, exp time.Duration, r *http.Request, i *identit
 		conf.SelfServiceBrowserDefaultReturnTo(r.Context()),
 		x.SecureRedirectUseSourceURL(requestURL),
 		x.SecureRedirectAllowURLs(conf.SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+		x.SecureRedirectRequireHTTPS(conf.SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
 		x.SecureRedirectAllowSelfServiceURLs(conf.SelfPublicURL(r.Context())),
 	)
 	if err != nil {
@@ -215,6 +232,10 @@ func (f *Flow) EnsureInternalContext() {
 func (f Flow) MarshalJSON() ([]byte, error) {
 	type local Flow
 	f.SetReturnTo()
+	f.IssuedAt = f.IssuedAt.UTC()
+	f.ExpiresAt = f.ExpiresAt.UTC()
+	f.CreatedAt = f.CreatedAt.UTC()
+	f.UpdatedAt = f.UpdatedAt.UTC()
 	return json.Marshal(local(f))
 }
 
@@ -250,6 +271,18 @@ func (f *Flow) ContinueWith() []flow.ContinueWith {
 	return f.ContinueWithItems
 }
 
+func (f *Flow) AddUpdatedGroup(g node.UiNodeGroup) {
+	if slices.Contains(f.UpdatedGroupItems, g) {
+		return
+	}
+	f.UpdatedGroupItems = append(f.UpdatedGroupItems, g)
+}
+
+// UpdatedGroups returns the node groups which were successfully updated by the submission.
+func (f *Flow) UpdatedGroups() []node.UiNodeGroup {
+	return f.UpdatedGroupItems
+}
+
 func (f *Flow) GetState() State {
 	return f.State
 }
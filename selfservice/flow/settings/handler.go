@@ -4,16 +4,23 @@
 package settings
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/ory/herodot"
 	"github.com/ory/nosurf"
+	"github.com/ory/x/otelx"
 	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
 	"github.com/ory/x/urlx"
 
 	"github.com/ory/kratos/continuity"
@@ -37,6 +44,9 @@ const (
 	RouteSubmitFlow = "/self-service/settings"
 
 	ContinuityPrefix = "ory_kratos_settings"
+
+	// settingsMethodQueryParam carries the settings method across re-authentication.
+	settingsMethodQueryParam = "settings_method"
 )
 
 func ContinuityKey(id string) string {
@@ -48,6 +58,7 @@ type (
 		x.CSRFProvider
 		x.WriterProvider
 		x.LoggingProvider
+		x.TracingProvider
 
 		config.Provider
 
@@ -120,16 +131,23 @@ func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
 	admin.GET(RouteSubmitFlow, x.RedirectToPublicRoute(h.d))
 }
 
-func (h *Handler) NewFlow(w http.ResponseWriter, r *http.Request, i *identity.Identity, ft flow.Type) (*Flow, error) {
+func (h *Handler) NewFlow(w http.ResponseWriter, r *http.Request, i *identity.Identity, ft flow.Type) (_ *Flow, err error) {
+	ctx, span := h.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.flow.settings.Handler.NewFlow",
+		trace.WithAttributes(attribute.String(flow.AttributeKeyFlowType, string(ft))))
+	r = r.WithContext(ctx)
+	defer otelx.End(span, &err)
+
 	f, err := NewFlow(h.d.Config(), h.d.Config().SelfServiceFlowSettingsFlowLifespan(r.Context()), r, i, ft)
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(flow.SpanAttributes(f)...)
 
 	if err := h.d.SettingsHookExecutor().PreSettingsHook(w, r, f); err != nil {
 		return nil, err
 	}
 
+	stopHydration := x.TrackServerTiming(r.Context(), x.ServerTimingHydration)
 	for _, strategy := range h.d.SettingsStrategies(r.Context()) {
 		if err := h.d.ContinuityManager().Abort(r.Context(), w, r, ContinuityKey(strategy.SettingsStrategyID())); err != nil {
 			return nil, err
@@ -139,19 +157,30 @@ func (h *Handler) NewFlow(w http.ResponseWriter, r *http.Request, i *identity.Id
 			return nil, err
 		}
 	}
+	stopHydration()
+
+	flow.AddConfiguredUIMessages(r.Context(), h.d.Config(), f)
 
 	ds, err := h.d.Config().DefaultIdentityTraitsSchemaURL(r.Context())
 	if err != nil {
 		return nil, err
 	}
 
+	stopSchema := x.TrackServerTiming(r.Context(), x.ServerTimingSchema)
 	if err := sortNodes(r.Context(), f.UI.Nodes, ds.String()); err != nil {
 		return nil, err
 	}
+	stopSchema()
+
+	if err := h.storeIdentityUpdatedAt(r.Context(), f); err != nil {
+		return nil, err
+	}
 
+	stopDB := x.TrackServerTiming(r.Context(), x.ServerTimingDB)
 	if err := h.d.SettingsFlowPersister().CreateSettingsFlow(r.Context(), f); err != nil {
 		return nil, err
 	}
+	stopDB()
 
 	return f, nil
 }
@@ -213,7 +242,10 @@ type createNativeSettingsFlow struct {
 //		  400: errorGeneric
 //		  default: errorGeneric
 func (h *Handler) createNativeSettingsFlow(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w, r = x.StartServerTiming(h.d, w, r)
+	stopDB := x.TrackServerTiming(r.Context(), x.ServerTimingDB)
 	s, err := h.d.SessionManager().FetchFromRequest(r.Context(), r)
+	stopDB()
 	if err != nil {
 		h.d.Writer().WriteError(w, r, err)
 		return
@@ -296,7 +328,10 @@ type createBrowserSettingsFlow struct {
 //	  403: errorGeneric
 //	  default: errorGeneric
 func (h *Handler) createBrowserSettingsFlow(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w, r = x.StartServerTiming(h.d, w, r)
+	stopDB := x.TrackServerTiming(r.Context(), x.ServerTimingDB)
 	s, err := h.d.SessionManager().FetchFromRequest(r.Context(), r)
+	stopDB()
 	if err != nil {
 		h.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
 		return
@@ -319,6 +354,7 @@ func (h *Handler) createBrowserSettingsFlow(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	flow.SetCSRFTokenHeader(r.Context(), h.d.Config(), w, f)
 	redirTo := f.AppendTo(h.d.Config().SelfServiceFlowSettingsUI(r.Context())).String()
 	x.AcceptToRedirectOrJSON(w, r, h.d.Writer(), f, redirTo)
 }
@@ -355,6 +391,15 @@ type getSettingsFlow struct {
 	// in: header
 	// name: Cookie
 	Cookies string `json:"Cookie"`
+
+	// Response Format
+	//
+	// Set to `minimal` to only return the flow's ID, type, state, and the input nodes
+	// required to submit it, without script, text, image, or anchor nodes and node meta information.
+	// The response body then follows the `minimalFlow` schema.
+	//
+	// in: query
+	Format string `json:"format"`
 }
 
 // swagger:route GET /self-service/settings/flows frontend getSettingsFlow
@@ -440,6 +485,11 @@ func (h *Handler) fetchFlow(w http.ResponseWriter, r *http.Request) error {
 		return nil
 	}
 
+	if flow.IsMinimalFormatRequested(r) {
+		h.d.Writer().Write(w, r, flow.NewMinimal(pr))
+		return nil
+	}
+
 	h.d.Writer().Write(w, r, pr)
 	return nil
 }
@@ -557,6 +607,11 @@ type updateSettingsFlowBody struct{}
 //	  422: errorBrowserLocationChangeRequired
 //	  default: errorGeneric
 func (h *Handler) updateSettingsFlow(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var err error
+	ctx, span := h.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.flow.settings.Handler.updateSettingsFlow")
+	r = r.WithContext(ctx)
+	defer otelx.End(span, &err)
+
 	rid, err := GetFlowID(r)
 	if err != nil {
 		h.d.SettingsFlowErrorHandler().WriteFlowError(w, r, node.DefaultGroup, nil, nil, err)
@@ -565,12 +620,14 @@ func (h *Handler) updateSettingsFlow(w http.ResponseWriter, r *http.Request, ps
 
 	f, err := h.d.SettingsFlowPersister().GetSettingsFlow(r.Context(), rid)
 	if errors.Is(err, sqlcon.ErrNoRows) {
-		h.d.SettingsFlowErrorHandler().WriteFlowError(w, r, node.DefaultGroup, nil, nil, errors.WithStack(herodot.ErrNotFound.WithReasonf("The settings request could not be found. Please restart the flow.")))
+		err = errors.WithStack(herodot.ErrNotFound.WithReasonf("The settings request could not be found. Please restart the flow."))
+		h.d.SettingsFlowErrorHandler().WriteFlowError(w, r, node.DefaultGroup, nil, nil, err)
 		return
 	} else if err != nil {
 		h.d.SettingsFlowErrorHandler().WriteFlowError(w, r, node.DefaultGroup, nil, nil, err)
 		return
 	}
+	span.SetAttributes(flow.SpanAttributes(f)...)
 
 	ss, err := h.d.SessionManager().FetchFromRequest(r.Context(), r)
 	if err != nil {
@@ -579,23 +636,35 @@ func (h *Handler) updateSettingsFlow(w http.ResponseWriter, r *http.Request, ps
 	}
 
 	requestURL := x.RequestURL(r).String()
-	if err := h.d.SessionManager().DoesSessionSatisfy(r, ss, h.d.Config().SelfServiceSettingsRequiredAAL(r.Context()), session.WithRequestURL(requestURL)); err != nil {
+	if err = h.d.SessionManager().DoesSessionSatisfy(r, ss, h.d.Config().SelfServiceSettingsRequiredAAL(r.Context()), session.WithRequestURL(requestURL)); err != nil {
 		h.d.SettingsFlowErrorHandler().WriteFlowError(w, r, node.DefaultGroup, f, nil, err)
 		return
 	}
 
-	if err := f.Valid(ss); err != nil {
+	if err = f.Valid(ss); err != nil {
 		h.d.SettingsFlowErrorHandler().WriteFlowError(w, r, node.DefaultGroup, f, ss.Identity, err)
 		return
 	}
 
+	if err = h.checkIdentityUnmodified(r, f, ss.Identity); err != nil {
+		h.d.SettingsFlowErrorHandler().WriteFlowError(w, r, node.DefaultGroup, f, ss.Identity, err)
+		return
+	}
+
+	h.preselectMethod(r, f)
+
 	var s string
 	var updateContext *UpdateContext
 	for _, strat := range h.d.AllSettingsStrategies() {
-		uc, err := strat.Settings(w, r, f, ss)
+		var uc *UpdateContext
+		uc, err = strat.Settings(w, r, f, ss)
 		if errors.Is(err, flow.ErrStrategyNotResponsible) {
 			continue
-		} else if errors.Is(err, flow.ErrCompletedByStrategy) {
+		}
+
+		span.SetAttributes(flow.StrategySpanAttribute(strat.SettingsStrategyID()))
+		if errors.Is(err, flow.ErrCompletedByStrategy) {
+			err = nil
 			return
 		} else if err != nil {
 			h.d.SettingsFlowErrorHandler().WriteFlowError(w, r, strat.NodeGroup(), f, ss.Identity, err)
@@ -604,11 +673,13 @@ func (h *Handler) updateSettingsFlow(w http.ResponseWriter, r *http.Request, ps
 
 		s = strat.SettingsStrategyID()
 		updateContext = uc
+		f.AddUpdatedGroup(strat.NodeGroup())
 		break
 	}
 
 	if updateContext == nil {
-		h.d.SettingsFlowErrorHandler().WriteFlowError(w, r, node.DefaultGroup, f, ss.Identity, errors.WithStack(schema.NewNoSettingsStrategyResponsible()))
+		err = errors.WithStack(schema.NewNoSettingsStrategyResponsible())
+		h.d.SettingsFlowErrorHandler().WriteFlowError(w, r, node.DefaultGroup, f, ss.Identity, err)
 		return
 	}
 
@@ -619,8 +690,91 @@ func (h *Handler) updateSettingsFlow(w http.ResponseWriter, r *http.Request, ps
 		return
 	}
 
-	if err := h.d.SettingsHookExecutor().PostSettingsHook(w, r, s, updateContext, i); err != nil {
+	if err = h.d.SettingsHookExecutor().PostSettingsHook(w, r, s, updateContext, i); err != nil {
 		h.d.SettingsFlowErrorHandler().WriteFlowError(w, r, node.DefaultGroup, f, ss.Identity, err)
 		return
 	}
 }
+
+// preselectMethod marks the settings method the user was using before being asked to
+// re-authenticate as active when a browser flow is resumed.
+func (h *Handler) preselectMethod(r *http.Request, f *Flow) {
+	method := r.URL.Query().Get(settingsMethodQueryParam)
+	if method == "" || f.Type != flow.TypeBrowser {
+		return
+	}
+
+	for _, strat := range h.d.AllSettingsStrategies() {
+		if string(strat.NodeGroup()) == method {
+			f.Active = sqlxx.NullString(method)
+			return
+		}
+	}
+}
+
+// identityUpdatedAtKey is the internal context key of the identity's last modification time when the
+// settings flow was loaded.
+const identityUpdatedAtKey = "identity_updated_at"
+
+// storeIdentityUpdatedAt records when the identity was last modified before the flow was loaded, if
+// optimistic concurrency is enabled.
+func (h *Handler) storeIdentityUpdatedAt(ctx context.Context, f *Flow) error {
+	if !h.d.Config().SelfServiceSettingsOptimisticConcurrency(ctx) {
+		return nil
+	}
+
+	// The identity is re-fetched to get the modification time as stored by the database.
+	i, err := h.d.PrivilegedIdentityPool().GetIdentity(ctx, f.IdentityID, identity.ExpandNothing)
+	if err != nil {
+		return err
+	}
+
+	f.EnsureInternalContext()
+	f.InternalContext, err = sjson.SetBytes(f.InternalContext, identityUpdatedAtKey, i.UpdatedAt.UTC().Format(time.RFC3339Nano))
+	return errors.WithStack(err)
+}
+
+// identityUnmodifiedSince returns the point in time after which the identity must not have been modified
+// for the settings submission to succeed. This is the time given in the `If-Unmodified-Since` header or,
+// if absent, the identity's modification time when the flow was loaded or last successfully submitted.
+func identityUnmodifiedSince(r *http.Request, f *Flow) (since time.Time, ok bool, err error) {
+	if header := r.Header.Get("If-Unmodified-Since"); header != "" {
+		t, err := http.ParseTime(header)
+		if err != nil {
+			return time.Time{}, false, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to parse the If-Unmodified-Since header: %s", err))
+		}
+		// HTTP dates only have second precision, so any modification within that second is covered.
+		return t.Add(time.Second - time.Nanosecond), true, nil
+	}
+
+	stored := gjson.GetBytes(f.InternalContext, identityUpdatedAtKey).String()
+	if stored == "" {
+		// The flow was created before optimistic concurrency was enabled.
+		return time.Time{}, false, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, stored)
+	if err != nil {
+		return time.Time{}, false, errors.WithStack(err)
+	}
+	return t, true, nil
+}
+
+// checkIdentityUnmodified implements optimistic concurrency for settings submissions. If enabled, it fails
+// early if the identity was modified after the point in time returned by identityUnmodifiedSince. The
+// identity update itself repeats the check atomically, see HookExecutor.PostSettingsHook.
+func (h *Handler) checkIdentityUnmodified(r *http.Request, f *Flow, i *identity.Identity) error {
+	if !h.d.Config().SelfServiceSettingsOptimisticConcurrency(r.Context()) {
+		return nil
+	}
+
+	since, ok, err := identityUnmodifiedSince(r, f)
+	if err != nil || !ok {
+		return err
+	}
+
+	if i.UpdatedAt.After(since) {
+		return errors.WithStack(ErrIdentityModified)
+	}
+	return nil
+}
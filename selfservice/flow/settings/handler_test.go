@@ -40,6 +40,7 @@ import (
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/login"
 	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
 )
 
@@ -598,6 +599,34 @@ func TestHandler(t *testing.T) {
 				assert.Equal(t, "Your changes have been saved!", gjson.Get(actual, "ui.messages.0.text").String(), actual)
 			})
 		})
+
+		t.Run("description=resume - settings method is preselected", func(t *testing.T) {
+			_, body := initFlow(t, primaryUser, false)
+			id := gjson.GetBytes(body, "id").String()
+			require.NotEmpty(t, id, "%s", body)
+
+			res, err := primaryUser.Get(publicTS.URL + settings.RouteSubmitFlow + "?" + url.Values{"flow": {id}, "settings_method": {settings.StrategyProfile}}.Encode())
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			f, err := reg.SettingsFlowPersister().GetSettingsFlow(ctx, uuid.FromStringOrNil(id))
+			require.NoError(t, err)
+			assert.EqualValues(t, settings.StrategyProfile, f.Active.String())
+		})
+
+		t.Run("description=submit - updated groups are tracked", func(t *testing.T) {
+			_, body := initSPAFlow(t, primaryUser)
+			var f kratos.SettingsFlow
+			require.NoError(t, json.Unmarshal(body, &f))
+
+			actual, res := testhelpers.SettingsMakeRequest(t, false, true, &f, primaryUser, fmt.Sprintf(`{"method":"profile", "traits": {"numby": 16}, "csrf_token": "%s"}`, x.FakeCSRFToken))
+			require.Equal(t, http.StatusOK, res.StatusCode, actual)
+
+			var updated settings.Flow
+			require.NoError(t, json.Unmarshal([]byte(actual), &updated))
+			assert.Contains(t, updated.UpdatedGroups(), node.UiNodeGroup(settings.StrategyProfile))
+			assert.NotContains(t, updated.UpdatedGroups(), node.PasswordGroup)
+		})
 	})
 
 	t.Run("case=relative redirect when self-service settings ui is a relative url", func(t *testing.T) {
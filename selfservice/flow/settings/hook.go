@@ -19,6 +19,7 @@ import (
 	"github.com/ory/kratos/ui/container"
 	"github.com/ory/kratos/ui/node"
 
+	"github.com/ory/x/otelx"
 	"github.com/ory/x/sqlcon"
 
 	"github.com/ory/kratos/schema"
@@ -57,6 +58,7 @@ type (
 	executorDependencies interface {
 		identity.ManagementProvider
 		identity.ValidationProvider
+		identity.PoolProvider
 		session.ManagementProvider
 		config.Provider
 
@@ -66,6 +68,7 @@ type (
 
 		x.CSRFTokenGeneratorProvider
 		x.LoggingProvider
+		x.TracingProvider
 		x.WriterProvider
 	}
 	HookExecutor struct {
@@ -151,7 +154,12 @@ func (e *HookExecutor) handleSettingsError(_ http.ResponseWriter, r *http.Reques
 	return flowError
 }
 
-func (e *HookExecutor) PostSettingsHook(w http.ResponseWriter, r *http.Request, settingsType string, ctxUpdate *UpdateContext, i *identity.Identity, opts ...PostSettingsHookOption) error {
+func (e *HookExecutor) PostSettingsHook(w http.ResponseWriter, r *http.Request, settingsType string, ctxUpdate *UpdateContext, i *identity.Identity, opts ...PostSettingsHookOption) (err error) {
+	ctx, span := e.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.flow.settings.HookExecutor.PostSettingsHook",
+		trace.WithAttributes(append(flow.SpanAttributes(ctxUpdate.Flow), flow.StrategySpanAttribute(settingsType))...))
+	r = r.WithContext(ctx)
+	defer otelx.End(span, &err)
+
 	e.d.Logger().
 		WithRequest(r).
 		WithField("identity_id", i.ID).
@@ -163,6 +171,7 @@ func (e *HookExecutor) PostSettingsHook(w http.ResponseWriter, r *http.Request,
 	returnTo, err := x.SecureRedirectTo(r, c.SelfServiceBrowserDefaultReturnTo(r.Context()),
 		x.SecureRedirectUseSourceURL(ctxUpdate.Flow.RequestURL),
 		x.SecureRedirectAllowURLs(c.SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+		x.SecureRedirectRequireHTTPS(c.SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
 		x.SecureRedirectAllowSelfServiceURLs(c.SelfPublicURL(r.Context())),
 		x.SecureRedirectOverrideDefaultReturnTo(
 			e.d.Config().SelfServiceFlowSettingsReturnTo(r.Context(), settingsType,
@@ -215,6 +224,25 @@ func (e *HookExecutor) PostSettingsHook(w http.ResponseWriter, r *http.Request,
 		options = append(options, identity.ManagerAllowWriteProtectedTraits)
 	}
 
+	if c.SelfServiceSettingsOptimisticConcurrency(r.Context()) {
+		since, ok, err := identityUnmodifiedSince(r, ctxUpdate.Flow)
+		if err != nil {
+			return err
+		}
+		if ok {
+			options = append(options, identity.ManagerRequireUnmodifiedSince(since))
+		}
+	}
+
+	var originalTraits identity.Traits
+	if c.SelfServiceSettingsReturnChangedTraits(r.Context()) {
+		original, err := e.d.IdentityPool().GetIdentity(r.Context(), i.ID, identity.ExpandNothing)
+		if err != nil {
+			return err
+		}
+		originalTraits = original.Traits
+	}
+
 	if err := e.d.IdentityManager().Update(r.Context(), i, options...); err != nil {
 		if errors.Is(err, identity.ErrProtectedFieldModified) {
 			e.d.Logger().WithError(err).Debug("Modifying protected field requires re-authentication.")
@@ -223,6 +251,9 @@ func (e *HookExecutor) PostSettingsHook(w http.ResponseWriter, r *http.Request,
 		if errors.Is(err, sqlcon.ErrUniqueViolation) {
 			return schema.NewDuplicateCredentialsError(err)
 		}
+		if errors.Is(err, identity.ErrModifiedSince) {
+			return errors.WithStack(ErrIdentityModified)
+		}
 		return err
 	}
 	e.d.Audit().
@@ -232,6 +263,12 @@ func (e *HookExecutor) PostSettingsHook(w http.ResponseWriter, r *http.Request,
 
 	ctxUpdate.UpdateIdentity(i)
 	ctxUpdate.Flow.State = flow.StateSuccess
+	if c.SelfServiceSettingsReturnChangedTraits(r.Context()) {
+		ctxUpdate.Flow.ChangedTraits, err = changedTraits(originalTraits, i.Traits)
+		if err != nil {
+			return err
+		}
+	}
 	if hookOptions.cb != nil {
 		if err := hookOptions.cb(ctxUpdate); err != nil {
 			return err
@@ -292,6 +329,8 @@ func (e *HookExecutor) PostSettingsHook(w http.ResponseWriter, r *http.Request,
 		// ContinueWith items are transient items, not stored in the database, and need to be carried over here, so
 		// they can be returned to the client.
 		updatedFlow.ContinueWithItems = ctxUpdate.Flow.ContinueWithItems
+		updatedFlow.ChangedTraits = ctxUpdate.Flow.ChangedTraits
+		updatedFlow.UpdatedGroupItems = ctxUpdate.Flow.UpdatedGroupItems
 
 		e.d.Writer().Write(w, r, updatedFlow)
 		return nil
@@ -309,6 +348,8 @@ func (e *HookExecutor) PostSettingsHook(w http.ResponseWriter, r *http.Request,
 		// ContinueWith items are transient items, not stored in the database, and need to be carried over here, so
 		// they can be returned to the client.
 		updatedFlow.ContinueWithItems = ctxUpdate.Flow.ContinueWithItems
+		updatedFlow.ChangedTraits = ctxUpdate.Flow.ChangedTraits
+		updatedFlow.UpdatedGroupItems = ctxUpdate.Flow.UpdatedGroupItems
 
 		e.d.Writer().Write(w, r, updatedFlow)
 		return nil
@@ -9,6 +9,8 @@ import (
 	"encoding/json"
 
 	"github.com/pkg/errors"
+
+	"github.com/ory/kratos/identity"
 )
 
 // Flow State
@@ -17,6 +19,7 @@ import (
 //
 // - choose_method: ask the user to choose a method (e.g. recover account via email)
 // - sent_email: the email has been sent to the user
+// - sent_sms: the SMS has been sent to the user
 // - passed_challenge: the request was successful and the recovery challenge was passed.
 // - show_form: a form is shown to the user to perform the flow
 // - success: the flow has been completed successfully
@@ -28,6 +31,7 @@ type State string
 const (
 	StateChooseMethod    State = "choose_method"
 	StateEmailSent       State = "sent_email"
+	StateSMSSent         State = "sent_sms"
 	StatePassedChallenge State = "passed_challenge"
 	StateShowForm        State = "show_form"
 	StateSuccess         State = "success"
@@ -36,6 +40,10 @@ const (
 var states = []State{StateChooseMethod, StateEmailSent, StatePassedChallenge}
 
 func indexOf(current State) int {
+	if current == StateSMSSent {
+		// Sending an SMS is equivalent to sending an email.
+		current = StateEmailSent
+	}
 	for k, s := range states {
 		if s == current {
 			return k
@@ -48,6 +56,25 @@ func HasReachedState(expected, actual State) bool {
 	return indexOf(actual) >= indexOf(expected)
 }
 
+// StepOf returns the one-based step of the given state in the recovery and verification
+// flows, e.g. for rendering a progress indicator.
+func StepOf(current State) int {
+	return indexOf(current) + 1
+}
+
+// TotalSteps returns the number of steps of the recovery and verification flows.
+func TotalSteps() int {
+	return len(states)
+}
+
+// SentState returns the state of a flow after a message was sent through the given channel.
+func SentState(channel string) State {
+	if channel == identity.ChannelTypeSMS {
+		return StateSMSSent
+	}
+	return StateEmailSent
+}
+
 func NextState(current State) State {
 	if current == StatePassedChallenge {
 		return StatePassedChallenge
@@ -7,14 +7,28 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ory/kratos/identity"
 )
 
 func TestState(t *testing.T) {
 	assert.EqualValues(t, StateEmailSent, NextState(StateChooseMethod))
 	assert.EqualValues(t, StatePassedChallenge, NextState(StateEmailSent))
+	assert.EqualValues(t, StatePassedChallenge, NextState(StateSMSSent))
 	assert.EqualValues(t, StatePassedChallenge, NextState(StatePassedChallenge))
 
+	assert.EqualValues(t, StateEmailSent, SentState(identity.ChannelTypeEmail))
+	assert.EqualValues(t, StateSMSSent, SentState(identity.ChannelTypeSMS))
+
 	assert.True(t, HasReachedState(StatePassedChallenge, StatePassedChallenge))
 	assert.False(t, HasReachedState(StatePassedChallenge, StateEmailSent))
+	assert.False(t, HasReachedState(StatePassedChallenge, StateSMSSent))
+	assert.True(t, HasReachedState(StateEmailSent, StateSMSSent))
 	assert.False(t, HasReachedState(StateEmailSent, StateChooseMethod))
+
+	assert.Equal(t, 3, TotalSteps())
+	assert.Equal(t, 1, StepOf(StateChooseMethod))
+	assert.Equal(t, 2, StepOf(StateEmailSent))
+	assert.Equal(t, 2, StepOf(StateSMSSent))
+	assert.Equal(t, 3, StepOf(StatePassedChallenge))
 }
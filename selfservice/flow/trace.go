@@ -0,0 +1,29 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package flow
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Span attribute keys used when tracing self-service flows.
+const (
+	AttributeKeyFlowID       = "flow.id"
+	AttributeKeyFlowType     = "flow.type"
+	AttributeKeyFlowStrategy = "flow.strategy"
+)
+
+// SpanAttributes returns the span attributes describing the given flow.
+func SpanAttributes(f Flow) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String(AttributeKeyFlowID, f.GetID().String()),
+		attribute.String(AttributeKeyFlowType, string(f.GetType())),
+	}
+}
+
+// StrategySpanAttribute returns the span attribute describing the strategy used
+// to submit a flow.
+func StrategySpanAttribute(strategy string) attribute.KeyValue {
+	return attribute.String(AttributeKeyFlowStrategy, strategy)
+}
@@ -0,0 +1,24 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package flow
+
+import (
+	"context"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/text"
+	"github.com/ory/x/stringsx"
+)
+
+// AddConfiguredUIMessages adds the messages configured in `selfservice.flows.<flow>.ui_messages`
+// to the flow's UI.
+func AddConfiguredUIMessages(ctx context.Context, conf *config.Config, f Flow) {
+	for _, m := range conf.SelfServiceFlowUIMessages(ctx, string(f.GetFlowName())) {
+		message := text.NewInfoSelfServiceCustomMessage(m.Text, text.UITextType(stringsx.Coalesce(m.Type, string(text.Info))))
+		if m.ID > 0 {
+			message.ID = text.ID(m.ID)
+		}
+		f.GetUI().Messages.Add(message)
+	}
+}
@@ -98,6 +98,12 @@ func (s *ErrorHandler) WriteFlowError(
 			return
 		}
 
+		if f.Type == flow.TypeBrowser && s.d.Config().SelfServiceFlowExpiredAsStatusForBrowser(r.Context()) {
+			e.FlowID = a.ID
+			s.d.Writer().WriteError(w, r, e)
+			return
+		}
+
 		// We need to use the new flow, as that flow will be a browser flow. Bug fix for:
 		//
 		// https://github.com/ory/kratos/issues/2049!!
@@ -144,7 +150,9 @@ func (s *ErrorHandler) forward(w http.ResponseWriter, r *http.Request, rr *Flow,
 		return
 	}
 
-	if rr.Type == flow.TypeAPI || x.IsJSONRequest(r) {
+	if rr.Type == flow.TypeAPI {
+		flow.WriteAPIFlowError(s.d, w, r, x.RecoverStatusCode(err, http.StatusBadRequest), err)
+	} else if x.IsJSONRequest(r) {
 		s.d.Writer().WriteErrorCode(w, r, x.RecoverStatusCode(err, http.StatusBadRequest), err)
 	} else {
 		s.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
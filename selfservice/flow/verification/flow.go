@@ -19,6 +19,7 @@ import (
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/ui/container"
+	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
 	"github.com/ory/x/sqlxx"
 	"github.com/ory/x/urlx"
@@ -62,8 +63,8 @@ type Flow struct {
 	// ReturnTo contains the requested return_to URL.
 	ReturnTo string `json:"return_to,omitempty" db:"-"`
 
-	// Active, if set, contains the registration method that is being used. It is initially
-	// not set.
+	// Active contains the verification method that is being used. It is set when the flow
+	// is created to the method configured in `selfservice.flows.verification.use`.
 	Active sqlxx.NullString `json:"active,omitempty" faker:"-" db:"active_method"`
 
 	// UI contains data which must be shown in the user interface.
@@ -75,11 +76,23 @@ type Flow struct {
 	//
 	// - choose_method: ask the user to choose a method (e.g. verify your email)
 	// - sent_email: the email has been sent to the user
+	// - sent_sms: the SMS has been sent to the user
 	// - passed_challenge: the request was successful and the verification challenge was passed.
 	//
 	// required: true
 	State State `json:"state" faker:"-" db:"state"`
 
+	// Step is the one-based step of the flow derived from its state, e.g. 2 for
+	// `sent_email`. It can be used to render a progress indicator.
+	//
+	// required: true
+	Step int `json:"step" faker:"-" db:"-"`
+
+	// TotalSteps is the number of steps of the flow.
+	//
+	// required: true
+	TotalSteps int `json:"total_steps" faker:"-" db:"-"`
+
 	// OAuth2LoginChallenge holds the login challenge originally set during the registration flow.
 	OAuth2LoginChallenge sqlxx.NullString `json:"-" db:"oauth2_login_challenge"`
 	OAuth2LoginChallengeParams
@@ -135,6 +148,7 @@ func NewFlow(conf *config.Config, exp time.Duration, csrf string, r *http.Reques
 		conf.SelfServiceBrowserDefaultReturnTo(r.Context()),
 		x.SecureRedirectUseSourceURL(requestURL),
 		x.SecureRedirectAllowURLs(conf.SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+		x.SecureRedirectRequireHTTPS(conf.SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
 		x.SecureRedirectAllowSelfServiceURLs(conf.SelfPublicURL(r.Context())),
 	)
 	if err != nil {
@@ -180,7 +194,29 @@ func FromOldFlow(conf *config.Config, exp time.Duration, csrf string, r *http.Re
 	return nf, nil
 }
 
-func NewPostHookFlow(conf *config.Config, exp time.Duration, csrf string, r *http.Request, strategy Strategy, original flow.Flow) (*Flow, error) {
+type (
+	postHookFlowOptions struct {
+		returnTo *url.URL
+	}
+
+	PostHookFlowOption func(o *postHookFlowOptions)
+)
+
+// WithPostHookReturnTo sets the return_to URL of the verification flow. It takes precedence over the
+// `after_verification_return_to` and `return_to` query parameters of the original flow and must be
+// allowed by `selfservice.allowed_return_urls`.
+func WithPostHookReturnTo(u *url.URL) PostHookFlowOption {
+	return func(o *postHookFlowOptions) {
+		o.returnTo = u
+	}
+}
+
+func NewPostHookFlow(conf *config.Config, exp time.Duration, csrf string, r *http.Request, strategy Strategy, original flow.Flow, opts ...PostHookFlowOption) (*Flow, error) {
+	o := new(postHookFlowOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	f, err := NewFlow(conf, exp, csrf, r, strategy, original.GetType())
 	if err != nil {
 		return nil, err
@@ -196,6 +232,21 @@ func NewPostHookFlow(conf *config.Config, exp time.Duration, csrf string, r *htt
 		query.Set("return_to", afterVerificationReturn)
 	}
 	query.Del("after_verification_return_to")
+
+	if o.returnTo != nil {
+		returnTo, err := x.SecureRedirectTo(r,
+			conf.SelfServiceBrowserDefaultReturnTo(r.Context()),
+			x.SecureRedirectReturnTo(o.returnTo.String()),
+			x.SecureRedirectAllowURLs(conf.SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+			x.SecureRedirectRequireHTTPS(conf.SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
+			x.SecureRedirectAllowSelfServiceURLs(conf.SelfPublicURL(r.Context())),
+		)
+		if err != nil {
+			return nil, err
+		}
+		query.Set("return_to", returnTo.String())
+	}
+
 	requestURL.RawQuery = query.Encode()
 	f.RequestURL = requestURL.String()
 	return f, nil
@@ -230,9 +281,37 @@ func (f *Flow) SetCSRFToken(token string) {
 func (f Flow) MarshalJSON() ([]byte, error) {
 	type local Flow
 	f.SetReturnTo()
+	f.IssuedAt = f.IssuedAt.UTC()
+	f.ExpiresAt = f.ExpiresAt.UTC()
+	f.CreatedAt = f.CreatedAt.UTC()
+	f.UpdatedAt = f.UpdatedAt.UTC()
+	f.Step = flow.StepOf(f.State)
+	f.TotalSteps = flow.TotalSteps()
+	// The CSRF token is excluded by its struct tag already. Clear it on the copy as well so the
+	// field is not serialized when the tag is changed by accident. Browser clients still receive
+	// the token through the `csrf_token` UI node, use MarshalRedactedJSON to omit it as well.
+	f.CSRFToken = ""
 	return json.Marshal(local(f))
 }
 
+// MarshalRedactedJSON encodes the flow like MarshalJSON but also clears the value of the
+// `csrf_token` UI node. Use it when a flow is serialized for anything other than a response to
+// the client it belongs to, for example when caching it.
+func (f Flow) MarshalRedactedJSON() ([]byte, error) {
+	if f.UI != nil {
+		ui := *f.UI
+		ui.Nodes = make(node.Nodes, len(f.UI.Nodes))
+		for k, n := range f.UI.Nodes {
+			if n.ID() == x.CSRFTokenName {
+				n = node.NewCSRFNode("")
+			}
+			ui.Nodes[k] = n
+		}
+		f.UI = &ui
+	}
+	return f.MarshalJSON()
+}
+
 func (f *Flow) SetReturnTo() {
 	if u, err := url.Parse(f.RequestURL); err == nil {
 		f.ReturnTo = u.Query().Get("return_to")
@@ -274,6 +353,7 @@ func (f *Flow) ContinueURL(ctx context.Context, config *config.Config) *url.URL
 	returnTo, err := x.SecureRedirectTo(&verificationRequest, flowContinueURL,
 		x.SecureRedirectAllowSelfServiceURLs(config.SelfPublicURL(ctx)),
 		x.SecureRedirectAllowURLs(config.SelfServiceBrowserAllowedReturnToDomains(ctx)),
+		x.SecureRedirectRequireHTTPS(config.SelfServiceBrowserRequireHTTPSReturnTo(ctx)),
 	)
 	if err != nil {
 		// an error occured return flow default, or global default return URL
@@ -26,6 +26,7 @@ import (
 
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/registration"
+	"github.com/ory/kratos/x"
 )
 
 func TestFlow(t *testing.T) {
@@ -118,12 +119,96 @@ func TestNewPostHookFlow(t *testing.T) {
 			"after_verification_return_to": {expectedReturnTo},
 		}, expectedReturnTo)
 	})
+
+	t.Run("case=return_to option", func(t *testing.T) {
+		conf.MustSet(context.Background(), config.ViperKeyURLsAllowedReturnToDomains, []string{"http://foo.com/hook_callback"})
+		t.Cleanup(func() {
+			conf.MustSet(context.Background(), config.ViperKeyURLsAllowedReturnToDomains, nil)
+		})
+		originalFlow := registration.Flow{
+			RequestURL: "http://foo.com/bar?" + url.Values{"after_verification_return_to": {"http://foo.com/verification_callback"}}.Encode(),
+		}
+
+		t.Run("case=allowed", func(t *testing.T) {
+			f, err := verification.NewPostHookFlow(conf, time.Second, "", u, nil, &originalFlow,
+				verification.WithPostHookReturnTo(urlx.ParseOrPanic("http://foo.com/hook_callback/done")))
+			require.NoError(t, err)
+			requestURL, err := urlx.Parse(f.RequestURL)
+			require.NoError(t, err)
+			assert.Equal(t, "http://foo.com/hook_callback/done", requestURL.Query().Get("return_to"))
+		})
+
+		t.Run("case=not allowed", func(t *testing.T) {
+			_, err := verification.NewPostHookFlow(conf, time.Second, "", u, nil, &originalFlow,
+				verification.WithPostHookReturnTo(urlx.ParseOrPanic("http://evil.com/hook_callback")))
+			require.Error(t, err)
+		})
+	})
 }
 
 func TestFlowEncodeJSON(t *testing.T) {
 	assert.EqualValues(t, "", gjson.Get(jsonx.TestMarshalJSONString(t, &verification.Flow{RequestURL: "https://foo.bar?foo=bar"}), "return_to").String())
 	assert.EqualValues(t, "/bar", gjson.Get(jsonx.TestMarshalJSONString(t, &verification.Flow{RequestURL: "https://foo.bar?return_to=/bar"}), "return_to").String())
 	assert.EqualValues(t, "/bar", gjson.Get(jsonx.TestMarshalJSONString(t, verification.Flow{RequestURL: "https://foo.bar?return_to=/bar"}), "return_to").String())
+
+	t.Run("case=timestamps are encoded in UTC", func(t *testing.T) {
+		loc := time.FixedZone("UTC+2", 2*60*60)
+		issuedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, loc)
+		f := &verification.Flow{IssuedAt: issuedAt, ExpiresAt: issuedAt.Add(time.Hour)}
+
+		encoded := jsonx.TestMarshalJSONString(t, f)
+		assert.Equal(t, "2024-01-01T11:00:00Z", gjson.Get(encoded, "expires_at").String())
+		assert.Equal(t, "2024-01-01T10:00:00Z", gjson.Get(encoded, "issued_at").String())
+
+		// the flow itself is not modified
+		assert.Equal(t, loc, f.ExpiresAt.Location())
+	})
+
+	t.Run("case=csrf token is never encoded", func(t *testing.T) {
+		token := x.NewUUID().String()
+		f := &verification.Flow{ID: x.NewUUID(), CSRFToken: token, RequestURL: "https://foo.bar?return_to=/bar"}
+
+		assert.NotContains(t, jsonx.TestMarshalJSONString(t, f), token)
+		assert.NotContains(t, jsonx.TestMarshalJSONString(t, *f), token)
+
+		// the flow itself is not modified
+		assert.Equal(t, token, f.CSRFToken)
+	})
+
+	t.Run("case=csrf token is redacted from the ui", func(t *testing.T) {
+		_, reg := internal.NewFastRegistryWithMocks(t)
+		strategy, err := reg.GetActiveVerificationStrategy(context.Background())
+		require.NoError(t, err)
+
+		r := &http.Request{URL: urlx.ParseOrPanic("http://foo/bar/baz"), Host: "foo"}
+		f, err := verification.NewFlow(reg.Config(), time.Hour, reg.GenerateCSRFToken(r), r, strategy, flow.TypeBrowser)
+		require.NoError(t, err)
+
+		token := f.UI.Nodes.Find(x.CSRFTokenName).GetValue()
+		require.NotEmpty(t, token)
+		require.Equal(t, token, f.CSRFToken)
+
+		// browser clients need the token to submit the flow
+		assert.Contains(t, jsonx.TestMarshalJSONString(t, f), token)
+
+		redacted, err := f.MarshalRedactedJSON()
+		require.NoError(t, err)
+		assert.NotContains(t, string(redacted), token)
+		assert.True(t, gjson.GetBytes(redacted, `ui.nodes.#(attributes.name=="csrf_token")`).Exists(), "%s", redacted)
+		assert.Equal(t, gjson.Get(jsonx.TestMarshalJSONString(t, f), "ui.nodes.#").Int(), gjson.GetBytes(redacted, "ui.nodes.#").Int())
+
+		// the flow itself is not modified
+		assert.Equal(t, token, f.UI.Nodes.Find(x.CSRFTokenName).GetValue())
+		assert.Equal(t, token, f.CSRFToken)
+	})
+	t.Run("case=progress is derived from the state", func(t *testing.T) {
+		f := &verification.Flow{ID: x.NewUUID(), State: flow.StateEmailSent, RequestURL: "https://foo.bar"}
+
+		encoded := jsonx.TestMarshalJSONString(t, f)
+		assert.Equal(t, "sent_email", gjson.Get(encoded, "state").String())
+		assert.EqualValues(t, 2, gjson.Get(encoded, "step").Int())
+		assert.EqualValues(t, 3, gjson.Get(encoded, "total_steps").Int())
+	})
 }
 
 func TestFromOldFlow(t *testing.T) {
@@ -7,12 +7,16 @@ import (
 	"net/http"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/ory/kratos/hydra"
 	"github.com/ory/kratos/session"
 	"github.com/ory/nosurf"
 
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/ui/node"
+	"github.com/ory/x/otelx"
 	"github.com/ory/x/sqlcon"
 
 	"github.com/ory/herodot"
@@ -53,6 +57,7 @@ type (
 		x.CSRFTokenGeneratorProvider
 		x.WriterProvider
 		x.CSRFProvider
+		x.TracingProvider
 		x.LoggingProvider
 
 		FlowPersistenceProvider
@@ -98,7 +103,12 @@ func WithFlowReturnTo(returnTo string) FlowOption {
 	}
 }
 
-func (h *Handler) NewVerificationFlow(w http.ResponseWriter, r *http.Request, ft flow.Type, opts ...FlowOption) (*Flow, error) {
+func (h *Handler) NewVerificationFlow(w http.ResponseWriter, r *http.Request, ft flow.Type, opts ...FlowOption) (_ *Flow, err error) {
+	ctx, span := h.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.flow.verification.Handler.NewVerificationFlow",
+		trace.WithAttributes(attribute.String(flow.AttributeKeyFlowType, string(ft))))
+	r = r.WithContext(ctx)
+	defer otelx.End(span, &err)
+
 	strategy, err := h.d.GetActiveVerificationStrategy(r.Context())
 	if err != nil {
 		return nil, err
@@ -111,14 +121,19 @@ func (h *Handler) NewVerificationFlow(w http.ResponseWriter, r *http.Request, ft
 	for _, o := range opts {
 		o(f)
 	}
+	span.SetAttributes(flow.SpanAttributes(f)...)
+
+	flow.AddConfiguredUIMessages(r.Context(), h.d.Config(), f)
 
 	if err := h.d.VerificationExecutor().PreVerificationHook(w, r, f); err != nil {
 		return nil, err
 	}
 
+	stopDB := x.TrackServerTiming(r.Context(), x.ServerTimingDB)
 	if err := h.d.VerificationFlowPersister().CreateVerificationFlow(r.Context(), f); err != nil {
 		return nil, err
 	}
+	stopDB()
 
 	return f, nil
 }
@@ -146,6 +161,7 @@ func (h *Handler) NewVerificationFlow(w http.ResponseWriter, r *http.Request, ft
 //	  400: errorGeneric
 //	  default: errorGeneric
 func (h *Handler) createNativeVerificationFlow(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w, r = x.StartServerTiming(h.d, w, r)
 	if !h.d.Config().SelfServiceFlowVerificationEnabled(r.Context()) {
 		h.d.SelfServiceErrorManager().Forward(r.Context(), w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Verification is not allowed because it was disabled.")))
 		return
@@ -193,6 +209,7 @@ type createBrowserVerificationFlow struct {
 //	  303: emptyResponse
 //	  default: errorGeneric
 func (h *Handler) createBrowserVerificationFlow(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w, r = x.StartServerTiming(h.d, w, r)
 	if !h.d.Config().SelfServiceFlowVerificationEnabled(r.Context()) {
 		h.d.SelfServiceErrorManager().Forward(r.Context(), w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Verification is not allowed because it was disabled.")))
 		return
@@ -204,6 +221,7 @@ func (h *Handler) createBrowserVerificationFlow(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	flow.SetCSRFTokenHeader(r.Context(), h.d.Config(), w, req)
 	redirTo := req.AppendTo(h.d.Config().SelfServiceFlowVerificationUI(r.Context())).String()
 	x.AcceptToRedirectOrJSON(w, r, h.d.Writer(), req, redirTo)
 }
@@ -232,6 +250,15 @@ type getVerificationFlow struct {
 	// in: header
 	// name: Cookie
 	Cookie string `json:"cookie"`
+
+	// Response Format
+	//
+	// Set to `minimal` to only return the flow's ID, type, state, and the input nodes
+	// required to submit it, without script, text, image, or anchor nodes and node meta information.
+	// The response body then follows the `minimalFlow` schema.
+	//
+	// in: query
+	Format string `json:"format"`
 }
 
 // swagger:route GET /self-service/verification/flows frontend getVerificationFlow
@@ -304,6 +331,11 @@ func (h *Handler) getVerificationFlow(w http.ResponseWriter, r *http.Request, _
 		return
 	}
 
+	if flow.IsMinimalFormatRequested(r) {
+		h.d.Writer().Write(w, r, flow.NewMinimal(req))
+		return
+	}
+
 	h.d.Writer().Write(w, r, req)
 }
 
@@ -355,6 +387,12 @@ type updateVerificationFlow struct {
 //lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
 type updateVerificationFlowBody struct{}
 
+// SubmitFlow submits the verification flow referenced by the request's flow
+// query parameter. Strategies use this to complete flows on routes of their own.
+func (h *Handler) SubmitFlow(w http.ResponseWriter, r *http.Request) {
+	h.updateVerificationFlow(w, r, nil)
+}
+
 // swagger:route POST /self-service/verification frontend updateVerificationFlow
 //
 // # Complete Verification Flow
@@ -369,6 +407,7 @@ type updateVerificationFlowBody struct{}
 //   - For Browser clients without HTTP Header `Accept` or with `Accept: text/*` it returns a HTTP 303 See Other redirect to the Verification UI URL with the Verification Flow ID appended.
 //   - `sent_email` is the success state after `choose_method` when using the `link` method and allows the user to request another verification email. It
 //     works for both API and Browser-initiated flows and returns the same responses as the flow in `choose_method` state.
+//   - `sent_sms` is the equivalent of `sent_email` when the verification code was sent to a phone number.
 //   - `passed_challenge` expects a `token` to be sent in the URL query and given the nature of the flow ("sending a verification link")
 //     does not have any API capabilities. The server responds with a HTTP 303 See Other redirect either to the Settings UI URL
 //     (if the link was valid) and instructs the user to update their password, or a redirect to the Verification UI URL with
@@ -392,23 +431,29 @@ type updateVerificationFlowBody struct{}
 //	  410: errorGeneric
 //	  default: errorGeneric
 func (h *Handler) updateVerificationFlow(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var err error
+	ctx, span := h.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.flow.verification.Handler.updateVerificationFlow")
+	r = r.WithContext(ctx)
+	defer otelx.End(span, &err)
+
 	rid, err := flow.GetFlowID(r)
 	if err != nil {
 		h.d.VerificationFlowErrorHandler().WriteFlowError(w, r, nil, node.DefaultGroup, err)
 		return
 	}
 
-	ctx := r.Context()
 	f, err := h.d.VerificationFlowPersister().GetVerificationFlow(ctx, rid)
 	if errors.Is(err, sqlcon.ErrNoRows) {
-		h.d.VerificationFlowErrorHandler().WriteFlowError(w, r, nil, node.DefaultGroup, errors.WithStack(herodot.ErrNotFound.WithReasonf("The verification request could not be found. Please restart the flow.")))
+		err = errors.WithStack(herodot.ErrNotFound.WithReasonf("The verification request could not be found. Please restart the flow."))
+		h.d.VerificationFlowErrorHandler().WriteFlowError(w, r, nil, node.DefaultGroup, err)
 		return
 	} else if err != nil {
 		h.d.VerificationFlowErrorHandler().WriteFlowError(w, r, nil, node.DefaultGroup, err)
 		return
 	}
+	span.SetAttributes(flow.SpanAttributes(f)...)
 
-	if err := f.Valid(); err != nil {
+	if err = f.Valid(); err != nil {
 		h.d.VerificationFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 		return
 	}
@@ -421,10 +466,14 @@ func (h *Handler) updateVerificationFlow(w http.ResponseWriter, r *http.Request,
 			continue
 		}
 
-		err := ss.Verify(w, r, f)
+		err = ss.Verify(w, r, f)
 		if errors.Is(err, flow.ErrStrategyNotResponsible) {
 			continue
-		} else if errors.Is(err, flow.ErrCompletedByStrategy) {
+		}
+
+		span.SetAttributes(flow.StrategySpanAttribute(ss.VerificationStrategyID()))
+		if errors.Is(err, flow.ErrCompletedByStrategy) {
+			err = nil
 			return
 		} else if err != nil {
 			h.d.VerificationFlowErrorHandler().WriteFlowError(w, r, f, ss.NodeGroup(), err)
@@ -437,7 +486,8 @@ func (h *Handler) updateVerificationFlow(w http.ResponseWriter, r *http.Request,
 	}
 
 	if !found {
-		h.d.VerificationFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, errors.WithStack(schema.NewNoVerificationStrategyResponsible()))
+		err = errors.WithStack(schema.NewNoVerificationStrategyResponsible())
+		h.d.VerificationFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 		return
 	}
 
@@ -446,12 +496,13 @@ func (h *Handler) updateVerificationFlow(w http.ResponseWriter, r *http.Request,
 		// and redirect back to the OAuth2 provider.
 		if flow.HasReachedState(flow.StatePassedChallenge, f.State) && f.OAuth2LoginChallenge.String() != "" {
 			if !f.IdentityID.Valid || !f.SessionID.Valid {
-				h.d.VerificationFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup,
-					herodot.ErrBadRequest.WithReasonf("No session was found for this flow. Please retry the authentication."))
+				err = herodot.ErrBadRequest.WithReasonf("No session was found for this flow. Please retry the authentication.")
+				h.d.VerificationFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 				return
 			}
 
-			callbackURL, err := h.d.Hydra().AcceptLoginRequest(ctx,
+			var callbackURL string
+			callbackURL, err = h.d.Hydra().AcceptLoginRequest(ctx,
 				hydra.AcceptLoginRequestParams{
 					LoginChallenge:        string(f.OAuth2LoginChallenge),
 					IdentityID:            f.IdentityID.UUID.String(),
@@ -463,7 +514,8 @@ func (h *Handler) updateVerificationFlow(w http.ResponseWriter, r *http.Request,
 				return
 			}
 
-			sess, err := h.d.SessionPersister().GetSession(ctx, f.SessionID.UUID, session.ExpandDefault)
+			var sess *session.Session
+			sess, err = h.d.SessionPersister().GetSession(ctx, f.SessionID.UUID, session.ExpandDefault)
 			if err != nil {
 				h.d.VerificationFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
 				return
@@ -90,6 +90,27 @@ func TestGetFlow(t *testing.T) {
 		})
 	})
 
+	t.Run("case=reports the active method", func(t *testing.T) {
+		currentUse := conf.GetProvider(ctx).Get(config.ViperKeySelfServiceVerificationUse)
+		conf.MustSet(ctx, config.ViperKeySelfServiceVerificationUse, string(verification.VerificationStrategyLink))
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceVerificationUse, currentUse)
+		})
+
+		client := testhelpers.NewClientWithCookies(t)
+		_ = setupVerificationUI(t, client)
+
+		t.Run("type=browser", func(t *testing.T) {
+			f := testhelpers.InitializeVerificationFlowViaBrowser(t, client, false, public)
+			assert.Equal(t, string(verification.VerificationStrategyLink), f.GetActive())
+		})
+
+		t.Run("type=api", func(t *testing.T) {
+			f := testhelpers.InitializeVerificationFlowViaAPI(t, client, public)
+			assert.Equal(t, string(verification.VerificationStrategyLink), f.GetActive())
+		})
+	})
+
 	t.Run("case=csrf cookie missing", func(t *testing.T) {
 		client := http.DefaultClient
 		_ = setupVerificationUI(t, client)
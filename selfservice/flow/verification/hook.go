@@ -10,6 +10,8 @@ import (
 
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/ory/x/otelx"
+
 	"github.com/ory/kratos/x/events"
 
 	"github.com/ory/kratos/driver/config"
@@ -62,6 +64,7 @@ type (
 		HooksProvider
 		x.CSRFTokenGeneratorProvider
 		x.LoggingProvider
+		x.TracingProvider
 		x.WriterProvider
 	}
 
@@ -90,7 +93,13 @@ func (e *HookExecutor) PreVerificationHook(w http.ResponseWriter, r *http.Reques
 	return nil
 }
 
-func (e *HookExecutor) PostVerificationHook(w http.ResponseWriter, r *http.Request, a *Flow, i *identity.Identity) error {
+func (e *HookExecutor) PostVerificationHook(w http.ResponseWriter, r *http.Request, a *Flow, i *identity.Identity) (err error) {
+	ctx := r.Context()
+	ctx, span := e.d.Tracer(ctx).Tracer().Start(ctx, "selfservice.flow.verification.HookExecutor.PostVerificationHook",
+		trace.WithAttributes(append(flow.SpanAttributes(a), flow.StrategySpanAttribute(a.Active.String()))...))
+	r = r.WithContext(ctx)
+	defer otelx.End(span, &err)
+
 	e.d.Logger().
 		WithRequest(r).
 		WithField("identity_id", i.ID).
@@ -11,6 +11,7 @@ import "github.com/ory/kratos/selfservice/flow"
 //
 // - choose_method: ask the user to choose a method (e.g. recover account via email)
 // - sent_email: the email has been sent to the user
+// - sent_sms: the SMS has been sent to the user
 // - passed_challenge: the request was successful and the recovery challenge was passed.
 //
 // swagger:model verificationFlowState
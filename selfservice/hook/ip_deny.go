@@ -0,0 +1,68 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hook
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/selfservice/flow/registration"
+	"github.com/ory/kratos/x"
+)
+
+var _ registration.PreHookExecutor = new(IPDeny)
+
+// ErrIPDenied is returned if the client IP is on the deny list. It is intentionally generic to
+// not reveal why the request was rejected.
+var ErrIPDenied = herodot.ErrForbidden.WithReason("The request was rejected.")
+
+type (
+	ipDenyConfig struct {
+		// Ranges is a list of CIDR ranges, e.g. `10.0.0.0/8` or `2001:db8::/32`.
+		Ranges []string `json:"ranges"`
+	}
+
+	ipDenyDependencies interface {
+		config.Provider
+	}
+
+	IPDeny struct {
+		deps ipDenyDependencies
+		conf json.RawMessage
+	}
+)
+
+func NewIPDeny(d ipDenyDependencies, conf json.RawMessage) *IPDeny {
+	return &IPDeny{deps: d, conf: conf}
+}
+
+func (e *IPDeny) ExecuteRegistrationPreHook(_ http.ResponseWriter, r *http.Request, _ *registration.Flow) error {
+	var c ipDenyConfig
+	if err := json.Unmarshal(e.conf, &c); err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to decode the ip_deny hook configuration: %s", err))
+	}
+
+	// The ranges are validated when the configuration is loaded.
+	denied, err := config.ParseCIDRs(c.Ranges)
+	if err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("The ip_deny hook configuration is invalid: %s", err))
+	}
+
+	ip := x.ClientIP(r, e.deps.Config().PublicTrustedProxies(r.Context()))
+	if ip == nil {
+		return nil
+	}
+
+	for _, ipNet := range denied {
+		if ipNet.Contains(ip) {
+			return errors.WithStack(ErrIPDenied)
+		}
+	}
+
+	return nil
+}
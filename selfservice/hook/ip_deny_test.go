@@ -0,0 +1,57 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hook_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/selfservice/flow/registration"
+	"github.com/ory/kratos/selfservice/hook"
+)
+
+func TestIPDeny(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeyPublicTrustedProxies, []string{"10.0.0.0/8"})
+
+	h := hook.NewIPDeny(reg, json.RawMessage(`{"ranges":["192.0.2.0/24","2001:db8::/32"]}`))
+
+	for _, tc := range []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		denied  bool
+	}{
+		{name: "denied remote address", remote: "192.0.2.10:1234", denied: true},
+		{name: "denied ipv6 remote address", remote: "[2001:db8::1]:1234", denied: true},
+		{name: "denied forwarded address from trusted proxy", remote: "10.0.0.1:1234", headers: map[string]string{"X-Forwarded-For": "192.0.2.99"}, denied: true},
+		{name: "denied spoofed forwarded address", remote: "192.0.2.10:1234", headers: map[string]string{"X-Forwarded-For": "198.51.100.1", "True-Client-IP": "198.51.100.1"}, denied: true},
+		{name: "allowed remote address", remote: "198.51.100.1:1234"},
+		{name: "allowed forwarded address from trusted proxy", remote: "10.0.0.1:1234", headers: map[string]string{"X-Forwarded-For": "198.51.100.1"}},
+		{name: "forwarded address from untrusted remote is ignored", remote: "198.51.100.1:1234", headers: map[string]string{"X-Forwarded-For": "192.0.2.99"}},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/self-service/registration/browser", nil)
+			r.RemoteAddr = tc.remote
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+
+			err := h.ExecuteRegistrationPreHook(httptest.NewRecorder(), r, new(registration.Flow))
+			if tc.denied {
+				require.ErrorIs(t, err, hook.ErrIPDenied)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -24,10 +24,14 @@ import (
 	"github.com/ory/x/otelx"
 )
 
-var _ registration.PostHookPostPersistExecutor = new(SessionIssuer)
+var (
+	_ registration.PostHookPrePersistExecutor  = new(SessionIssuer)
+	_ registration.PostHookPostPersistExecutor = new(SessionIssuer)
+)
 
 type (
 	sessionIssuerDependencies interface {
+		session.IssuancePolicyProvider
 		session.ManagementProvider
 		session.PersistenceProvider
 		sessiontokenexchange.PersistenceProvider
@@ -46,6 +50,18 @@ func NewSessionIssuer(r sessionIssuerDependencies) *SessionIssuer {
 	return &SessionIssuer{r: r}
 }
 
+// ExecutePostRegistrationPrePersistHook consults the session issuance policy before the identity is
+// created, so that a denied registration does not leave an identity behind.
+func (e *SessionIssuer) ExecutePostRegistrationPrePersistHook(_ http.ResponseWriter, r *http.Request, a *registration.Flow, i *identity.Identity) error {
+	return otelx.WithSpan(r.Context(), "selfservice.hook.SessionIssuer.ExecutePostRegistrationPrePersistHook", func(ctx context.Context) error {
+		if !i.IsActive() {
+			// The identity is registered as inactive and does not receive a session.
+			return nil
+		}
+		return session.CheckIssuancePolicy(ctx, e.r.SessionIssuancePolicy(), i, a)
+	})
+}
+
 func (e *SessionIssuer) ExecutePostRegistrationPostPersistHook(w http.ResponseWriter, r *http.Request, a *registration.Flow, s *session.Session) error {
 	return otelx.WithSpan(r.Context(), "selfservice.hook.SessionIssuer.ExecutePostRegistrationPostPersistHook", func(ctx context.Context) error {
 		return e.executePostRegistrationPostPersistHook(w, r.WithContext(ctx), a, s)
@@ -53,6 +69,11 @@ func (e *SessionIssuer) ExecutePostRegistrationPostPersistHook(w http.ResponseWr
 }
 
 func (e *SessionIssuer) executePostRegistrationPostPersistHook(w http.ResponseWriter, r *http.Request, a *registration.Flow, s *session.Session) error {
+	if s.Identity != nil && !s.Identity.IsActive() {
+		// The identity was registered as inactive and can not sign in until it is activated.
+		return nil
+	}
+
 	if a.Type == flow.TypeAPI {
 		// We don't want to redirect with the code, if the flow was submitted with an ID token.
 		// This is the case for Sign in with native Apple SDK or Google SDK.
@@ -65,12 +86,12 @@ func (e *SessionIssuer) executePostRegistrationPostPersistHook(w http.ResponseWr
 		}
 
 		a.AddContinueWith(flow.NewContinueWithSetToken(s.Token))
-		e.r.Writer().Write(w, r, &registration.APIFlowResponse{
+		e.r.Writer().Write(w, r, (&registration.APIFlowResponse{
 			Session:      s,
 			Token:        s.Token,
 			Identity:     s.Identity,
 			ContinueWith: a.ContinueWithItems,
-		})
+		}).WithAddresses(r.Context(), e.r.Config()))
 
 		trace.SpanFromContext(r.Context()).AddEvent(events.NewLoginSucceeded(r.Context(), &events.LoginSucceededOpts{
 			SessionID:  s.ID,
@@ -96,11 +117,11 @@ func (e *SessionIssuer) executePostRegistrationPostPersistHook(w http.ResponseWr
 
 	// SPA flows additionally send the session
 	if x.IsJSONRequest(r) {
-		e.r.Writer().Write(w, r, &registration.APIFlowResponse{
+		e.r.Writer().Write(w, r, (&registration.APIFlowResponse{
 			Session:      s,
 			Identity:     s.Identity,
 			ContinueWith: a.ContinueWithItems,
-		})
+		}).WithAddresses(r.Context(), e.r.Config()))
 		return errors.WithStack(registration.ErrHookAbortFlow)
 	}
 
@@ -96,9 +96,10 @@ func (e *Verifier) do(
 	isBrowserFlow := f.GetType() == flow.TypeBrowser
 	isRegistrationOrLoginFlow := f.GetFlowName() == flow.RegistrationFlow || f.GetFlowName() == flow.LoginFlow
 
+	revalidateAfter := e.r.Config().SelfServiceFlowVerificationRevalidateAfter(ctx)
 	for k := range i.VerifiableAddresses {
 		address := &i.VerifiableAddresses[k]
-		if isRegistrationOrLoginFlow && address.Verified {
+		if isRegistrationOrLoginFlow && address.Verified && !address.NeedsRevalidation(revalidateAfter) {
 			continue
 		} else if !isRegistrationOrLoginFlow && address.Status != identity.VerifiableAddressStatusPending {
 			// In case of the settings flow, we only want to create a new verification flow if there is no pending
@@ -131,7 +132,7 @@ func (e *Verifier) do(
 			flowCallback(verificationFlow)
 		}
 
-		verificationFlow.State = flow.StateEmailSent
+		verificationFlow.State = flow.SentState(address.Via)
 
 		if err := strategy.PopulateVerificationMethod(r, verificationFlow); err != nil {
 			return err
@@ -92,6 +92,34 @@ func TestVerifier(t *testing.T) {
 				require.Len(t, messages, 2)
 			})
 
+			t.Run("case=should send out sms for unverified phone numbers", func(t *testing.T) {
+				t.Parallel()
+				originalFlow := tc.originalFlow()
+				conf, reg := internal.NewFastRegistryWithMocks(t)
+				testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/verify_sms.schema.json")
+				conf.MustSet(ctx, config.ViperKeyPublicBaseURL, "https://www.ory.sh/")
+
+				i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+				i.Traits = identity.Traits(`{"phone":"+4917667111638"}`)
+				require.NoError(t, reg.IdentityManager().Create(context.Background(), i))
+
+				h := hook.NewVerifier(reg)
+				require.NoError(t, tc.execHook(h, i, originalFlow))
+				require.Len(t, originalFlow.ContinueWith(), 1)
+				vf := originalFlow.ContinueWith()[0]
+				assert.IsType(t, &flow.ContinueWithVerificationUI{}, vf)
+				fView := vf.(*flow.ContinueWithVerificationUI).Flow
+
+				expectedVerificationFlow, err := reg.VerificationFlowPersister().GetVerificationFlow(ctx, fView.ID)
+				require.NoError(t, err)
+				require.Equal(t, flow.StateSMSSent, expectedVerificationFlow.State)
+
+				messages, err := reg.CourierPersister().NextMessages(context.Background(), 12)
+				require.NoError(t, err)
+				require.Len(t, messages, 1)
+				assert.Equal(t, "+4917667111638", messages[0].Recipient)
+			})
+
 			t.Run("case should skip already verified addresses", func(t *testing.T) {
 				t.Parallel()
 				originalFlow := tc.originalFlow()
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
@@ -23,6 +25,7 @@ import (
 	grpccodes "google.golang.org/grpc/codes"
 
 	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/request"
 	"github.com/ory/kratos/schema"
@@ -68,25 +71,43 @@ var jsonnetCache, _ = ristretto.NewCache(&ristretto.Config{
 
 type (
 	webHookDependencies interface {
+		config.Provider
 		x.LoggingProvider
 		x.HTTPClientProvider
 		x.TracingProvider
 		jsonnetsecure.VMProvider
 	}
 
+	// templateContext is the data passed to the web hook's Jsonnet body template
+	// as `ctx`. Its fields form a stable contract with the templates, so only add
+	// fields here and never rename or remove them. It must never contain secrets
+	// such as credentials or the web hook's own auth configuration.
 	templateContext struct {
-		Flow           flow.Flow          `json:"flow"`
-		RequestHeaders http.Header        `json:"request_headers"`
-		RequestMethod  string             `json:"request_method"`
-		RequestURL     string             `json:"request_url"`
-		RequestCookies map[string]string  `json:"request_cookies"`
-		Identity       *identity.Identity `json:"identity,omitempty"`
-		Session        *session.Session   `json:"session,omitempty"`
+		// Flow is the self-service flow the hook is executed for.
+		Flow flow.Flow `json:"flow"`
+		// FlowType is the kind of flow, e.g. `login` or `registration`. Not to be
+		// confused with `flow.type`, which is either `api` or `browser`.
+		FlowType flow.FlowName `json:"flow_type"`
+		// TransientPayload is the transient payload submitted with the flow. It is
+		// omitted if the payload is empty or `null`.
+		TransientPayload json.RawMessage `json:"transient_payload,omitempty"`
+		// RequestHeaders only contains the allowed request headers and never
+		// headers or cookies carrying credentials.
+		RequestHeaders http.Header `json:"request_headers"`
+		RequestMethod  string      `json:"request_method"`
+		RequestURL     string      `json:"request_url"`
+		// RequestCookies never contains the session and anti-CSRF cookies.
+		RequestCookies map[string]string `json:"request_cookies"`
+		// Identity is set for hooks which are executed after the identity is
+		// known. Credentials and admin metadata are never included.
+		Identity *identity.Identity `json:"identity,omitempty"`
+		Session  *session.Session   `json:"session,omitempty"`
 	}
 
 	WebHook struct {
-		deps webHookDependencies
-		conf json.RawMessage
+		deps           webHookDependencies
+		conf           json.RawMessage
+		allowedHeaders []string
 	}
 
 	detailedMessage struct {
@@ -106,10 +127,69 @@ type (
 	}
 )
 
-func cookies(req *http.Request) map[string]string {
+// credentialHeaders carry credentials and are never passed to web hooks, even if
+// they are allowed explicitly.
+var credentialHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"X-Session-Token",
+}
+
+// filterHeaders returns a copy of the headers which only contains the allowed headers.
+func filterHeaders(headers http.Header, allowed []string) http.Header {
+	filtered := make(http.Header, len(allowed))
+	for _, k := range allowed {
+		if v, ok := headers[k]; ok {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+// isSecretCookie reports whether the cookie is the session or the anti-CSRF cookie
+// issued by Kratos.
+func (e *WebHook) isSecretCookie(req *http.Request, name string) bool {
+	return name == e.deps.Config().SessionName(req.Context()) || name == x.CSRFCookieName(e.deps, req)
+}
+
+// headers returns a copy of the request headers without the headers carrying
+// credentials. The session and anti-CSRF cookies are removed from the `Cookie`
+// header.
+func (e *WebHook) headers(req *http.Request) http.Header {
+	headers := req.Header.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	for _, k := range credentialHeaders {
+		headers.Del(k)
+	}
+
+	lines := headers.Values("Cookie")
+	headers.Del("Cookie")
+	for _, line := range lines {
+		var kept []string
+		var removed bool
+		for _, c := range (&http.Request{Header: http.Header{"Cookie": {line}}}).Cookies() {
+			if e.isSecretCookie(req, c.Name) {
+				removed = true
+				continue
+			}
+			kept = append(kept, c.Name+"="+c.Value)
+		}
+		if !removed {
+			headers.Add("Cookie", line)
+		} else if len(kept) > 0 {
+			headers.Add("Cookie", strings.Join(kept, "; "))
+		}
+	}
+	return headers
+}
+
+// cookies returns the request cookies without the session and anti-CSRF cookies.
+func (e *WebHook) cookies(req *http.Request) map[string]string {
 	cookies := make(map[string]string)
 	for _, c := range req.Cookies() {
-		if c.Name != "" {
+		if c.Name != "" && !e.isSecretCookie(req, c.Name) {
 			cookies[c.Name] = c.Value
 		}
 	}
@@ -120,14 +200,23 @@ func NewWebHook(r webHookDependencies, c json.RawMessage) *WebHook {
 	return &WebHook{deps: r, conf: c}
 }
 
+// WithAllowedHeaders restricts the request headers passed to the web hook to the given,
+// canonicalized header names. If nil, all request headers are passed. Request cookies
+// are only passed if `Cookie` is allowed. Headers carrying credentials, such as
+// `Authorization`, and the session and anti-CSRF cookies are never passed.
+func (e *WebHook) WithAllowedHeaders(allowed []string) *WebHook {
+	e.allowedHeaders = allowed
+	return e
+}
+
 func (e *WebHook) ExecuteLoginPreHook(_ http.ResponseWriter, req *http.Request, flow *login.Flow) error {
 	return otelx.WithSpan(req.Context(), "selfservice.hook.WebHook.ExecuteLoginPreHook", func(ctx context.Context) error {
 		return e.execute(ctx, &templateContext{
 			Flow:           flow,
-			RequestHeaders: req.Header,
+			RequestHeaders: e.headers(req),
+			RequestCookies: e.cookies(req),
 			RequestMethod:  req.Method,
 			RequestURL:     x.RequestURL(req).String(),
-			RequestCookies: cookies(req),
 		})
 	})
 }
@@ -136,10 +225,10 @@ func (e *WebHook) ExecuteLoginPostHook(_ http.ResponseWriter, req *http.Request,
 	return otelx.WithSpan(req.Context(), "selfservice.hook.WebHook.ExecuteLoginPostHook", func(ctx context.Context) error {
 		return e.execute(ctx, &templateContext{
 			Flow:           flow,
-			RequestHeaders: req.Header,
+			RequestHeaders: e.headers(req),
+			RequestCookies: e.cookies(req),
 			RequestMethod:  req.Method,
 			RequestURL:     x.RequestURL(req).String(),
-			RequestCookies: cookies(req),
 			Identity:       session.Identity,
 			Session:        session,
 		})
@@ -150,10 +239,10 @@ func (e *WebHook) ExecuteVerificationPreHook(_ http.ResponseWriter, req *http.Re
 	return otelx.WithSpan(req.Context(), "selfservice.hook.WebHook.ExecuteVerificationPreHook", func(ctx context.Context) error {
 		return e.execute(ctx, &templateContext{
 			Flow:           flow,
-			RequestHeaders: req.Header,
+			RequestHeaders: e.headers(req),
+			RequestCookies: e.cookies(req),
 			RequestMethod:  req.Method,
 			RequestURL:     x.RequestURL(req).String(),
-			RequestCookies: cookies(req),
 		})
 	})
 }
@@ -162,10 +251,10 @@ func (e *WebHook) ExecutePostVerificationHook(_ http.ResponseWriter, req *http.R
 	return otelx.WithSpan(req.Context(), "selfservice.hook.WebHook.ExecutePostVerificationHook", func(ctx context.Context) error {
 		return e.execute(ctx, &templateContext{
 			Flow:           flow,
-			RequestHeaders: req.Header,
+			RequestHeaders: e.headers(req),
+			RequestCookies: e.cookies(req),
 			RequestMethod:  req.Method,
 			RequestURL:     x.RequestURL(req).String(),
-			RequestCookies: cookies(req),
 			Identity:       id,
 		})
 	})
@@ -175,9 +264,9 @@ func (e *WebHook) ExecuteRecoveryPreHook(_ http.ResponseWriter, req *http.Reques
 	return otelx.WithSpan(req.Context(), "selfservice.hook.WebHook.ExecuteRecoveryPreHook", func(ctx context.Context) error {
 		return e.execute(ctx, &templateContext{
 			Flow:           flow,
-			RequestHeaders: req.Header,
+			RequestHeaders: e.headers(req),
+			RequestCookies: e.cookies(req),
 			RequestMethod:  req.Method,
-			RequestCookies: cookies(req),
 			RequestURL:     x.RequestURL(req).String(),
 		})
 	})
@@ -187,10 +276,10 @@ func (e *WebHook) ExecutePostRecoveryHook(_ http.ResponseWriter, req *http.Reque
 	return otelx.WithSpan(req.Context(), "selfservice.hook.WebHook.ExecutePostRecoveryHook", func(ctx context.Context) error {
 		return e.execute(ctx, &templateContext{
 			Flow:           flow,
-			RequestHeaders: req.Header,
+			RequestHeaders: e.headers(req),
+			RequestCookies: e.cookies(req),
 			RequestMethod:  req.Method,
 			RequestURL:     x.RequestURL(req).String(),
-			RequestCookies: cookies(req),
 			Identity:       session.Identity,
 		})
 	})
@@ -200,10 +289,10 @@ func (e *WebHook) ExecuteRegistrationPreHook(_ http.ResponseWriter, req *http.Re
 	return otelx.WithSpan(req.Context(), "selfservice.hook.WebHook.ExecuteRegistrationPreHook", func(ctx context.Context) error {
 		return e.execute(ctx, &templateContext{
 			Flow:           flow,
-			RequestHeaders: req.Header,
+			RequestHeaders: e.headers(req),
+			RequestCookies: e.cookies(req),
 			RequestMethod:  req.Method,
 			RequestURL:     x.RequestURL(req).String(),
-			RequestCookies: cookies(req),
 		})
 	})
 }
@@ -216,10 +305,10 @@ func (e *WebHook) ExecutePostRegistrationPrePersistHook(_ http.ResponseWriter, r
 	return otelx.WithSpan(req.Context(), "selfservice.hook.WebHook.ExecutePostRegistrationPrePersistHook", func(ctx context.Context) error {
 		return e.execute(ctx, &templateContext{
 			Flow:           flow,
-			RequestHeaders: req.Header,
+			RequestHeaders: e.headers(req),
+			RequestCookies: e.cookies(req),
 			RequestMethod:  req.Method,
 			RequestURL:     x.RequestURL(req).String(),
-			RequestCookies: cookies(req),
 			Identity:       id,
 		})
 	})
@@ -237,10 +326,10 @@ func (e *WebHook) ExecutePostRegistrationPostPersistHook(_ http.ResponseWriter,
 	return otelx.WithSpan(ctx, "selfservice.hook.WebHook.ExecutePostRegistrationPostPersistHook", func(ctx context.Context) error {
 		return e.execute(ctx, &templateContext{
 			Flow:           flow,
-			RequestHeaders: req.Header,
+			RequestHeaders: e.headers(req),
+			RequestCookies: e.cookies(req),
 			RequestMethod:  req.Method,
 			RequestURL:     x.RequestURL(req).String(),
-			RequestCookies: cookies(req),
 			Identity:       session.Identity,
 		})
 	})
@@ -250,10 +339,10 @@ func (e *WebHook) ExecuteSettingsPreHook(_ http.ResponseWriter, req *http.Reques
 	return otelx.WithSpan(req.Context(), "selfservice.hook.WebHook.ExecuteSettingsPreHook", func(ctx context.Context) error {
 		return e.execute(ctx, &templateContext{
 			Flow:           flow,
-			RequestHeaders: req.Header,
+			RequestHeaders: e.headers(req),
+			RequestCookies: e.cookies(req),
 			RequestMethod:  req.Method,
 			RequestURL:     x.RequestURL(req).String(),
-			RequestCookies: cookies(req),
 		})
 	})
 }
@@ -265,10 +354,10 @@ func (e *WebHook) ExecuteSettingsPostPersistHook(_ http.ResponseWriter, req *htt
 	return otelx.WithSpan(req.Context(), "selfservice.hook.WebHook.ExecuteSettingsPostPersistHook", func(ctx context.Context) error {
 		return e.execute(ctx, &templateContext{
 			Flow:           flow,
-			RequestHeaders: req.Header,
+			RequestHeaders: e.headers(req),
+			RequestCookies: e.cookies(req),
 			RequestMethod:  req.Method,
 			RequestURL:     x.RequestURL(req).String(),
-			RequestCookies: cookies(req),
 			Identity:       id,
 		})
 	})
@@ -281,16 +370,32 @@ func (e *WebHook) ExecuteSettingsPrePersistHook(_ http.ResponseWriter, req *http
 	return otelx.WithSpan(req.Context(), "selfservice.hook.WebHook.ExecuteSettingsPrePersistHook", func(ctx context.Context) error {
 		return e.execute(ctx, &templateContext{
 			Flow:           flow,
-			RequestHeaders: req.Header,
+			RequestHeaders: e.headers(req),
+			RequestCookies: e.cookies(req),
 			RequestMethod:  req.Method,
 			RequestURL:     x.RequestURL(req).String(),
-			RequestCookies: cookies(req),
 			Identity:       id,
 		})
 	})
 }
 
 func (e *WebHook) execute(ctx context.Context, data *templateContext) error {
+	if data.Flow != nil {
+		data.FlowType = data.Flow.GetFlowName()
+		// An empty, `{}` or explicit `null` payload is omitted from the template context.
+		// Forms without a transient payload decode to an empty object.
+		tp := data.Flow.GetTransientPayload()
+		if parsed := gjson.ParseBytes(tp); parsed.Type != gjson.Null && !(parsed.IsObject() && len(parsed.Map()) == 0) {
+			data.TransientPayload = tp
+		}
+	}
+	if e.allowedHeaders != nil {
+		data.RequestHeaders = filterHeaders(data.RequestHeaders, e.allowedHeaders)
+		if !slices.Contains(e.allowedHeaders, "Cookie") {
+			data.RequestCookies = map[string]string{}
+		}
+	}
+
 	var (
 		httpClient     = e.deps.HTTPClient(ctx)
 		ignoreResponse = gjson.GetBytes(e.conf, "response.ignore").Bool()
@@ -23,6 +23,7 @@ import (
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"golang.org/x/exp/slices"
@@ -61,9 +62,11 @@ func TestWebHooks(t *testing.T) {
 	whDeps := struct {
 		x.SimpleLoggerWithClient
 		*jsonnetsecure.TestProvider
+		config.Provider
 	}{
 		x.SimpleLoggerWithClient{L: logger, C: reg.HTTPClient(context.Background()), T: otelx.NewNoop(logger, &otelx.Config{ServiceName: "kratos"})},
 		jsonnetsecure.NewTestProvider(t),
+		reg,
 	}
 	type WebHookRequest struct {
 		Body    string
@@ -116,8 +119,16 @@ func TestWebHooks(t *testing.T) {
 		return ts
 	}
 
+	// headersWithoutCredentials returns the request headers as passed to web hooks.
+	headersWithoutCredentials := func(req *http.Request) []byte {
+		h := req.Header.Clone()
+		h.Del("Authorization")
+		raw, _ := json.Marshal(h)
+		return raw
+	}
+
 	bodyWithFlowOnly := func(req *http.Request, f flow.Flow) string {
-		h, _ := json.Marshal(req.Header)
+		h := headersWithoutCredentials(req)
 		return fmt.Sprintf(`{
 					"flow_id": "%s",
 					"headers": %s,
@@ -132,7 +143,7 @@ func TestWebHooks(t *testing.T) {
 	}
 
 	bodyWithFlowAndIdentityAndTransientPayload := func(req *http.Request, f flow.Flow, s *session.Session, tp json.RawMessage) string {
-		h, _ := json.Marshal(req.Header)
+		h := headersWithoutCredentials(req)
 		return fmt.Sprintf(`{
 					"flow_id": "%s",
 					"identity_id": "%s",
@@ -149,7 +160,7 @@ func TestWebHooks(t *testing.T) {
 	}
 
 	bodyWithFlowAndIdentityAndSessionAndTransientPayload := func(req *http.Request, f flow.Flow, s *session.Session, tp json.RawMessage) string {
-		h, _ := json.Marshal(req.Header)
+		h := headersWithoutCredentials(req)
 		return fmt.Sprintf(`{
 					"flow_id": "%s",
 					"identity_id": "%s",
@@ -316,8 +327,9 @@ func TestWebHooks(t *testing.T) {
 							req := &http.Request{
 								Host: "www.ory.sh",
 								Header: map[string][]string{
-									"Some-Header": {"Some-Value"},
-									"Cookie":      {"Some-Cookie-1=Some-Cookie-Value; Some-Cookie-2=Some-other-Cookie-Value", "Some-Cookie-3=Third-Cookie-Value"},
+									"Some-Header":   {"Some-Value"},
+									"Authorization": {"Bearer Some-Token"},
+									"Cookie":        {"Some-Cookie-1=Some-Cookie-Value; Some-Cookie-2=Some-other-Cookie-Value", "Some-Cookie-3=Third-Cookie-Value"},
 								},
 								RequestURI: "/some_end_point",
 								Method:     http.MethodPost,
@@ -930,6 +942,50 @@ func TestWebHooks(t *testing.T) {
 		wg.Wait()
 	})
 
+	t.Run("can reference the flow type and transient payload", func(t *testing.T) {
+		t.Parallel()
+		whr := &WebHookRequest{}
+		ts := newServer(webHookEndPoint(whr))
+		conf := json.RawMessage(fmt.Sprintf(`{"url": "%s", "method": "POST", "body": "%s"}`, ts.URL+path, "file://./stub/flow_context.jsonnet"))
+		wh := hook.NewWebHook(&whDeps, conf)
+
+		for _, tc := range []struct {
+			flowType    string
+			greeting    string
+			callWebHook func(req *http.Request) error
+		}{
+			{
+				flowType: "registration",
+				greeting: "welcome",
+				callWebHook: func(req *http.Request) error {
+					return wh.ExecuteRegistrationPreHook(nil, req, &registration.Flow{ID: x.NewUUID(), TransientPayload: transientPayload})
+				},
+			},
+			{
+				flowType: "login",
+				greeting: "welcome back",
+				callWebHook: func(req *http.Request) error {
+					return wh.ExecuteLoginPreHook(nil, req, &login.Flow{ID: x.NewUUID(), TransientPayload: transientPayload})
+				},
+			},
+		} {
+			t.Run("flow="+tc.flowType, func(t *testing.T) {
+				req := &http.Request{
+					Header: map[string][]string{"Some-Header": {"Some-Value"}},
+					Host:   "www.ory.sh",
+					TLS:    new(tls.ConnectionState),
+					URL:    &url.URL{Path: "/some_end_point"},
+					Method: http.MethodPost,
+				}
+				require.NoError(t, tc.callWebHook(req))
+
+				assert.Equal(t, tc.flowType, gjson.Get(whr.Body, "flow_type").String(), whr.Body)
+				assert.Equal(t, tc.greeting, gjson.Get(whr.Body, "greeting").String(), whr.Body)
+				assert.JSONEq(t, string(transientPayload), gjson.Get(whr.Body, "transient_payload").Raw, whr.Body)
+			})
+		}
+	})
+
 	for _, tc := range []struct {
 		code        int
 		mustSuccess bool
@@ -982,9 +1038,11 @@ func TestDisallowPrivateIPRanges(t *testing.T) {
 	whDeps := struct {
 		x.SimpleLoggerWithClient
 		*jsonnetsecure.TestProvider
+		config.Provider
 	}{
 		x.SimpleLoggerWithClient{L: logger, C: reg.HTTPClient(context.Background()), T: otelx.NewNoop(logger, &otelx.Config{ServiceName: "kratos"})},
 		jsonnetsecure.NewTestProvider(t),
+		reg,
 	}
 
 	req := &http.Request{
@@ -1043,6 +1101,103 @@ func TestDisallowPrivateIPRanges(t *testing.T) {
 	})
 }
 
+func TestWebHookAllowedHeaders(t *testing.T) {
+	t.Parallel()
+	_, reg := internal.NewFastRegistryWithMocks(t)
+	logger := logrusx.New("kratos", "test")
+	whDeps := struct {
+		x.SimpleLoggerWithClient
+		*jsonnetsecure.TestProvider
+		config.Provider
+	}{
+		x.SimpleLoggerWithClient{L: logger, C: reg.HTTPClient(context.Background()), T: otelx.NewNoop(logger, &otelx.Config{ServiceName: "kratos"})},
+		jsonnetsecure.NewTestProvider(t),
+		reg,
+	}
+
+	bodies := make(chan []byte, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	t.Cleanup(ts.Close)
+
+	newRequest := func() *http.Request {
+		req := &http.Request{
+			Host: "www.ory.sh",
+			Header: map[string][]string{
+				"Some-Header":         {"Some-Value"},
+				"Other-Header":        {"Other-Value"},
+				"Authorization":       {"Bearer Some-Token"},
+				"Proxy-Authorization": {"Basic Some-Credentials"},
+				"X-Session-Token":     {"Some-Token"},
+				"Cookie": {
+					reg.Config().SessionName(context.Background()) + "=Some-Session; Some-Cookie-1=Some-Cookie-Value",
+					"Some-Cookie-2=Some-other-Cookie-Value",
+				},
+			},
+			RequestURI: "/some_end_point",
+			Method:     http.MethodPost,
+			URL:        &url.URL{Path: "/some_end_point"},
+		}
+		req.Header.Add("Cookie", x.CSRFCookieName(reg, req)+"=Some-CSRF-Token")
+		return req
+	}
+
+	run := func(t *testing.T, allowedHeaders []string) gjson.Result {
+		wh := hook.NewWebHook(&whDeps, json.RawMessage(`{"url": "`+ts.URL+`", "method": "POST", "body": "file://./stub/test_body.jsonnet"}`)).
+			WithAllowedHeaders(allowedHeaders)
+		req := newRequest()
+		require.NoError(t, wh.ExecuteLoginPreHook(nil, req, &login.Flow{ID: x.NewUUID()}))
+		assert.Len(t, req.Header, 6, "the incoming request must not be modified")
+		assert.Len(t, req.Cookies(), 4, "the incoming request must not be modified")
+		return gjson.ParseBytes(<-bodies)
+	}
+
+	assertNoCredentials := func(t *testing.T, body gjson.Result) {
+		assert.False(t, body.Get("headers.Authorization").Exists(), "%s", body.Raw)
+		assert.False(t, body.Get("headers.Proxy-Authorization").Exists(), "%s", body.Raw)
+		assert.False(t, body.Get("headers.X-Session-Token").Exists(), "%s", body.Raw)
+		assert.NotContains(t, body.Get("headers.Cookie").Raw, "Some-Session", "%s", body.Raw)
+		assert.NotContains(t, body.Get("headers.Cookie").Raw, "Some-CSRF-Token", "%s", body.Raw)
+		for _, c := range body.Get("cookies").Map() {
+			assert.NotEqual(t, "Some-Session", c.String(), "%s", body.Raw)
+			assert.NotEqual(t, "Some-CSRF-Token", c.String(), "%s", body.Raw)
+		}
+	}
+
+	t.Run("case=passes all headers and cookies by default", func(t *testing.T) {
+		body := run(t, nil)
+		assert.Equal(t, "Some-Value", body.Get("headers.Some-Header.0").String(), "%s", body.Raw)
+		assert.Equal(t, "Other-Value", body.Get("headers.Other-Header.0").String(), "%s", body.Raw)
+		assert.Equal(t, []any{"Some-Cookie-1=Some-Cookie-Value", "Some-Cookie-2=Some-other-Cookie-Value"}, body.Get("headers.Cookie").Value(), "%s", body.Raw)
+		assert.JSONEq(t, `{"Some-Cookie-1":"Some-Cookie-Value","Some-Cookie-2":"Some-other-Cookie-Value"}`, body.Get("cookies").Raw, "%s", body.Raw)
+		assertNoCredentials(t, body)
+	})
+
+	t.Run("case=passes only allowed headers", func(t *testing.T) {
+		body := run(t, []string{"Some-Header"})
+		assert.Equal(t, "Some-Value", body.Get("headers.Some-Header.0").String(), "%s", body.Raw)
+		assert.False(t, body.Get("headers.Other-Header").Exists(), "%s", body.Raw)
+		assert.False(t, body.Get("headers.Cookie").Exists(), "%s", body.Raw)
+		assert.Empty(t, body.Get("cookies").Map(), "%s", body.Raw)
+		assertNoCredentials(t, body)
+	})
+
+	t.Run("case=never passes credentials", func(t *testing.T) {
+		body := run(t, []string{"Some-Header", "Authorization", "Proxy-Authorization", "X-Session-Token", "Cookie"})
+		assert.Equal(t, "Some-Value", body.Get("headers.Some-Header.0").String(), "%s", body.Raw)
+		assert.JSONEq(t, `{"Some-Cookie-1":"Some-Cookie-Value","Some-Cookie-2":"Some-other-Cookie-Value"}`, body.Get("cookies").Raw, "%s", body.Raw)
+		assertNoCredentials(t, body)
+	})
+
+	t.Run("case=passes no headers if the allowlist is empty", func(t *testing.T) {
+		body := run(t, []string{})
+		assert.Empty(t, body.Get("headers").Map(), "%s", body.Raw)
+		assert.Empty(t, body.Get("cookies").Map(), "%s", body.Raw)
+	})
+}
+
 func TestAsyncWebhook(t *testing.T) {
 	t.Parallel()
 	_, reg := internal.NewFastRegistryWithMocks(t)
@@ -1052,9 +1207,11 @@ func TestAsyncWebhook(t *testing.T) {
 	whDeps := struct {
 		x.SimpleLoggerWithClient
 		*jsonnetsecure.TestProvider
+		config.Provider
 	}{
 		x.SimpleLoggerWithClient{L: logger, C: reg.HTTPClient(context.Background()), T: otelx.NewNoop(logger, &otelx.Config{ServiceName: "kratos"})},
 		jsonnetsecure.NewTestProvider(t),
+		reg,
 	}
 
 	req := &http.Request{
@@ -1132,9 +1289,11 @@ func TestWebhookEvents(t *testing.T) {
 	whDeps := struct {
 		x.SimpleLoggerWithClient
 		*jsonnetsecure.TestProvider
+		config.Provider
 	}{
 		x.SimpleLoggerWithClient{L: logger, C: reg.HTTPClient(context.Background()), T: otelx.NewNoop(logger, &otelx.Config{ServiceName: "kratos"})},
 		jsonnetsecure.NewTestProvider(t),
+		reg,
 	}
 
 	req := &http.Request{
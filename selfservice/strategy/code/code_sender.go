@@ -222,26 +222,88 @@ func (s *Sender) SendRecoveryCode(ctx context.Context, f *recovery.Flow, via ide
 		return err
 	}
 
-	rawCode := GenerateCode()
+	// A code is created for every configured channel the identity has an address
+	// for. The courier sends the first one and falls back to the next channel if
+	// it fails to deliver the message. The first message which is delivered
+	// stops the chain.
+	var templates []courier.Template
+	for _, channel := range s.deps.Config().SelfServiceFlowRecoveryChannelOrder(ctx) {
+		channelAddress := recoveryAddressForChannel(i, address, channel)
+		if channelAddress == nil {
+			continue
+		}
+
+		rawCode := GenerateCode()
 
-	var code *RecoveryCode
-	if code, err = s.deps.
-		RecoveryCodePersister().
-		CreateRecoveryCode(ctx, &CreateRecoveryCodeParams{
-			RawCode:         rawCode,
-			CodeType:        RecoveryCodeTypeSelfService,
-			ExpiresIn:       s.deps.Config().SelfServiceCodeMethodLifespan(ctx),
-			RecoveryAddress: address,
-			FlowID:          f.ID,
-			IdentityID:      i.ID,
-		}); err != nil {
+		var code *RecoveryCode
+		if code, err = s.deps.
+			RecoveryCodePersister().
+			CreateRecoveryCode(ctx, &CreateRecoveryCodeParams{
+				RawCode:         rawCode,
+				CodeType:        RecoveryCodeTypeSelfService,
+				ExpiresIn:       s.deps.Config().SelfServiceCodeMethodLifespan(ctx),
+				RecoveryAddress: channelAddress,
+				FlowID:          f.ID,
+				IdentityID:      i.ID,
+			}); err != nil {
+			return err
+		}
+
+		t, err := s.recoveryCodeTemplate(ctx, i, rawCode, code, f)
+		if err != nil {
+			return err
+		}
+		templates = append(templates, t)
+	}
+
+	if len(templates) == 0 {
+		// None of the configured channels has an address for this identity. We
+		// handle this like an unknown address to prevent account enumeration.
+		s.deps.Audit().
+			WithField("identity_id", i.ID).
+			WithField("strategy", "code").
+			Info("Account recovery was requested but the identity has no address for any of the configured recovery channels.")
+		return errors.WithStack(ErrUnknownAddress)
+	}
+
+	c, err := s.deps.Courier(ctx)
+	if err != nil {
 		return err
 	}
 
-	return s.SendRecoveryCodeTo(ctx, i, rawCode, code, f)
+	_, err = c.QueueWithFallback(ctx, templates)
+	return err
+}
+
+// recoveryAddressForChannel returns the address the recovery code should be sent
+// to for the given channel. The address the user entered is preferred if it
+// belongs to the channel.
+func recoveryAddressForChannel(i *identity.Identity, requested *identity.RecoveryAddress, channel string) *identity.RecoveryAddress {
+	if string(requested.Via) == channel {
+		return requested
+	}
+
+	for k := range i.RecoveryAddresses {
+		if string(i.RecoveryAddresses[k].Via) == channel {
+			return &i.RecoveryAddresses[k]
+		}
+	}
+
+	return nil
 }
 
 func (s *Sender) SendRecoveryCodeTo(ctx context.Context, i *identity.Identity, codeString string, code *RecoveryCode, f *recovery.Flow) error {
+	t, err := s.recoveryCodeTemplate(ctx, i, codeString, code, f)
+	if err != nil {
+		return err
+	}
+
+	return s.send(ctx, string(code.RecoveryAddress.Via), t)
+}
+
+// recoveryCodeTemplate returns the template of the message sending the recovery code
+// to the code's recovery address.
+func (s *Sender) recoveryCodeTemplate(ctx context.Context, i *identity.Identity, codeString string, code *RecoveryCode, f *recovery.Flow) (courier.Template, error) {
 	s.deps.Audit().
 		WithField("via", code.RecoveryAddress.Via).
 		WithField("identity_id", code.RecoveryAddress.IdentityID).
@@ -252,23 +314,34 @@ func (s *Sender) SendRecoveryCodeTo(ctx context.Context, i *identity.Identity, c
 
 	model, err := x.StructToMap(i)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	transientPayload, err := x.ParseRawMessageOrEmpty(f.GetTransientPayload())
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
 
-	emailModel := email.RecoveryCodeValidModel{
-		To:               code.RecoveryAddress.Value,
-		RecoveryCode:     codeString,
-		Identity:         model,
-		RequestURL:       f.GetRequestURL(),
-		TransientPayload: transientPayload,
+	switch code.RecoveryAddress.Via {
+	case identity.RecoveryAddressTypeEmail:
+		return email.NewRecoveryCodeValid(s.deps, &email.RecoveryCodeValidModel{
+			To:               code.RecoveryAddress.Value,
+			RecoveryCode:     codeString,
+			Identity:         model,
+			RequestURL:       f.GetRequestURL(),
+			TransientPayload: transientPayload,
+		}), nil
+	case identity.RecoveryAddressTypeSMS:
+		return sms.NewRecoveryCodeValid(s.deps, &sms.RecoveryCodeValidModel{
+			To:               code.RecoveryAddress.Value,
+			RecoveryCode:     codeString,
+			Identity:         model,
+			RequestURL:       f.GetRequestURL(),
+			TransientPayload: transientPayload,
+		}), nil
+	default:
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Expected email or sms but got %s", code.RecoveryAddress.Via))
 	}
-
-	return s.send(ctx, string(code.RecoveryAddress.Via), email.NewRecoveryCodeValid(s.deps, &emailModel))
 }
 
 // SendVerificationCode sends a verification code & link to the specified address
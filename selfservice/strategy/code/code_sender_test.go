@@ -8,6 +8,8 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"regexp"
 	"testing"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
@@ -24,6 +27,7 @@ import (
 	"github.com/ory/kratos/selfservice/flow/recovery"
 	"github.com/ory/kratos/selfservice/flow/verification"
 	"github.com/ory/kratos/selfservice/strategy/code"
+	"github.com/ory/kratos/x"
 	"github.com/ory/x/urlx"
 )
 
@@ -102,6 +106,96 @@ func TestSender(t *testing.T) {
 		})
 	})
 
+	t.Run("method=SendRecoveryCode with channel_order", func(t *testing.T) {
+		var delivered []string
+		newChannel := func(t *testing.T, status int) string {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if status < 300 {
+					delivered = append(delivered, gjson.GetBytes(x.MustReadAll(r.Body), "recipient").String())
+				}
+				w.WriteHeader(status)
+			}))
+			t.Cleanup(srv.Close)
+			return srv.URL
+		}
+
+		// The email channel rejects all messages, the SMS channel delivers them.
+		failingURL, succeedingURL := newChannel(t, http.StatusBadRequest), newChannel(t, http.StatusOK)
+		conf.MustSet(ctx, config.ViperKeySelfServiceRecoveryChannelOrder, []string{"email", "sms"})
+		conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "http")
+		conf.MustSet(ctx, config.ViperKeyCourierChannels, fmt.Sprintf(`[{"id": "sms", "type": "http", "request_config": {"url": "%s", "method": "POST", "body": "base64://%s"}}]`,
+			succeedingURL, b64(`function(ctx) { recipient: ctx.recipient }`)))
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceRecoveryChannelOrder, nil)
+			conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "smtp")
+			conf.MustSet(ctx, config.ViperKeyCourierHTTPRequestConfig, nil)
+			conf.MustSet(ctx, config.ViperKeyCourierChannels, []any{})
+		})
+
+		sendRecoveryCode := func(t *testing.T, emailURL, phone string) (courier.Courier, *recovery.Flow, string) {
+			conf.MustSet(ctx, config.ViperKeyCourierHTTPRequestConfig, fmt.Sprintf(`{"url": "%s", "method": "POST", "body": "base64://%s"}`,
+				emailURL, b64(`function(ctx) { recipient: ctx.recipient }`)))
+			c, err := reg.Courier(ctx)
+			require.NoError(t, err)
+
+			email := testhelpers.RandomEmail()
+			i := identity.NewIdentity(testhelpers.UseIdentitySchema(t, conf, "file://./stub/recovery_sms.schema.json"))
+			i.Traits = identity.Traits(fmt.Sprintf(`{"email": "%s", "phone": "%s"}`, email, phone))
+			require.NoError(t, reg.IdentityManager().Create(ctx, i))
+
+			f, err := recovery.NewFlow(conf, time.Hour, "", u, code.NewStrategy(reg), flow.TypeBrowser)
+			require.NoError(t, err)
+			require.NoError(t, reg.RecoveryFlowPersister().CreateRecoveryFlow(ctx, f))
+
+			delivered = nil
+			require.NoError(t, reg.CodeSender().SendRecoveryCode(ctx, f, "email", email))
+			return c, f, email
+		}
+
+		findMessage := func(t *testing.T, recipient string) *courier.Message {
+			messages, _, _, err := reg.CourierPersister().ListMessages(ctx, courier.ListCourierMessagesParameters{Recipient: recipient}, nil)
+			require.NoError(t, err)
+			require.Len(t, messages, 1)
+			return &messages[0]
+		}
+
+		t.Run("case=falls back to the next channel if delivery fails", func(t *testing.T) {
+			phone := "+4917612345678"
+			c, f, email := sendRecoveryCode(t, failingURL, phone)
+
+			// Only the email is queued at first.
+			require.NoError(t, c.DispatchQueue(ctx))
+			assert.Empty(t, delivered)
+			assert.Equal(t, courier.MessageStatusAbandoned, findMessage(t, email).Status)
+
+			// The failed email was replaced by the SMS.
+			require.NoError(t, c.DispatchQueue(ctx))
+			assert.Equal(t, []string{phone}, delivered)
+
+			message := findMessage(t, phone)
+			assert.Equal(t, courier.MessageStatusSent, message.Status)
+			assert.EqualValues(t, courier.MessageTypeSMS, message.Type)
+			assert.Contains(t, message.Body, "Your recovery code is: ")
+
+			_, err := reg.RecoveryCodePersister().UseRecoveryCode(ctx, f.ID, regexp.MustCompile(testhelpers.CodeRegex).FindString(message.Body))
+			require.NoError(t, err)
+		})
+
+		t.Run("case=stops at the first delivered message", func(t *testing.T) {
+			phone := "+4917687654321"
+			c, _, email := sendRecoveryCode(t, succeedingURL, phone)
+
+			require.NoError(t, c.DispatchQueue(ctx))
+			require.NoError(t, c.DispatchQueue(ctx))
+			assert.Equal(t, []string{email}, delivered)
+			assert.Equal(t, courier.MessageStatusSent, findMessage(t, email).Status)
+
+			messages, _, _, err := reg.CourierPersister().ListMessages(ctx, courier.ListCourierMessagesParameters{Recipient: phone}, nil)
+			require.NoError(t, err)
+			assert.Empty(t, messages)
+		})
+	})
+
 	t.Run("method=SendVerificationCode", func(t *testing.T) {
 		verificationFlow := func(t *testing.T) {
 			t.Helper()
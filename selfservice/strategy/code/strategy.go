@@ -133,24 +133,28 @@ func (s *Strategy) NodeGroup() node.UiNodeGroup {
 	return node.CodeGroup
 }
 
+// EnabledForAAL returns true if the code method is offered for the given
+// assurance level: as a first factor if `passwordless_enabled` is set and as
+// a second factor if `mfa_enabled` is set.
+func (s *Strategy) EnabledForAAL(ctx context.Context, aal identity.AuthenticatorAssuranceLevel) bool {
+	codeConfig := s.deps.Config().SelfServiceCodeStrategy(ctx)
+	if aal == identity.AuthenticatorAssuranceLevel2 {
+		return codeConfig.MFAEnabled
+	}
+	return codeConfig.PasswordlessEnabled
+}
+
 func (s *Strategy) PopulateMethod(r *http.Request, f flow.Flow) error {
-	codeConfig := s.deps.Config().SelfServiceCodeStrategy(r.Context())
 	switch f := f.(type) {
 	case *login.Flow:
-		if f.RequestedAAL == identity.AuthenticatorAssuranceLevel2 {
-			if !codeConfig.MFAEnabled {
-				// if the flow is requesting AAL2 but MFA is not enabled for the code strategy, we return nil so that
-				// other strategies can fulfil the request
-				return nil
-			}
-		} else if !codeConfig.PasswordlessEnabled {
-			// if the flow is a normal login flow but passwordless is not enabled for the code strategy,
-			// we return nil so that other strategies can fulfil the request
+		if !s.EnabledForAAL(r.Context(), f.RequestedAAL) {
+			// if the code strategy is not enabled for the requested AAL, we return nil so that
+			// other strategies can fulfil the request
 			return nil
 		}
 	case *registration.Flow:
 		// for registration flows, we don't have AAL requirements, so we just check that passwordless is enabled.
-		if !codeConfig.PasswordlessEnabled {
+		if !s.EnabledForAAL(r.Context(), identity.AuthenticatorAssuranceLevel1) {
 			return nil
 		}
 	}
@@ -166,7 +170,7 @@ func (s *Strategy) PopulateMethod(r *http.Request, f flow.Flow) error {
 		if err := s.populateChooseMethodFlow(r, f); err != nil {
 			return err
 		}
-	case flow.StateEmailSent:
+	case flow.StateEmailSent, flow.StateSMSSent:
 		if err := s.populateEmailSentFlow(r.Context(), f); err != nil {
 			return err
 		}
@@ -223,7 +227,10 @@ func (s *Strategy) populateChooseMethodFlow(r *http.Request, f flow.Flow) error
 			}
 
 			value := gjson.GetBytes(sess.Identity.Traits, via).String()
-			if value == "" {
+			if value == "" && s.deps.Config().SelfServiceFlowLoginHideUnavailableMFAMethods(ctx) {
+				// The identity can not receive a code, so we do not offer this method.
+				return nil
+			} else if value == "" {
 				return errors.WithStack(herodot.ErrBadRequest.WithReasonf("No value found for trait %s in the current identity", via))
 			}
 
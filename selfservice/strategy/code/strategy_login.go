@@ -90,7 +90,8 @@ func (s *Strategy) HandleLoginError(r *http.Request, f *login.Flow, body *update
 
 	if f != nil {
 		email := ""
-		if body != nil {
+		// Once the code was sent, the identifier is required to submit the code and is always kept.
+		if body != nil && (f.GetState() != flow.StateChooseMethod || s.deps.Config().SelfServiceFlowLoginEchoIdentifierOnError(r.Context())) {
 			email = body.Identifier
 		}
 
@@ -391,6 +391,35 @@ func TestLoginCodeStrategy(t *testing.T) {
 				})
 			})
 
+			t.Run("case=should not echo the identifier on error if disabled", func(t *testing.T) {
+				previous := conf.SelfServiceFlowLoginEchoIdentifierOnError(ctx)
+				conf.MustSet(ctx, config.ViperKeySelfServiceLoginEchoIdentifierOnError, false)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeySelfServiceLoginEchoIdentifierOnError, previous)
+				})
+
+				s := createLoginFlow(ctx, t, public, tc.apiType, false)
+
+				// submit email
+				s = submitLogin(ctx, t, s, tc.apiType, func(v *url.Values) {
+					v.Set("identifier", testhelpers.RandomEmail())
+				}, false, func(t *testing.T, s *state, body string, resp *http.Response) {
+					if tc.apiType == ApiTypeBrowser {
+						require.EqualValues(t, http.StatusOK, resp.StatusCode)
+
+						lf, _, err := testhelpers.NewSDKCustomClient(public, s.client).FrontendApi.GetLoginFlow(ctx).Id(s.flowID).Execute()
+						require.NoError(t, err)
+						raw, err := json.Marshal(lf)
+						require.NoError(t, err)
+						body = string(raw)
+					} else {
+						require.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+					}
+					assert.Contains(t, gjson.Get(body, "ui.messages.0.text").String(), "account does not exist or has not setup sign in with code", "%s", body)
+					assert.Empty(t, gjson.Get(body, "ui.nodes.#(attributes.name==identifier).attributes.value").String(), "%s", body)
+				})
+			})
+
 			t.Run("case=should not be able to use valid code after 5 attempts", func(t *testing.T) {
 				s := createLoginFlow(ctx, t, public, tc.apiType, false)
 
@@ -738,6 +767,28 @@ func TestLoginCodeStrategy(t *testing.T) {
 					}
 					require.Equal(t, "No value found for trait email_1 in the current identity", gjson.GetBytes(body, "reason").String(), "%s", body)
 				})
+
+				t.Run("case=unset trait in identity hides the method with hide_unavailable_mfa_methods", func(t *testing.T) {
+					conf.MustSet(ctx, config.ViperKeySelfServiceLoginHideUnavailableMFAMethods, true)
+					t.Cleanup(func() {
+						conf.MustSet(ctx, config.ViperKeySelfServiceLoginHideUnavailableMFAMethods, false)
+					})
+
+					identity := createIdentity(ctx, t, false)
+					var cl *http.Client
+					var f *oryClient.LoginFlow
+					if tc.apiType == ApiTypeNative {
+						cl = testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, identity)
+						f = testhelpers.InitializeLoginFlowViaAPI(t, cl, public, false, testhelpers.InitFlowWithAAL("aal2"), testhelpers.InitFlowWithVia("email_1"))
+					} else {
+						cl = testhelpers.NewHTTPClientWithIdentitySessionCookieLocalhost(t, reg, identity)
+						f = testhelpers.InitializeLoginFlowViaBrowser(t, cl, public, false, tc.apiType == ApiTypeSPA, false, false, testhelpers.InitFlowWithAAL("aal2"), testhelpers.InitFlowWithVia("email_1"))
+					}
+
+					body, err := json.Marshal(f)
+					require.NoError(t, err)
+					assert.Empty(t, gjson.GetBytes(body, "ui.nodes.#(group==code)#").Array(), "%s", body)
+				})
 			})
 		})
 	}
@@ -694,6 +694,33 @@ func TestRecovery(t *testing.T) {
 		assert.True(t, gjson.Get(body, "ui.nodes.#(attributes.name==email)").Exists())
 	})
 
+	t.Run("description=should respect the configured maximum submit attempts", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceRecoveryMaxSubmitAttempts, 2)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceRecoveryMaxSubmitAttempts, 5)
+		})
+
+		email := testhelpers.RandomEmail()
+		createIdentityToRecover(t, reg, email)
+		c := testhelpers.NewClientWithCookies(t)
+		body := submitRecovery(t, c, RecoveryClientTypeBrowser, func(v url.Values) {
+			v.Set("email", email)
+		}, http.StatusOK)
+		initialFlowId := gjson.Get(body, "id")
+
+		for submitTry := 0; submitTry < 2; submitTry++ {
+			body := submitRecoveryCode(t, c, body, RecoveryClientTypeBrowser, "12312312", http.StatusOK)
+
+			testhelpers.AssertMessage(t, []byte(body), "The recovery code is invalid or has already been used. Please try again.")
+		}
+
+		body = submitRecoveryCode(t, c, body, RecoveryClientTypeBrowser, "12312312", http.StatusOK)
+
+		require.Len(t, gjson.Get(body, "ui.messages").Array(), 1)
+		assert.Equal(t, "The request was submitted too often. Please request another code.", gjson.Get(body, "ui.messages.0.text").String())
+		assert.NotEqual(t, initialFlowId.String(), gjson.Get(body, "id").String())
+	})
+
 	t.Run("description=should be able to recover after using invalid code", func(t *testing.T) {
 		for _, testCase := range flowTypeCases {
 			t.Run("type="+testCase.ClientType.String(), func(t *testing.T) {
@@ -37,7 +37,7 @@ func (s *Strategy) RegisterAdminVerificationRoutes(admin *x.RouterAdmin) {
 
 // PopulateVerificationMethod set's the appropriate UI nodes on this flow
 //
-// If the flow's state is `sent_email`, the `code` input and the success notification is set
+// If the flow's state is `sent_email` or `sent_sms`, the `code` input and the success notification is set
 // Otherwise, the default email input is added.
 // If the flow is a browser flow, the CSRF token is added to the UI.
 func (s *Strategy) PopulateVerificationMethod(r *http.Request, f *verification.Flow) error {
@@ -153,7 +153,7 @@ func (s *Strategy) Verify(w http.ResponseWriter, r *http.Request, f *verificatio
 	switch f.State {
 	case flow.StateChooseMethod:
 		fallthrough
-	case flow.StateEmailSent:
+	case flow.StateEmailSent, flow.StateSMSSent:
 		return s.verificationHandleFormSubmission(w, r, f, body)
 	case flow.StatePassedChallenge:
 		return s.retryVerificationFlowWithMessage(w, r, f.Type, text.NewErrorValidationVerificationRetrySuccess())
@@ -208,14 +208,15 @@ func (s *Strategy) verificationHandleFormSubmission(w http.ResponseWriter, r *ht
 		return s.handleVerificationError(w, r, f, body, err)
 	}
 
-	if err := s.deps.CodeSender().SendVerificationCode(r.Context(), f, identity.VerifiableAddressTypeEmail, body.Email); err != nil {
+	via := identity.VerifiableAddressTypeEmail
+	if err := s.deps.CodeSender().SendVerificationCode(r.Context(), f, via, body.Email); err != nil {
 		if !errors.Is(err, ErrUnknownAddress) {
 			return s.handleVerificationError(w, r, f, body, err)
 		}
 		// Continue execution
 	}
 
-	f.State = flow.StateEmailSent
+	f.State = flow.SentState(via)
 
 	if err := s.PopulateVerificationMethod(r, f); err != nil {
 		return s.handleVerificationError(w, r, f, body, err)
@@ -34,6 +34,7 @@ import (
 	"github.com/ory/kratos/internal/testhelpers"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/verification"
+	"github.com/ory/kratos/selfservice/hook/hooktest"
 	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/x"
 	"github.com/ory/x/assertx"
@@ -437,6 +438,47 @@ func TestVerification(t *testing.T) {
 		assert.Equal(t, returnToURL, gjson.GetBytes(body, "ui.nodes.#(attributes.id==continue).attributes.href").String())
 	})
 
+	t.Run("description=should pass transient payload to webhooks", func(t *testing.T) {
+		webhookTS := hooktest.NewServer()
+		t.Cleanup(webhookTS.Close)
+
+		conf.MustSet(ctx, config.ViperKeySelfServiceVerificationAfter+".hooks", []config.SelfServiceHook{webhookTS.HookConfig()})
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeySelfServiceVerificationAfter+".hooks", nil) })
+
+		submit := func(t *testing.T, transientPayload string) {
+			webhookTS.LastBody = nil
+			f, _, rawCode := newValidBrowserFlow(t, public.URL+verification.RouteInitBrowserFlow)
+
+			values := url.Values{
+				"code":       {rawCode},
+				"csrf_token": {x.FakeCSRFToken},
+			}
+			if transientPayload != "" {
+				values.Set("transient_payload", transientPayload)
+			}
+
+			res, err := (&http.Client{}).PostForm(public.URL+verification.RouteSubmitFlow+"?flow="+f.ID.String(), values)
+			require.NoError(t, err)
+			body := ioutilx.MustReadAll(res.Body)
+			require.EqualValues(t, "passed_challenge", gjson.GetBytes(body, "state").String(), "%s", body)
+			require.NotEmpty(t, webhookTS.LastBody)
+		}
+
+		t.Run("case=payload is set", func(t *testing.T) {
+			payload := `{"signup_id":"abc"}`
+			submit(t, payload)
+
+			assert.JSONEq(t, payload, gjson.GetBytes(webhookTS.LastBody, "transient_payload").Raw, "%s", webhookTS.LastBody)
+			webhookTS.AssertTransientPayload(t, payload)
+		})
+
+		t.Run("case=payload is not set", func(t *testing.T) {
+			submit(t, "")
+
+			assert.False(t, gjson.GetBytes(webhookTS.LastBody, "transient_payload").Exists(), "%s", webhookTS.LastBody)
+		})
+	})
+
 	t.Run("case=should respond with replaced error if successful code is submitted again via api", func(t *testing.T) {
 		_ = expectSuccess(t, nil, true, false, func(v url.Values) {
 			v.Set("email", verificationEmail)
@@ -579,6 +621,35 @@ func TestVerification(t *testing.T) {
 		assert.True(t, gjson.Get(body, "ui.nodes.#(attributes.name==email)").Exists())
 	})
 
+	t.Run("description=should respect the configured maximum submit attempts", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceVerificationMaxSubmitAttempts, 2)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceVerificationMaxSubmitAttempts, 5)
+		})
+
+		email := strings.ToLower(testhelpers.RandomEmail())
+		createIdentityToRecover(t, reg, email)
+		c := testhelpers.NewClientWithCookies(t)
+
+		body := expectSuccess(t, nil, true, false, func(v url.Values) {
+			v.Set("email", verificationEmail)
+		})
+		initialFlowId := gjson.Get(body, "id")
+
+		for submitTry := 0; submitTry < 2; submitTry++ {
+			xcbody, _ := submitVerificationCode(t, body, c, "12312312")
+			require.Equal(t, initialFlowId.String(), gjson.Get(xcbody, "id").String())
+
+			testhelpers.AssertMessage(t, []byte(xcbody), "The verification code is invalid or has already been used. Please try again.")
+		}
+
+		body, _ = submitVerificationCode(t, body, c, "12312312")
+
+		require.Len(t, gjson.Get(body, "ui.messages").Array(), 1)
+		assert.Equal(t, "The request was submitted too often. Please request another code.", gjson.Get(body, "ui.messages.0.text").String())
+		assert.NotEqual(t, initialFlowId.String(), gjson.Get(body, "id").String())
+	})
+
 	t.Run("description=should be able to verify already verified email address", func(t *testing.T) {
 		email := strings.ToLower(testhelpers.RandomEmail())
 		createIdentityToRecover(t, reg, email)
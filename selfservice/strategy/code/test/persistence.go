@@ -126,6 +126,27 @@ func TestPersister(ctx context.Context, p interface {
 				require.ErrorIs(t, err, code.ErrCodeSubmittedTooOften)
 			})
 
+			t.Run("case=should make the flow unusable after the configured number of tries", func(t *testing.T) {
+				ctx := confighelpers.WithConfigValue(ctx, config.ViperKeySelfServiceRecoveryMaxSubmitAttempts, 2)
+
+				dto, f, _ := newRecoveryCodeDTO(t, testhelpers.RandomEmail())
+				_, err := p.CreateRecoveryCode(ctx, dto)
+				require.NoError(t, err)
+
+				for i := 1; i <= 2; i++ {
+					_, err = p.UseRecoveryCode(ctx, f.ID, "i-do-not-exist")
+					require.Error(t, err)
+					require.NotErrorIs(t, err, code.ErrCodeSubmittedTooOften)
+				}
+
+				_, err = p.UseRecoveryCode(ctx, f.ID, "i-do-not-exist")
+				require.ErrorIs(t, err, code.ErrCodeSubmittedTooOften)
+
+				// Even the correct code can no longer be used
+				_, err = p.UseRecoveryCode(ctx, f.ID, dto.RawCode)
+				require.ErrorIs(t, err, code.ErrCodeSubmittedTooOften)
+			})
+
 			t.Run("case=should delete codes of flow", func(t *testing.T) {
 				dto, f, _ := newRecoveryCodeDTO(t, testhelpers.RandomEmail())
 				for i := 0; i < 10; i++ {
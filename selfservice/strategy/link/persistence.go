@@ -13,6 +13,9 @@ type (
 	RecoveryTokenPersister interface {
 		CreateRecoveryToken(ctx context.Context, token *RecoveryToken) error
 		UseRecoveryToken(ctx context.Context, fID uuid.UUID, token string) (*RecoveryToken, error)
+		// FindRecoveryTokenFlowID returns the ID of the recovery flow an unused
+		// token belongs to.
+		FindRecoveryTokenFlowID(ctx context.Context, token string) (uuid.UUID, error)
 		DeleteRecoveryToken(ctx context.Context, token string) error
 	}
 
@@ -23,6 +26,9 @@ type (
 	VerificationTokenPersister interface {
 		CreateVerificationToken(ctx context.Context, token *VerificationToken) error
 		UseVerificationToken(ctx context.Context, fID uuid.UUID, token string) (*VerificationToken, error)
+		// FindVerificationTokenFlowID returns the ID of the verification flow an
+		// unused token belongs to.
+		FindVerificationTokenFlowID(ctx context.Context, token string) (uuid.UUID, error)
 		DeleteVerificationToken(ctx context.Context, token string) error
 	}
 
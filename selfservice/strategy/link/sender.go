@@ -5,14 +5,12 @@ package link
 
 import (
 	"context"
-	"net/url"
 
 	"github.com/ory/kratos/courier/template/email"
 
 	"github.com/pkg/errors"
 
 	"github.com/ory/x/sqlcon"
-	"github.com/ory/x/urlx"
 
 	"github.com/ory/kratos/courier"
 	"github.com/ory/kratos/driver/config"
@@ -99,7 +97,7 @@ func (s *Sender) SendRecoveryLink(ctx context.Context, f *recovery.Flow, via ide
 		return err
 	}
 
-	token := NewSelfServiceRecoveryToken(address, f, s.r.Config().SelfServiceLinkMethodLifespan(ctx))
+	token := NewSelfServiceRecoveryToken(address, f, s.r.Config().SelfServiceLinkMethodLifespan(ctx), recoveryTokenLength(ctx, s.r.Config()))
 	if err := s.r.RecoveryTokenPersister().CreateRecoveryToken(ctx, token); err != nil {
 		return err
 	}
@@ -157,7 +155,7 @@ func (s *Sender) SendVerificationLink(ctx context.Context, f *verification.Flow,
 		return err
 	}
 
-	token := NewSelfServiceVerificationToken(address, f, s.r.Config().SelfServiceLinkMethodLifespan(ctx))
+	token := NewSelfServiceVerificationToken(address, f, s.r.Config().SelfServiceLinkMethodLifespan(ctx), verificationTokenLength(ctx, s.r.Config()))
 	if err := s.r.VerificationTokenPersister().CreateVerificationToken(ctx, token); err != nil {
 		return err
 	}
@@ -187,13 +185,7 @@ func (s *Sender) SendRecoveryTokenTo(ctx context.Context, f *recovery.Flow, i *i
 		return errors.WithStack(err)
 	}
 
-	recoveryUrl := urlx.CopyWithQuery(
-		urlx.AppendPaths(s.r.Config().SelfServiceLinkMethodBaseURL(ctx), recovery.RouteSubmitFlow),
-		url.Values{
-			"token": {token.Token},
-			"flow":  {f.ID.String()},
-		}).
-		String()
+	recoveryUrl := recoveryLink(ctx, s.r.Config(), s.r.Config().SelfServiceLinkMethodBaseURL(ctx), f.ID, token.Token)
 
 	return s.send(ctx, string(address.Via), email.NewRecoveryValid(s.r,
 		&email.RecoveryValidModel{
@@ -224,12 +216,7 @@ func (s *Sender) SendVerificationTokenTo(ctx context.Context, f *verification.Fl
 		return errors.WithStack(err)
 	}
 
-	verificationUrl := urlx.CopyWithQuery(
-		urlx.AppendPaths(s.r.Config().SelfServiceLinkMethodBaseURL(ctx), verification.RouteSubmitFlow),
-		url.Values{
-			"flow":  {f.ID.String()},
-			"token": {token.Token},
-		}).String()
+	verificationUrl := verificationLink(ctx, s.r.Config(), s.r.Config().SelfServiceLinkMethodBaseURL(ctx), f.ID, token.Token)
 
 	if err := s.send(ctx, string(address.Via), email.NewVerificationValid(s.r,
 		&email.VerificationValidModel{
@@ -4,6 +4,7 @@
 package link
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/url"
@@ -21,6 +22,7 @@ import (
 	"github.com/ory/x/sqlxx"
 	"github.com/ory/x/urlx"
 
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/selfservice/flow"
@@ -34,6 +36,7 @@ import (
 
 const (
 	RouteAdminCreateRecoveryLink = "/recovery/link"
+	RouteRecoveryOpaqueLink      = "/self-service/recovery/link"
 )
 
 func (s *Strategy) RecoveryStrategyID() string {
@@ -43,6 +46,7 @@ func (s *Strategy) RecoveryStrategyID() string {
 func (s *Strategy) RegisterPublicRecoveryRoutes(public *x.RouterPublic) {
 	s.d.CSRFHandler().IgnorePath(RouteAdminCreateRecoveryLink)
 	public.POST(RouteAdminCreateRecoveryLink, x.RedirectToAdminRoute(s.d))
+	public.GET(RouteRecoveryOpaqueLink, strategy.IsRecoveryDisabled(s.d, s.RecoveryStrategyID(), s.resolveOpaqueRecoveryLink))
 }
 
 func (s *Strategy) RegisterAdminRecoveryRoutes(admin *x.RouterAdmin) {
@@ -50,6 +54,58 @@ func (s *Strategy) RegisterAdminRecoveryRoutes(admin *x.RouterAdmin) {
 	admin.POST(RouteAdminCreateRecoveryLink, wrappedCreateRecoveryLink)
 }
 
+// resolveOpaqueRecoveryLink handles recovery links which only carry the
+// recovery token. The flow is looked up server-side using the token.
+func (s *Strategy) resolveOpaqueRecoveryLink(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	token := r.URL.Query().Get("token")
+
+	err := s.useOpaqueRecoveryToken(w, r, token)
+	if err != nil && !errors.Is(err, flow.ErrCompletedByStrategy) {
+		s.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
+	}
+}
+
+func (s *Strategy) useOpaqueRecoveryToken(w http.ResponseWriter, r *http.Request, token string) error {
+	if len(token) == 0 {
+		return s.retryRecoveryFlowWithMessage(w, r, flow.TypeBrowser, text.NewErrorValidationRecoveryTokenInvalidOrAlreadyUsed())
+	}
+
+	fID, err := s.d.RecoveryTokenPersister().FindRecoveryTokenFlowID(r.Context(), token)
+	if errors.Is(err, sqlcon.ErrNoRows) {
+		return s.retryRecoveryFlowWithMessage(w, r, flow.TypeBrowser, text.NewErrorValidationRecoveryTokenInvalidOrAlreadyUsed())
+	} else if err != nil {
+		return err
+	}
+
+	return s.recoveryUseToken(w, r, fID, &recoverySubmitPayload{Token: token, Flow: fID.String()})
+}
+
+// recoveryTokenLength returns the length of new recovery tokens. Opaque
+// tokens are shorter because they are the only thing in the link.
+func recoveryTokenLength(ctx context.Context, c *config.Config) int {
+	if c.SelfServiceFlowRecoveryUseOpaqueTokens(ctx) {
+		return OpaqueTokenLength
+	}
+	return TokenLength
+}
+
+// recoveryLink returns the link sent to or handed out for the given recovery
+// token. If opaque tokens are enabled, the flow ID is omitted from the link.
+func recoveryLink(ctx context.Context, c *config.Config, base *url.URL, flowID uuid.UUID, token string) string {
+	if c.SelfServiceFlowRecoveryUseOpaqueTokens(ctx) {
+		return urlx.CopyWithQuery(
+			urlx.AppendPaths(base, RouteRecoveryOpaqueLink),
+			url.Values{"token": {token}}).String()
+	}
+
+	return urlx.CopyWithQuery(
+		urlx.AppendPaths(base, recovery.RouteSubmitFlow),
+		url.Values{
+			"token": {token},
+			"flow":  {flowID.String()},
+		}).String()
+}
+
 func (s *Strategy) PopulateRecoveryMethod(r *http.Request, f *recovery.Flow) error {
 	f.UI.SetCSRF(s.d.GenerateCSRFToken(r))
 	f.UI.GetNodes().Upsert(
@@ -184,7 +240,7 @@ func (s *Strategy) createRecoveryLinkForIdentity(w http.ResponseWriter, r *http.
 		return
 	}
 
-	token := NewAdminRecoveryToken(id.ID, req.ID, expiresIn)
+	token := NewAdminRecoveryToken(id.ID, req.ID, expiresIn, recoveryTokenLength(r.Context(), s.d.Config()))
 	if err := s.d.RecoveryTokenPersister().CreateRecoveryToken(r.Context(), token); err != nil {
 		s.d.Writer().WriteError(w, r, err)
 		return
@@ -196,13 +252,8 @@ func (s *Strategy) createRecoveryLinkForIdentity(w http.ResponseWriter, r *http.
 		Info("A recovery link has been created.")
 
 	s.d.Writer().Write(w, r, &recoveryLinkForIdentity{
-		ExpiresAt: req.ExpiresAt.UTC(),
-		RecoveryLink: urlx.CopyWithQuery(
-			urlx.AppendPaths(s.d.Config().SelfPublicURL(r.Context()), recovery.RouteSubmitFlow),
-			url.Values{
-				"token": {token.Token},
-				"flow":  {req.ID.String()},
-			}).String(),
+		ExpiresAt:    req.ExpiresAt.UTC(),
+		RecoveryLink: recoveryLink(r.Context(), s.d.Config(), s.d.Config().SelfPublicURL(r.Context()), req.ID, token.Token),
 	},
 		herodot.UnescapedHTML)
 }
@@ -745,6 +745,56 @@ func TestRecovery(t *testing.T) {
 		assert.Equal(t, "The recovery token is invalid or has already been used. Please retry the flow.", rs.Ui.Messages[0].Text)
 	})
 
+	t.Run("description=should resolve opaque recovery links", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceRecoveryUseOpaqueTokens, true)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceRecoveryUseOpaqueTokens, nil)
+		})
+
+		requestLink := func(t *testing.T, email string) string {
+			createIdentityToRecover(t, reg, email)
+			expectSuccess(t, nil, false, false, func(v url.Values) {
+				v.Set("email", email)
+			})
+
+			message := testhelpers.CourierExpectMessage(ctx, t, reg, email, "Recover access to your account")
+			recoveryLink := testhelpers.CourierExpectLinkInMessage(t, message, 1)
+
+			assert.Contains(t, recoveryLink, public.URL+link.RouteRecoveryOpaqueLink)
+			assert.NotContains(t, recoveryLink, "flow=")
+			assert.Len(t, urlx.ParseOrPanic(recoveryLink).Query().Get("token"), link.OpaqueTokenLength)
+			return recoveryLink
+		}
+
+		t.Run("case=valid token", func(t *testing.T) {
+			recoveryLink := requestLink(t, "recover-opaque-1@ory.sh")
+
+			res, err := testhelpers.NewClientWithCookies(t).Get(recoveryLink)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Contains(t, res.Request.URL.String(), conf.SelfServiceFlowSettingsUI(ctx).String())
+
+			body := ioutilx.MustReadAll(res.Body)
+			assert.Equal(t, text.NewRecoverySuccessful(time.Now().Add(time.Hour)).Text,
+				gjson.GetBytes(body, "ui.messages.0.text").String(), "%s", body)
+		})
+
+		t.Run("case=tampered token", func(t *testing.T) {
+			recoveryLink := requestLink(t, "recover-opaque-2@ory.sh")
+
+			c := testhelpers.NewClientWithCookies(t)
+			res, err := c.Get(recoveryLink + "tampered")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Contains(t, res.Request.URL.String(), conf.SelfServiceFlowRecoveryUI(ctx).String()+"?flow=")
+
+			rs, _, err := testhelpers.NewSDKCustomClient(public, c).FrontendApi.GetRecoveryFlow(context.Background()).Id(res.Request.URL.Query().Get("flow")).Execute()
+			require.NoError(t, err)
+			require.Len(t, rs.Ui.Messages, 1)
+			assert.Equal(t, "The recovery token is invalid or has already been used. Please retry the flow.", rs.Ui.Messages[0].Text)
+		})
+	})
+
 	t.Run("description=should not be able to use an outdated link", func(t *testing.T) {
 		recoveryEmail := "recoverme5@ory.sh"
 		createIdentityToRecover(t, reg, recoveryEmail)
@@ -10,13 +10,17 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/gofrs/uuid"
+	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/verification"
+	"github.com/ory/kratos/selfservice/strategy"
 	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/ui/container"
 	"github.com/ory/kratos/ui/node"
@@ -28,16 +32,79 @@ import (
 	"github.com/ory/x/urlx"
 )
 
+const RouteVerificationOpaqueLink = "/self-service/verification/link"
+
 func (s *Strategy) VerificationStrategyID() string {
 	return string(verification.VerificationStrategyLink)
 }
 
 func (s *Strategy) RegisterPublicVerificationRoutes(public *x.RouterPublic) {
+	public.GET(RouteVerificationOpaqueLink, strategy.IsVerificationDisabled(s.d, s.VerificationStrategyID(), s.resolveOpaqueVerificationLink))
 }
 
 func (s *Strategy) RegisterAdminVerificationRoutes(admin *x.RouterAdmin) {
 }
 
+// resolveOpaqueVerificationLink handles verification links which only carry
+// the verification token. The flow is looked up server-side using the token
+// and then submitted as if the link had contained the flow ID.
+func (s *Strategy) resolveOpaqueVerificationLink(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	token := r.URL.Query().Get("token")
+
+	fID, err := s.findOpaqueVerificationTokenFlowID(w, r, token)
+	if errors.Is(err, flow.ErrCompletedByStrategy) {
+		return
+	} else if err != nil {
+		s.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
+		return
+	}
+
+	r = r.Clone(r.Context())
+	r.URL.RawQuery = url.Values{"flow": {fID.String()}, "token": {token}}.Encode()
+	s.d.VerificationHandler().SubmitFlow(w, r)
+}
+
+func (s *Strategy) findOpaqueVerificationTokenFlowID(w http.ResponseWriter, r *http.Request, token string) (uuid.UUID, error) {
+	if len(token) == 0 {
+		return uuid.Nil, s.retryVerificationFlowWithMessage(w, r, flow.TypeBrowser, text.NewErrorValidationVerificationTokenInvalidOrAlreadyUsed())
+	}
+
+	fID, err := s.d.VerificationTokenPersister().FindVerificationTokenFlowID(r.Context(), token)
+	if errors.Is(err, sqlcon.ErrNoRows) {
+		return uuid.Nil, s.retryVerificationFlowWithMessage(w, r, flow.TypeBrowser, text.NewErrorValidationVerificationTokenInvalidOrAlreadyUsed())
+	} else if err != nil {
+		return uuid.Nil, err
+	}
+
+	return fID, nil
+}
+
+// verificationTokenLength returns the length of new verification tokens.
+// Opaque tokens are shorter because they are the only thing in the link.
+func verificationTokenLength(ctx context.Context, c *config.Config) int {
+	if c.SelfServiceFlowVerificationUseOpaqueTokens(ctx) {
+		return OpaqueTokenLength
+	}
+	return TokenLength
+}
+
+// verificationLink returns the link sent for the given verification token. If
+// opaque tokens are enabled, the flow ID is omitted from the link.
+func verificationLink(ctx context.Context, c *config.Config, base *url.URL, flowID uuid.UUID, token string) string {
+	if c.SelfServiceFlowVerificationUseOpaqueTokens(ctx) {
+		return urlx.CopyWithQuery(
+			urlx.AppendPaths(base, RouteVerificationOpaqueLink),
+			url.Values{"token": {token}}).String()
+	}
+
+	return urlx.CopyWithQuery(
+		urlx.AppendPaths(base, verification.RouteSubmitFlow),
+		url.Values{
+			"flow":  {flowID.String()},
+			"token": {token},
+		}).String()
+}
+
 func (s *Strategy) PopulateVerificationMethod(r *http.Request, f *verification.Flow) error {
 	f.UI.SetCSRF(s.d.GenerateCSRFToken(r))
 	f.UI.GetNodes().Upsert(
@@ -318,7 +385,7 @@ func (s *Strategy) retryVerificationFlowWithError(w http.ResponseWriter, r *http
 }
 
 func (s *Strategy) SendVerificationEmail(ctx context.Context, f *verification.Flow, i *identity.Identity, a *identity.VerifiableAddress) error {
-	token := NewSelfServiceVerificationToken(a, f, s.d.Config().SelfServiceLinkMethodLifespan(ctx))
+	token := NewSelfServiceVerificationToken(a, f, s.d.Config().SelfServiceLinkMethodLifespan(ctx), verificationTokenLength(ctx, s.d.Config()))
 	if err := s.d.VerificationTokenPersister().CreateVerificationToken(ctx, token); err != nil {
 		return err
 	}
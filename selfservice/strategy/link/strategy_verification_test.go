@@ -374,7 +374,7 @@ func TestVerification(t *testing.T) {
 		identityToVerify.VerifiableAddresses = append(identityToVerify.VerifiableAddresses, *email)
 		require.NoError(t, reg.IdentityManager().Update(context.Background(), identityToVerify, identity.ManagerAllowWriteProtectedTraits))
 
-		token := link.NewSelfServiceVerificationToken(&identityToVerify.VerifiableAddresses[0], f, time.Hour)
+		token := link.NewSelfServiceVerificationToken(&identityToVerify.VerifiableAddresses[0], f, time.Hour, link.TokenLength)
 		require.NoError(t, reg.VerificationTokenPersister().CreateVerificationToken(context.Background(), token))
 		return f, token
 	}
@@ -383,6 +383,55 @@ func TestVerification(t *testing.T) {
 		return newValidFlow(t, flow.TypeBrowser, requestURL)
 	}
 
+	t.Run("description=should resolve opaque verification links", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceVerificationUseOpaqueTokens, true)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceVerificationUseOpaqueTokens, nil)
+		})
+
+		requestLink := func(t *testing.T) string {
+			expectSuccess(t, nil, false, false, func(v url.Values) {
+				v.Set("email", verificationEmail)
+			})
+
+			message := testhelpers.CourierExpectMessage(ctx, t, reg, verificationEmail, "Please verify your email address")
+			verificationLink := testhelpers.CourierExpectLinkInMessage(t, message, 1)
+
+			assert.Contains(t, verificationLink, public.URL+link.RouteVerificationOpaqueLink)
+			assert.NotContains(t, verificationLink, "flow=")
+			assert.Len(t, urlx.ParseOrPanic(verificationLink).Query().Get("token"), link.OpaqueTokenLength)
+			return verificationLink
+		}
+
+		t.Run("case=valid token", func(t *testing.T) {
+			verificationLink := requestLink(t)
+
+			res, err := testhelpers.NewClientWithCookies(t).Get(verificationLink)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Contains(t, res.Request.URL.String(), conf.SelfServiceFlowVerificationUI(ctx).String())
+
+			body := string(ioutilx.MustReadAll(res.Body))
+			assert.EqualValues(t, "passed_challenge", gjson.Get(body, "state").String(), "%s", body)
+			assert.EqualValues(t, text.NewInfoSelfServiceVerificationSuccessful().Text, gjson.Get(body, "ui.messages.0.text").String(), "%s", body)
+		})
+
+		t.Run("case=tampered token", func(t *testing.T) {
+			verificationLink := requestLink(t)
+
+			c := testhelpers.NewClientWithCookies(t)
+			res, err := c.Get(verificationLink + "tampered")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Contains(t, res.Request.URL.String(), conf.SelfServiceFlowVerificationUI(ctx).String()+"?flow=")
+
+			sr, _, err := testhelpers.NewSDKCustomClient(public, c).FrontendApi.GetVerificationFlow(context.Background()).Id(res.Request.URL.Query().Get("flow")).Execute()
+			require.NoError(t, err)
+			require.Len(t, sr.Ui.Messages, 1)
+			assert.Equal(t, "The verification token is invalid or has already been used. Please retry the flow.", sr.Ui.Messages[0].Text)
+		})
+	})
+
 	t.Run("case=respects return_to URI parameter", func(t *testing.T) {
 		returnToURL := public.URL + "/after-verification"
 		conf.MustSet(ctx, config.ViperKeyURLsAllowedReturnToDomains, []string{returnToURL})
@@ -82,6 +82,24 @@ func TestPersister(ctx context.Context, p interface {
 				require.Error(t, err)
 			})
 
+			t.Run("case=should find the flow of a recovery token", func(t *testing.T) {
+				expected, f := newRecoveryToken(t, "opaque-user@ory.sh")
+				require.NoError(t, p.CreateRecoveryToken(ctx, expected))
+
+				t.Run("not work on another network", func(t *testing.T) {
+					_, p := testhelpers.NewNetwork(t, ctx, p)
+					_, err := p.FindRecoveryTokenFlowID(ctx, expected.Token)
+					require.ErrorIs(t, err, sqlcon.ErrNoRows)
+				})
+
+				_, err := p.FindRecoveryTokenFlowID(ctx, expected.Token+"tampered")
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+				actual, err := p.FindRecoveryTokenFlowID(ctx, expected.Token)
+				require.NoError(t, err)
+				assert.Equal(t, f.ID, actual)
+			})
+
 			t.Run("case=should create a recovery token and use it", func(t *testing.T) {
 				expected, f := newRecoveryToken(t, "other-user@ory.sh")
 				require.NoError(t, p.CreateRecoveryToken(ctx, expected))
@@ -167,6 +185,24 @@ func TestPersister(ctx context.Context, p interface {
 				require.NoError(t, p.CreateVerificationToken(ctx, token))
 			})
 
+			t.Run("case=should find the flow of a verification token", func(t *testing.T) {
+				f, expected := newVerificationToken(t, "opaque-user@ory.sh")
+				require.NoError(t, p.CreateVerificationToken(ctx, expected))
+
+				t.Run("not work on another network", func(t *testing.T) {
+					_, p := testhelpers.NewNetwork(t, ctx, p)
+					_, err := p.FindVerificationTokenFlowID(ctx, expected.Token)
+					require.ErrorIs(t, err, sqlcon.ErrNoRows)
+				})
+
+				_, err := p.FindVerificationTokenFlowID(ctx, expected.Token+"tampered")
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+				actual, err := p.FindVerificationTokenFlowID(ctx, expected.Token)
+				require.NoError(t, err)
+				assert.Equal(t, f.ID, actual)
+			})
+
 			t.Run("case=should create a verification token and use it", func(t *testing.T) {
 				f, expected := newVerificationToken(t, "other-user@ory.sh")
 				require.NoError(t, p.CreateVerificationToken(ctx, expected))
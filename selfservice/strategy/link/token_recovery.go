@@ -19,6 +19,15 @@ import (
 	"github.com/ory/kratos/x"
 )
 
+const (
+	// TokenLength is the length of tokens in links which also carry the flow ID.
+	TokenLength = 32
+
+	// OpaqueTokenLength is the length of tokens in opaque links. These links
+	// only carry the token, which is resolved to its flow server-side.
+	OpaqueTokenLength = 16
+)
+
 type RecoveryTokenType int
 
 const (
@@ -67,7 +76,7 @@ func (RecoveryToken) TableName(ctx context.Context) string {
 	return "identity_recovery_tokens"
 }
 
-func NewSelfServiceRecoveryToken(address *identity.RecoveryAddress, f *recovery.Flow, expiresIn time.Duration) *RecoveryToken {
+func NewSelfServiceRecoveryToken(address *identity.RecoveryAddress, f *recovery.Flow, expiresIn time.Duration, tokenLength int) *RecoveryToken {
 	now := time.Now().UTC()
 	var identityID = uuid.UUID{}
 	var recoveryAddressID = uuid.UUID{}
@@ -77,7 +86,7 @@ func NewSelfServiceRecoveryToken(address *identity.RecoveryAddress, f *recovery.
 	}
 	return &RecoveryToken{
 		ID:                x.NewUUID(),
-		Token:             randx.MustString(32, randx.AlphaNum),
+		Token:             randx.MustString(tokenLength, randx.AlphaNum),
 		RecoveryAddress:   address,
 		ExpiresAt:         now.Add(expiresIn),
 		IssuedAt:          now,
@@ -88,11 +97,11 @@ func NewSelfServiceRecoveryToken(address *identity.RecoveryAddress, f *recovery.
 	}
 }
 
-func NewAdminRecoveryToken(identityID uuid.UUID, fID uuid.UUID, expiresIn time.Duration) *RecoveryToken {
+func NewAdminRecoveryToken(identityID uuid.UUID, fID uuid.UUID, expiresIn time.Duration, tokenLength int) *RecoveryToken {
 	now := time.Now().UTC()
 	return &RecoveryToken{
 		ID:         x.NewUUID(),
-		Token:      randx.MustString(32, randx.AlphaNum),
+		Token:      randx.MustString(tokenLength, randx.AlphaNum),
 		ExpiresAt:  now.Add(expiresIn),
 		IssuedAt:   now,
 		IdentityID: identityID,
@@ -55,11 +55,11 @@ func (VerificationToken) TableName(ctx context.Context) string {
 	return "identity_verification_tokens"
 }
 
-func NewSelfServiceVerificationToken(address *identity.VerifiableAddress, f *verification.Flow, expiresIn time.Duration) *VerificationToken {
+func NewSelfServiceVerificationToken(address *identity.VerifiableAddress, f *verification.Flow, expiresIn time.Duration, tokenLength int) *VerificationToken {
 	now := time.Now().UTC()
 	return &VerificationToken{
 		ID:                x.NewUUID(),
-		Token:             randx.MustString(32, randx.AlphaNum),
+		Token:             randx.MustString(tokenLength, randx.AlphaNum),
 		VerifiableAddress: address,
 		ExpiresAt:         now.Add(expiresIn),
 		IssuedAt:          now,
@@ -32,7 +32,7 @@ func TestVerificationToken(t *testing.T) {
 
 			tokens := make([]string, 10)
 			for k := range tokens {
-				tokens[k] = link.NewSelfServiceVerificationToken(nil, f, time.Hour).Token
+				tokens[k] = link.NewSelfServiceVerificationToken(nil, f, time.Hour, link.TokenLength).Token
 			}
 
 			assert.Len(t, stringslice.Unique(tokens), len(tokens))
@@ -43,7 +43,7 @@ func TestVerificationToken(t *testing.T) {
 			f, err := verification.NewFlow(conf, -time.Hour, "", req, nil, flow.TypeBrowser)
 			require.NoError(t, err)
 
-			token := link.NewSelfServiceVerificationToken(nil, f, -time.Hour)
+			token := link.NewSelfServiceVerificationToken(nil, f, -time.Hour, link.TokenLength)
 			require.Error(t, token.Valid())
 			assert.EqualError(t, token.Valid(), f.Valid().Error())
 		})
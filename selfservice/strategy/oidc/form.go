@@ -13,19 +13,20 @@ import (
 )
 
 // merge merges the userFormValues (extracted from the initial POST request) prefixed with `traits` (encoded) with the
-// values coming from the OpenID Provider (openIDProviderValues).
-func merge(containerTraits json.RawMessage, openIDProviderValues json.RawMessage) (identity.Traits, error) {
+// values coming from the OpenID Provider (openIDProviderValues). If preserveNumberPrecision is set, numbers are
+// kept verbatim instead of being converted to float64.
+func merge(containerTraits json.RawMessage, openIDProviderValues json.RawMessage, preserveNumberPrecision bool) (identity.Traits, error) {
 	if len(containerTraits) == 0 || string(containerTraits) == "{}" {
 		return identity.Traits(openIDProviderValues), nil
 	}
 
 	var pt map[string]interface{}
-	if err := json.NewDecoder(bytes.NewBuffer(openIDProviderValues)).Decode(&pt); err != nil {
+	if err := identity.Traits(openIDProviderValues).Decode(&pt, preserveNumberPrecision); err != nil {
 		return nil, err
 	}
 
 	var ct map[string]interface{}
-	if err := json.NewDecoder(bytes.NewBuffer(containerTraits)).Decode(&ct); err != nil {
+	if err := identity.Traits(containerTraits).Decode(&ct, preserveNumberPrecision); err != nil {
 		return nil, err
 	}
 
@@ -14,10 +14,11 @@ import (
 
 func TestMerge(t *testing.T) {
 	for k, tc := range []struct {
-		schema string
-		form   json.RawMessage
-		op     json.RawMessage
-		expect json.RawMessage
+		schema   string
+		form     json.RawMessage
+		op       json.RawMessage
+		expect   json.RawMessage
+		preserve bool
 	}{
 		{
 			form:   json.RawMessage("{}"),
@@ -39,9 +40,20 @@ func TestMerge(t *testing.T) {
 			op:     json.RawMessage(`{"foo":"bar","baz":"bar","opv":"bla"}`),
 			expect: json.RawMessage(`{"foo":"bar","baz":"bar","bool":true,"opv":"blubb"}`),
 		},
+		{
+			form:     json.RawMessage(`{"opv": "blubb"}`),
+			op:       json.RawMessage(`{"external_id":9007199254740993,"opv":"bla"}`),
+			expect:   json.RawMessage(`{"external_id":9007199254740993,"opv":"blubb"}`),
+			preserve: true,
+		},
+		{
+			form:   json.RawMessage(`{"opv": "blubb"}`),
+			op:     json.RawMessage(`{"external_id":9007199254740993,"opv":"bla"}`),
+			expect: json.RawMessage(`{"external_id":9007199254740992,"opv":"blubb"}`),
+		},
 	} {
 		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
-			got, err := merge(tc.form, tc.op)
+			got, err := merge(tc.form, tc.op, tc.preserve)
 			require.NoError(t, err)
 			assert.JSONEq(t, string(tc.expect), string(got))
 		})
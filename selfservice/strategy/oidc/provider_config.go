@@ -134,8 +134,35 @@ func (p Configuration) Redir(public *url.URL) string {
 type ConfigurationCollection struct {
 	BaseRedirectURI string          `json:"base_redirect_uri"`
 	Providers       []Configuration `json:"providers"`
+
+	// PreserveParams is a list of query parameters which are carried over from
+	// the initial flow request to the flow's return_to URL, or the default return
+	// URL if the flow has none, after the user returns from the OpenID Connect
+	// provider. All other parameters are dropped.
+	PreserveParams []string `json:"preserve_params,omitempty"`
+
+	// MaxProviders is the maximum number of providers allowed. It is enforced
+	// when the configuration is loaded and whenever it is read, so that it also
+	// applies after the configuration was reloaded.
+	MaxProviders int `json:"max_providers,omitempty"`
+
+	// OnExistingAccount controls what happens if the identity created from the
+	// provider's claims conflicts with an existing identity, for example because
+	// the email address is already used. Defaults to `link_after_verification`.
+	OnExistingAccount string `json:"on_existing_account,omitempty"`
 }
 
+const (
+	// OnExistingAccountError shows a duplicate credentials error.
+	OnExistingAccountError = "error"
+	// OnExistingAccountLinkAfterVerification asks the user to sign in to the
+	// existing account first and links the provider afterward.
+	OnExistingAccountLinkAfterVerification = "link_after_verification"
+	// OnExistingAccountCreateSeparate creates a new identity without the
+	// identifiers and addresses which are already in use.
+	OnExistingAccountCreateSeparate = "create_separate"
+)
+
 // !!! WARNING !!!
 //
 // If you add a provider here, please also add a test to
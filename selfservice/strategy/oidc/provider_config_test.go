@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ory/herodot"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/internal"
@@ -34,3 +35,26 @@ func TestConfig(t *testing.T) {
 	require.Len(t, collection.Providers, 1)
 	assert.Equal(t, "generic", collection.Providers[0].Provider)
 }
+
+func TestConfigMaxProviders(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	s := oidc.NewStrategy(reg)
+
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+string(identity.CredentialsTypeOIDC), map[string]interface{}{
+		"config": map[string]interface{}{
+			"max_providers": 1,
+			"providers":     []map[string]interface{}{{"id": "a", "provider": "generic"}, {"id": "b", "provider": "generic"}},
+		},
+	})
+
+	_, err := s.Config(ctx)
+	var he *herodot.DefaultError
+	require.ErrorAs(t, err, &he)
+	assert.Contains(t, he.Reason(), "contains 2 providers, but at most 1 are allowed")
+
+	conf.MustSet(ctx, config.ViperKeyOIDCMaxProviders, 2)
+	collection, err := s.Config(ctx)
+	require.NoError(t, err)
+	assert.Len(t, collection.Providers, 2)
+}
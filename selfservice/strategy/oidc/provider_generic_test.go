@@ -61,7 +61,7 @@ func TestProviderGenericOIDC_AddAuthCodeURLOptions(t *testing.T) {
 		assert.Contains(t, actual.Query().Get("redirect_uri"), "https://ory.sh")
 	})
 
-	t.Run("case=redirectURI is public base url", func(t *testing.T) {
+	t.Run("case=redirectURI is base redirect uri", func(t *testing.T) {
 		conf.MustSet(ctx, config.ViperKeyOIDCBaseRedirectURL, "https://example.org")
 		t.Cleanup(func() {
 			conf.MustSet(ctx, config.ViperKeyOIDCBaseRedirectURL, nil)
@@ -72,6 +72,17 @@ func TestProviderGenericOIDC_AddAuthCodeURLOptions(t *testing.T) {
 		assert.Contains(t, actual.Query().Get("redirect_uri"), "https://example.org")
 	})
 
+	t.Run("case=redirectURI keeps the path of the base redirect uri", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyOIDCBaseRedirectURL, "https://example.org/identity/")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyOIDCBaseRedirectURL, nil)
+		})
+		r := &login.Flow{ID: x.NewUUID(), Refresh: true}
+		actual, err := url.ParseRequestURI(makeAuthCodeURL(t, r, reg))
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.org/identity/self-service/methods/oidc/callback/valid", actual.Query().Get("redirect_uri"))
+	})
+
 	t.Run("case=expect prompt to be login with forced flag", func(t *testing.T) {
 		r := &login.Flow{
 			ID:      x.NewUUID(),
@@ -109,6 +109,7 @@ type Dependencies interface {
 	settings.ErrorHandlerProvider
 	settings.FlowPersistenceProvider
 	settings.HookExecutorProvider
+	settings.HandlerProvider
 
 	continuity.ManagementProvider
 
@@ -137,6 +138,7 @@ type AuthCodeContainer struct {
 	State            string          `json:"state"`
 	Traits           json.RawMessage `json:"traits"`
 	TransientPayload json.RawMessage `json:"transient_payload"`
+	PreservedParams  url.Values      `json:"preserved_params,omitempty"`
 }
 
 type State struct {
@@ -220,7 +222,7 @@ func (s *Strategy) setRoutes(r *x.RouterPublic) {
 		// When handler is called using POST method, the cookies are not attached to the request
 		// by the browser. So here we just redirect the request to the same location rewriting the
 		// form fields to query params. This second GET request should have the cookies attached.
-		r.POST(RouteCallback, s.redirectToGET)
+		r.POST(RouteCallback, strategy.IsDisabled(s.d, s.ID().String(), s.redirectToGET))
 	}
 }
 
@@ -466,6 +468,7 @@ func (s *Strategy) HandleCallback(w http.ResponseWriter, r *http.Request, ps htt
 	switch a := req.(type) {
 	case *login.Flow:
 		a.TransientPayload = cntnr.TransientPayload
+		a.ReturnTo = withPreservedParams(a.ReturnTo, a.RequestURL, s.d.Config().SelfServiceFlowLoginReturnTo(ctx, s.ID().String()), cntnr.PreservedParams)
 		if ff, err := s.processLogin(w, r, a, et, claims, provider, cntnr); err != nil {
 			if errors.Is(err, flow.ErrCompletedByStrategy) {
 				return
@@ -479,6 +482,7 @@ func (s *Strategy) HandleCallback(w http.ResponseWriter, r *http.Request, ps htt
 		return
 	case *registration.Flow:
 		a.TransientPayload = cntnr.TransientPayload
+		a.ReturnTo = withPreservedParams(a.ReturnTo, a.RequestURL, s.d.Config().SelfServiceFlowRegistrationReturnTo(ctx, s.ID().String()), cntnr.PreservedParams)
 		if ff, err := s.processRegistration(w, r, a, et, claims, provider, cntnr, ""); err != nil {
 			if ff != nil {
 				s.forwardError(w, r, ff, err)
@@ -574,7 +578,7 @@ func (s *Strategy) populateMethod(r *http.Request, f flow.Flow, message func(pro
 }
 
 func (s *Strategy) Config(ctx context.Context) (*ConfigurationCollection, error) {
-	var c ConfigurationCollection
+	c := ConfigurationCollection{MaxProviders: s.d.Config().OIDCMaxProviders(ctx)}
 
 	conf := s.d.Config().SelfServiceStrategy(ctx, string(s.ID())).Config
 	if err := jsonx.
@@ -584,9 +588,84 @@ func (s *Strategy) Config(ctx context.Context) (*ConfigurationCollection, error)
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to decode OpenID Connect Provider configuration: %s", err))
 	}
 
+	// The limit is checked on every read so that it also applies to reloaded configurations.
+	if len(c.Providers) > c.MaxProviders {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("The OpenID Connect configuration contains %d providers, but at most %d are allowed.", len(c.Providers), c.MaxProviders))
+	}
+
 	return &c, nil
 }
 
+// onExistingAccount returns the configured behavior for registrations which
+// conflict with an existing identity.
+func (s *Strategy) onExistingAccount(ctx context.Context) string {
+	c, err := s.Config(ctx)
+	if err != nil || c.OnExistingAccount == "" {
+		return OnExistingAccountLinkAfterVerification
+	}
+	return c.OnExistingAccount
+}
+
+// preservedParams returns the query parameters of the flow's request URL which
+// are allowlisted in the `preserve_params` configuration.
+func (s *Strategy) preservedParams(ctx context.Context, requestURL string) url.Values {
+	c, err := s.Config(ctx)
+	if err != nil || len(c.PreserveParams) == 0 {
+		return nil
+	}
+
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return nil
+	}
+
+	query := u.Query()
+	preserved := url.Values{}
+	for _, key := range c.PreserveParams {
+		if values, ok := query[key]; ok {
+			preserved[key] = values
+		}
+	}
+
+	return preserved
+}
+
+// withPreservedParams appends the preserved query parameters to the URL the flow
+// returns to. Like the post-flow hooks, it falls back to the return_to parameter of
+// the flow's request URL and then to the default return URL if the flow has no
+// return_to URL. The return_to URL is returned unchanged if it can not be parsed.
+func withPreservedParams(returnTo, requestURL string, defaultReturnTo *url.URL, params url.Values) string {
+	if len(params) == 0 {
+		return returnTo
+	}
+
+	original := returnTo
+	if returnTo == "" {
+		if u, err := url.Parse(requestURL); err == nil {
+			returnTo = u.Query().Get("return_to")
+		}
+	}
+	if returnTo == "" && defaultReturnTo != nil {
+		returnTo = defaultReturnTo.String()
+	}
+	if returnTo == "" {
+		return original
+	}
+
+	u, err := url.Parse(returnTo)
+	if err != nil {
+		return original
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
 func (s *Strategy) provider(ctx context.Context, r *http.Request, id string) (Provider, error) {
 	if c, err := s.Config(ctx); err != nil {
 		return nil, err
@@ -622,7 +701,10 @@ func (s *Strategy) handleError(w http.ResponseWriter, r *http.Request, f flow.Fl
 		// Reset all nodes to not confuse users.
 		// This is kinda hacky and will probably need to be updated at some point.
 
-		if dup := new(identity.ErrDuplicateCredentials); errors.As(err, &dup) {
+		if dup := new(identity.ErrDuplicateCredentials); errors.As(err, &dup) && s.onExistingAccount(r.Context()) == OnExistingAccountError {
+			// Do not offer to sign in to the existing account and link the credentials.
+			err = schema.NewDuplicateCredentialsError(dup)
+		} else if errors.As(err, &dup) {
 			err = schema.NewDuplicateCredentialsError(dup)
 
 			if validationErr := new(schema.ValidationError); errors.As(err, &validationErr) {
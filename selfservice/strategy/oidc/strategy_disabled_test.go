@@ -0,0 +1,125 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/driver/config"
+	confighelpers "github.com/ory/kratos/driver/config/testhelpers"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/strategy"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/configx"
+)
+
+func TestStrategyDisabledViaContext(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t, configx.WithValues(map[string]any{
+		config.ViperKeySelfServiceStrategyConfig + ".oidc": map[string]any{
+			"enabled": true,
+			"config": map[string]any{
+				"providers": []map[string]any{{
+					"id":            "valid",
+					"provider":      "generic",
+					"client_id":     "client",
+					"client_secret": "secret",
+					"issuer_url":    "https://example.com",
+					"mapper_url":    "file://./stub/oidc.hydra.jsonnet",
+				}},
+			},
+		},
+	}))
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/registration.schema.json")
+
+	router := x.NewRouterPublic()
+	ts := confighelpers.NewConfigurableTestServer(router)
+	t.Cleanup(ts.Close)
+	conf.MustSet(ctx, config.ViperKeyPublicBaseURL, ts.URL)
+	reg.RegisterRoutes(ctx, router, x.NewRouterAdmin())
+
+	// The context-scoped config does not inherit values set on conf, which is why the
+	// identity schema and base URL are repeated here.
+	disabled := confighelpers.WithConfigValues(ctx, map[string]any{
+		config.ViperKeyDefaultIdentitySchemaID:                     "default",
+		config.ViperKeyIdentitySchemas:                             config.Schemas{{ID: "default", URL: "file://./stub/registration.schema.json"}},
+		config.ViperKeyPublicBaseURL:                               ts.URL,
+		config.ViperKeySelfServiceStrategyConfig + ".oidc.enabled": false,
+	})
+
+	assertDisabled := func(t *testing.T, res *http.Response) {
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode, "%s", body)
+		assert.Contains(t, string(body), strategy.EndpointDisabledMessage, "%s", body)
+	}
+
+	hasOIDC := func(ids []string) bool {
+		for _, id := range ids {
+			if id == identity.CredentialsTypeOIDC.String() {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("case=strategy is only disabled for the scoped context", func(t *testing.T) {
+		var enabledIDs, disabledIDs []string
+		for _, s := range reg.LoginStrategies(ctx) {
+			enabledIDs = append(enabledIDs, s.ID().String())
+		}
+		for _, s := range reg.LoginStrategies(disabled) {
+			disabledIDs = append(disabledIDs, s.ID().String())
+		}
+		assert.True(t, hasOIDC(enabledIDs), "%v", enabledIDs)
+		assert.False(t, hasOIDC(disabledIDs), "%v", disabledIDs)
+
+		enabledIDs, disabledIDs = nil, nil
+		for _, s := range reg.RegistrationStrategies(ctx) {
+			enabledIDs = append(enabledIDs, s.ID().String())
+		}
+		for _, s := range reg.RegistrationStrategies(disabled) {
+			disabledIDs = append(disabledIDs, s.ID().String())
+		}
+		assert.True(t, hasOIDC(enabledIDs), "%v", enabledIDs)
+		assert.False(t, hasOIDC(disabledIDs), "%v", disabledIDs)
+	})
+
+	t.Run("case=callback route is disabled", func(t *testing.T) {
+		res, err := ts.Client(disabled).Get(ts.URL + oidc.RouteBase + "/callback/valid")
+		require.NoError(t, err)
+		assertDisabled(t, res)
+
+		res, err = ts.Client(disabled).PostForm(ts.URL+oidc.RouteBase+"/callback/valid", url.Values{"code": {"foo"}})
+		require.NoError(t, err)
+		assertDisabled(t, res)
+	})
+
+	t.Run("case=login is disabled", func(t *testing.T) {
+		f := testhelpers.InitializeLoginFlowViaAPI(t, ts.Client(ctx).Client, ts.Server, false)
+
+		res, err := ts.Client(disabled).PostForm(f.Ui.Action, url.Values{"method": {"oidc"}, "provider": {"valid"}})
+		require.NoError(t, err)
+		assertDisabled(t, res)
+	})
+
+	t.Run("case=registration is disabled", func(t *testing.T) {
+		f := testhelpers.InitializeRegistrationFlowViaAPI(t, ts.Client(ctx).Client, ts.Server)
+
+		res, err := ts.Client(disabled).PostForm(f.Ui.Action, url.Values{"method": {"oidc"}, "provider": {"valid"}})
+		require.NoError(t, err)
+		assertDisabled(t, res)
+	})
+}
@@ -0,0 +1,237 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/selfservice/strategy"
+	"github.com/ory/kratos/x"
+	"github.com/ory/nosurf"
+)
+
+const (
+	RouteLinks = RouteBase + "/links"
+	RouteLink  = RouteLinks + "/:provider"
+)
+
+func (s *Strategy) setLinkRoutes(r *x.RouterPublic) {
+	if handle, _, _ := r.Lookup("GET", RouteLinks); handle == nil {
+		// API clients authenticate with the session token and can not send an anti-CSRF token,
+		// which is why revokeMyOidcLink checks the anti-CSRF token of browser requests itself.
+		s.d.CSRFHandler().IgnorePath(RouteLinks)
+		s.d.CSRFHandler().IgnoreGlob(RouteLinks + "/*")
+
+		r.GET(RouteLinks, strategy.IsDisabled(s.d, s.ID().String(), s.listMyOidcLinks))
+		r.DELETE(RouteLink, strategy.IsDisabled(s.d, s.ID().String(), s.revokeMyOidcLink))
+	}
+}
+
+// A linked OpenID Connect provider
+//
+// swagger:model oidcLink
+type oidcLink struct {
+	// The ID of the linked provider.
+	//
+	// required: true
+	Provider string `json:"provider"`
+
+	// The subject of the identity at the linked provider.
+	//
+	// required: true
+	Subject string `json:"subject"`
+}
+
+// List of linked OpenID Connect providers
+//
+// swagger:response listMyOidcLinks
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type listMyOidcLinksResponse struct {
+	// in: body
+	Body []oidcLink
+}
+
+// List My OpenID Connect Links Parameters
+//
+// swagger:parameters listMyOidcLinks
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type listMyOidcLinks struct {
+	// Set the Session Token when calling from non-browser clients. A session token has a format of `MP2YWEMeM8MxjkGKpH4dqOQ4Q4DlSPaj`.
+	//
+	// in: header
+	SessionToken string `json:"X-Session-Token"`
+
+	// Set the Cookie Header. This is especially useful when calling this endpoint from a server-side application. In that
+	// scenario you must include the HTTP Cookie Header which originally was included in the request to your server.
+	//
+	// in: header
+	Cookie string `json:"Cookie"`
+}
+
+// swagger:route GET /self-service/methods/oidc/links frontend listMyOidcLinks
+//
+// # List my linked OpenID Connect providers
+//
+// This endpoint returns the OpenID Connect providers linked to the identity of the current session.
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: listMyOidcLinks
+//	  401: errorGeneric
+//	  default: errorGeneric
+func (s *Strategy) listMyOidcLinks(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	sess, err := s.d.SessionManager().FetchFromRequest(r.Context(), r)
+	if err != nil {
+		s.d.Audit().WithRequest(r).WithError(err).Info("No valid session cookie found.")
+		s.d.Writer().WriteError(w, r, herodot.ErrUnauthorized.WithWrap(err).WithReasonf("No valid session cookie found."))
+		return
+	}
+
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), sess.IdentityID)
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	conf, err := s.Config(r.Context())
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	linked, err := s.linkedProviders(r.Context(), r, conf, i)
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	links := make([]oidcLink, 0, len(linked))
+	if creds, ok := i.GetCredentials(s.ID()); ok {
+		var cc identity.CredentialsOIDC
+		if err := json.Unmarshal(creds.Config, &cc); err != nil {
+			s.d.Writer().WriteError(w, r, errors.WithStack(err))
+			return
+		}
+
+		for _, p := range cc.Providers {
+			for _, l := range linked {
+				if l.Config().ID == p.Provider {
+					links = append(links, oidcLink{Provider: p.Provider, Subject: p.Subject})
+					break
+				}
+			}
+		}
+	}
+
+	s.d.Writer().Write(w, r, links)
+}
+
+// Revoke My OpenID Connect Link Parameters
+//
+// swagger:parameters revokeMyOidcLink
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type revokeMyOidcLink struct {
+	// The ID of the linked provider.
+	//
+	// required: true
+	// in: path
+	Provider string `json:"provider"`
+
+	// Set the Session Token when calling from non-browser clients. A session token has a format of `MP2YWEMeM8MxjkGKpH4dqOQ4Q4DlSPaj`.
+	//
+	// in: header
+	SessionToken string `json:"X-Session-Token"`
+
+	// Set the Cookie Header. This is especially useful when calling this endpoint from a server-side application. In that
+	// scenario you must include the HTTP Cookie Header which originally was included in the request to your server.
+	//
+	// in: header
+	Cookie string `json:"Cookie"`
+
+	// The anti-CSRF token. Required when calling this endpoint with the session cookie from a browser.
+	//
+	// in: header
+	CSRFToken string `json:"X-CSRF-Token"`
+}
+
+// swagger:route DELETE /self-service/methods/oidc/links/{provider} frontend revokeMyOidcLink
+//
+// # Revoke one of my linked OpenID Connect providers
+//
+// Calling this endpoint unlinks the provider from the identity of the current session. The link is revoked
+// in a new settings flow, which means that the session must satisfy the AAL required by the settings flow,
+// that the settings hooks are executed, and that a session which is no longer privileged has to re-authenticate
+// first. The link can not be revoked if it is the last remaining first factor credential.
+//
+// Browsers must send the anti-CSRF token in the `X-CSRF-Token` header. API clients must authenticate with
+// the `X-Session-Token` header and must not send cookies.
+//
+// The response is the completed settings flow. Browsers which do not send `Accept: application/json` are
+// redirected like they are when updating a settings flow.
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: settingsFlow
+//	  303: emptyResponse
+//	  400: settingsFlow
+//	  401: errorGeneric
+//	  403: errorGeneric
+//	  default: errorGeneric
+func (s *Strategy) revokeMyOidcLink(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	sess, err := s.d.SessionManager().FetchFromRequest(r.Context(), r)
+	if err != nil {
+		s.d.Audit().WithRequest(r).WithError(err).Info("No valid session cookie found.")
+		s.d.Writer().WriteError(w, r, herodot.ErrUnauthorized.WithWrap(err).WithReasonf("No valid session cookie found."))
+		return
+	}
+
+	flowType := flow.TypeBrowser
+	if r.Header.Get("X-Session-Token") != "" {
+		flowType = flow.TypeAPI
+	}
+	if err := flow.EnsureCSRF(s.d, r, flowType, false, s.d.GenerateCSRFToken, r.Header.Get(nosurf.HeaderName)); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if err := s.d.SessionManager().DoesSessionSatisfy(r, sess, s.d.Config().SelfServiceSettingsRequiredAAL(r.Context())); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	// Revoking a link is a settings update, so it runs through a settings flow to
+	// apply the privileged session handling and the settings hooks.
+	f, err := s.d.SettingsHandler().NewFlow(w, r, sess.Identity, flowType)
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	p := &updateSettingsFlowWithOidcMethod{Unlink: ps.ByName("provider"), FlowID: f.ID.String()}
+	if err := s.unlinkProvider(w, r, &settings.UpdateContext{Session: sess, Flow: f}, p); err != nil && !errors.Is(err, flow.ErrCompletedByStrategy) {
+		s.d.SettingsFlowErrorHandler().WriteFlowError(w, r, s.NodeGroup(), f, sess.Identity, err)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/sqlxx"
+)
+
+func TestLinksAPI(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+
+	newProvider := func(id string) oidc.Configuration {
+		return oidc.Configuration{
+			ID:           id,
+			Provider:     "generic",
+			ClientID:     "client",
+			ClientSecret: "secret",
+			IssuerURL:    "https://example.com",
+			Mapper:       "file://./stub/oidc.hydra.jsonnet",
+		}
+	}
+	viperSetProviderConfig(t, conf, newProvider("ory"), newProvider("github"))
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/settings.schema.json")
+
+	publicTS, _ := testhelpers.NewKratosServer(t, reg)
+
+	testID := x.NewUUID().String()
+	users := map[string]*identity.Identity{
+		"githuber": {
+			ID: x.NewUUID(), Traits: identity.Traits(`{"email":"hackerman+links+` + testID + `@ory.sh"}`),
+			SchemaID: config.DefaultIdentityTraitsSchemaID,
+			Credentials: map[identity.CredentialsType]identity.Credentials{
+				identity.CredentialsTypeOIDC: {
+					Type:        identity.CredentialsTypeOIDC,
+					Identifiers: []string{"ory:hackerman+links+" + testID, "github:hackerman+links+" + testID},
+					Config:      sqlxx.JSONRawMessage(`{"providers":[{"provider":"ory","subject":"hackerman+links+` + testID + `"},{"provider":"github","subject":"hackerman+links+` + testID + `"}]}`),
+				},
+			},
+		},
+	}
+	agents := testhelpers.AddAndLoginIdentities(t, reg, publicTS, users)
+
+	list := func(t *testing.T, client *http.Client) (*http.Response, string) {
+		res, err := client.Get(publicTS.URL + oidc.RouteLinks)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, string(body)
+	}
+
+	revokeWithToken := func(t *testing.T, client *http.Client, provider, csrfToken string) (*http.Response, string) {
+		req, err := http.NewRequest("DELETE", publicTS.URL+oidc.RouteLinks+"/"+provider, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "application/json")
+		if csrfToken != "" {
+			req.Header.Set("X-CSRF-Token", csrfToken)
+		}
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, string(body)
+	}
+
+	revoke := func(t *testing.T, client *http.Client, provider string) (*http.Response, string) {
+		return revokeWithToken(t, client, provider, x.FakeCSRFToken)
+	}
+
+	t.Run("case=requires a session", func(t *testing.T) {
+		res, _ := list(t, http.DefaultClient)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		res, _ = revoke(t, http.DefaultClient, "github")
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("case=lists linked providers", func(t *testing.T) {
+		res, body := list(t, agents["githuber"])
+		require.Equal(t, http.StatusOK, res.StatusCode, body)
+		assert.Equal(t, []interface{}{"ory", "github"}, gjson.Get(body, "#.provider").Value(), body)
+		assert.Equal(t, "hackerman+links+"+testID, gjson.Get(body, "0.subject").String(), body)
+		assert.False(t, gjson.Get(body, "0.initial_id_token").Exists(), "tokens must not be exposed: %s", body)
+	})
+
+	t.Run("case=requires an anti-CSRF token to revoke from the browser", func(t *testing.T) {
+		res, body := revokeWithToken(t, agents["githuber"], "github", "")
+		assert.Equal(t, http.StatusForbidden, res.StatusCode, body)
+		assert.Contains(t, body, "Cross-Site-Request-Forgery", body)
+
+		res, body = revokeWithToken(t, agents["githuber"], "github", "invalid")
+		assert.Equal(t, http.StatusForbidden, res.StatusCode, body)
+	})
+
+	t.Run("case=can not revoke an unknown link", func(t *testing.T) {
+		res, body := revoke(t, agents["githuber"], "google")
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+		assert.Contains(t, gjson.Get(body, "ui.messages.0.text").String(), "can not unlink non-existing OpenID Connect connection", body)
+	})
+
+	t.Run("case=revokes a link but not the last one", func(t *testing.T) {
+		res, body := revoke(t, agents["githuber"], "github")
+		require.Equal(t, http.StatusOK, res.StatusCode, body)
+		assert.Equal(t, "success", gjson.Get(body, "state").String(), body)
+
+		res, body = list(t, agents["githuber"])
+		require.Equal(t, http.StatusOK, res.StatusCode, body)
+		assert.Equal(t, []interface{}{"ory"}, gjson.Get(body, "#.provider").Value(), body)
+
+		i, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, users["githuber"].ID)
+		require.NoError(t, err)
+		var cc identity.CredentialsOIDC
+		_, err = i.ParseCredentials(identity.CredentialsTypeOIDC, &cc)
+		require.NoError(t, err)
+		require.Len(t, cc.Providers, 1)
+		assert.Equal(t, "ory", cc.Providers[0].Provider)
+
+		res, body = revoke(t, agents["githuber"], "ory")
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+		assert.Contains(t, gjson.Get(body, "ui.messages.0.text").String(), "last remaining first factor credential", body)
+
+		i, err = reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, users["githuber"].ID)
+		require.NoError(t, err)
+		_, err = i.ParseCredentials(identity.CredentialsTypeOIDC, &cc)
+		require.NoError(t, err)
+		assert.Len(t, cc.Providers, 1)
+	})
+
+	t.Run("case=requires a privileged session to revoke", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsPrivilegedAuthenticationAfter, "1ns")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsPrivilegedAuthenticationAfter, "5m")
+		})
+
+		res, body := revoke(t, agents["githuber"], "ory")
+		assert.Equal(t, http.StatusForbidden, res.StatusCode, body)
+		assert.Equal(t, "session_refresh_required", gjson.Get(body, "error.id").String(), body)
+	})
+}
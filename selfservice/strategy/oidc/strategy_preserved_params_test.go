@@ -0,0 +1,52 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ory/x/urlx"
+)
+
+func TestWithPreservedParams(t *testing.T) {
+	defaultReturnTo := urlx.ParseOrPanic("https://www.ory.sh/default")
+	params := url.Values{"section": {"billing"}}
+
+	for _, tc := range []struct {
+		name, returnTo, requestURL, expected string
+		params                               url.Values
+	}{
+		{
+			name:       "appends to the flow's return_to",
+			returnTo:   "https://www.ory.sh/foo?bar=baz",
+			requestURL: "https://kratos.ory.sh/self-service/login/browser?return_to=https://www.ory.sh/other",
+			params:     params,
+			expected:   "https://www.ory.sh/foo?bar=baz&section=billing",
+		},
+		{
+			name:       "falls back to the request's return_to",
+			requestURL: "https://kratos.ory.sh/self-service/login/browser?return_to=https://www.ory.sh/other",
+			params:     params,
+			expected:   "https://www.ory.sh/other?section=billing",
+		},
+		{
+			name:       "falls back to the default return URL",
+			requestURL: "https://kratos.ory.sh/self-service/login/browser?section=billing",
+			params:     params,
+			expected:   "https://www.ory.sh/default?section=billing",
+		},
+		{
+			name:       "keeps the return_to without params",
+			requestURL: "https://kratos.ory.sh/self-service/login/browser",
+			expected:   "",
+		},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, withPreservedParams(tc.returnTo, tc.requestURL, defaultReturnTo, tc.params))
+		})
+	}
+}
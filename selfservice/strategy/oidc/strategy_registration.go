@@ -221,6 +221,7 @@ func (s *Strategy) Register(w http.ResponseWriter, r *http.Request, f *registrat
 			FlowID:           f.ID.String(),
 			Traits:           p.Traits,
 			TransientPayload: f.TransientPayload,
+			PreservedParams:  s.preservedParams(ctx, f.RequestURL),
 		}),
 		continuity.WithLifespan(time.Minute*30)); err != nil {
 		return s.handleError(w, r, f, pid, nil, err)
@@ -337,7 +338,13 @@ func (s *Strategy) processRegistration(w http.ResponseWriter, r *http.Request, r
 	}
 
 	i.SetCredentials(s.ID(), *creds)
-	if err := s.d.RegistrationExecutor().PostRegistrationHook(w, r, identity.CredentialsTypeOIDC, provider.Config().ID, rf, i); err != nil {
+
+	var opts []identity.ManagerOption
+	if s.onExistingAccount(r.Context()) == OnExistingAccountCreateSeparate {
+		opts = append(opts, identity.ManagerDropConflictingIdentifiers)
+	}
+
+	if err := s.d.RegistrationExecutor().PostRegistrationHook(w, r, identity.CredentialsTypeOIDC, provider.Config().ID, rf, i, opts...); err != nil {
 		return nil, s.handleError(w, r, rf, provider.Config().ID, i.Traits, err)
 	}
 
@@ -400,7 +407,7 @@ func (s *Strategy) setTraits(w http.ResponseWriter, r *http.Request, a *registra
 	}
 
 	if container != nil {
-		traits, err := merge(container.Traits, json.RawMessage(jsonTraits.Raw))
+		traits, err := merge(container.Traits, json.RawMessage(jsonTraits.Raw), s.d.Config().IdentityTraitsPreserveNumberPrecision(r.Context()))
 		if err != nil {
 			return s.handleError(w, r, a, provider.Config().ID, nil, err)
 		}
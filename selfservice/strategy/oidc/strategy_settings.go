@@ -51,7 +51,9 @@ var UnlinkAllFirstFactorConnectionsError = &jsonschema.ValidationError{
 	Message: "can not unlink OpenID Connect connection because it is the last remaining first factor credential", InstancePtr: "#/",
 }
 
-func (s *Strategy) RegisterSettingsRoutes(router *x.RouterPublic) {}
+func (s *Strategy) RegisterSettingsRoutes(router *x.RouterPublic) {
+	s.setLinkRoutes(router)
+}
 
 func (s *Strategy) SettingsStrategyID() string {
 	return s.ID().String()
@@ -431,43 +433,60 @@ func (s *Strategy) unlinkProvider(w http.ResponseWriter, r *http.Request, ctxUpd
 		return s.handleSettingsError(w, r, ctxUpdate, p, errors.WithStack(settings.NewFlowNeedsReAuth()))
 	}
 
-	providers, err := s.Config(r.Context())
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), ctxUpdate.Session.Identity.ID)
 	if err != nil {
 		return s.handleSettingsError(w, r, ctxUpdate, p, err)
 	}
 
-	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), ctxUpdate.Session.Identity.ID)
-	if err != nil {
+	if err := s.removeLinkedProvider(r.Context(), r, i, p.Unlink); err != nil {
 		return s.handleSettingsError(w, r, ctxUpdate, p, err)
 	}
 
-	availableProviders, err := s.linkedProviders(r.Context(), r, providers, i)
-	if err != nil {
+	if err := s.d.SettingsHookExecutor().PostSettingsHook(w, r, s.SettingsStrategyID(), ctxUpdate, i, settings.WithCallback(func(ctxUpdate *settings.UpdateContext) error {
+		return s.PopulateSettingsMethod(r, ctxUpdate.Session.Identity, ctxUpdate.Flow)
+	})); err != nil {
 		return s.handleSettingsError(w, r, ctxUpdate, p, err)
 	}
 
+	return errors.WithStack(flow.ErrCompletedByStrategy)
+}
+
+// removeLinkedProvider removes the link to the given provider from the
+// identity's credentials without persisting the identity. It refuses to remove
+// the last remaining first factor credential.
+func (s *Strategy) removeLinkedProvider(ctx context.Context, r *http.Request, i *identity.Identity, unlink string) error {
+	providers, err := s.Config(ctx)
+	if err != nil {
+		return err
+	}
+
+	availableProviders, err := s.linkedProviders(ctx, r, providers, i)
+	if err != nil {
+		return err
+	}
+
 	var cc identity.CredentialsOIDC
 	creds, err := i.ParseCredentials(s.ID(), &cc)
 	if err != nil {
-		return s.handleSettingsError(w, r, ctxUpdate, p, err)
+		return err
 	}
 
-	count, err := s.d.IdentityManager().CountActiveFirstFactorCredentials(r.Context(), i)
+	count, err := s.d.IdentityManager().CountActiveFirstFactorCredentials(ctx, i)
 	if err != nil {
-		return s.handleSettingsError(w, r, ctxUpdate, p, err)
+		return err
 	}
 
 	if count < 2 {
-		return s.handleSettingsError(w, r, ctxUpdate, p, errors.WithStack(UnlinkAllFirstFactorConnectionsError))
+		return errors.WithStack(UnlinkAllFirstFactorConnectionsError)
 	}
 
 	var found bool
 	var updatedProviders []identity.CredentialsOIDCProvider
 	var updatedIdentifiers []string
 	for _, available := range availableProviders {
-		if p.Unlink == available.Config().ID {
+		if unlink == available.Config().ID {
 			for _, link := range cc.Providers {
-				if link.Provider != p.Unlink {
+				if link.Provider != unlink {
 					updatedIdentifiers = append(updatedIdentifiers, identity.OIDCUniqueID(link.Provider, link.Subject))
 					updatedProviders = append(updatedProviders, link)
 				} else {
@@ -478,23 +497,17 @@ func (s *Strategy) unlinkProvider(w http.ResponseWriter, r *http.Request, ctxUpd
 	}
 
 	if !found {
-		return s.handleSettingsError(w, r, ctxUpdate, p, errors.WithStack(UnknownConnectionValidationError))
+		return errors.WithStack(UnknownConnectionValidationError)
 	}
 
 	creds.Identifiers = updatedIdentifiers
 	creds.Config, err = json.Marshal(&identity.CredentialsOIDC{Providers: updatedProviders})
 	if err != nil {
-		return s.handleSettingsError(w, r, ctxUpdate, p, errors.WithStack(err))
+		return errors.WithStack(err)
 	}
 
 	i.Credentials[s.ID()] = *creds
-	if err := s.d.SettingsHookExecutor().PostSettingsHook(w, r, s.SettingsStrategyID(), ctxUpdate, i, settings.WithCallback(func(ctxUpdate *settings.UpdateContext) error {
-		return s.PopulateSettingsMethod(r, ctxUpdate.Session.Identity, ctxUpdate.Flow)
-	})); err != nil {
-		return s.handleSettingsError(w, r, ctxUpdate, p, err)
-	}
-
-	return errors.WithStack(flow.ErrCompletedByStrategy)
+	return nil
 }
 
 func (s *Strategy) handleSettingsError(w http.ResponseWriter, r *http.Request, ctxUpdate *settings.UpdateContext, p *updateSettingsFlowWithOidcMethod, err error) error {
@@ -976,6 +976,59 @@ func TestStrategy(t *testing.T) {
 		})
 	})
 
+	t.Run("case=login with preserved query parameters", func(t *testing.T) {
+		subject = "login-preserve-params@ory.sh"
+		scope = []string{"openid"}
+
+		key := fmt.Sprintf("%s.%s.config.preserve_params", config.ViperKeySelfServiceStrategyConfig, identity.CredentialsTypeOIDC)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, key, nil)
+		})
+
+		for _, tc := range []struct {
+			name         string
+			preserve     []string
+			requestQuery string
+			expectPath   string
+			expectQuery  url.Values
+		}{
+			{
+				name:         "with return_to",
+				preserve:     []string{"section"},
+				requestQuery: "return_to=/foo&section=billing&tracking=abc",
+				expectPath:   "/foo",
+				expectQuery:  url.Values{"section": {"billing"}},
+			},
+			{
+				name:         "without return_to",
+				preserve:     []string{"section"},
+				requestQuery: "section=billing&tracking=abc",
+				expectPath:   "",
+				expectQuery:  url.Values{"section": {"billing"}},
+			},
+			{
+				// Shows that tracking is only dropped because it is not allowlisted.
+				name:         "all params allowlisted",
+				preserve:     []string{"section", "tracking"},
+				requestQuery: "section=billing&tracking=abc",
+				expectPath:   "",
+				expectQuery:  url.Values{"section": {"billing"}, "tracking": {"abc"}},
+			},
+		} {
+			t.Run("case="+tc.name, func(t *testing.T) {
+				conf.MustSet(ctx, key, tc.preserve)
+
+				r := newBrowserLoginFlow(t, returnTS.URL+"?"+tc.requestQuery, time.Minute)
+				action := assertFormValues(t, r.ID, "valid")
+				res, body := makeRequest(t, "valid", action, url.Values{})
+				assertIdentity(t, res, body)
+
+				assert.Equal(t, tc.expectPath, res.Request.URL.Path)
+				assert.Equal(t, tc.expectQuery, res.Request.URL.Query())
+			})
+		}
+	})
+
 	t.Run("case=register and register again but login", func(t *testing.T) {
 		subject = "register-twice@ory.sh"
 		scope = []string{"openid"}
@@ -1406,6 +1459,106 @@ func TestStrategy(t *testing.T) {
 		})
 	})
 
+	t.Run("case=on_existing_account", func(t *testing.T) {
+		key := fmt.Sprintf("%s.%s.config.on_existing_account", config.ViperKeySelfServiceStrategyConfig, identity.CredentialsTypeOIDC)
+		scope = []string{"openid"}
+
+		createPasswordIdentity := func(t *testing.T, email string) *identity.Identity {
+			i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+			p, err := reg.Hasher(ctx).Generate(ctx, []byte("lwkj52sdkjf"))
+			require.NoError(t, err)
+			i.SetCredentials(identity.CredentialsTypePassword, identity.Credentials{
+				Identifiers: []string{email},
+				Config:      sqlxx.JSONRawMessage(`{"hashed_password":"` + string(p) + `"}`),
+			})
+			i.Traits = identity.Traits(`{"subject":"` + email + `"}`)
+			require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+			return i
+		}
+
+		assertNotLinked := func(t *testing.T, id uuid.UUID) {
+			i, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id)
+			require.NoError(t, err)
+			_, ok := i.GetCredentials(identity.CredentialsTypeOIDC)
+			assert.False(t, ok, "%+v", i.Credentials)
+		}
+
+		for _, tc := range []struct {
+			mode   string
+			schema string
+			assert func(t *testing.T, existing *identity.Identity, res *http.Response, body []byte)
+		}{
+			{
+				mode: oidc.OnExistingAccountLinkAfterVerification,
+				assert: func(t *testing.T, existing *identity.Identity, res *http.Response, body []byte) {
+					assert.Contains(t, res.Request.URL.String(), uiTS.URL, "%s", body)
+					assert.Contains(t, gjson.GetBytes(body, "ui.action").String(), login.RouteSubmitFlow, "should ask to sign in first: %s", body)
+					assert.True(t, gjson.GetBytes(body, "ui.messages.#(id==4000028)").Exists(), "%s", body)
+					assertNotLinked(t, existing.ID)
+				},
+			},
+			{
+				mode: oidc.OnExistingAccountError,
+				assert: func(t *testing.T, existing *identity.Identity, res *http.Response, body []byte) {
+					assert.Contains(t, res.Request.URL.String(), uiTS.URL, "%s", body)
+					assert.Contains(t, gjson.GetBytes(body, "ui.action").String(), registration.RouteSubmitFlow, "should stay on the registration flow: %s", body)
+					assert.True(t, gjson.GetBytes(body, "ui.messages.#(id==4000028)").Exists(), "%s", body)
+					assertNotLinked(t, existing.ID)
+				},
+			},
+			{
+				mode:   oidc.OnExistingAccountCreateSeparate,
+				schema: "file://./stub/registration-optional-subject.schema.json",
+				assert: func(t *testing.T, existing *identity.Identity, res *http.Response, body []byte) {
+					assert.Contains(t, res.Request.URL.String(), returnTS.URL, "%s", body)
+					assert.Equal(t, claims.traits.website, gjson.GetBytes(body, "identity.traits.website").String(), "%s", body)
+					assert.False(t, gjson.GetBytes(body, "identity.traits.subject").Exists(), "the conflicting email must be removed: %s", body)
+
+					separateID := gjson.GetBytes(body, "identity.id").String()
+					assert.NotEqual(t, existing.ID.String(), separateID, "%s", body)
+					assertNotLinked(t, existing.ID)
+
+					// The separate identity must not run into the conflict on its next update.
+					separate, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, uuid.Must(uuid.FromString(separateID)))
+					require.NoError(t, err)
+					separate.Traits = identity.Traits(`{"website":"https://www.ory.sh/updated"}`)
+					require.NoError(t, reg.IdentityManager().Update(ctx, separate, identity.ManagerAllowWriteProtectedTraits))
+				},
+			},
+			{
+				mode: oidc.OnExistingAccountCreateSeparate,
+				assert: func(t *testing.T, existing *identity.Identity, res *http.Response, body []byte) {
+					// The schema requires the conflicting email, so no separate identity is created.
+					assert.Contains(t, res.Request.URL.String(), uiTS.URL, "%s", body)
+					assert.Contains(t, gjson.GetBytes(body, "ui.action").String(), registration.RouteSubmitFlow, "%s", body)
+					assert.True(t, gjson.GetBytes(body, "ui.messages.#(id==4000028)").Exists(), "%s", body)
+					assertNotLinked(t, existing.ID)
+				},
+			},
+		} {
+			t.Run("mode="+tc.mode, func(t *testing.T) {
+				conf.MustSet(ctx, key, tc.mode)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, key, nil)
+				})
+				if tc.schema != "" {
+					testhelpers.SetDefaultIdentitySchema(conf, tc.schema)
+					t.Cleanup(func() {
+						testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/registration.schema.json")
+					})
+				}
+
+				subject = "on-existing-account-" + x.NewUUID().String() + "@ory.sh"
+				existing := createPasswordIdentity(t, subject)
+
+				r := newRegistrationFlow(t, returnTS.URL, time.Minute, flow.TypeBrowser)
+				action := assertFormValues(t, r.ID, "valid")
+				res, body := makeRequest(t, "valid", action, url.Values{})
+				tc.assert(t, existing, res, body)
+			})
+		}
+	})
+
 	t.Run("method=TestPopulateSignUpMethod", func(t *testing.T) {
 		conf.MustSet(ctx, config.ViperKeyPublicBaseURL, "https://foo/")
 
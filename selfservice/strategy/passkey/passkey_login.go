@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/go-webauthn/webauthn/webauthn"
@@ -60,7 +61,7 @@ func (s *Strategy) populateLoginMethodForPasskeys(r *http.Request, loginFlow *lo
 		return err
 	}
 
-	webAuthn, err := webauthn.New(s.d.Config().PasskeyConfig(ctx))
+	webAuthn, err := webauthn.New(s.passkeyConfig(r))
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -78,6 +79,15 @@ func (s *Strategy) populateLoginMethodForPasskeys(r *http.Request, loginFlow *lo
 		return errors.WithStack(err)
 	}
 
+	loginFlow.InternalContext, err = sjson.SetBytes(
+		loginFlow.InternalContext,
+		flow.PrefixInternalContextKey(s.ID(), InternalContextKeyChallengeIssuedAt),
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	injectWebAuthnOptions, err := json.Marshal(option)
 	if err != nil {
 		return errors.WithStack(err)
@@ -118,10 +128,8 @@ func (s *Strategy) populateLoginMethodForPasskeys(r *http.Request, loginFlow *lo
 		"",
 		node.PasskeyGroup,
 		node.InputAttributeTypeButton,
-		node.WithInputAttributes(func(attr *node.InputAttributes) {
-			attr.OnClick = "window.__oryPasskeyLogin()"                // this function is defined in webauthn.js
-			attr.OnLoad = "window.__oryPasskeyLoginAutocompleteInit()" // same here
-		}),
+		webauthnx.WithOnClick(s.d.Config().WebAuthnUseExternalScript(ctx), webauthnx.TriggerPasskeyLogin, ""),
+		webauthnx.WithOnLoad(s.d.Config().WebAuthnUseExternalScript(ctx), webauthnx.TriggerPasskeyLoginAutocompleteInit),
 	).WithMetaLabel(text.NewInfoSelfServiceLoginPasskey()))
 
 	return nil
@@ -159,7 +167,7 @@ func (s *Strategy) populateLoginMethodForRefresh(r *http.Request, loginFlow *log
 
 	passkeyIdentifier := s.PasskeyDisplayNameFromIdentity(ctx, id)
 
-	webAuthn, err := webauthn.New(s.d.Config().PasskeyConfig(ctx))
+	webAuthn, err := webauthn.New(s.passkeyConfig(r))
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -182,6 +190,15 @@ func (s *Strategy) populateLoginMethodForRefresh(r *http.Request, loginFlow *log
 		return errors.WithStack(err)
 	}
 
+	loginFlow.InternalContext, err = sjson.SetBytes(
+		loginFlow.InternalContext,
+		flow.PrefixInternalContextKey(s.ID(), InternalContextKeyChallengeIssuedAt),
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	injectWebAuthnOptions, err := json.Marshal(option)
 	if err != nil {
 		return errors.WithStack(err)
@@ -213,9 +230,7 @@ func (s *Strategy) populateLoginMethodForRefresh(r *http.Request, loginFlow *log
 		"",
 		node.PasskeyGroup,
 		node.InputAttributeTypeButton,
-		node.WithInputAttributes(func(attr *node.InputAttributes) {
-			attr.OnClick = "window.__oryPasskeyLogin()" // this function is defined in webauthn.js
-		}),
+		webauthnx.WithOnClick(s.d.Config().WebAuthnUseExternalScript(ctx), webauthnx.TriggerPasskeyLogin, ""),
 	).WithMetaLabel(text.NewInfoSelfServiceLoginPasskey()))
 
 	loginFlow.UI.SetCSRF(s.d.GenerateCSRFToken(r))
@@ -322,7 +337,7 @@ func (s *Strategy) loginPasswordless(w http.ResponseWriter, r *http.Request, f *
 func (s *Strategy) loginAuthenticate(_ http.ResponseWriter, r *http.Request, f *login.Flow, p *updateLoginFlowWithPasskeyMethod, _ identity.AuthenticatorAssuranceLevel) (*identity.Identity, error) {
 	ctx := r.Context()
 
-	web, err := webauthn.New(s.d.Config().PasskeyConfig(ctx))
+	web, err := webauthn.New(s.passkeyConfig(r))
 	if err != nil {
 		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to get webAuthn config.").WithDebug(err.Error())))
 	}
@@ -339,6 +354,13 @@ func (s *Strategy) loginAuthenticate(_ http.ResponseWriter, r *http.Request, f *
 	}
 	webAuthnSess.UserID = nil
 
+	// The challenge may expire before the flow does to limit the window in which an assertion can be replayed.
+	// Challenges without an issue time are treated as expired.
+	if issuedAt := gjson.GetBytes(f.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeyChallengeIssuedAt)).Time(); issuedAt.IsZero() ||
+		time.Since(issuedAt) > s.d.Config().PasskeyChallengeLifespan(ctx) {
+		return nil, s.handleLoginError(r, f, schema.NewWebAuthnChallengeExpiredError())
+	}
+
 	userHandle := webAuthnResponse.Response.UserHandle
 	credentialType := identity.CredentialsTypePasskey
 	i, _, err := s.d.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, identity.CredentialsTypePasskey, string(userHandle))
@@ -386,6 +408,11 @@ func (s *Strategy) loginAuthenticate(_ http.ResponseWriter, r *http.Request, f *
 		return nil, s.handleLoginError(r, f, errors.WithStack(err))
 	}
 
+	f.InternalContext, err = sjson.DeleteBytes(f.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeyChallengeIssuedAt))
+	if err != nil {
+		return nil, s.handleLoginError(r, f, errors.WithStack(err))
+	}
+
 	f.Active = s.ID()
 	if err = s.d.LoginFlowPersister().UpdateLoginFlow(ctx, f); err != nil {
 		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrInternalServerError.WithReason("Could not update flow").WithDebug(err.Error())))
@@ -10,11 +10,13 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
@@ -77,6 +79,31 @@ func TestCompleteLogin(t *testing.T) {
 			})
 		})
 
+		t.Run("case=passwordless or password form has password and passkey nodes", func(t *testing.T) {
+			passwordEnabled := config.ViperKeySelfServiceStrategyConfig + "." + string(identity.CredentialsTypePassword) + ".enabled"
+			fix.conf.MustSet(fix.ctx, passwordEnabled, true)
+			fix.conf.MustSet(fix.ctx, config.ViperKeySelfServiceLoginFlowStyle, config.LoginFlowStylePasswordlessOrPassword)
+			t.Cleanup(func() {
+				fix.conf.MustSet(fix.ctx, passwordEnabled, false)
+				fix.conf.MustSet(fix.ctx, config.ViperKeySelfServiceLoginFlowStyle, config.LoginFlowStyleOneStep)
+			})
+
+			client := testhelpers.NewClientWithCookies(t)
+			f := testhelpers.InitializeLoginFlowViaBrowser(t, client, fix.publicTS, false, true, false, false)
+			nodes, err := json.Marshal(f.Ui.Nodes)
+			require.NoError(t, err)
+
+			identifier := gjson.GetBytes(nodes, "#(attributes.name==identifier)")
+			require.True(t, identifier.Exists(), "%s", nodes)
+			assert.Equal(t, "username webauthn", identifier.Get("attributes.autocomplete").String(), "%s", nodes)
+			assert.Len(t, gjson.GetBytes(nodes, "#(attributes.name==identifier)#").Array(), 1, "%s", nodes)
+
+			assert.Equal(t, "password", gjson.GetBytes(nodes, "#(attributes.name==password).group").String(), "%s", nodes)
+			assert.Equal(t, "password", gjson.GetBytes(nodes, "#(attributes.name==method).attributes.value").String(), "%s", nodes)
+			assert.Equal(t, "passkey", gjson.GetBytes(nodes, "#(attributes.name=="+node.PasskeyLoginTrigger+").group").String(), "%s", nodes)
+			assert.True(t, gjson.GetBytes(nodes, "#(attributes.name=="+node.PasskeyChallenge+")").Exists(), "%s", nodes)
+		})
+
 		t.Run("case=passkey shows error if user tries to sign in but no such user exists", func(t *testing.T) {
 			payload := func(v url.Values) {
 				v.Set("method", "passkey")
@@ -180,6 +207,39 @@ func TestCompleteLogin(t *testing.T) {
 			})
 		})
 
+		t.Run("case=should reject assertions for expired challenges", func(t *testing.T) {
+			previous := fix.conf.PasskeyChallengeLifespan(fix.ctx)
+			fix.conf.MustSet(fix.ctx, config.ViperKeyPasskeyChallengeLifespan, "1m")
+			t.Cleanup(func() {
+				fix.conf.MustSet(fix.ctx, config.ViperKeyPasskeyChallengeLifespan, previous.String())
+			})
+
+			for _, tc := range []struct {
+				d        string
+				issuedAt any
+			}{
+				{d: "issued before the lifespan", issuedAt: time.Now().Add(-5 * time.Minute).UTC()},
+				{d: "without an issue time", issuedAt: nil},
+			} {
+				t.Run("case="+tc.d, func(t *testing.T) {
+					fix.createIdentityWithPasskey(t, identity.Credentials{
+						Config:  loginPasswordlessCredentials,
+						Version: 1,
+					})
+
+					internalContext, err := sjson.SetBytes(loginPasswordlessContext,
+						flow.PrefixInternalContextKey(identity.CredentialsTypePasskey, passkey.InternalContextKeyChallengeIssuedAt), tc.issuedAt)
+					require.NoError(t, err)
+
+					body, res, _ := fix.submitWebAuthnLoginWithClient(t, true, internalContext, testhelpers.NewClientWithCookies(t), func(values url.Values) {
+						values.Set(node.PasskeyLogin, string(loginPasswordlessResponse))
+					}, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel1))
+					assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+					assert.EqualValues(t, text.ErrorValidationWebAuthnChallengeExpired, gjson.Get(body, "ui.messages.0.id").Int(), "%s", body)
+				})
+			}
+		})
+
 		t.Run("case=succeeds with passwordless login", func(t *testing.T) {
 			run := func(t *testing.T, spa bool) {
 				fix.conf.MustSet(fix.ctx, config.ViperKeySessionWhoAmIAAL, "aal1")
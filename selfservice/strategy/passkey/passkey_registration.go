@@ -149,7 +149,7 @@ func (s *Strategy) Register(w http.ResponseWriter, r *http.Request, regFlow *reg
 			herodot.ErrBadRequest.WithReasonf("Unable to parse WebAuthn response: %s", err)))
 	}
 
-	webAuthn, err := webauthn.New(s.d.Config().PasskeyConfig(ctx))
+	webAuthn, err := webauthn.New(s.passkeyConfig(r))
 	if err != nil {
 		return s.handleRegistrationError(w, r, regFlow, params, errors.WithStack(
 			herodot.ErrInternalServerError.WithReasonf("Unable to get webAuthn config").WithDebug(err.Error())))
@@ -225,14 +225,14 @@ func (s *Strategy) PopulateRegistrationMethod(r *http.Request, regFlow *registra
 	}
 	createData.DisplayNameFieldName = fieldName
 
-	webAuthn, err := webauthn.New(s.d.Config().PasskeyConfig(ctx))
+	webAuthn, err := webauthn.New(s.passkeyConfig(r))
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	user := &webauthnx.User{
 		Name:   "",
 		ID:     []byte(randx.MustString(64, randx.AlphaNum)),
-		Config: s.d.Config().PasskeyConfig(ctx),
+		Config: s.passkeyConfig(r),
 	}
 	option, sessionData, err := webAuthn.BeginRegistration(user)
 	if err != nil {
@@ -275,15 +275,13 @@ func (s *Strategy) PopulateRegistrationMethod(r *http.Request, regFlow *registra
 			Type: node.InputAttributeTypeHidden,
 		}})
 
-	regFlow.UI.Nodes.Append(&node.Node{
-		Type:  node.Input,
-		Group: node.PasskeyGroup,
-		Meta:  &node.Meta{Label: text.NewInfoSelfServiceRegistrationRegisterPasskey()},
-		Attributes: &node.InputAttributes{
-			Name:    node.PasskeyRegisterTrigger,
-			Type:    node.InputAttributeTypeButton,
-			OnClick: "window.__oryPasskeyRegistration()", // defined in webauthn.js
-		}})
+	regFlow.UI.Nodes.Append(node.NewInputField(
+		node.PasskeyRegisterTrigger,
+		nil,
+		node.PasskeyGroup,
+		node.InputAttributeTypeButton,
+		webauthnx.WithOnClick(s.d.Config().WebAuthnUseExternalScript(ctx), webauthnx.TriggerPasskeyRegistration, ""),
+	).WithMetaLabel(text.NewInfoSelfServiceRegistrationRegisterPasskey()))
 
 	// Passkey nodes end
 
@@ -38,7 +38,8 @@ func (s *Strategy) RegisterSettingsRoutes(_ *x.RouterPublic) {}
 func (s *Strategy) SettingsStrategyID() string { return s.ID().String() }
 
 const (
-	InternalContextKeySessionData = "session_data"
+	InternalContextKeySessionData       = "session_data"
+	InternalContextKeyChallengeIssuedAt = "challenge_issued_at"
 )
 
 func (s *Strategy) PopulateSettingsMethod(r *http.Request, id *identity.Identity, f *settings.Flow) error {
@@ -75,7 +76,7 @@ func (s *Strategy) PopulateSettingsMethod(r *http.Request, id *identity.Identity
 		}
 	}
 
-	web, err := webauthn.New(s.d.Config().PasskeyConfig(r.Context()))
+	web, err := webauthn.New(s.passkeyConfig(r))
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -89,7 +90,7 @@ func (s *Strategy) PopulateSettingsMethod(r *http.Request, id *identity.Identity
 	user := &webauthnx.User{
 		Name:   identifier,
 		ID:     []byte(randx.MustString(64, randx.AlphaNum)),
-		Config: s.d.Config().PasskeyConfig(r.Context()),
+		Config: s.passkeyConfig(r),
 	}
 	option, sessionData, err := web.BeginRegistration(user)
 	if err != nil {
@@ -113,9 +114,7 @@ func (s *Strategy) PopulateSettingsMethod(r *http.Request, id *identity.Identity
 		"",
 		node.PasskeyGroup,
 		node.InputAttributeTypeButton,
-		node.WithInputAttributes(func(a *node.InputAttributes) {
-			a.OnClick = "window.__oryPasskeySettingsRegistration()"
-		}),
+		webauthnx.WithOnClick(s.d.Config().WebAuthnUseExternalScript(r.Context()), webauthnx.TriggerPasskeySettingsRegistration, ""),
 	).WithMetaLabel(text.NewInfoSelfServiceSettingsRegisterPasskey()))
 
 	f.UI.Nodes.Upsert(&node.Node{
@@ -329,7 +328,7 @@ func (s *Strategy) continueSettingsFlowAdd(r *http.Request, ctxUpdate *settings.
 		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to parse WebAuthn response: %s", err))
 	}
 
-	web, err := webauthn.New(s.d.Config().PasskeyConfig(r.Context()))
+	web, err := webauthn.New(s.passkeyConfig(r))
 	if err != nil {
 		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to get webAuthn config.").WithDebug(err.Error()))
 	}
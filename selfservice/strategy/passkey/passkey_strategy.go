@@ -6,7 +6,9 @@ package passkey
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/pkg/errors"
 
 	"github.com/ory/kratos/continuity"
@@ -80,6 +82,12 @@ func NewStrategy(d any) *Strategy {
 	}
 }
 
+// passkeyConfig returns the relying party configuration for the host the request was made to.
+func (s *Strategy) passkeyConfig(r *http.Request) *webauthn.Config {
+	ctx := r.Context()
+	return s.d.Config().PasskeyConfig(config.WithWebAuthnRequestHost(ctx, x.RequestHost(r, s.d.Config().PublicTrustedProxies(ctx))))
+}
+
 func (*Strategy) ID() identity.CredentialsType {
 	return identity.CredentialsTypePasskey
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 
 	"github.com/ory/kratos/driver"
@@ -25,8 +26,10 @@ import (
 	"github.com/ory/kratos/internal"
 	kratos "github.com/ory/kratos/internal/httpclient"
 	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/login"
 	"github.com/ory/kratos/selfservice/flow/registration"
+	"github.com/ory/kratos/selfservice/strategy/passkey"
 	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
 	"github.com/ory/x/assertx"
@@ -192,6 +195,11 @@ func (fix *fixture) submitWebAuthnLoginFlowWithClient(t *testing.T, isSPA bool,
 	interim, err := fix.reg.LoginFlowPersister().GetLoginFlow(fix.ctx, uuid.FromStringOrNil(f.Id))
 	require.NoError(t, err)
 	interim.InternalContext = contextFixture
+	// The fixtures were recorded without the time the challenge was issued.
+	if key := flow.PrefixInternalContextKey(identity.CredentialsTypePasskey, passkey.InternalContextKeyChallengeIssuedAt); gjson.ValidBytes(contextFixture) && !gjson.GetBytes(contextFixture, key).Exists() {
+		interim.InternalContext, err = sjson.SetBytes(contextFixture, key, time.Now().UTC())
+		require.NoError(t, err)
+	}
 	require.NoError(t, fix.reg.LoginFlowPersister().UpdateLoginFlow(fix.ctx, interim))
 
 	values := testhelpers.SDKFormFieldsToURLValues(f.Ui.Nodes)
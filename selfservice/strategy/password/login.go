@@ -22,6 +22,7 @@ import (
 	"github.com/ory/herodot"
 	"github.com/ory/x/decoderx"
 
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/hash"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/schema"
@@ -38,7 +39,9 @@ func (s *Strategy) RegisterLoginRoutes(r *x.RouterPublic) {
 func (s *Strategy) handleLoginError(w http.ResponseWriter, r *http.Request, f *login.Flow, payload *updateLoginFlowWithPasswordMethod, err error) error {
 	if f != nil {
 		f.UI.Nodes.ResetNodes("password")
-		f.UI.Nodes.SetValueAttribute("identifier", stringsx.Coalesce(payload.Identifier, payload.LegacyIdentifier))
+		if s.d.Config().SelfServiceFlowLoginEchoIdentifierOnError(r.Context()) {
+			f.UI.Nodes.SetValueAttribute("identifier", stringsx.Coalesce(payload.Identifier, payload.LegacyIdentifier))
+		}
 		if f.Type == flow.TypeBrowser {
 			f.UI.SetCSRF(s.d.GenerateCSRFToken(r))
 		}
@@ -157,7 +160,7 @@ func (s *Strategy) PopulateLoginMethod(r *http.Request, requestedAAL identity.Au
 		if err != nil {
 			return err
 		}
-		sr.UI.SetNode(node.NewInputField("identifier", "", node.DefaultGroup, node.InputAttributeTypeText, node.WithRequiredInputAttribute).WithMetaLabel(identifierLabel))
+		sr.UI.SetNode(node.NewInputField("identifier", "", node.DefaultGroup, node.InputAttributeTypeText, s.identifierAttributes(r)...).WithMetaLabel(identifierLabel))
 	}
 
 	sr.UI.SetCSRF(s.d.GenerateCSRFToken(r))
@@ -166,3 +169,15 @@ func (s *Strategy) PopulateLoginMethod(r *http.Request, requestedAAL identity.Au
 
 	return nil
 }
+
+func (s *Strategy) identifierAttributes(r *http.Request) []node.InputAttributesModifier {
+	ctx := r.Context()
+	attrs := []node.InputAttributesModifier{node.WithRequiredInputAttribute}
+	if s.d.Config().SelfServiceLoginFlowStyle(ctx) == config.LoginFlowStylePasswordlessOrPassword &&
+		s.d.Config().SelfServiceStrategy(ctx, string(identity.CredentialsTypePasskey)).Enabled {
+		// The passkey strategy uses the same identifier field for its conditional UI, which is
+		// why the browser needs to offer passkeys here as well.
+		attrs = append(attrs, func(attributes *node.InputAttributes) { attributes.Autocomplete = "username webauthn" })
+	}
+	return attrs
+}
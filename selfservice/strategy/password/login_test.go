@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -25,16 +26,21 @@ import (
 
 	"github.com/ory/x/urlx"
 
+	"github.com/ory/herodot"
 	"github.com/ory/kratos/hash"
 	kratos "github.com/ory/kratos/internal/httpclient"
 	"github.com/ory/x/assertx"
 	"github.com/ory/x/errorsx"
 	"github.com/ory/x/ioutilx"
+	"github.com/ory/x/sqlcon"
 	"github.com/ory/x/sqlxx"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
@@ -42,6 +48,8 @@ import (
 	"github.com/ory/kratos/internal/testhelpers"
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/flow/registration"
+	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/x"
 )
@@ -467,6 +475,107 @@ func TestCompleteLogin(t *testing.T) {
 		})
 	})
 
+	t.Run("case=should not echo the identifier on error if disabled", func(t *testing.T) {
+		identifier, pwd := x.NewUUID().String(), "password"
+		createIdentity(ctx, reg, t, identifier, pwd)
+
+		values := func(v url.Values) {
+			v.Set("identifier", identifier)
+			v.Set("password", "not-password")
+		}
+
+		body := expectValidationError(t, false, false, true, values)
+		assert.Equal(t, identifier, gjson.Get(body, "ui.nodes.#(attributes.name==identifier).attributes.value").String(), "%s", body)
+
+		previous := conf.SelfServiceFlowLoginEchoIdentifierOnError(ctx)
+		conf.MustSet(ctx, config.ViperKeySelfServiceLoginEchoIdentifierOnError, false)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceLoginEchoIdentifierOnError, previous)
+		})
+
+		body = expectValidationError(t, false, false, true, values)
+		assert.Equal(t, text.NewErrorValidationInvalidCredentials().Text, gjson.Get(body, "ui.messages.0.text").String(), "%s", body)
+		assert.Empty(t, gjson.Get(body, "ui.nodes.#(attributes.name==identifier).attributes.value").String(), "%s", body)
+	})
+
+	t.Run("case=should lock the flow after too many failed submissions", func(t *testing.T) {
+		conf.MustSet(ctx, "selfservice.flows.login.password.max_submissions", 2)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, "selfservice.flows.login.password.max_submissions", nil)
+		})
+
+		identifier, pwd := x.NewUUID().String(), "password"
+		createIdentity(ctx, reg, t, identifier, pwd)
+
+		apiClient := testhelpers.NewDebugClient(t)
+		f := testhelpers.InitializeLoginFlowViaAPI(t, apiClient, publicTS, false)
+		for range 2 {
+			body, res := testhelpers.LoginMakeRequest(t, true, false, f, apiClient,
+				fmt.Sprintf(`{"method":"password","identifier":"%s","password":"not-password"}`, identifier))
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+			assert.EqualValues(t, text.ErrorValidationInvalidCredentials, gjson.Get(body, "ui.messages.0.id").Int(), "%s", body)
+		}
+
+		body, res := testhelpers.LoginMakeRequest(t, true, false, f, apiClient,
+			fmt.Sprintf(`{"method":"password","identifier":"%s","password":"%s"}`, identifier, pwd))
+		assert.Equal(t, http.StatusForbidden, res.StatusCode, "%s", body)
+		assert.Equal(t, text.ErrIDSelfServiceFlowLocked, gjson.Get(body, "error.id").String(), "%s", body)
+		assert.False(t, gjson.Get(body, "session_token").Exists(), "%s", body)
+
+		f = testhelpers.InitializeLoginFlowViaAPI(t, apiClient, publicTS, false)
+		body, res = testhelpers.LoginMakeRequest(t, true, false, f, apiClient,
+			fmt.Sprintf(`{"method":"password","identifier":"%s","password":"%s"}`, identifier, pwd))
+		assert.Equal(t, http.StatusOK, res.StatusCode, "a new flow is not locked: %s", body)
+	})
+
+	t.Run("case=should not exceed the submission limit with concurrent submissions", func(t *testing.T) {
+		conf.MustSet(ctx, "selfservice.flows.login.password.max_submissions", 2)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, "selfservice.flows.login.password.max_submissions", nil)
+		})
+
+		identifier := x.NewUUID().String()
+		createIdentity(ctx, reg, t, identifier, "password")
+
+		apiClient := testhelpers.NewDebugClient(t)
+		f := testhelpers.InitializeLoginFlowViaAPI(t, apiClient, publicTS, false)
+
+		reqs := make([]*http.Request, 8)
+		for k := range reqs {
+			reqs[k] = testhelpers.NewRequest(t, true, "POST", f.Ui.Action,
+				strings.NewReader(fmt.Sprintf(`{"method":"password","identifier":"%s","password":"not-password"}`, identifier)))
+			reqs[k].Header.Set("Accept", "application/json")
+		}
+
+		codes := make([]int, len(reqs))
+		var wg sync.WaitGroup
+		for k, req := range reqs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, err := apiClient.Do(req)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer res.Body.Close()
+				codes[k] = res.StatusCode
+			}()
+		}
+		wg.Wait()
+
+		var failed, locked int
+		for _, code := range codes {
+			switch code {
+			case http.StatusBadRequest:
+				failed++
+			case http.StatusForbidden:
+				locked++
+			}
+		}
+		assert.Equal(t, 2, failed, "%v", codes)
+		assert.Equal(t, len(reqs)-2, locked, "%v", codes)
+	})
+
 	t.Run("should pass with real request", func(t *testing.T) {
 		identifier, pwd := x.NewUUID().String(), "password"
 		createIdentity(ctx, reg, t, identifier, pwd)
@@ -771,6 +880,211 @@ func TestCompleteLogin(t *testing.T) {
 		assert.Equal(t, identifier, gjson.Get(body, "identity.traits.subject").String(), "%s", body)
 	})
 
+	t.Run("case=identifier_input_normalization", func(t *testing.T) {
+		login := func(t *testing.T, identifier, pwd string) string {
+			browserClient := testhelpers.NewClientWithCookies(t)
+			f := testhelpers.InitializeLoginFlowViaBrowser(t, browserClient, publicTS, false, false, false, false)
+
+			values := url.Values{"method": {"password"}, "identifier": {identifier}, "password": {pwd}, "csrf_token": {x.FakeCSRFToken}}.Encode()
+			body, res := testhelpers.LoginMakeRequest(t, false, false, f, browserClient, values)
+			assert.EqualValues(t, http.StatusOK, res.StatusCode)
+			return body
+		}
+
+		for _, tc := range []struct {
+			mode               string
+			expectWhitespaceOK bool
+			expectDecomposedOK bool
+		}{
+			{mode: config.IdentifierInputNormalizationNone},
+			{mode: config.IdentifierInputNormalizationTrim, expectWhitespaceOK: true},
+			{mode: config.IdentifierInputNormalizationTrimNFC, expectWhitespaceOK: true, expectDecomposedOK: true},
+		} {
+			t.Run("mode="+tc.mode, func(t *testing.T) {
+				previous := conf.GetProvider(ctx).Get(config.ViperKeySelfServiceIdentifierInputNormalization)
+				conf.MustSet(ctx, config.ViperKeySelfServiceIdentifierInputNormalization, tc.mode)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeySelfServiceIdentifierInputNormalization, previous)
+				})
+
+				t.Run("case=trailing whitespace", func(t *testing.T) {
+					identifier, pwd := x.NewUUID().String(), "password"
+					createIdentity(ctx, reg, t, identifier, pwd)
+
+					body := login(t, identifier+"  ", pwd)
+					if tc.expectWhitespaceOK {
+						assert.Equal(t, identifier, gjson.Get(body, "identity.traits.subject").String(), "%s", body)
+					} else {
+						assert.False(t, gjson.Get(body, "identity").Exists(), "%s", body)
+					}
+				})
+
+				t.Run("case=decomposed unicode", func(t *testing.T) {
+					// "é" as a single code point (NFC) and as "e" followed by a combining acute accent (NFD).
+					id := x.NewUUID().String()
+					composed, decomposed, pwd := "jos\u00e9-"+id+"@ory.sh", "jose\u0301-"+id+"@ory.sh", "password"
+					createIdentity(ctx, reg, t, composed, pwd)
+
+					body := login(t, decomposed, pwd)
+					if tc.expectDecomposedOK {
+						assert.Equal(t, composed, gjson.Get(body, "identity.traits.subject").String(), "%s", body)
+					} else {
+						assert.False(t, gjson.Get(body, "identity").Exists(), "%s", body)
+					}
+				})
+
+				t.Run("case=decomposed unicode on write", func(t *testing.T) {
+					id := x.NewUUID().String()
+					composed, decomposed, pwd := "jos\u00e9-"+id+"@ory.sh", "jose\u0301-"+id+"@ory.sh", "password"
+					createIdentity(ctx, reg, t, decomposed, pwd)
+
+					body := login(t, composed, pwd)
+					if tc.expectDecomposedOK {
+						assert.Equal(t, decomposed, gjson.Get(body, "identity.traits.subject").String(), "%s", body)
+					} else {
+						assert.False(t, gjson.Get(body, "identity").Exists(), "%s", body)
+					}
+				})
+			})
+		}
+	})
+
+	t.Run("case=identifier_storage=hashed", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceIdentifierStorage, config.IdentifierStorageHashed)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceIdentifierStorage, nil)
+		})
+
+		login := func(t *testing.T, identifier, pwd string) string {
+			browserClient := testhelpers.NewClientWithCookies(t)
+			f := testhelpers.InitializeLoginFlowViaBrowser(t, browserClient, publicTS, false, false, false, false)
+
+			values := url.Values{"method": {"password"}, "identifier": {identifier}, "password": {pwd}, "csrf_token": {x.FakeCSRFToken}}.Encode()
+			body, res := testhelpers.LoginMakeRequest(t, false, false, f, browserClient, values)
+			assert.EqualValues(t, http.StatusOK, res.StatusCode)
+			return body
+		}
+
+		storedIdentifiers := func(t *testing.T, credentialsID uuid.UUID) (stored []string) {
+			var identifiers []identity.CredentialIdentifier
+			require.NoError(t, reg.Persister().GetConnection(ctx).Where("identity_credential_id = ?", credentialsID).All(&identifiers))
+			for _, i := range identifiers {
+				stored = append(stored, i.Identifier)
+			}
+			return stored
+		}
+
+		identifier, pwd := x.NewUUID().String(), "password"
+		createIdentity(ctx, reg, t, identifier, pwd)
+
+		_, creds, err := reg.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, identity.CredentialsTypePassword, identifier)
+		require.NoError(t, err)
+		assert.Equal(t, []string{identifier}, creds.Identifiers, "identifiers are read back in plaintext")
+
+		stored := storedIdentifiers(t, creds.ID)
+		require.Len(t, stored, 1)
+		assert.NotContains(t, stored[0], identifier, "the raw identifier must not be stored")
+
+		body := login(t, " "+strings.ToUpper(identifier)+" ", pwd)
+		assert.Equal(t, identifier, gjson.Get(body, "identity.traits.subject").String(), "%s", body)
+
+		t.Run("case=stored hash is not accepted as identifier", func(t *testing.T) {
+			body := login(t, stored[0], pwd)
+			assert.Empty(t, gjson.Get(body, "identity.id").String(), "%s", body)
+			assert.EqualValues(t, text.ErrorValidationInvalidCredentials, gjson.Get(body, "ui.messages.0.id").Int(), "%s", body)
+		})
+
+		t.Run("case=identifiers stored before hashing was enabled are found", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySelfServiceIdentifierStorage, config.IdentifierStoragePlaintext)
+			identifier := x.NewUUID().String()
+			createIdentity(ctx, reg, t, identifier, pwd)
+			conf.MustSet(ctx, config.ViperKeySelfServiceIdentifierStorage, config.IdentifierStorageHashed)
+
+			body := login(t, identifier, pwd)
+			assert.Equal(t, identifier, gjson.Get(body, "identity.traits.subject").String(), "%s", body)
+
+			err := reg.PrivilegedIdentityPool().CreateIdentity(ctx, &identity.Identity{
+				Traits: identity.Traits(fmt.Sprintf(`{"subject":"%s"}`, identifier)),
+				Credentials: map[identity.CredentialsType]identity.Credentials{
+					identity.CredentialsTypePassword: {Type: identity.CredentialsTypePassword, Identifiers: []string{identifier}},
+				},
+			})
+			assert.ErrorIs(t, err, sqlcon.ErrUniqueViolation, "a plaintext identifier must still be unique")
+		})
+
+		t.Run("case=previous secrets are tried on lookup", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySecretsDefault, []string{"a-new-and-very-secure-session-key", "not-a-secure-session-key"})
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeySecretsDefault, []string{"not-a-secure-session-key"})
+			})
+
+			body := login(t, identifier, pwd)
+			assert.Equal(t, identifier, gjson.Get(body, "identity.traits.subject").String(), "%s", body)
+
+			is, _, err := reg.PrivilegedIdentityPool().ListIdentities(ctx, identity.ListIdentityParameters{CredentialsIdentifier: identifier})
+			require.NoError(t, err)
+			require.Len(t, is, 1)
+			assert.Equal(t, identifier, gjson.GetBytes([]byte(is[0].Traits), "subject").String())
+
+			err = reg.PrivilegedIdentityPool().CreateIdentity(ctx, &identity.Identity{
+				Traits: identity.Traits(fmt.Sprintf(`{"subject":"%s"}`, identifier)),
+				Credentials: map[identity.CredentialsType]identity.Credentials{
+					identity.CredentialsTypePassword: {Type: identity.CredentialsTypePassword, Identifiers: []string{identifier}},
+				},
+			})
+			assert.ErrorIs(t, err, sqlcon.ErrUniqueViolation, "an identifier hashed with a previous secret must still be unique")
+		})
+
+		t.Run("case=similarity search is rejected", func(t *testing.T) {
+			_, _, err := reg.PrivilegedIdentityPool().ListIdentities(ctx, identity.ListIdentityParameters{CredentialsIdentifierSimilar: identifier})
+			var he *herodot.DefaultError
+			require.ErrorAs(t, err, &he)
+			assert.Equal(t, http.StatusBadRequest, he.CodeField)
+		})
+	})
+
+	t.Run("case=emits flow spans", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		previous := reg.Tracer(ctx)
+		reg.SetTracer(previous.WithOTLP(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")))
+		t.Cleanup(func() {
+			reg.SetTracer(previous)
+		})
+
+		identifier, pwd := x.NewUUID().String(), "password"
+		createIdentity(ctx, reg, t, identifier, pwd)
+
+		submit := func(t *testing.T, pwd string) (*kratos.LoginFlow, sdktrace.ReadOnlySpan) {
+			browserClient := testhelpers.NewClientWithCookies(t)
+			f := testhelpers.InitializeLoginFlowViaBrowser(t, browserClient, publicTS, false, false, false, false)
+
+			values := url.Values{"method": {"password"}, "identifier": {identifier}, "password": {pwd}, "csrf_token": {x.FakeCSRFToken}}.Encode()
+			_, _ = testhelpers.LoginMakeRequest(t, false, false, f, browserClient, values)
+
+			var span sdktrace.ReadOnlySpan
+			for _, s := range recorder.Ended() {
+				if s.Name() == "selfservice.flow.login.Handler.updateLoginFlow" {
+					span = s
+				}
+			}
+			require.NotNil(t, span, "the login submission must be traced")
+			return f, span
+		}
+
+		f, span := submit(t, pwd)
+		attributes := map[string]string{}
+		for _, a := range span.Attributes() {
+			attributes[string(a.Key)] = a.Value.AsString()
+		}
+		assert.Equal(t, f.Id, attributes[flow.AttributeKeyFlowID])
+		assert.Equal(t, string(flow.TypeBrowser), attributes[flow.AttributeKeyFlowType])
+		assert.Equal(t, identity.CredentialsTypePassword.String(), attributes[flow.AttributeKeyFlowStrategy])
+		assert.Equal(t, codes.Unset, span.Status().Code)
+
+		_, span = submit(t, "not-"+pwd)
+		assert.Equal(t, codes.Error, span.Status().Code, "a failed submission must be recorded on the span")
+	})
+
 	t.Run("should fail as email is not yet verified", func(t *testing.T) {
 		conf.MustSet(ctx, config.ViperKeySelfServiceLoginAfter+".password.hooks", []map[string]interface{}{
 			{"hook": "require_verified_address"},
@@ -865,3 +1179,58 @@ func TestCompleteLogin(t *testing.T) {
 		assert.Equal(t, identifier, gjson.Get(body, "identity.traits.subject").String(), "%s", body)
 	})
 }
+
+func TestReadOnlyMaintenanceMode(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+string(identity.CredentialsTypePassword),
+		map[string]interface{}{"enabled": true})
+	publicTS, adminTS := testhelpers.NewKratosServerWithCSRF(t, reg)
+	testhelpers.SetDefaultIdentitySchemaFromRaw(conf, loginSchema)
+	conf.MustSet(ctx, config.ViperKeySecretsDefault, []string{"not-a-secure-session-key"})
+
+	identifier, pwd := x.NewUUID().String(), "password"
+	createIdentity(ctx, reg, t, identifier, pwd)
+
+	conf.MustSet(ctx, config.ViperKeyMaintenanceReadOnly, true)
+	t.Cleanup(func() {
+		conf.MustSet(ctx, config.ViperKeyMaintenanceReadOnly, nil)
+	})
+
+	apiClient := testhelpers.NewDebugClient(t)
+
+	t.Run("case=registration is rejected", func(t *testing.T) {
+		res, err := apiClient.Get(publicTS.URL + registration.RouteInitAPIFlow)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body := ioutilx.MustReadAll(res.Body)
+
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode, "%s", body)
+		assert.Equal(t, text.ErrIDMaintenanceReadOnly, gjson.GetBytes(body, "error.id").String(), "%s", body)
+	})
+
+	t.Run("case=admin writes are rejected", func(t *testing.T) {
+		res, err := apiClient.Post(adminTS.URL+"/admin/identities", "application/json", strings.NewReader(`{"traits":{"subject":"foo"}}`))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	})
+
+	t.Run("case=login and whoami succeed", func(t *testing.T) {
+		f := testhelpers.InitializeLoginFlowViaAPI(t, apiClient, publicTS, false)
+		body, res := testhelpers.LoginMakeRequest(t, true, false, f, apiClient,
+			fmt.Sprintf(`{"method":"password","identifier":"%s","password":"%s"}`, identifier, pwd))
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+
+		token := gjson.Get(body, "session_token").String()
+		require.NotEmpty(t, token, "%s", body)
+
+		req, err := http.NewRequest("GET", publicTS.URL+session.RouteWhoami, nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Session-Token", token)
+		res, err = apiClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+}
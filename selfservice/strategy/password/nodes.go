@@ -4,6 +4,7 @@
 package password
 
 import (
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/ui/node"
 )
@@ -17,3 +18,15 @@ func NewPasswordNode(name string, autocomplete node.UiNodeInputAttributeAutocomp
 		})).
 		WithMetaLabel(text.NewInfoNodeInputPassword())
 }
+
+// NewPasswordNodeWithPolicy returns the password node for setting a new
+// password. Its meta contains the password policy so that UIs can render the
+// requirements upfront.
+func NewPasswordNodeWithPolicy(name string, policy *config.PasswordPolicy) *node.Node {
+	return NewPasswordNode(name, node.InputAttributeAutocompleteNewPassword).
+		WithMetaPasswordPolicy(&node.PasswordPolicy{
+			MinLength:                        policy.MinPasswordLength,
+			BreachCheckEnabled:               policy.HaveIBeenPwnedEnabled,
+			IdentifierSimilarityCheckEnabled: policy.IdentifierSimilarityCheckEnabled,
+		})
+}
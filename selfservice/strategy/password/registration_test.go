@@ -6,6 +6,7 @@ package password_test
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +15,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gofrs/uuid"
+
 	"github.com/ory/kratos/driver"
 	"github.com/ory/kratos/internal/registrationhelpers"
 
@@ -21,6 +24,7 @@ import (
 	"github.com/ory/kratos/ui/node"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
 
 	"github.com/ory/kratos/ui/container"
@@ -31,6 +35,7 @@ import (
 	"github.com/ory/kratos/internal/testhelpers"
 	"github.com/ory/kratos/selfservice/flow/registration"
 	"github.com/ory/x/assertx"
+	"github.com/ory/x/ioutilx"
 
 	"github.com/ory/kratos/x"
 )
@@ -610,6 +615,118 @@ func TestRegistration(t *testing.T) {
 			assert.Equal(t, username.String(), gjson.Get(actual, "identity.traits.username").String(), "%s", actual)
 		})
 
+		t.Run("case=should include addresses in the response if enabled", func(t *testing.T) {
+			testhelpers.SetDefaultIdentitySchema(conf, "file://stub/registration.addresses.schema.json")
+			conf.MustSet(ctx, config.HookStrategyKey(config.ViperKeySelfServiceRegistrationAfter, identity.CredentialsTypePassword.String()), []config.SelfServiceHook{{Name: "session"}})
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.HookStrategyKey(config.ViperKeySelfServiceRegistrationAfter, identity.CredentialsTypePassword.String()), nil)
+				conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationIncludeAddresses, nil)
+			})
+
+			register := func(t *testing.T) (string, string) {
+				email := testhelpers.RandomEmail()
+				payload := testhelpers.InitializeRegistrationFlowViaAPI(t, apiClient, publicTS)
+				actual, res := testhelpers.RegistrationMakeRequest(t, true, false, payload, apiClient, fmt.Sprintf(`{
+  "method": "password",
+  "password": "%s",
+  "traits": {
+    "email": "%s"
+  }
+}`, x.NewUUID(), email))
+				require.EqualValues(t, http.StatusOK, res.StatusCode, assertx.PrettifyJSONPayload(t, actual))
+				return email, actual
+			}
+
+			t.Run("case=disabled", func(t *testing.T) {
+				_, actual := register(t)
+				assert.False(t, gjson.Get(actual, "verifiable_addresses").Exists(), "%s", actual)
+				assert.False(t, gjson.Get(actual, "recovery_addresses").Exists(), "%s", actual)
+			})
+
+			t.Run("case=enabled", func(t *testing.T) {
+				conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationIncludeAddresses, true)
+
+				email, actual := register(t)
+				assert.Equal(t, email, gjson.Get(actual, "verifiable_addresses.0.value").String(), "%s", actual)
+				assert.False(t, gjson.Get(actual, "verifiable_addresses.0.verified").Bool(), "%s", actual)
+				assert.Equal(t, "email", gjson.Get(actual, "verifiable_addresses.0.via").String(), "%s", actual)
+				assert.NotEmpty(t, gjson.Get(actual, "verifiable_addresses.0.status").String(), "%s", actual)
+				assert.Equal(t, email, gjson.Get(actual, "recovery_addresses.0.value").String(), "%s", actual)
+			})
+		})
+
+		t.Run("case=should require consent if enabled", func(t *testing.T) {
+			testhelpers.SetDefaultIdentitySchema(conf, "file://stub/registration.schema.json")
+			conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationRequireConsent, map[string]interface{}{
+				"enabled": true,
+				"label":   "I agree to the terms",
+				"version": "2024-01-01",
+			})
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationRequireConsent, nil)
+			})
+
+			register := func(t *testing.T, consent string) (string, *http.Response) {
+				payload := testhelpers.InitializeRegistrationFlowViaAPI(t, apiClient, publicTS)
+				consentNode := gjson.Get(x.MustEncodeJSON(t, payload.Ui.Nodes), `#(attributes.name=="consent")`)
+				require.True(t, consentNode.Exists(), "%+v", payload.Ui.Nodes)
+				assert.Equal(t, "checkbox", consentNode.Get("attributes.type").String())
+				assert.True(t, consentNode.Get("attributes.required").Bool())
+				assert.Equal(t, "I agree to the terms", consentNode.Get("meta.label.text").String())
+
+				return testhelpers.RegistrationMakeRequest(t, true, false, payload, apiClient, fmt.Sprintf(`{
+  "method": "password",
+  "password": "%s",
+  %s
+  "traits": {
+    "foobar": "bar",
+    "username": "%s"
+  }
+}`, x.NewUUID(), consent, x.NewUUID()))
+			}
+
+			t.Run("case=fails without consent", func(t *testing.T) {
+				actual, res := register(t, "")
+				assert.EqualValues(t, http.StatusBadRequest, res.StatusCode, assertx.PrettifyJSONPayload(t, actual))
+				assert.EqualValues(t, text.ErrorValidationRequired, gjson.Get(actual, `ui.nodes.#(attributes.name=="consent").messages.0.id`).Int(), "%s", actual)
+			})
+
+			t.Run("case=records consent version", func(t *testing.T) {
+				actual, res := register(t, `"consent": true,`)
+				require.EqualValues(t, http.StatusOK, res.StatusCode, assertx.PrettifyJSONPayload(t, actual))
+
+				i, err := reg.PrivilegedIdentityPool().GetIdentity(ctx, uuid.FromStringOrNil(gjson.Get(actual, "identity.id").String()), identity.ExpandNothing)
+				require.NoError(t, err)
+				assert.Equal(t, "2024-01-01", gjson.GetBytes(i.MetadataAdmin, "consent.version").String(), "%s", i.MetadataAdmin)
+				assert.True(t, gjson.GetBytes(i.MetadataAdmin, "consent.given_at").Exists(), "%s", i.MetadataAdmin)
+			})
+
+			t.Run("case=records consent from forms", func(t *testing.T) {
+				payload := testhelpers.InitializeRegistrationFlowViaAPI(t, apiClient, publicTS)
+
+				values := url.Values{
+					"method":          {"password"},
+					"password":        {x.NewUUID().String()},
+					"consent":         {"true"},
+					"traits.foobar":   {"bar"},
+					"traits.username": {x.NewUUID().String()},
+				}
+				req, err := http.NewRequest("POST", payload.Ui.Action, strings.NewReader(values.Encode()))
+				require.NoError(t, err)
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.Header.Set("Accept", "application/json")
+				res, err := apiClient.Do(req)
+				require.NoError(t, err)
+				defer res.Body.Close()
+				actual := string(ioutilx.MustReadAll(res.Body))
+				require.EqualValues(t, http.StatusOK, res.StatusCode, assertx.PrettifyJSONPayload(t, actual))
+
+				i, err := reg.PrivilegedIdentityPool().GetIdentity(ctx, uuid.FromStringOrNil(gjson.Get(actual, "identity.id").String()), identity.ExpandNothing)
+				require.NoError(t, err)
+				assert.Equal(t, "2024-01-01", gjson.GetBytes(i.MetadataAdmin, "consent.version").String(), "%s", i.MetadataAdmin)
+			})
+		})
+
 		t.Run("case=should choose the correct identity schema", func(t *testing.T) {
 			conf.MustSet(ctx, config.ViperKeyDefaultIdentitySchemaID, "advanced-user")
 			conf.MustSet(ctx, config.ViperKeyIdentitySchemas, config.Schemas{
@@ -671,10 +788,37 @@ func TestRegistration(t *testing.T) {
 				node.NewInputField("traits.username", nil, node.PasswordGroup, node.InputAttributeTypeText),
 				node.NewInputField("password", nil, node.PasswordGroup, node.InputAttributeTypePassword, node.WithRequiredInputAttribute, node.WithInputAttributes(func(a *node.InputAttributes) {
 					a.Autocomplete = node.InputAttributeAutocompleteNewPassword
-				})).WithMetaLabel(text.NewInfoNodeInputPassword()),
+				})).WithMetaLabel(text.NewInfoNodeInputPassword()).WithMetaPasswordPolicy(&node.PasswordPolicy{
+					MinLength:                        8,
+					BreachCheckEnabled:               true,
+					IdentifierSimilarityCheckEnabled: true,
+				}),
 				node.NewInputField("traits.bar", nil, node.PasswordGroup, node.InputAttributeTypeText),
 				node.NewInputField("method", "password", node.PasswordGroup, node.InputAttributeTypeSubmit).WithMetaLabel(text.NewInfoRegistration()),
 			},
 		}, f.Ui)
+
+		t.Run("case=password policy reflects config", func(t *testing.T) {
+			for key, value := range map[string]interface{}{
+				config.ViperKeyPasswordMinLength:                        12,
+				config.ViperKeyPasswordHaveIBeenPwnedEnabled:            false,
+				config.ViperKeyPasswordIdentifierSimilarityCheckEnabled: false,
+			} {
+				key, previous := key, conf.GetProvider(ctx).Get(key)
+				conf.MustSet(ctx, key, value)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, key, previous)
+				})
+			}
+
+			f := testhelpers.InitializeRegistrationFlowViaBrowser(t, testhelpers.NewClientWithCookies(t), publicTS, false, false, false)
+			nodes, err := json.Marshal(f.Ui.Nodes)
+			require.NoError(t, err)
+
+			policy := gjson.GetBytes(nodes, "#(attributes.name==password).meta.password_policy")
+			assert.EqualValues(t, 12, policy.Get("min_length").Int(), "%s", nodes)
+			assert.False(t, policy.Get("breach_check_enabled").Bool(), "%s", nodes)
+			assert.False(t, policy.Get("identifier_similarity_check_enabled").Bool(), "%s", nodes)
+		})
 	})
 }
@@ -27,6 +27,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
@@ -34,6 +35,7 @@ import (
 	"github.com/ory/kratos/internal/testhelpers"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/x"
 	"github.com/ory/x/assertx"
 	"github.com/ory/x/httpx"
@@ -486,6 +488,104 @@ func TestStrategyTraits(t *testing.T) {
 		})
 	})
 
+	t.Run("description=optimistic concurrency rejects stale submissions", func(t *testing.T) {
+		setPrivileged(t)
+		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsOptimisticConcurrency, true)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsOptimisticConcurrency, nil)
+		})
+
+		id := newIdentityWithPassword("john-optimistic-concurrency@doe.com")
+		apiUser := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
+
+		submitIfUnmodifiedSince := func(t *testing.T, f *kratos.SettingsFlow, since string) (string, *http.Response) {
+			values := testhelpers.SDKFormFieldsToURLValues(f.Ui.Nodes)
+			values.Set("method", settings.StrategyProfile)
+			values.Set("traits.numby", "16")
+
+			req := testhelpers.NewRequest(t, true, "POST", f.Ui.Action, bytes.NewBufferString(testhelpers.EncodeFormAsJSON(t, true, values)))
+			req.Header.Set("Accept", "application/json")
+			if since != "" {
+				req.Header.Set("If-Unmodified-Since", since)
+			}
+
+			res, err := apiUser.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			return string(body), res
+		}
+		submit := func(t *testing.T, f *kratos.SettingsFlow) (string, *http.Response) {
+			return submitIfUnmodifiedSince(t, f, "")
+		}
+
+		staleFlow := testhelpers.InitializeSettingsFlowViaAPI(t, apiUser, publicTS)
+
+		// The identity is changed out-of-band after the flow was loaded.
+		changed, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id.ID)
+		require.NoError(t, err)
+		changed.Traits = identity.Traits(`{"email":"john-optimistic-concurrency@doe.com","stringy":"changed","booly":false,"numby":2.5,"should_long_string":"asdfasdfasdfasdfasfdasdfasdfasdf","should_big_number":2048}`)
+		require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(ctx, changed))
+
+		actual, res := submit(t, staleFlow)
+		assert.Equal(t, http.StatusConflict, res.StatusCode, "%s", actual)
+		assert.Equal(t, text.ErrIDSelfServiceSettingsConflict, gjson.Get(actual, "error.id").String(), "%s", actual)
+
+		freshFlow := testhelpers.InitializeSettingsFlowViaAPI(t, apiUser, publicTS)
+		actual, res = submit(t, freshFlow)
+		assert.Equal(t, http.StatusOK, res.StatusCode, "%s", actual)
+		assert.EqualValues(t, flow.StateSuccess, gjson.Get(actual, "state").String(), "%s", actual)
+
+		// Submitting the same flow again is not a conflict, as the flow was
+		// updated along with the identity.
+		actual, res = submit(t, freshFlow)
+		assert.Equal(t, http.StatusOK, res.StatusCode, "%s", actual)
+		assert.EqualValues(t, flow.StateSuccess, gjson.Get(actual, "state").String(), "%s", actual)
+
+		t.Run("case=stale If-Unmodified-Since header", func(t *testing.T) {
+			f := testhelpers.InitializeSettingsFlowViaAPI(t, apiUser, publicTS)
+			actual, res := submitIfUnmodifiedSince(t, f, time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+			assert.Equal(t, http.StatusConflict, res.StatusCode, "%s", actual)
+			assert.Equal(t, text.ErrIDSelfServiceSettingsConflict, gjson.Get(actual, "error.id").String(), "%s", actual)
+		})
+
+		t.Run("case=current If-Unmodified-Since header takes precedence over the flow", func(t *testing.T) {
+			actual, res := submitIfUnmodifiedSince(t, staleFlow, time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+			assert.Equal(t, http.StatusOK, res.StatusCode, "%s", actual)
+			assert.EqualValues(t, flow.StateSuccess, gjson.Get(actual, "state").String(), "%s", actual)
+		})
+
+		t.Run("case=malformed If-Unmodified-Since header", func(t *testing.T) {
+			f := testhelpers.InitializeSettingsFlowViaAPI(t, apiUser, publicTS)
+			actual, res := submitIfUnmodifiedSince(t, f, "not a date")
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", actual)
+			assert.Contains(t, gjson.Get(actual, "ui.messages.0.text").String(), "If-Unmodified-Since", "%s", actual)
+		})
+	})
+
+	t.Run("description=returns changed traits", func(t *testing.T) {
+		setPrivileged(t)
+		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsReturnChangedTraits, true)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsReturnChangedTraits, nil)
+		})
+
+		id := newIdentityWithPassword("john-changed-traits@doe.com")
+		apiUser := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
+
+		f := testhelpers.InitializeSettingsFlowViaAPI(t, apiUser, publicTS)
+		traits, err := sjson.Set(string(id.Traits), "numby", 16)
+		require.NoError(t, err)
+		payload, err := sjson.SetRaw(`{"method":"profile"}`, "traits", traits)
+		require.NoError(t, err)
+
+		actual, res := testhelpers.SettingsMakeRequest(t, true, false, f, apiUser, payload)
+		assert.Equal(t, http.StatusOK, res.StatusCode, "%s", actual)
+		assert.EqualValues(t, flow.StateSuccess, gjson.Get(actual, "state").String(), "%s", actual)
+		assert.Equal(t, `["/traits/numby"]`, gjson.Get(actual, "changed_traits").Raw, "%s", actual)
+	})
+
 	t.Run("description=ensure that hooks are running", func(t *testing.T) {
 		setPrivileged(t)
 
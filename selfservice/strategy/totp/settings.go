@@ -281,13 +281,14 @@ func (s *Strategy) PopulateSettingsMethod(r *http.Request, id *identity.Identity
 			return err
 		}
 
-		qr, err := NewTOTPImageQRNode(key)
-		if err != nil {
-			return err
-		}
-
 		f.UI.Nodes.Upsert(NewTOTPSourceURLNode(key))
-		f.UI.Nodes.Upsert(qr)
+		if s.d.Config().TOTPEmitQRDataURI(r.Context()) {
+			qr, err := NewTOTPImageQRNode(key)
+			if err != nil {
+				return err
+			}
+			f.UI.Nodes.Upsert(qr)
+		}
 		f.UI.Nodes.Upsert(NewVerifyTOTPNode())
 		f.UI.Nodes.Append(node.NewInputField("method", "totp", node.TOTPGroup, node.InputAttributeTypeSubmit).WithMetaLabel(text.NewInfoNodeLabelSave()))
 	}
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -60,6 +61,7 @@ func TestCompleteSettings(t *testing.T) {
 
 	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/settings.schema.json")
 	conf.MustSet(ctx, config.ViperKeySecretsDefault, []string{"not-a-secure-session-key"})
+	conf.MustSet(ctx, config.ViperKeyTOTPEmitQRDataURI, true)
 
 	t.Run("case=device unlinking is available when identity has totp", func(t *testing.T) {
 		id, _, _ := createIdentity(t, reg)
@@ -86,6 +88,38 @@ func TestCompleteSettings(t *testing.T) {
 		})
 	})
 
+	t.Run("case=qr code data uri is only emitted when enabled", func(t *testing.T) {
+		id, _, _ := createIdentity(t, reg)
+		id.Credentials = nil
+		require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(context.Background(), id))
+		apiClient := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyTOTPEmitQRDataURI, true)
+		})
+
+		for _, tc := range []struct {
+			name    string
+			value   bool
+			enabled bool
+		}{
+			{name: "enabled", value: true, enabled: true},
+			{name: "disabled", value: false, enabled: false},
+		} {
+			t.Run("case="+tc.name, func(t *testing.T) {
+				conf.MustSet(ctx, config.ViperKeyTOTPEmitQRDataURI, tc.value)
+
+				f := testhelpers.InitializeSettingsFlowViaAPI(t, apiClient, publicTS)
+				nodes, err := json.Marshal(f.Ui.Nodes)
+				require.NoError(t, err)
+				qr := gjson.GetBytes(nodes, `#(attributes.id=="totp_qr")`)
+				assert.Equal(t, tc.enabled, qr.Exists(), "%s", nodes)
+				if tc.enabled {
+					assert.True(t, strings.HasPrefix(qr.Get("attributes.src").String(), "data:image/png;base64,"))
+				}
+			})
+		}
+	})
+
 	doAPIFlow := func(t *testing.T, v func(url.Values), id *identity.Identity) (string, *http.Response) {
 		apiClient := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
 		f := testhelpers.InitializeSettingsFlowViaAPI(t, apiClient, publicTS)
@@ -0,0 +1,175 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package webauthn
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/fetcher"
+)
+
+var attestationMetadataCache, _ = ristretto.NewCache(&ristretto.Config{
+	MaxCost:     100 << 20, // 100MB
+	NumCounters: 1_000,
+	BufferItems: 64,
+})
+
+// ErrAttestationNotVerified is returned if attestation verification is enabled and the
+// attestation of a new credential can not be verified.
+var ErrAttestationNotVerified = herodot.ErrBadRequest.WithError("attestation not verified").
+	WithReason("The attestation of the security key could not be verified. Please use a different security key.")
+
+// attestationMetadata is the subset of a FIDO Metadata Service BLOB payload used to verify authenticators.
+type attestationMetadata struct {
+	Entries []attestationMetadataEntry `json:"entries"`
+}
+
+type attestationMetadataEntry struct {
+	AAGUID            string `json:"aaguid"`
+	MetadataStatement struct {
+		AttestationRootCertificates []string `json:"attestationRootCertificates"`
+	} `json:"metadataStatement"`
+	StatusReports []struct {
+		Status        metadata.AuthenticatorStatus `json:"status"`
+		EffectiveDate string                       `json:"effectiveDate"`
+	} `json:"statusReports"`
+}
+
+// latestStatus returns the status of the most recent status report. Earlier reports are superseded
+// by later ones, so only the latest one decides whether the authenticator is still trusted.
+func (e *attestationMetadataEntry) latestStatus() (status metadata.AuthenticatorStatus, found bool) {
+	var effective string
+	for _, report := range e.StatusReports {
+		// Effective dates are ISO 8601 dates, so they sort lexicographically. Reports without a
+		// date or with the same date are assumed to be listed in chronological order.
+		if !found || report.EffectiveDate >= effective {
+			status, effective, found = report.Status, report.EffectiveDate, true
+		}
+	}
+	return status, found
+}
+
+// verifyChain checks that the attestation certificate chains up to one of the attestation root
+// certificates listed for the authenticator.
+func (e *attestationMetadataEntry) verifyChain(chain []*x509.Certificate) error {
+	roots := x509.NewCertPool()
+	for _, raw := range e.MetadataStatement.AttestationRootCertificates {
+		der, err := base64.StdEncoding.DecodeString(raw)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		root, err := x509.ParseCertificate(der)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		roots.AddCert(root)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return errors.WithStack(err)
+}
+
+// attestationCertificates returns the attestation certificate chain (x5c) of the attestation statement.
+// Self attestation does not convey a certificate chain.
+func attestationCertificates(statement map[string]any) ([]*x509.Certificate, error) {
+	x5c, _ := statement["x5c"].([]any)
+	chain := make([]*x509.Certificate, 0, len(x5c))
+	for _, raw := range x5c {
+		der, ok := raw.([]byte)
+		if !ok {
+			return nil, errors.Errorf("expected attestation certificate to be bytes but got %T", raw)
+		}
+		c, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		chain = append(chain, c)
+	}
+	return chain, nil
+}
+
+// verifyAttestation enforces the attestation verification settings on a new credential. The
+// attestation statement itself was already verified when the credential was created, unless the
+// authenticator did not convey one. Self attestation is signed by the credential key itself and
+// proves nothing about the authenticator, so it is rejected as well. Without a metadata URL, nothing
+// else is checked and any attestation certificate chain is accepted.
+//
+// If a metadata URL is configured, the authenticator must be listed there, its latest status report
+// must not be undesired, and its attestation certificate must chain to one of the listed roots. The
+// metadata is trusted as-is: the signature of the FIDO MDS BLOB is not checked here.
+func (s *Strategy) verifyAttestation(ctx context.Context, credential *webauthn.Credential, response *protocol.ParsedCredentialCreationData) error {
+	conf := s.d.Config().WebAuthnVerifyAttestation(ctx)
+	if !conf.Enabled {
+		return nil
+	}
+
+	if credential.AttestationType == "" || credential.AttestationType == "none" {
+		return errors.WithStack(ErrAttestationNotVerified.WithDetail("reason", "no attestation statement was conveyed"))
+	}
+
+	chain, err := attestationCertificates(response.Response.AttestationObject.AttStatement)
+	if err != nil {
+		return errors.WithStack(ErrAttestationNotVerified.WithWrap(err))
+	} else if len(chain) == 0 {
+		return errors.WithStack(ErrAttestationNotVerified.WithDetail("reason", "self attestation is not accepted"))
+	}
+
+	if conf.MetadataURL == "" {
+		return nil
+	}
+
+	aaguid, err := uuid.FromBytes(credential.Authenticator.AAGUID)
+	if err != nil {
+		return errors.WithStack(ErrAttestationNotVerified.WithWrap(err))
+	}
+
+	fetch := fetcher.NewFetcher(fetcher.WithClient(s.d.HTTPClient(ctx)), fetcher.WithCache(attestationMetadataCache, 60*time.Minute))
+	raw, err := fetch.FetchContext(ctx, conf.MetadataURL)
+	if err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to fetch the FIDO metadata.").WithWrap(err))
+	}
+
+	var md attestationMetadata
+	if err := json.NewDecoder(raw).Decode(&md); err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to decode the FIDO metadata.").WithWrap(err))
+	}
+
+	for _, entry := range md.Entries {
+		if uuid.FromStringOrNil(entry.AAGUID) != aaguid {
+			continue
+		}
+
+		if status, ok := entry.latestStatus(); ok && metadata.IsUndesiredAuthenticatorStatus(status) {
+			return errors.WithStack(ErrAttestationNotVerified.WithDetailf("reason", "authenticator %s has status %s", aaguid, status))
+		}
+
+		if err := entry.verifyChain(chain); err != nil {
+			return errors.WithStack(ErrAttestationNotVerified.WithDetailf("reason", "attestation certificate of authenticator %s is not trusted", aaguid).WithWrap(err))
+		}
+		return nil
+	}
+
+	return errors.WithStack(ErrAttestationNotVerified.WithDetailf("reason", "authenticator %s is not listed in the FIDO metadata", aaguid))
+}
@@ -4,6 +4,8 @@
 package webauthn
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"strings"
@@ -27,6 +29,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/selfservice/flow"
@@ -113,6 +116,43 @@ func (s *Strategy) populateLoginMethodForPasswordless(r *http.Request, sr *login
 		func(attributes *node.InputAttributes) { attributes.Autocomplete = "username webauthn" },
 	).WithMetaLabel(identifierLabel))
 	sr.UI.GetNodes().Append(node.NewInputField("method", "webauthn", node.WebAuthnGroup, node.InputAttributeTypeSubmit).WithMetaLabel(text.NewInfoSelfServiceLoginWebAuthn()))
+	return s.populateLoginMethodForConditionalMediation(r, sr)
+}
+
+// populateLoginMethodForConditionalMediation starts a discoverable login, which browsers
+// offer as autofill of the identifier input.
+func (s *Strategy) populateLoginMethodForConditionalMediation(r *http.Request, sr *login.Flow) error {
+	web, err := webauthn.New(s.webAuthnConfig(r))
+	if err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to initiate WebAuth.").WithDebug(err.Error()))
+	}
+
+	options, sessionData, err := web.BeginDiscoverableLogin()
+	if err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to initiate WebAuth login.").WithDebug(err.Error()))
+	}
+
+	sr.InternalContext, err = sjson.SetBytes(sr.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeySessionData), sessionData)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	sr.InternalContext, err = sjson.SetBytes(sr.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeyChallengeIssuedAt), time.Now().UTC())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	injectWebAuthnOptions, err := json.Marshal(struct {
+		*protocol.CredentialAssertion
+		Mediation string `json:"mediation"`
+	}{CredentialAssertion: options, Mediation: "conditional"})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	sr.UI.Nodes.Upsert(webauthnx.NewWebAuthnScript(s.d.Config().SelfPublicURL(r.Context())))
+	sr.UI.Nodes.Upsert(webauthnx.NewWebAuthnLoginChallenge(string(injectWebAuthnOptions), s.d.Config().WebAuthnUseExternalScript(r.Context())))
+	sr.UI.Nodes.Upsert(webauthnx.NewWebAuthnLoginInput())
 	return nil
 }
 
@@ -133,9 +173,15 @@ func (s *Strategy) populateLoginMethod(r *http.Request, sr *login.Flow, i *ident
 		return errors.WithStack(err)
 	}
 
-	webAuthCreds := conf.Credentials.ToWebAuthn()
+	credentials := conf.Credentials
+	maxAllowCredentials := s.d.Config().WebAuthnMaxAllowCredentials(r.Context())
+	if maxAllowCredentials > 0 {
+		credentials = credentials.SortedByLastUse()
+	}
+
+	webAuthCreds := credentials.ToWebAuthn()
 	if !sr.IsForced() {
-		webAuthCreds = conf.Credentials.ToWebAuthnFiltered(aal)
+		webAuthCreds = credentials.ToWebAuthnFiltered(aal)
 	}
 
 	if len(webAuthCreds) == 0 {
@@ -143,22 +189,39 @@ func (s *Strategy) populateLoginMethod(r *http.Request, sr *login.Flow, i *ident
 		return webauthnx.ErrNoCredentials
 	}
 
-	web, err := webauthn.New(s.d.Config().WebAuthnConfig(r.Context()))
+	web, err := webauthn.New(s.webAuthnConfig(r))
 	if err != nil {
 		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to initiate WebAuth.").WithDebug(err.Error()))
 	}
 
-	options, sessionData, err := web.BeginLogin(webauthnx.NewUser(conf.UserHandle, webAuthCreds, web.Config))
+	var loginOpts []webauthn.LoginOption
+	if appID := s.d.Config().WebAuthnLegacyAppID(r.Context()); appID != nil {
+		// Allows credentials registered with the legacy FIDO U2F API to be used.
+		loginOpts = append(loginOpts, webauthn.WithAssertionExtensions(protocol.AuthenticationExtensions{"appid": appID.String()}))
+	}
+
+	options, sessionData, err := web.BeginLogin(webauthnx.NewUser(conf.UserHandle, webAuthCreds, web.Config), loginOpts...)
 	if err != nil {
 		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to initiate WebAuth login.").WithDebug(err.Error()))
 	}
 
+	if maxAllowCredentials > 0 && len(options.Response.AllowedCredentials) > maxAllowCredentials {
+		// Keeps the challenge small for identities with many credentials. The session
+		// data still allows every credential, so that older keys keep working.
+		options.Response.AllowedCredentials = options.Response.AllowedCredentials[:maxAllowCredentials]
+	}
+
 	// Remove the WebAuthn URL from the internal context now that it is set!
 	sr.InternalContext, err = sjson.SetBytes(sr.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeySessionData), sessionData)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	sr.InternalContext, err = sjson.SetBytes(sr.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeyChallengeIssuedAt), time.Now().UTC())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	injectWebAuthnOptions, err := json.Marshal(options)
 	if err != nil {
 		return errors.WithStack(err)
@@ -170,13 +233,20 @@ func (s *Strategy) populateLoginMethod(r *http.Request, sr *login.Flow, i *ident
 
 	sr.UI.SetCSRF(s.d.GenerateCSRFToken(r))
 	sr.UI.Nodes.Upsert(webauthnx.NewWebAuthnScript(s.d.Config().SelfPublicURL(r.Context())))
-	sr.UI.SetNode(webauthnx.NewWebAuthnLoginTrigger(string(injectWebAuthnOptions)).
+	sr.UI.SetNode(webauthnx.NewWebAuthnLoginTrigger(string(injectWebAuthnOptions), s.d.Config().WebAuthnUseExternalScript(r.Context())).
 		WithMetaLabel(label))
 	sr.UI.Nodes.Upsert(webauthnx.NewWebAuthnLoginInput())
 
 	return nil
 }
 
+// isDiscoverableLogin returns true if the login was started without knowing the identity,
+// which is the case for conditional mediation.
+func (s *Strategy) isDiscoverableLogin(f *login.Flow) bool {
+	sessionData := gjson.GetBytes(f.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeySessionData))
+	return sessionData.IsObject() && sessionData.Get("user_id").String() == "" && len(sessionData.Get("allowed_credentials").Array()) == 0
+}
+
 func (s *Strategy) handleLoginError(r *http.Request, f *login.Flow, err error) error {
 	if f != nil {
 		f.UI.Nodes.ResetNodes("webauth_login")
@@ -261,6 +331,25 @@ func (s *Strategy) loginPasswordless(w http.ResponseWriter, r *http.Request, f *
 		return nil, s.handleLoginError(r, f, err)
 	}
 
+	if len(p.Login) > 0 && s.isDiscoverableLogin(f) {
+		// The credential was chosen from the autofill of the identifier input.
+		webAuthnResponse, err := protocol.ParseCredentialRequestResponseBody(strings.NewReader(p.Login))
+		if err != nil {
+			return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to parse WebAuthn response.").WithDebug(err.Error())))
+		}
+
+		if len(webAuthnResponse.Response.UserHandle) == 0 {
+			return nil, s.handleLoginError(r, f, errors.WithStack(schema.NewNoWebAuthnCredentials()))
+		}
+
+		i, err := s.d.PrivilegedIdentityPool().FindIdentityByWebauthnUserHandle(r.Context(), webAuthnResponse.Response.UserHandle)
+		if err != nil {
+			return nil, s.handleLoginError(r, f, errors.WithStack(schema.NewNoWebAuthnCredentials()))
+		}
+
+		return s.loginAuthenticate(w, r, f, i.ID, p, identity.AuthenticatorAssuranceLevel1)
+	}
+
 	if p.Identifier == "" {
 		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrBadRequest.WithReason("identifier is required")))
 	}
@@ -321,7 +410,7 @@ func (s *Strategy) loginAuthenticate(_ http.ResponseWriter, r *http.Request, f *
 		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrInternalServerError.WithReason("The WebAuthn credentials could not be decoded properly").WithDebug(err.Error()).WithWrap(err)))
 	}
 
-	web, err := webauthn.New(s.d.Config().WebAuthnConfig(r.Context()))
+	web, err := webauthn.New(s.webAuthnConfig(r))
 	if err != nil {
 		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to get webAuthn config.").WithDebug(err.Error())))
 	}
@@ -336,21 +425,58 @@ func (s *Strategy) loginAuthenticate(_ http.ResponseWriter, r *http.Request, f *
 		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Expected WebAuthN in internal context to be an object but got: %s", err)))
 	}
 
+	// The challenge may expire before the flow does to limit the window in which an assertion can be replayed.
+	// Challenges without an issue time are treated as expired.
+	if issuedAt := gjson.GetBytes(f.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeyChallengeIssuedAt)).Time(); issuedAt.IsZero() ||
+		time.Since(issuedAt) > s.d.Config().WebAuthnChallengeLifespan(r.Context()) {
+		return nil, s.handleLoginError(r, f, schema.NewWebAuthnChallengeExpiredError())
+	}
+
 	webAuthCreds := o.Credentials.ToWebAuthnFiltered(aal)
 	if f.IsForced() {
 		webAuthCreds = o.Credentials.ToWebAuthn()
 	}
 
-	if _, err := web.ValidateLogin(webauthnx.NewUser(o.UserHandle, webAuthCreds, web.Config), webAuthnSess, webAuthnResponse); err != nil {
+	user := webauthnx.NewUser(o.UserHandle, webAuthCreds, web.Config)
+	var credential *webauthn.Credential
+	if s.isDiscoverableLogin(f) {
+		credential, err = web.ValidateDiscoverableLogin(func(_, _ []byte) (webauthn.User, error) {
+			return user, nil
+		}, webAuthnSess, webAuthnResponse)
+	} else {
+		credential, err = web.ValidateLogin(user, webAuthnSess, webAuthnResponse)
+	}
+	if err != nil {
 		return nil, s.handleLoginError(r, f, errors.WithStack(schema.NewWebAuthnVerifierWrongError("#/")))
 	}
 
+	updated, err := s.checkSignCount(r.Context(), i, &o, credential.ID, webAuthnResponse.Response.AuthenticatorData.Counter)
+	if err != nil {
+		return nil, s.handleLoginError(r, f, err)
+	}
+
+	if s.d.Config().WebAuthnMaxAllowCredentials(r.Context()) > 0 {
+		o.Credentials.MarkUsed(credential.ID, time.Now().UTC().Round(time.Second))
+		updated = true
+	}
+
+	if updated {
+		if err := s.updateCredentials(r.Context(), i, c, &o); err != nil {
+			return nil, s.handleLoginError(r, f, err)
+		}
+	}
+
 	// Remove the WebAuthn URL from the internal context now that it is set!
 	f.InternalContext, err = sjson.DeleteBytes(f.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeySessionData))
 	if err != nil {
 		return nil, s.handleLoginError(r, f, errors.WithStack(err))
 	}
 
+	f.InternalContext, err = sjson.DeleteBytes(f.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeyChallengeIssuedAt))
+	if err != nil {
+		return nil, s.handleLoginError(r, f, errors.WithStack(err))
+	}
+
 	f.Active = s.ID()
 	if err = s.d.LoginFlowPersister().UpdateLoginFlow(r.Context(), f); err != nil {
 		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrInternalServerError.WithReason("Could not update flow").WithDebug(err.Error())))
@@ -359,6 +485,56 @@ func (s *Strategy) loginAuthenticate(_ http.ResponseWriter, r *http.Request, f *
 	return i, nil
 }
 
+// checkSignCount applies the configured sign count policy to the credential
+// used for login and sets the asserted sign count, returning true if it was
+// changed. Authenticators which do not implement a sign count always report
+// zero and are never rejected.
+func (s *Strategy) checkSignCount(ctx context.Context, i *identity.Identity, o *identity.CredentialsWebAuthnConfig, credentialID []byte, signCount uint32) (bool, error) {
+	policy := s.d.Config().WebAuthnSignCountPolicy(ctx)
+	if policy == config.WebAuthnSignCountPolicyIgnore {
+		return false, nil
+	}
+
+	for k := range o.Credentials {
+		stored := &o.Credentials[k]
+		if !bytes.Equal(stored.ID, credentialID) {
+			continue
+		}
+
+		if signCount == 0 && stored.Authenticator.SignCount == 0 {
+			return false, nil
+		}
+
+		if signCount <= stored.Authenticator.SignCount {
+			s.d.Logger().
+				WithField("identity_id", i.ID).
+				WithField("stored_sign_count", stored.Authenticator.SignCount).
+				WithField("asserted_sign_count", signCount).
+				Warn("The WebAuthn sign count did not increase which may indicate a cloned authenticator.")
+			if policy == config.WebAuthnSignCountPolicyReject {
+				return false, errors.WithStack(schema.NewWebAuthnVerifierWrongError("#/"))
+			}
+			return false, nil
+		}
+
+		stored.Authenticator.SignCount = signCount
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (s *Strategy) updateCredentials(ctx context.Context, i *identity.Identity, c *identity.Credentials, o *identity.CredentialsWebAuthnConfig) error {
+	conf, err := json.Marshal(o)
+	if err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReason("Unable to encode WebAuthn credentials.").WithDebug(err.Error()))
+	}
+
+	c.Config = conf
+	i.SetCredentials(s.ID(), *c)
+	return s.d.PrivilegedIdentityPool().UpdateIdentity(ctx, i)
+}
+
 func (s *Strategy) loginMultiFactor(w http.ResponseWriter, r *http.Request, f *login.Flow, identityID uuid.UUID, p *updateLoginFlowWithWebAuthnMethod) (*identity.Identity, error) {
 	if err := login.CheckAAL(f, identity.AuthenticatorAssuranceLevel2); err != nil {
 		return nil, err
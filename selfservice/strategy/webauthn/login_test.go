@@ -6,12 +6,14 @@ package webauthn_test
 import (
 	"context"
 	_ "embed"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/ory/x/jsonx"
 
@@ -28,6 +30,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
@@ -137,6 +140,11 @@ func TestCompleteLogin(t *testing.T) {
 		interim, err := reg.LoginFlowPersister().GetLoginFlow(context.Background(), uuid.FromStringOrNil(f.Id))
 		require.NoError(t, err)
 		interim.InternalContext = contextFixture
+		// The fixtures were recorded without the time the challenge was issued.
+		if key := flow.PrefixInternalContextKey(identity.CredentialsTypeWebAuthn, webauthn.InternalContextKeyChallengeIssuedAt); !gjson.GetBytes(contextFixture, key).Exists() {
+			interim.InternalContext, err = sjson.SetBytes(contextFixture, key, time.Now().UTC())
+			require.NoError(t, err)
+		}
 		require.NoError(t, reg.LoginFlowPersister().UpdateLoginFlow(context.Background(), interim))
 
 		values := testhelpers.SDKFormFieldsToURLValues(f.Ui.Nodes)
@@ -290,7 +298,36 @@ func TestCompleteLogin(t *testing.T) {
 		t.Run("case=webauthn button exists", func(t *testing.T) {
 			client := testhelpers.NewClientWithCookies(t)
 			f := testhelpers.InitializeLoginFlowViaBrowser(t, client, publicTS, false, true, false, false)
-			testhelpers.SnapshotTExcept(t, f.Ui.Nodes, []string{"0.attributes.value"})
+			testhelpers.SnapshotTExcept(t, f.Ui.Nodes, []string{
+				"0.attributes.value",
+				"3.attributes.src",
+				"3.attributes.nonce",
+				"4.attributes.value",
+			})
+
+			nodes, err := json.Marshal(f.Ui.Nodes)
+			require.NoError(t, err)
+			assert.Equal(t, "username webauthn", gjson.GetBytes(nodes, "#(attributes.name==identifier).attributes.autocomplete").String(), "%s", nodes)
+			assert.Equal(t, "text", gjson.GetBytes(nodes, "#(attributes.name==identifier).attributes.type").String(), "the identifier input must be visible: %s", nodes)
+
+			challenge := gjson.GetBytes(nodes, "#(attributes.name=="+node.WebAuthnChallenge+").attributes.value").String()
+			assert.Equal(t, "conditional", gjson.Get(challenge, "mediation").String(), "%s", nodes)
+			assert.NotEmpty(t, gjson.Get(challenge, "publicKey.challenge").String(), "%s", nodes)
+			assert.False(t, gjson.Get(challenge, "publicKey.allowCredentials").Exists(), "%s", nodes)
+		})
+
+		t.Run("case=webauthn challenge of the identified user does not use conditional mediation", func(t *testing.T) {
+			_, subject := createIdentityAndReturnIdentifier(t, reg, []byte(`{"credentials":[{"id":"Zm9vZm9v","is_passwordless":true}]}`))
+
+			body, res := doBrowserFlow(t, false, func(v url.Values) {
+				v.Set("method", identity.CredentialsTypeWebAuthn.String())
+				v.Set("identifier", subject)
+			}, testhelpers.NewClientWithCookies(t))
+			checkURL(t, true, res)
+
+			assert.Equal(t, "hidden", gjson.Get(body, "ui.nodes.#(attributes.name==identifier).attributes.type").String(), "%s", body)
+			assert.False(t, gjson.Get(body, "ui.nodes.#(attributes.name=="+node.WebAuthnChallenge+")").Exists(), "%s", body)
+			assert.NotContains(t, gjson.Get(body, "ui.nodes.#(attributes.name==webauthn_login_trigger).attributes.onclick").String(), "mediation", "%s", body)
 		})
 
 		t.Run("case=webauthn shows error if user tries to sign in but no such user exists", func(t *testing.T) {
@@ -456,6 +493,37 @@ func TestCompleteLogin(t *testing.T) {
 				run(t, true)
 			})
 		})
+
+		t.Run("case=succeeds with conditional mediation", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySessionWhoAmIAAL, "aal1")
+			id := createIdentityWithWebAuthn(t, identity.Credentials{
+				Config:  loginFixtureSuccessV1PasswordlessCredentials,
+				Version: 1,
+			})
+
+			// Discoverable logins are started without knowing the user.
+			contextFixture, err := sjson.DeleteBytes(loginFixtureSuccessV1PasswordlessContext, "webauthn_session_data.user_id")
+			require.NoError(t, err)
+			contextFixture, err = sjson.DeleteBytes(contextFixture, "webauthn_session_data.allowed_credentials")
+			require.NoError(t, err)
+
+			// The user handle is not signed, so the recorded response can be replayed with it.
+			userHandle, err := base64.StdEncoding.DecodeString(gjson.GetBytes(loginFixtureSuccessV1PasswordlessCredentials, "user_handle").String())
+			require.NoError(t, err)
+			response, err := sjson.SetBytes(loginFixtureSuccessV1PasswordlessResponse, "response.userHandle", base64.RawURLEncoding.EncodeToString(userHandle))
+			require.NoError(t, err)
+
+			body, res, _ := submitWebAuthnLoginWithClient(t, false, id, contextFixture, testhelpers.NewClientWithCookies(t), func(values url.Values) {
+				// The form is submitted by the script, without the method and without an identifier.
+				values.Del("method")
+				values.Set("identifier", "")
+				values.Set(node.WebAuthnLogin, string(response))
+			}, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel1))
+
+			assert.Contains(t, res.Request.URL.String(), redirTS.URL, "%s", body)
+			assert.True(t, gjson.Get(body, "active").Bool(), "%s", body)
+			assert.EqualValues(t, id.ID.String(), gjson.Get(body, "identity.id").String(), "%s", body)
+		})
 	})
 
 	t.Run("flow=mfa", func(t *testing.T) {
@@ -476,6 +544,68 @@ func TestCompleteLogin(t *testing.T) {
 			ensureReplacement(t, "2", f.Ui, "allowCredentials")
 		})
 
+		t.Run("case=webauthn payload contains the appid extension with legacy_appid", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeyWebAuthnLegacyAppID, "https://legacy-appid.ory.sh/app-id.json")
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyWebAuthnLegacyAppID, nil)
+			})
+
+			id := createIdentity(t, reg)
+			apiClient := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
+			f := testhelpers.InitializeLoginFlowViaBrowser(t, apiClient, publicTS, false, true, false, false, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel2))
+			ensureReplacement(t, "2", f.Ui, "appid")
+			ensureReplacement(t, "2", f.Ui, "legacy-appid.ory.sh")
+		})
+
+		t.Run("case=webauthn payload has no appid extension by default", func(t *testing.T) {
+			id := createIdentity(t, reg)
+			apiClient := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
+			f := testhelpers.InitializeLoginFlowViaBrowser(t, apiClient, publicTS, false, true, false, false, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel2))
+			ensureReplacement(t, "2", f.Ui, "allowCredentials")
+
+			actual, err := json.Marshal(f.Ui.Nodes)
+			require.NoError(t, err)
+			assert.NotContains(t, gjson.GetBytes(actual, "2.attributes.onclick").String(), "appid")
+			assert.NotContains(t, gjson.GetBytes(actual, "2.attributes.onclick").String(), "mediation", "conditional mediation is only used for passwordless logins")
+		})
+
+		t.Run("case=webauthn payload contains the recorded transports", func(t *testing.T) {
+			id, _ := createIdentityAndReturnIdentifier(t, reg, []byte(`{"credentials":[{"id":"Zm9vZm9v","display_name":"foo","transports":["usb","nfc"]},{"id":"YmFyYmFy","display_name":"bar"}]}`))
+			apiClient := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
+			f := testhelpers.InitializeLoginFlowViaBrowser(t, apiClient, publicTS, false, true, false, false, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel2))
+
+			actual, err := json.Marshal(f.Ui.Nodes)
+			require.NoError(t, err)
+			onclick := gjson.GetBytes(actual, "2.attributes.onclick").String()
+			assert.Contains(t, onclick, `{"type":"public-key","id":"Zm9vZm9v","transports":["usb","nfc"]}`)
+			assert.Contains(t, onclick, `{"type":"public-key","id":"YmFyYmFy"}`, "credentials without transports omit the field")
+		})
+
+		t.Run("case=webauthn payload contains only the most recently used credentials with max_allow_credentials", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeyWebAuthnMaxAllowCredentials, 2)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyWebAuthnMaxAllowCredentials, nil)
+			})
+
+			id, _ := createIdentityAndReturnIdentifier(t, reg, []byte(`{"credentials":[`+
+				`{"id":"b2xkb2xk","display_name":"old","added_at":"2024-01-01T00:00:00Z","last_used_at":"2024-02-01T00:00:00Z"},`+
+				`{"id":"bmV3bmV3","display_name":"new","added_at":"2024-01-01T00:00:00Z","last_used_at":"2024-04-01T00:00:00Z"},`+
+				`{"id":"bmV2ZXJu","display_name":"never used","added_at":"2024-03-01T00:00:00Z"}]}`))
+			apiClient := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
+			f := testhelpers.InitializeLoginFlowViaBrowser(t, apiClient, publicTS, false, true, false, false, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel2))
+
+			actual, err := json.Marshal(f.Ui.Nodes)
+			require.NoError(t, err)
+			onclick := gjson.GetBytes(actual, "2.attributes.onclick").String()
+			assert.Contains(t, onclick, `"allowCredentials":[{"type":"public-key","id":"bmV3bmV3"},{"type":"public-key","id":"bmV2ZXJu"}]`)
+			assert.NotContains(t, onclick, "b2xkb2xk")
+
+			actualFlow, err := reg.LoginFlowPersister().GetLoginFlow(ctx, uuid.FromStringOrNil(f.Id))
+			require.NoError(t, err)
+			allowed := gjson.GetBytes(actualFlow.InternalContext, flow.PrefixInternalContextKey(identity.CredentialsTypeWebAuthn, webauthn.InternalContextKeySessionData)+".allowed_credentials")
+			assert.Len(t, allowed.Array(), 3, "every credential can still be used to sign in: %s", allowed.Raw)
+		})
+
 		t.Run("case=webauthn payload is not set when identity has no webauthn", func(t *testing.T) {
 			id := createIdentityWithoutWebAuthn(t, reg)
 			apiClient := testhelpers.NewHTTPClientWithIdentitySessionCookie(t, reg, id)
@@ -486,6 +616,24 @@ func TestCompleteLogin(t *testing.T) {
 			})
 		})
 
+		t.Run("case=webauthn trigger is not rendered without webauthn regardless of hide_unavailable_mfa_methods", func(t *testing.T) {
+			for _, hide := range []bool{false, true} {
+				t.Run(fmt.Sprintf("hide=%t", hide), func(t *testing.T) {
+					conf.MustSet(ctx, config.ViperKeySelfServiceLoginHideUnavailableMFAMethods, hide)
+					t.Cleanup(func() {
+						conf.MustSet(ctx, config.ViperKeySelfServiceLoginHideUnavailableMFAMethods, nil)
+					})
+
+					id := createIdentityWithoutWebAuthn(t, reg)
+					apiClient := testhelpers.NewHTTPClientWithIdentitySessionCookie(t, reg, id)
+					f := testhelpers.InitializeLoginFlowViaBrowser(t, apiClient, publicTS, false, true, false, false, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel2))
+
+					body := jsonx.TestMarshalJSONString(t, f.Ui)
+					assert.Empty(t, gjson.Get(body, "nodes.#(group==webauthn)#").Array(), body)
+				})
+			}
+		})
+
 		t.Run("case=webauthn payload is not set for API clients", func(t *testing.T) {
 			id := createIdentity(t, reg)
 
@@ -552,6 +700,76 @@ func TestCompleteLogin(t *testing.T) {
 			snapshotx.SnapshotTExcept(t, json.RawMessage(gjson.Get(body, "ui.messages").Raw), []string{})
 		})
 
+		t.Run("case=should reject assertions for expired challenges", func(t *testing.T) {
+			previous := conf.WebAuthnChallengeLifespan(ctx)
+			conf.MustSet(ctx, config.ViperKeyWebAuthnChallengeLifespan, "1m")
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyWebAuthnChallengeLifespan, previous.String())
+			})
+
+			id := createIdentityWithWebAuthn(t, identity.Credentials{
+				Config:  loginFixtureSuccessV1Credentials,
+				Version: 1,
+			})
+
+			// The login flow is still valid, but the challenge was issued before the challenge lifespan.
+			internalContext, err := sjson.SetBytes(loginFixtureSuccessV1Context,
+				flow.PrefixInternalContextKey(identity.CredentialsTypeWebAuthn, webauthn.InternalContextKeyChallengeIssuedAt),
+				time.Now().Add(-5*time.Minute).UTC())
+			require.NoError(t, err)
+
+			body, res, _ := submitWebAuthnLogin(t, true, id, internalContext, func(values url.Values) {
+				values.Set("identifier", loginFixtureSuccessEmail)
+				values.Set(node.WebAuthnLogin, string(loginFixtureSuccessV1Response))
+			}, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel2))
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+			assert.EqualValues(t, text.ErrorValidationWebAuthnChallengeExpired, gjson.Get(body, "ui.messages.0.id").Int(), "%s", body)
+		})
+
+		t.Run("case=should reject assertions for challenges without an issue time", func(t *testing.T) {
+			id := createIdentityWithWebAuthn(t, identity.Credentials{
+				Config:  loginFixtureSuccessV1Credentials,
+				Version: 1,
+			})
+
+			internalContext, err := sjson.SetBytes(loginFixtureSuccessV1Context,
+				flow.PrefixInternalContextKey(identity.CredentialsTypeWebAuthn, webauthn.InternalContextKeyChallengeIssuedAt), nil)
+			require.NoError(t, err)
+
+			body, res, _ := submitWebAuthnLogin(t, true, id, internalContext, func(values url.Values) {
+				values.Set("identifier", loginFixtureSuccessEmail)
+				values.Set(node.WebAuthnLogin, string(loginFixtureSuccessV1Response))
+			}, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel2))
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+			assert.EqualValues(t, text.ErrorValidationWebAuthnChallengeExpired, gjson.Get(body, "ui.messages.0.id").Int(), "%s", body)
+		})
+
+		t.Run("case=should reject assertions from origins of another tenant", func(t *testing.T) {
+			publicURL, err := url.Parse(publicTS.URL)
+			require.NoError(t, err)
+			conf.MustSet(ctx, config.ViperKeyWebAuthnTenants, []map[string]any{{
+				"host":    publicURL.Host,
+				"id":      "localhost",
+				"origins": []string{"https://tenant-b.localhost"},
+			}})
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyWebAuthnTenants, nil)
+			})
+
+			id := createIdentityWithWebAuthn(t, identity.Credentials{
+				Config:  loginFixtureSuccessV1Credentials,
+				Version: 1,
+			})
+
+			// The fixture was signed for origin http://localhost:4455 which is not an origin of this tenant.
+			body, res, _ := submitWebAuthnLogin(t, true, id, loginFixtureSuccessV1Context, func(values url.Values) {
+				values.Set("identifier", loginFixtureSuccessEmail)
+				values.Set(node.WebAuthnLogin, string(loginFixtureSuccessV1Response))
+			}, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel2))
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+			assert.False(t, gjson.Get(body, "session.active").Bool(), "%s", body)
+		})
+
 		t.Run("case=login with a security key using", func(t *testing.T) {
 			idd := uuid.FromStringOrNil("44fc22c9-abae-4c3e-a56b-37c7b38d973e")
 			out, err := json.Marshal(identity.CredentialsWebAuthnConfig{UserHandle: idd[:]})
@@ -613,5 +831,83 @@ func TestCompleteLogin(t *testing.T) {
 				})
 			}
 		})
+
+		t.Run("case=sign count policy", func(t *testing.T) {
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyWebAuthnSignCountPolicy, config.WebAuthnSignCountPolicyIgnore)
+			})
+
+			// The response fixture asserts a sign count of 10, so storing 10
+			// makes the sign count regress.
+			regressed, err := sjson.SetBytes(loginFixtureSuccessV1Credentials, "credentials.0.authenticator.sign_count", 10)
+			require.NoError(t, err)
+
+			signIn := func(t *testing.T, credentials []byte) (*identity.Identity, string) {
+				id := createIdentityWithWebAuthn(t, identity.Credentials{Config: credentials, Version: 1})
+				body, _, _ := submitWebAuthnLogin(t, true, id, loginFixtureSuccessV1Context, func(values url.Values) {
+					values.Set("identifier", loginFixtureSuccessEmail)
+					values.Set(node.WebAuthnLogin, string(loginFixtureSuccessV1Response))
+				}, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel2))
+				return id, body
+			}
+
+			storedSignCount := func(t *testing.T, id *identity.Identity) int64 {
+				actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id.ID)
+				require.NoError(t, err)
+				c, ok := actual.GetCredentials(identity.CredentialsTypeWebAuthn)
+				require.True(t, ok)
+				return gjson.GetBytes(c.Config, "credentials.0.authenticator.sign_count").Int()
+			}
+
+			for _, tc := range []struct {
+				policy  string
+				success bool
+			}{
+				{policy: config.WebAuthnSignCountPolicyIgnore, success: true},
+				{policy: config.WebAuthnSignCountPolicyWarn, success: true},
+				{policy: config.WebAuthnSignCountPolicyReject, success: false},
+			} {
+				t.Run("policy="+tc.policy, func(t *testing.T) {
+					conf.MustSet(ctx, config.ViperKeyWebAuthnSignCountPolicy, tc.policy)
+
+					id, body := signIn(t, regressed)
+					if tc.success {
+						assert.True(t, gjson.Get(body, "session.active").Bool(), "%s", body)
+					} else {
+						assert.False(t, gjson.Get(body, "session.active").Bool(), "%s", body)
+						assert.Equal(t, "The provided authentication code is invalid, please try again.", gjson.Get(body, "ui.messages.0.text").String(), "%s", body)
+					}
+					assert.EqualValues(t, 10, storedSignCount(t, id))
+				})
+			}
+
+			t.Run("case=stores increasing sign count", func(t *testing.T) {
+				conf.MustSet(ctx, config.ViperKeyWebAuthnSignCountPolicy, config.WebAuthnSignCountPolicyReject)
+
+				id, body := signIn(t, loginFixtureSuccessV1Credentials)
+				assert.True(t, gjson.Get(body, "session.active").Bool(), "%s", body)
+				assert.EqualValues(t, 10, storedSignCount(t, id))
+			})
+		})
+
+		t.Run("case=stores the time of the last use with max_allow_credentials", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeyWebAuthnMaxAllowCredentials, 5)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyWebAuthnMaxAllowCredentials, nil)
+			})
+
+			id := createIdentityWithWebAuthn(t, identity.Credentials{Config: loginFixtureSuccessV1Credentials, Version: 1})
+			body, _, _ := submitWebAuthnLogin(t, true, id, loginFixtureSuccessV1Context, func(values url.Values) {
+				values.Set("identifier", loginFixtureSuccessEmail)
+				values.Set(node.WebAuthnLogin, string(loginFixtureSuccessV1Response))
+			}, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel2))
+			assert.True(t, gjson.Get(body, "session.active").Bool(), "%s", body)
+
+			actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id.ID)
+			require.NoError(t, err)
+			c, ok := actual.GetCredentials(identity.CredentialsTypeWebAuthn)
+			require.True(t, ok)
+			assert.WithinDuration(t, time.Now(), gjson.GetBytes(c.Config, "credentials.0.last_used_at").Time(), time.Minute)
+		})
 	})
 }
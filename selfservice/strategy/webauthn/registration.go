@@ -141,7 +141,7 @@ func (s *Strategy) Register(w http.ResponseWriter, r *http.Request, regFlow *reg
 			herodot.ErrBadRequest.WithReasonf("Unable to parse WebAuthn response: %s", err)))
 	}
 
-	web, err := webauthn.New(s.d.Config().WebAuthnConfig(r.Context()))
+	web, err := webauthn.New(s.webAuthnConfig(r))
 	if err != nil {
 		return s.handleRegistrationError(w, r, regFlow, &p, errors.WithStack(
 			herodot.ErrInternalServerError.WithReasonf("Unable to get webAuthn config.").WithDebug(err.Error())))
@@ -156,6 +156,10 @@ func (s *Strategy) Register(w http.ResponseWriter, r *http.Request, regFlow *reg
 			herodot.ErrInternalServerError.WithReasonf("Unable to create WebAuthn credential: %s", err)))
 	}
 
+	if err := s.verifyAttestation(ctx, credential, webAuthnResponse); err != nil {
+		return s.handleRegistrationError(w, r, regFlow, &p, err)
+	}
+
 	credentialWebAuthn := identity.CredentialFromWebAuthn(credential, true)
 	credentialWebAuthn.DisplayName = p.RegisterDisplayName
 	credentialWebAuthnConfig, err := json.Marshal(identity.CredentialsWebAuthnConfig{
@@ -206,13 +210,13 @@ func (s *Strategy) PopulateRegistrationMethod(r *http.Request, f *registration.F
 		f.UI.SetNode(n)
 	}
 
-	web, err := webauthn.New(s.d.Config().WebAuthnConfig(ctx))
+	web, err := webauthn.New(s.webAuthnConfig(r))
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
 	webauthID := x.NewUUID()
-	user := webauthnx.NewUser(webauthID[:], nil, s.d.Config().WebAuthnConfig(ctx))
+	user := webauthnx.NewUser(webauthID[:], nil, web.Config)
 	option, sessionData, err := web.BeginRegistration(user)
 	if err != nil {
 		return errors.WithStack(err)
@@ -231,7 +235,7 @@ func (s *Strategy) PopulateRegistrationMethod(r *http.Request, f *registration.F
 	f.UI.Nodes.Upsert(webauthnx.NewWebAuthnScript(s.d.Config().SelfPublicURL(ctx)))
 	f.UI.Nodes.Upsert(webauthnx.NewWebAuthnConnectionName())
 	f.UI.Nodes.Upsert(webauthnx.NewWebAuthnConnectionInput())
-	f.UI.Nodes.Upsert(webauthnx.NewWebAuthnConnectionTrigger(string(injectWebAuthnOptions)).
+	f.UI.Nodes.Upsert(webauthnx.NewWebAuthnConnectionTrigger(string(injectWebAuthnOptions), s.d.Config().WebAuthnUseExternalScript(ctx)).
 		WithMetaLabel(text.NewInfoSelfServiceRegistrationRegisterWebAuthn()))
 
 	f.UI.SetCSRF(s.d.GenerateCSRFToken(r))
@@ -5,12 +5,26 @@ package webauthn_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	_ "embed"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
 	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,6 +43,7 @@ import (
 	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
+	"github.com/ory/kratos/x/webauthnx"
 	"github.com/ory/x/assertx"
 )
 
@@ -152,6 +167,57 @@ func TestRegistration(t *testing.T) {
 		}
 	})
 
+	t.Run("case=webauthn button does not use inline scripts with external script", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyWebAuthnUseExternalScript, true)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyWebAuthnUseExternalScript, false)
+		})
+
+		for _, f := range flows {
+			t.Run(f, func(t *testing.T) {
+				client := testhelpers.NewClientWithCookies(t)
+				f := testhelpers.InitializeRegistrationFlowViaBrowser(t, client, publicTS, flowToIsSPA(f), false, false)
+
+				var found bool
+				for _, n := range f.Ui.Nodes {
+					attr := n.Attributes.UiNodeInputAttributes
+					if attr == nil {
+						continue
+					}
+					assert.Empty(t, attr.GetOnclick(), "%s must not carry an inline onclick script", attr.Name)
+					assert.Empty(t, attr.GetOnload(), "%s must not carry an inline onload script", attr.Name)
+					if attr.Name == node.WebAuthnRegisterTrigger {
+						found = true
+						assert.Equal(t, webauthnx.TriggerWebAuthnRegistration, attr.GetOnclickTrigger())
+						assert.True(t, gjson.Valid(attr.Value.(string)), "the options are passed as the value")
+					}
+				}
+				assert.True(t, found)
+			})
+		}
+	})
+
+	t.Run("case=display name is used as the relying party name", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyWebAuthnUseExternalScript, true)
+		conf.MustSet(ctx, config.ViperKeyWebAuthnRPDisplayName, "foo")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyWebAuthnUseExternalScript, false)
+			conf.MustSet(ctx, config.ViperKeyWebAuthnRPDisplayName, "Ory Corp")
+		})
+
+		for _, f := range flows {
+			t.Run(f, func(t *testing.T) {
+				client := testhelpers.NewClientWithCookies(t)
+				f := testhelpers.InitializeRegistrationFlowViaBrowser(t, client, publicTS, flowToIsSPA(f), false, false)
+				nodes, err := json.Marshal(f.Ui.Nodes)
+				require.NoError(t, err)
+
+				options := gjson.GetBytes(nodes, fmt.Sprintf("#(attributes.name==%s).attributes.value", node.WebAuthnRegisterTrigger)).String()
+				assert.Equal(t, "foo", gjson.Get(options, "publicKey.rp.name").String(), "%s", options)
+			})
+		}
+	})
+
 	t.Run("case=should return an error because not passing validation", func(t *testing.T) {
 		email := testhelpers.RandomEmail()
 
@@ -474,6 +540,137 @@ func TestRegistration(t *testing.T) {
 		})
 	})
 
+	t.Run("case=verify attestation", func(t *testing.T) {
+		testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/registration.schema.json")
+		conf.MustSet(ctx, config.ViperKeyWebAuthnVerifyAttestation+".enabled", true)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyWebAuthnVerifyAttestation, nil)
+		})
+
+		register := func(t *testing.T, response string) (string, string) {
+			email := testhelpers.RandomEmail()
+			actual, _, _ := makeRegistration(t, "spa", func(v url.Values) {
+				v.Set("traits.username", email)
+				v.Set("traits.foobar", "bazbar")
+				v.Set(node.WebAuthnRegister, response)
+				v.Del("method")
+			})
+			return email, actual
+		}
+
+		aaguid := x.NewUUID()
+		ca := newAttestationCA(t)
+
+		t.Run("case=accepts a verified attestation", func(t *testing.T) {
+			email, actual := register(t, newPackedAttestationResponse(t, aaguid, ca, false))
+			assert.Equal(t, email, gjson.Get(actual, "identity.traits.username").String(), "%s", actual)
+		})
+
+		withoutVerification := func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeyWebAuthnVerifyAttestation+".enabled", false)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyWebAuthnVerifyAttestation+".enabled", true)
+			})
+		}
+
+		t.Run("case=rejects a tampered attestation", func(t *testing.T) {
+			_, actual := register(t, newPackedAttestationResponse(t, aaguid, ca, true))
+			assert.False(t, gjson.Get(actual, "identity").Exists(), "%s", actual)
+			assert.Contains(t, actual, "Unable to create WebAuthn credential")
+
+			t.Run("case=the attestation signature is checked without verification as well", func(t *testing.T) {
+				withoutVerification(t)
+				_, actual := register(t, newPackedAttestationResponse(t, aaguid, ca, true))
+				assert.False(t, gjson.Get(actual, "identity").Exists(), "%s", actual)
+				assert.Contains(t, actual, "Unable to create WebAuthn credential")
+			})
+		})
+
+		t.Run("case=rejects credentials without attestation", func(t *testing.T) {
+			_, actual := register(t, string(registrationFixtureSuccessResponse))
+			assert.False(t, gjson.Get(actual, "identity").Exists(), "%s", actual)
+			assert.Equal(t, webauthn.ErrAttestationNotVerified.Reason(), gjson.Get(actual, "ui.messages.0.text").String(), "%s", actual)
+		})
+
+		t.Run("case=rejects self attestation", func(t *testing.T) {
+			_, actual := register(t, newPackedAttestationResponse(t, aaguid, nil, false))
+			assert.False(t, gjson.Get(actual, "identity").Exists(), "%s", actual)
+			assert.Equal(t, webauthn.ErrAttestationNotVerified.Reason(), gjson.Get(actual, "ui.messages.0.text").String(), "%s", actual)
+
+			t.Run("case=accepts self attestation without verification", func(t *testing.T) {
+				withoutVerification(t)
+				email, actual := register(t, newPackedAttestationResponse(t, aaguid, nil, false))
+				assert.Equal(t, email, gjson.Get(actual, "identity.traits.username").String(), "%s", actual)
+			})
+		})
+
+		t.Run("case=accepts any attestation certificate without metadata", func(t *testing.T) {
+			email, actual := register(t, newPackedAttestationResponse(t, aaguid, newAttestationCA(t), false))
+			assert.Equal(t, email, gjson.Get(actual, "identity.traits.username").String(), "%s", actual)
+		})
+
+		t.Run("case=checks the authenticator against the FIDO metadata", func(t *testing.T) {
+			setMetadata := func(t *testing.T, entries ...any) {
+				conf.MustSet(ctx, config.ViperKeyWebAuthnVerifyAttestation+".metadata_url", "base64://"+base64.StdEncoding.EncodeToString([]byte(x.MustEncodeJSON(t, map[string]any{"entries": entries}))))
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeyWebAuthnVerifyAttestation+".metadata_url", nil)
+				})
+			}
+
+			entry := func(aaguid uuid.UUID, root *x509.Certificate, reports ...map[string]any) map[string]any {
+				return map[string]any{
+					"aaguid":            aaguid.String(),
+					"metadataStatement": map[string]any{"attestationRootCertificates": []string{base64.StdEncoding.EncodeToString(root.Raw)}},
+					"statusReports":     reports,
+				}
+			}
+
+			report := func(status, effectiveDate string) map[string]any {
+				return map[string]any{"status": status, "effectiveDate": effectiveDate}
+			}
+
+			assertRejected := func(t *testing.T, actual string) {
+				assert.False(t, gjson.Get(actual, "identity").Exists(), "%s", actual)
+				assert.Equal(t, webauthn.ErrAttestationNotVerified.Reason(), gjson.Get(actual, "ui.messages.0.text").String(), "%s", actual)
+			}
+
+			t.Run("case=accepts a listed authenticator", func(t *testing.T) {
+				setMetadata(t, entry(aaguid, ca.cert, report("FIDO_CERTIFIED", "2020-01-01")))
+				email, actual := register(t, newPackedAttestationResponse(t, aaguid, ca, false))
+				assert.Equal(t, email, gjson.Get(actual, "identity.traits.username").String(), "%s", actual)
+			})
+
+			t.Run("case=rejects an unlisted authenticator", func(t *testing.T) {
+				setMetadata(t, entry(aaguid, ca.cert, report("FIDO_CERTIFIED", "2020-01-01")))
+				_, actual := register(t, newPackedAttestationResponse(t, x.NewUUID(), ca, false))
+				assertRejected(t, actual)
+			})
+
+			t.Run("case=rejects an attestation certificate from another root", func(t *testing.T) {
+				setMetadata(t, entry(aaguid, newAttestationCA(t).cert, report("FIDO_CERTIFIED", "2020-01-01")))
+				_, actual := register(t, newPackedAttestationResponse(t, aaguid, ca, false))
+				assertRejected(t, actual)
+			})
+
+			t.Run("case=only the latest status report counts", func(t *testing.T) {
+				setMetadata(t, entry(aaguid, ca.cert,
+					report("USER_VERIFICATION_BYPASS", "2021-01-01"),
+					report("FIDO_CERTIFIED", "2020-01-01"),
+					report("UPDATE_AVAILABLE", "2022-01-01"),
+				))
+				email, actual := register(t, newPackedAttestationResponse(t, aaguid, ca, false))
+				assert.Equal(t, email, gjson.Get(actual, "identity.traits.username").String(), "%s", actual)
+
+				setMetadata(t, entry(aaguid, ca.cert,
+					report("FIDO_CERTIFIED", "2020-01-01"),
+					report("REVOKED", "2022-01-01"),
+				))
+				_, actual = register(t, newPackedAttestationResponse(t, aaguid, ca, false))
+				assertRejected(t, actual)
+			})
+		})
+	})
+
 	t.Run("case=should fail if no identifier was set in the schema", func(t *testing.T) {
 		testhelpers.SetDefaultIdentitySchema(conf, "file://stub/missing-identifier.schema.json")
 
@@ -489,3 +686,116 @@ func TestRegistration(t *testing.T) {
 		}
 	})
 }
+
+type attestationCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newAttestationCA returns a self-signed attestation root certificate.
+func newAttestationCA(t *testing.T) *attestationCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Attestation Root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &attestationCA{cert: cert, key: key}
+}
+
+// newPackedAttestationResponse returns a registration response with a packed attestation for the
+// challenge of registrationFixtureSuccessInternalContext. The attestation is a basic attestation with
+// a certificate issued by ca, or a self attestation if ca is nil. If tamper is true, the attestation
+// signature is invalid.
+func newPackedAttestationResponse(t *testing.T, aaguid uuid.UUID, ca *attestationCA, tamper bool) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: key.X.FillBytes(make([]byte, 32)),
+		YCoord: key.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	credentialID := make([]byte, 32)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+
+	rpIDHash := sha256.Sum256([]byte("localhost"))
+	authData := append([]byte{}, rpIDHash[:]...)
+	authData = append(authData, 0x41)       // user present, attested credential data included
+	authData = append(authData, 0, 0, 0, 0) // sign count
+	authData = append(authData, aaguid.Bytes()...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(credentialID)))
+	authData = append(authData, credentialID...)
+	authData = append(authData, publicKey...)
+
+	clientData := []byte(fmt.Sprintf(`{"type":"webauthn.create","challenge":%q,"origin":"http://localhost:4455","crossOrigin":false}`,
+		gjson.GetBytes(registrationFixtureSuccessInternalContext, "webauthn_session_data.challenge").String()))
+	clientDataHash := sha256.Sum256(clientData)
+	signed := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	statement := map[string]any{"alg": int64(webauthncose.AlgES256)}
+
+	// Self attestation is signed with the credential key, basic attestation with the key of the attestation certificate.
+	signer := key
+	if ca != nil {
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject: pkix.Name{
+				Country:            []string{"DE"},
+				Organization:       []string{"Ory"},
+				OrganizationalUnit: []string{"Authenticator Attestation"},
+				CommonName:         "Test Authenticator",
+			},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			BasicConstraintsValid: true,
+		}, ca.cert, &signer.PublicKey, ca.key)
+		require.NoError(t, err)
+		statement["x5c"] = []any{der}
+	}
+
+	sig, err := ecdsa.SignASN1(rand.Reader, signer, signed[:])
+	require.NoError(t, err)
+	if tamper {
+		sig[len(sig)-1] ^= 0xff
+	}
+	statement["sig"] = sig
+
+	attestationObject, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "packed",
+		"attStmt":  statement,
+		"authData": authData,
+	})
+	require.NoError(t, err)
+
+	id := base64.RawURLEncoding.EncodeToString(credentialID)
+	return x.MustEncodeJSON(t, map[string]any{
+		"id":    id,
+		"rawId": id,
+		"type":  "public-key",
+		"response": map[string]any{
+			"attestationObject": base64.RawURLEncoding.EncodeToString(attestationObject),
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+		},
+	})
+}
@@ -46,7 +46,8 @@ func (s *Strategy) SettingsStrategyID() string {
 }
 
 const (
-	InternalContextKeySessionData = "session_data"
+	InternalContextKeySessionData       = "session_data"
+	InternalContextKeyChallengeIssuedAt = "challenge_issued_at"
 )
 
 // Update Settings Flow with WebAuthn Method
@@ -247,7 +248,7 @@ func (s *Strategy) continueSettingsFlowAdd(r *http.Request, ctxUpdate *settings.
 		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to parse WebAuthn response: %s", err))
 	}
 
-	web, err := webauthn.New(s.d.Config().WebAuthnConfig(r.Context()))
+	web, err := webauthn.New(s.webAuthnConfig(r))
 	if err != nil {
 		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to get webAuthn config.").WithDebug(err.Error()))
 	}
@@ -257,6 +258,10 @@ func (s *Strategy) continueSettingsFlowAdd(r *http.Request, ctxUpdate *settings.
 		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to create WebAuthn credential: %s", err))
 	}
 
+	if err := s.verifyAttestation(r.Context(), credential, webAuthnResponse); err != nil {
+		return err
+	}
+
 	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), ctxUpdate.Session.IdentityID)
 	if err != nil {
 		return err
@@ -360,12 +365,12 @@ func (s *Strategy) PopulateSettingsMethod(r *http.Request, id *identity.Identity
 		}
 	}
 
-	web, err := webauthn.New(s.d.Config().WebAuthnConfig(r.Context()))
+	web, err := webauthn.New(s.webAuthnConfig(r))
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	option, sessionData, err := web.BeginRegistration(webauthnx.NewUser(id.ID.Bytes(), nil, web.Config))
+	option, sessionData, err := web.BeginRegistration(s.webAuthnUser(r.Context(), confidentialIdentity, web.Config))
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -382,7 +387,7 @@ func (s *Strategy) PopulateSettingsMethod(r *http.Request, id *identity.Identity
 
 	f.UI.Nodes.Upsert(webauthnx.NewWebAuthnScript(s.d.Config().SelfPublicURL(r.Context())))
 	f.UI.Nodes.Upsert(webauthnx.NewWebAuthnConnectionName())
-	f.UI.Nodes.Upsert(webauthnx.NewWebAuthnConnectionTrigger(string(injectWebAuthnOptions)).
+	f.UI.Nodes.Upsert(webauthnx.NewWebAuthnConnectionTrigger(string(injectWebAuthnOptions), s.d.Config().WebAuthnUseExternalScript(r.Context())).
 		WithMetaLabel(text.NewInfoSelfServiceSettingsRegisterWebAuthn()))
 	f.UI.Nodes.Upsert(webauthnx.NewWebAuthnConnectionInput())
 	return nil
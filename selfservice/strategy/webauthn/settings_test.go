@@ -167,6 +167,35 @@ func TestCompleteSettings(t *testing.T) {
 		ensureReplacement(t, "2", f.Ui, "Ory Corp")
 	})
 
+	t.Run("case=user entity", func(t *testing.T) {
+		t.Run("case=falls back to the identifier", func(t *testing.T) {
+			id, identifier := createIdentityAndReturnIdentifier(t, reg, nil)
+
+			apiClient := testhelpers.NewHTTPClientWithIdentitySessionCookie(t, reg, id)
+			f := testhelpers.InitializeSettingsFlowViaBrowser(t, apiClient, true, publicTS)
+			ensureReplacement(t, "4", f.Ui, `"name":"`+identifier+`"`)
+			ensureReplacement(t, "4", f.Ui, `"displayName":"`+identifier+`"`)
+		})
+
+		t.Run("case=uses the configured traits", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeyWebAuthnUserNameTrait, "subject")
+			conf.MustSet(ctx, config.ViperKeyWebAuthnUserDisplayNameTrait, "website")
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyWebAuthnUserNameTrait, nil)
+				conf.MustSet(ctx, config.ViperKeyWebAuthnUserDisplayNameTrait, nil)
+			})
+
+			id, identifier := createIdentityAndReturnIdentifier(t, reg, nil)
+			id.Traits = identity.Traits(fmt.Sprintf(`{"subject":"%s","website":"Example User"}`, identifier))
+			require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(context.Background(), id))
+
+			apiClient := testhelpers.NewHTTPClientWithIdentitySessionCookie(t, reg, id)
+			f := testhelpers.InitializeSettingsFlowViaBrowser(t, apiClient, true, publicTS)
+			ensureReplacement(t, "4", f.Ui, `"name":"`+identifier+`"`)
+			ensureReplacement(t, "4", f.Ui, `"displayName":"Example User"`)
+		})
+	})
+
 	t.Run("case=webauthn only works for browsers", func(t *testing.T) {
 		id := createIdentityWithoutWebAuthn(t, reg)
 		require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(context.Background(), id))
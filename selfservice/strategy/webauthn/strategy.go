@@ -6,8 +6,11 @@ package webauthn
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
 
 	"github.com/ory/kratos/continuity"
 	"github.com/ory/kratos/driver/config"
@@ -20,7 +23,9 @@ import (
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
+	"github.com/ory/kratos/x/webauthnx"
 	"github.com/ory/x/decoderx"
+	"github.com/ory/x/stringsx"
 )
 
 var (
@@ -31,6 +36,7 @@ var (
 
 type webauthnStrategyDependencies interface {
 	x.LoggingProvider
+	x.HTTPClientProvider
 	x.WriterProvider
 	x.CSRFTokenGeneratorProvider
 	x.CSRFProvider
@@ -106,6 +112,34 @@ func (s *Strategy) countCredentials(cc map[identity.CredentialsType]identity.Cre
 	return
 }
 
+// webAuthnUser returns the WebAuthn user entity of the identity. Its name and display name
+// are read from the configured traits and fall back to the WebAuthn identifier.
+func (s *Strategy) webAuthnUser(ctx context.Context, i *identity.Identity, conf *webauthn.Config) *webauthnx.User {
+	user := webauthnx.NewUser(i.ID.Bytes(), nil, conf)
+
+	var identifier string
+	if c, ok := i.GetCredentials(s.ID()); ok && len(c.Identifiers) > 0 {
+		identifier = c.Identifiers[0]
+	}
+
+	user.Name = stringsx.Coalesce(traitString(i, s.d.Config().WebAuthnUserNameTrait(ctx)), identifier)
+	user.DisplayName = stringsx.Coalesce(traitString(i, s.d.Config().WebAuthnUserDisplayNameTrait(ctx)), user.Name)
+	return user
+}
+
+func traitString(i *identity.Identity, path string) string {
+	if path == "" {
+		return ""
+	}
+	return gjson.GetBytes(i.Traits, path).String()
+}
+
+// webAuthnConfig returns the relying party configuration for the host the request was made to.
+func (s *Strategy) webAuthnConfig(r *http.Request) *webauthn.Config {
+	ctx := r.Context()
+	return s.d.Config().WebAuthnConfig(config.WithWebAuthnRequestHost(ctx, x.RequestHost(r, s.d.Config().PublicTrustedProxies(ctx))))
+}
+
 func (s *Strategy) ID() identity.CredentialsType {
 	return identity.CredentialsTypeWebAuthn
 }
@@ -4,6 +4,7 @@
 package session
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -25,6 +26,7 @@ import (
 	"github.com/ory/herodot"
 
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/x"
 )
 
@@ -32,6 +34,7 @@ type (
 	handlerDependencies interface {
 		ManagementProvider
 		PersistenceProvider
+		identity.PrivilegedPoolProvider
 		x.WriterProvider
 		x.TracingProvider
 		x.LoggingProvider
@@ -62,12 +65,14 @@ const (
 	RouteCollection                  = "/sessions"
 	RouteExchangeCodeForSessionToken = RouteCollection + "/token-exchange" // #nosec G101
 	RouteWhoami                      = RouteCollection + "/whoami"
+	RouteValidate                    = RouteCollection + "/validate"
 	RouteSession                     = RouteCollection + "/:id"
 )
 
 const (
 	AdminRouteIdentity           = "/identities"
 	AdminRouteIdentitiesSessions = AdminRouteIdentity + "/:id/sessions"
+	AdminRouteIdentityExport     = AdminRouteIdentity + "/:id/export"
 	AdminRouteSessionExtendId    = RouteSession + "/extend"
 )
 
@@ -78,6 +83,7 @@ func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
 
 	admin.GET(AdminRouteIdentitiesSessions, h.listIdentitySessions)
 	admin.DELETE(AdminRouteIdentitiesSessions, h.deleteIdentitySessions)
+	admin.GET(AdminRouteIdentityExport, h.exportIdentity)
 	admin.PATCH(AdminRouteSessionExtendId, h.adminSessionExtend)
 
 	admin.DELETE(RouteCollection, x.RedirectToPublicRoute(h.r))
@@ -91,6 +97,7 @@ func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	h.r.CSRFHandler().IgnoreGlob(RouteCollection + "/*")
 	h.r.CSRFHandler().IgnoreGlob(RouteCollection + "/*/extend")
 	h.r.CSRFHandler().IgnoreGlob(AdminRouteIdentity + "/*/sessions")
+	h.r.CSRFHandler().IgnoreGlob(AdminRouteIdentity + "/*/export")
 
 	for _, m := range []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodConnect, http.MethodOptions, http.MethodTrace} {
 		public.Handle(m, RouteWhoami, h.whoami)
@@ -99,10 +106,12 @@ func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	public.DELETE(RouteCollection, h.deleteMySessions)
 	public.DELETE(RouteSession, h.deleteMySession)
 	public.GET(RouteCollection, h.listMySessions)
+	public.GET(RouteValidate, h.validateSession)
 
 	public.GET(RouteExchangeCodeForSessionToken, h.exchangeCode)
 
 	public.DELETE(AdminRouteIdentitiesSessions, x.RedirectToAdminRoute(h.r))
+	public.GET(AdminRouteIdentityExport, x.RedirectToAdminRoute(h.r))
 }
 
 // Check Session Request Parameters
@@ -242,6 +251,15 @@ func (h *Handler) whoami(w http.ResponseWriter, r *http.Request, _ httprouter.Pa
 	// s.Devices = nil
 	s.Identity = s.Identity.CopyWithoutCredentials()
 
+	if revalidateAfter := c.SelfServiceFlowVerificationRevalidateAfter(ctx); revalidateAfter > 0 {
+		for _, a := range s.Identity.VerifiableAddresses {
+			if a.NeedsRevalidation(revalidateAfter) {
+				s.VerificationRequired = true
+				break
+			}
+		}
+	}
+
 	tokenizeTemplate := r.URL.Query().Get("tokenize_as")
 	if tokenizeTemplate != "" {
 		if err := h.r.SessionTokenizer().TokenizeSession(ctx, tokenizeTemplate, s); err != nil {
@@ -272,6 +290,122 @@ func (h *Handler) whoami(w http.ResponseWriter, r *http.Request, _ httprouter.Pa
 	h.r.Writer().Write(w, r, s)
 }
 
+// Check Session Validity Request Parameters
+//
+// swagger:parameters validateSession
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type validateSession struct {
+	// Set the Session Token when calling from non-browser clients. A session token has a format of `MP2YWEMeM8MxjkGKpH4dqOQ4Q4DlSPaj`.
+	//
+	// in: header
+	SessionToken string `json:"X-Session-Token"`
+
+	// Set the Cookie Header. This is especially useful when calling this endpoint from a server-side application. In that
+	// scenario you must include the HTTP Cookie Header which originally was included in the request to your server.
+	//
+	// It is ok if more than one cookie are included here as all other cookies will be ignored.
+	//
+	// in: header
+	Cookie string `json:"Cookie"`
+}
+
+// Session Validity
+//
+// A minimal representation of a valid session.
+//
+// swagger:model sessionValidity
+type Validity struct {
+	// Active is always true for valid sessions.
+	//
+	// required: true
+	Active bool `json:"active"`
+
+	// AAL is the Authenticator Assurance Level of the session.
+	//
+	// required: true
+	AAL identity.AuthenticatorAssuranceLevel `json:"aal"`
+
+	// IdentityID is the ID of the identity the session belongs to.
+	//
+	// required: true
+	IdentityID uuid.UUID `json:"identity_id"`
+
+	// ExpiresAt is the time when the session expires.
+	//
+	// required: true
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// swagger:route GET /sessions/validate frontend validateSession
+//
+// # Check if the Current HTTP Session is Valid
+//
+// A lightweight alternative to `/sessions/whoami` for reverse proxies and API gateways which only need to know
+// whether a request is authenticated. The identity's traits, credentials, and addresses are neither loaded nor
+// returned. The session is checked the same way as in `/sessions/whoami`, including the required Authenticator
+// Assurance Level.
+//
+// When the request it successful it adds the user ID to the 'X-Kratos-Authenticated-Identity-Id' header
+// in the response. If session caching is enabled, the `Ory-Session-Cache-For` header is set as well.
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: sessionValidity
+//	  401: errorGeneric
+//	  403: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) validateSession(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx, span := h.r.Tracer(r.Context()).Tracer().Start(r.Context(), "sessions.Handler.validateSession")
+	defer span.End()
+
+	s, err := h.r.SessionManager().FetchFromRequestWithoutDetails(ctx, r)
+	c := h.r.Config()
+	if err != nil {
+		if noSess := new(ErrNoActiveSessionFound); c.SessionWhoAmICaching(ctx) && errors.As(err, &noSess) && noSess.credentialsMissing {
+			w.Header().Set("Ory-Session-Cache-For", fmt.Sprintf("%d", int64(time.Minute.Seconds())))
+		}
+
+		h.r.Audit().WithRequest(r).WithError(err).Info("No valid session found.")
+		h.r.Writer().WriteError(w, r, ErrNoSessionFound.WithWrap(err))
+		return
+	}
+
+	var aalErr *ErrAALNotSatisfied
+	if err := h.r.SessionManager().DoesSessionSatisfy(r, s, c.SessionWhoAmIAAL(ctx), UpsertAAL); errors.As(err, &aalErr) {
+		h.r.Audit().WithRequest(r).WithError(err).Info("Session was found but AAL is not satisfied for calling this endpoint.")
+		h.r.Writer().WriteError(w, r, err)
+		return
+	} else if err != nil {
+		h.r.Audit().WithRequest(r).WithError(err).Info("No valid session cookie found.")
+		h.r.Writer().WriteError(w, r, herodot.ErrUnauthorized.WithWrap(err).WithReasonf("Unable to determine AAL."))
+		return
+	}
+
+	w.Header().Set("X-Kratos-Authenticated-Identity-Id", s.IdentityID.String())
+
+	if c.SessionWhoAmICaching(ctx) {
+		expiry := time.Until(s.ExpiresAt)
+		if c.SessionWhoAmICachingMaxAge(ctx) > 0 && expiry > c.SessionWhoAmICachingMaxAge(ctx) {
+			expiry = c.SessionWhoAmICachingMaxAge(ctx)
+		}
+
+		w.Header().Set("Ory-Session-Cache-For", fmt.Sprintf("%0.f", expiry.Seconds()))
+	}
+
+	h.r.Writer().Write(w, r, &Validity{
+		Active:     true,
+		AAL:        s.AuthenticatorAssuranceLevel,
+		IdentityID: s.IdentityID,
+		ExpiresAt:  s.ExpiresAt,
+	})
+}
+
 // Delete Identity Session Parameters
 //
 // swagger:parameters deleteIdentitySessions
@@ -632,6 +766,98 @@ func (h *Handler) listIdentitySessions(w http.ResponseWriter, r *http.Request, p
 	h.r.Writer().Write(w, r, sess)
 }
 
+// Export Identity Parameters
+//
+// swagger:parameters exportIdentity
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type exportIdentity struct {
+	// ID is the identity's ID.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+}
+
+// Identity Data Export
+//
+// Contains all data Ory Kratos stores about an identity. Credential secrets
+// such as password hashes are never part of the export.
+//
+// swagger:model identityExport
+type IdentityExport struct {
+	// Identity contains the identity's traits, metadata, addresses, and the
+	// public parts of its credentials.
+	//
+	// required: true
+	Identity *identity.Identity `json:"identity"`
+
+	// Sessions contains the identity's active sessions.
+	//
+	// required: true
+	Sessions []Session `json:"sessions"`
+}
+
+func (e IdentityExport) MarshalJSON() ([]byte, error) {
+	type local IdentityExport
+	return json.Marshal(struct {
+		local
+		Identity identity.WithExportedCredentialsAndAdminMetadataInJSON `json:"identity"`
+	}{
+		local:    local(e),
+		Identity: identity.WithExportedCredentialsAndAdminMetadataInJSON(*e.Identity),
+	})
+}
+
+// swagger:route GET /admin/identities/{id}/export identity exportIdentity
+//
+// # Export all Data of an Identity
+//
+// This endpoint returns everything Ory Kratos stores about an identity in a single JSON document, for example
+// to answer data subject access requests. The export contains the identity's traits, metadata, verifiable and
+// recovery addresses, the public parts of its credentials, and its active sessions. Credential secrets such as
+// password hashes are excluded.
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  200: identityExport
+//	  400: errorGeneric
+//	  404: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) exportIdentity(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	iID, err := uuid.FromString(ps.ByName("id"))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithError(err.Error()).WithDebug("could not parse UUID")))
+		return
+	}
+
+	i, err := h.r.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), iID)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	sessions := make([]Session, 0)
+	for page := 1; ; page++ {
+		batch, total, err := h.r.SessionPersister().ListSessionsByIdentity(r.Context(), iID, pointerx.Bool(true), page, x.PagePaginationLimit, uuid.Nil, Expandables{ExpandSessionDevices})
+		if err != nil {
+			h.r.Writer().WriteError(w, r, err)
+			return
+		}
+		sessions = append(sessions, batch...)
+		if len(batch) == 0 || int64(len(sessions)) >= total {
+			break
+		}
+	}
+
+	h.r.Writer().Write(w, r, &IdentityExport{Identity: i, Sessions: sessions})
+}
+
 // Deleted Session Count
 //
 // swagger:model deleteMySessionsCount
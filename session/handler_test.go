@@ -30,6 +30,7 @@ import (
 	"github.com/ory/kratos/corpx"
 	"github.com/ory/x/pagination/keysetpagination"
 	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/assert"
@@ -141,6 +142,77 @@ func TestSessionWhoAmI(t *testing.T) {
 		})
 	})
 
+	t.Run("case=verification_required with revalidate_after", func(t *testing.T) {
+		verifiedAt := sqlxx.NullTime(time.Now().Add(-48 * time.Hour))
+		email := "revalidate" + uuid.Must(uuid.NewV4()).String() + "@bar.sh"
+		i := &identity.Identity{
+			ID:     x.NewUUID(),
+			State:  identity.StateActive,
+			Traits: identity.Traits(`{"email": "` + email + `"}`),
+			VerifiableAddresses: []identity.VerifiableAddress{{
+				Value:      email,
+				Via:        identity.AddressTypeEmail,
+				Verified:   true,
+				Status:     identity.VerifiableAddressStatusCompleted,
+				VerifiedAt: &verifiedAt,
+			}},
+		}
+		h, _ := testhelpers.MockSessionCreateHandlerWithIdentity(t, reg, i)
+		r.GET("/set/revalidate", h)
+
+		run := func(t *testing.T) string {
+			client := testhelpers.NewClientWithCookies(t)
+			testhelpers.MockHydrateCookieClient(t, client, ts.URL+"/set/revalidate")
+
+			res, err := client.Get(ts.URL + RouteWhoami)
+			require.NoError(t, err)
+			body := x.MustReadAll(res.Body)
+			require.EqualValues(t, http.StatusOK, res.StatusCode, "%s", body)
+			return string(body)
+		}
+
+		t.Run("case=disabled", func(t *testing.T) {
+			assert.False(t, gjson.Get(run(t), "verification_required").Bool())
+		})
+
+		t.Run("case=verified within the window", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySelfServiceVerificationRevalidateAfter, "72h")
+			t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeySelfServiceVerificationRevalidateAfter, nil) })
+			assert.False(t, gjson.Get(run(t), "verification_required").Bool())
+		})
+
+		t.Run("case=verified beyond the window", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySelfServiceVerificationRevalidateAfter, "24h")
+			t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeySelfServiceVerificationRevalidateAfter, nil) })
+			body := run(t)
+			assert.True(t, gjson.Get(body, "verification_required").Bool(), body)
+		})
+	})
+
+	t.Run("case=returns the schema of the identity", func(t *testing.T) {
+		schemaID := testhelpers.UseIdentitySchema(t, conf, "file://./stub/identity.schema.json")
+		i := identity.NewIdentity(schemaID)
+		i.Traits = identity.Traits(`{"email":"` + x.NewUUID().String() + `@ory.sh"}`)
+		h, _ := testhelpers.MockSessionCreateHandlerWithIdentity(t, reg, i)
+		r.GET("/set/custom-schema", h)
+
+		client := testhelpers.NewClientWithCookies(t)
+		testhelpers.MockHydrateCookieClient(t, client, ts.URL+"/set/custom-schema")
+
+		res, err := client.Get(ts.URL + RouteWhoami)
+		require.NoError(t, err)
+		body := x.MustReadAll(res.Body)
+		require.EqualValues(t, http.StatusOK, res.StatusCode, "%s", body)
+
+		schemas, err := reg.IdentityTraitsSchemas(ctx)
+		require.NoError(t, err)
+		schema, err := schemas.GetByID(schemaID)
+		require.NoError(t, err)
+
+		assert.Equal(t, schemaID, gjson.GetBytes(body, "identity.schema_id").String(), "%s", body)
+		assert.Equal(t, schema.SchemaURL(conf.SelfPublicURL(ctx)).String(), gjson.GetBytes(body, "identity.schema_url").String(), "%s", body)
+	})
+
 	t.Run("case=http methods", func(t *testing.T) {
 		run := func(t *testing.T, cacheEnabled bool, maxAge time.Duration) {
 			conf.MustSet(ctx, config.ViperKeySessionWhoAmICaching, cacheEnabled)
@@ -244,6 +316,58 @@ func TestSessionWhoAmI(t *testing.T) {
 		assert.Empty(t, res.Header.Get("Ory-Session-Cache-For"))
 	})
 
+	t.Run("case=validate", func(t *testing.T) {
+		run := func(t *testing.T, cacheEnabled bool) {
+			conf.MustSet(ctx, config.ViperKeySessionWhoAmICaching, cacheEnabled)
+			conf.MustSet(ctx, config.ViperKeySessionWhoAmICachingMaxAge, time.Minute)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeySessionWhoAmICaching, nil)
+				conf.MustSet(ctx, config.ViperKeySessionWhoAmICachingMaxAge, nil)
+			})
+			client := testhelpers.NewClientWithCookies(t)
+
+			res, err := client.Get(ts.URL + RouteValidate)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.EqualValues(t, http.StatusUnauthorized, res.StatusCode)
+
+			reg.CSRFHandler().IgnorePath("/set")
+			testhelpers.MockHydrateCookieClient(t, client, ts.URL+"/set")
+
+			res, err = client.Get(ts.URL + RouteValidate)
+			require.NoError(t, err)
+			body := x.MustReadAll(res.Body)
+			require.EqualValues(t, http.StatusOK, res.StatusCode, "%s", body)
+
+			assert.Equal(t, i.ID.String(), res.Header.Get("X-Kratos-Authenticated-Identity-Id"))
+			if cacheEnabled {
+				assert.Equal(t, "60", res.Header.Get("Ory-Session-Cache-For"))
+			} else {
+				assert.Empty(t, res.Header.Get("Ory-Session-Cache-For"))
+			}
+
+			assert.True(t, gjson.GetBytes(body, "active").Bool(), "%s", body)
+			assert.Equal(t, "aal1", gjson.GetBytes(body, "aal").String(), "%s", body)
+			assert.Equal(t, i.ID.String(), gjson.GetBytes(body, "identity_id").String(), "%s", body)
+			assert.True(t, gjson.GetBytes(body, "expires_at").Exists(), "%s", body)
+
+			var keys []string
+			gjson.ParseBytes(body).ForEach(func(key, _ gjson.Result) bool {
+				keys = append(keys, key.String())
+				return true
+			})
+			assert.ElementsMatch(t, []string{"active", "aal", "identity_id", "expires_at"}, keys, "%s", body)
+		}
+
+		t.Run("cache disabled", func(t *testing.T) {
+			run(t, false)
+		})
+
+		t.Run("cache enabled", func(t *testing.T) {
+			run(t, true)
+		})
+	})
+
 	/*
 		t.Run("case=respects AAL config", func(t *testing.T) {
 			conf.MustSet(ctx, config.ViperKeySessionLifespan, "1m")
@@ -838,6 +962,86 @@ func TestHandlerAdminSessionManagement(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("case=should export all identity data without credential secrets", func(t *testing.T) {
+		client := testhelpers.NewClientWithCookies(t)
+		email := "export-" + x.NewUUID().String() + "@ory.sh"
+		i := identity.NewIdentity("")
+		i.Traits = identity.Traits(`{"email":"` + email + `"}`)
+		i.MetadataPublic = []byte(`{"public":"metadata"}`)
+		i.MetadataAdmin = []byte(`{"admin":"metadata"}`)
+		i.SetCredentials(identity.CredentialsTypePassword, identity.Credentials{
+			Type:        identity.CredentialsTypePassword,
+			Identifiers: []string{email},
+			Config:      sqlxx.JSONRawMessage(`{"hashed_password":"$2a$08$.cOYmAd.vCpDOoiVJrO5B.hjTLKQQ6cAK40u8uB.FnZDyPvVvQ9Q."}`),
+		})
+		i.SetCredentials(identity.CredentialsTypeWebAuthn, identity.Credentials{
+			Type:        identity.CredentialsTypeWebAuthn,
+			Identifiers: []string{x.NewUUID().String()},
+			Config:      sqlxx.JSONRawMessage(`{"credentials":[{"id":"Zm9v","public_key":"YmFy","display_name":"my key"}],"user_handle":"YmF6"}`),
+		})
+		i.SetCredentials(identity.CredentialsTypeOIDC, identity.Credentials{
+			Type:        identity.CredentialsTypeOIDC,
+			Identifiers: []string{"github:" + email},
+			Config:      sqlxx.JSONRawMessage(`{"providers":[{"subject":"` + email + `","provider":"github","initial_access_token":"secret-access-token"}]}`),
+		})
+		i.VerifiableAddresses = []identity.VerifiableAddress{*identity.NewVerifiableEmailAddress(email, i.ID)}
+		i.RecoveryAddresses = []identity.RecoveryAddress{*identity.NewRecoveryEmailAddress(email, i.ID)}
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+
+		active := make([]Session, 2)
+		for j := range active {
+			require.NoError(t, faker.FakeData(&active[j]))
+			active[j].Identity = i
+			active[j].Active = true
+			active[j].ExpiresAt = time.Now().Add(time.Hour)
+			require.NoError(t, reg.SessionPersister().UpsertSession(ctx, &active[j]))
+		}
+
+		var inactive Session
+		require.NoError(t, faker.FakeData(&inactive))
+		inactive.Identity = i
+		inactive.Active = false
+		require.NoError(t, reg.SessionPersister().UpsertSession(ctx, &inactive))
+
+		req, _ := http.NewRequest("GET", ts.URL+"/admin/identities/"+i.ID.String()+"/export", nil)
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		body := ioutilx.MustReadAll(res.Body)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+
+		assert.Equal(t, i.ID.String(), gjson.GetBytes(body, "identity.id").String(), "%s", body)
+		assert.Equal(t, email, gjson.GetBytes(body, "identity.traits.email").String(), "%s", body)
+		assert.Equal(t, "metadata", gjson.GetBytes(body, "identity.metadata_public.public").String(), "%s", body)
+		assert.Equal(t, "metadata", gjson.GetBytes(body, "identity.metadata_admin.admin").String(), "%s", body)
+		assert.Equal(t, email, gjson.GetBytes(body, "identity.verifiable_addresses.0.value").String(), "%s", body)
+		assert.Equal(t, email, gjson.GetBytes(body, "identity.recovery_addresses.0.value").String(), "%s", body)
+		assert.Equal(t, email, gjson.GetBytes(body, "identity.credentials.password.identifiers.0").String(), "%s", body)
+		assert.False(t, gjson.GetBytes(body, "identity.credentials.password.config.hashed_password").Exists(), "%s", body)
+		assert.NotContains(t, string(body), "hashed_password")
+		assert.NotContains(t, string(body), "$2a$08$")
+
+		assert.Equal(t, "Zm9v", gjson.GetBytes(body, "identity.credentials.webauthn.config.credentials.0.id").String(), "%s", body)
+		assert.Equal(t, "YmFy", gjson.GetBytes(body, "identity.credentials.webauthn.config.credentials.0.public_key").String(), "%s", body)
+		assert.Equal(t, "my key", gjson.GetBytes(body, "identity.credentials.webauthn.config.credentials.0.display_name").String(), "%s", body)
+		assert.Equal(t, "github", gjson.GetBytes(body, "identity.credentials.oidc.config.providers.0.provider").String(), "%s", body)
+		assert.Equal(t, email, gjson.GetBytes(body, "identity.credentials.oidc.config.providers.0.subject").String(), "%s", body)
+		assert.NotContains(t, string(body), "initial_access_token")
+		assert.NotContains(t, string(body), "secret-access-token")
+
+		assert.ElementsMatch(t,
+			[]string{active[0].ID.String(), active[1].ID.String()},
+			[]string{gjson.GetBytes(body, "sessions.0.id").String(), gjson.GetBytes(body, "sessions.1.id").String()},
+			"%s", body)
+		assert.EqualValues(t, 2, gjson.GetBytes(body, "sessions.#").Int(), "%s", body)
+
+		t.Run("case=unknown identity", func(t *testing.T) {
+			req, _ := http.NewRequest("GET", ts.URL+"/admin/identities/"+x.NewUUID().String()+"/export", nil)
+			res, err := client.Do(req)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		})
+	})
 }
 
 func TestHandlerSelfServiceSessionManagement(t *testing.T) {
@@ -1013,6 +1217,39 @@ func TestHandlerSelfServiceSessionManagement(t *testing.T) {
 		}
 	})
 
+	t.Run("case=whoami should re-sign cookie after secret rotation", func(t *testing.T) {
+		client, _, _ := setup(t)
+
+		var secrets []string
+		for _, s := range conf.SecretsSession(ctx) {
+			secrets = append(secrets, string(s))
+		}
+		conf.MustSet(ctx, config.ViperKeySecretsCookie, append([]string{"a-new-secret-for-signing-cookies"}, secrets...))
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySecretsCookie, nil)
+			conf.MustSet(ctx, config.ViperKeySessionResignOnRead, nil)
+		})
+
+		whoami := func(t *testing.T) []*http.Cookie {
+			res, err := client.Get(ts.URL + "/sessions/whoami")
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			return res.Cookies()
+		}
+
+		t.Run("case=disabled", func(t *testing.T) {
+			assert.Len(t, whoami(t), 0)
+		})
+
+		t.Run("case=enabled", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySessionResignOnRead, true)
+
+			require.Len(t, whoami(t), 1)
+			assert.Len(t, whoami(t), 0, "the re-signed cookie uses the current secret")
+		})
+	})
+
 	t.Run("case=whoami should not issue cookie if request is token based", func(t *testing.T) {
 		_, _, session := setup(t)
 
@@ -0,0 +1,121 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/otelx"
+	"github.com/ory/x/stringsx"
+)
+
+type (
+	// IssuancePolicy decides whether a session may be issued to an identity
+	// which completed a login or registration flow.
+	IssuancePolicy interface {
+		// Allow returns false and the reason to show to the user if the session
+		// must not be issued. Implementations must respect the cancellation of
+		// the context.
+		Allow(ctx context.Context, i *identity.Identity, f flow.Flow) (allow bool, reason string, err error)
+	}
+	IssuancePolicyProvider interface {
+		SessionIssuancePolicy() IssuancePolicy
+	}
+
+	issuancePolicyDependencies interface {
+		config.Provider
+		x.HTTPClientProvider
+		x.TracingProvider
+	}
+
+	// HTTPIssuancePolicy asks the policy service configured in
+	// `session.issuance_policy.url`. All sessions are allowed if no policy
+	// service is configured.
+	HTTPIssuancePolicy struct {
+		d issuancePolicyDependencies
+	}
+
+	issuancePolicyRequest struct {
+		Identity *identity.Identity `json:"identity"`
+		Flow     flow.Flow          `json:"flow"`
+	}
+
+	issuancePolicyResponse struct {
+		Allow  bool   `json:"allow"`
+		Reason string `json:"reason"`
+	}
+)
+
+var _ IssuancePolicy = (*HTTPIssuancePolicy)(nil)
+
+func NewHTTPIssuancePolicy(d issuancePolicyDependencies) *HTTPIssuancePolicy {
+	return &HTTPIssuancePolicy{d: d}
+}
+
+func (p *HTTPIssuancePolicy) Allow(ctx context.Context, i *identity.Identity, f flow.Flow) (allow bool, reason string, err error) {
+	u := p.d.Config().SessionIssuancePolicyURL(ctx)
+	if u == nil {
+		return true, "", nil
+	}
+
+	ctx, span := p.d.Tracer(ctx).Tracer().Start(ctx, "session.HTTPIssuancePolicy.Allow")
+	defer otelx.End(span, &err)
+
+	body, err := json.Marshal(&issuancePolicyRequest{Identity: i, Flow: f})
+	if err != nil {
+		return false, "", errors.WithStack(err)
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return false, "", errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.d.HTTPClient(ctx).Do(req)
+	if err != nil {
+		return false, "", errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to reach the session issuance policy service.").WithWrap(err))
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return false, "", errors.WithStack(herodot.ErrInternalServerError.WithReasonf("The session issuance policy service responded with unexpected status code %d.", res.StatusCode))
+	}
+
+	var decision issuancePolicyResponse
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&decision); err != nil {
+		return false, "", errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to decode the response of the session issuance policy service.").WithWrap(err))
+	}
+
+	return decision.Allow, decision.Reason, nil
+}
+
+// CheckIssuancePolicy returns an error which can be shown to the user if the
+// issuance policy does not allow issuing a session.
+func CheckIssuancePolicy(ctx context.Context, p IssuancePolicy, i *identity.Identity, f flow.Flow) error {
+	allow, reason, err := p.Allow(ctx, i, f)
+	if err != nil {
+		return err
+	}
+	if !allow {
+		return errors.WithStack(herodot.ErrForbidden.
+			WithID(text.ErrIDSessionIssuanceDenied).
+			WithError("session issuance denied").
+			WithReason(stringsx.Coalesce(reason, "You are not allowed to sign in.")))
+	}
+	return nil
+}
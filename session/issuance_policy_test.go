@@ -0,0 +1,101 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package session_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/text"
+	"github.com/ory/x/urlx"
+)
+
+func TestHTTPIssuancePolicy(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+
+	var requests []string
+	policy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, string(body))
+
+		switch gjson.GetBytes(body, "identity.traits.email").String() {
+		case "allowed@ory.sh":
+			_ = json.NewEncoder(w).Encode(map[string]any{"allow": true})
+		case "denied@ory.sh":
+			_ = json.NewEncoder(w).Encode(map[string]any{"allow": false, "reason": "Your device does not meet the security requirements."})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(policy.Close)
+
+	newIdentity := func(email string) *identity.Identity {
+		i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+		i.Traits = identity.Traits(`{"email":"` + email + `"}`)
+		return i
+	}
+
+	f, err := login.NewFlow(conf, time.Minute, "", &http.Request{URL: urlx.ParseOrPanic("/")}, flow.TypeBrowser)
+	require.NoError(t, err)
+
+	t.Run("case=allows all sessions without a policy service", func(t *testing.T) {
+		allow, _, err := reg.SessionIssuancePolicy().Allow(ctx, newIdentity("denied@ory.sh"), f)
+		require.NoError(t, err)
+		assert.True(t, allow)
+		assert.Empty(t, requests)
+	})
+
+	conf.MustSet(ctx, config.ViperKeySessionIssuancePolicyURL, policy.URL)
+
+	t.Run("case=allows the session", func(t *testing.T) {
+		requests = nil
+
+		allow, _, err := reg.SessionIssuancePolicy().Allow(ctx, newIdentity("allowed@ory.sh"), f)
+		require.NoError(t, err)
+		assert.True(t, allow)
+
+		require.Len(t, requests, 1)
+		assert.Equal(t, f.ID.String(), gjson.Get(requests[0], "flow.id").String(), requests[0])
+		require.NoError(t, session.CheckIssuancePolicy(ctx, reg.SessionIssuancePolicy(), newIdentity("allowed@ory.sh"), f))
+	})
+
+	t.Run("case=denies the session", func(t *testing.T) {
+		allow, reason, err := reg.SessionIssuancePolicy().Allow(ctx, newIdentity("denied@ory.sh"), f)
+		require.NoError(t, err)
+		assert.False(t, allow)
+		assert.Equal(t, "Your device does not meet the security requirements.", reason)
+
+		err = session.CheckIssuancePolicy(ctx, reg.SessionIssuancePolicy(), newIdentity("denied@ory.sh"), f)
+		var he *herodot.DefaultError
+		require.ErrorAs(t, err, &he)
+		assert.Equal(t, http.StatusForbidden, he.StatusCode())
+		assert.Equal(t, text.ErrIDSessionIssuanceDenied, he.ID())
+		assert.Equal(t, "Your device does not meet the security requirements.", he.Reason())
+	})
+
+	t.Run("case=fails if the policy service fails", func(t *testing.T) {
+		_, _, err := reg.SessionIssuancePolicy().Allow(ctx, newIdentity("unknown@ory.sh"), f)
+		var he *herodot.DefaultError
+		require.ErrorAs(t, err, &he)
+		assert.Contains(t, he.Reason(), "unexpected status code 400")
+	})
+}
@@ -130,6 +130,10 @@ type Manager interface {
 	// FetchFromRequest creates an HTTP session using cookies.
 	FetchFromRequest(context.Context, *http.Request) (*Session, error)
 
+	// FetchFromRequestWithoutDetails works like FetchFromRequest but does not load the session's devices
+	// nor the identity's credentials and addresses.
+	FetchFromRequestWithoutDetails(context.Context, *http.Request) (*Session, error)
+
 	// PurgeFromRequest removes an HTTP session.
 	PurgeFromRequest(context.Context, http.ResponseWriter, *http.Request) error
 
@@ -5,6 +5,7 @@ package session
 
 import (
 	"context"
+	"crypto/sha256"
 	"net/http"
 	"net/url"
 	"time"
@@ -116,7 +117,8 @@ func (s *ManagerHTTP) RefreshCookie(ctx context.Context, w http.ResponseWriter,
 	}
 
 	expiresAt := getCookieExpiry(cookie)
-	if expiresAt == nil || expiresAt.Before(session.ExpiresAt) {
+	if expiresAt == nil || expiresAt.Before(session.ExpiresAt) ||
+		(s.r.Config().SessionResignOnRead(ctx) && !s.isSignedWithCurrentSecret(ctx, r)) {
 		if err := s.IssueCookie(ctx, w, r, session); err != nil {
 			return err
 		}
@@ -125,6 +127,19 @@ func (s *ManagerHTTP) RefreshCookie(ctx context.Context, w http.ResponseWriter,
 	return nil
 }
 
+// isSignedWithCurrentSecret checks if the session cookie can be decoded with the first
+// secret of `secrets.cookie`, using the same keys as the cookie manager.
+func (s *ManagerHTTP) isSignedWithCurrentSecret(ctx context.Context, r *http.Request) bool {
+	secrets := s.r.Config().SecretsSession(ctx)
+	if len(secrets) < 2 {
+		return true
+	}
+
+	encrypt := sha256.Sum256(secrets[0])
+	cookie, err := sessions.NewCookieStore(secrets[0], encrypt[:]).New(r, s.cookieName(ctx))
+	return err == nil && !cookie.IsNew
+}
+
 func (s *ManagerHTTP) IssueCookie(ctx context.Context, w http.ResponseWriter, r *http.Request, session *Session) (err error) {
 	ctx, span := s.r.Tracer(ctx).Tracer().Start(ctx, "sessions.ManagerHTTP.IssueCookie")
 	defer otelx.End(span, &err)
@@ -143,7 +158,7 @@ func (s *ManagerHTTP) IssueCookie(ctx context.Context, w http.ResponseWriter, r
 		cookie.Options.Domain = domain
 	}
 
-	if alias := s.r.Config().SelfPublicURL(ctx); s.r.Config().SelfPublicURL(ctx).String() != alias.String() {
+	if alias := s.r.Config().SelfPublicURL(ctx); !s.r.Config().SessionUseHostPrefix(ctx) && s.r.Config().SelfPublicURL(ctx).String() != alias.String() {
 		// If a domain alias is detected use that instead.
 		cookie.Options.Domain = alias.Hostname()
 		cookie.Options.Path = alias.Path
@@ -169,6 +184,11 @@ func (s *ManagerHTTP) IssueCookie(ctx context.Context, w http.ResponseWriter, r
 		} else {
 			cookie.Options.MaxAge = int(time.Until(session.ExpiresAt).Seconds())
 		}
+
+		// The configured max age may shorten, but never extend, the cookie's lifetime.
+		if maxAge := int(s.r.Config().SessionCookieMaxAge(ctx).Seconds()); maxAge > 0 && maxAge < cookie.Options.MaxAge {
+			cookie.Options.MaxAge = maxAge
+		}
 	}
 
 	cookie.Values["session_token"] = session.Token
@@ -223,20 +243,35 @@ func (s *ManagerHTTP) extractToken(r *http.Request) string {
 
 func (s *ManagerHTTP) FetchFromRequest(ctx context.Context, r *http.Request) (_ *Session, err error) {
 	ctx, span := s.r.Tracer(ctx).Tracer().Start(ctx, "sessions.ManagerHTTP.FetchFromRequest")
-	defer func() {
-		if e := new(ErrNoActiveSessionFound); errors.As(err, &e) {
-			span.End()
-		} else {
-			otelx.End(span, &err)
-		}
-	}()
+	defer endFetchSpan(span, &err)
+
+	return s.fetchFromRequest(ctx, r, ExpandEverything, identity.ExpandDefault)
+}
+
+func (s *ManagerHTTP) FetchFromRequestWithoutDetails(ctx context.Context, r *http.Request) (_ *Session, err error) {
+	ctx, span := s.r.Tracer(ctx).Tracer().Start(ctx, "sessions.ManagerHTTP.FetchFromRequestWithoutDetails")
+	defer endFetchSpan(span, &err)
+
+	// The identity is still loaded so that sessions of inactive identities are rejected.
+	return s.fetchFromRequest(ctx, r, ExpandDefault, identity.ExpandNothing)
+}
+
+// endFetchSpan does not record a missing session as a span error.
+func endFetchSpan(span trace.Span, err *error) {
+	if e := new(ErrNoActiveSessionFound); errors.As(*err, &e) {
+		span.End()
+	} else {
+		otelx.End(span, err)
+	}
+}
 
+func (s *ManagerHTTP) fetchFromRequest(ctx context.Context, r *http.Request, expand Expandables, identityExpand identity.Expandables) (*Session, error) {
 	token := s.extractToken(r.WithContext(ctx))
 	if token == "" {
 		return nil, errors.WithStack(NewErrNoCredentialsForSession())
 	}
 
-	se, err := s.r.SessionPersister().GetSessionByToken(ctx, token, ExpandEverything, identity.ExpandDefault)
+	se, err := s.r.SessionPersister().GetSessionByToken(ctx, token, expand, identityExpand)
 	if err != nil {
 		if errors.Is(err, herodot.ErrNotFound) || errors.Is(err, sqlcon.ErrNoRows) {
 			return nil, errors.WithStack(NewErrNoActiveSessionFound())
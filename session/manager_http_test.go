@@ -148,6 +148,41 @@ func TestManagerHTTP(t *testing.T) {
 			assert.EqualValues(t, true, actual.HttpOnly)
 			assert.EqualValues(t, true, actual.Secure)
 		})
+
+		t.Run("case=with host prefix", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySessionUseHostPrefix, true)
+			conf.MustSet(ctx, "dev", true)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeySessionUseHostPrefix, false)
+				conf.MustSet(ctx, "dev", false)
+			})
+
+			actual := getCookie(t, httptest.NewRequest("GET", "https://baseurl.com/bar", nil))
+			assert.EqualValues(t, "__Host-ory_kratos_session", actual.Name)
+			assert.EqualValues(t, "", actual.Domain, "Domain must not be set for __Host- cookies")
+			assert.EqualValues(t, "/", actual.Path, "Path must be / for __Host- cookies")
+			assert.EqualValues(t, true, actual.Secure, "Secure is enforced even in dev mode")
+		})
+
+		t.Run("case=with max age override", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySessionLifespan, "24h")
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeySessionLifespan, nil)
+				conf.MustSet(ctx, config.ViperKeySessionCookieMaxAge, nil)
+			})
+
+			actual := getCookie(t, httptest.NewRequest("GET", "https://baseurl.com/bar", nil))
+			assert.EqualValues(t, 24*60*60, actual.MaxAge, "Max-Age is derived from the lifespan by default")
+
+			conf.MustSet(ctx, config.ViperKeySessionCookieMaxAge, "1h")
+			actual = getCookie(t, httptest.NewRequest("GET", "https://baseurl.com/bar", nil))
+			assert.EqualValues(t, 60*60, actual.MaxAge)
+
+			s.ExpiresAt = time.Now().Add(30 * time.Minute)
+			t.Cleanup(func() { s.ExpiresAt = time.Time{} })
+			actual = getCookie(t, httptest.NewRequest("GET", "https://baseurl.com/bar", nil))
+			assert.InDelta(t, 30*60, actual.MaxAge, 5, "Max-Age never outlives the session")
+		})
 	})
 
 	t.Run("suite=SessionAddAuthenticationMethod", func(t *testing.T) {
@@ -145,6 +145,12 @@ type Session struct {
 	// It is only set when the `tokenize` query parameter was set to a valid tokenize template during calls to `/session/whoami`.
 	Tokenized string `json:"tokenized,omitempty" faker:"-" db:"-"`
 
+	// VerificationRequired is true if one of the identity's addresses was verified longer ago than
+	// `selfservice.flows.verification.revalidate_after` and needs to be verified again.
+	//
+	// It is only set during calls to `/sessions/whoami`.
+	VerificationRequired bool `json:"verification_required,omitempty" faker:"-" db:"-"`
+
 	// The Session Token
 	//
 	// The token of this session.
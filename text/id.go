@@ -110,6 +110,10 @@ const (
 	InfoSelfServiceVerificationEmailWithCodeSent                     // 1080003
 )
 
+const (
+	InfoSelfServiceCustomMessage ID = 1090000 + iota // 1090000
+)
+
 const (
 	ErrorValidation ID = 4000000 + iota
 	ErrorValidationGeneric
@@ -148,6 +152,7 @@ const (
 	ErrorValidationPasswordTooManyBreaches
 	ErrorValidationNoCodeUser
 	ErrorValidationTraitsMismatch
+	ErrorValidationWebAuthnChallengeExpired
 )
 
 const (
@@ -11,6 +11,8 @@ const (
 	ErrIDSelfServiceFlowDisabled                       = "self_service_flow_disabled"
 	ErrIDSelfServiceBrowserLocationChangeRequiredError = "browser_location_change_required"
 	ErrIDSelfServiceFlowReplaced                       = "self_service_flow_replaced"
+	ErrIDSelfServiceSettingsConflict                   = "self_service_settings_conflict"
+	ErrIDSelfServiceFlowLocked                         = "self_service_flow_locked"
 
 	ErrIDAlreadyLoggedIn             = "session_already_available"
 	ErrIDAddressNotVerified          = "session_verified_address_required"
@@ -20,6 +22,14 @@ const (
 	ErrNoActiveSession               = "session_inactive"
 	ErrIDRedirectURLNotAllowed       = "self_service_flow_return_to_forbidden"
 	ErrIDInitiatedBySomeoneElse      = "security_identity_mismatch"
+	ErrIDSessionIssuanceDenied       = "session_issuance_denied"
+	ErrIDLoginRequired               = "login_required"
 
 	ErrIDCSRF = "security_csrf_violation"
+
+	ErrIDMaintenanceReadOnly = "maintenance_read_only"
+
+	ErrIDFlowInitRateLimited = "self_service_flow_init_rate_limited"
+
+	ErrIDRequestTimeout = "request_timeout"
 )
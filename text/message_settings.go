@@ -182,28 +182,32 @@ func NewInfoSelfServiceRegisterWebAuthnDisplayName() *Message {
 	}
 }
 
-func NewInfoSelfServiceRemoveWebAuthn(name string, createdAt time.Time) *Message {
+func NewInfoSelfServiceRemoveWebAuthn(name string, createdAt time.Time, backupEligible, backupState bool) *Message {
 	return &Message{
 		ID:   InfoSelfServiceSettingsRemoveWebAuthn,
 		Text: fmt.Sprintf("Remove security key \"%s\"", name),
 		Type: Info,
 		Context: context(map[string]any{
-			"display_name":  name,
-			"added_at":      createdAt,
-			"added_at_unix": createdAt.Unix(),
+			"display_name":    name,
+			"added_at":        createdAt,
+			"added_at_unix":   createdAt.Unix(),
+			"backup_eligible": backupEligible,
+			"backup_state":    backupState,
 		}),
 	}
 }
 
-func NewInfoSelfServiceRemovePasskey(name string, createdAt time.Time) *Message {
+func NewInfoSelfServiceRemovePasskey(name string, createdAt time.Time, backupEligible, backupState bool) *Message {
 	return &Message{
 		ID:   InfoSelfServiceSettingsRemovePasskey,
 		Text: fmt.Sprintf("Remove passkey \"%s\"", name),
 		Type: Info,
 		Context: context(map[string]any{
-			"display_name":  name,
-			"added_at":      createdAt,
-			"added_at_unix": createdAt.Unix(),
+			"display_name":    name,
+			"added_at":        createdAt,
+			"added_at_unix":   createdAt.Unix(),
+			"backup_eligible": backupEligible,
+			"backup_state":    backupState,
 		}),
 	}
 }
@@ -13,3 +13,11 @@ func NewErrorSystemGeneric(reason string) *Message {
 		}),
 	}
 }
+
+func NewInfoSelfServiceCustomMessage(message string, t UITextType) *Message {
+	return &Message{
+		ID:   InfoSelfServiceCustomMessage,
+		Text: message,
+		Type: t,
+	}
+}
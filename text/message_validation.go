@@ -416,3 +416,11 @@ func NewErrorValidationTraitsMismatch() *Message {
 		Type: Error,
 	}
 }
+
+func NewErrorValidationWebAuthnChallengeExpired() *Message {
+	return &Message{
+		ID:   ErrorValidationWebAuthnChallengeExpired,
+		Text: "The security key challenge expired, please try again.",
+		Type: Error,
+	}
+}
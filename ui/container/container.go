@@ -58,6 +58,36 @@ type Container struct {
 
 	// Messages contains all global form messages and errors.
 	Messages text.Messages `json:"messages,omitempty"`
+
+	// ErrorMessages contains all global form messages of type `error`.
+	//
+	// It is computed from `messages` when the container is rendered and is not stored.
+	ErrorMessages text.Messages `json:"error_messages,omitempty" faker:"-"`
+
+	// InfoMessages contains all global form messages which are not of type `error`.
+	//
+	// It is computed from `messages` when the container is rendered and is not stored.
+	InfoMessages text.Messages `json:"info_messages,omitempty" faker:"-"`
+
+	// Meta contains optional paging information for node groups, keyed by the node group.
+	//
+	// It allows UIs to lazy-render groups with many nodes.
+	Meta map[node.UiNodeGroup]*GroupMeta `json:"meta,omitempty" faker:"-"`
+}
+
+// GroupMeta contains paging information for the nodes of a node group.
+//
+// swagger:model uiContainerGroupMeta
+type GroupMeta struct {
+	// Page is the zero-based page of the group's nodes included in the container.
+	//
+	// required: true
+	Page int64 `json:"page"`
+
+	// TotalCount is the total number of nodes in the group.
+	//
+	// required: true
+	TotalCount int64 `json:"total_count"`
 }
 
 // New returns an empty container.
@@ -336,12 +366,37 @@ func (c *Container) AddMessage(group node.UiNodeGroup, err *text.Message, setFor
 	}
 }
 
+// MarshalJSON computes `error_messages` and `info_messages` by splitting `messages`
+// by their type.
+func (c Container) MarshalJSON() ([]byte, error) {
+	type local Container
+	out := local(c)
+	out.ErrorMessages, out.InfoMessages = nil, nil
+	for _, m := range c.Messages {
+		if m.Type == text.Error {
+			out.ErrorMessages = append(out.ErrorMessages, m)
+		} else {
+			out.InfoMessages = append(out.InfoMessages, m)
+		}
+	}
+
+	return json.Marshal(out)
+}
+
 func (c *Container) Scan(value interface{}) error {
 	return sqlxx.JSONScan(c, value)
 }
 
+// Value stores the container without the computed `error_messages` and `info_messages`
+// arrays, which are only added to API responses.
 func (c *Container) Value() (driver.Value, error) {
-	return sqlxx.JSONValue(c)
+	type local Container
+	if c == nil {
+		return sqlxx.JSONValue((*local)(nil))
+	}
+	out := local(*c)
+	out.ErrorMessages, out.InfoMessages = nil, nil
+	return sqlxx.JSONValue(&out)
 }
 
 func addPrefix(name, prefix, separator string) string {
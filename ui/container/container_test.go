@@ -24,7 +24,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 
+	kratos "github.com/ory/kratos/internal/httpclient"
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/text"
 )
@@ -313,6 +315,41 @@ func TestContainer(t *testing.T) {
 		assert.Empty(t, c.Nodes.Find("2").Attributes.(*node.InputAttributes).FieldValue)
 	})
 
+	t.Run("method=MarshalJSON", func(t *testing.T) {
+		c := New("")
+		require.NoError(t, c.ParseError(node.DefaultGroup, schema.NewInvalidCredentialsError()))
+		c.AddMessage(node.DefaultGroup, &text.Message{ID: 1, Type: text.Info, Text: "info"})
+
+		raw, err := json.Marshal(c)
+		require.NoError(t, err)
+
+		var actual struct {
+			Messages      text.Messages `json:"messages"`
+			ErrorMessages text.Messages `json:"error_messages"`
+			InfoMessages  text.Messages `json:"info_messages"`
+		}
+		require.NoError(t, json.Unmarshal(raw, &actual))
+
+		assert.Len(t, actual.Messages, 2, "%s", raw)
+		assert.EqualValues(t, text.Messages{*text.NewErrorValidationInvalidCredentials()}, actual.ErrorMessages, "%s", raw)
+		assert.EqualValues(t, text.Messages{{ID: 1, Type: text.Info, Text: "info"}}, actual.InfoMessages, "%s", raw)
+
+		t.Run("case=omits empty arrays", func(t *testing.T) {
+			raw, err := json.Marshal(New(""))
+			require.NoError(t, err)
+			assert.NotContains(t, string(raw), "error_messages")
+			assert.NotContains(t, string(raw), "info_messages")
+		})
+
+		t.Run("case=does not store computed arrays", func(t *testing.T) {
+			value, err := c.Value()
+			require.NoError(t, err)
+			assert.Contains(t, value, "messages")
+			assert.NotContains(t, value, "error_messages")
+			assert.NotContains(t, value, "info_messages")
+		})
+	})
+
 	t.Run("method=remove", func(t *testing.T) {
 		c := Container{
 			Nodes: node.Nodes{
@@ -328,3 +365,43 @@ func TestContainer(t *testing.T) {
 		require.EqualValues(t, "bar", c.Nodes[0].Attributes.GetValue())
 	})
 }
+
+func TestContainerMeta(t *testing.T) {
+	t.Run("case=payloads without meta decode", func(t *testing.T) {
+		raw := []byte(`{"action":"/foo","method":"POST","nodes":[]}`)
+
+		var c Container
+		require.NoError(t, json.Unmarshal(raw, &c))
+		assert.Nil(t, c.Meta)
+
+		var sdk kratos.UiContainer
+		require.NoError(t, json.Unmarshal(raw, &sdk))
+		assert.False(t, sdk.HasMeta())
+
+		out, err := json.Marshal(sdk)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(raw), string(out))
+	})
+
+	t.Run("case=meta round-trips", func(t *testing.T) {
+		c := New("/foo")
+		c.Meta = map[node.UiNodeGroup]*GroupMeta{node.WebAuthnGroup: {Page: 1, TotalCount: 42}}
+
+		raw, err := json.Marshal(c)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"page":1,"total_count":42}`, gjson.GetBytes(raw, "meta.webauthn").Raw)
+
+		var sdk kratos.UiContainer
+		require.NoError(t, json.Unmarshal(raw, &sdk))
+		require.True(t, sdk.HasMeta())
+		assert.EqualValues(t, 1, sdk.GetMeta()["webauthn"].Page)
+		assert.EqualValues(t, 42, sdk.GetMeta()["webauthn"].TotalCount)
+
+		out, err := json.Marshal(sdk)
+		require.NoError(t, err)
+
+		var actual Container
+		require.NoError(t, json.Unmarshal(out, &actual))
+		assert.Equal(t, c.Meta, actual.Meta)
+	})
+}
@@ -97,6 +97,14 @@ type InputAttributes struct {
 	// used for WebAuthn.
 	OnLoad string `json:"onload,omitempty"`
 
+	// OnClickTrigger may contain the name of a function defined in webauthn.js which should
+	// be called on click. It is set instead of OnClick if inline scripts are disabled.
+	OnClickTrigger string `json:"onclickTrigger,omitempty"`
+
+	// OnLoadTrigger may contain the name of a function defined in webauthn.js which should
+	// be called on load. It is set instead of OnLoad if inline scripts are disabled.
+	OnLoadTrigger string `json:"onloadTrigger,omitempty"`
+
 	// NodeType represents this node's types. It is a mirror of `node.type` and
 	// is primarily used to allow compatibility with OpenAPI 3.0.  In this struct it technically always is "input".
 	//
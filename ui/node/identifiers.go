@@ -28,6 +28,7 @@ const (
 	WebAuthnRegister            = "webauthn_register"
 	WebAuthnLogin               = "webauthn_login"
 	WebAuthnLoginTrigger        = "webauthn_login_trigger"
+	WebAuthnChallenge           = "webauthn_challenge"
 	WebAuthnRegisterDisplayName = "webauthn_register_displayname"
 	WebAuthnRemove              = "webauthn_remove"
 	WebAuthnScript              = "webauthn_script"
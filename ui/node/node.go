@@ -110,6 +110,35 @@ type Meta struct {
 	// If you wish to use other titles or labels implement that directly in
 	// your UI.
 	Label *text.Message `json:"label,omitempty"`
+
+	// PasswordPolicy contains the password requirements, if this node is a
+	// password input for a new password.
+	PasswordPolicy *PasswordPolicy `json:"password_policy,omitempty"`
+}
+
+// A Password Policy
+//
+// Describes the requirements a new password has to fulfill, so that UIs can
+// render them before the form is submitted.
+//
+// swagger:model uiNodePasswordPolicy
+type PasswordPolicy struct {
+	// MinLength is the minimum length of the password.
+	//
+	// required: true
+	MinLength uint `json:"min_length"`
+
+	// BreachCheckEnabled is true if the password is checked against known
+	// data breaches.
+	//
+	// required: true
+	BreachCheckEnabled bool `json:"breach_check_enabled"`
+
+	// IdentifierSimilarityCheckEnabled is true if the password must not be
+	// too similar to the user's identifier.
+	//
+	// required: true
+	IdentifierSimilarityCheckEnabled bool `json:"identifier_similarity_check_enabled"`
 }
 
 // Used for en/decoding the Attributes field.
@@ -138,6 +167,14 @@ func (n *Node) WithMetaLabel(label *text.Message) *Node {
 	return n
 }
 
+func (n *Node) WithMetaPasswordPolicy(policy *PasswordPolicy) *Node {
+	if n.Meta == nil {
+		n.Meta = new(Meta)
+	}
+	n.Meta.PasswordPolicy = policy
+	return n
+}
+
 func (n *Node) GetValue() interface{} {
 	return n.Attributes.GetValue()
 }
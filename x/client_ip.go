@@ -0,0 +1,67 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP returns the IP of the client that sent the request.
+//
+// Headers such as X-Forwarded-For can be set by anyone and are therefore only honored if the
+// remote address of the connection is one of the trusted proxies. In that case, the
+// X-Forwarded-For chain is walked from right to left and the first address that is not a
+// trusted proxy is returned.
+func ClientIP(r *http.Request, trusted []*net.IPNet) net.IP {
+	ip := parseIP(r.RemoteAddr)
+	if ip == nil || !containsIP(trusted, ip) {
+		return ip
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseIP(hops[i])
+		if hop == nil {
+			return ip
+		}
+		ip = hop
+		if !containsIP(trusted, hop) {
+			return hop
+		}
+	}
+	return ip
+}
+
+// RequestHost returns the host the client sent the request to.
+//
+// The X-Forwarded-Host header is only honored if the remote address of the connection is one
+// of the trusted proxies. Otherwise, the Host header of the request is returned.
+func RequestHost(r *http.Request, trusted []*net.IPNet) string {
+	if ip := parseIP(r.RemoteAddr); ip != nil && containsIP(trusted, ip) {
+		hosts := strings.Split(r.Header.Get("X-Forwarded-Host"), ",")
+		if host := strings.TrimSpace(hosts[0]); host != "" {
+			return host
+		}
+	}
+	return r.Host
+}
+
+func parseIP(addr string) net.IP {
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	trusted := []*net.IPNet{proxies}
+
+	for _, tc := range []struct {
+		name     string
+		remote   string
+		forwards []string
+		expected string
+	}{
+		{name: "remote address", remote: "192.0.2.10:1234", expected: "192.0.2.10"},
+		{name: "ipv6 remote address", remote: "[2001:db8::1]:1234", expected: "2001:db8::1"},
+		{name: "forwarded header from untrusted remote", remote: "192.0.2.10:1234", forwards: []string{"198.51.100.1"}, expected: "192.0.2.10"},
+		{name: "forwarded header from trusted proxy", remote: "10.0.0.1:1234", forwards: []string{"198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "spoofed forwarded hop", remote: "10.0.0.1:1234", forwards: []string{"203.0.113.1, 198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "chained trusted proxies", remote: "10.0.0.1:1234", forwards: []string{"198.51.100.1", "10.0.0.2"}, expected: "198.51.100.1"},
+		{name: "invalid forwarded hop", remote: "10.0.0.1:1234", forwards: []string{"not-an-ip"}, expected: "10.0.0.1"},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tc.remote
+			for _, f := range tc.forwards {
+				r.Header.Add("X-Forwarded-For", f)
+			}
+			assert.Equal(t, tc.expected, ClientIP(r, trusted).String())
+		})
+	}
+}
+
+func TestRequestHost(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	trusted := []*net.IPNet{proxies}
+
+	for _, tc := range []struct {
+		name     string
+		remote   string
+		forward  string
+		expected string
+	}{
+		{name: "host header", remote: "192.0.2.10:1234", expected: "auth.tenant-a.com"},
+		{name: "forwarded host from untrusted remote", remote: "192.0.2.10:1234", forward: "auth.tenant-b.com", expected: "auth.tenant-a.com"},
+		{name: "forwarded host from trusted proxy", remote: "10.0.0.1:1234", forward: "auth.tenant-b.com", expected: "auth.tenant-b.com"},
+		{name: "first of several forwarded hosts", remote: "10.0.0.1:1234", forward: "auth.tenant-b.com, internal", expected: "auth.tenant-b.com"},
+		{name: "trusted proxy without forwarded host", remote: "10.0.0.1:1234", expected: "auth.tenant-a.com"},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "https://auth.tenant-a.com/", nil)
+			r.RemoteAddr = tc.remote
+			if tc.forward != "" {
+				r.Header.Set("X-Forwarded-Host", tc.forward)
+			}
+			assert.Equal(t, tc.expected, RequestHost(r, trusted))
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/negroni"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/text"
+)
+
+var ErrFlowInitRateLimited = herodot.DefaultError{
+	IDField:     text.ErrIDFlowInitRateLimited,
+	CodeField:   http.StatusTooManyRequests,
+	StatusField: http.StatusText(http.StatusTooManyRequests),
+	ErrorField:  "Too many flows were initialized.",
+	ReasonField: "Too many flows were initialized from your network. Please try again in a minute.",
+}
+
+type (
+	flowInitRateLimitDependencies interface {
+		config.Provider
+		WriterProvider
+	}
+
+	// FlowErrorForwarder sends browsers to the error user interface, e.g. the
+	// Forward method of the self-service error manager.
+	FlowErrorForwarder func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error)
+
+	// flowInitRateLimiter counts flow initializations per IP in fixed one minute windows.
+	// The counts are kept in memory, so every instance enforces the limit on its own.
+	flowInitRateLimiter struct {
+		sync.Mutex
+		window time.Time
+		counts map[string]int
+	}
+)
+
+// flowInitPaths are the public paths which initialize login, registration, recovery, and verification flows.
+var flowInitPaths = []string{
+	"/self-service/login/browser",
+	"/self-service/login/api",
+	"/self-service/registration/browser",
+	"/self-service/registration/api",
+	"/self-service/recovery/browser",
+	"/self-service/recovery/api",
+	"/self-service/verification/browser",
+	"/self-service/verification/api",
+}
+
+// FlowInitRateLimitMiddleware answers flow initializations with 429 Too Many Requests once a
+// client IP exceeds `selfservice.flows.init_rate_limit.max_per_minute`. Browser flows are
+// forwarded to the error user interface instead.
+func FlowInitRateLimitMiddleware(reg flowInitRateLimitDependencies, forward FlowErrorForwarder) negroni.HandlerFunc {
+	limiter := &flowInitRateLimiter{counts: map[string]int{}}
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		ctx := r.Context()
+		limit := reg.Config().SelfServiceFlowInitRateLimitMaxPerMinute(ctx)
+		if limit <= 0 || !isFlowInitPath(r.URL.Path) {
+			next(w, r)
+			return
+		}
+
+		ip := r.RemoteAddr
+		if clientIP := ClientIP(r, reg.Config().PublicTrustedProxies(ctx)); clientIP != nil {
+			ip = clientIP.String()
+		}
+
+		if !limiter.allow(ip, limit, time.Now()) {
+			err := errors.WithStack(ErrFlowInitRateLimited.WithDebugf("The configuration key %s is set to %d.", config.ViperKeySelfServiceFlowsInitRateLimitMaxPerMinute, limit))
+			if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/browser") {
+				forward(ctx, w, r, err)
+				return
+			}
+			reg.Writer().WriteError(w, r, err)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (l *flowInitRateLimiter) allow(ip string, limit int, now time.Time) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	// Starting a new window drops the counts of the previous one, which keeps the map from growing.
+	if window := now.Truncate(time.Minute); !window.Equal(l.window) {
+		l.window = window
+		l.counts = map[string]int{}
+	}
+
+	l.counts[ip]++
+	return l.counts[ip] <= limit
+}
+
+func isFlowInitPath(path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	for _, p := range flowInitPaths {
+		if path == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/negroni"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/x"
+)
+
+func TestFlowInitRateLimitMiddleware(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeyPublicTrustedProxies, []string{"127.0.0.0/8", "::1/128"})
+
+	n := negroni.New()
+	n.UseFunc(x.FlowInitRateLimitMiddleware(reg, reg.SelfServiceErrorManager().Forward))
+	n.UseHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	ts := httptest.NewServer(n)
+	t.Cleanup(ts.Close)
+
+	client := ts.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	get := func(t *testing.T, path, ip string) *http.Response {
+		req, err := http.NewRequest("GET", ts.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Forwarded-For", ip)
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res
+	}
+
+	t.Run("case=unlimited by default", func(t *testing.T) {
+		for range 5 {
+			assert.Equal(t, http.StatusOK, get(t, "/self-service/login/browser", "54.155.246.1").StatusCode)
+		}
+	})
+
+	t.Run("case=throttles rapid inits from one ip", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceFlowsInitRateLimitMaxPerMinute, 2)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceFlowsInitRateLimitMaxPerMinute, nil)
+		})
+
+		assert.Equal(t, http.StatusOK, get(t, "/self-service/login/api", "54.155.246.2").StatusCode)
+		assert.Equal(t, http.StatusOK, get(t, "/self-service/registration/browser", "54.155.246.2").StatusCode)
+		assert.Equal(t, http.StatusTooManyRequests, get(t, "/self-service/recovery/api", "54.155.246.2").StatusCode)
+
+		res := get(t, "/self-service/verification/browser", "54.155.246.2")
+		assert.Equal(t, http.StatusSeeOther, res.StatusCode, "browsers are sent to the error ui")
+		assert.Contains(t, res.Header.Get("Location"), conf.SelfServiceFlowErrorURL(ctx).String())
+
+		assert.Equal(t, http.StatusOK, get(t, "/self-service/verification/browser", "54.155.246.3").StatusCode, "other IPs are not throttled")
+		assert.Equal(t, http.StatusOK, get(t, "/self-service/login/flows", "54.155.246.2").StatusCode, "other endpoints are not throttled")
+	})
+
+	t.Run("case=ignores forwarded addresses from untrusted peers", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceFlowsInitRateLimitMaxPerMinute, 1)
+		conf.MustSet(ctx, config.ViperKeyPublicTrustedProxies, []string{})
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceFlowsInitRateLimitMaxPerMinute, nil)
+			conf.MustSet(ctx, config.ViperKeyPublicTrustedProxies, []string{"127.0.0.0/8", "::1/128"})
+		})
+
+		assert.Equal(t, http.StatusOK, get(t, "/self-service/login/api", "54.155.246.4").StatusCode)
+		assert.Equal(t, http.StatusTooManyRequests, get(t, "/self-service/login/api", "54.155.246.5").StatusCode, "rotating the header does not bypass the limit")
+	})
+}
@@ -6,6 +6,7 @@ package x
 import (
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
 
 	"github.com/ory/kratos/text"
@@ -27,6 +28,7 @@ type secureRedirectOptions struct {
 	defaultReturnTo *url.URL
 	returnTo        string
 	sourceURL       string
+	requireHTTPS    bool
 }
 
 type SecureRedirectOption func(*secureRedirectOptions)
@@ -38,6 +40,14 @@ func SecureRedirectAllowURLs(urls []url.URL) SecureRedirectOption {
 	}
 }
 
+// SecureRedirectRequireHTTPS rejects `?return_to=` values which do not use the
+// https scheme, even if they are part of the allow list.
+func SecureRedirectRequireHTTPS(require bool) SecureRedirectOption {
+	return func(o *secureRedirectOptions) {
+		o.requireHTTPS = o.requireHTTPS || require
+	}
+}
+
 // SecureRedirectUseSourceURL uses the given source URL (checks the `?return_to` value)
 // instead of r.URL.
 func SecureRedirectUseSourceURL(source string) SecureRedirectOption {
@@ -78,6 +88,27 @@ func SecureRedirectToIsAllowedHost(returnTo *url.URL, allowed url.URL) bool {
 	return strings.EqualFold(allowed.Host, returnTo.Host)
 }
 
+// SecureRedirectToIsAllowedPath validates if the redirect_to param is within the path subtree of an allowed URL.
+// An allowed URL without a path allows all paths. A trailing `/*` is optional, `https://foo.bar/callback/*`
+// and `https://foo.bar/callback` both allow `https://foo.bar/callback/x` but not `https://foo.bar/callback-x`.
+func SecureRedirectToIsAllowedPath(returnTo *url.URL, allowed url.URL) bool {
+	prefix := strings.TrimSuffix(strings.TrimSuffix(allowed.Path, "*"), "/")
+	if prefix == "" {
+		return true
+	}
+
+	// Browsers treat backslashes as path separators and some servers decode encoded separators, so neither can be
+	// checked against the allowed subtree reliably.
+	escaped := strings.ToLower(returnTo.EscapedPath())
+	if strings.Contains(returnTo.Path, `\`) || strings.Contains(escaped, "%2f") || strings.Contains(escaped, "%5c") {
+		return false
+	}
+
+	// Dot segments are resolved by the browser, so they must not be used to escape the allowed subtree.
+	p := path.Clean(stringsx.Coalesce(returnTo.Path, "/"))
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
+
 // TakeOverReturnToParameter carries over the return_to parameter to a new URL
 // If `from` does not contain the `return_to` query parameter, the first non-empty value from `fallback` is used instead.
 func TakeOverReturnToParameter(from string, to string, fallback ...string) (string, error) {
@@ -133,12 +164,17 @@ func SecureRedirectTo(r *http.Request, defaultReturnTo *url.URL, opts ...SecureR
 	returnTo.Host = stringsx.Coalesce(returnTo.Host, o.defaultReturnTo.Host)
 	returnTo.Scheme = stringsx.Coalesce(returnTo.Scheme, o.defaultReturnTo.Scheme)
 
+	if o.requireHTTPS && !strings.EqualFold(returnTo.Scheme, "https") {
+		return nil, errors.WithStack(herodot.ErrBadRequest.
+			WithID(text.ErrIDRedirectURLNotAllowed).
+			WithReasonf("Requested return_to URL %q is not allowed.", returnTo).
+			WithDebug("Only https return_to URLs are allowed."))
+	}
+
 	for _, allowed := range o.allowlist {
 		if strings.EqualFold(allowed.Scheme, returnTo.Scheme) &&
 			SecureRedirectToIsAllowedHost(returnTo, allowed) &&
-			strings.HasPrefix(
-				stringsx.Coalesce(returnTo.Path, "/"),
-				stringsx.Coalesce(allowed.Path, "/")) {
+			SecureRedirectToIsAllowedPath(returnTo, allowed) {
 			return returnTo, nil
 		}
 	}
@@ -169,6 +205,7 @@ func SecureContentNegotiationRedirection(
 			append([]SecureRedirectOption{
 				SecureRedirectUseSourceURL(requestURL),
 				SecureRedirectAllowURLs(c.SelfServiceBrowserAllowedReturnToDomains(r.Context())),
+				SecureRedirectRequireHTTPS(c.SelfServiceBrowserRequireHTTPSReturnTo(r.Context())),
 				SecureRedirectAllowSelfServiceURLs(c.SelfPublicURL(r.Context())),
 			}, opts...)...,
 		)
@@ -104,6 +104,36 @@ func TestSecureRedirectToIsAllowedHost(t *testing.T) {
 	}
 }
 
+func TestSecureRedirectToIsAllowedPath(t *testing.T) {
+	type testCase struct {
+		allowedURL  string
+		redirectURL string
+		valid       bool
+	}
+	tests := map[string]testCase{
+		"case=No path allows all paths":                {allowedURL: "https://foo.bar", redirectURL: "https://foo.bar/redir", valid: true},
+		"case=Root path allows all paths":              {allowedURL: "https://foo.bar/", redirectURL: "https://foo.bar/redir", valid: true},
+		"case=Subtree is allowed":                      {allowedURL: "https://foo.bar/kratos-callback/*", redirectURL: "https://foo.bar/kratos-callback/x", valid: true},
+		"case=Subtree root is allowed":                 {allowedURL: "https://foo.bar/kratos-callback/*", redirectURL: "https://foo.bar/kratos-callback", valid: true},
+		"case=Subtree without wildcard is allowed":     {allowedURL: "https://foo.bar/kratos-callback", redirectURL: "https://foo.bar/kratos-callback/x", valid: true},
+		"case=Other path is not allowed":               {allowedURL: "https://foo.bar/kratos-callback/*", redirectURL: "https://foo.bar/other", valid: false},
+		"case=Sibling with same prefix is not allowed": {allowedURL: "https://foo.bar/kratos-callback", redirectURL: "https://foo.bar/kratos-callback-x", valid: false},
+		"case=Dot segments are not allowed to escape":  {allowedURL: "https://foo.bar/kratos-callback/*", redirectURL: "https://foo.bar/kratos-callback/../other", valid: false},
+		"case=Backslashes are not allowed to escape":   {allowedURL: "https://foo.bar/kratos-callback/*", redirectURL: `https://foo.bar/kratos-callback/..\other`, valid: false},
+		"case=Encoded slashes are not allowed":         {allowedURL: "https://foo.bar/kratos-callback/*", redirectURL: "https://foo.bar/kratos-callback/..%2Fother", valid: false},
+		"case=Encoded backslashes are not allowed":     {allowedURL: "https://foo.bar/kratos-callback/*", redirectURL: "https://foo.bar/kratos-callback/..%5cother", valid: false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			allowedURL, err := url.Parse(tc.allowedURL)
+			require.NoError(t, err)
+			redirectURL, err := url.Parse(tc.redirectURL)
+			require.NoError(t, err)
+			assert.Equal(t, tc.valid, x.SecureRedirectToIsAllowedPath(redirectURL, *allowedURL))
+		})
+	}
+}
+
 func TestTakeOverReturnToParameter(t *testing.T) {
 	type testCase struct {
 		fromUrl           string
@@ -243,6 +273,23 @@ func TestSecureRedirectTo(t *testing.T) {
 		assert.Equal(t, body, "error")
 	})
 
+	t.Run("case=return to another domain is restricted to the allowed path", func(t *testing.T) {
+		secureRedirectTo := func(returnTo string) (*url.URL, error) {
+			return x.SecureRedirectTo(
+				httptest.NewRequest("GET", "/?return_to="+returnTo, nil),
+				urlx.ParseOrPanic("https://www.ory.sh/default-return-to"),
+				x.SecureRedirectAllowURLs([]url.URL{*urlx.ParseOrPanic("https://app.example.com/kratos-callback/*")}),
+			)
+		}
+
+		returnTo, err := secureRedirectTo("https://app.example.com/kratos-callback/x")
+		require.NoError(t, err)
+		assert.Equal(t, "https://app.example.com/kratos-callback/x", returnTo.String())
+
+		_, err = secureRedirectTo("https://app.example.com/other")
+		require.Error(t, err)
+	})
+
 	t.Run("case=return to another domain fails if scheme mismatches", func(t *testing.T) {
 		s := newServer(t, false, false, true, func(ts *httptest.Server) []x.SecureRedirectOption {
 			return []x.SecureRedirectOption{x.SecureRedirectAllowURLs([]url.URL{*urlx.ParseOrPanic("http://www.ory.sh/")})}
@@ -312,4 +359,39 @@ func TestSecureRedirectTo(t *testing.T) {
 		_, body := makeRequest(t, s, "?return_to=http:///kratos")
 		assert.Equal(t, body, "http://www.ory.sh/kratos")
 	})
+
+	t.Run("case=should respect require_https_return_to", func(t *testing.T) {
+		ctx := context.Background()
+		conf, _ := internal.NewFastRegistryWithMocks(t)
+		conf.MustSet(ctx, config.ViperKeyURLsAllowedReturnToDomains, []string{"http://www.ory.sh", "https://www.ory.sh"})
+
+		secureRedirectTo := func(returnTo string) (*url.URL, error) {
+			return x.SecureRedirectTo(
+				httptest.NewRequest("GET", "/?return_to="+returnTo, nil),
+				urlx.ParseOrPanic("https://www.ory.sh/default-return-to"),
+				x.SecureRedirectAllowURLs(conf.SelfServiceBrowserAllowedReturnToDomains(ctx)),
+				x.SecureRedirectRequireHTTPS(conf.SelfServiceBrowserRequireHTTPSReturnTo(ctx)),
+			)
+		}
+
+		t.Run("case=disabled", func(t *testing.T) {
+			returnTo, err := secureRedirectTo("http://www.ory.sh/kratos")
+			require.NoError(t, err)
+			assert.Equal(t, "http://www.ory.sh/kratos", returnTo.String())
+		})
+
+		t.Run("case=enabled", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeyURLsRequireHTTPSReturnTo, true)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyURLsRequireHTTPSReturnTo, nil)
+			})
+
+			_, err := secureRedirectTo("http://www.ory.sh/kratos")
+			require.Error(t, err)
+
+			returnTo, err := secureRedirectTo("https://www.ory.sh/kratos")
+			require.NoError(t, err)
+			assert.Equal(t, "https://www.ory.sh/kratos", returnTo.String())
+		})
+	})
 }
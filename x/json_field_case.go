@@ -0,0 +1,115 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/negroni"
+
+	"github.com/ory/kratos/driver/config"
+)
+
+// jsonFieldCasePreservedKeys are the keys of user-defined data whose nested keys are never converted.
+var jsonFieldCasePreservedKeys = map[string]bool{
+	"traits":            true,
+	"metadata_public":   true,
+	"metadata_admin":    true,
+	"transient_payload": true,
+}
+
+type jsonFieldCaseWriter struct {
+	http.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+// PublicJSONFieldCaseMiddleware converts the keys of JSON responses of the public endpoint to
+// camelCase if `serve.public.json_field_case` is set to `camel`.
+func PublicJSONFieldCaseMiddleware(reg config.Provider) negroni.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		if reg.Config().PublicJSONFieldCase(r.Context()) != config.JSONFieldCaseCamel {
+			next(w, r)
+			return
+		}
+
+		cw := &jsonFieldCaseWriter{ResponseWriter: w, status: http.StatusOK}
+		next(cw, r)
+		cw.flush()
+	}
+}
+
+func (w *jsonFieldCaseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *jsonFieldCaseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *jsonFieldCaseWriter) flush() {
+	body := w.body.Bytes()
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		if converted, err := camelCaseJSON(body); err == nil {
+			body = converted
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+func camelCaseJSON(in []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(in))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(camelCaseKeys(v)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func camelCaseKeys(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, value := range v {
+			if !jsonFieldCasePreservedKeys[key] {
+				value = camelCaseKeys(value)
+			}
+			result[snakeToCamel(key)] = value
+		}
+		return result
+	case []any:
+		for k := range v {
+			v[k] = camelCaseKeys(v[k])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func snakeToCamel(key string) string {
+	parts := strings.Split(key, "_")
+	for k := 1; k < len(parts); k++ {
+		if parts[k] != "" {
+			parts[k] = strings.ToUpper(parts[k][:1]) + parts[k][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,76 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"github.com/urfave/negroni"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/x"
+)
+
+func TestPublicJSONFieldCaseMiddleware(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+
+	f := &login.Flow{
+		ID:               x.NewUUID(),
+		Type:             flow.TypeBrowser,
+		RequestURL:       "https://www.ory.sh/",
+		ExpiresAt:        time.Now().Add(time.Hour),
+		TransientPayload: []byte(`{"some_key":"value"}`),
+	}
+
+	n := negroni.New()
+	n.UseFunc(x.PublicJSONFieldCaseMiddleware(reg))
+	n.UseHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reg.Writer().Write(w, r, f)
+	})
+	ts := httptest.NewServer(n)
+	t.Cleanup(ts.Close)
+
+	get := func(t *testing.T) string {
+		res, err := ts.Client().Get(ts.URL)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	t.Run("case=snake case by default", func(t *testing.T) {
+		body := get(t)
+		assert.Equal(t, f.RequestURL, gjson.Get(body, "request_url").String(), body)
+		assert.True(t, gjson.Get(body, "expires_at").Exists(), body)
+		assert.False(t, gjson.Get(body, "requestUrl").Exists(), body)
+	})
+
+	t.Run("case=camel case", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyPublicJSONFieldCase, config.JSONFieldCaseCamel)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyPublicJSONFieldCase, nil)
+		})
+
+		body := get(t)
+		assert.Equal(t, f.RequestURL, gjson.Get(body, "requestUrl").String(), body)
+		assert.True(t, gjson.Get(body, "expiresAt").Exists(), body)
+		assert.False(t, gjson.Get(body, "request_url").Exists(), body)
+		assert.False(t, gjson.Get(body, "expires_at").Exists(), body)
+		assert.Equal(t, "value", gjson.Get(body, "transientPayload.some_key").String(), "user-defined data is not converted: %s", body)
+	})
+}
@@ -0,0 +1,101 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/negroni"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/text"
+)
+
+var ErrReadOnly = herodot.DefaultError{
+	IDField:     text.ErrIDMaintenanceReadOnly,
+	CodeField:   http.StatusServiceUnavailable,
+	StatusField: http.StatusText(http.StatusServiceUnavailable),
+	ErrorField:  "The service is in read-only maintenance mode.",
+	ReasonField: "This request would modify data and can not be processed while the service is in maintenance mode. Please try again later.",
+}
+
+type readOnlyDependencies interface {
+	config.Provider
+	WriterProvider
+}
+
+// readOnlyRoute matches requests by method and path prefix. An empty method
+// matches all methods.
+type readOnlyRoute struct {
+	method string
+	prefix string
+}
+
+func (rr readOnlyRoute) matches(r *http.Request) bool {
+	return (rr.method == "" || rr.method == r.Method) && strings.HasPrefix(r.URL.Path, rr.prefix)
+}
+
+func matchesAnyRoute(routes []readOnlyRoute, r *http.Request) bool {
+	for _, route := range routes {
+		if route.matches(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlyPublicRoutes are the public routes which modify data and are rejected
+// in read-only mode. Registrations completed through an OpenID Connect callback
+// are rejected by the registration hook executor instead, as the callback also
+// serves logins.
+var readOnlyPublicRoutes = []readOnlyRoute{
+	{prefix: "/self-service/registration"},
+	{prefix: "/self-service/settings"},
+	{prefix: "/self-service/recovery"},
+	{prefix: "/self-service/verification"},
+	{method: http.MethodDelete, prefix: "/self-service/methods/oidc/links/"},
+	{method: http.MethodDelete, prefix: "/sessions"},
+}
+
+// readOnlyAdminAllowedRoutes are the admin routes which use unsafe methods but
+// do not modify data, and therefore remain available in read-only mode.
+var readOnlyAdminAllowedRoutes = []readOnlyRoute{
+	{method: http.MethodPost, prefix: "/admin/identities/validate"},
+}
+
+// ReadOnlyPublicMiddleware rejects requests to self-service flows and session endpoints
+// which modify data while `maintenance.read_only` is enabled. Login, logout, and whoami
+// remain available.
+func ReadOnlyPublicMiddleware(reg readOnlyDependencies) negroni.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		if reg.Config().MaintenanceReadOnly(r.Context()) && matchesAnyRoute(readOnlyPublicRoutes, r) {
+			reg.Writer().WriteError(w, r, errors.WithStack(ErrReadOnly.WithDebug("The configuration key maintenance.read_only is enabled.")))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// ReadOnlyAdminMiddleware rejects all admin requests which modify data while
+// `maintenance.read_only` is enabled.
+func ReadOnlyAdminMiddleware(reg readOnlyDependencies) negroni.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		if reg.Config().MaintenanceReadOnly(r.Context()) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				if !matchesAnyRoute(readOnlyAdminAllowedRoutes, r) {
+					reg.Writer().WriteError(w, r, errors.WithStack(ErrReadOnly.WithDebug("The configuration key maintenance.read_only is enabled.")))
+					return
+				}
+			}
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"github.com/urfave/negroni"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/x"
+)
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+
+	newServer := func(m negroni.HandlerFunc) *httptest.Server {
+		n := negroni.New()
+		n.UseFunc(m)
+		n.UseHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		ts := httptest.NewServer(n)
+		t.Cleanup(ts.Close)
+		return ts
+	}
+	publicTS := newServer(x.ReadOnlyPublicMiddleware(reg))
+	adminTS := newServer(x.ReadOnlyAdminMiddleware(reg))
+
+	do := func(t *testing.T, ts *httptest.Server, method, path string) (int, string) {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.NoError(t, err)
+		res, err := ts.Client().Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, string(body)
+	}
+
+	t.Run("case=everything is allowed when disabled", func(t *testing.T) {
+		code, _ := do(t, publicTS, "GET", "/self-service/registration/api")
+		assert.Equal(t, http.StatusOK, code)
+		code, _ = do(t, adminTS, "POST", "/admin/identities")
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	conf.MustSet(ctx, config.ViperKeyMaintenanceReadOnly, true)
+	t.Cleanup(func() {
+		conf.MustSet(ctx, config.ViperKeyMaintenanceReadOnly, nil)
+	})
+
+	t.Run("case=public routes which modify data are rejected", func(t *testing.T) {
+		for _, tc := range []struct{ method, path string }{
+			{"GET", "/self-service/registration/api"},
+			{"POST", "/self-service/settings?flow=foo"},
+			{"GET", "/self-service/recovery/browser"},
+			{"POST", "/self-service/verification?flow=foo"},
+			{"DELETE", "/self-service/methods/oidc/links/google"},
+			{"DELETE", "/sessions"},
+			{"DELETE", "/sessions/foo"},
+		} {
+			code, body := do(t, publicTS, tc.method, tc.path)
+			assert.Equal(t, http.StatusServiceUnavailable, code, "%s %s", tc.method, tc.path)
+			assert.Equal(t, text.ErrIDMaintenanceReadOnly, gjson.Get(body, "error.id").String(), "%s", body)
+		}
+	})
+
+	t.Run("case=public routes which do not modify data are allowed", func(t *testing.T) {
+		for _, tc := range []struct{ method, path string }{
+			{"GET", "/self-service/login/api"},
+			{"POST", "/self-service/login?flow=foo"},
+			{"GET", "/self-service/logout/api"},
+			{"GET", "/sessions/whoami"},
+			{"GET", "/sessions"},
+			{"GET", "/self-service/methods/oidc/callback/google"},
+		} {
+			code, _ := do(t, publicTS, tc.method, tc.path)
+			assert.Equal(t, http.StatusOK, code, "%s %s", tc.method, tc.path)
+		}
+	})
+
+	t.Run("case=admin writes are rejected", func(t *testing.T) {
+		for _, tc := range []struct{ method, path string }{
+			{"POST", "/admin/identities"},
+			{"PATCH", "/admin/identities/foo"},
+			{"DELETE", "/admin/identities/foo/sessions"},
+		} {
+			code, body := do(t, adminTS, tc.method, tc.path)
+			assert.Equal(t, http.StatusServiceUnavailable, code, "%s %s", tc.method, tc.path)
+			assert.Equal(t, text.ErrIDMaintenanceReadOnly, gjson.Get(body, "error.id").String(), "%s", body)
+		}
+	})
+
+	t.Run("case=admin reads and validation are allowed", func(t *testing.T) {
+		for _, tc := range []struct{ method, path string }{
+			{"GET", "/admin/identities"},
+			{"HEAD", "/admin/identities"},
+			{"POST", "/admin/identities/validate"},
+		} {
+			code, _ := do(t, adminTS, tc.method, tc.path)
+			assert.Equal(t, http.StatusOK, code, "%s %s", tc.method, tc.path)
+		}
+	})
+}
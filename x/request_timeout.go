@@ -0,0 +1,134 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/negroni"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/text"
+)
+
+var ErrRequestTimeout = herodot.DefaultError{
+	IDField:     text.ErrIDRequestTimeout,
+	CodeField:   http.StatusServiceUnavailable,
+	StatusField: http.StatusText(http.StatusServiceUnavailable),
+	ErrorField:  "The request took too long to process.",
+}
+
+type requestTimeoutDependencies interface {
+	config.Provider
+	WriterProvider
+}
+
+// timeoutWriter buffers the response of a handler until it completes, so that
+// the response can be replaced by an error if the handler times out. It does not
+// implement http.Flusher or http.Hijacker.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	code     int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.body.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+	tw.code = code
+}
+
+func requestTimeoutMiddleware(reg requestTimeoutDependencies, key string, timeout func(*config.Config, context.Context) time.Duration) negroni.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		d := timeout(reg.Config(), r.Context())
+		if d <= 0 {
+			next(w, r)
+			return
+		}
+
+		// Canceling the request context also aborts outgoing calls (e.g. web hooks) that have
+		// a longer timeout of their own.
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		r = r.WithContext(ctx)
+
+		tw := &timeoutWriter{header: make(http.Header)}
+		done := make(chan struct{})
+		panicked := make(chan any, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+			next(tw, r)
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			for k, v := range tw.header {
+				w.Header()[k] = v
+			}
+			if tw.code == 0 {
+				tw.code = http.StatusOK
+			}
+			w.WriteHeader(tw.code)
+			_, _ = w.Write(tw.body.Bytes())
+		case <-ctx.Done():
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.timedOut = true
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				// The client went away, so there is nobody to respond to.
+				return
+			}
+			reg.Writer().WriteError(w, r, errors.WithStack(ErrRequestTimeout.
+				WithReasonf("The request was aborted because it took longer than %s. The limit is configured by %s.", d, key)))
+		}
+	}
+}
+
+// PublicRequestTimeoutMiddleware aborts requests to the public endpoint with 503 Service Unavailable
+// once they exceed `serve.public.request_timeout`.
+func PublicRequestTimeoutMiddleware(reg requestTimeoutDependencies) negroni.HandlerFunc {
+	return requestTimeoutMiddleware(reg, config.ViperKeyPublicRequestTimeout, (*config.Config).PublicRequestTimeout)
+}
+
+// AdminRequestTimeoutMiddleware aborts requests to the admin endpoint with 503 Service Unavailable
+// once they exceed `serve.admin.request_timeout`.
+func AdminRequestTimeoutMiddleware(reg requestTimeoutDependencies) negroni.HandlerFunc {
+	return requestTimeoutMiddleware(reg, config.ViperKeyAdminRequestTimeout, (*config.Config).AdminRequestTimeout)
+}
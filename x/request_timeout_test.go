@@ -0,0 +1,104 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"github.com/urfave/negroni"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/x"
+)
+
+func TestRequestTimeoutMiddleware(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+
+	canceled := make(chan struct{}, 1)
+	n := negroni.New()
+	n.UseFunc(x.PublicRequestTimeoutMiddleware(reg))
+	n.UseHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+			_, _ = w.Write([]byte("ok"))
+		case <-r.Context().Done():
+			canceled <- struct{}{}
+		}
+	})
+	ts := httptest.NewServer(n)
+	t.Cleanup(ts.Close)
+
+	get := func(t *testing.T) (*http.Response, string) {
+		res, err := ts.Client().Get(ts.URL)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, string(body)
+	}
+
+	t.Run("case=no timeout by default", func(t *testing.T) {
+		res, body := get(t)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "ok", body)
+	})
+
+	t.Run("case=slow handler is cut off", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyPublicRequestTimeout, "50ms")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyPublicRequestTimeout, nil)
+		})
+
+		start := time.Now()
+		res, body := get(t)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.Contains(t, res.Header.Get("Content-Type"), "application/json")
+		assert.Equal(t, text.ErrIDRequestTimeout, gjson.Get(body, "error.id").String(), "%s", body)
+		assert.Contains(t, gjson.Get(body, "error.reason").String(), config.ViperKeyPublicRequestTimeout, "%s", body)
+
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("expected the request context to be canceled")
+		}
+	})
+
+	t.Run("case=client disconnect is not reported as a timeout", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyPublicRequestTimeout, "500ms")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyPublicRequestTimeout, nil)
+		})
+
+		reqCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		req := httptest.NewRequest("GET", "/", nil).WithContext(reqCtx)
+		// Simulate a client which went away instead of a deadline of the client.
+		go func() {
+			<-time.After(10 * time.Millisecond)
+			cancel()
+		}()
+
+		rec := httptest.NewRecorder()
+		n.ServeHTTP(rec, req)
+		assert.Empty(t, rec.Body.String())
+
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("expected the request context to be canceled")
+		}
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ory/kratos/driver/config"
+)
+
+// Server timing metrics recorded during flow creation.
+const (
+	ServerTimingDB        = "db"
+	ServerTimingSchema    = "schema"
+	ServerTimingHydration = "hydration"
+)
+
+type (
+	serverTimingContextKey struct{}
+
+	serverTiming struct {
+		sync.Mutex
+		metrics   []string
+		durations map[string]time.Duration
+	}
+
+	serverTimingWriter struct {
+		http.ResponseWriter
+		t           *serverTiming
+		wroteHeader bool
+	}
+)
+
+// StartServerTiming collects the metrics tracked with TrackServerTiming while the request is
+// handled and sends them in the Server-Timing response header. It does nothing unless
+// `serve.public.server_timing` is enabled.
+func StartServerTiming(reg config.Provider, w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request) {
+	if !reg.Config().PublicServerTiming(r.Context()) {
+		return w, r
+	}
+
+	t := &serverTiming{durations: map[string]time.Duration{}}
+	return &serverTimingWriter{ResponseWriter: w, t: t}, r.WithContext(context.WithValue(r.Context(), serverTimingContextKey{}, t))
+}
+
+// TrackServerTiming starts measuring the metric and returns a function which stops the
+// measurement. Durations of the same metric are summed up.
+func TrackServerTiming(ctx context.Context, metric string) (stop func()) {
+	t, ok := ctx.Value(serverTimingContextKey{}).(*serverTiming)
+	if !ok {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		t.Lock()
+		defer t.Unlock()
+		if _, ok := t.durations[metric]; !ok {
+			t.metrics = append(t.metrics, metric)
+		}
+		t.durations[metric] += time.Since(start)
+	}
+}
+
+func (t *serverTiming) header() string {
+	t.Lock()
+	defer t.Unlock()
+
+	values := make([]string, len(t.metrics))
+	for k, metric := range t.metrics {
+		values[k] = fmt.Sprintf("%s;dur=%.3f", metric, float64(t.durations[metric].Microseconds())/1000)
+	}
+	return strings.Join(values, ", ")
+}
+
+func (w *serverTimingWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if h := w.t.header(); h != "" {
+			w.Header().Set("Server-Timing", h)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *serverTimingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
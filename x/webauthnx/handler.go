@@ -4,7 +4,9 @@
 package webauthnx
 
 import (
+	"crypto/sha512"
 	_ "embed"
+	"encoding/base64"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
@@ -15,8 +17,16 @@ import (
 //go:embed js/webauthn.js
 var jsOnLoad []byte
 
+// ScriptIntegrity is the subresource integrity hash of the webauthn.js script.
+var ScriptIntegrity = scriptIntegrity(jsOnLoad)
+
 const ScriptURL = "/.well-known/ory/webauthn.js"
 
+func scriptIntegrity(script []byte) string {
+	sum := sha512.Sum384(script)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
 // swagger:model webAuthnJavaScript
 //
 //nolint:deadcode,unused
@@ -48,6 +58,7 @@ func RegisterWebauthnRoute(r *x.RouterPublic) {
 	if handle, _, _ := r.Lookup("GET", ScriptURL); handle == nil {
 		r.GET(ScriptURL, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 			w.Header().Set("Content-Type", "text/javascript; charset=UTF-8")
+			w.Header().Set("Integrity", ScriptIntegrity)
 			_, _ = w.Write(jsOnLoad)
 		})
 	}
@@ -0,0 +1,51 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package webauthnx
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+)
+
+func TestScriptIntegrity(t *testing.T) {
+	t.Run("case=hash is stable", func(t *testing.T) {
+		sum := sha512.Sum384(jsOnLoad)
+		assert.Equal(t, "sha384-"+base64.StdEncoding.EncodeToString(sum[:]), ScriptIntegrity)
+		assert.Equal(t, ScriptIntegrity, scriptIntegrity(jsOnLoad))
+
+		base, err := url.Parse("https://www.ory.sh/")
+		require.NoError(t, err)
+		attr, ok := NewWebAuthnScript(base).Attributes.(*node.ScriptAttributes)
+		require.True(t, ok)
+		assert.Equal(t, ScriptIntegrity, attr.Integrity)
+	})
+
+	t.Run("case=hash matches served bytes", func(t *testing.T) {
+		router := x.NewRouterPublic()
+		RegisterWebauthnRoute(router)
+		ts := httptest.NewServer(router)
+		t.Cleanup(ts.Close)
+
+		res, err := ts.Client().Get(ts.URL + ScriptURL)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, ScriptIntegrity, res.Header.Get("Integrity"))
+		assert.Equal(t, scriptIntegrity(body), res.Header.Get("Integrity"))
+	})
+}
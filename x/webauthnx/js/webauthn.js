@@ -125,15 +125,13 @@
       })
   }
 
-  window.__oryPasskeyLoginAutocompleteInit = async function () {
-    const dataEl = document.getElementsByName("passkey_challenge")[0]
-    const resultEl = document.getElementsByName("passkey_login")[0]
+  async function __oryLoginAutocompleteInit(dataElName, resultElName) {
+    const dataEl = document.getElementsByName(dataElName)[0]
+    const resultEl = document.getElementsByName(resultElName)[0]
     const identifierEl = document.getElementsByName("identifier")[0]
 
     if (!dataEl || !resultEl || !identifierEl) {
-      console.debug(
-        "__oryPasskeyLoginAutocompleteInit: mandatory fields not found",
-      )
+      console.debug("__oryLoginAutocompleteInit: mandatory fields not found")
       return
     }
 
@@ -195,6 +193,14 @@
       })
   }
 
+  window.__oryPasskeyLoginAutocompleteInit = function () {
+    return __oryLoginAutocompleteInit("passkey_challenge", "passkey_login")
+  }
+
+  window.__oryWebAuthnLoginAutocompleteInit = function () {
+    return __oryLoginAutocompleteInit("webauthn_challenge", "webauthn_login")
+  }
+
   window.__oryPasskeyLogin = function () {
     const dataEl = document.getElementsByName("passkey_challenge")[0]
     const resultEl = document.getElementsByName("passkey_login")[0]
@@ -373,8 +379,41 @@
       })
   }
 
+  // If inline scripts are disabled, nodes reference the functions above in their
+  // onclickTrigger and onloadTrigger attributes instead of carrying inline scripts.
+  // UIs render those as data-onclick-trigger and data-onload-trigger, and the
+  // handlers are bound here. Options are passed as the element's value.
+  function __oryWebAuthnBindTriggers() {
+    document
+      .querySelectorAll("[data-onclick-trigger]")
+      .forEach(function (el) {
+        const trigger = window[el.dataset.onclickTrigger]
+        if (typeof trigger !== "function" || el.__oryTriggerBound) {
+          return
+        }
+        el.__oryTriggerBound = true
+        el.addEventListener("click", function () {
+          trigger(el.value ? JSON.parse(el.value) : undefined)
+        })
+      })
+
+    document.querySelectorAll("[data-onload-trigger]").forEach(function (el) {
+      const trigger = window[el.dataset.onloadTrigger]
+      if (typeof trigger === "function") {
+        trigger()
+      }
+    })
+  }
+
   window.__oryWebAuthnLogin = __oryWebAuthnLogin
   window.__oryWebAuthnRegistration = __oryWebAuthnRegistration
   window.__oryPasskeySettingsRegistration = __oryPasskeySettingsRegistration
+  window.__oryWebAuthnBindTriggers = __oryWebAuthnBindTriggers
   window.__oryWebAuthnInitialized = true
+
+  if (document.readyState === "loading") {
+    document.addEventListener("DOMContentLoaded", __oryWebAuthnBindTriggers)
+  } else {
+    __oryWebAuthnBindTriggers()
+  }
 })()
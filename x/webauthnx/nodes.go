@@ -4,9 +4,7 @@
 package webauthnx
 
 import (
-	"crypto/sha512"
 	_ "embed"
-	"encoding/base64"
 	"fmt"
 	"net/url"
 
@@ -18,21 +16,57 @@ import (
 	"github.com/ory/kratos/ui/node"
 )
 
-func NewWebAuthnConnectionTrigger(options string) *node.Node {
+// The functions defined in webauthn.js which are referenced by the nodes.
+const (
+	TriggerWebAuthnLogin                 = "__oryWebAuthnLogin"
+	TriggerWebAuthnLoginAutocompleteInit = "__oryWebAuthnLoginAutocompleteInit"
+	TriggerWebAuthnRegistration          = "__oryWebAuthnRegistration"
+	TriggerPasskeyLogin                  = "__oryPasskeyLogin"
+	TriggerPasskeyLoginAutocompleteInit  = "__oryPasskeyLoginAutocompleteInit"
+	TriggerPasskeyRegistration           = "__oryPasskeyRegistration"
+	TriggerPasskeySettingsRegistration   = "__oryPasskeySettingsRegistration"
+)
+
+// WithOnClick makes the input call the given webauthn.js function with the options on click.
+// If useExternalScript is true, the function is referenced in OnClickTrigger instead of an
+// inline OnClick script and the options are passed as the input's value.
+func WithOnClick(useExternalScript bool, trigger, options string) node.InputAttributesModifier {
+	return func(a *node.InputAttributes) {
+		if useExternalScript {
+			a.OnClickTrigger = trigger
+			if options != "" {
+				a.FieldValue = options
+			}
+			return
+		}
+		a.OnClick = "window." + trigger + "(" + options + ")"
+	}
+}
+
+// WithOnLoad makes the input call the given webauthn.js function on load, either inline or
+// referenced in OnLoadTrigger if useExternalScript is true.
+func WithOnLoad(useExternalScript bool, trigger string) node.InputAttributesModifier {
+	return func(a *node.InputAttributes) {
+		if useExternalScript {
+			a.OnLoadTrigger = trigger
+			return
+		}
+		a.OnLoad = "window." + trigger + "()"
+	}
+}
+
+func NewWebAuthnConnectionTrigger(options string, useExternalScript bool) *node.Node {
 	return node.NewInputField(node.WebAuthnRegisterTrigger, "", node.WebAuthnGroup,
-		node.InputAttributeTypeButton, node.WithInputAttributes(func(a *node.InputAttributes) {
-			a.OnClick = "window.__oryWebAuthnRegistration(" + options + ")"
-		}))
+		node.InputAttributeTypeButton, WithOnClick(useExternalScript, TriggerWebAuthnRegistration, options))
 }
 
 func NewWebAuthnScript(base *url.URL) *node.Node {
 	src := urlx.AppendPaths(base, ScriptURL).String()
-	integrity := sha512.Sum512(jsOnLoad)
 	return node.NewScriptField(
 		node.WebAuthnScript,
 		src,
 		node.WebAuthnGroup,
-		fmt.Sprintf("sha512-%s", base64.StdEncoding.EncodeToString(integrity[:])),
+		ScriptIntegrity,
 	)
 }
 
@@ -41,11 +75,16 @@ func NewWebAuthnConnectionInput() *node.Node {
 		node.InputAttributeTypeHidden)
 }
 
-func NewWebAuthnLoginTrigger(options string) *node.Node {
+func NewWebAuthnLoginTrigger(options string, useExternalScript bool) *node.Node {
 	return node.NewInputField(node.WebAuthnLoginTrigger, "", node.WebAuthnGroup,
-		node.InputAttributeTypeButton, node.WithInputAttributes(func(a *node.InputAttributes) {
-			a.OnClick = "window.__oryWebAuthnLogin(" + options + ")"
-		}))
+		node.InputAttributeTypeButton, WithOnClick(useExternalScript, TriggerWebAuthnLogin, options))
+}
+
+// NewWebAuthnLoginChallenge holds the options of a discoverable login, which is offered as
+// autofill of the identifier input once the page has loaded.
+func NewWebAuthnLoginChallenge(options string, useExternalScript bool) *node.Node {
+	return node.NewInputField(node.WebAuthnChallenge, options, node.WebAuthnGroup,
+		node.InputAttributeTypeHidden, WithOnLoad(useExternalScript, TriggerWebAuthnLoginAutocompleteInit))
 }
 
 func NewWebAuthnLoginInput() *node.Node {
@@ -65,7 +104,7 @@ func NewWebAuthnUnlink(c *identity.CredentialWebAuthn, opts ...node.InputAttribu
 		node.WebAuthnGroup,
 		node.InputAttributeTypeSubmit,
 		opts...,
-	).WithMetaLabel(text.NewInfoSelfServiceRemoveWebAuthn(stringsx.Coalesce(c.DisplayName, "unnamed"), c.AddedAt))
+	).WithMetaLabel(text.NewInfoSelfServiceRemoveWebAuthn(stringsx.Coalesce(c.DisplayName, "unnamed"), c.AddedAt, c.BackupEligible, c.BackupState))
 }
 
 func NewPasskeyUnlink(c *identity.CredentialWebAuthn, opts ...node.InputAttributesModifier) *node.Node {
@@ -75,5 +114,5 @@ func NewPasskeyUnlink(c *identity.CredentialWebAuthn, opts ...node.InputAttribut
 		node.PasskeyGroup,
 		node.InputAttributeTypeSubmit,
 		opts...,
-	).WithMetaLabel(text.NewInfoSelfServiceRemovePasskey(stringsx.Coalesce(c.DisplayName, "unnamed"), c.AddedAt))
+	).WithMetaLabel(text.NewInfoSelfServiceRemovePasskey(stringsx.Coalesce(c.DisplayName, "unnamed"), c.AddedAt, c.BackupEligible, c.BackupState))
 }
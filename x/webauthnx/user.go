@@ -13,6 +13,7 @@ var _ webauthn.User = (*User)(nil)
 
 type User struct {
 	Name        string
+	DisplayName string
 	ID          []byte
 	Credentials []webauthn.Credential
 	Config      *webauthn.Config
@@ -35,7 +36,7 @@ func (u *User) WebAuthnName() string {
 }
 
 func (u *User) WebAuthnDisplayName() string {
-	return stringsx.Coalesce(u.Name, u.Config.RPDisplayName)
+	return stringsx.Coalesce(u.DisplayName, u.Name, u.Config.RPDisplayName)
 }
 
 func (u *User) WebAuthnIcon() string {